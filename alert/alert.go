@@ -0,0 +1,73 @@
+package alert
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ============================================================================
+// 告警通道 - 将规则中的 Alert 调用发送到Slack、Webhook、邮件等外部通道
+// ============================================================================
+//
+// 引擎在规则动作执行 Alert("...") 时同步调用 Sink.Send，发送失败只记录错误日志，不影响规则执行。
+// 网络通道应设置超时，并通过 Throttle 限流去重，避免规则频繁命中时刷屏。
+
+// Alert 规则告警
+type Alert struct {
+	BizCode string         `json:"biz_code"`         // 业务码
+	Rule    string         `json:"rule"`             // 触发告警的GRL规则名
+	Message string         `json:"message"`          // 插值后的告警消息
+	Fields  map[string]any `json:"fields,omitempty"` // 消息中模板变量的取值
+	Time    time.Time      `json:"time"`             // 告警时间
+}
+
+// String 告警描述
+func (a Alert) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%s/%s] %s", a.BizCode, a.Rule, a.Message)
+	if len(a.Fields) > 0 {
+		keys := make([]string, 0, len(a.Fields))
+		for key := range a.Fields {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Fprintf(&b, "\n%s: %v", key, a.Fields[key])
+		}
+	}
+	return b.String()
+}
+
+// Sink 告警通道
+type Sink interface {
+	// Send 发送告警，在规则执行协程中同步调用
+	Send(ctx context.Context, alert Alert) error
+}
+
+// SinkFunc 函数形式的告警通道
+type SinkFunc func(ctx context.Context, alert Alert) error
+
+// Send 实现Sink
+func (f SinkFunc) Send(ctx context.Context, alert Alert) error {
+	return f(ctx, alert)
+}
+
+// Multi 组合多个告警通道 - 依次发送到每个通道，返回所有失败通道的错误
+func Multi(sinks ...Sink) Sink {
+	return SinkFunc(func(ctx context.Context, alert Alert) error {
+		var errs []error
+		for _, sink := range sinks {
+			if sink == nil {
+				continue
+			}
+			if err := sink.Send(ctx, alert); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(errs...)
+	})
+}
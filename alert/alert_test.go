@@ -0,0 +1,39 @@
+package alert
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// TestMulti 测试组合告警通道
+func TestMulti(t *testing.T) {
+	Convey("组合告警通道", t, func() {
+		var received []string
+		record := func(name string, err error) Sink {
+			return SinkFunc(func(ctx context.Context, alert Alert) error {
+				received = append(received, name)
+				return err
+			})
+		}
+
+		Convey("依次发送到每个通道并汇总错误", func() {
+			sink := Multi(record("slack", nil), nil, record("mail", errors.New("smtp down")), record("webhook", nil))
+			err := sink.Send(context.Background(), Alert{Message: "x"})
+			So(received, ShouldResemble, []string{"slack", "mail", "webhook"})
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "smtp down")
+		})
+
+		Convey("全部成功返回nil", func() {
+			So(Multi(record("slack", nil)).Send(context.Background(), Alert{}), ShouldBeNil)
+		})
+
+		Convey("告警描述按字段名排序", func() {
+			a := Alert{BizCode: "loan", Rule: "Risk", Message: "高风险", Fields: map[string]any{"score": 95, "user.id": "u-42"}}
+			So(a.String(), ShouldEqual, "[loan/Risk] 高风险\nscore: 95\nuser.id: u-42")
+		})
+	})
+}
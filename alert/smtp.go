@@ -0,0 +1,68 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+)
+
+// SMTPConfig 邮件告警配置
+type SMTPConfig struct {
+	Addr     string   // SMTP服务地址，如 smtp.example.com:587
+	Username string   // 认证用户名，为空时不认证
+	Password string   // 认证密码
+	From     string   // 发件人
+	To       []string // 收件人
+	Subject  string   // 邮件主题前缀，默认 "规则告警"
+}
+
+// SMTPSink 邮件告警通道
+type SMTPSink struct {
+	config   SMTPConfig
+	sendMail func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewSMTPSink 创建邮件告警通道
+func NewSMTPSink(cfg SMTPConfig) *SMTPSink {
+	if cfg.Subject == "" {
+		cfg.Subject = "规则告警"
+	}
+	return &SMTPSink{config: cfg, sendMail: smtp.SendMail}
+}
+
+// Send 实现Sink - 主题为 "主题前缀 业务码/规则名"，正文为告警描述
+func (s *SMTPSink) Send(ctx context.Context, alert Alert) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if len(s.config.To) == 0 {
+		return fmt.Errorf("邮件告警未配置收件人")
+	}
+
+	var auth smtp.Auth
+	if s.config.Username != "" {
+		host, _, err := net.SplitHostPort(s.config.Addr)
+		if err != nil {
+			return fmt.Errorf("SMTP地址无效: %w", err)
+		}
+		auth = smtp.PlainAuth("", s.config.Username, s.config.Password, host)
+	}
+
+	subject := fmt.Sprintf("%s %s/%s", s.config.Subject, alert.BizCode, alert.Rule)
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", s.config.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(s.config.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(alert.String(), "\n", "\r\n"))
+	msg.WriteString("\r\n")
+
+	if err := s.sendMail(s.config.Addr, auth, s.config.From, s.config.To, []byte(msg.String())); err != nil {
+		return fmt.Errorf("发送邮件告警失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package alert
+
+import (
+	"context"
+	"errors"
+	"net/smtp"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// TestSMTPSink 测试邮件告警通道
+func TestSMTPSink(t *testing.T) {
+	Convey("邮件告警通道", t, func() {
+		var (
+			addr, from string
+			to         []string
+			auth       smtp.Auth
+			msg        string
+		)
+		sink := NewSMTPSink(SMTPConfig{
+			Addr: "smtp.example.com:587", Username: "risk", Password: "secret",
+			From: "risk@example.com", To: []string{"oncall@example.com", "lead@example.com"},
+		})
+		sink.sendMail = func(a string, au smtp.Auth, f string, t []string, m []byte) error {
+			addr, auth, from, to, msg = a, au, f, t, string(m)
+			return nil
+		}
+		a := Alert{BizCode: "loan", Rule: "Risk", Message: "用户 u-42 高风险"}
+
+		Convey("发送邮件", func() {
+			So(sink.Send(context.Background(), a), ShouldBeNil)
+			So(addr, ShouldEqual, "smtp.example.com:587")
+			So(auth, ShouldNotBeNil)
+			So(from, ShouldEqual, "risk@example.com")
+			So(to, ShouldResemble, []string{"oncall@example.com", "lead@example.com"})
+			So(msg, ShouldContainSubstring, "To: oncall@example.com, lead@example.com\r\n")
+			So(msg, ShouldContainSubstring, "Subject: =?utf-8?q?")
+			So(msg, ShouldEndWith, "\r\n\r\n[loan/Risk] 用户 u-42 高风险\r\n")
+		})
+
+		Convey("发送失败返回错误", func() {
+			sink.sendMail = func(string, smtp.Auth, string, []string, []byte) error {
+				return errors.New("connection refused")
+			}
+			err := sink.Send(context.Background(), a)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "connection refused")
+		})
+
+		Convey("未配置收件人返回错误", func() {
+			So(NewSMTPSink(SMTPConfig{Addr: "smtp.example.com:25"}).Send(context.Background(), a), ShouldNotBeNil)
+		})
+	})
+}
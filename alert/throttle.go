@@ -0,0 +1,92 @@
+package alert
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ThrottleConfig 告警限流去重配置
+type ThrottleConfig struct {
+	DedupWindow time.Duration            // 去重窗口，窗口内相同键的告警只发送一次，<=0不去重
+	DedupKey    func(alert Alert) string // 去重键，为nil时取 业务码|规则名|消息
+	Limit       int                      // 每个限流窗口最多发送的告警数，<=0不限流
+	Window      time.Duration            // 限流窗口，默认1分钟
+}
+
+// ThrottledSink 限流去重的告警通道 - 被抑制的告警不发送，Send返回nil
+type ThrottledSink struct {
+	sink   Sink
+	config ThrottleConfig
+	now    func() time.Time
+
+	mu          sync.Mutex
+	lastSent    map[string]time.Time // 去重键 -> 最近发送时间
+	windowStart time.Time            // 当前限流窗口开始时间
+	windowCount int                  // 当前限流窗口已发送数
+	suppressed  int64                // 累计被抑制的告警数
+}
+
+// Throttle 为告警通道增加限流和去重
+func Throttle(sink Sink, cfg ThrottleConfig) *ThrottledSink {
+	if cfg.Window <= 0 {
+		cfg.Window = time.Minute
+	}
+	if cfg.DedupKey == nil {
+		cfg.DedupKey = func(alert Alert) string {
+			return alert.BizCode + "|" + alert.Rule + "|" + alert.Message
+		}
+	}
+	return &ThrottledSink{sink: sink, config: cfg, now: time.Now, lastSent: make(map[string]time.Time)}
+}
+
+// Send 实现Sink
+func (s *ThrottledSink) Send(ctx context.Context, alert Alert) error {
+	if !s.allow(alert) {
+		return nil
+	}
+	return s.sink.Send(ctx, alert)
+}
+
+// Suppressed 累计被抑制的告警数
+func (s *ThrottledSink) Suppressed() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.suppressed
+}
+
+// allow 判断告警是否发送，发送时记录去重键和限流计数
+func (s *ThrottledSink) allow(alert Alert) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	var key string
+	if s.config.DedupWindow > 0 {
+		key = s.config.DedupKey(alert)
+		if last, ok := s.lastSent[key]; ok && now.Sub(last) < s.config.DedupWindow {
+			s.suppressed++
+			return false
+		}
+	}
+	if s.config.Limit > 0 {
+		if now.Sub(s.windowStart) >= s.config.Window {
+			s.windowStart, s.windowCount = now, 0
+		}
+		if s.windowCount >= s.config.Limit {
+			s.suppressed++
+			return false
+		}
+		s.windowCount++
+	}
+	if s.config.DedupWindow > 0 {
+		s.lastSent[key] = now
+		// 清理过期的去重键
+		for k, last := range s.lastSent {
+			if now.Sub(last) >= s.config.DedupWindow {
+				delete(s.lastSent, k)
+			}
+		}
+	}
+	return true
+}
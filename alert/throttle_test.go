@@ -0,0 +1,61 @@
+package alert
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// TestThrottle 测试告警限流去重
+func TestThrottle(t *testing.T) {
+	Convey("告警限流去重", t, func() {
+		var sent []string
+		sink := SinkFunc(func(ctx context.Context, alert Alert) error {
+			sent = append(sent, alert.Message)
+			return nil
+		})
+		now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		throttle := func(cfg ThrottleConfig) *ThrottledSink {
+			s := Throttle(sink, cfg)
+			s.now = func() time.Time { return now }
+			return s
+		}
+		send := func(s Sink, message string) {
+			So(s.Send(context.Background(), Alert{BizCode: "loan", Rule: "Risk", Message: message}), ShouldBeNil)
+		}
+
+		Convey("去重窗口内相同告警只发送一次", func() {
+			s := throttle(ThrottleConfig{DedupWindow: 10 * time.Minute})
+			send(s, "a")
+			send(s, "a")
+			send(s, "b")
+			now = now.Add(10 * time.Minute)
+			send(s, "a")
+			So(sent, ShouldResemble, []string{"a", "b", "a"})
+			So(s.Suppressed(), ShouldEqual, 1)
+		})
+
+		Convey("限流窗口内超出数量的告警被抑制", func() {
+			s := throttle(ThrottleConfig{Limit: 2})
+			send(s, "a")
+			send(s, "b")
+			send(s, "c")
+			now = now.Add(time.Minute)
+			send(s, "d")
+			So(sent, ShouldResemble, []string{"a", "b", "d"})
+			So(s.Suppressed(), ShouldEqual, 1)
+		})
+
+		Convey("自定义去重键", func() {
+			s := throttle(ThrottleConfig{
+				DedupWindow: time.Hour,
+				DedupKey:    func(alert Alert) string { return alert.Rule },
+			})
+			send(s, "a")
+			send(s, "b")
+			So(sent, ShouldResemble, []string{"a"})
+		})
+	})
+}
@@ -0,0 +1,73 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// 默认HTTP请求超时
+const defaultHTTPTimeout = 5 * time.Second
+
+// WebhookSink HTTP告警通道 - 以POST请求发送告警
+type WebhookSink struct {
+	URL     string                            // 请求地址
+	Client  *http.Client                      // HTTP客户端，为nil时使用超时5秒的默认客户端
+	Headers map[string]string                 // 附加请求头
+	Encode  func(alert Alert) ([]byte, error) // 请求体编码，为nil时编码为Alert的JSON
+}
+
+// NewWebhookSink 创建通用HTTP告警通道，请求体为告警的JSON
+func NewWebhookSink(url string) *WebhookSink {
+	return &WebhookSink{URL: url}
+}
+
+// NewSlackSink 创建Slack告警通道 - 通过Incoming Webhook发送文本消息
+func NewSlackSink(webhookURL string) *WebhookSink {
+	return &WebhookSink{
+		URL: webhookURL,
+		Encode: func(alert Alert) ([]byte, error) {
+			return json.Marshal(map[string]string{"text": alert.String()})
+		},
+	}
+}
+
+// Send 实现Sink - 响应状态码非2xx时返回错误
+func (s *WebhookSink) Send(ctx context.Context, alert Alert) error {
+	encode := s.Encode
+	if encode == nil {
+		encode = func(alert Alert) ([]byte, error) { return json.Marshal(alert) }
+	}
+	body, err := encode(alert)
+	if err != nil {
+		return fmt.Errorf("编码告警失败: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("创建告警请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range s.Headers {
+		req.Header.Set(key, value)
+	}
+
+	client := s.Client
+	if client == nil {
+		client = &http.Client{Timeout: defaultHTTPTimeout}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("发送告警失败: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("发送告警失败: %s %s", resp.Status, bytes.TrimSpace(detail))
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// TestWebhookSink 测试HTTP告警通道
+func TestWebhookSink(t *testing.T) {
+	Convey("HTTP告警通道", t, func() {
+		var (
+			body    []byte
+			headers http.Header
+			status  = http.StatusOK
+		)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ = io.ReadAll(r.Body)
+			headers = r.Header
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte("invalid_token"))
+		}))
+		defer server.Close()
+
+		a := Alert{BizCode: "loan", Rule: "Risk", Message: "用户 u-42 高风险", Fields: map[string]any{"score": 95}}
+
+		Convey("通用Webhook发送告警JSON", func() {
+			sink := NewWebhookSink(server.URL)
+			sink.Headers = map[string]string{"Authorization": "Bearer token"}
+			So(sink.Send(context.Background(), a), ShouldBeNil)
+			So(headers.Get("Content-Type"), ShouldEqual, "application/json")
+			So(headers.Get("Authorization"), ShouldEqual, "Bearer token")
+
+			var decoded map[string]any
+			So(json.Unmarshal(body, &decoded), ShouldBeNil)
+			So(decoded["biz_code"], ShouldEqual, "loan")
+			So(decoded["rule"], ShouldEqual, "Risk")
+			So(decoded["message"], ShouldEqual, "用户 u-42 高风险")
+			So(decoded["fields"], ShouldResemble, map[string]any{"score": float64(95)})
+		})
+
+		Convey("Slack发送文本消息", func() {
+			So(NewSlackSink(server.URL).Send(context.Background(), a), ShouldBeNil)
+			var decoded map[string]string
+			So(json.Unmarshal(body, &decoded), ShouldBeNil)
+			So(decoded, ShouldResemble, map[string]string{"text": "[loan/Risk] 用户 u-42 高风险\nscore: 95"})
+		})
+
+		Convey("非2xx响应返回错误", func() {
+			status = http.StatusForbidden
+			err := NewSlackSink(server.URL).Send(context.Background(), a)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "403")
+			So(err.Error(), ShouldContainSubstring, "invalid_token")
+		})
+	})
+}
@@ -5,6 +5,7 @@ package cache
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"time"
 )
 
@@ -60,6 +61,35 @@ type Cache interface {
 	Close() error
 }
 
+// StatsReporter 缓存统计接口 - 可选实现，用于诊断输出
+type StatsReporter interface {
+	// Stats 获取缓存统计信息
+	Stats() Stats
+}
+
+// Stats 缓存统计信息
+type Stats struct {
+	Entries int   `json:"entries"`  // 当前条目数
+	MaxSize int   `json:"max_size"` // 最大条目数
+	Hits    int64 `json:"hits"`     // 命中次数
+	Misses  int64 `json:"misses"`   // 未命中次数
+}
+
+// PrefixDeleter 按前缀删除接口 - 可选实现，用于失效一批幂等结果
+type PrefixDeleter interface {
+	// DelPrefix 删除以prefix开头且match返回true的键
+	//
+	// 参数:
+	//   ctx    - 上下文，用于超时控制和取消操作
+	//   prefix - 键前缀
+	//   match  - 键过滤条件，为nil时删除前缀下的全部键
+	//
+	// 返回值:
+	//   int   - 删除的键数量
+	//   error - 操作错误
+	DelPrefix(ctx context.Context, prefix string, match func(key string) bool) (int, error)
+}
+
 // ============================================================================
 // 缓存工具类 - 键构建器和序列化支持
 // ============================================================================
@@ -85,6 +115,19 @@ func (CacheKeyBuilder) RuleKey(bizCode string) string {
 	return "runehammer:rule:" + bizCode
 }
 
+// PagedRuleKey 构建分页获取的规则缓存键 - 分页获取的规则不含GRL，与完整规则分开缓存
+//
+// 参数:
+//   bizCode - 业务码
+//
+// 返回值:
+//   string - 格式化的缓存键
+//
+// 格式: runehammer:rule-paged:{bizCode}
+func (CacheKeyBuilder) PagedRuleKey(bizCode string) string {
+	return "runehammer:rule-paged:" + bizCode
+}
+
 // MetaKey 构建元数据缓存键
 //
 // 参数:
@@ -98,6 +141,26 @@ func (CacheKeyBuilder) MetaKey(bizCode string) string {
 	return "runehammer:meta:" + bizCode
 }
 
+// IdempotencyKey 构建幂等结果缓存键
+//
+// 参数:
+//   bizCode - 业务码
+//   ruleSet - 规则集内容哈希
+//   key     - 调用方提供的幂等键
+//
+// 返回值:
+//   string - 格式化的缓存键
+//
+// 格式: runehammer:idem:{bizCode}:{ruleSet}:{key}
+func (CacheKeyBuilder) IdempotencyKey(bizCode string, ruleSet string, key string) string {
+	return fmt.Sprintf("runehammer:idem:%s:%s:%s", bizCode, ruleSet, key)
+}
+
+// IdempotencyPrefix 构建业务码幂等结果缓存键的前缀，其后为 "规则集哈希:幂等键"
+func (CacheKeyBuilder) IdempotencyPrefix(bizCode string) string {
+	return fmt.Sprintf("runehammer:idem:%s:", bizCode)
+}
+
 // ============================================================================
 // 缓存数据结构 - 规则缓存项的序列化支持
 // ============================================================================
@@ -115,9 +115,19 @@ func TestCacheKeyBuilder(t *testing.T) {
 			So(key, ShouldEqual, "runehammer:rule:test_biz")
 		})
 
+		Convey("分页规则键构建", func() {
+			key := builder.PagedRuleKey("test_biz")
+			So(key, ShouldEqual, "runehammer:rule-paged:test_biz")
+		})
+
 		Convey("元数据键构建", func() {
 			key := builder.MetaKey("test_meta")
 			So(key, ShouldEqual, "runehammer:meta:test_meta")
 		})
+
+		Convey("幂等键构建", func() {
+			key := builder.IdempotencyKey("test_biz", "3f2a", "req-1")
+			So(key, ShouldEqual, "runehammer:idem:test_biz:3f2a:req-1")
+		})
 	})
 }
\ No newline at end of file
@@ -3,7 +3,9 @@ package cache
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -23,6 +25,8 @@ type MemoryCache struct {
 	mutex    sync.RWMutex         // 读写锁保护
 	maxSize  int                  // 最大缓存条目数
 	stopChan chan struct{}        // 停止信号通道
+	hits     atomic.Int64         // 命中次数
+	misses   atomic.Int64         // 未命中次数
 }
 
 // cacheItem 缓存项 - 包含值和过期时间的数据结构
@@ -63,6 +67,7 @@ func (m *MemoryCache) Get(ctx context.Context, key string) ([]byte, error) {
 
 	item, exists := m.data[key]
 	if !exists {
+		m.misses.Add(1)
 		return nil, fmt.Errorf("cache key not found")
 	}
 
@@ -70,9 +75,11 @@ func (m *MemoryCache) Get(ctx context.Context, key string) ([]byte, error) {
 	if time.Now().After(item.ExpiresAt) {
 		// 异步删除过期项，避免阻塞读操作
 		go m.asyncDelete(key)
+		m.misses.Add(1)
 		return nil, fmt.Errorf("cache key not found")
 	}
 
+	m.hits.Add(1)
 	return item.Value, nil
 }
 
@@ -104,6 +111,34 @@ func (m *MemoryCache) Del(ctx context.Context, key string) error {
 	return nil
 }
 
+// DelPrefix 删除以prefix开头且match返回true的键
+func (m *MemoryCache) DelPrefix(ctx context.Context, prefix string, match func(key string) bool) (int, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	deleted := 0
+	for key := range m.data {
+		if strings.HasPrefix(key, prefix) && (match == nil || match(key)) {
+			delete(m.data, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// Stats 获取缓存统计信息
+func (m *MemoryCache) Stats() Stats {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return Stats{
+		Entries: len(m.data),
+		MaxSize: m.maxSize,
+		Hits:    m.hits.Load(),
+		Misses:  m.misses.Load(),
+	}
+}
+
 // Close 关闭缓存 - 停止后台清理任务
 func (m *MemoryCache) Close() error {
 	// 防止重复关闭channel
@@ -3,6 +3,7 @@ package cache
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -84,6 +85,28 @@ func TestMemoryCacheDetailed(t *testing.T) {
 				So(err, ShouldNotBeNil)
 				So(err.Error(), ShouldContainSubstring, "not found")
 			})
+
+			Convey("DelPrefix按前缀和条件删除", func() {
+				for _, key := range []string{"idem:loan:1:c-1", "idem:loan:2:c-2", "idem:loan:2:d-1", "idem:risk:1:c-1"} {
+					So(cache.Set(ctx, key, []byte("v"), time.Hour), ShouldBeNil)
+				}
+				deleter, ok := cache.(PrefixDeleter)
+				So(ok, ShouldBeTrue)
+
+				deleted, err := deleter.DelPrefix(ctx, "idem:loan:", func(key string) bool {
+					return strings.Contains(key, ":c-")
+				})
+				So(err, ShouldBeNil)
+				So(deleted, ShouldEqual, 2)
+				_, err = cache.Get(ctx, "idem:loan:2:d-1")
+				So(err, ShouldBeNil)
+				_, err = cache.Get(ctx, "idem:risk:1:c-1")
+				So(err, ShouldBeNil)
+
+				deleted, err = deleter.DelPrefix(ctx, "idem:loan:", nil)
+				So(err, ShouldBeNil)
+				So(deleted, ShouldEqual, 1)
+			})
 		})
 		
 		Convey("TTL过期测试", func() {
@@ -519,3 +542,25 @@ func TestMemoryCacheDetailed(t *testing.T) {
 		})
 	})
 }
+
+// TestMemoryCacheStats 测试内存缓存统计
+func TestMemoryCacheStats(t *testing.T) {
+	Convey("内存缓存统计", t, func() {
+		c := NewMemoryCache(10)
+		defer c.Close()
+		ctx := context.Background()
+
+		reporter, ok := c.(StatsReporter)
+		So(ok, ShouldBeTrue)
+
+		So(c.Set(ctx, "k", []byte("v"), time.Minute), ShouldBeNil)
+		_, _ = c.Get(ctx, "k")
+		_, _ = c.Get(ctx, "missing")
+
+		stats := reporter.Stats()
+		So(stats.Entries, ShouldEqual, 1)
+		So(stats.MaxSize, ShouldEqual, 10)
+		So(stats.Hits, ShouldEqual, 1)
+		So(stats.Misses, ShouldEqual, 1)
+	})
+}
@@ -3,6 +3,7 @@ package cache
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -94,6 +95,48 @@ func (r *RedisCache) Del(ctx context.Context, key string) error {
 	return r.client.Del(ctx, key).Err()
 }
 
+// DelPrefix 删除以prefix开头且match返回true的键 - 通过SCAN遍历，不阻塞Redis
+//
+// 参数:
+//   ctx    - 上下文，用于超时控制和取消操作
+//   prefix - 键前缀，其中的通配符按字面匹配
+//   match  - 键过滤条件，为nil时删除前缀下的全部键
+//
+// 返回值:
+//   int   - 删除的键数量
+//   error - 操作错误，已删除的键不会恢复
+func (r *RedisCache) DelPrefix(ctx context.Context, prefix string, match func(key string) bool) (int, error) {
+	pattern := redisGlobEscaper.Replace(prefix) + "*"
+	deleted := 0
+	var cursor uint64
+	for {
+		keys, next, err := r.client.Scan(ctx, cursor, pattern, 100).Result()
+		if err != nil {
+			return deleted, err
+		}
+		var batch []string
+		for _, key := range keys {
+			if match == nil || match(key) {
+				batch = append(batch, key)
+			}
+		}
+		if len(batch) > 0 {
+			n, err := r.client.Del(ctx, batch...).Result()
+			if err != nil {
+				return deleted, err
+			}
+			deleted += int(n)
+		}
+		if next == 0 {
+			return deleted, nil
+		}
+		cursor = next
+	}
+}
+
+// redisGlobEscaper 转义Redis SCAN MATCH模式中的通配符
+var redisGlobEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`, "]", `\]`)
+
 // Close 关闭Redis连接 - 释放客户端连接资源
 //
 // 返回值:
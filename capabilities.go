@@ -0,0 +1,534 @@
+package runehammer
+
+import (
+	"context"
+	"io"
+	"iter"
+	"time"
+
+	"gitee.com/damengde/runehammer/rule"
+)
+
+// ============================================================================
+// 引擎可选能力 - Engine、BaseEngine 只包含执行和关闭，其他能力以可选接口提供
+// ============================================================================
+//
+// 新增能力以新的可选接口（或在已有接口中）提供，不再扩大 Engine、BaseEngine，
+// 自行实现或包装引擎（如测试替身、装饰器）的代码只需实现用到的接口。
+// New 和 NewBaseEngine 返回的引擎实现本文件中的全部接口（BaseEngine 的会话接口为
+// SessionExecutor[map[string]interface{}]，按版本执行通过 WithVersion、WithSelector 选项），
+// 使用方通过类型断言获取：
+//
+//	engine, err := New[MyResult](WithDSN(dsn))
+//	if kill, ok := engine.(KillSwitch); ok {
+//	    err = kill.Disable(ctx, "PAYMENT", "INC-1024 下游风控服务故障")
+//	}
+//	report, err := engine.(RuleAnalysis).RunRuleTests(ctx, "LOAN")
+
+// VersionedExecutor 按规则集版本或规则选择器执行的能力
+type VersionedExecutor[T any] interface {
+	// ExecVersion 按指定规则集版本执行 - 绕过最新版本，供长时间运行的批处理在发布新版本后保持版本一致
+	//
+	// 版本为业务码规则的最大版本号，可通过 WithExecReport 从 ExecReport.RuleSetVersion 获取；
+	// 同一版本号对应多个规则集（如删除规则而未提升版本号）时使用最近编译的一个，
+	// 需要精确固定时使用 Exec 加 WithRuleSetHash(report.RuleSetHash)。
+	// 引擎只在内存中保留最近编译过的规则集（WithVersionRetention，默认3个，<=0时不保留），
+	// 引擎重启后历史版本丢失；请求的版本既不是最新版本也未被保留时返回 ErrVersionNotFound。
+	//
+	// 参数:
+	//   ctx     - 上下文
+	//   bizCode - 业务码
+	//   version - 规则集版本
+	//   input   - 输入数据
+	//   opts    - 执行选项
+	//
+	// 返回值:
+	//   T     - 规则执行结果
+	//   error - 执行错误
+	//
+	// 使用示例:
+	//   var report ExecReport
+	//   _, err := engine.Exec(ctx, "SETTLEMENT", first, WithExecReport(&report))
+	//   for _, item := range batch {
+	//       result, err := engine.ExecVersion(ctx, "SETTLEMENT", report.RuleSetVersion, item)
+	//   }
+	ExecVersion(ctx context.Context, bizCode string, version int, input any, opts ...ExecOption) (T, error)
+
+	// ExecWhere 只执行满足选择器的规则 - 选择器是基于规则元数据的类SQL表达式，编译后按表达式缓存
+	//
+	// 可用字段: name、tags、priority（GRL salience）、version、enabled、rollout_percent、
+	// description、created_by、updated_by、id、source_id；操作符: = != <> > >= < <= CONTAINS IN，
+	// 以 AND、OR、NOT 和括号组合。语法错误时不执行并返回错误。
+	//
+	// 参数:
+	//   ctx      - 上下文
+	//   bizCode  - 业务码
+	//   selector - 选择器表达式
+	//   input    - 输入数据
+	//   opts     - 执行选项
+	//
+	// 返回值:
+	//   T     - 规则执行结果
+	//   error - 执行错误
+	//
+	// 使用示例:
+	//   result, err := engine.ExecWhere(ctx, "RISK", "tags CONTAINS 'fast' AND priority >= 50", input)
+	ExecWhere(ctx context.Context, bizCode string, selector string, input any, opts ...ExecOption) (T, error)
+}
+
+// SessionExecutor 执行会话与结果流式输出的能力
+type SessionExecutor[T any] interface {
+	// NewSession 创建执行会话 - 同一输入依次执行多个业务码时只归一化、注入一次输入和内置函数
+	//
+	// 会话中每次执行的Result互相独立，撤回、完成等执行状态每次重新开始；规则对输入的修改对后续执行可见。
+	// 会话中的执行串行进行，执行均经过中间件，可使用全部执行选项。
+	//
+	// 参数:
+	//   ctx   - 会话中各次执行使用的上下文
+	//   input - 输入数据
+	//
+	// 返回值:
+	//   *Session[T] - 执行会话
+	//
+	// 使用示例:
+	//   session := engine.NewSession(ctx, input)
+	//   kyc, err := session.Exec("KYC")
+	//   risk, err := session.Exec("RISK", WithParams(map[string]any{"threshold": 80}))
+	NewSession(ctx context.Context, input any) *Session[T]
+
+	// StreamResults 以迭代器流式获取规则产出的结果元素 - 规则动作以 Emit(key, 元素) 产出，不在结果中累积
+	//
+	// 规则执行期间每产出一个元素即交给迭代器，使用方停止迭代时中止执行；执行失败时最后产出 (nil, err)。
+	// 需要同时获取结果其他字段时使用 WithResultStream 回调。
+	//
+	// 参数:
+	//   ctx        - 上下文
+	//   bizCode    - 业务码
+	//   input      - 输入数据
+	//   key        - 流式输出的结果键
+	//   maxResults - 最多输出的元素数，超出的元素丢弃，<=0表示不限制
+	//   opts       - 执行选项
+	//
+	// 返回值:
+	//   iter.Seq2[any, error] - 结果元素迭代器
+	//
+	// 使用示例:
+	//   for offer, err := range engine.StreamResults(ctx, "OFFERS", input, "offers", 1000) {
+	//       if err != nil {
+	//           return err
+	//       }
+	//       send(offer)
+	//   }
+	StreamResults(ctx context.Context, bizCode string, input any, key string, maxResults int, opts ...ExecOption) iter.Seq2[any, error]
+}
+
+// Explainer 决策解释的能力
+type Explainer interface {
+	// Explain 执行规则并解释每个Result字段由哪些规则写入 - 自动化决策的可解释性
+	//
+	// 基于结果变更日志（WithResultJournal），按字段汇总各规则的写入顺序及写入前后的值。
+	//
+	// 参数:
+	//   ctx     - 上下文
+	//   bizCode - 业务码
+	//   input   - 输入数据，与 Exec 相同
+	//   opts    - 执行选项，与 Exec 相同；执行报告和变更日志由 Explain 设置
+	//
+	// 返回值:
+	//   *Explanation - 决策解释，Field(path) 查找单个字段，Rules() 列出参与的规则
+	//   error        - 执行失败
+	//
+	// 示例:
+	//   explanation, err := engine.Explain(ctx, "LOAN_APPROVAL", applicant)
+	//   for _, change := range explanation.Field("approved").Changes {
+	//       fmt.Println(change.Rule, change.Old, "->", change.New)
+	//   }
+	Explain(ctx context.Context, bizCode string, input any, opts ...ExecOption) (*Explanation, error)
+}
+
+// Diagnostics 诊断与运行指标
+type Diagnostics interface {
+	// DebugDump 输出诊断快照 - 以JSON格式写入已编译业务码及哈希、缓存统计、
+	// 连接池统计、定时任务和脱敏配置，用于问题排查
+	//
+	// 参数:
+	//   w - 输出目标
+	//
+	// 返回值:
+	//   error - 写入错误
+	DebugDump(w io.Writer) error
+
+	// KnowledgeBaseMemory 获取已编译知识库的内存占用估算 - 每个业务码的规则数、AST节点数和估算字节数，
+	// 按估算字节数降序，用于定位占用内存较多的业务码
+	//
+	// 返回值:
+	//   []CompileInfo - 知识库编译信息，EstimatedBytes 为近似值，不含保留的历史版本
+	KnowledgeBaseMemory() []CompileInfo
+
+	// RecentErrors 获取近期错误 - 按发生时间先后返回有界缓冲中的执行/编译错误
+	//
+	// 返回值:
+	//   []ErrorRecord - 错误记录，包含业务码、分类（fetch/compile/conversion/timeout/execution）和错误信息
+	RecentErrors() []ErrorRecord
+
+	// Metrics 获取执行指标 - 如已恢复的panic次数
+	Metrics() ExecMetrics
+
+	// ReadOnly 引擎是否为只读模式（WithReadOnly） - 只读实例的规则复制和数据清理返回 ErrReadOnly
+	ReadOnly() bool
+
+	// SLOStatus 获取延迟SLO状态 - 返回配置了SLO（WithLatencySLO）且已有执行记录的业务码
+	// 在统计窗口内的p95/p99延迟、是否满足SLO及规则负责人，按业务码排序
+	//
+	// 示例:
+	//   for _, status := range engine.SLOStatus() {
+	//       if !status.Healthy {
+	//           log.Printf("%s 违反延迟SLO，负责人 %v", status.BizCode, status.Owners)
+	//       }
+	//   }
+	SLOStatus() []SLOStatus
+
+	// DecisionStats 获取业务码的决策分布快照 - 需通过 WithDecisionStats 启用
+	//
+	// 分布按规则集版本累计，规则变更后上一版本的分布保留在 Previous 中，
+	// 对比两者的通过率、分数分桶即可发现规则变更引起的决策漂移。
+	//
+	// 参数:
+	//   bizCode - 业务码
+	//
+	// 返回值:
+	//   *DecisionStats - 分布快照，未启用或尚无执行记录时为nil
+	DecisionStats(bizCode string) *DecisionStats
+
+	// DeadRules 获取业务码的失效规则报告 - 需通过 WithDeadRuleDetection 启用
+	//
+	// 引擎按规则记录命中次数和最近命中时间，观察满一个检测窗口且窗口内从未命中的规则视为失效，
+	// 可据此清理过时的规则逻辑。
+	//
+	// 参数:
+	//   bizCode - 业务码
+	//
+	// 返回值:
+	//   *DeadRuleReport - 失效规则报告，未启用检测或尚无执行记录时为nil
+	DeadRules(bizCode string) *DeadRuleReport
+}
+
+// Registry 执行中间件、枚举与参考数据等登记，以及业务码执行配置的查询
+type Registry interface {
+	// NullPolicy 获取业务码的缺失字段比较语义 - 转换规则定义时应使用同一语义
+	//
+	// 参数:
+	//   bizCode - 业务码，启用层级继承时未配置的子业务码沿用父业务码的配置
+	//
+	// 返回值:
+	//   NullPolicy - WithBizNullPolicy 配置的语义，未配置时为 WithNullPolicy 的语义
+	//
+	// 使用示例:
+	//   converter := rule.NewGRLConverter(rule.ConverterConfig{NullPolicy: engine.NullPolicy("RISK")})
+	NullPolicy(bizCode string) NullPolicy
+
+	// ExecMode 获取业务码的规则执行模式
+	//
+	// 参数:
+	//   bizCode - 业务码，启用层级继承时未配置的子业务码沿用父业务码的配置
+	//
+	// 返回值:
+	//   ExecMode - WithBizExecMode 配置的模式，未配置时为 WithExecMode 的模式
+	ExecMode(bizCode string) ExecMode
+
+	// Use 注册执行中间件 - 包裹Exec管线，先注册的中间件在外层，多次调用时追加
+	//
+	// 参数:
+	//   middleware - 中间件，形如 func(next ExecFunc) ExecFunc；不调用next直接返回时结果须为T（TypedEngine 为 map[string]interface{}）
+	//
+	// 使用示例:
+	//   engine.Use(func(next ExecFunc) ExecFunc {
+	//       return func(ctx context.Context, bizCode string, input any, opts ...ExecOption) (any, error) {
+	//           start := time.Now()
+	//           result, err := next(ctx, bizCode, input, opts...)
+	//           metrics.Observe(bizCode, time.Since(start), err)
+	//           return result, err
+	//       }
+	//   })
+	Use(middleware ...ExecMiddleware)
+
+	// RegisterPrebuiltKnowledgeBase 将知识库中已编译的知识库登记到业务码 - 复用在其他地方编译的Grule规则
+	//
+	// 参数:
+	//   bizCode - 业务码，登记后执行不再从映射器获取规则
+	//   name    - 知识库名称
+	//   version - 知识库版本
+	//
+	// 返回值:
+	//   error - 知识库不存在时返回 ErrKnowledgeBaseNotFound
+	//
+	// 使用示例:
+	//   lib := ast.NewKnowledgeLibrary()
+	//   builder.NewRuleBuilder(lib).BuildRuleFromResource("Legacy", "1.0.0", pkg.NewFileResource("legacy.grl"))
+	//   engine, _ := New[map[string]any](WithDSN(dsn), WithKnowledgeLibrary(lib))
+	//   err := engine.RegisterPrebuiltKnowledgeBase("LEGACY_RISK", "Legacy", "1.0.0")
+	RegisterPrebuiltKnowledgeBase(bizCode, name, version string) error
+
+	// RegisterEnum 登记枚举 - 规则条件以 name.成员 引用（如 OrderStatus.PAID），编译时解析为成员名字符串
+	//
+	// 引用不存在的成员时规则编译失败，避免在规则中散落魔法字符串；重复登记时替换，已编译的知识库随之重新编译。
+	//
+	// 参数:
+	//   name   - 枚举名，须为标识符且不能与 Params、Result 等注入对象重名
+	//   values - 成员，须为标识符且不重复
+	//
+	// 返回值:
+	//   error - 名称或成员无效，或与参考数据表重名
+	//
+	// 使用示例:
+	//   err := engine.RegisterEnum("OrderStatus", "PENDING", "PAID", "REFUNDED")
+	//   // 规则中: when Params.status == OrderStatus.PAID then ...
+	RegisterEnum(name string, values ...string) error
+
+	// RegisterRefTable 登记参考数据表 - 规则以 name.行 引用行键，以 name.行.列 引用单元格的值，编译时解析为字面量
+	//
+	// 引用不存在的行或列时规则编译失败；重复登记时替换，已编译的知识库随之重新编译。
+	//
+	// 参数:
+	//   name - 表名
+	//   rows - 行键 -> 列名 -> 值，值须为字符串、布尔或数值
+	//
+	// 返回值:
+	//   error - 名称、行列名或值类型无效，或与枚举重名
+	//
+	// 使用示例:
+	//   err := engine.RegisterRefTable("Countries", map[string]map[string]any{"CN": {"currency": "CNY", "risk": 1}})
+	//   // 规则中: when Params.country == Countries.CN && Params.risk <= Countries.CN.risk then ...
+	RegisterRefTable(name string, rows map[string]map[string]any) error
+}
+
+// RuleLayers 运行时覆盖规则与规则分层解析
+type RuleLayers interface {
+	// AddOverride 添加运行时覆盖规则 - 立即生效，覆盖内置规则和数据库中的同名规则（Rule.Name），到期自动移除
+	//
+	// 覆盖规则只保存在内存中，不写入数据库，重启后丢失；同一业务码下同名的覆盖规则被替换。
+	// 配置了消息通道（WithPubSub）时广播给其他实例，广播失败时本实例已生效，返回的错误包含失败原因。
+	//
+	// 参数:
+	//   ctx      - 上下文
+	//   bizCode  - 业务码
+	//   override - 覆盖规则，Name 和 GRL 必填
+	//   ttl      - 有效时长，为0表示不过期（直到移除或实例重启）
+	//
+	// 返回值:
+	//   error - 参数无效、GRL编译失败、引擎为只读模式（ErrReadOnly）或广播失败
+	//
+	// 示例:
+	//   err := engine.AddOverride(ctx, "PAYMENT", &rule.Rule{Name: "block_merchant", GRL: grl}, 30*time.Minute)
+	AddOverride(ctx context.Context, bizCode string, override *rule.Rule, ttl time.Duration) error
+
+	// RemoveOverride 移除运行时覆盖规则 - 被覆盖的同名规则重新生效，同时广播给其他实例，
+	// 本实例不存在时返回 ErrOverrideNotFound
+	RemoveOverride(ctx context.Context, bizCode, name string) error
+
+	// Resolve 解析业务码的生效规则 - 返回每条生效规则的来源层（内置、数据库、运行时覆盖）
+	// 及被其覆盖的低层，用于排查规则为何生效或未生效
+	//
+	// 参数:
+	//   ctx     - 上下文
+	//   bizCode - 业务码，启用层级继承时包含父级业务码的规则
+	//
+	// 返回值:
+	//   *RuleResolution - 解析结果
+	//   error           - 获取数据库规则失败
+	Resolve(ctx context.Context, bizCode string) (*RuleResolution, error)
+}
+
+// KillSwitch 业务码紧急停用
+type KillSwitch interface {
+	// Disable 紧急停用业务码 - 立即生效，Exec 不再执行规则，配置了降级结果时返回降级结果
+	// （降级原因包装 ErrBizDisabled），否则返回 ErrBizDisabled
+	//
+	// 配置了消息通道（WithPubSub）时广播给其他实例，停用记录和审计记录（WithAuditRecorder）在本实例写入，
+	// 实例重启后 New 在返回前加载停用记录。启用业务码层级继承时，停用父级业务码同时停用其子业务码。
+	//
+	// 参数:
+	//   ctx     - 上下文
+	//   bizCode - 业务码
+	//   reason  - 停用原因，必填
+	//
+	// 返回值:
+	//   error - 参数无效、引擎为只读模式（ErrReadOnly），或已在本实例停用但持久化、审计记录、广播失败
+	//
+	// 示例:
+	//   err := engine.Disable(ctx, "PAYMENT", "INC-1024 下游风控服务故障")
+	Disable(ctx context.Context, bizCode, reason string) error
+
+	// Enable 恢复紧急停用的业务码 - 同样删除停用记录、广播给其他实例并写入审计记录
+	Enable(ctx context.Context, bizCode string) error
+
+	// Disabled 获取已紧急停用的业务码及停用原因
+	Disabled() []Disablement
+}
+
+// Maintenance 数据清理、缓存失效与租户规则集复制
+type Maintenance interface {
+	// RunRetention 立即执行一次数据清理 - 删除超出保留数的规则历史版本和超过保留时长的审计记录，
+	// 配置数据保留后清理任务也会按间隔定时执行
+	//
+	// 参数:
+	//   ctx - 上下文，批之间检查取消
+	//
+	// 返回值:
+	//   *RetentionReport - 各清理目标的删除数和批次数
+	//   error            - 部分目标清理失败时返回合并的错误，报告仍包含已完成的删除数
+	RunRetention(ctx context.Context) (*RetentionReport, error)
+
+	// Invalidate 失效幂等结果 - 上游数据（如客户资料）变更时清除业务码下幂等键以inputKeyPrefix开头的
+	// 已存储结果，避免在幂等窗口内返回过时的决策；inputKeyPrefix为空时失效业务码的全部结果
+	//
+	// 配置了消息通道（WithPubSub）时广播给其他实例。也可通过 WithInvalidationSource 接入数据变更事件自动失效。
+	//
+	// 参数:
+	//   ctx            - 上下文
+	//   bizCode        - 业务码，不包含子业务码
+	//   inputKeyPrefix - 幂等键前缀
+	//
+	// 返回值:
+	//   error - 缓存不支持按前缀删除（ErrInvalidationNotSupported），或已在本实例失效但广播失败
+	//
+	// 示例:
+	//   // 幂等键为 "客户ID:订单号"，客户资料变更后失效该客户的全部决策
+	//   err := engine.Invalidate(ctx, "CREDIT_LIMIT", "cust-42:")
+	Invalidate(ctx context.Context, bizCode, inputKeyPrefix string) error
+
+	// RefreshMatching 按模式失效业务码缓存 - 清理匹配业务码的编译知识库和规则缓存，
+	// 下次执行时重新加载，用于共享定义变更影响整个命名空间的场景
+	//
+	// 参数:
+	//   pattern - 包含 * ? [ 时按glob匹配（如 payments.*），否则按前缀匹配
+	//
+	// 返回值:
+	//   []string - 已失效的业务码
+	//   error    - 模式格式错误
+	RefreshMatching(pattern string) ([]string, error)
+
+	// CloneBizCode 复制租户规则集 - 将模板租户业务码的全部规则复制到新租户，
+	// 新规则获得新ID并通过SourceID指向来源规则，目标已存在同名规则时跳过并记入冲突列表
+	//
+	// 租户通过业务码前缀区分：租户 acme 的业务码 payments 对应 acme.payments，
+	// 来源租户为空表示不带前缀的模板业务码。需要规则映射器实现 rule.RuleCloneMapper。
+	//
+	// 参数:
+	//   ctx        - 上下文
+	//   fromTenant - 来源（模板）租户
+	//   toTenant   - 目标租户
+	//   bizCodes   - 要复制的业务码（不含租户前缀）
+	//
+	// 返回值:
+	//   *CloneReport - 复制报告，包含已复制规则和冲突
+	//   error        - 参数无效、映射器不支持（ErrCloneNotSupported）或读写失败
+	CloneBizCode(ctx context.Context, fromTenant, toTenant string, bizCodes ...string) (*CloneReport, error)
+
+	// CloneBizCodeDryRun 演练复制租户规则集 - 只生成复制报告，不写入任何规则
+	CloneBizCodeDryRun(ctx context.Context, fromTenant, toTenant string, bizCodes ...string) (*CloneReport, error)
+}
+
+// RuleAnalysis 规则集的限制检查、补全元数据、测试和静态分析
+type RuleAnalysis interface {
+	// CheckRuleLimits 检查候选规则集是否超出业务码的规模与复杂度限制（WithRuleLimits、WithTenantRuleLimits）
+	//
+	// 引擎在复制租户规则集（CloneBizCode）和添加运行时覆盖（AddOverride）时自动检查；
+	// 应用自己的规则发布流程（如管理后台写库前）调用该方法拒绝病态规则集。
+	//
+	// 参数:
+	//   bizCode - 业务码，按租户前缀查找限制
+	//   rules   - 发布后业务码的完整规则集（含禁用规则）
+	//
+	// 返回值:
+	//   error - 超出限制时返回*RuleLimitError，列出全部超限项，可通过errors.Is(err, ErrRuleLimitExceeded)判断
+	//
+	// 示例:
+	//   if err := engine.CheckRuleLimits("acme.payments", candidate); err != nil {
+	//       return fmt.Errorf("拒绝发布: %w", err)
+	//   }
+	CheckRuleLimits(bizCode string, rules []*rule.Rule) error
+
+	// Completions 获取自动补全元数据 - 返回业务码输入类型的字段路径及类型、内置函数和操作符，
+	// 供规则编辑器使用
+	//
+	// 参数:
+	//   bizCode - 业务码，输入类型通过WithInputType注册
+	//
+	// 返回值:
+	//   *CompletionMetadata - 补全元数据
+	//   error               - 未注册输入类型时返回ErrInputTypeNotRegistered
+	Completions(bizCode string) (*CompletionMetadata, error)
+
+	// RunRuleTests 执行业务码存储的测试用例 - 校验存储中的最新规则（可能尚未同步到缓存）
+	//
+	// 规则和用例直接从映射器读取，在独立知识库中编译执行，不影响线上编译缓存。
+	// 映射器需实现 rule.RuleTestMapper（内置GORM映射器已实现，用例存储于 runehammer_rule_tests 表）。
+	//
+	// 参数:
+	//   ctx     - 上下文
+	//   bizCode - 业务码
+	//
+	// 返回值:
+	//   *RuleTestReport - 测试报告，用例失败时 report.Err() 返回 ErrRuleTestsFailed
+	//   error           - 映射器不支持（ErrRuleTestsNotSupported）、读取失败或规则编译失败
+	RunRuleTests(ctx context.Context, bizCode string) (*RuleTestReport, error)
+
+	// RunBundleTests 以规则包中的规则执行规则包附带的测试用例 - 导入或发布规则包前的校验
+	RunBundleTests(ctx context.Context, bundle *rule.RuleBundle) (*RuleTestReport, error)
+
+	// DataFlow 获取业务码规则集的数据流图 - 用于规则依赖可视化
+	//
+	// 节点为启用的规则，边表示一条规则写入的Result字段被另一条规则读取。
+	// 规则声明了写入字段（Rule.Writes）时以声明为准，未声明时按GRL分析。
+	//
+	// 参数:
+	//   ctx     - 上下文
+	//   bizCode - 业务码
+	//
+	// 返回值:
+	//   *rule.DataFlowGraph - 数据流图，可通过 DOT() 输出Graphviz格式
+	//   error               - 获取规则失败
+	DataFlow(ctx context.Context, bizCode string) (*rule.DataFlowGraph, error)
+
+	// EstimateCost 估算业务码当前规则集的单次执行成本 - 静态分析条件数、正则与查找调用、输入字段数
+	//
+	// 估算基于经验单位成本，用于分级（low/medium/high/extreme）和与 WithLatencyBudget 的预算比较。
+	// 发布前检查候选规则集可直接使用 rule.EstimateCost。
+	//
+	// 参数:
+	//   ctx     - 上下文
+	//   bizCode - 业务码
+	//
+	// 返回值:
+	//   *rule.CostEstimate - 成本估算，包含各规则的明细
+	//   error              - 获取规则失败
+	EstimateCost(ctx context.Context, bizCode string) (*rule.CostEstimate, error)
+}
+
+// engineCapabilities 引擎的全部能力 - New 和 NewBaseEngine 返回的引擎均实现
+type engineCapabilities[T any] interface {
+	Engine[T]
+	VersionedExecutor[T]
+	SessionExecutor[T]
+	Explainer
+	Diagnostics
+	Registry
+	RuleLayers
+	KillSwitch
+	Maintenance
+	RuleAnalysis
+}
+
+// NewBaseEngine 返回的引擎实现除 VersionedExecutor 外的全部可选能力
+var _ interface {
+	BaseEngine
+	SessionExecutor[map[string]interface{}]
+	Explainer
+	Diagnostics
+	Registry
+	RuleLayers
+	KillSwitch
+	Maintenance
+	RuleAnalysis
+} = (*baseEngineWrapper)(nil)
@@ -0,0 +1,80 @@
+package runehammer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// execOnlyEngine 只实现 BaseEngine 的引擎，模拟使用方自行实现的测试替身
+type execOnlyEngine struct{}
+
+func (execOnlyEngine) ExecRaw(ctx context.Context, bizCode string, input any, opts ...ExecOption) (map[string]interface{}, error) {
+	return map[string]interface{}{"ok": true}, nil
+}
+
+func (execOnlyEngine) Close() error { return nil }
+
+func TestCapabilities(t *testing.T) {
+	Convey("引擎可选能力", t, func() {
+		Convey("New 返回的引擎实现全部可选接口", func() {
+			eng, err := New[map[string]any](WithDSN("sqlite:file:capabilities?mode=memory"), WithAutoMigrate())
+			So(err, ShouldBeNil)
+			defer eng.Close()
+
+			_, ok := eng.(engineCapabilities[map[string]any])
+			So(ok, ShouldBeTrue)
+
+			disabled := eng.(KillSwitch).Disabled()
+			So(disabled, ShouldBeEmpty)
+		})
+
+		Convey("NewBaseEngine 返回的引擎实现会话和诊断接口", func() {
+			base, err := NewBaseEngine(WithDSN("sqlite:file:base_capabilities?mode=memory"), WithAutoMigrate())
+			So(err, ShouldBeNil)
+			defer base.Close()
+
+			_, ok := base.(SessionExecutor[map[string]interface{}])
+			So(ok, ShouldBeTrue)
+			_, ok = base.(Diagnostics)
+			So(ok, ShouldBeTrue)
+		})
+
+		Convey("只实现 BaseEngine 的引擎可包装为 TypedEngine", func() {
+			typed := NewTypedEngine[map[string]any](execOnlyEngine{})
+
+			result, err := typed.Exec(context.Background(), "loan", nil)
+			So(err, ShouldBeNil)
+			So(result["ok"], ShouldBeTrue)
+
+			_, err = typed.Explain(context.Background(), "loan", nil)
+			So(errors.Is(err, errors.ErrUnsupported), ShouldBeTrue)
+
+			for _, err := range typed.StreamResults(context.Background(), "loan", nil, "offers", 0) {
+				So(errors.Is(err, errors.ErrUnsupported), ShouldBeTrue)
+			}
+		})
+
+		Convey("TypedEngine.ExecVersion 不修改调用方选项切片的底层数组", func() {
+			typed := NewTypedEngine[map[string]any](execOnlyEngine{})
+			opts := make([]ExecOption, 1, 2)
+			opts[0] = WithProfiling()
+
+			_, err := typed.ExecVersion(context.Background(), "loan", 1, nil, opts...)
+			So(err, ShouldBeNil)
+			So(opts[:2][1], ShouldBeNil)
+		})
+
+		Convey("TypedEngine.ExecWhere 不修改调用方选项切片的底层数组", func() {
+			typed := NewTypedEngine[map[string]any](execOnlyEngine{})
+			opts := make([]ExecOption, 1, 2)
+			opts[0] = WithProfiling()
+
+			_, err := typed.ExecWhere(context.Background(), "loan", "name = 'a'", nil, opts...)
+			So(err, ShouldBeNil)
+			So(opts[:2][1], ShouldBeNil)
+		})
+	})
+}
@@ -0,0 +1,569 @@
+package codegen
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gitee.com/damengde/runehammer/rule"
+)
+
+// ============================================================================
+// 代码生成 - 将冻结的标准规则翻译为普通Go函数，用于对延迟极其敏感的热路径
+// ============================================================================
+//
+// 生成器先用规则转换器把标准规则转换为GRL（与解释执行使用同一份GRL），
+// 再把GRL的条件和动作翻译为带类型的Go代码。生成的函数按Grule的执行语义运行:
+// 每轮在未执行的规则中选出条件成立且优先级最高的一条执行，执行后重新匹配，
+// 直到没有可执行的规则；每条规则最多执行一次（转换器生成的规则执行后即Retract）。
+//
+// 只支持可以静态确定类型的规则: 条件和计算表达式只能引用输入结构体的字段、
+// 字面量以及比较、逻辑和算术运算；动作只能为Result字段或输入字段赋值。
+// 函数调用（包括 in/contains/matches 等函数式操作符）、读取Result、日志和告警动作
+// 均返回 ErrUnsupported。生成结果应通过 CheckEquivalence 与解释执行比对。
+
+// ErrUnsupported 规则包含无法生成Go代码的结构，可通过errors.Is判断
+var ErrUnsupported = errors.New("规则不支持生成Go代码")
+
+// defaultFunc 默认生成的函数名
+const defaultFunc = "Evaluate"
+
+// Options 代码生成选项
+type Options struct {
+	Package    string             // 生成代码的包名
+	Func       string             // 生成的函数名，默认Evaluate
+	Input      any                // 输入类型样例（结构体或结构体指针），决定函数参数类型及规则中的事实名（类型名小写）
+	ImportPath string             // 生成代码所在包的导入路径，与输入类型同包时省略包限定
+	BizCode    string             // 业务码，仅用于生成代码的注释
+	Converter  *rule.GRLConverter // 规则转换器，为空时使用默认配置；需与解释执行使用的转换器一致
+}
+
+// converter 获取规则转换器
+func (o Options) converter() *rule.GRLConverter {
+	if o.Converter != nil {
+		return o.Converter
+	}
+	return rule.NewGRLConverter()
+}
+
+// inputType 输入结构体类型
+func (o Options) inputType() (reflect.Type, error) {
+	t := reflect.TypeOf(o.Input)
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct || t.Name() == "" {
+		return nil, fmt.Errorf("输入类型必须是具名结构体或其指针: %T", o.Input)
+	}
+	return t, nil
+}
+
+// compiledRule 翻译后的规则
+type compiledRule struct {
+	id         string
+	salience   int
+	condition  string
+	statements []string
+}
+
+var (
+	grlRuleHeadRegex = regexp.MustCompile(`(?m)^\s*rule\s+([A-Za-z_]\w*)`)
+	grlBodyRegex     = regexp.MustCompile(`(?s)\bwhen\s*\n(.*?)\n\s*then\s*\n(.*)\}\s*$`)
+	grlRetractRegex  = regexp.MustCompile(`^Retract\(\s*"[^"]*"\s*\)$`)
+)
+
+// Generate 根据标准规则生成Go源代码
+//
+// 生成的函数签名为 func <Func>(<fact> *<Input>) map[string]any，参数名即规则中引用的事实名，
+// 规则对输入字段的赋值直接修改传入的结构体。禁用的规则不生成代码。
+//
+// 参数:
+//
+//	rules - 标准规则列表，顺序决定同优先级规则的匹配顺序
+//	opts  - 生成选项
+//
+// 返回值:
+//
+//	[]byte - 已格式化的Go源代码
+//	error  - 选项无效或规则不支持时返回错误（可通过errors.Is判断ErrUnsupported）
+func Generate(rules []rule.StandardRule, opts Options) ([]byte, error) {
+	if opts.Package == "" {
+		return nil, fmt.Errorf("生成代码的包名不能为空")
+	}
+	if opts.Func == "" {
+		opts.Func = defaultFunc
+	}
+	inputType, err := opts.inputType()
+	if err != nil {
+		return nil, err
+	}
+	fact := strings.ToLower(inputType.Name())
+	if fact == "result" || fact == "fired" {
+		return nil, fmt.Errorf("%w: 输入类型名 %s 与生成代码的局部变量冲突", ErrUnsupported, inputType.Name())
+	}
+
+	tr := &translator{fact: fact, input: inputType}
+	converter := opts.converter()
+	var compiled []compiledRule
+	for _, r := range rules {
+		if !r.Enabled {
+			continue
+		}
+		grl, err := converter.ConvertRule(r, rule.Definitions{})
+		if err != nil {
+			return nil, fmt.Errorf("转换规则 %s 失败: %w", r.ID, err)
+		}
+		c, err := tr.compileRule(grl)
+		if err != nil {
+			return nil, fmt.Errorf("规则 %s: %w", r.ID, err)
+		}
+		compiled = append(compiled, c)
+	}
+	sort.SliceStable(compiled, func(i, j int) bool {
+		return compiled[i].salience > compiled[j].salience
+	})
+
+	typeName := inputType.Name()
+	var imports []string
+	if inputType.PkgPath() != opts.ImportPath {
+		typeName = inputType.String()
+		imports = append(imports, inputType.PkgPath())
+	}
+
+	return render(opts, fact, typeName, imports, compiled)
+}
+
+// render 输出Go源代码
+func render(opts Options, fact, typeName string, imports []string, rules []compiledRule) ([]byte, error) {
+	var sb strings.Builder
+	sb.WriteString("// Code generated by runehammer codegen. DO NOT EDIT.\n\n")
+	sb.WriteString("package " + opts.Package + "\n\n")
+	for _, path := range imports {
+		sb.WriteString(fmt.Sprintf("import %q\n\n", path))
+	}
+
+	source := "规则"
+	if opts.BizCode != "" {
+		source = "业务码 " + opts.BizCode + " 的规则"
+	}
+	sb.WriteString(fmt.Sprintf("// %s 执行%s（共%d条），与解释执行语义一致\n", opts.Func, source, len(rules)))
+	sb.WriteString("//\n")
+	sb.WriteString("// 规则按优先级从高到低匹配，每条规则最多执行一次，执行后重新从最高优先级开始匹配，\n")
+	sb.WriteString("// 直到没有可执行的规则。\n")
+	sb.WriteString(fmt.Sprintf("func %s(%s *%s) map[string]any {\n", opts.Func, fact, typeName))
+	sb.WriteString("result := make(map[string]any)\n")
+	if len(rules) == 0 {
+		sb.WriteString("return result\n}\n")
+		return formatSource(sb.String())
+	}
+
+	sb.WriteString(fmt.Sprintf("var fired [%d]bool\n", len(rules)))
+	sb.WriteString("for {\nswitch {\n")
+	for i, r := range rules {
+		sb.WriteString(fmt.Sprintf("case !fired[%d] && (%s): // %s salience %d\n", i, r.condition, r.id, r.salience))
+		sb.WriteString(fmt.Sprintf("fired[%d] = true\n", i))
+		for _, stmt := range r.statements {
+			sb.WriteString(stmt + "\n")
+		}
+	}
+	sb.WriteString("default:\nreturn result\n}\n}\n}\n")
+	return formatSource(sb.String())
+}
+
+// formatSource 格式化生成的源代码
+func formatSource(source string) ([]byte, error) {
+	formatted, err := format.Source([]byte(source))
+	if err != nil {
+		return nil, fmt.Errorf("格式化生成代码失败: %w", err)
+	}
+	return formatted, nil
+}
+
+// ============================================================================
+// GRL翻译
+// ============================================================================
+
+// translator GRL到Go的翻译器
+type translator struct {
+	fact  string       // 事实名，即生成函数的参数名
+	input reflect.Type // 输入结构体类型
+}
+
+// compileRule 翻译转换器生成的单条GRL规则
+func (tr *translator) compileRule(grl string) (compiledRule, error) {
+	head := grlRuleHeadRegex.FindStringSubmatch(grl)
+	body := grlBodyRegex.FindStringSubmatch(grl)
+	if head == nil || body == nil {
+		return compiledRule{}, fmt.Errorf("%w: 无法识别的GRL结构", ErrUnsupported)
+	}
+	c := compiledRule{id: head[1], salience: rule.RuleSalience(grl)}
+
+	cond, err := parser.ParseExpr(strings.TrimSpace(body[1]))
+	if err != nil {
+		return c, fmt.Errorf("%w: 无法解析条件: %v", ErrUnsupported, err)
+	}
+	v, err := tr.expr(cond)
+	if err != nil {
+		return c, err
+	}
+	if v.kind != kindBool {
+		return c, fmt.Errorf("%w: 条件不是布尔表达式", ErrUnsupported)
+	}
+	c.condition = v.code
+
+	for _, line := range strings.Split(body[2], "\n") {
+		line = strings.TrimSuffix(strings.TrimSpace(line), ";")
+		if line == "" || grlRetractRegex.MatchString(line) {
+			continue
+		}
+		stmt, err := tr.statement(line)
+		if err != nil {
+			return c, err
+		}
+		c.statements = append(c.statements, stmt)
+	}
+	return c, nil
+}
+
+// statement 翻译赋值动作
+func (tr *translator) statement(line string) (string, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), "", "package p\nfunc _() {\n"+line+"\n}", 0)
+	if err != nil {
+		return "", fmt.Errorf("%w: 无法解析动作 %s", ErrUnsupported, line)
+	}
+	body := file.Decls[0].(*ast.FuncDecl).Body.List
+	assign, ok := body[0].(*ast.AssignStmt)
+	if len(body) != 1 || !ok || assign.Tok != token.ASSIGN || len(assign.Lhs) != 1 || len(assign.Rhs) != 1 {
+		return "", fmt.Errorf("%w: 只支持赋值动作: %s", ErrUnsupported, line)
+	}
+
+	value, err := tr.expr(assign.Rhs[0])
+	if err != nil {
+		return "", err
+	}
+
+	// Result字段赋值
+	if index, ok := assign.Lhs[0].(*ast.IndexExpr); ok {
+		if ident, ok := index.X.(*ast.Ident); ok && ident.Name == "Result" {
+			if key, ok := index.Index.(*ast.BasicLit); ok && key.Kind == token.STRING {
+				return fmt.Sprintf("result[%s] = %s", key.Value, value.code), nil
+			}
+		}
+		return "", fmt.Errorf("%w: 不支持的赋值目标: %s", ErrUnsupported, line)
+	}
+
+	// 输入字段赋值
+	target, err := tr.expr(assign.Lhs[0])
+	if err != nil || target.typ == nil {
+		return "", fmt.Errorf("%w: 不支持的赋值目标: %s", ErrUnsupported, line)
+	}
+	if !assignable(value, target) {
+		return "", fmt.Errorf("%w: %s 的值类型与字段类型不一致", ErrUnsupported, line)
+	}
+	return fmt.Sprintf("%s = %s", target.code, value.code), nil
+}
+
+// valueKind 值类别
+type valueKind int
+
+const (
+	kindBool valueKind = iota
+	kindInt
+	kindFloat
+	kindString
+)
+
+var (
+	int64Type   = reflect.TypeOf(int64(0))
+	float64Type = reflect.TypeOf(float64(0))
+	boolType    = reflect.TypeOf(false)
+	stringType  = reflect.TypeOf("")
+)
+
+// value 翻译后的表达式
+type value struct {
+	code string
+	kind valueKind
+	typ  reflect.Type // 值的Go类型，为nil表示无类型常量
+}
+
+// expr 翻译表达式
+func (tr *translator) expr(node ast.Expr) (value, error) {
+	switch n := node.(type) {
+	case *ast.ParenExpr:
+		inner, err := tr.expr(n.X)
+		if err != nil {
+			return value{}, err
+		}
+		inner.code = "(" + inner.code + ")"
+		return inner, nil
+
+	case *ast.BasicLit:
+		switch n.Kind {
+		case token.INT:
+			return value{code: n.Value, kind: kindInt}, nil
+		case token.FLOAT:
+			return value{code: n.Value, kind: kindFloat}, nil
+		case token.STRING:
+			return value{code: n.Value, kind: kindString}, nil
+		}
+
+	case *ast.Ident:
+		if n.Name == "true" || n.Name == "false" {
+			return value{code: n.Name, kind: kindBool}, nil
+		}
+
+	case *ast.SelectorExpr:
+		return tr.field(n)
+
+	case *ast.UnaryExpr:
+		return tr.unary(n)
+
+	case *ast.BinaryExpr:
+		return tr.binary(n)
+
+	case *ast.IndexExpr:
+		return value{}, fmt.Errorf("%w: 不支持读取Result或下标访问", ErrUnsupported)
+
+	case *ast.CallExpr:
+		return value{}, fmt.Errorf("%w: 不支持函数调用 %s", ErrUnsupported, exprString(n.Fun))
+	}
+	return value{}, fmt.Errorf("%w: 不支持的表达式 %s", ErrUnsupported, exprString(node))
+}
+
+// field 翻译输入字段引用，如 order.Customer.Level
+func (tr *translator) field(node *ast.SelectorExpr) (value, error) {
+	var path []string
+	var cur ast.Expr = node
+	for {
+		switch n := cur.(type) {
+		case *ast.SelectorExpr:
+			path = append([]string{n.Sel.Name}, path...)
+			cur = n.X
+			continue
+		case *ast.Ident:
+			path = append([]string{n.Name}, path...)
+		default:
+			return value{}, fmt.Errorf("%w: 不支持的字段引用 %s", ErrUnsupported, exprString(node))
+		}
+		break
+	}
+
+	if path[0] == "Result" || path[0] == "result" {
+		return value{}, fmt.Errorf("%w: 不支持读取Result", ErrUnsupported)
+	}
+	if path[0] != tr.fact {
+		return value{}, fmt.Errorf("%w: 未知的事实 %s（输入事实名为 %s）", ErrUnsupported, path[0], tr.fact)
+	}
+	t := tr.input
+	for i, name := range path[1:] {
+		if t.Kind() != reflect.Struct {
+			return value{}, fmt.Errorf("%w: %s 不是结构体字段", ErrUnsupported, strings.Join(path[:i+1], "."))
+		}
+		f, ok := t.FieldByName(name)
+		if !ok || !f.IsExported() {
+			return value{}, fmt.Errorf("%w: 输入类型没有导出字段 %s", ErrUnsupported, strings.Join(path[:i+2], "."))
+		}
+		t = f.Type
+	}
+
+	v := value{code: strings.Join(path, "."), typ: t}
+	switch t.Kind() {
+	case reflect.Bool:
+		v.kind = kindBool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v.kind = kindInt
+	case reflect.Float32, reflect.Float64:
+		v.kind = kindFloat
+	case reflect.String:
+		v.kind = kindString
+	default:
+		return value{}, fmt.Errorf("%w: 字段 %s 的类型 %s 不支持", ErrUnsupported, v.code, t)
+	}
+	return v, nil
+}
+
+// unary 翻译一元表达式 - 逻辑非和负数字面量
+func (tr *translator) unary(node *ast.UnaryExpr) (value, error) {
+	x, err := tr.expr(node.X)
+	if err != nil {
+		return value{}, err
+	}
+	switch {
+	case node.Op == token.NOT && x.kind == kindBool:
+		return value{code: "!" + x.code, kind: kindBool, typ: x.typ}, nil
+	case node.Op == token.SUB && x.typ == nil && (x.kind == kindInt || x.kind == kindFloat):
+		return value{code: "-" + x.code, kind: x.kind}, nil
+	}
+	return value{}, fmt.Errorf("%w: 不支持的一元运算 %s", ErrUnsupported, exprString(node))
+}
+
+// binary 翻译二元表达式
+func (tr *translator) binary(node *ast.BinaryExpr) (value, error) {
+	a, err := tr.expr(node.X)
+	if err != nil {
+		return value{}, err
+	}
+	b, err := tr.expr(node.Y)
+	if err != nil {
+		return value{}, err
+	}
+	op := node.Op.String()
+	join := func(a, b value) string { return a.code + " " + op + " " + b.code }
+
+	switch node.Op {
+	case token.LAND, token.LOR:
+		if a.kind == kindBool && b.kind == kindBool {
+			return value{code: join(a, b), kind: kindBool, typ: boolType}, nil
+		}
+
+	case token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ:
+		switch {
+		case a.kind == kindBool && b.kind == kindBool && (node.Op == token.EQL || node.Op == token.NEQ):
+			a, b = unifyTyped(a, b, boolType)
+		case a.kind == kindString && b.kind == kindString:
+			a, b = unifyTyped(a, b, stringType)
+		case isNumeric(a) && isNumeric(b):
+			a, b = unifyNumeric(a, b, false)
+		default:
+			return value{}, fmt.Errorf("%w: 比较的操作数类型不一致: %s", ErrUnsupported, exprString(node))
+		}
+		return value{code: join(a, b), kind: kindBool, typ: boolType}, nil
+
+	case token.ADD, token.SUB, token.MUL:
+		if node.Op == token.ADD && a.kind == kindString && b.kind == kindString {
+			a, b = unifyTyped(a, b, stringType)
+			return value{code: join(a, b), kind: kindString, typ: a.typ}, nil
+		}
+		if isNumeric(a) && isNumeric(b) {
+			a, b = unifyNumeric(a, b, true)
+			return arithmetic(join(a, b), a, b), nil
+		}
+
+	case token.QUO:
+		// Grule的除法总是按浮点数计算
+		if isNumeric(a) && isNumeric(b) {
+			return value{code: toFloat(a).code + " / " + toFloat(b).code, kind: kindFloat, typ: float64Type}, nil
+		}
+
+	case token.REM:
+		if a.kind == kindInt && b.kind == kindInt {
+			if b.typ != nil || strings.Trim(b.code, "0") == "" {
+				return value{}, fmt.Errorf("%w: 取模的除数必须为非零整数常量", ErrUnsupported)
+			}
+			a, b = unifyNumeric(a, b, true)
+			return arithmetic(join(a, b), a, b), nil
+		}
+	}
+	return value{}, fmt.Errorf("%w: 不支持的运算 %s", ErrUnsupported, exprString(node))
+}
+
+// arithmetic 算术运算结果 - 两侧均为无类型常量时结果仍为无类型常量
+func arithmetic(code string, a, b value) value {
+	v := value{code: code, kind: kindInt}
+	if a.kind == kindFloat || b.kind == kindFloat {
+		v.kind = kindFloat
+	}
+	if a.typ != nil {
+		v.typ = a.typ
+	} else {
+		v.typ = b.typ
+	}
+	return v
+}
+
+// isNumeric 是否为数值
+func isNumeric(v value) bool {
+	return v.kind == kindInt || v.kind == kindFloat
+}
+
+// unifyTyped 统一两个同类别操作数的类型 - 类型不同的具名类型转换为基础类型
+func unifyTyped(a, b value, base reflect.Type) (value, value) {
+	if a.typ == nil || b.typ == nil || a.typ == b.typ {
+		return a, b
+	}
+	return convert(a, base), convert(b, base)
+}
+
+// unifyNumeric 统一数值操作数的类型，与Grule一致: 整数按int64、浮点数按float64计算，混合时按float64
+//
+// widen 为true时（算术运算）有类型的整数扩展为int64，避免窄类型溢出；浮点数总是按float64比较和计算。
+func unifyNumeric(a, b value, widen bool) (value, value) {
+	switch {
+	case a.typ == nil && b.typ == nil:
+		return a, b
+	case a.typ == nil || b.typ == nil:
+		typed, constant := a, b
+		if typed.typ == nil {
+			typed, constant = b, a
+		}
+		if constant.kind == kindFloat && typed.kind == kindInt {
+			typed = toFloat(typed)
+		} else if widen || typed.kind == kindFloat {
+			typed = widenValue(typed)
+		}
+		if a.typ == nil {
+			return constant, typed
+		}
+		return typed, constant
+	case a.typ == b.typ && !widen && a.kind == kindInt:
+		return a, b
+	}
+
+	a, b = widenValue(a), widenValue(b)
+	if a.kind != b.kind {
+		a, b = toFloat(a), toFloat(b)
+	}
+	return a, b
+}
+
+// widenValue 将有类型的数值扩展为int64或float64
+func widenValue(v value) value {
+	if v.typ == nil {
+		return v
+	}
+	if v.kind == kindFloat {
+		return convert(v, float64Type)
+	}
+	return convert(v, int64Type)
+}
+
+// toFloat 转换为float64
+func toFloat(v value) value {
+	v = convert(v, float64Type)
+	v.kind = kindFloat
+	return v
+}
+
+// convert 类型转换，类型相同时保持不变
+func convert(v value, t reflect.Type) value {
+	if v.typ == t {
+		return v
+	}
+	return value{code: t.String() + "(" + v.code + ")", kind: v.kind, typ: t}
+}
+
+// assignable 值是否可以直接赋给字段
+func assignable(v, field value) bool {
+	if v.typ != nil {
+		return v.typ == field.typ
+	}
+	return v.kind == field.kind || (v.kind == kindInt && field.kind == kindFloat)
+}
+
+// exprString 表达式源码，用于错误信息
+func exprString(node ast.Expr) string {
+	var sb strings.Builder
+	if err := format.Node(&sb, token.NewFileSet(), node); err != nil {
+		return fmt.Sprintf("%T", node)
+	}
+	return sb.String()
+}
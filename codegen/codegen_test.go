@@ -0,0 +1,164 @@
+package codegen
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"os"
+	"testing"
+
+	"gitee.com/damengde/runehammer/codegen/internal/example"
+	"gitee.com/damengde/runehammer/rule"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// update 重新生成示例代码: go test ./codegen -run TestGenerate -update
+var update = flag.Bool("update", false, "重新生成 internal/example 中的代码")
+
+// exampleFile 示例生成代码的路径
+const exampleFile = "internal/example/evaluate_gen.go"
+
+// exampleRules 示例规则集
+func exampleRules() []rule.StandardRule {
+	vip := rule.NewStandardRule("vip_discount", "VIP折扣")
+	vip.Priority = 100
+	vip.AddSimpleCondition("order.VIP", rule.OpEqual, true).
+		AddSimpleCondition("order.Amount", rule.OpGreaterThanOrEqual, 1000).
+		AddAction(rule.ActionTypeAssign, "Result.discount", 0.2).
+		AddAction(rule.ActionTypeAssign, "order.Status", "vip")
+
+	tier := rule.NewStandardRule("vip_tier", "VIP等级")
+	tier.Priority = 40
+	tier.AddSimpleCondition("order.Status", rule.OpEqual, "vip").
+		AddAction(rule.ActionTypeAssign, "Result.tier", "gold")
+
+	shipping := rule.NewStandardRule("shipping", "运费")
+	shipping.Priority = 60
+	shipping.Conditions = rule.Condition{Type: rule.ConditionTypeComposite, Operator: rule.OpOr, Children: []rule.Condition{
+		{Type: rule.ConditionTypeSimple, Left: "order.Country", Operator: rule.OpNotEqual, Right: "CN"},
+		{Type: rule.ConditionTypeSimple, Left: "order.Weight", Operator: rule.OpGreaterThan, Right: 20.5},
+	}}
+	shipping.Actions = []rule.Action{{Type: rule.ActionTypeCalculate, Target: "Result.shipping", Expression: "order.Weight * 2 + 10"}}
+
+	bulk := rule.NewStandardRule("bulk", "批量")
+	bulk.AddSimpleCondition("order.Items", rule.OpBetween, []int{10, 100}).
+		AddAction(rule.ActionTypeAssign, "Result.bulk", true)
+	bulk.Actions = append(bulk.Actions, rule.Action{Type: rule.ActionTypeCalculate, Target: "Result.perItem", Expression: "order.Amount / order.Items"})
+
+	segment := rule.NewStandardRule("enterprise", "企业客户")
+	segment.Priority = 50
+	segment.Conditions = rule.Condition{Type: rule.ConditionTypeExpression,
+		Expression: `order.Customer.Level > 2 && order.Customer.Segment == "enterprise" && !order.VIP`}
+	segment.Actions = []rule.Action{{Type: rule.ActionTypeCalculate, Target: "Result.credit", Expression: "order.Customer.Level * 1000 + order.Amount % 7"}}
+
+	disabled := rule.NewStandardRule("disabled", "禁用")
+	disabled.Enabled = false
+	disabled.AddSimpleCondition("order.Amount", rule.OpGreaterThan, 0).
+		AddAction(rule.ActionTypeAssign, "Result.disabled", true)
+
+	return []rule.StandardRule{*vip, *tier, *shipping, *bulk, *segment, *disabled}
+}
+
+// exampleOptions 示例生成选项
+func exampleOptions() Options {
+	return Options{
+		Package:    "example",
+		Input:      example.Order{},
+		ImportPath: "gitee.com/damengde/runehammer/codegen/internal/example",
+		BizCode:    "ORDER_PRICING",
+	}
+}
+
+// TestGenerate 测试规则代码生成
+func TestGenerate(t *testing.T) {
+	Convey("规则代码生成", t, func() {
+		rules := exampleRules()
+		opts := exampleOptions()
+
+		Convey("生成代码与示例文件一致", func() {
+			source, err := Generate(rules, opts)
+			So(err, ShouldBeNil)
+			if *update {
+				So(os.WriteFile(exampleFile, source, 0o644), ShouldBeNil)
+			}
+			existing, err := os.ReadFile(exampleFile)
+			So(err, ShouldBeNil)
+			So(string(source), ShouldEqual, string(existing))
+		})
+
+		Convey("生成代码与解释执行等价", func() {
+			inputs := []any{
+				example.Order{Amount: 1500, VIP: true, Country: "CN", Weight: 3.5, Items: 2},
+				example.Order{Amount: 800, VIP: true, Country: "US", Weight: 30.25, Items: 12},
+				&example.Order{Amount: 2001, Country: "CN", Weight: 1, Items: 100, Customer: example.Customer{Level: 3, Segment: "enterprise"}},
+				example.Order{Amount: 999, Country: "CN", Weight: 20.5, Items: 9, Customer: example.Customer{Level: 2, Segment: "enterprise"}},
+				example.Order{},
+			}
+			report, err := CheckEquivalence(context.Background(), rules, opts, func(in any) map[string]any {
+				return example.Evaluate(in.(*example.Order))
+			}, inputs)
+			So(err, ShouldBeNil)
+			So(report.Cases, ShouldEqual, len(inputs))
+			So(report.Mismatches, ShouldBeEmpty)
+			So(report.OK(), ShouldBeTrue)
+
+			order := &example.Order{Amount: 1500, VIP: true, Country: "CN"}
+			result := example.Evaluate(order)
+			So(result, ShouldResemble, map[string]any{"discount": 0.2, "tier": "gold"})
+			So(order.Status, ShouldEqual, "vip")
+		})
+
+		Convey("等价性校验报告不一致项", func() {
+			report, err := CheckEquivalence(context.Background(), rules, opts, func(in any) map[string]any {
+				return map[string]any{}
+			}, []any{example.Order{Amount: 1500, VIP: true, Country: "CN"}})
+			So(err, ShouldBeNil)
+			So(report.OK(), ShouldBeFalse)
+			So(report.Mismatches[0].Target, ShouldEqual, "result")
+			So(report.Mismatches[0].Interpreted, ShouldResemble, map[string]any{"discount": 0.2, "tier": "gold"})
+
+			_, err = CheckEquivalence(context.Background(), rules, opts, nil, []any{42})
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("不支持的规则返回ErrUnsupported", func() {
+			unsupported := map[string]rule.StandardRule{
+				"函数式操作符": *rule.NewStandardRule("in", "in").
+					AddSimpleCondition("order.Country", rule.OpContains, "C").
+					AddAction(rule.ActionTypeAssign, "Result.x", 1),
+				"读取Result": *rule.NewStandardRule("read", "read").
+					AddSimpleCondition("Result.tier", rule.OpEqual, "gold").
+					AddAction(rule.ActionTypeAssign, "Result.x", 1),
+				"日志动作": *rule.NewStandardRule("log", "log").
+					AddSimpleCondition("order.Amount", rule.OpGreaterThan, 1).
+					AddAction(rule.ActionTypeLog, "", "hit"),
+				"类型不一致": *rule.NewStandardRule("mixed", "mixed").
+					AddSimpleCondition("order.Amount", rule.OpEqual, "big").
+					AddAction(rule.ActionTypeAssign, "Result.x", 1),
+				"未知字段": *rule.NewStandardRule("unknown", "unknown").
+					AddSimpleCondition("order.Missing", rule.OpEqual, 1).
+					AddAction(rule.ActionTypeAssign, "Result.x", 1),
+				"字段赋值类型不一致": *rule.NewStandardRule("assign", "assign").
+					AddSimpleCondition("order.VIP", rule.OpEqual, true).
+					AddAction(rule.ActionTypeAssign, "order.Items", "many"),
+			}
+			for _, r := range unsupported {
+				_, err := Generate([]rule.StandardRule{r}, opts)
+				So(errors.Is(err, ErrUnsupported), ShouldBeTrue)
+				So(err.Error(), ShouldContainSubstring, r.ID)
+			}
+		})
+
+		Convey("选项校验", func() {
+			_, err := Generate(rules, Options{Input: example.Order{}})
+			So(err, ShouldNotBeNil)
+			_, err = Generate(rules, Options{Package: "p", Input: map[string]any{}})
+			So(err, ShouldNotBeNil)
+
+			source, err := Generate(nil, Options{Package: "fast", Func: "Run", Input: &example.Order{}})
+			So(err, ShouldBeNil)
+			So(string(source), ShouldContainSubstring, `import "gitee.com/damengde/runehammer/codegen/internal/example"`)
+			So(string(source), ShouldContainSubstring, "func Run(order *example.Order) map[string]any {")
+		})
+	})
+}
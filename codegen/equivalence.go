@@ -0,0 +1,151 @@
+package codegen
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	"gitee.com/damengde/runehammer/engine"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+)
+
+// equivalenceBizCode 等价性校验使用的业务码
+const equivalenceBizCode = "codegen_equivalence"
+
+// ============================================================================
+// 等价性校验 - 对比生成代码与解释执行的结果
+// ============================================================================
+
+// EquivalenceReport 等价性校验报告
+type EquivalenceReport struct {
+	Cases      int                   // 校验的输入数
+	Mismatches []EquivalenceMismatch // 不一致项
+}
+
+// OK 是否全部一致
+func (r *EquivalenceReport) OK() bool {
+	return len(r.Mismatches) == 0
+}
+
+// EquivalenceMismatch 不一致项
+type EquivalenceMismatch struct {
+	Case        int    `json:"case"`        // 输入序号
+	Target      string `json:"target"`      // 不一致的对象: result 为执行结果，input 为规则修改后的输入
+	Interpreted any    `json:"interpreted"` // 解释执行的值
+	Generated   any    `json:"generated"`   // 生成代码的值
+	Error       string `json:"error"`       // 解释执行失败时的错误
+}
+
+// CheckEquivalence 用同一组输入分别执行解释器和生成的函数，比对结果与规则修改后的输入
+//
+// 解释执行使用与线上一致的规则引擎执行路径（结构体输入以类型名小写注入）。
+// 每个输入复制两份分别交给两侧执行，互不影响；比较前按JSON归一化，因此 int64(1) 与 float64(1) 视为相同。
+//
+// 参数:
+//
+//	ctx       - 上下文
+//	rules     - 生成代码使用的标准规则
+//	opts      - 生成代码使用的选项（使用其中的转换器）
+//	generated - 生成的函数，通常为 func(in any) map[string]any { return pkg.Evaluate(in.(*Order)) }
+//	inputs    - 输入样例，结构体或结构体指针
+//
+// 返回值:
+//
+//	*EquivalenceReport - 校验报告
+//	error              - 规则转换失败或输入不是结构体时返回错误
+func CheckEquivalence(ctx context.Context, rules []rule.StandardRule, opts Options, generated func(input any) map[string]any, inputs []any) (*EquivalenceReport, error) {
+	converter := opts.converter()
+	var compiled staticRules
+	for _, r := range rules {
+		if !r.Enabled {
+			continue
+		}
+		grl, err := converter.ConvertRule(r, rule.Definitions{})
+		if err != nil {
+			return nil, fmt.Errorf("转换规则 %s 失败: %w", r.ID, err)
+		}
+		compiled = append(compiled, &rule.Rule{BizCode: equivalenceBizCode, Name: r.ID, GRL: grl, Enabled: true})
+	}
+	if len(compiled) == 0 {
+		return nil, fmt.Errorf("没有启用的规则")
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.CacheType = config.CacheTypeNone
+	interpreter := engine.NewEngineImpl[map[string]any](
+		cfg, compiled, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+		ast.NewKnowledgeLibrary(), nil, cron.New(), false,
+	)
+	defer interpreter.Close()
+
+	report := &EquivalenceReport{Cases: len(inputs)}
+	for i, input := range inputs {
+		interpretedInput, err := copyInput(input)
+		if err != nil {
+			return nil, fmt.Errorf("输入 %d: %w", i, err)
+		}
+		generatedInput, _ := copyInput(input)
+
+		generatedResult := generated(generatedInput)
+		interpretedResult, err := interpreter.Exec(ctx, equivalenceBizCode, interpretedInput)
+		if err != nil {
+			report.Mismatches = append(report.Mismatches, EquivalenceMismatch{
+				Case: i, Target: "result", Generated: normalize(generatedResult), Error: err.Error(),
+			})
+			continue
+		}
+
+		if left, right := normalize(interpretedResult), normalize(generatedResult); !reflect.DeepEqual(left, right) {
+			report.Mismatches = append(report.Mismatches, EquivalenceMismatch{
+				Case: i, Target: "result", Interpreted: left, Generated: right,
+			})
+		}
+		if left, right := normalize(interpretedInput), normalize(generatedInput); !reflect.DeepEqual(left, right) {
+			report.Mismatches = append(report.Mismatches, EquivalenceMismatch{
+				Case: i, Target: "input", Interpreted: left, Generated: right,
+			})
+		}
+	}
+	return report, nil
+}
+
+// staticRules 固定规则列表 - 作为解释执行的规则映射器
+type staticRules []*rule.Rule
+
+// FindByBizCode 实现rule.RuleMapper接口
+func (s staticRules) FindByBizCode(ctx context.Context, bizCode string) ([]*rule.Rule, error) {
+	return s, nil
+}
+
+// copyInput 复制结构体输入，返回指向副本的指针
+func copyInput(input any) (any, error) {
+	v := reflect.ValueOf(input)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("输入必须是结构体或结构体指针: %T", input)
+	}
+	copied := reflect.New(v.Type())
+	copied.Elem().Set(v)
+	return copied.Interface(), nil
+}
+
+// normalize 按JSON归一化，消除数值类型差异
+func normalize(v any) any {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var out any
+	if err := json.Unmarshal(data, &out); err != nil {
+		return v
+	}
+	return out
+}
@@ -0,0 +1,35 @@
+// Code generated by runehammer codegen. DO NOT EDIT.
+
+package example
+
+// Evaluate 执行业务码 ORDER_PRICING 的规则（共5条），与解释执行语义一致
+//
+// 规则按优先级从高到低匹配，每条规则最多执行一次，执行后重新从最高优先级开始匹配，
+// 直到没有可执行的规则。
+func Evaluate(order *Order) map[string]any {
+	result := make(map[string]any)
+	var fired [5]bool
+	for {
+		switch {
+		case !fired[0] && ((order.VIP == true) && (order.Amount >= 1000)): // vip_discount salience 100
+			fired[0] = true
+			result["discount"] = 0.2
+			order.Status = "vip"
+		case !fired[1] && ((order.Country != "CN") || (float64(order.Weight) > 20.5)): // shipping salience 60
+			fired[1] = true
+			result["shipping"] = float64(order.Weight)*2 + 10
+		case !fired[2] && (order.Items >= 10 && order.Items <= 100): // bulk salience 50
+			fired[2] = true
+			result["bulk"] = true
+			result["perItem"] = float64(order.Amount) / float64(order.Items)
+		case !fired[3] && (order.Customer.Level > 2 && order.Customer.Segment == "enterprise" && !order.VIP): // enterprise salience 50
+			fired[3] = true
+			result["credit"] = int64(order.Customer.Level)*1000 + int64(order.Amount)%7
+		case !fired[4] && (order.Status == "vip"): // vip_tier salience 40
+			fired[4] = true
+			result["tier"] = "gold"
+		default:
+			return result
+		}
+	}
+}
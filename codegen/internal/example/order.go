@@ -0,0 +1,19 @@
+// Package example 代码生成示例 - 测试用的输入类型及由 codegen 生成的函数
+package example
+
+// Order 订单
+type Order struct {
+	Amount   int      // 订单金额
+	Weight   float32  // 重量（千克）
+	Country  string   // 收货国家
+	VIP      bool     // 是否VIP
+	Items    int32    // 商品件数
+	Status   string   // 订单状态，规则可修改
+	Customer Customer // 客户信息
+}
+
+// Customer 客户
+type Customer struct {
+	Level   int    // 客户等级
+	Segment string // 客户分群
+}
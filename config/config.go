@@ -1,6 +1,9 @@
 package config
 
 import (
+	"fmt"
+	"math"
+	"strings"
 	"time"
 )
 
@@ -13,6 +16,88 @@ const (
 	CacheTypeNone   CacheType = "none"   // 禁用缓存
 )
 
+// WriteCheckMode 规则写入声明检查模式
+type WriteCheckMode string
+
+const (
+	WriteCheckOff   WriteCheckMode = ""      // 不检查
+	WriteCheckWarn  WriteCheckMode = "warn"  // 写入未声明字段时记录警告日志
+	WriteCheckError WriteCheckMode = "error" // 写入未声明字段时编译失败
+)
+
+// CostCheckMode 规则集成本预算检查模式
+type CostCheckMode string
+
+const (
+	CostCheckOff   CostCheckMode = ""      // 不检查
+	CostCheckWarn  CostCheckMode = "warn"  // 预估成本超出延迟预算时记录警告日志
+	CostCheckError CostCheckMode = "error" // 预估成本超出延迟预算时编译失败
+)
+
+// LatencySLO 业务码的执行延迟SLO，为0的分位不检查
+type LatencySLO struct {
+	P95 time.Duration // 统计窗口内p95延迟的目标上限
+	P99 time.Duration // 统计窗口内p99延迟的目标上限
+}
+
+// RuleLimits 规则集的规模与复杂度限制，在发布、复制和导入规则时检查，<=0的项不限制
+type RuleLimits struct {
+	MaxRules          int // 业务码的最大规则数（含禁用规则）
+	MaxConditionDepth int // 单条规则when条件的最大括号嵌套深度，不含括号的条件深度为1
+	MaxGRLBytes       int // 单条规则GRL的最大字节数
+}
+
+// valid 限制项均不为负数
+func (l RuleLimits) valid() bool {
+	return l.MaxRules >= 0 && l.MaxConditionDepth >= 0 && l.MaxGRLBytes >= 0
+}
+
+// NonFinitePolicy Result中出现非有限数值（NaN、±Inf）时的处理方式
+type NonFinitePolicy string
+
+const (
+	NonFiniteAllow      NonFinitePolicy = ""           // 原样返回
+	NonFiniteError      NonFinitePolicy = "error"      // 执行返回错误
+	NonFiniteSubstitute NonFinitePolicy = "substitute" // 替换为NonFiniteDefault
+)
+
+// NullPolicy 缺失字段（字段不存在或值为nil）的比较语义
+type NullPolicy string
+
+const (
+	NullPolicyDefault NullPolicy = ""        // Grule默认：条件求值出错时规则不命中
+	NullPolicyFalse   NullPolicy = "false"   // 涉及缺失字段的比较为false
+	NullPolicyError   NullPolicy = "error"   // 条件引用缺失字段时执行返回错误
+	NullPolicyUnknown NullPolicy = "unknown" // 三值逻辑：涉及缺失字段的比较为unknown，规则仅在条件为true时命中
+)
+
+// Valid 是否为已知的缺失字段语义
+func (p NullPolicy) Valid() bool {
+	switch p {
+	case NullPolicyDefault, NullPolicyFalse, NullPolicyError, NullPolicyUnknown:
+		return true
+	}
+	return false
+}
+
+// ExecMode 规则执行模式 - 决定一次执行中命中多少条规则
+type ExecMode string
+
+const (
+	ExecModeAllMatching ExecMode = ""      // 全部命中：执行所有条件成立的规则（Grule默认行为）
+	ExecModeFirstMatch  ExecMode = "first" // 首条命中：按规则顺序只执行第一条条件成立的规则，忽略salience
+	ExecModeBestMatch   ExecMode = "best"  // 最优命中：只执行条件成立的规则中salience最高的一条
+)
+
+// Valid 是否为已知的执行模式
+func (m ExecMode) Valid() bool {
+	switch m {
+	case ExecModeAllMatching, ExecModeFirstMatch, ExecModeBestMatch:
+		return true
+	}
+	return false
+}
+
 // ============================================================================
 // 纯配置定义 - 仅包含配置参数，不包含实例对象
 // ============================================================================
@@ -20,8 +105,15 @@ const (
 // Config 纯配置结构 - 只包含配置参数，不持有任何实例
 type Config struct {
 	// 数据库配置参数
-	DSN         string // 数据库连接字符串
-	AutoMigrate bool   // 是否自动迁移数据库表结构
+	DSN                string        // 数据库连接字符串
+	AutoMigrate        bool          // 是否自动迁移数据库表结构
+	SchemaCheck        bool          // 启动时检查数据库表结构与模型是否一致（不迁移），不一致时创建引擎失败
+	IndexAdvisor       bool          // 启动时诊断规则获取查询的执行计划，全表扫描时记录警告日志
+	ReadOnly           bool          // 只读模式，禁止迁移、规则复制和数据清理等全部写操作
+	DBMaxOpenConns     int           // 最大打开连接数，<=0保持驱动默认值
+	DBMaxIdleConns     int           // 最大空闲连接数，<=0保持驱动默认值
+	DBConnMaxLifetime  time.Duration // 连接最大存活时间，<=0保持驱动默认值
+	SlowQueryThreshold time.Duration // 慢查询阈值，规则查询耗时超过该值时记录警告日志，<=0表示不记录
 
 	// 缓存配置参数
 	CacheType     CacheType     // 缓存类型：memory、redis、none
@@ -33,6 +125,78 @@ type Config struct {
 
 	// 定时任务配置参数
 	SyncInterval time.Duration // 规则同步间隔
+
+	// 执行配置参数
+	IdempotencyWindow   time.Duration     // 幂等结果保留窗口，<=0表示禁用
+	CopyInput           bool              // 是否在注入前深拷贝输入，规则修改Params不影响调用方的map/结构体
+	InputCoercion       bool              // 是否在注入前归一化map输入中的字符串数值/布尔值
+	InputSchema         map[string]string // 输入字段声明类型（字段路径 -> int/float/number/bool/string），为空时按内容推断
+	FlattenInput        bool              // 是否为map输入追加扁平化路径别名，如 Params["customer.address.city"]
+	InputTypes          map[string]any    // 业务码 -> 输入类型（结构体/map样例或字段路径->类型声明），用于生成自动补全元数据
+	FieldErrors         bool              // 是否启用字段错误累积，规则通过Errors.AddError记录，汇总到Result["errors"]
+	RolloutKeyField     string            // 规则灰度放量分桶键的输入字段路径，如 userId、customer.id
+	VersionRetention    int               // 每个业务码保留的已编译规则集版本数，供固定版本执行使用，只保留在内存中（重启后丢失），<=0表示不保留历史版本
+	WriteCheck          WriteCheckMode    // 规则写入声明检查模式，声明了Writes的规则写入其他Result字段时告警或编译失败
+	Locale              string            // 规则格式化函数（FormatNumber、FormatCurrency等）的默认区域，如 zh-CN，为空时为 en-US
+	ErrorLocale         string            // 执行错误的信息区域，为空或中文时为中文，其他区域为英文；与格式化区域相互独立
+	SimilarityMaxLength int               // 相似度函数（Levenshtein、JaroWinkler）的最大输入长度（字符数），超出时执行返回错误，<=0时取1000
+
+	// 规则获取配置参数
+	RulePageSize           int  // 分页获取规则的每页条数，>0时按页获取（需映射器实现PagedRuleMapper）并按页拼接GRL编译；获取的规则只保留元数据；启用公共调用提取或成本预算检查时完整编译
+	RuleCountWarnThreshold int  // 业务码规则数量告警阈值，超过时记录警告日志，<=0表示不检查
+	BizCodeInheritance     bool // 是否启用业务码层级继承，如 payments.cards.fraud 同时包含 payments.cards、payments 的规则
+	IncrementalCompile     bool // 是否增量编译，规则集变更后只编译新增或修改的规则（按GRL哈希判断），复用其余规则的编译结果；分页编译时不生效
+	HoistCommonCalls       bool // 是否提取多条规则条件中相同的函数调用为共享事实，每次执行按参数值只求值一次
+
+	// 环境配置参数
+	Environment string // 引擎运行环境，如 dev、staging、prod；同名规则优先使用该环境的变体，没有时使用默认变体
+
+	// 诊断配置参数
+	RecentErrorsSize            int           // 近期错误环形缓冲容量，<=0表示不记录
+	DecisionStatsFields         []string      // 决策分布统计的结果字段路径，如 approved、score，为空表示不统计
+	DecisionStatsBuckets        []float64     // 数值结果字段的分桶上界，为空时只统计计数、总和与极值
+	DecisionStatsSampleRate     float64       // 决策分布采样率 (0,1)，<=0或>=1表示统计每次执行
+	DecisionStatsExportInterval time.Duration // 决策分布导出间隔，<=0表示不定期导出
+	AnomalyThreshold            float64       // 异常告警阈值：规则命中率或结果取值占比相对基线的绝对变化量 (0,1]，<=0表示不告警
+	AnomalyMinSamples           int64         // 异常检测的最小样本数，当前与基线分布均达到该数量后才比较，<=0时取100
+	DeadRuleWindow              time.Duration // 失效规则检测窗口，窗口内从未命中的规则视为失效，<=0表示不检测
+	DeadRuleCheckInterval       time.Duration // 失效规则检测间隔，<=0时取1小时
+
+	// 缺失字段配置参数
+	NullPolicy      NullPolicy            // 缺失字段的比较语义，为空时沿用Grule默认行为
+	BizNullPolicies map[string]NullPolicy // 业务码 -> 缺失字段比较语义，覆盖NullPolicy；启用层级继承时子业务码沿用父业务码的配置
+
+	// 执行模式配置参数
+	ExecMode     ExecMode            // 规则执行模式，为空时执行所有条件成立的规则
+	BizExecModes map[string]ExecMode // 业务码 -> 执行模式，覆盖ExecMode；启用层级继承时子业务码沿用父业务码的配置
+
+	// 成本预算配置参数
+	CostCheck         CostCheckMode            // 规则集成本预算检查模式，编译前静态估算单次执行成本，超出延迟预算时告警或编译失败
+	LatencyBudget     time.Duration            // 单次执行的延迟预算，<=0表示不限制
+	BizLatencyBudgets map[string]time.Duration // 业务码 -> 延迟预算，覆盖LatencyBudget；启用层级继承时子业务码沿用父业务码的配置
+
+	// 延迟SLO配置参数
+	LatencySLOs          map[string]LatencySLO // 业务码 -> 执行延迟SLO，为空表示不统计；启用层级继承时子业务码沿用父业务码的配置
+	LatencySLOWindow     time.Duration         // 延迟SLO统计窗口，<=0时取5分钟
+	LatencySLOMinSamples int                   // 窗口内样本数达到该数量后才判断是否违反SLO，<=0时取100
+
+	// 规则集限制配置参数
+	RuleLimits       RuleLimits            // 规则集的默认规模与复杂度限制
+	TenantRuleLimits map[string]RuleLimits // 租户（业务码前缀）-> 规则集限制，覆盖RuleLimits；按业务码层级查找最近的配置，不受层级继承开关影响
+
+	// 数值安全配置参数
+	NonFinitePolicy  NonFinitePolicy // Result中出现NaN、±Inf时的处理方式，为空时原样返回
+	NonFiniteDefault float64         // NonFiniteSubstitute时替换非有限数值的值
+
+	// 汇率配置参数
+	RateCacheTTL time.Duration // 货币换算汇率的缓存时长，<=0时取1分钟
+	RateMaxAge   time.Duration // 汇率的最大时效，汇率时间早于该时长时执行返回错误，<=0表示不检查
+
+	// 数据保留配置参数
+	AuditRetention       time.Duration // 审计类记录（保留目标）的保留时长，超过的记录由定时清理任务删除，<=0表示不清理
+	RuleVersionRetention int           // 每条规则在数据库中保留的版本数，超出的禁用历史版本由定时清理任务删除，<=0表示不清理
+	RetentionInterval    time.Duration // 清理任务执行间隔，<=0时取1小时
+	RetentionBatchSize   int           // 每批删除的记录数，<=0时取500
 }
 
 // DefaultConfig 返回默认配置
@@ -44,38 +208,227 @@ func DefaultConfig() *Config {
 		MaxCacheSize: 1000,
 		CacheType:    CacheTypeMemory, // 默认使用内存缓存
 		RedisDB:      0,
+
+		IdempotencyWindow: 10 * time.Minute,
+		VersionRetention:  3,
+		RecentErrorsSize:  100,
 	}
 }
 
+// 配置错误代码 - 机器可读，可通过 ValidationErrors.Has 判断
+const (
+	CodeMissingDSN              = "missing_dsn"               // 未配置数据库DSN
+	CodeInvalidCacheType        = "invalid_cache_type"        // 未知的缓存类型
+	CodeMissingRedisAddr        = "missing_redis_addr"        // 使用Redis缓存但未配置地址
+	CodeInvalidRedisDB          = "invalid_redis_db"          // Redis数据库编号为负数
+	CodeInvalidCacheSize        = "invalid_cache_size"        // 内存缓存大小不大于0
+	CodeInvalidCacheTTL         = "invalid_cache_ttl"         // 启用缓存但缓存生存时间不大于0
+	CodeConflictingCacheOptions = "conflicting_cache_options" // 配置了Redis参数但未使用Redis缓存
+	CodeInvalidSyncInterval     = "invalid_sync_interval"     // 规则同步间隔为负数
+	CodeInvalidWriteCheck       = "invalid_write_check"       // 未知的写入声明检查模式
+	CodeInvalidAnomalyThreshold = "invalid_anomaly_threshold" // 异常告警阈值超出范围
+	CodeInvalidRetention        = "invalid_retention"         // 数据保留参数为负数
+	CodeInvalidNullPolicy       = "invalid_null_policy"       // 未知的缺失字段比较语义
+	CodeInvalidDeadRuleWindow   = "invalid_dead_rule_window"  // 失效规则检测参数为负数
+	CodeInvalidExecMode         = "invalid_exec_mode"         // 未知的规则执行模式
+	CodeInvalidCostCheck        = "invalid_cost_check"        // 未知的成本预算检查模式或延迟预算为负数
+	CodeInvalidNonFinitePolicy  = "invalid_non_finite_policy" // 未知的非有限数值处理方式或替换值不是有限数值
+	CodeInvalidRateCache        = "invalid_rate_cache"        // 汇率缓存时长或最大时效为负数
+	CodeReadOnlyConflict        = "read_only_conflict"        // 只读模式下启用了自动迁移或数据清理
+	CodeInvalidLatencySLO       = "invalid_latency_slo"       // 延迟SLO目标、统计窗口或最小样本数为负数
+	CodeInvalidRuleLimits       = "invalid_rule_limits"       // 规则集限制为负数
+)
+
 // Validate 验证配置参数的合法性
+//
+// 检查全部配置项，存在问题时返回 ValidationErrors，列出每个问题的代码、字段和处理建议。
 func (c *Config) Validate() error {
+	var errs ValidationErrors
+	add := func(code, field, message string) {
+		errs = append(errs, &ConfigError{Code: code, Field: field, Message: message})
+	}
+
 	if c.DSN == "" {
-		return &ConfigError{Message: "数据库DSN不能为空"}
+		add(CodeMissingDSN, "DSN", "数据库DSN不能为空，请使用 WithDSN 或 WithCustomDB 配置数据库")
 	}
 
 	// 验证缓存类型
-	if c.CacheType != CacheTypeMemory && c.CacheType != CacheTypeRedis && c.CacheType != CacheTypeNone {
-		return &ConfigError{Message: "缓存类型必须是memory、redis或none"}
+	switch c.CacheType {
+	case CacheTypeMemory, CacheTypeRedis, CacheTypeNone:
+	default:
+		add(CodeInvalidCacheType, "CacheType", fmt.Sprintf("缓存类型必须是memory、redis或none，当前为 %q", c.CacheType))
 	}
 
 	// 如果是Redis缓存，检查Redis配置
 	if c.CacheType == CacheTypeRedis && c.RedisAddr == "" {
-		return &ConfigError{Message: "使用Redis缓存时，Redis地址不能为空"}
+		add(CodeMissingRedisAddr, "RedisAddr", "使用Redis缓存时，Redis地址不能为空，请在 WithRedisCache 中传入地址")
+	}
+	if c.RedisDB < 0 {
+		add(CodeInvalidRedisDB, "RedisDB", fmt.Sprintf("Redis数据库编号不能为负数，当前为 %d", c.RedisDB))
+	}
+	if c.CacheType != CacheTypeRedis && (c.RedisAddr != "" || c.RedisPassword != "") {
+		add(CodeConflictingCacheOptions, "CacheType", fmt.Sprintf("配置了Redis地址或密码，但缓存类型为 %q，Redis配置不会生效；请移除 WithMemoryCache/WithNoCache/WithCustomCache 或 WithRedisCache 之一", c.CacheType))
 	}
 
 	// 如果是内存缓存，检查大小配置
 	if c.CacheType == CacheTypeMemory && c.MaxCacheSize <= 0 {
-		return &ConfigError{Message: "使用内存缓存时，缓存大小必须大于0"}
+		add(CodeInvalidCacheSize, "MaxCacheSize", fmt.Sprintf("使用内存缓存时，缓存大小必须大于0，当前为 %d", c.MaxCacheSize))
+	}
+	if (c.CacheType == CacheTypeMemory || c.CacheType == CacheTypeRedis) && c.CacheTTL <= 0 {
+		add(CodeInvalidCacheTTL, "CacheTTL", fmt.Sprintf("启用缓存时，缓存生存时间必须大于0，当前为 %s；不需要缓存时请使用 WithNoCache", c.CacheTTL))
+	}
+
+	if c.SyncInterval < 0 {
+		add(CodeInvalidSyncInterval, "SyncInterval", fmt.Sprintf("规则同步间隔不能为负数，当前为 %s", c.SyncInterval))
+	}
+
+	if c.WriteCheck != WriteCheckOff && c.WriteCheck != WriteCheckWarn && c.WriteCheck != WriteCheckError {
+		add(CodeInvalidWriteCheck, "WriteCheck", fmt.Sprintf("写入声明检查模式必须是warn或error，当前为 %q", c.WriteCheck))
+	}
+
+	if c.CostCheck != CostCheckOff && c.CostCheck != CostCheckWarn && c.CostCheck != CostCheckError {
+		add(CodeInvalidCostCheck, "CostCheck", fmt.Sprintf("成本预算检查模式必须是warn或error，当前为 %q", c.CostCheck))
+	}
+	if c.LatencyBudget < 0 {
+		add(CodeInvalidCostCheck, "LatencyBudget", fmt.Sprintf("延迟预算不能为负数，当前为 %s", c.LatencyBudget))
+	}
+	for bizCode, budget := range c.BizLatencyBudgets {
+		if budget < 0 {
+			add(CodeInvalidCostCheck, "BizLatencyBudgets", fmt.Sprintf("业务码 %s 的延迟预算不能为负数，当前为 %s", bizCode, budget))
+		}
+	}
+
+	if c.NonFinitePolicy != NonFiniteAllow && c.NonFinitePolicy != NonFiniteError && c.NonFinitePolicy != NonFiniteSubstitute {
+		add(CodeInvalidNonFinitePolicy, "NonFinitePolicy", fmt.Sprintf("非有限数值处理方式必须是error或substitute，当前为 %q", c.NonFinitePolicy))
+	}
+	if math.IsNaN(c.NonFiniteDefault) || math.IsInf(c.NonFiniteDefault, 0) {
+		add(CodeInvalidNonFinitePolicy, "NonFiniteDefault", fmt.Sprintf("非有限数值的替换值必须是有限数值，当前为 %g", c.NonFiniteDefault))
+	}
+
+	if c.RateCacheTTL < 0 || c.RateMaxAge < 0 {
+		add(CodeInvalidRateCache, "RateCacheTTL", fmt.Sprintf("汇率缓存时长和最大时效不能为负数，当前为 %s、%s", c.RateCacheTTL, c.RateMaxAge))
+	}
+
+	if c.ReadOnly {
+		if c.AutoMigrate {
+			add(CodeReadOnlyConflict, "AutoMigrate", "只读模式下不能启用自动迁移，请移除 WithAutoMigrate 或在可写实例上执行迁移")
+		}
+		if c.AuditRetention > 0 || c.RuleVersionRetention > 0 {
+			add(CodeReadOnlyConflict, "AuditRetention", "只读模式下不能启用数据清理，请在可写实例上配置 WithAuditRetention、WithRuleVersionRetention")
+		}
 	}
 
+	for bizCode, slo := range c.LatencySLOs {
+		if slo.P95 < 0 || slo.P99 < 0 {
+			add(CodeInvalidLatencySLO, "LatencySLOs", fmt.Sprintf("业务码 %s 的延迟SLO不能为负数，当前为 p95=%s p99=%s", bizCode, slo.P95, slo.P99))
+		}
+	}
+	if c.LatencySLOWindow < 0 || c.LatencySLOMinSamples < 0 {
+		add(CodeInvalidLatencySLO, "LatencySLOWindow", fmt.Sprintf("延迟SLO统计窗口和最小样本数不能为负数，当前为 %s、%d", c.LatencySLOWindow, c.LatencySLOMinSamples))
+	}
+
+	if !c.RuleLimits.valid() {
+		add(CodeInvalidRuleLimits, "RuleLimits", fmt.Sprintf("规则集限制不能为负数，当前为 %+v", c.RuleLimits))
+	}
+	for tenant, limits := range c.TenantRuleLimits {
+		if !limits.valid() {
+			add(CodeInvalidRuleLimits, "TenantRuleLimits", fmt.Sprintf("租户 %s 的规则集限制不能为负数，当前为 %+v", tenant, limits))
+		}
+	}
+
+	if !c.NullPolicy.Valid() {
+		add(CodeInvalidNullPolicy, "NullPolicy", fmt.Sprintf("缺失字段比较语义必须是false、error或unknown，当前为 %q", c.NullPolicy))
+	}
+	for bizCode, policy := range c.BizNullPolicies {
+		if !policy.Valid() {
+			add(CodeInvalidNullPolicy, "BizNullPolicies", fmt.Sprintf("业务码 %s 的缺失字段比较语义必须是false、error或unknown，当前为 %q", bizCode, policy))
+		}
+	}
+
+	if !c.ExecMode.Valid() {
+		add(CodeInvalidExecMode, "ExecMode", fmt.Sprintf("规则执行模式必须是first或best，当前为 %q", c.ExecMode))
+	}
+	for bizCode, mode := range c.BizExecModes {
+		if !mode.Valid() {
+			add(CodeInvalidExecMode, "BizExecModes", fmt.Sprintf("业务码 %s 的规则执行模式必须是first或best，当前为 %q", bizCode, mode))
+		}
+	}
+
+	if c.AnomalyThreshold > 1 {
+		add(CodeInvalidAnomalyThreshold, "AnomalyThreshold", fmt.Sprintf("异常告警阈值必须在(0,1]之间，当前为 %g", c.AnomalyThreshold))
+	}
+
+	if c.DeadRuleWindow < 0 || c.DeadRuleCheckInterval < 0 {
+		add(CodeInvalidDeadRuleWindow, "DeadRuleWindow", fmt.Sprintf("失效规则检测窗口和间隔不能为负数，当前为 %s、%s", c.DeadRuleWindow, c.DeadRuleCheckInterval))
+	}
+
+	if c.AuditRetention < 0 {
+		add(CodeInvalidRetention, "AuditRetention", fmt.Sprintf("审计记录保留时长不能为负数，当前为 %s", c.AuditRetention))
+	}
+	if c.RuleVersionRetention < 0 {
+		add(CodeInvalidRetention, "RuleVersionRetention", fmt.Sprintf("规则保留版本数不能为负数，当前为 %d", c.RuleVersionRetention))
+	}
+	if c.RetentionInterval < 0 || c.RetentionBatchSize < 0 {
+		add(CodeInvalidRetention, "RetentionInterval", fmt.Sprintf("清理间隔和批大小不能为负数，当前为 %s、%d", c.RetentionInterval, c.RetentionBatchSize))
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
 	return nil
 }
 
 // ConfigError 配置错误类型
 type ConfigError struct {
-	Message string
+	Code    string // 错误代码，如 missing_dsn
+	Field   string // 配置字段
+	Message string // 错误说明与处理建议
 }
 
 func (e *ConfigError) Error() string {
 	return e.Message
 }
+
+// ValidationErrors 配置验证错误集合 - 包含全部配置问题，可通过errors.As获取
+type ValidationErrors []*ConfigError
+
+// Error 实现error接口，逐条列出问题
+func (e ValidationErrors) Error() string {
+	if len(e) == 1 {
+		return fmt.Sprintf("[%s] %s", e[0].Code, e[0].Message)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "共%d个配置问题:", len(e))
+	for i, err := range e {
+		fmt.Fprintf(&b, " %d) [%s] %s;", i+1, err.Code, err.Message)
+	}
+	return strings.TrimSuffix(b.String(), ";")
+}
+
+// Unwrap 返回各个配置错误，支持errors.As获取*ConfigError
+func (e ValidationErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+// Codes 返回全部错误代码
+func (e ValidationErrors) Codes() []string {
+	codes := make([]string, len(e))
+	for i, err := range e {
+		codes[i] = err.Code
+	}
+	return codes
+}
+
+// Has 是否包含指定代码的错误
+func (e ValidationErrors) Has(code string) bool {
+	for _, err := range e {
+		if err.Code == code {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,155 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+)
+
+// ============================================================================
+// 集合聚合函数 - 对集合元素的字段求和、均值、极值，用于 sum(items.amount) == total 等跨字段校验
+// ============================================================================
+//
+// 元素可以是map或结构体，field 为字段路径（与 Get 相同）。字段缺失或为nil的元素不参与聚合，
+// 字段存在但不是数值时执行出错，避免金额等字段类型错误时校验被静默跳过。
+
+// aggregateValues 取集合元素字段的数值
+func aggregateValues(slice any, field string) ([]float64, error) {
+	if slice == nil {
+		return nil, nil
+	}
+	items, ok := sliceItems(slice)
+	if !ok {
+		return nil, fmt.Errorf("聚合对象不是集合: %T", slice)
+	}
+	values := make([]float64, 0, len(items))
+	for i, item := range items {
+		raw, ok := lookupPath(item, field)
+		if !ok || raw == nil {
+			continue
+		}
+		n, ok := toFloat(raw)
+		if !ok {
+			return nil, fmt.Errorf("第%d个元素的字段 %s 不是数值: %v", i+1, field, raw)
+		}
+		values = append(values, n)
+	}
+	return values, nil
+}
+
+// sumValues 求和
+func sumValues(values []float64) float64 {
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum
+}
+
+// avgValues 均值，没有数值时为0
+func avgValues(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	return sumValues(values) / float64(len(values))
+}
+
+// extremeValue 极值，没有数值时为0
+func extremeValue(values []float64, max bool) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	result := values[0]
+	for _, v := range values[1:] {
+		if (max && v > result) || (!max && v < result) {
+			result = v
+		}
+	}
+	return result
+}
+
+// aggregate 按字段取值并聚合，出错时panic由规则执行捕获
+func aggregate(name string, slice any, field string, fn func([]float64) float64) float64 {
+	values, err := aggregateValues(slice, field)
+	if err != nil {
+		panic(fmt.Errorf("%s: %w", name, err))
+	}
+	return fn(values)
+}
+
+// countItems 集合元素个数，nil为0
+func countItems(slice any) int64 {
+	if slice == nil {
+		return 0
+	}
+	items, ok := sliceItems(slice)
+	if !ok {
+		panic(fmt.Errorf("CountOf: 聚合对象不是集合: %T", slice))
+	}
+	return int64(len(items))
+}
+
+// SumBy 集合元素字段之和
+func (f *ruleFunctions) SumBy(slice any, field string) float64 {
+	values, err := aggregateValues(slice, field)
+	if err != nil {
+		f.raise("SumBy", err)
+	}
+	return sumValues(values)
+}
+
+// AvgBy 集合元素字段的均值
+func (f *ruleFunctions) AvgBy(slice any, field string) float64 {
+	values, err := aggregateValues(slice, field)
+	if err != nil {
+		f.raise("AvgBy", err)
+	}
+	return avgValues(values)
+}
+
+// MinBy 集合元素字段的最小值
+func (f *ruleFunctions) MinBy(slice any, field string) float64 {
+	values, err := aggregateValues(slice, field)
+	if err != nil {
+		f.raise("MinBy", err)
+	}
+	return extremeValue(values, false)
+}
+
+// MaxBy 集合元素字段的最大值
+func (f *ruleFunctions) MaxBy(slice any, field string) float64 {
+	values, err := aggregateValues(slice, field)
+	if err != nil {
+		f.raise("MaxBy", err)
+	}
+	return extremeValue(values, true)
+}
+
+// CountOf 集合元素个数
+func (f *ruleFunctions) CountOf(slice any) int64 {
+	if slice == nil {
+		return 0
+	}
+	items, ok := sliceItems(slice)
+	if !ok {
+		f.raise("CountOf", fmt.Errorf("聚合对象不是集合: %T", slice))
+	}
+	return int64(len(items))
+}
+
+// injectAggregateFunctions 注入集合聚合函数
+func injectAggregateFunctions(dataCtx ast.IDataContext) {
+	dataCtx.Add("SumBy", func(slice any, field string) float64 {
+		return aggregate("SumBy", slice, field, sumValues)
+	})
+	dataCtx.Add("AvgBy", func(slice any, field string) float64 {
+		return aggregate("AvgBy", slice, field, avgValues)
+	})
+	dataCtx.Add("MinBy", func(slice any, field string) float64 {
+		return aggregate("MinBy", slice, field, func(values []float64) float64 { return extremeValue(values, false) })
+	})
+	dataCtx.Add("MaxBy", func(slice any, field string) float64 {
+		return aggregate("MaxBy", slice, field, func(values []float64) float64 { return extremeValue(values, true) })
+	})
+	dataCtx.Add("CountOf", countItems)
+}
@@ -0,0 +1,97 @@
+package engine
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+// TestAggregateFunctions 测试集合聚合函数
+func TestAggregateFunctions(t *testing.T) {
+	Convey("集合聚合函数", t, func() {
+		items := []any{
+			map[string]any{"amount": 10, "qty": int64(2)},
+			map[string]any{"amount": 2.5},
+			map[string]any{"amount": nil},
+			struct{ Amount float64 }{Amount: 7.5},
+		}
+
+		Convey("按字段聚合，缺失字段的元素不参与", func() {
+			values, err := aggregateValues(items, "amount")
+			So(err, ShouldBeNil)
+			So(values, ShouldResemble, []float64{10, 2.5})
+			So(sumValues(values), ShouldEqual, 12.5)
+			So(avgValues(values), ShouldEqual, 6.25)
+			So(extremeValue(values, false), ShouldEqual, 2.5)
+			So(extremeValue(values, true), ShouldEqual, 10)
+			So(avgValues(nil), ShouldEqual, 0)
+			So(extremeValue(nil, true), ShouldEqual, 0)
+			So(countItems(items), ShouldEqual, 4)
+			So(countItems(nil), ShouldEqual, 0)
+
+			values, err = aggregateValues(items, "Amount")
+			So(err, ShouldBeNil)
+			So(values, ShouldResemble, []float64{7.5})
+		})
+
+		Convey("字段不是数值或对象不是集合时报错", func() {
+			_, err := aggregateValues([]any{map[string]any{"amount": "x"}}, "amount")
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "第1个元素的字段 amount 不是数值")
+			_, err = aggregateValues(42, "amount")
+			So(err, ShouldNotBeNil)
+			So(func() { countItems("x") }, ShouldPanic)
+		})
+
+		Convey("跨字段校验规则中使用", func() {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			converter := rule.NewGRLConverter()
+			total, err := converter.ConvertToGRL(rule.ValidationRule{Field: "sum(items.amount)", Operator: rule.OpEqual, Compare: "total"})
+			So(err, ShouldBeNil)
+			count, err := converter.ConvertToGRL(rule.ValidationRule{Field: "count(items)", Operator: rule.OpLessThanOrEqual, Compare: "max_items"})
+			So(err, ShouldBeNil)
+
+			mapper := rule.NewMockRuleMapper(ctrl)
+			mapper.EXPECT().FindByBizCode(gomock.Any(), "order").Return([]*rule.Rule{
+				{Name: "total", Enabled: true, GRL: total},
+				{Name: "count", Enabled: true, GRL: count},
+			}, nil).AnyTimes()
+
+			engine := NewEngineImpl[map[string]any](
+				config.DefaultConfig(), mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+				ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+			)
+			defer engine.Close()
+			ctx := context.Background()
+
+			result, err := engine.Exec(ctx, "order", map[string]any{
+				"items":     []any{map[string]any{"amount": 30}, map[string]any{"amount": 12.5}},
+				"total":     42.5,
+				"max_items": 5,
+			})
+			So(err, ShouldBeNil)
+			So(result["valid"], ShouldBeNil)
+
+			result, err = engine.Exec(ctx, "order", map[string]any{
+				"items":     []any{map[string]any{"amount": 30}, map[string]any{"amount": 12.5}},
+				"total":     40,
+				"max_items": 1,
+			})
+			So(err, ShouldBeNil)
+			So(result["valid"], ShouldEqual, false)
+			So(result["error.sum(items.amount)"], ShouldEqual, "sum(items.amount) 必须等于 total")
+			So(result["error.count(items)"], ShouldEqual, "count(items) 必须小于等于 max_items")
+		})
+	})
+}
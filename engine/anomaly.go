@@ -0,0 +1,187 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// ============================================================================
+// 异常告警 - 规则命中率或决策分布相对基线大幅变化时告警，用于及早发现错误的规则发布
+// ============================================================================
+//
+// 基线为上一规则集版本累计的决策分布，当前分布与基线的样本数均达到最小样本数后，
+// 每次记录执行都会比较两者的规则命中率与结果取值占比，绝对变化量超过阈值即告警。
+// 同一指标在同一规则集版本内只告警一次。
+
+// 默认异常检测最小样本数
+const defaultAnomalyMinSamples = 100
+
+// AnomalyKind 异常类型
+type AnomalyKind string
+
+const (
+	AnomalyRuleFireRate AnomalyKind = "rule_fire_rate" // 规则命中率变化
+	AnomalyOutcomeShift AnomalyKind = "outcome_shift"  // 结果字段取值分布变化
+)
+
+// AnomalyAlert 异常告警
+type AnomalyAlert struct {
+	BizCode         string      // 业务码
+	Kind            AnomalyKind // 异常类型
+	Rule            string      // GRL规则名（规则命中率告警）
+	Field           string      // 结果字段路径（结果分布告警）
+	Value           string      // 结果取值或数值分桶（如 le=600）
+	Baseline        float64     // 基线占比 [0,1]
+	Current         float64     // 当前占比 [0,1]
+	BaselineVersion int         // 基线规则集版本
+	CurrentVersion  int         // 当前规则集版本
+	Samples         int64       // 当前分布的样本数
+}
+
+// Shift 占比变化量（当前-基线）
+func (a AnomalyAlert) Shift() float64 {
+	return a.Current - a.Baseline
+}
+
+// String 告警描述
+func (a AnomalyAlert) String() string {
+	subject := a.Rule
+	if a.Kind == AnomalyOutcomeShift {
+		subject = a.Field + "=" + a.Value
+	}
+	return fmt.Sprintf("%s %s %s: v%d %.2f%% -> v%d %.2f%%", a.BizCode, a.Kind, subject,
+		a.BaselineVersion, a.Baseline*100, a.CurrentVersion, a.Current*100)
+}
+
+// AnomalyAlerter 异常告警回调 - 在执行协程中同步调用，实现应避免阻塞
+type AnomalyAlerter interface {
+	OnAnomaly(ctx context.Context, alert AnomalyAlert)
+}
+
+// AnomalyAlertFunc 函数形式的异常告警回调
+type AnomalyAlertFunc func(ctx context.Context, alert AnomalyAlert)
+
+// OnAnomaly 实现AnomalyAlerter
+func (f AnomalyAlertFunc) OnAnomaly(ctx context.Context, alert AnomalyAlert) {
+	f(ctx, alert)
+}
+
+// SetAnomalyAlerter 设置异常告警回调，为nil时以警告日志输出
+func (e *engineImpl[T]) SetAnomalyAlerter(alerter AnomalyAlerter) {
+	e.anomalyAlerter = alerter
+}
+
+// anomalyMinSamples 异常检测最小样本数
+func (e *engineImpl[T]) anomalyMinSamples() int64 {
+	if e.config.AnomalyMinSamples > 0 {
+		return e.config.AnomalyMinSamples
+	}
+	return defaultAnomalyMinSamples
+}
+
+// detectAnomalies 比较当前分布与基线，返回新出现的异常，需持有recorder锁
+func (e *engineImpl[T]) detectAnomalies(recorder *decisionRecorder) []AnomalyAlert {
+	threshold := e.config.AnomalyThreshold
+	current, baseline := recorder.current, recorder.previous
+	if threshold <= 0 || current == nil || baseline == nil {
+		return nil
+	}
+	minSamples := e.anomalyMinSamples()
+	if current.Samples < minSamples || baseline.Samples < minSamples {
+		return nil
+	}
+
+	var alerts []AnomalyAlert
+	check := func(alert AnomalyAlert) {
+		if math.Abs(alert.Shift()) <= threshold {
+			return
+		}
+		key := string(alert.Kind) + "|" + alert.Rule + "|" + alert.Field + "|" + alert.Value
+		if recorder.alerted[key] {
+			return
+		}
+		if recorder.alerted == nil {
+			recorder.alerted = make(map[string]bool)
+		}
+		recorder.alerted[key] = true
+
+		alert.BizCode = current.BizCode
+		alert.BaselineVersion = baseline.RuleSetVersion
+		alert.CurrentVersion = current.RuleSetVersion
+		alert.Samples = current.Samples
+		alerts = append(alerts, alert)
+	}
+
+	// 规则命中率
+	for _, name := range unionKeys(baseline.RuleFires, current.RuleFires) {
+		check(AnomalyAlert{
+			Kind:     AnomalyRuleFireRate,
+			Rule:     name,
+			Baseline: ratio(baseline.RuleFires[name], baseline.Samples),
+			Current:  ratio(current.RuleFires[name], current.Samples),
+		})
+	}
+
+	// 结果取值占比
+	for _, field := range e.config.DecisionStatsFields {
+		base, cur := baseline.Fields[field], current.Fields[field]
+		for _, value := range unionKeys(base.Values, cur.Values) {
+			check(AnomalyAlert{
+				Kind:     AnomalyOutcomeShift,
+				Field:    field,
+				Value:    value,
+				Baseline: ratio(base.Values[value], baseline.Samples),
+				Current:  ratio(cur.Values[value], current.Samples),
+			})
+		}
+		if len(base.Buckets) != len(cur.Buckets) {
+			continue
+		}
+		for i := range cur.Buckets {
+			check(AnomalyAlert{
+				Kind:     AnomalyOutcomeShift,
+				Field:    field,
+				Value:    fmt.Sprintf("le=%g", cur.Buckets[i].UpperBound),
+				Baseline: ratio(base.Buckets[i].Count, base.Count),
+				Current:  ratio(cur.Buckets[i].Count, cur.Count),
+			})
+		}
+	}
+	return alerts
+}
+
+// notifyAnomalies 发送异常告警
+func (e *engineImpl[T]) notifyAnomalies(ctx context.Context, alerts []AnomalyAlert) {
+	for _, alert := range alerts {
+		if e.anomalyAlerter != nil {
+			e.anomalyAlerter.OnAnomaly(ctx, alert)
+		} else if e.logger != nil {
+			e.logger.Warnf(ctx, "决策分布异常", "alert", alert.String())
+		}
+	}
+}
+
+// ratio 计算占比，分母为0时返回0
+func ratio(count, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(count) / float64(total)
+}
+
+// unionKeys 两个计数map的键并集，按字典序排列
+func unionKeys(a, b map[string]int64) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
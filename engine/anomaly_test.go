@@ -0,0 +1,126 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+// TestAnomalyAlerts 测试决策分布异常告警
+func TestAnomalyAlerts(t *testing.T) {
+	Convey("决策分布异常告警", t, func() {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		version, cutoff := 1, 500
+		mapper := rule.NewMockRuleMapper(ctrl)
+		mapper.EXPECT().FindByBizCode(gomock.Any(), "loan").DoAndReturn(func(ctx context.Context, bizCode string) ([]*rule.Rule, error) {
+			return []*rule.Rule{
+				{Name: "init", Version: version, Enabled: true, GRL: `rule Init "初始化" salience 10 {
+	when true
+	then
+		Result["approved"] = false;
+		Retract("Init");
+}`},
+				{Name: "approve", Version: version, Enabled: true, GRL: fmt.Sprintf(`rule Approve "通过" {
+	when Params["score"] >= %d
+	then
+		Result["approved"] = true;
+		Retract("Approve");
+}`, cutoff)},
+			}, nil
+		}).AnyTimes()
+
+		cfg := config.DefaultConfig()
+		cfg.DecisionStatsFields = []string{"approved"}
+		cfg.AnomalyThreshold = 0.3
+		cfg.AnomalyMinSamples = 10
+		engine := NewEngineImpl[map[string]any](
+			cfg, mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+			ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+		)
+		defer engine.Close()
+		ctx := context.Background()
+
+		var alerts []AnomalyAlert
+		engine.SetAnomalyAlerter(AnomalyAlertFunc(func(ctx context.Context, alert AnomalyAlert) {
+			alerts = append(alerts, alert)
+		}))
+
+		// 分数 0,100,...,900 各执行一次
+		execRound := func() {
+			for score := 0; score < 1000; score += 100 {
+				_, err := engine.Exec(ctx, "loan", map[string]any{"score": score})
+				So(err, ShouldBeNil)
+			}
+		}
+		release := func(v, c int) {
+			version, cutoff = v, c
+			So(engine.refreshCache("loan"), ShouldBeNil)
+		}
+
+		Convey("统计规则命中次数", func() {
+			execRound()
+			stats := engine.DecisionStats("loan")
+			So(stats.RuleFires, ShouldResemble, map[string]int64{"Init": 10, "Approve": 5})
+		})
+
+		Convey("首个版本没有基线时不告警", func() {
+			execRound()
+			execRound()
+			So(alerts, ShouldBeEmpty)
+		})
+
+		Convey("分布变化超过阈值时告警且每个指标只告警一次", func() {
+			execRound()
+			release(2, 900)
+			execRound()
+			execRound()
+
+			So(alerts, ShouldHaveLength, 3)
+			So(alerts[0].Kind, ShouldEqual, AnomalyRuleFireRate)
+			So(alerts[0].Rule, ShouldEqual, "Approve")
+			So(alerts[0].Baseline, ShouldEqual, 0.5)
+			So(alerts[0].Current, ShouldEqual, 0.1)
+			So(alerts[0].BaselineVersion, ShouldEqual, 1)
+			So(alerts[0].CurrentVersion, ShouldEqual, 2)
+			So(alerts[0].Samples, ShouldEqual, 10)
+			So(alerts[0].BizCode, ShouldEqual, "loan")
+
+			So(alerts[1].Kind, ShouldEqual, AnomalyOutcomeShift)
+			So(alerts[1].Field, ShouldEqual, "approved")
+			So(alerts[1].Value, ShouldEqual, "false")
+			So(alerts[1].Shift(), ShouldAlmostEqual, 0.4)
+			So(alerts[2].Value, ShouldEqual, "true")
+			So(alerts[2].Shift(), ShouldAlmostEqual, -0.4)
+			So(alerts[2].String(), ShouldContainSubstring, "approved=true")
+		})
+
+		Convey("变化未超过阈值时不告警", func() {
+			execRound()
+			release(2, 600)
+			execRound()
+			So(alerts, ShouldBeEmpty)
+		})
+
+		Convey("样本数不足时不比较", func() {
+			execRound()
+			release(2, 900)
+			for score := 0; score < 500; score += 100 {
+				_, err := engine.Exec(ctx, "loan", map[string]any{"score": score})
+				So(err, ShouldBeNil)
+			}
+			So(alerts, ShouldBeEmpty)
+		})
+	})
+}
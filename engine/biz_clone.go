@@ -0,0 +1,193 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gitee.com/damengde/runehammer/rule"
+)
+
+// ============================================================================
+// 租户规则复制 - 以模板租户的规则集初始化新租户
+// ============================================================================
+//
+// 租户通过业务码前缀区分: 租户 acme 的业务码 payments 对应 acme.payments，
+// 租户为空表示不带前缀的业务码（通常作为模板）。
+
+// ErrCloneNotSupported 规则映射器未实现RuleCloneMapper，无法复制规则
+var ErrCloneNotSupported = errors.New("规则映射器不支持复制规则")
+
+// CloneReport 规则复制报告
+type CloneReport struct {
+	FromTenant string           // 来源租户
+	ToTenant   string           // 目标租户
+	DryRun     bool             // 是否为演练，演练时不写入任何规则
+	Cloned     []ClonedRule     // 已复制（演练时为将要复制）的规则
+	Collisions []CloneCollision // 目标业务码已存在同名规则而跳过的规则
+}
+
+// ClonedRule 复制的规则
+type ClonedRule struct {
+	BizCode  string // 目标业务码
+	Name     string // 规则名称
+	ID       uint64 // 新规则ID，演练时为0
+	SourceID uint64 // 来源规则ID
+	Version  int    // 保留的来源版本号
+}
+
+// CloneCollision 复制冲突 - 目标业务码已存在同名规则
+type CloneCollision struct {
+	BizCode    string // 目标业务码
+	Name       string // 规则名称
+	ExistingID uint64 // 目标业务码中已存在的规则ID
+	SourceID   uint64 // 来源规则ID
+}
+
+// tenantBizCode 租户业务码 - 租户为空时返回原业务码
+func tenantBizCode(tenant, bizCode string) string {
+	if tenant == "" {
+		return bizCode
+	}
+	return tenant + bizCodeSeparator + bizCode
+}
+
+// CloneBizCode 复制租户规则集 - 将来源租户指定业务码的全部规则（含禁用规则）复制到目标租户
+//
+// 新规则获得新ID，保留GRL内容、启用状态和版本号，并通过SourceID指向来源规则。
+// 目标业务码已存在同名规则（同一环境变体）时跳过并记录在冲突列表中；全部规则在同一事务中写入，
+// 写入成功后刷新目标业务码缓存。复制后的规则集超出目标租户的限制（Config.TenantRuleLimits）时
+// 返回*RuleLimitError，不写入任何规则。
+//
+// 参数:
+//
+//	ctx        - 上下文
+//	fromTenant - 来源（模板）租户，为空表示不带前缀的业务码
+//	toTenant   - 目标租户
+//	bizCodes   - 要复制的业务码（不含租户前缀）
+//
+// 返回值:
+//
+//	*CloneReport - 复制报告
+//	error        - 参数无效、映射器不支持复制、超出规则集限制或读写失败
+func (e *engineImpl[T]) CloneBizCode(ctx context.Context, fromTenant, toTenant string, bizCodes ...string) (*CloneReport, error) {
+	return e.cloneBizCodes(ctx, fromTenant, toTenant, bizCodes, false)
+}
+
+// CloneBizCodeDryRun 演练复制租户规则集 - 只生成复制报告，不写入任何规则
+func (e *engineImpl[T]) CloneBizCodeDryRun(ctx context.Context, fromTenant, toTenant string, bizCodes ...string) (*CloneReport, error) {
+	return e.cloneBizCodes(ctx, fromTenant, toTenant, bizCodes, true)
+}
+
+// cloneBizCodes 复制规则集
+func (e *engineImpl[T]) cloneBizCodes(ctx context.Context, fromTenant, toTenant string, bizCodes []string, dryRun bool) (*CloneReport, error) {
+	e.mutex.RLock()
+	closed := e.closed
+	e.mutex.RUnlock()
+	if closed {
+		return nil, ErrEngineClosed
+	}
+	if !dryRun {
+		if err := e.checkWritable("复制规则集"); err != nil {
+			return nil, err
+		}
+	}
+
+	if fromTenant == toTenant {
+		return nil, fmt.Errorf("来源租户与目标租户相同: %q", fromTenant)
+	}
+	if toTenant == "" {
+		return nil, fmt.Errorf("目标租户不能为空")
+	}
+	if len(bizCodes) == 0 {
+		return nil, fmt.Errorf("未指定要复制的业务码")
+	}
+
+	mapper, ok := e.mapper.(rule.RuleCloneMapper)
+	if !ok {
+		return nil, ErrCloneNotSupported
+	}
+
+	report := &CloneReport{FromTenant: fromTenant, ToTenant: toTenant, DryRun: dryRun}
+	var clones []*rule.Rule
+	var targets []string
+
+	for _, bizCode := range bizCodes {
+		source := tenantBizCode(fromTenant, bizCode)
+		target := tenantBizCode(toTenant, bizCode)
+
+		sourceRules, err := mapper.FindAllByBizCode(ctx, source)
+		if err != nil {
+			return nil, fmt.Errorf("读取来源业务码 %s 规则失败: %w", source, err)
+		}
+		existingRules, err := mapper.FindAllByBizCode(ctx, target)
+		if err != nil {
+			return nil, fmt.Errorf("读取目标业务码 %s 规则失败: %w", target, err)
+		}
+
+		existing := make(map[ruleVariant]uint64, len(existingRules))
+		for _, r := range existingRules {
+			existing[variantOf(r)] = r.ID
+		}
+
+		start := len(clones)
+		for _, r := range sourceRules {
+			if id, ok := existing[variantOf(r)]; ok {
+				report.Collisions = append(report.Collisions, CloneCollision{
+					BizCode: target, Name: r.Name, ExistingID: id, SourceID: r.ID,
+				})
+				continue
+			}
+			clones = append(clones, &rule.Rule{
+				BizCode:     target,
+				Name:        r.Name,
+				GRL:         r.GRL,
+				Version:     r.Version,
+				Enabled:     r.Enabled,
+				Environment: r.Environment,
+				Tags:        r.Tags,
+				Writes:      r.Writes,
+				Description: r.Description,
+				CreatedBy:   r.CreatedBy,
+				UpdatedBy:   r.UpdatedBy,
+				SourceID:    r.ID,
+				Owner:       r.Owner,
+				DocURL:      r.DocURL,
+			})
+		}
+
+		// 检查复制后目标业务码的完整规则集，超出目标租户的限制时整体放弃
+		merged := append(append([]*rule.Rule{}, existingRules...), clones[start:]...)
+		if err := e.CheckRuleLimits(target, merged); err != nil {
+			return nil, err
+		}
+		targets = append(targets, target)
+	}
+
+	if !dryRun {
+		if err := mapper.CreateRules(ctx, clones); err != nil {
+			return nil, fmt.Errorf("写入复制规则失败: %w", err)
+		}
+	}
+
+	for _, r := range clones {
+		report.Cloned = append(report.Cloned, ClonedRule{
+			BizCode: r.BizCode, Name: r.Name, ID: r.ID, SourceID: r.SourceID, Version: r.Version,
+		})
+	}
+
+	if e.logger != nil {
+		e.logger.Infof(ctx, "租户规则复制完成", "from", fromTenant, "to", toTenant, "dryRun", dryRun,
+			"cloned", len(report.Cloned), "collisions", len(report.Collisions))
+	}
+
+	if !dryRun && len(clones) > 0 {
+		for _, target := range targets {
+			if err := e.refreshCache(target); err != nil && e.logger != nil {
+				e.logger.Warnf(ctx, "复制后刷新缓存失败", "bizCode", target, "error", err)
+			}
+		}
+	}
+
+	return report, nil
+}
@@ -0,0 +1,110 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// TestCloneBizCode 测试租户规则复制
+func TestCloneBizCode(t *testing.T) {
+	Convey("租户规则复制", t, func() {
+		db, err := gorm.Open(sqlite.Open("file:clone_biz.db?mode=memory&cache=shared"), &gorm.Config{})
+		So(err, ShouldBeNil)
+		So(db.AutoMigrate(&rule.Rule{}), ShouldBeNil)
+		db.Where("1 = 1").Delete(&rule.Rule{})
+
+		seed := []*rule.Rule{
+			{BizCode: "template.payments", Name: "limit", GRL: `rule Limit "限额" { when true then Result["limit"] = 1000; Retract("Limit"); }`, Version: 3, Enabled: true},
+			{BizCode: "template.payments", Name: "legacy", GRL: `rule Legacy "旧规则" { when true then Result["legacy"] = true; Retract("Legacy"); }`, Version: 1, Enabled: false},
+			{BizCode: "template.refunds", Name: "refund", GRL: `rule Refund "退款" { when true then Result["refund"] = true; Retract("Refund"); }`, Version: 2, Enabled: true},
+			{BizCode: "acme.refunds", Name: "refund", GRL: `rule AcmeRefund "已有退款" { when true then Result["refund"] = false; Retract("AcmeRefund"); }`, Version: 1, Enabled: true},
+		}
+		So(db.Create(&seed).Error, ShouldBeNil)
+
+		engine := NewEngineImpl[map[string]any](
+			config.DefaultConfig(), rule.NewRuleMapper(db), nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+			ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+		)
+		defer engine.Close()
+		ctx := context.Background()
+
+		countRules := func(bizCode string) int64 {
+			var count int64
+			db.Model(&rule.Rule{}).Where("biz_code = ?", bizCode).Count(&count)
+			return count
+		}
+
+		Convey("演练只报告不写入", func() {
+			report, err := engine.CloneBizCodeDryRun(ctx, "template", "acme", "payments", "refunds")
+			So(err, ShouldBeNil)
+			So(report.DryRun, ShouldBeTrue)
+			So(report.Cloned, ShouldHaveLength, 2)
+			So(report.Cloned[0].ID, ShouldEqual, 0)
+			So(report.Collisions, ShouldResemble, []CloneCollision{
+				{BizCode: "acme.refunds", Name: "refund", ExistingID: seed[3].ID, SourceID: seed[2].ID},
+			})
+			So(countRules("acme.payments"), ShouldEqual, 0)
+		})
+
+		Convey("复制规则并保留版本与来源", func() {
+			report, err := engine.CloneBizCode(ctx, "template", "acme", "payments", "refunds")
+			So(err, ShouldBeNil)
+			So(report.Cloned, ShouldHaveLength, 2)
+			So(report.Collisions, ShouldHaveLength, 1)
+
+			var cloned []*rule.Rule
+			So(db.Where("biz_code = ?", "acme.payments").Order("id ASC").Find(&cloned).Error, ShouldBeNil)
+			So(cloned, ShouldHaveLength, 2)
+			So(cloned[0].ID, ShouldNotEqual, seed[0].ID)
+			So(cloned[0].SourceID, ShouldEqual, seed[0].ID)
+			So(cloned[0].Version, ShouldEqual, 3)
+			So(cloned[0].GRL, ShouldEqual, seed[0].GRL)
+			So(cloned[1].Enabled, ShouldBeFalse)
+			So(report.Cloned[0].ID, ShouldEqual, cloned[0].ID)
+
+			// 冲突规则保持不变
+			So(countRules("acme.refunds"), ShouldEqual, 1)
+
+			result, err := engine.Exec(ctx, "acme.payments", map[string]any{"x": 1})
+			So(err, ShouldBeNil)
+			So(result["limit"], ShouldEqual, 1000)
+			So(result["legacy"], ShouldBeNil)
+		})
+
+		Convey("参数校验", func() {
+			_, err := engine.CloneBizCode(ctx, "acme", "acme", "payments")
+			So(err, ShouldNotBeNil)
+			_, err = engine.CloneBizCode(ctx, "template", "", "payments")
+			So(err, ShouldNotBeNil)
+			_, err = engine.CloneBizCode(ctx, "template", "acme")
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("映射器不支持复制", func() {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			other := NewEngineImpl[map[string]any](
+				config.DefaultConfig(), rule.NewMockRuleMapper(ctrl), nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+				ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+			)
+			defer other.Close()
+
+			_, err := other.CloneBizCode(ctx, "template", "acme", "payments")
+			So(errors.Is(err, ErrCloneNotSupported), ShouldBeTrue)
+		})
+	})
+}
@@ -0,0 +1,77 @@
+package engine
+
+import (
+	"context"
+	"strings"
+
+	"gitee.com/damengde/runehammer/rule"
+)
+
+// ============================================================================
+// 业务码层级继承 - payments.cards.fraud 执行时同时包含 payments.cards、payments 的规则
+// ============================================================================
+
+// bizCodeSeparator 业务码层级分隔符
+const bizCodeSeparator = "."
+
+// bizCodeChain 业务码继承链 - 由子到父排列
+//
+// 示例: payments.cards.fraud -> [payments.cards.fraud payments.cards payments]
+func bizCodeChain(bizCode string) []string {
+	chain := []string{bizCode}
+	for {
+		idx := strings.LastIndex(bizCode, bizCodeSeparator)
+		if idx <= 0 {
+			return chain
+		}
+		bizCode = bizCode[:idx]
+		chain = append(chain, bizCode)
+	}
+}
+
+// inheritanceEnabled 是否启用业务码层级继承
+func (e *engineImpl[T]) inheritanceEnabled() bool {
+	return e.config != nil && e.config.BizCodeInheritance
+}
+
+// resolveRules 获取业务码的有效规则 - 启用继承时合并父级业务码规则
+//
+// 优先级确定: 按继承链由子到父合并，子级与父级存在同名规则（Rule.Name）时子级覆盖父级；
+// 合并后的规则按子级在前、父级在后排列，同级保持获取顺序。
+func (e *engineImpl[T]) resolveRules(ctx context.Context, bizCode string) ([]*rule.Rule, error) {
+	if !e.inheritanceEnabled() {
+		return e.getRules(ctx, bizCode)
+	}
+
+	var merged []*rule.Rule
+	seen := make(map[string]bool)
+	for _, code := range bizCodeChain(bizCode) {
+		rules, err := e.getRules(ctx, code)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range rules {
+			if seen[r.Name] {
+				continue
+			}
+			seen[r.Name] = true
+			merged = append(merged, r)
+		}
+	}
+	return merged, nil
+}
+
+// invalidateDescendants 清理子业务码的编译缓存 - 父级规则变化时子级需重新合并编译
+func (e *engineImpl[T]) invalidateDescendants(bizCode string) {
+	if !e.inheritanceEnabled() {
+		return
+	}
+
+	prefix := bizCode + bizCodeSeparator
+	e.knowledgeBases.Range(func(key, value interface{}) bool {
+		if code, ok := key.(string); ok && strings.HasPrefix(code, prefix) {
+			e.dropKnowledgeBase(code)
+		}
+		return true
+	})
+}
@@ -0,0 +1,85 @@
+package engine
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+// TestBizCodeInheritance 测试业务码层级继承
+func TestBizCodeInheritance(t *testing.T) {
+	Convey("业务码层级继承", t, func() {
+
+		Convey("继承链由子到父", func() {
+			So(bizCodeChain("payments.cards.fraud"), ShouldResemble, []string{"payments.cards.fraud", "payments.cards", "payments"})
+			So(bizCodeChain("payments"), ShouldResemble, []string{"payments"})
+			So(bizCodeChain(".hidden"), ShouldResemble, []string{".hidden"})
+		})
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mapper := rule.NewMockRuleMapper(ctrl)
+		mapper.EXPECT().FindByBizCode(gomock.Any(), "payments").Return([]*rule.Rule{
+			{Name: "limit", GRL: `rule ParentLimit "父级限额" { when true then Result["limit"] = 1000; Retract("ParentLimit"); }`, Enabled: true},
+			{Name: "audit", GRL: `rule ParentAudit "父级审计" { when true then Result["audit"] = true; Retract("ParentAudit"); }`, Enabled: true},
+		}, nil).AnyTimes()
+		mapper.EXPECT().FindByBizCode(gomock.Any(), "payments.cards").Return([]*rule.Rule{
+			{Name: "limit", GRL: `rule CardLimit "卡限额" { when true then Result["limit"] = 500; Retract("CardLimit"); }`, Enabled: true},
+		}, nil).AnyTimes()
+		mapper.EXPECT().FindByBizCode(gomock.Any(), "payments.cards.fraud").Return([]*rule.Rule{
+			{Name: "fraud", GRL: `rule Fraud "欺诈检查" { when true then Result["fraud"] = false; Retract("Fraud"); }`, Enabled: true},
+		}, nil).AnyTimes()
+
+		cfg := config.DefaultConfig()
+		cfg.BizCodeInheritance = true
+		engine := NewEngineImpl[map[string]any](
+			cfg, mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+			ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+		)
+		defer engine.Close()
+
+		Convey("子业务码包含父级规则，同名规则子级覆盖", func() {
+			rules, err := engine.resolveRules(context.Background(), "payments.cards.fraud")
+			So(err, ShouldBeNil)
+			names := make([]string, 0, len(rules))
+			for _, r := range rules {
+				names = append(names, r.Name)
+			}
+			So(names, ShouldResemble, []string{"fraud", "limit", "audit"})
+
+			result, err := engine.Exec(context.Background(), "payments.cards.fraud", map[string]any{"amount": 1})
+			So(err, ShouldBeNil)
+			So(result["fraud"], ShouldEqual, false)
+			So(result["limit"], ShouldEqual, 500)
+			So(result["audit"], ShouldEqual, true)
+		})
+
+		Convey("父级刷新时清理子业务码编译缓存", func() {
+			_, err := engine.Exec(context.Background(), "payments.cards.fraud", map[string]any{"amount": 1})
+			So(err, ShouldBeNil)
+			_, ok := engine.knowledgeBases.Load("payments.cards.fraud")
+			So(ok, ShouldBeTrue)
+
+			So(engine.refreshCache("payments"), ShouldBeNil)
+			_, ok = engine.knowledgeBases.Load("payments.cards.fraud")
+			So(ok, ShouldBeFalse)
+		})
+
+		Convey("未启用时仅执行自身规则", func() {
+			cfg.BizCodeInheritance = false
+			rules, err := engine.resolveRules(context.Background(), "payments.cards.fraud")
+			So(err, ShouldBeNil)
+			So(rules, ShouldHaveLength, 1)
+		})
+	})
+}
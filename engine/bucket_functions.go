@@ -0,0 +1,147 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+)
+
+// ============================================================================
+// 区间分桶函数 - 评分到等级等映射，避免一串 between 条件
+// ============================================================================
+//
+// 阈值为升序的区间下限，区间左闭右开：值小于第一个阈值时落在第0个桶，
+// 大于等于第i个阈值且小于第i+1个阈值时落在第i+1个桶。阈值和标签可以是切片，
+// 也可以是JSON数组字符串（如 "[300,600,700]"）或逗号分隔字符串（如 "300,600,700"），
+// 后者便于在GRL中直接书写；MetricRule 的 Bands 区间表会生成JSON数组形式的 Band 调用。
+
+// ErrInvalidBands 阈值或标签无效
+var ErrInvalidBands = errors.New("区间定义无效")
+
+// parseThresholds 解析升序阈值
+func parseThresholds(thresholds any) ([]float64, error) {
+	items, err := bandItems(thresholds)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]float64, len(items))
+	for i, item := range items {
+		value, ok := numericArg(item)
+		if !ok {
+			return nil, fmt.Errorf("%w: 第%d个阈值 %v 不是数值", ErrInvalidBands, i+1, item)
+		}
+		if i > 0 && value <= values[i-1] {
+			return nil, fmt.Errorf("%w: 阈值必须严格升序（%v 不大于 %v）", ErrInvalidBands, value, values[i-1])
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// bandItems 将切片、JSON数组字符串或逗号分隔字符串转换为元素列表
+func bandItems(value any) ([]any, error) {
+	text, ok := value.(string)
+	if !ok {
+		items, ok := sliceItems(value)
+		if !ok {
+			return nil, fmt.Errorf("%w: 不支持的类型 %T", ErrInvalidBands, value)
+		}
+		return items, nil
+	}
+
+	text = strings.TrimSpace(text)
+	if strings.HasPrefix(text, "[") {
+		parsed, ok := parseJSON(text)
+		items, isArray := parsed.([]any)
+		if !ok || !isArray {
+			return nil, fmt.Errorf("%w: 无效的JSON数组 %s", ErrInvalidBands, text)
+		}
+		return items, nil
+	}
+	if text == "" {
+		return []any{}, nil
+	}
+	parts := strings.Split(text, ",")
+	items := make([]any, len(parts))
+	for i, part := range parts {
+		items[i] = strings.TrimSpace(part)
+	}
+	return items, nil
+}
+
+// numericArg 数值参数，兼容逗号分隔字符串中的数字文本
+func numericArg(value any) (float64, bool) {
+	if n, ok := toFloat(value); ok {
+		return n, true
+	}
+	if text, ok := value.(string); ok {
+		n, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+// bucketIndex 值所在的桶序号，即不大于该值的阈值个数
+func bucketIndex(value any, thresholds []float64) (int, error) {
+	n, ok := numericArg(value)
+	if !ok {
+		return 0, fmt.Errorf("%w: 分桶的值 %v 不是数值", ErrInvalidBands, value)
+	}
+	index := 0
+	for index < len(thresholds) && n >= thresholds[index] {
+		index++
+	}
+	return index, nil
+}
+
+// bucket 计算桶序号，参数无效时panic，由Grule作为执行错误返回
+func bucket(value any, thresholds any) int64 {
+	parsed, err := parseThresholds(thresholds)
+	if err != nil {
+		panic(fmt.Errorf("Bucket: %w", err))
+	}
+	index, err := bucketIndex(value, parsed)
+	if err != nil {
+		panic(fmt.Errorf("Bucket: %w", err))
+	}
+	return int64(index)
+}
+
+// band 按区间取标签，标签个数必须比阈值多一个（第一个为低于最小阈值时的标签）
+func band(value any, thresholds any, labels any) any {
+	parsed, err := parseThresholds(thresholds)
+	if err != nil {
+		panic(fmt.Errorf("Band: %w", err))
+	}
+	items, err := bandItems(labels)
+	if err != nil {
+		panic(fmt.Errorf("Band: %w", err))
+	}
+	if len(items) != len(parsed)+1 {
+		panic(fmt.Errorf("Band: %w: %d个阈值需要%d个标签，实际为%d个", ErrInvalidBands, len(parsed), len(parsed)+1, len(items)))
+	}
+	index, err := bucketIndex(value, parsed)
+	if err != nil {
+		panic(fmt.Errorf("Band: %w", err))
+	}
+	return items[index]
+}
+
+// Bucket 值所在的桶序号 (0~len(thresholds))
+func (f *ruleFunctions) Bucket(value any, thresholds any) int64 {
+	return bucket(value, thresholds)
+}
+
+// Band 按区间取标签或值，如 Band(Params["score"], "600,700", "C,B,A")
+func (f *ruleFunctions) Band(value any, thresholds any, labels any) any {
+	return band(value, thresholds, labels)
+}
+
+// injectBucketFunctions 注入区间分桶函数
+func injectBucketFunctions(dataCtx ast.IDataContext) {
+	dataCtx.Add("Bucket", bucket)
+	dataCtx.Add("Band", band)
+}
@@ -0,0 +1,92 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+// TestBucketFunctions 测试区间分桶函数
+func TestBucketFunctions(t *testing.T) {
+	Convey("区间分桶函数", t, func() {
+		Convey("桶序号，区间左闭右开", func() {
+			thresholds := []any{600, 700, 800}
+			So(bucket(599, thresholds), ShouldEqual, 0)
+			So(bucket(600, thresholds), ShouldEqual, 1)
+			So(bucket(750.5, thresholds), ShouldEqual, 2)
+			So(bucket(int64(900), thresholds), ShouldEqual, 3)
+		})
+
+		Convey("阈值支持JSON数组和逗号分隔字符串", func() {
+			So(bucket(650, "[600, 700]"), ShouldEqual, 1)
+			So(bucket(650, " 600 , 700 "), ShouldEqual, 1)
+			So(bucket("720", []float64{600, 700}), ShouldEqual, 2)
+		})
+
+		Convey("按区间取标签，保留JSON中的值类型", func() {
+			So(band(650, "600,700", "C,B,A"), ShouldEqual, "B")
+			So(band(500, "[600,700]", `["low",1.5,2]`), ShouldEqual, "low")
+			So(band(710, "[600,700]", `["low",1.5,2]`), ShouldEqual, int64(2))
+		})
+
+		Convey("定义无效时报错", func() {
+			for _, call := range []func(){
+				func() { bucket(1, "700,600") },
+				func() { bucket(1, "600,x") },
+				func() { bucket("abc", "600") },
+				func() { bucket(1, "[600") },
+				func() { band(1, "600,700", "A,B") },
+			} {
+				func() {
+					defer func() {
+						err, _ := recover().(error)
+						So(errors.Is(err, ErrInvalidBands), ShouldBeTrue)
+					}()
+					call()
+				}()
+			}
+		})
+
+		Convey("指标规则的区间表在规则中执行", func() {
+			grl, err := rule.NewGRLConverter().ConvertToGRL(rule.MetricRule{
+				Name:    "grade",
+				Formula: `Params["score"]`,
+				Bands: &rule.BandTable{
+					Default: "D",
+					Bands:   []rule.Band{{Min: 600, Value: "C"}, {Min: 700, Value: "B"}, {Min: 800, Value: "A"}},
+				},
+			})
+			So(err, ShouldBeNil)
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mapper := rule.NewMockRuleMapper(ctrl)
+			mapper.EXPECT().FindByBizCode(gomock.Any(), "credit").Return([]*rule.Rule{
+				{Name: "grade", Enabled: true, GRL: grl},
+				{Name: "tier", Enabled: true, GRL: `rule Tier "分层" { when true then Result["tier"] = Bucket(Params["score"], "650,750"); Retract("Tier"); }`},
+			}, nil).AnyTimes()
+
+			engine := NewEngineImpl[map[string]any](
+				config.DefaultConfig(), mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+				ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+			)
+			defer engine.Close()
+
+			result, err := engine.Exec(context.Background(), "credit", map[string]any{"score": 720})
+			So(err, ShouldBeNil)
+			So(result["grade"], ShouldEqual, "B")
+			So(result["tier"], ShouldEqual, 1)
+		})
+	})
+}
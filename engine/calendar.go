@@ -0,0 +1,233 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ============================================================================
+// 节假日日历 - 结算、SLA等规则按地区判断节假日和工作日
+// ============================================================================
+//
+// 规则通过 IsHoliday(date, region)、IsBusinessDay(date, region)、NextBusinessDay(date, region) 使用，
+// 日期可以是 time.Time 或 2006-01-02 / RFC3339 格式的字符串。日历由 CalendarProvider 提供，
+// 内置的 Calendar 从YAML加载，格式如下（weekend 默认周六、周日，workdays 为调休上班的日期）:
+//
+//	CN:
+//	  holidays: [2024-10-01, 2024-10-02, 2024-10-03]
+//	  workdays: [2024-10-12]
+//	US:
+//	  weekend: [Saturday, Sunday]
+//	  holidays: [2024-07-04, 2024-12-25]
+
+// maxBusinessDaySearch NextBusinessDay 向后查找的最大天数
+const maxBusinessDaySearch = 366
+
+var (
+	// ErrNoCalendar 规则调用日历函数但未设置日历提供者
+	ErrNoCalendar = errors.New("未设置节假日日历")
+	// ErrUnknownRegion 日历中没有该地区
+	ErrUnknownRegion = errors.New("日历中没有该地区")
+)
+
+// CalendarProvider 节假日日历提供者
+type CalendarProvider interface {
+	// IsHoliday 是否为法定节假日（不含普通周末）
+	IsHoliday(ctx context.Context, date time.Time, region string) (bool, error)
+	// IsBusinessDay 是否为工作日 - 非周末且非节假日，或为调休上班日
+	IsBusinessDay(ctx context.Context, date time.Time, region string) (bool, error)
+}
+
+// regionCalendar 单个地区的日历
+type regionCalendar struct {
+	weekend  map[time.Weekday]bool
+	holidays map[string]bool
+	workdays map[string]bool
+}
+
+// Calendar 内置的节假日日历 - 按地区保存节假日、调休上班日和周末，实现 CalendarProvider
+type Calendar struct {
+	regions map[string]*regionCalendar
+}
+
+// calendarRegionSpec YAML中单个地区的定义
+type calendarRegionSpec struct {
+	Weekend  []string `yaml:"weekend"`
+	Holidays []string `yaml:"holidays"`
+	Workdays []string `yaml:"workdays"`
+}
+
+// weekdayNames YAML中周末的写法，不区分大小写
+var weekdayNames = map[string]time.Weekday{
+	"sunday": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday,
+	"thursday": time.Thursday, "friday": time.Friday, "saturday": time.Saturday,
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// ParseCalendar 解析YAML格式的节假日日历，地区名不区分大小写
+func ParseCalendar(data []byte) (*Calendar, error) {
+	var specs map[string]calendarRegionSpec
+	if err := yaml.Unmarshal(data, &specs); err != nil {
+		return nil, fmt.Errorf("解析节假日日历失败: %w", err)
+	}
+
+	calendar := &Calendar{regions: make(map[string]*regionCalendar, len(specs))}
+	for region, spec := range specs {
+		rc := &regionCalendar{
+			weekend:  map[time.Weekday]bool{time.Saturday: true, time.Sunday: true},
+			holidays: make(map[string]bool, len(spec.Holidays)),
+			workdays: make(map[string]bool, len(spec.Workdays)),
+		}
+		if spec.Weekend != nil {
+			rc.weekend = make(map[time.Weekday]bool, len(spec.Weekend))
+			for _, name := range spec.Weekend {
+				day, ok := weekdayNames[strings.ToLower(strings.TrimSpace(name))]
+				if !ok {
+					return nil, fmt.Errorf("地区 %s 的周末 %q 无效", region, name)
+				}
+				rc.weekend[day] = true
+			}
+		}
+		for _, dates := range []struct {
+			values []string
+			target map[string]bool
+		}{{spec.Holidays, rc.holidays}, {spec.Workdays, rc.workdays}} {
+			for _, value := range dates.values {
+				date, err := time.Parse(time.DateOnly, strings.TrimSpace(value))
+				if err != nil {
+					return nil, fmt.Errorf("地区 %s 的日期 %q 无效，应为 2006-01-02 格式", region, value)
+				}
+				dates.target[date.Format(time.DateOnly)] = true
+			}
+		}
+		calendar.regions[strings.ToUpper(region)] = rc
+	}
+	return calendar, nil
+}
+
+// LoadCalendar 从YAML文件加载节假日日历
+func LoadCalendar(path string) (*Calendar, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取节假日日历失败: %w", err)
+	}
+	return ParseCalendar(data)
+}
+
+// region 查找地区日历
+func (c *Calendar) region(region string) (*regionCalendar, error) {
+	rc, ok := c.regions[strings.ToUpper(strings.TrimSpace(region))]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownRegion, region)
+	}
+	return rc, nil
+}
+
+// IsHoliday 实现CalendarProvider
+func (c *Calendar) IsHoliday(ctx context.Context, date time.Time, region string) (bool, error) {
+	rc, err := c.region(region)
+	if err != nil {
+		return false, err
+	}
+	return rc.holidays[date.Format(time.DateOnly)], nil
+}
+
+// IsBusinessDay 实现CalendarProvider
+func (c *Calendar) IsBusinessDay(ctx context.Context, date time.Time, region string) (bool, error) {
+	rc, err := c.region(region)
+	if err != nil {
+		return false, err
+	}
+	key := date.Format(time.DateOnly)
+	if rc.workdays[key] {
+		return true, nil
+	}
+	return !rc.weekend[date.Weekday()] && !rc.holidays[key], nil
+}
+
+// nextBusinessDay date之后的第一个工作日，保留时刻
+func nextBusinessDay(ctx context.Context, calendar CalendarProvider, date time.Time, region string) (time.Time, error) {
+	for i := 1; i <= maxBusinessDaySearch; i++ {
+		day := date.AddDate(0, 0, i)
+		ok, err := calendar.IsBusinessDay(ctx, day, region)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if ok {
+			return day, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%s 之后 %d 天内没有工作日", date.Format(time.DateOnly), maxBusinessDaySearch)
+}
+
+// dateArg 日期参数 - time.Time 或 2006-01-02 / RFC3339 格式的字符串
+func dateArg(value any) (time.Time, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, nil
+	case string:
+		text := strings.TrimSpace(v)
+		if date, err := time.Parse(time.DateOnly, text); err == nil {
+			return date, nil
+		}
+		if date, err := time.Parse(time.RFC3339, text); err == nil {
+			return date, nil
+		}
+		return time.Time{}, fmt.Errorf("日期 %q 应为 2006-01-02 或 RFC3339 格式", v)
+	}
+	return time.Time{}, fmt.Errorf("日期必须是时间或字符串，实际为 %T", value)
+}
+
+// SetCalendarProvider 设置节假日日历提供者，为nil时规则中的日历函数执行返回 ErrNoCalendar
+func (e *engineImpl[T]) SetCalendarProvider(provider CalendarProvider) {
+	e.calendar = provider
+}
+
+// calendarDate 日历函数的公共参数检查
+func (f *ruleFunctions) calendarDate(name string, value any) time.Time {
+	if f.calendar == nil {
+		f.raise(name, ErrNoCalendar)
+	}
+	date, err := dateArg(value)
+	if err != nil {
+		f.raise(name, err)
+	}
+	return date
+}
+
+// IsHoliday 是否为法定节假日（不含普通周末）
+func (f *ruleFunctions) IsHoliday(date any, region any) bool {
+	day := f.calendarDate("IsHoliday", date)
+	ok, err := f.calendar.IsHoliday(f.ctx, day, stringArg(region))
+	if err != nil {
+		f.raise("IsHoliday", err)
+	}
+	return ok
+}
+
+// IsBusinessDay 是否为工作日
+func (f *ruleFunctions) IsBusinessDay(date any, region any) bool {
+	day := f.calendarDate("IsBusinessDay", date)
+	ok, err := f.calendar.IsBusinessDay(f.ctx, day, stringArg(region))
+	if err != nil {
+		f.raise("IsBusinessDay", err)
+	}
+	return ok
+}
+
+// NextBusinessDay date之后的第一个工作日，如 T+1 结算日
+func (f *ruleFunctions) NextBusinessDay(date any, region any) time.Time {
+	day := f.calendarDate("NextBusinessDay", date)
+	next, err := nextBusinessDay(f.ctx, f.calendar, day, stringArg(region))
+	if err != nil {
+		f.raise("NextBusinessDay", err)
+	}
+	return next
+}
@@ -0,0 +1,132 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+const testCalendarYAML = `
+CN:
+  holidays: [2024-10-01, 2024-10-02, 2024-10-03, 2024-10-04, 2024-10-07]
+  workdays: [2024-10-12]
+ae:
+  weekend: [Saturday, sun]
+  holidays: ["2024-12-02"]
+`
+
+// TestCalendar 测试节假日日历
+func TestCalendar(t *testing.T) {
+	Convey("节假日日历", t, func() {
+		calendar, err := ParseCalendar([]byte(testCalendarYAML))
+		So(err, ShouldBeNil)
+		ctx := context.Background()
+		day := func(s string) time.Time {
+			d, _ := time.Parse(time.DateOnly, s)
+			return d
+		}
+
+		Convey("节假日与工作日", func() {
+			ok, err := calendar.IsHoliday(ctx, day("2024-10-01"), "cn")
+			So(err, ShouldBeNil)
+			So(ok, ShouldBeTrue)
+
+			ok, _ = calendar.IsHoliday(ctx, day("2024-10-05"), "CN")
+			So(ok, ShouldBeFalse) // 普通周末不是节假日
+
+			ok, _ = calendar.IsBusinessDay(ctx, day("2024-10-05"), "CN")
+			So(ok, ShouldBeFalse)
+			ok, _ = calendar.IsBusinessDay(ctx, day("2024-10-12"), "CN")
+			So(ok, ShouldBeTrue) // 调休上班的周六
+			ok, _ = calendar.IsBusinessDay(ctx, day("2024-10-08"), "CN")
+			So(ok, ShouldBeTrue)
+
+			_, err = calendar.IsHoliday(ctx, day("2024-10-01"), "JP")
+			So(errors.Is(err, ErrUnknownRegion), ShouldBeTrue)
+		})
+
+		Convey("下一个工作日", func() {
+			next, err := nextBusinessDay(ctx, calendar, day("2024-09-30"), "CN")
+			So(err, ShouldBeNil)
+			So(next, ShouldEqual, day("2024-10-08"))
+
+			next, err = nextBusinessDay(ctx, calendar, day("2024-11-29"), "AE")
+			So(err, ShouldBeNil)
+			So(next, ShouldEqual, day("2024-12-03"))
+		})
+
+		Convey("日历格式错误", func() {
+			_, err := ParseCalendar([]byte("CN:\n  holidays: [2024-13-01]\n"))
+			So(err, ShouldNotBeNil)
+			_, err = ParseCalendar([]byte("CN:\n  weekend: [Funday]\n"))
+			So(err, ShouldNotBeNil)
+			_, err = LoadCalendar(filepath.Join(t.TempDir(), "missing.yaml"))
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("从文件加载", func() {
+			path := filepath.Join(t.TempDir(), "calendar.yaml")
+			So(os.WriteFile(path, []byte(testCalendarYAML), 0o600), ShouldBeNil)
+			loaded, err := LoadCalendar(path)
+			So(err, ShouldBeNil)
+			ok, _ := loaded.IsHoliday(ctx, day("2024-12-02"), "AE")
+			So(ok, ShouldBeTrue)
+		})
+
+		Convey("日期参数", func() {
+			d, err := dateArg("2024-10-01")
+			So(err, ShouldBeNil)
+			So(d, ShouldEqual, day("2024-10-01"))
+			_, err = dateArg("2024-10-01T08:00:00+08:00")
+			So(err, ShouldBeNil)
+			_, err = dateArg("01/10/2024")
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("规则中使用", func() {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mapper := rule.NewMockRuleMapper(ctrl)
+			mapper.EXPECT().FindByBizCode(gomock.Any(), "settle").Return([]*rule.Rule{
+				{Name: "settle", Enabled: true, GRL: `rule Settle "结算日" {
+	when IsHoliday(Params["tradeDate"], "CN") || IsBusinessDay(Params["tradeDate"], "CN") == false
+	then
+		Result["settleDate"] = TimeFormat(NextBusinessDay(Params["tradeDate"], "CN"), "2006-01-02");
+		Retract("Settle");
+}`},
+			}, nil).AnyTimes()
+
+			engine := NewEngineImpl[map[string]any](
+				config.DefaultConfig(), mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+				ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+			)
+			defer engine.Close()
+
+			_, err := engine.Exec(ctx, "settle", map[string]any{"tradeDate": "2024-10-01"})
+			So(errors.Is(err, ErrNoCalendar), ShouldBeTrue)
+
+			engine.SetCalendarProvider(calendar)
+			result, err := engine.Exec(ctx, "settle", map[string]any{"tradeDate": "2024-10-01"})
+			So(err, ShouldBeNil)
+			So(result["settleDate"], ShouldEqual, "2024-10-08")
+
+			result, err = engine.Exec(ctx, "settle", map[string]any{"tradeDate": day("2024-10-09")})
+			So(err, ShouldBeNil)
+			So(result, ShouldNotContainKey, "settleDate")
+		})
+	})
+}
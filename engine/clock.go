@@ -0,0 +1,74 @@
+package engine
+
+import (
+	"time"
+)
+
+// ============================================================================
+// 时钟 - 规则中的 Now()/Today()/NowMillis() 从可替换的时钟取当前时间，便于确定性测试
+// ============================================================================
+//
+// Grule将 Now() 解析为内置函数对象DEFUNC的方法，执行时由 ruleFunctions 覆盖为按引擎时钟取值；
+// 未设置时钟时使用系统时间。
+
+// Clock 时钟接口 - 提供当前时间
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc 函数适配器，将普通函数转换为 Clock
+type ClockFunc func() time.Time
+
+// Now 实现Clock接口
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// systemClock 系统时钟
+type systemClock struct{}
+
+// Now 实现Clock接口
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// SystemClock 使用系统时间的时钟
+var SystemClock Clock = systemClock{}
+
+// clockOrSystem 未设置时钟时返回系统时钟
+func clockOrSystem(clock Clock) Clock {
+	if clock == nil {
+		return SystemClock
+	}
+	return clock
+}
+
+// startOfDay 当天零点
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// SetClock 设置规则时间函数使用的时钟，为nil时使用系统时间
+func (e *engineImpl[T]) SetClock(clock Clock) {
+	e.clock = clock
+}
+
+// SetClock 设置规则时间函数使用的时钟，为nil时使用系统时间
+func (e *DynamicEngine[T]) SetClock(clock Clock) {
+	e.clock = clock
+}
+
+// Now 覆盖Grule内置函数 - 按引擎时钟返回当前时间
+func (f *ruleFunctions) Now() time.Time {
+	return clockOrSystem(f.clock).Now()
+}
+
+// Today 当天零点（时钟所在时区）
+func (f *ruleFunctions) Today() time.Time {
+	return startOfDay(f.Now())
+}
+
+// NowMillis 当前毫秒时间戳
+func (f *ruleFunctions) NowMillis() int64 {
+	return f.Now().UnixMilli()
+}
@@ -0,0 +1,72 @@
+package engine
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+// TestClock 测试规则时间函数使用注入的时钟
+func TestClock(t *testing.T) {
+	Convey("规则时间函数使用注入的时钟", t, func() {
+		ctx := context.Background()
+		fixed := time.Date(2024, 12, 31, 23, 59, 30, 0, time.UTC)
+		clock := ClockFunc(func() time.Time { return fixed })
+
+		Convey("规则引擎", func() {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mapper := rule.NewMockRuleMapper(ctrl)
+			mapper.EXPECT().FindByBizCode(gomock.Any(), "clock").Return([]*rule.Rule{
+				{Name: "year", Enabled: true, GRL: `rule Year "年末" {
+	when GetTimeYear(Now()) == 2024 && GetTimeDay(Today()) == 31
+	then
+		Result["millis"] = NowMillis();
+		Result["hour"] = GetTimeHour(Today());
+		Retract("Year");
+}`},
+			}, nil).AnyTimes()
+			engine := NewEngineImpl[map[string]any](
+				config.DefaultConfig(), mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+				ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+			)
+			defer engine.Close()
+			engine.SetClock(clock)
+
+			result, err := engine.Exec(ctx, "clock", map[string]any{})
+			So(err, ShouldBeNil)
+			So(result["millis"], ShouldEqual, fixed.UnixMilli())
+			So(result["hour"], ShouldEqual, 0)
+
+			Convey("未设置时钟时使用系统时间", func() {
+				engine.SetClock(nil)
+				result, err := engine.Exec(ctx, "clock", map[string]any{})
+				So(err, ShouldBeNil)
+				So(result["millis"], ShouldBeNil)
+			})
+		})
+
+		Convey("动态引擎", func() {
+			engine := NewDynamicEngine[map[string]interface{}](DynamicEngineConfig{EnableCache: false})
+			engine.SetClock(clock)
+
+			result, err := engine.ExecuteRuleDefinition(ctx, rule.SimpleRule{
+				When: "NowMillis() > 0",
+				Then: map[string]string{"Result.Millis": "NowMillis()"},
+			}, struct{ Amount int }{Amount: 1})
+			So(err, ShouldBeNil)
+			So(result["Millis"], ShouldEqual, fixed.UnixMilli())
+		})
+	})
+}
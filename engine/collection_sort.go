@@ -0,0 +1,170 @@
+package engine
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+)
+
+// ============================================================================
+// 集合排序与分组 - 规则内的排名逻辑，如从多个报价中选出最优报价
+// ============================================================================
+//
+// 元素可以是map或结构体，field 为字段路径（与 Get 相同，如 price、terms.rate）。
+// 排序值支持数值、字符串、时间和布尔，同类型之间比较；字段缺失或类型无法比较的元素
+// 无论升降序都排在最后，相等元素保持原有顺序。返回的切片和map可直接写入Result，
+// 也可以在规则中按下标取用，如 TopN(Params["offers"], "score", 1)[0]。
+
+// sortValue 元素的排序值
+type sortValue struct {
+	item   any
+	number float64
+	text   string
+	moment time.Time
+	kind   int // 0 缺失，1 数值，2 字符串，3 时间
+}
+
+// newSortValue 取元素字段的排序值
+func newSortValue(item any, field string) sortValue {
+	value := sortValue{item: item}
+	raw, ok := lookupPath(item, field)
+	if !ok || raw == nil {
+		return value
+	}
+	if n, ok := toFloat(raw); ok {
+		value.number, value.kind = n, 1
+		return value
+	}
+	switch v := raw.(type) {
+	case string:
+		value.text, value.kind = v, 2
+	case time.Time:
+		value.moment, value.kind = v, 3
+	case bool:
+		value.kind = 1
+		if v {
+			value.number = 1
+		}
+	}
+	return value
+}
+
+// less 比较两个排序值，类型不同时按类型序比较，保证排序稳定可预期
+func (v sortValue) less(other sortValue) bool {
+	if v.kind != other.kind {
+		return v.kind < other.kind
+	}
+	switch v.kind {
+	case 1:
+		return v.number < other.number
+	case 2:
+		return v.text < other.text
+	case 3:
+		return v.moment.Before(other.moment)
+	}
+	return false
+}
+
+// sliceItems 将任意切片或数组转换为[]any，其他类型返回false
+func sliceItems(slice any) ([]any, bool) {
+	if items, ok := slice.([]any); ok {
+		return items, true
+	}
+	v := reflect.ValueOf(slice)
+	if !v.IsValid() || (v.Kind() != reflect.Slice && v.Kind() != reflect.Array) {
+		return nil, false
+	}
+	items := make([]any, v.Len())
+	for i := range items {
+		items[i] = v.Index(i).Interface()
+	}
+	return items, true
+}
+
+// sortItems 按字段稳定排序，返回新切片，字段缺失的元素排在最后
+func sortItems(slice any, field string, desc bool) []any {
+	items, ok := sliceItems(slice)
+	if !ok {
+		return []any{}
+	}
+
+	values := make([]sortValue, len(items))
+	for i, item := range items {
+		values[i] = newSortValue(item, field)
+	}
+	sort.SliceStable(values, func(i, j int) bool {
+		a, b := values[i], values[j]
+		if (a.kind == 0) != (b.kind == 0) {
+			return b.kind == 0
+		}
+		if desc {
+			return b.less(a)
+		}
+		return a.less(b)
+	})
+
+	sorted := make([]any, len(values))
+	for i, value := range values {
+		sorted[i] = value.item
+	}
+	return sorted
+}
+
+// topItems 按字段降序取前n个元素
+func topItems(slice any, field string, n int) []any {
+	sorted := sortItems(slice, field, true)
+	if n < 0 {
+		n = 0
+	}
+	if n < len(sorted) {
+		sorted = sorted[:n]
+	}
+	return sorted
+}
+
+// groupItems 按字段值分组，键为字段值的字符串形式，字段缺失的元素归入空字符串键
+func groupItems(slice any, field string) map[string]any {
+	groups := make(map[string]any)
+	items, ok := sliceItems(slice)
+	if !ok {
+		return groups
+	}
+	for _, item := range items {
+		key := ""
+		if raw, ok := lookupPath(item, field); ok && raw != nil {
+			key = fmt.Sprint(raw)
+		}
+		group, _ := groups[key].([]any)
+		groups[key] = append(group, item)
+	}
+	return groups
+}
+
+// SortBy 按字段排序集合，desc为true时降序
+func (f *ruleFunctions) SortBy(slice any, field string, desc bool) []any {
+	return sortItems(slice, field, desc)
+}
+
+// TopN 按字段降序取前n个元素，n 为规则中的整数字面量（int64）或参数值
+func (f *ruleFunctions) TopN(slice any, field string, n any) []any {
+	count, ok := toFloat(n)
+	if !ok {
+		panic(fmt.Errorf("TopN 的n必须是数值，实际为 %T", n))
+	}
+	return topItems(slice, field, int(count))
+}
+
+// GroupBy 按字段值分组
+func (f *ruleFunctions) GroupBy(slice any, field string) map[string]any {
+	return groupItems(slice, field)
+}
+
+// injectSortFunctions 注入集合排序与分组函数
+func injectSortFunctions(dataCtx ast.IDataContext) {
+	dataCtx.Add("SortBy", sortItems)
+	dataCtx.Add("TopN", topItems)
+	dataCtx.Add("GroupBy", groupItems)
+}
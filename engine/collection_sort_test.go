@@ -0,0 +1,101 @@
+package engine
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+// TestCollectionSortFunctions 测试集合排序与分组函数
+func TestCollectionSortFunctions(t *testing.T) {
+	Convey("集合排序与分组函数", t, func() {
+		offers := []any{
+			map[string]any{"id": "a", "price": 30, "vendor": "x"},
+			map[string]any{"id": "b", "price": 12.5, "vendor": "y"},
+			map[string]any{"id": "c", "vendor": "x"},
+			map[string]any{"id": "d", "price": int64(30), "vendor": "y"},
+		}
+		ids := func(items []any) []any {
+			result := make([]any, len(items))
+			for i, item := range items {
+				result[i] = item.(map[string]any)["id"]
+			}
+			return result
+		}
+
+		Convey("按字段排序，缺失值排在最后，相等元素保持原顺序", func() {
+			So(ids(sortItems(offers, "price", false)), ShouldResemble, []any{"b", "a", "d", "c"})
+			So(ids(sortItems(offers, "price", true)), ShouldResemble, []any{"a", "d", "b", "c"})
+			So(ids(sortItems(offers, "id", true)), ShouldResemble, []any{"d", "c", "b", "a"})
+			So(offers[0].(map[string]any)["id"], ShouldEqual, "a")
+		})
+
+		Convey("支持结构体切片和时间字段", func() {
+			type offer struct {
+				ID      string
+				Expires time.Time
+			}
+			now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			items := []offer{{"late", now.Add(time.Hour)}, {"early", now}}
+			sorted := sortItems(items, "Expires", false)
+			So(sorted[0].(offer).ID, ShouldEqual, "early")
+		})
+
+		Convey("取前N个", func() {
+			So(ids(topItems(offers, "price", 2)), ShouldResemble, []any{"a", "d"})
+			So(topItems(offers, "price", 10), ShouldHaveLength, 4)
+			So(topItems(offers, "price", -1), ShouldBeEmpty)
+			So(topItems("not a slice", "price", 1), ShouldBeEmpty)
+		})
+
+		Convey("按字段分组", func() {
+			groups := groupItems(offers, "vendor")
+			So(groups, ShouldHaveLength, 2)
+			So(ids(groups["x"].([]any)), ShouldResemble, []any{"a", "c"})
+			So(ids(groupItems(offers, "price")[""].([]any)), ShouldResemble, []any{"c"})
+		})
+
+		Convey("规则中使用", func() {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mapper := rule.NewMockRuleMapper(ctrl)
+			mapper.EXPECT().FindByBizCode(gomock.Any(), "offer").Return([]*rule.Rule{
+				{Name: "best", Enabled: true, GRL: `rule BestOffer "选择最优报价" {
+	when true
+	then
+		Result["best"] = TopN(Params["offers"], "score", 1)[0];
+		Result["cheapest"] = SortBy(Params["offers"], "price", false);
+		Result["byVendor"] = GroupBy(Params["offers"], "vendor");
+		Retract("BestOffer");
+}`},
+			}, nil).AnyTimes()
+
+			engine := NewEngineImpl[map[string]any](
+				config.DefaultConfig(), mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+				ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+			)
+			defer engine.Close()
+
+			result, err := engine.Exec(context.Background(), "offer", map[string]any{"offers": []any{
+				map[string]any{"id": "a", "score": 80, "price": 99, "vendor": "x"},
+				map[string]any{"id": "b", "score": 95, "price": 120, "vendor": "y"},
+				map[string]any{"id": "c", "score": 60, "price": 80, "vendor": "x"},
+			}})
+			So(err, ShouldBeNil)
+			So(result["best"].(map[string]any)["id"], ShouldEqual, "b")
+			So(ids(result["cheapest"].([]any)), ShouldResemble, []any{"c", "a", "b"})
+			So(result["byVendor"].(map[string]any)["x"], ShouldHaveLength, 2)
+		})
+	})
+}
@@ -0,0 +1,121 @@
+package engine
+
+import (
+	"fmt"
+	"reflect"
+
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/hyperjumptech/grule-rule-engine/model"
+)
+
+// ============================================================================
+// 共享事实 - 多条规则共用的函数调用按参数值缓存结果，每次执行只求值一次
+// ============================================================================
+//
+// 启用 Config.HoistCommonCalls 后，编译时由 rule.HoistCommonCalls 将至少两条规则条件中相同的调用
+// 改写为 Shared.Eval("名称", 参数...) 或 Shared.Method(接收者, "方法", 参数...)，执行时注入的共享事实对象首次调用时求值并缓存。
+// 缓存按调用名和参数值区分，只在参数均为字符串、数值或布尔值时缓存；被提取的函数在一次执行内应当是确定的。
+// 指针、结构体等参数和方法调用的接收者可能是规则会修改的事实对象，以其为参数的调用每次重新求值，
+// 方法调用只在接收者为字符串时缓存。
+
+// sharedFacts 单次执行的共享事实对象
+type sharedFacts struct {
+	data   ast.IDataContext
+	values map[string]interface{} // 调用名和参数值 -> 结果
+}
+
+// stringType 字符串类型
+var stringType = reflect.TypeOf("")
+
+// hoistCommonCalls 是否提取公共调用
+func (e *engineImpl[T]) hoistCommonCalls() bool {
+	return e.config != nil && e.config.HoistCommonCalls
+}
+
+// injectSharedFacts 注入共享事实对象
+func injectSharedFacts(dataCtx ast.IDataContext) {
+	dataCtx.Add(rule.SharedFactsObject, &sharedFacts{data: dataCtx, values: make(map[string]interface{})})
+}
+
+// Eval 调用内置函数，参数均为标量时缓存结果
+//
+// 调用出错时不缓存，错误按原调用的方式由Grule处理。
+func (s *sharedFacts) Eval(name string, args ...interface{}) interface{} {
+	cacheable := scalarArgs(args)
+	key := fmt.Sprintf("%s%#v", name, args)
+	if cacheable {
+		if value, ok := s.values[key]; ok {
+			return value
+		}
+	}
+
+	node := s.data.Get("DEFUNC")
+	if node == nil {
+		panic(fmt.Errorf("共享事实 %s: 数据上下文中没有 DEFUNC", name))
+	}
+	value := callNode(node, name, args)
+	if cacheable {
+		s.values[key] = value
+	}
+	return value
+}
+
+// Method 调用接收者的方法，接收者为字符串且参数均为标量时缓存结果
+func (s *sharedFacts) Method(receiver interface{}, name string, args ...interface{}) interface{} {
+	recv := reflect.ValueOf(receiver)
+	if !recv.IsValid() {
+		panic(fmt.Errorf("共享事实 %s: 接收者为nil", name))
+	}
+	cacheable := recv.Type() == stringType && scalarArgs(args)
+	key := fmt.Sprintf("%#v.%s%#v", receiver, name, args)
+	if cacheable {
+		if value, ok := s.values[key]; ok {
+			return value
+		}
+	}
+
+	value := callNode(model.NewGoValueNode(recv, "Shared"), name, args)
+	if cacheable {
+		s.values[key] = value
+	}
+	return value
+}
+
+// scalarArgs 参数是否均为nil、字符串、数值或布尔值 - 这些参数按值区分缓存，不会被规则修改
+func scalarArgs(args []interface{}) bool {
+	for _, arg := range args {
+		if arg == nil {
+			continue
+		}
+		switch reflect.TypeOf(arg).Kind() {
+		case reflect.String, reflect.Bool,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// callNode 按Grule的方式调用节点上的函数，出错时panic
+func callNode(node model.ValueNode, name string, args []interface{}) interface{} {
+	in := make([]reflect.Value, len(args))
+	for i, arg := range args {
+		if arg == nil {
+			in[i] = reflect.Zero(reflect.TypeOf((*interface{})(nil)).Elem())
+		} else {
+			in[i] = reflect.ValueOf(arg)
+		}
+	}
+	out, err := node.CallFunction(name, in...)
+	if err != nil {
+		panic(fmt.Errorf("共享事实 %s: %w", name, err))
+	}
+	if out.IsValid() && out.CanInterface() {
+		return out.Interface()
+	}
+	return nil
+}
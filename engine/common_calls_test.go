@@ -0,0 +1,108 @@
+package engine
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+// sharedOrder 共享事实测试用的可变事实
+type sharedOrder struct {
+	Amount int
+}
+
+// sharedProbe 共享事实测试用的函数集，记录调用次数
+type sharedProbe struct {
+	calls int
+}
+
+func (p *sharedProbe) Total(o *sharedOrder) int {
+	p.calls++
+	return o.Amount
+}
+
+// TestHoistCommonCalls 测试公共调用提取后的执行
+func TestHoistCommonCalls(t *testing.T) {
+	Convey("公共调用提取", t, func() {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		ctx := context.Background()
+
+		rules := []*rule.Rule{
+			{BizCode: "kyc", Name: "Similar", Enabled: true, Version: 1,
+				GRL: `rule Similar "相似" { when Levenshtein(Params["name"], "alice") <= 1 && Params["email"].MatchString("^[a-z]+@") then Result["similar"] = true; Retract("Similar"); }`},
+			{BizCode: "kyc", Name: "Distinct", Enabled: true, Version: 1,
+				GRL: `rule Distinct "不同" { when Levenshtein(Params["name"], "alice") > 1 && Params["email"].MatchString("^[a-z]+@") then Result["distinct"] = true; Retract("Distinct"); }`},
+		}
+		mapper := rule.NewMockRuleMapper(ctrl)
+		mapper.EXPECT().FindByBizCode(gomock.Any(), "kyc").Return(rules, nil).AnyTimes()
+
+		cfg := config.DefaultConfig()
+		cfg.HoistCommonCalls = true
+		engine := NewEngineImpl[map[string]any](
+			cfg, mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+			ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+		)
+		defer engine.Close()
+
+		Convey("提取后结果与原规则相同，编译信息记录提取的调用", func() {
+			result, err := engine.Exec(ctx, "kyc", map[string]any{"name": "alise", "email": "a@x.com"})
+			So(err, ShouldBeNil)
+			So(result["similar"], ShouldEqual, true)
+			So(result, ShouldNotContainKey, "distinct")
+
+			result, err = engine.Exec(ctx, "kyc", map[string]any{"name": "bob", "email": "b@x.com"})
+			So(err, ShouldBeNil)
+			So(result["distinct"], ShouldEqual, true)
+
+			info, _ := engine.compileInfos.Load("kyc")
+			shared := info.(CompileInfo).SharedCalls
+			So(shared, ShouldHaveLength, 2)
+			So(shared[0].Rules, ShouldResemble, []string{"Similar", "Distinct"})
+			So(rules[0].GRL, ShouldNotContainSubstring, rule.SharedFactsObject)
+		})
+
+		Convey("同一次执行内相同参数只求值一次", func() {
+			dataCtx := ast.NewDataContext()
+			So(dataCtx.Add("DEFUNC", &ruleFunctions{}), ShouldBeNil)
+			injectSharedFacts(dataCtx)
+			shared := dataCtx.Get(rule.SharedFactsObject)
+			So(shared, ShouldNotBeNil)
+			facts := shared.Value().Interface().(*sharedFacts)
+
+			So(facts.Eval("Levenshtein", "alice", "alise"), ShouldEqual, 1)
+			So(facts.Eval("Levenshtein", "alice", "alise"), ShouldEqual, 1)
+			So(facts.Method("a@x.com", "MatchString", "^[a-z]+@"), ShouldEqual, true)
+			So(facts.Method("a@x.com", "MatchString", "^[a-z]+@"), ShouldEqual, true)
+			So(facts.values, ShouldHaveLength, 2)
+
+			So(func() { facts.Eval("Missing", 1) }, ShouldPanic)
+			So(func() { facts.Method(nil, "MatchString", "a") }, ShouldPanic)
+			So(facts.values, ShouldHaveLength, 2)
+		})
+
+		Convey("参数为可变事实时每次重新求值", func() {
+			probe := &sharedProbe{}
+			dataCtx := ast.NewDataContext()
+			So(dataCtx.Add("DEFUNC", probe), ShouldBeNil)
+			injectSharedFacts(dataCtx)
+			facts := dataCtx.Get(rule.SharedFactsObject).Value().Interface().(*sharedFacts)
+
+			order := &sharedOrder{Amount: 100}
+			So(facts.Eval("Total", order), ShouldEqual, 100)
+			order.Amount = 200
+			So(facts.Eval("Total", order), ShouldEqual, 200)
+			So(probe.calls, ShouldEqual, 2)
+			So(facts.values, ShouldBeEmpty)
+		})
+	})
+}
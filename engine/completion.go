@@ -0,0 +1,242 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+)
+
+// ============================================================================
+// 自动补全元数据 - 为规则编辑器提供可用字段、函数和操作符
+// ============================================================================
+
+// ErrInputTypeNotRegistered 业务码未注册输入类型
+var ErrInputTypeNotRegistered = errors.New("业务码未注册输入类型")
+
+// completionMaxDepth 字段展开的最大嵌套深度
+const completionMaxDepth = 6
+
+// CompletionMetadata 自动补全元数据
+type CompletionMetadata struct {
+	BizCode   string         `json:"bizCode"`   // 业务码
+	Root      string         `json:"root"`      // 输入在规则中的变量名，如 Params
+	Fields    []FieldInfo    `json:"fields"`    // 可用字段路径
+	Functions []FunctionInfo `json:"functions"` // 内置函数
+	Operators []string       `json:"operators"` // 可用操作符
+}
+
+// FieldInfo 字段信息
+type FieldInfo struct {
+	Path string `json:"path"` // 相对Root的字段路径，切片元素以[]表示，如 items[].qty
+	Type string `json:"type"` // 字段类型，如 int、float64、time.Time、[]string
+}
+
+// FunctionInfo 函数信息
+type FunctionInfo struct {
+	Name      string `json:"name"`      // 函数名
+	Signature string `json:"signature"` // 函数签名，如 func(string, string) bool
+}
+
+// grlOperators GRL支持的操作符
+var grlOperators = []string{
+	"==", "!=", ">", "<", ">=", "<=",
+	"&&", "||", "!",
+	"+", "-", "*", "/", "%",
+}
+
+// Completions 获取业务码的自动补全元数据
+//
+// 输入类型通过 config.InputTypes 按业务码注册，支持:
+//   - 结构体或结构体指针：展开导出字段
+//   - map样例：按样例值展开嵌套键
+//   - map[string]string：字段路径到类型的声明
+//
+// 未注册时回退到 config.InputSchema，两者均为空返回 ErrInputTypeNotRegistered。
+func (e *engineImpl[T]) Completions(bizCode string) (*CompletionMetadata, error) {
+	sample, ok := e.config.InputTypes[bizCode]
+	if !ok {
+		if len(e.config.InputSchema) == 0 {
+			return nil, fmt.Errorf("%w: %s", ErrInputTypeNotRegistered, bizCode)
+		}
+		sample = e.config.InputSchema
+	}
+
+	root, fields := describeInput(sample)
+	return &CompletionMetadata{
+		BizCode:   bizCode,
+		Root:      root,
+		Fields:    fields,
+		Functions: e.builtinFunctionInfos(),
+		Operators: append([]string(nil), grlOperators...),
+	}, nil
+}
+
+// builtinFunctionInfos 收集Grule内置函数和引擎内置函数的签名
+func (e *engineImpl[T]) builtinFunctionInfos() []FunctionInfo {
+	var functions []FunctionInfo
+
+	builtinType := reflect.TypeOf(&ast.BuiltInFunctions{})
+	for i := 0; i < builtinType.NumMethod(); i++ {
+		method := builtinType.Method(i)
+		functions = append(functions, FunctionInfo{
+			Name:      method.Name,
+			Signature: methodSignature(method.Type),
+		})
+	}
+
+	dataCtx := ast.NewDataContext()
+	e.injectBuiltinFunctions(dataCtx)
+	for _, name := range dataCtx.GetKeys() {
+		value, err := dataCtx.Get(name).GetValue()
+		if err != nil || value.Kind() != reflect.Func {
+			continue
+		}
+		functions = append(functions, FunctionInfo{Name: name, Signature: value.Type().String()})
+	}
+
+	sort.Slice(functions, func(i, j int) bool { return functions[i].Name < functions[j].Name })
+	return functions
+}
+
+// methodSignature 去除接收者后的方法签名
+func methodSignature(t reflect.Type) string {
+	in := make([]string, 0, t.NumIn())
+	for i := 1; i < t.NumIn(); i++ {
+		in = append(in, t.In(i).String())
+	}
+	out := make([]string, 0, t.NumOut())
+	for i := 0; i < t.NumOut(); i++ {
+		out = append(out, t.Out(i).String())
+	}
+
+	signature := "func(" + strings.Join(in, ", ") + ")"
+	switch len(out) {
+	case 0:
+	case 1:
+		signature += " " + out[0]
+	default:
+		signature += " (" + strings.Join(out, ", ") + ")"
+	}
+	return signature
+}
+
+// describeInput 展开输入类型的字段路径 - 变量名规则与injectInputData一致
+func describeInput(sample any) (string, []FieldInfo) {
+	var fields []FieldInfo
+
+	switch s := sample.(type) {
+	case map[string]string:
+		for path, typ := range s {
+			fields = append(fields, FieldInfo{Path: path, Type: typ})
+		}
+	case map[string]any:
+		collectMapFields(&fields, "", s, 0)
+	default:
+		t := reflect.TypeOf(sample)
+		for t != nil && t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t == nil || t.Kind() != reflect.Struct {
+			return "Params", nil
+		}
+		collectStructFields(&fields, "", t, 0)
+		sortFields(fields)
+		if t.Name() != "" {
+			return strings.ToLower(t.Name()), fields
+		}
+		return "Params", fields
+	}
+
+	sortFields(fields)
+	return "Params", fields
+}
+
+// collectStructFields 递归收集结构体导出字段
+func collectStructFields(fields *[]FieldInfo, prefix string, t reflect.Type, depth int) {
+	if depth >= completionMaxDepth {
+		return
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		path := joinFieldPath(prefix, field.Name)
+		*fields = append(*fields, FieldInfo{Path: path, Type: field.Type.String()})
+		collectNestedType(fields, path, field.Type, depth+1)
+	}
+}
+
+// collectNestedType 展开嵌套结构体和切片元素
+func collectNestedType(fields *[]FieldInfo, path string, t reflect.Type, depth int) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		if t == reflect.TypeOf(time.Time{}) {
+			return
+		}
+		collectStructFields(fields, path, t, depth)
+	case reflect.Slice, reflect.Array:
+		elem := t.Elem()
+		for elem.Kind() == reflect.Ptr {
+			elem = elem.Elem()
+		}
+		if elem.Kind() == reflect.Struct && elem != reflect.TypeOf(time.Time{}) {
+			collectStructFields(fields, path+"[]", elem, depth)
+		}
+	}
+}
+
+// collectMapFields 按map样例值递归收集键路径
+func collectMapFields(fields *[]FieldInfo, prefix string, data map[string]any, depth int) {
+	if depth >= completionMaxDepth {
+		return
+	}
+	for key, value := range data {
+		path := joinFieldPath(prefix, key)
+		*fields = append(*fields, FieldInfo{Path: path, Type: sampleTypeName(value)})
+
+		switch v := value.(type) {
+		case map[string]any:
+			collectMapFields(fields, path, v, depth+1)
+		case []any:
+			if len(v) > 0 {
+				if elem, ok := v[0].(map[string]any); ok {
+					collectMapFields(fields, path+"[]", elem, depth+1)
+				}
+			}
+		default:
+			if value != nil {
+				collectNestedType(fields, path, reflect.TypeOf(value), depth+1)
+			}
+		}
+	}
+}
+
+// sampleTypeName 样例值的类型名
+func sampleTypeName(value any) string {
+	if value == nil {
+		return "any"
+	}
+	return reflect.TypeOf(value).String()
+}
+
+// joinFieldPath 拼接字段路径
+func joinFieldPath(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+// sortFields 按路径排序，保证输出稳定
+func sortFields(fields []FieldInfo) {
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Path < fields[j].Path })
+}
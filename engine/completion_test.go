@@ -0,0 +1,104 @@
+package engine
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type completionItem struct {
+	SKU string
+	Qty int
+}
+
+type completionInput struct {
+	Age       int
+	CreatedAt time.Time
+	Customer  *struct {
+		Level string
+	}
+	Items  []completionItem
+	secret string
+}
+
+// TestCompletions 测试自动补全元数据
+func TestCompletions(t *testing.T) {
+	Convey("自动补全元数据", t, func() {
+		cfg := config.DefaultConfig()
+		cfg.InputTypes = map[string]any{
+			"struct_biz": completionInput{},
+			"map_biz": map[string]any{
+				"age":      18,
+				"customer": map[string]any{"level": "gold"},
+				"items":    []any{map[string]any{"qty": 1}},
+			},
+			"schema_biz": map[string]string{"order.amount": "float"},
+		}
+		engine := NewEngineImpl[map[string]any](
+			cfg, nil, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+			ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+		)
+
+		Convey("结构体输入展开导出字段", func() {
+			meta, err := engine.Completions("struct_biz")
+			So(err, ShouldBeNil)
+			So(meta.Root, ShouldEqual, "completioninput")
+			So(meta.Fields, ShouldResemble, []FieldInfo{
+				{Path: "Age", Type: "int"},
+				{Path: "CreatedAt", Type: "time.Time"},
+				{Path: "Customer", Type: "*struct { Level string }"},
+				{Path: "Customer.Level", Type: "string"},
+				{Path: "Items", Type: "[]engine.completionItem"},
+				{Path: "Items[].Qty", Type: "int"},
+				{Path: "Items[].SKU", Type: "string"},
+			})
+		})
+
+		Convey("map样例按值展开", func() {
+			meta, err := engine.Completions("map_biz")
+			So(err, ShouldBeNil)
+			So(meta.Root, ShouldEqual, "Params")
+			So(meta.Fields, ShouldContain, FieldInfo{Path: "customer.level", Type: "string"})
+			So(meta.Fields, ShouldContain, FieldInfo{Path: "items[].qty", Type: "int"})
+		})
+
+		Convey("字段类型声明", func() {
+			meta, err := engine.Completions("schema_biz")
+			So(err, ShouldBeNil)
+			So(meta.Fields, ShouldResemble, []FieldInfo{{Path: "order.amount", Type: "float"}})
+		})
+
+		Convey("包含内置函数和操作符", func() {
+			meta, err := engine.Completions("struct_biz")
+			So(err, ShouldBeNil)
+			So(meta.Functions, ShouldContain, FunctionInfo{Name: "Contains", Signature: "func(string, string) bool"})
+			So(meta.Functions, ShouldContain, FunctionInfo{Name: "HasPath", Signature: "func(interface {}, string) bool"})
+			So(meta.Operators, ShouldContain, ">=")
+
+			names := make(map[string]bool)
+			for _, fn := range meta.Functions {
+				names[fn.Name] = true
+			}
+			So(names["Retract"], ShouldBeTrue) // Grule内置函数
+		})
+
+		Convey("未注册输入类型", func() {
+			_, err := engine.Completions("unknown_biz")
+			So(errors.Is(err, ErrInputTypeNotRegistered), ShouldBeTrue)
+
+			// 回退到全局输入类型声明
+			cfg.InputSchema = map[string]string{"age": "int"}
+			meta, err := engine.Completions("unknown_biz")
+			So(err, ShouldBeNil)
+			So(meta.Fields, ShouldResemble, []FieldInfo{{Path: "age", Type: "int"}})
+		})
+	})
+}
@@ -0,0 +1,101 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gitee.com/damengde/runehammer/config"
+	"gitee.com/damengde/runehammer/rule"
+)
+
+// ============================================================================
+// 成本预算 - 编译前静态估算规则集的单次执行成本，与业务码的延迟预算比较
+// ============================================================================
+
+// ErrCostBudgetExceeded 规则集预估成本超出业务码的延迟预算（CostCheckError模式），可通过errors.Is判断
+var ErrCostBudgetExceeded = errors.New("规则集预估成本超出延迟预算")
+
+// latencyBudget 获取业务码的延迟预算
+//
+// 优先使用 Config.BizLatencyBudgets 中业务码的配置，启用层级继承时依次查找父业务码，均未配置时使用 Config.LatencyBudget。
+func (e *engineImpl[T]) latencyBudget(bizCode string) time.Duration {
+	if e.config == nil {
+		return 0
+	}
+	if len(e.config.BizLatencyBudgets) > 0 {
+		chain := []string{bizCode}
+		if e.inheritanceEnabled() {
+			chain = bizCodeChain(bizCode)
+		}
+		for _, code := range chain {
+			if budget, ok := e.config.BizLatencyBudgets[code]; ok {
+				return budget
+			}
+		}
+	}
+	return e.config.LatencyBudget
+}
+
+// EstimateCost 估算业务码当前规则集的单次执行成本
+//
+// 启用层级继承时包含父业务码的规则；输入字段数取 Config.InputSchema 的字段数，未配置时按规则引用的输入字段计算。
+// 发布前检查候选规则集可直接使用 rule.EstimateCost。
+//
+// 参数:
+//
+//	ctx     - 上下文
+//	bizCode - 业务码
+//
+// 返回值:
+//
+//	*rule.CostEstimate - 成本估算
+//	error              - 获取规则失败
+func (e *engineImpl[T]) EstimateCost(ctx context.Context, bizCode string) (*rule.CostEstimate, error) {
+	rules, err := e.resolveRules(ctx, bizCode)
+	if err != nil {
+		return nil, err
+	}
+	if rules, err = e.hydrateRules(ctx, rules); err != nil {
+		return nil, err
+	}
+	estimate := rule.EstimateCost(rules, e.expectedFactSize())
+	return &estimate, nil
+}
+
+// expectedFactSize 预期输入字段数 - 取输入字段声明的数量
+func (e *engineImpl[T]) expectedFactSize() int {
+	if e.config == nil {
+		return 0
+	}
+	return len(e.config.InputSchema)
+}
+
+// checkCostBudget 检查规则集预估成本是否超出延迟预算 - 按 Config.CostCheck 告警或返回错误
+func (e *engineImpl[T]) checkCostBudget(bizCode string, rules []*rule.Rule) error {
+	if !e.costCheckEnabled(bizCode) {
+		return nil
+	}
+	budget := e.latencyBudget(bizCode)
+
+	estimate := rule.EstimateCost(rules, e.expectedFactSize())
+	if estimate.Estimate <= budget {
+		return nil
+	}
+
+	if e.config.CostCheck == config.CostCheckWarn {
+		e.logger.Warnf(context.Background(), "规则集预估成本超出延迟预算",
+			"bizCode", bizCode, "estimate", estimate.Estimate, "budget", budget, "tier", estimate.Tier)
+		return nil
+	}
+	return fmt.Errorf("%w: 业务码 %s 预估 %s（%s），预算 %s", ErrCostBudgetExceeded, bizCode, estimate.Estimate, estimate.Tier, budget)
+}
+
+// costCheckEnabled 是否检查业务码的成本预算 - 未关闭成本检查且配置了延迟预算
+func (e *engineImpl[T]) costCheckEnabled(bizCode string) bool {
+	if e.config == nil || e.config.CostCheck == config.CostCheckOff {
+		return false
+	}
+	return e.latencyBudget(bizCode) > 0
+}
@@ -0,0 +1,86 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+// TestCostBudget 测试规则集成本预算检查
+func TestCostBudget(t *testing.T) {
+	Convey("规则集成本预算检查", t, func() {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mapper := rule.NewMockRuleMapper(ctrl)
+		mapper.EXPECT().FindByBizCode(gomock.Any(), "risk.card").Return([]*rule.Rule{
+			{Name: "regex", Enabled: true, GRL: `rule Regex "正则" { when Matches(Params["email"], "^a") && Blacklist.Contains(Params["id"]) then Result["hit"] = true; Retract("Regex"); }`},
+		}, nil).AnyTimes()
+		mapper.EXPECT().FindByBizCode(gomock.Any(), "cheap").Return([]*rule.Rule{
+			{Name: "ok", Enabled: true, GRL: `rule Ok "通过" { when Params["amount"] > 0 then Result["ok"] = true; Retract("Ok"); }`},
+		}, nil).AnyTimes()
+
+		cfg := config.DefaultConfig()
+		cfg.LatencyBudget = 20 * time.Microsecond
+		cfg.BizLatencyBudgets = map[string]time.Duration{"risk": 5 * time.Microsecond}
+		engine := NewEngineImpl[map[string]any](
+			cfg, mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+			ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+		)
+		defer engine.Close()
+		ctx := context.Background()
+		input := map[string]any{"amount": 1}
+
+		Convey("按业务码和继承链解析预算", func() {
+			So(engine.latencyBudget("cheap"), ShouldEqual, 20*time.Microsecond)
+			So(engine.latencyBudget("risk"), ShouldEqual, 5*time.Microsecond)
+			So(engine.latencyBudget("risk.card"), ShouldEqual, 20*time.Microsecond)
+
+			cfg.BizCodeInheritance = true
+			So(engine.latencyBudget("risk.card"), ShouldEqual, 5*time.Microsecond)
+		})
+
+		Convey("估算业务码规则集成本", func() {
+			estimate, err := engine.EstimateCost(ctx, "risk.card")
+			So(err, ShouldBeNil)
+			So(estimate.Rules, ShouldHaveLength, 1)
+			So(estimate.Rules[0].Regex, ShouldEqual, 1)
+			So(estimate.Rules[0].Lookups, ShouldEqual, 1)
+			So(estimate.Tier, ShouldEqual, rule.CostTierMedium)
+		})
+
+		Convey("未启用检查时不拦截", func() {
+			_, err := engine.Exec(ctx, "cheap", input)
+			So(err, ShouldBeNil)
+			So(engine.checkCostBudget("risk.card", []*rule.Rule{{Name: "x", Enabled: true, GRL: `rule X "x" { when Matches(Params.a, "b") then Retract("X"); }`}}), ShouldBeNil)
+		})
+
+		Convey("告警模式只记录日志", func() {
+			cfg.CostCheck = config.CostCheckWarn
+			mapper.EXPECT().FindByBizCode(gomock.Any(), "risk").Return(nil, nil).AnyTimes()
+			cfg.BizCodeInheritance = true
+			_, err := engine.Exec(ctx, "risk.card", input)
+			So(err, ShouldBeNil)
+		})
+
+		Convey("错误模式超出预算时编译失败", func() {
+			cfg.CostCheck = config.CostCheckError
+			_, err := engine.Exec(ctx, "risk.card", input)
+			So(errors.Is(err, ErrCostBudgetExceeded), ShouldBeTrue)
+
+			_, err = engine.Exec(ctx, "cheap", input)
+			So(err, ShouldBeNil)
+		})
+	})
+}
@@ -0,0 +1,146 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// 货币换算 - 规则通过 ConvertCurrency 按汇率提供者换算金额，调用方无需预先换算
+// ============================================================================
+//
+// 汇率按 (from, to) 缓存 Config.RateCacheTTL（默认1分钟），过期后重新获取；获取失败时
+// 仍在时效内的缓存汇率继续使用。汇率时间（Rate.AsOf，为零时取获取时间）早于
+// Config.RateMaxAge 时视为过期，执行返回 ErrStaleRate。时效按引擎时钟（WithClock）计算。
+
+// DefaultRateCacheTTL 未配置时汇率的缓存时长
+const DefaultRateCacheTTL = time.Minute
+
+var (
+	// ErrNoRateProvider 规则调用 ConvertCurrency 但未设置汇率提供者
+	ErrNoRateProvider = errors.New("未设置汇率提供者")
+	// ErrStaleRate 汇率超过最大时效
+	ErrStaleRate = errors.New("汇率已过期")
+)
+
+// Rate 汇率 - 1单位源货币可兑换 Value 单位目标货币
+type Rate struct {
+	Value float64   // 汇率
+	AsOf  time.Time // 汇率时间，为零时以获取时间计算时效
+}
+
+// RateProvider 汇率提供者
+type RateProvider interface {
+	// Rate 返回 from -> to 的汇率，货币代码为大写ISO 4217代码，如 USD、CNY
+	Rate(ctx context.Context, from, to string) (Rate, error)
+}
+
+// RateFunc 函数形式的汇率提供者
+type RateFunc func(ctx context.Context, from, to string) (Rate, error)
+
+// Rate 实现RateProvider
+func (f RateFunc) Rate(ctx context.Context, from, to string) (Rate, error) {
+	return f(ctx, from, to)
+}
+
+// cachedRate 缓存的汇率
+type cachedRate struct {
+	rate      Rate
+	fetchedAt time.Time
+}
+
+// rateCache 汇率缓存 - 引擎内所有执行共享
+type rateCache struct {
+	provider RateProvider
+	ttl      time.Duration
+	maxAge   time.Duration
+
+	mu      sync.Mutex
+	entries map[string]cachedRate
+}
+
+// newRateCache 创建汇率缓存，ttl<=0时取默认值，maxAge<=0表示不检查时效
+func newRateCache(provider RateProvider, ttl, maxAge time.Duration) *rateCache {
+	if ttl <= 0 {
+		ttl = DefaultRateCacheTTL
+	}
+	return &rateCache{provider: provider, ttl: ttl, maxAge: maxAge, entries: make(map[string]cachedRate)}
+}
+
+// rate 获取汇率 - 缓存未过期时直接返回，否则向提供者获取，获取失败时退回仍在时效内的缓存
+func (c *rateCache) rate(ctx context.Context, now time.Time, from, to string) (float64, error) {
+	key := from + "/" + to
+	c.mu.Lock()
+	entry, cached := c.entries[key]
+	c.mu.Unlock()
+
+	if !cached || now.Sub(entry.fetchedAt) >= c.ttl {
+		rate, err := c.provider.Rate(ctx, from, to)
+		switch {
+		case err == nil && rate.Value > 0:
+			entry, cached = cachedRate{rate: rate, fetchedAt: now}, true
+			c.mu.Lock()
+			c.entries[key] = entry
+			c.mu.Unlock()
+		case !cached && err != nil:
+			return 0, fmt.Errorf("获取汇率 %s 失败: %w", key, err)
+		case !cached:
+			return 0, fmt.Errorf("汇率 %s 无效: %v", key, rate.Value)
+		}
+	}
+
+	asOf := entry.rate.AsOf
+	if asOf.IsZero() {
+		asOf = entry.fetchedAt
+	}
+	if c.maxAge > 0 && now.Sub(asOf) > c.maxAge {
+		return 0, fmt.Errorf("%w: %s 的汇率时间为 %s，超过最大时效 %s", ErrStaleRate, key, asOf.Format(time.RFC3339), c.maxAge)
+	}
+	return entry.rate.Value, nil
+}
+
+// convert 换算金额，货币代码不区分大小写，相同货币直接返回
+func (c *rateCache) convert(ctx context.Context, now time.Time, amount float64, from, to string) (float64, error) {
+	from, to = strings.ToUpper(strings.TrimSpace(from)), strings.ToUpper(strings.TrimSpace(to))
+	if from == to {
+		return amount, nil
+	}
+	if c == nil {
+		return 0, ErrNoRateProvider
+	}
+	rate, err := c.rate(ctx, now, from, to)
+	if err != nil {
+		return 0, err
+	}
+	return amount * rate, nil
+}
+
+// SetRateProvider 设置汇率提供者，为nil时规则中的 ConvertCurrency 只能换算相同货币
+func (e *engineImpl[T]) SetRateProvider(provider RateProvider) {
+	if provider == nil {
+		e.rates = nil
+		return
+	}
+	var ttl, maxAge time.Duration
+	if e.config != nil {
+		ttl, maxAge = e.config.RateCacheTTL, e.config.RateMaxAge
+	}
+	e.rates = newRateCache(provider, ttl, maxAge)
+}
+
+// ConvertCurrency 按汇率提供者换算金额，如 ConvertCurrency(Params["amount"], Params["currency"], "USD")
+func (f *ruleFunctions) ConvertCurrency(amount any, from, to any) float64 {
+	value, ok := toFloat(amount)
+	if !ok {
+		f.raise("ConvertCurrency", fmt.Errorf("金额必须是数值，实际为 %T", amount))
+	}
+	converted, err := f.rates.convert(f.ctx, clockOrSystem(f.clock).Now(), value, stringArg(from), stringArg(to))
+	if err != nil {
+		f.raise("ConvertCurrency", err)
+	}
+	return converted
+}
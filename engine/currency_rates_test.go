@@ -0,0 +1,122 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+// TestCurrencyConversion 测试货币换算
+func TestCurrencyConversion(t *testing.T) {
+	Convey("货币换算", t, func() {
+		start := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+		now := start
+		calls := 0
+		var failure error
+		provider := RateFunc(func(ctx context.Context, from, to string) (Rate, error) {
+			calls++
+			if failure != nil {
+				return Rate{}, failure
+			}
+			if from == "EUR" && to == "USD" {
+				return Rate{Value: 1.1, AsOf: start}, nil
+			}
+			return Rate{}, errors.New("unsupported pair")
+		})
+		ctx := context.Background()
+
+		Convey("缓存期内不重复获取", func() {
+			rates := newRateCache(provider, time.Minute, 0)
+			amount, err := rates.convert(ctx, now, 100, "eur", "USD")
+			So(err, ShouldBeNil)
+			So(amount, ShouldAlmostEqual, 110)
+
+			_, err = rates.convert(ctx, now.Add(30*time.Second), 1, "EUR", "USD")
+			So(err, ShouldBeNil)
+			So(calls, ShouldEqual, 1)
+
+			_, err = rates.convert(ctx, now.Add(2*time.Minute), 1, "EUR", "USD")
+			So(err, ShouldBeNil)
+			So(calls, ShouldEqual, 2)
+		})
+
+		Convey("相同货币无需提供者", func() {
+			var rates *rateCache
+			amount, err := rates.convert(ctx, now, 42, "cny", "CNY")
+			So(err, ShouldBeNil)
+			So(amount, ShouldEqual, 42)
+
+			_, err = rates.convert(ctx, now, 42, "CNY", "USD")
+			So(errors.Is(err, ErrNoRateProvider), ShouldBeTrue)
+		})
+
+		Convey("获取失败时使用时效内的缓存，超过最大时效报错", func() {
+			rates := newRateCache(provider, time.Minute, time.Hour)
+			_, err := rates.convert(ctx, now, 1, "EUR", "USD")
+			So(err, ShouldBeNil)
+
+			failure = errors.New("provider down")
+			amount, err := rates.convert(ctx, now.Add(30*time.Minute), 10, "EUR", "USD")
+			So(err, ShouldBeNil)
+			So(amount, ShouldAlmostEqual, 11)
+
+			_, err = rates.convert(ctx, now.Add(2*time.Hour), 10, "EUR", "USD")
+			So(errors.Is(err, ErrStaleRate), ShouldBeTrue)
+
+			_, err = rates.convert(ctx, now, 10, "GBP", "USD")
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "GBP/USD")
+		})
+
+		Convey("规则中使用", func() {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mapper := rule.NewMockRuleMapper(ctrl)
+			mapper.EXPECT().FindByBizCode(gomock.Any(), "limit").Return([]*rule.Rule{
+				{Name: "limit", Enabled: true, GRL: `rule Limit "跨币种限额" {
+	when ConvertCurrency(Params["amount"], Params["currency"], "USD") > 1000
+	then
+		Result["review"] = true;
+		Retract("Limit");
+}`},
+			}, nil).AnyTimes()
+
+			cfg := config.DefaultConfig()
+			cfg.RateMaxAge = time.Hour
+			engine := NewEngineImpl[map[string]any](
+				cfg, mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+				ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+			)
+			defer engine.Close()
+			engine.SetClock(ClockFunc(func() time.Time { return now }))
+
+			_, err := engine.Exec(ctx, "limit", map[string]any{"amount": 950, "currency": "EUR"})
+			So(errors.Is(err, ErrNoRateProvider), ShouldBeTrue)
+
+			engine.SetRateProvider(provider)
+			result, err := engine.Exec(ctx, "limit", map[string]any{"amount": 950, "currency": "EUR"})
+			So(err, ShouldBeNil)
+			So(result["review"], ShouldEqual, true)
+
+			result, err = engine.Exec(ctx, "limit", map[string]any{"amount": 950, "currency": "USD"})
+			So(err, ShouldBeNil)
+			So(result, ShouldNotContainKey, "review")
+
+			now = start.Add(3 * time.Hour)
+			_, err = engine.Exec(ctx, "limit", map[string]any{"amount": 950, "currency": "EUR"})
+			So(errors.Is(err, ErrStaleRate), ShouldBeTrue)
+		})
+	})
+}
@@ -0,0 +1,72 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gitee.com/damengde/runehammer/config"
+	"gitee.com/damengde/runehammer/rule"
+)
+
+// ============================================================================
+// 写入声明与数据流 - 规则声明写入的Result字段，编译时检查并构建数据流图
+// ============================================================================
+
+// ErrUndeclaredWrite 规则写入了未声明的Result字段，可通过errors.Is判断
+var ErrUndeclaredWrite = errors.New("规则写入未声明的结果字段")
+
+// checkWriteDeclarations 检查规则写入是否超出声明 - 按 Config.WriteCheck 告警或返回错误
+func (e *engineImpl[T]) checkWriteDeclarations(bizCode string, rules []*rule.Rule) error {
+	if e.config == nil || e.config.WriteCheck == config.WriteCheckOff {
+		return nil
+	}
+
+	var violations []string
+	for _, r := range rules {
+		if r == nil || !r.Enabled {
+			continue
+		}
+		undeclared := rule.UndeclaredWrites(r)
+		if len(undeclared) == 0 {
+			continue
+		}
+		if e.config.WriteCheck == config.WriteCheckWarn {
+			e.logger.Warnf(context.Background(), "规则写入未声明的结果字段",
+				"bizCode", bizCode, "rule", r.Name, "fields", undeclared, "declared", r.Writes)
+			continue
+		}
+		violations = append(violations, fmt.Sprintf("%s(%s)", r.Name, strings.Join(undeclared, ", ")))
+	}
+
+	if len(violations) > 0 {
+		return fmt.Errorf("%w: %s", ErrUndeclaredWrite, strings.Join(violations, "; "))
+	}
+	return nil
+}
+
+// DataFlow 获取业务码规则集的数据流图
+//
+// 节点为启用的规则，边表示一条规则写入的Result字段被另一条规则读取，
+// 规则声明了 Writes 时以声明为准，未声明时按GRL分析，可通过 DOT() 输出用于可视化。
+//
+// 参数:
+//
+//	ctx     - 上下文
+//	bizCode - 业务码
+//
+// 返回值:
+//
+//	*rule.DataFlowGraph - 数据流图
+//	error               - 获取规则失败
+func (e *engineImpl[T]) DataFlow(ctx context.Context, bizCode string) (*rule.DataFlowGraph, error) {
+	rules, err := e.getRules(ctx, bizCode)
+	if err != nil {
+		return nil, err
+	}
+	if rules, err = e.hydrateRules(ctx, rules); err != nil {
+		return nil, err
+	}
+	return rule.AnalyzeDataFlow(rules), nil
+}
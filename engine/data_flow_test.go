@@ -0,0 +1,73 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+// TestWriteDeclarations 测试写入声明检查与数据流图
+func TestWriteDeclarations(t *testing.T) {
+	Convey("写入声明检查与数据流图", t, func() {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mapper := rule.NewMockRuleMapper(ctrl)
+		mapper.EXPECT().FindByBizCode(gomock.Any(), "loan").Return([]*rule.Rule{
+			{Name: "score", Enabled: true, Writes: []string{"score"},
+				GRL: `rule Score "评分" salience 10 { when true then Result["score"] = 700; Retract("Score"); }`},
+			{Name: "approve", Enabled: true, Writes: []string{"approved"},
+				GRL: `rule Approve "审批" { when Result["score"] >= 600 then Result["approved"] = true; Result["limit"] = 5000; Retract("Approve"); }`},
+		}, nil).AnyTimes()
+
+		newEngine := func(mode config.WriteCheckMode) *engineImpl[map[string]any] {
+			cfg := config.DefaultConfig()
+			cfg.WriteCheck = mode
+			return NewEngineImpl[map[string]any](
+				cfg, mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+				ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+			)
+		}
+		ctx := context.Background()
+
+		Convey("error模式下写入未声明字段时编译失败", func() {
+			engine := newEngine(config.WriteCheckError)
+			defer engine.Close()
+
+			_, err := engine.Exec(ctx, "loan", map[string]any{})
+			So(errors.Is(err, ErrUndeclaredWrite), ShouldBeTrue)
+			So(err.Error(), ShouldContainSubstring, "approve(limit)")
+		})
+
+		Convey("warn模式和未启用时正常执行", func() {
+			for _, mode := range []config.WriteCheckMode{config.WriteCheckWarn, config.WriteCheckOff} {
+				engine := newEngine(mode)
+				result, err := engine.Exec(ctx, "loan", map[string]any{})
+				So(err, ShouldBeNil)
+				So(result["limit"], ShouldEqual, 5000)
+				engine.Close()
+			}
+		})
+
+		Convey("获取数据流图", func() {
+			engine := newEngine(config.WriteCheckOff)
+			defer engine.Close()
+
+			graph, err := engine.DataFlow(ctx, "loan")
+			So(err, ShouldBeNil)
+			So(graph.Nodes, ShouldHaveLength, 2)
+			So(graph.Nodes[1].Undeclared, ShouldResemble, []string{"limit"})
+			So(graph.Edges, ShouldResemble, []rule.DataFlowEdge{{From: "score", To: "approve", Field: "score"}})
+		})
+	})
+}
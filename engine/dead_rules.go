@@ -0,0 +1,211 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+)
+
+// ============================================================================
+// 失效规则检测 - 按规则记录命中情况，定期找出检测窗口内从未命中的规则，便于清理过时逻辑
+// ============================================================================
+
+// 默认检测间隔
+const defaultDeadRuleInterval = time.Hour
+
+// DeadRule 检测窗口内未命中的规则
+type DeadRule struct {
+	Name      string    `json:"name"`       // GRL规则名
+	Since     time.Time `json:"since"`      // 规则开始被观察的时间（首次出现在已执行的规则集中）
+	LastFired time.Time `json:"last_fired"` // 最近一次命中时间，从未命中时为零值
+	Fires     int64     `json:"fires"`      // 观察期间累计命中次数
+}
+
+// DeadRuleReport 业务码失效规则报告
+type DeadRuleReport struct {
+	BizCode     string        `json:"biz_code"`     // 业务码
+	Window      time.Duration `json:"window"`       // 检测窗口
+	GeneratedAt time.Time     `json:"generated_at"` // 报告生成时间
+	Executions  int64         `json:"executions"`   // 观察期间的执行次数
+	Rules       []DeadRule    `json:"rules"`        // 未命中的规则，按规则名排序
+}
+
+// DeadRuleReporter 失效规则报告回调 - 按检测间隔接收存在失效规则的业务码报告
+type DeadRuleReporter interface {
+	ReportDeadRules(ctx context.Context, reports []DeadRuleReport) error
+}
+
+// DeadRuleReporterFunc 函数形式的失效规则报告回调
+type DeadRuleReporterFunc func(ctx context.Context, reports []DeadRuleReport) error
+
+// ReportDeadRules 实现DeadRuleReporter
+func (f DeadRuleReporterFunc) ReportDeadRules(ctx context.Context, reports []DeadRuleReport) error {
+	return f(ctx, reports)
+}
+
+// ruleActivity 单个业务码的规则命中记录
+type ruleActivity struct {
+	mu         sync.Mutex
+	kb         *ast.KnowledgeBase   // 最近观察的知识库，变化时刷新规则列表
+	rules      map[string]*DeadRule // 规则名 -> 命中记录，只包含当前知识库中的规则
+	executions int64                // 执行次数
+}
+
+// deadRulesEnabled 是否启用失效规则检测
+func (e *engineImpl[T]) deadRulesEnabled() bool {
+	return e.config != nil && e.config.DeadRuleWindow > 0
+}
+
+// SetDeadRuleReporter 设置失效规则报告回调，为nil时以警告日志输出
+func (e *engineImpl[T]) SetDeadRuleReporter(reporter DeadRuleReporter) {
+	e.deadRuleReporter = reporter
+}
+
+// recordRuleActivity 记录一次执行中命中的规则，知识库变化时同步规则列表（保留仍存在规则的记录）
+func (e *engineImpl[T]) recordRuleActivity(bizCode string, kb *ast.KnowledgeBase, fired []string) {
+	if !e.deadRulesEnabled() || kb == nil {
+		return
+	}
+	value, _ := e.ruleActivity.LoadOrStore(bizCode, &ruleActivity{})
+	activity := value.(*ruleActivity)
+
+	now := time.Now()
+	activity.mu.Lock()
+	defer activity.mu.Unlock()
+
+	if activity.kb != kb {
+		rules := make(map[string]*DeadRule, len(kb.RuleEntries))
+		for name, entry := range kb.RuleEntries {
+			if entry.Deleted {
+				continue // 增量编译移除的规则条目
+			}
+			if record, ok := activity.rules[name]; ok {
+				rules[name] = record
+			} else {
+				rules[name] = &DeadRule{Name: name, Since: now}
+			}
+		}
+		activity.kb = kb
+		activity.rules = rules
+	}
+
+	activity.executions++
+	for _, name := range fired {
+		if record, ok := activity.rules[name]; ok {
+			record.LastFired = now
+			record.Fires++
+		}
+	}
+}
+
+// DeadRules 获取业务码的失效规则报告
+//
+// 观察时长达到检测窗口、且窗口内没有命中的规则视为失效，新发布的规则在观察满一个窗口前不会被报告。
+//
+// 返回值:
+//
+//	*DeadRuleReport - 失效规则报告，未启用检测或业务码尚无执行记录时为nil
+func (e *engineImpl[T]) DeadRules(bizCode string) *DeadRuleReport {
+	if !e.deadRulesEnabled() {
+		return nil
+	}
+	return e.deadRules(bizCode, time.Now())
+}
+
+// deadRules 按指定时间生成失效规则报告
+func (e *engineImpl[T]) deadRules(bizCode string, now time.Time) *DeadRuleReport {
+	value, ok := e.ruleActivity.Load(bizCode)
+	if !ok {
+		return nil
+	}
+	activity := value.(*ruleActivity)
+	window := e.config.DeadRuleWindow
+	cutoff := now.Add(-window)
+
+	activity.mu.Lock()
+	defer activity.mu.Unlock()
+
+	report := &DeadRuleReport{
+		BizCode:     bizCode,
+		Window:      window,
+		GeneratedAt: now,
+		Executions:  activity.executions,
+		Rules:       []DeadRule{},
+	}
+	for _, record := range activity.rules {
+		if record.Since.After(cutoff) || record.LastFired.After(cutoff) {
+			continue
+		}
+		report.Rules = append(report.Rules, *record)
+	}
+	sort.Slice(report.Rules, func(i, j int) bool { return report.Rules[i].Name < report.Rules[j].Name })
+	return report
+}
+
+// allDeadRules 所有存在失效规则的业务码报告，按业务码排序
+func (e *engineImpl[T]) allDeadRules(now time.Time) []DeadRuleReport {
+	var bizCodes []string
+	e.ruleActivity.Range(func(key, value interface{}) bool {
+		bizCodes = append(bizCodes, key.(string))
+		return true
+	})
+	sort.Strings(bizCodes)
+
+	var reports []DeadRuleReport
+	for _, bizCode := range bizCodes {
+		if report := e.deadRules(bizCode, now); report != nil && len(report.Rules) > 0 {
+			reports = append(reports, *report)
+		}
+	}
+	return reports
+}
+
+// reportDeadRules 检测并报告所有业务码的失效规则
+func (e *engineImpl[T]) reportDeadRules(ctx context.Context, now time.Time) error {
+	reports := e.allDeadRules(now)
+	if len(reports) == 0 {
+		return nil
+	}
+
+	if e.deadRuleReporter != nil {
+		return e.deadRuleReporter.ReportDeadRules(ctx, reports)
+	}
+	if e.logger != nil {
+		for _, report := range reports {
+			names := make([]string, 0, len(report.Rules))
+			for _, r := range report.Rules {
+				names = append(names, r.Name)
+			}
+			e.logger.Warnf(ctx, "检测到失效规则", "bizCode", report.BizCode, "window", report.Window,
+				"executions", report.Executions, "rules", names)
+		}
+	}
+	return nil
+}
+
+// StartDeadRuleDetection 启动失效规则定期检测 - 未配置检测窗口时不启动，检测间隔<=0时取1小时
+func (e *engineImpl[T]) StartDeadRuleDetection() error {
+	if !e.deadRulesEnabled() {
+		return nil
+	}
+	interval := e.config.DeadRuleCheckInterval
+	if interval <= 0 {
+		interval = defaultDeadRuleInterval
+	}
+
+	_, err := e.scheduler().AddFunc(fmt.Sprintf("@every %s", interval), func() {
+		if err := e.reportDeadRules(context.Background(), time.Now()); err != nil && e.logger != nil {
+			e.logger.Warnf(context.Background(), "失效规则报告失败", "error", err)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("添加失效规则检测任务失败: %w", err)
+	}
+	e.RegisterDiagnostics("dead_rules", func() any { return e.allDeadRules(time.Now()) })
+	e.scheduler().Start()
+	return nil
+}
@@ -0,0 +1,93 @@
+package engine
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+// TestDeadRules 测试失效规则检测
+func TestDeadRules(t *testing.T) {
+	Convey("失效规则检测", t, func() {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		cfg := config.DefaultConfig()
+		cfg.DeadRuleWindow = time.Hour
+		mapper := rule.NewMockRuleMapper(ctrl)
+		engine := NewEngineImpl[map[string]any](
+			cfg, mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+			ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+		)
+		defer engine.Close()
+
+		rules := []*rule.Rule{
+			{BizCode: "loan", Name: "vip", Enabled: true, GRL: `rule vip "VIP" { when Params["vip"] == true then Result["vip"] = true; Retract("vip"); }`},
+			{BizCode: "loan", Name: "legacy", Enabled: true, GRL: `rule legacy "旧规则" { when Params["channel"] == "fax" then Result["fax"] = true; Retract("legacy"); }`},
+		}
+		mapper.EXPECT().FindByBizCode(gomock.Any(), "loan").Return(rules, nil).AnyTimes()
+
+		_, err := engine.Exec(context.Background(), "loan", map[string]any{"vip": true, "channel": "web"})
+		So(err, ShouldBeNil)
+		_, err = engine.Exec(context.Background(), "loan", map[string]any{"vip": false, "channel": "web"})
+		So(err, ShouldBeNil)
+
+		Convey("观察未满窗口的规则不报告", func() {
+			report := engine.DeadRules("loan")
+			So(report, ShouldNotBeNil)
+			So(report.Executions, ShouldEqual, 2)
+			So(report.Rules, ShouldBeEmpty)
+			So(engine.DeadRules("unknown"), ShouldBeNil)
+		})
+
+		Convey("窗口内未命中的规则视为失效", func() {
+			report := engine.deadRules("loan", time.Now().Add(2*time.Hour))
+			So(report.Rules, ShouldHaveLength, 2)
+			So(report.Rules[0].Name, ShouldEqual, "legacy")
+			So(report.Rules[0].LastFired.IsZero(), ShouldBeTrue)
+			So(report.Rules[1].Name, ShouldEqual, "vip")
+			So(report.Rules[1].Fires, ShouldEqual, 1)
+
+			report = engine.deadRules("loan", time.Now().Add(30*time.Minute))
+			So(report.Rules, ShouldBeEmpty)
+		})
+
+		Convey("按检测结果调用报告回调", func() {
+			var received []DeadRuleReport
+			engine.SetDeadRuleReporter(DeadRuleReporterFunc(func(ctx context.Context, reports []DeadRuleReport) error {
+				received = reports
+				return nil
+			}))
+			So(engine.reportDeadRules(context.Background(), time.Now()), ShouldBeNil)
+			So(received, ShouldBeNil)
+
+			So(engine.reportDeadRules(context.Background(), time.Now().Add(2*time.Hour)), ShouldBeNil)
+			So(received, ShouldHaveLength, 1)
+			So(received[0].BizCode, ShouldEqual, "loan")
+			So(received[0].Window, ShouldEqual, time.Hour)
+		})
+
+		Convey("启动定时检测并注册诊断项", func() {
+			cfg.DeadRuleCheckInterval = time.Minute
+			So(engine.StartDeadRuleDetection(), ShouldBeNil)
+			So(engine.diagnosticsSources, ShouldContainKey, "dead_rules")
+		})
+
+		Convey("未配置检测窗口时不记录", func() {
+			cfg.DeadRuleWindow = 0
+			So(engine.DeadRules("loan"), ShouldBeNil)
+			So(engine.StartDeadRuleDetection(), ShouldBeNil)
+			So(engine.diagnosticsSources, ShouldNotContainKey, "dead_rules")
+		})
+	})
+}
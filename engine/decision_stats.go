@@ -0,0 +1,328 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+)
+
+// ============================================================================
+// 决策分布统计 - 按业务码在内存中累计结果字段的取值分布，用于规则变更后的漂移检测
+// ============================================================================
+
+// DecisionStatsMissing 结果中缺少统计字段时计入的取值
+const DecisionStatsMissing = "<missing>"
+
+// DecisionStats 业务码决策分布
+type DecisionStats struct {
+	BizCode        string                       // 业务码
+	RuleSetVersion int                          // 统计期间的规则集版本（规则最大版本号）
+	Since          time.Time                    // 统计开始时间
+	Samples        int64                        // 采样的执行次数
+	Fields         map[string]FieldDistribution // 结果字段路径 -> 取值分布
+	RuleFires      map[string]int64             // GRL规则名 -> 命中（执行了动作）的执行次数
+	Previous       *DecisionStats               // 上一规则集版本的分布，规则变更后用于对比
+}
+
+// FieldDistribution 结果字段取值分布
+type FieldDistribution struct {
+	Values  map[string]int64 // 非数值取值计数，如 approved: true/false、level: A/B
+	Buckets []BucketCount    // 数值分桶计数，按上界升序，最后一个桶上界为+Inf
+	Count   int64            // 数值取值个数
+	Sum     float64          // 数值之和
+	Min     float64          // 最小数值
+	Max     float64          // 最大数值
+}
+
+// BucketCount 数值分桶 - 统计 (上一桶上界, UpperBound] 区间内的取值个数
+type BucketCount struct {
+	UpperBound float64
+	Count      int64
+}
+
+// DecisionStatsExporter 决策分布导出器 - 按导出间隔接收所有业务码的分布快照
+type DecisionStatsExporter interface {
+	ExportDecisionStats(ctx context.Context, stats []DecisionStats) error
+}
+
+// DecisionStatsExporterFunc 函数形式的决策分布导出器
+type DecisionStatsExporterFunc func(ctx context.Context, stats []DecisionStats) error
+
+// ExportDecisionStats 实现DecisionStatsExporter
+func (f DecisionStatsExporterFunc) ExportDecisionStats(ctx context.Context, stats []DecisionStats) error {
+	return f(ctx, stats)
+}
+
+// decisionRecorder 单个业务码的分布累计器
+type decisionRecorder struct {
+	mu       sync.Mutex
+	current  *DecisionStats
+	previous *DecisionStats
+	alerted  map[string]bool // 当前版本已告警的指标，每个指标每个版本只告警一次
+}
+
+// fireRecorder 记录单次执行中命中的规则
+type fireRecorder struct {
+	fired []string
+}
+
+// EvaluateRuleEntry 实现GruleEngineListener
+func (f *fireRecorder) EvaluateRuleEntry(cycle uint64, entry *ast.RuleEntry, candidate bool) {}
+
+// ExecuteRuleEntry 实现GruleEngineListener
+func (f *fireRecorder) ExecuteRuleEntry(cycle uint64, entry *ast.RuleEntry) {
+	for _, name := range f.fired {
+		if name == entry.RuleName {
+			return
+		}
+	}
+	f.fired = append(f.fired, entry.RuleName)
+}
+
+// BeginCycle 实现GruleEngineListener
+func (f *fireRecorder) BeginCycle(cycle uint64) {}
+
+// decisionStatsEnabled 是否启用决策分布统计 - 配置统计字段或异常告警时启用
+func (e *engineImpl[T]) decisionStatsEnabled() bool {
+	return e.config != nil && (len(e.config.DecisionStatsFields) > 0 || e.config.AnomalyThreshold > 0)
+}
+
+// SetDecisionStatsExporter 设置决策分布导出器，为nil时导出到日志
+func (e *engineImpl[T]) SetDecisionStatsExporter(exporter DecisionStatsExporter) {
+	e.decisionExporter = exporter
+}
+
+// recordDecision 按采样率记录一次执行结果及命中的规则，并检查分布异常
+func (e *engineImpl[T]) recordDecision(ctx context.Context, bizCode string, version int, result map[string]interface{}, fired []string) {
+	if !e.decisionStatsEnabled() {
+		return
+	}
+	if rate := e.config.DecisionStatsSampleRate; rate > 0 && rate < 1 && rand.Float64() >= rate {
+		return
+	}
+
+	value, _ := e.decisionStats.LoadOrStore(bizCode, &decisionRecorder{})
+	recorder := value.(*decisionRecorder)
+
+	recorder.mu.Lock()
+	e.accumulate(recorder, bizCode, version, result, fired)
+	alerts := e.detectAnomalies(recorder)
+	recorder.mu.Unlock()
+
+	e.notifyAnomalies(ctx, alerts)
+}
+
+// accumulate 累计一次执行，需持有recorder锁
+func (e *engineImpl[T]) accumulate(recorder *decisionRecorder, bizCode string, version int, result map[string]interface{}, fired []string) {
+	if recorder.current == nil || recorder.current.RuleSetVersion != version {
+		// 规则集版本变化时开始新的分布，保留上一版本用于对比
+		if recorder.current != nil {
+			recorder.previous = recorder.current
+		}
+		recorder.current = &DecisionStats{
+			BizCode:        bizCode,
+			RuleSetVersion: version,
+			Since:          time.Now(),
+			Fields:         make(map[string]FieldDistribution),
+			RuleFires:      make(map[string]int64),
+		}
+		recorder.alerted = nil
+	}
+
+	stats := recorder.current
+	stats.Samples++
+	for _, name := range fired {
+		stats.RuleFires[name]++
+	}
+	for _, field := range e.config.DecisionStatsFields {
+		dist := stats.Fields[field]
+		value, ok := lookupPath(result, field)
+		if !ok || value == nil {
+			dist.addValue(DecisionStatsMissing)
+		} else if number, ok := toFloat(value); ok {
+			dist.addNumber(number, e.config.DecisionStatsBuckets)
+		} else {
+			dist.addValue(fmt.Sprint(value))
+		}
+		stats.Fields[field] = dist
+	}
+}
+
+// addValue 累计非数值取值
+func (d *FieldDistribution) addValue(value string) {
+	if d.Values == nil {
+		d.Values = make(map[string]int64)
+	}
+	d.Values[value]++
+}
+
+// addNumber 累计数值取值
+func (d *FieldDistribution) addNumber(value float64, bounds []float64) {
+	if d.Count == 0 || value < d.Min {
+		d.Min = value
+	}
+	if d.Count == 0 || value > d.Max {
+		d.Max = value
+	}
+	d.Count++
+	d.Sum += value
+
+	if len(bounds) == 0 {
+		return
+	}
+	if d.Buckets == nil {
+		sorted := append([]float64{}, bounds...)
+		sort.Float64s(sorted)
+		d.Buckets = make([]BucketCount, 0, len(sorted)+1)
+		for _, bound := range sorted {
+			d.Buckets = append(d.Buckets, BucketCount{UpperBound: bound})
+		}
+		d.Buckets = append(d.Buckets, BucketCount{UpperBound: math.Inf(1)})
+	}
+	for i := range d.Buckets {
+		if value <= d.Buckets[i].UpperBound {
+			d.Buckets[i].Count++
+			return
+		}
+	}
+}
+
+// toFloat 数值类型转换为float64
+func toFloat(value any) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+// copy 深拷贝分布快照
+func (s *DecisionStats) copy() *DecisionStats {
+	if s == nil {
+		return nil
+	}
+	out := *s
+	out.Previous = nil
+	out.RuleFires = make(map[string]int64, len(s.RuleFires))
+	for name, count := range s.RuleFires {
+		out.RuleFires[name] = count
+	}
+	out.Fields = make(map[string]FieldDistribution, len(s.Fields))
+	for field, dist := range s.Fields {
+		if dist.Values != nil {
+			values := make(map[string]int64, len(dist.Values))
+			for k, v := range dist.Values {
+				values[k] = v
+			}
+			dist.Values = values
+		}
+		dist.Buckets = append([]BucketCount(nil), dist.Buckets...)
+		out.Fields[field] = dist
+	}
+	return &out
+}
+
+// DecisionStats 获取业务码的决策分布快照
+//
+// 返回值:
+//
+//	*DecisionStats - 当前规则集版本的分布（Previous为上一版本），未启用或尚无记录时为nil
+func (e *engineImpl[T]) DecisionStats(bizCode string) *DecisionStats {
+	value, ok := e.decisionStats.Load(bizCode)
+	if !ok {
+		return nil
+	}
+	recorder := value.(*decisionRecorder)
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	stats := recorder.current.copy()
+	if stats != nil {
+		stats.Previous = recorder.previous.copy()
+	}
+	return stats
+}
+
+// allDecisionStats 所有业务码的分布快照，按业务码排序
+func (e *engineImpl[T]) allDecisionStats() []DecisionStats {
+	var bizCodes []string
+	e.decisionStats.Range(func(key, value interface{}) bool {
+		bizCodes = append(bizCodes, key.(string))
+		return true
+	})
+	sort.Strings(bizCodes)
+
+	stats := make([]DecisionStats, 0, len(bizCodes))
+	for _, bizCode := range bizCodes {
+		if s := e.DecisionStats(bizCode); s != nil {
+			stats = append(stats, *s)
+		}
+	}
+	return stats
+}
+
+// exportDecisionStats 导出所有业务码的分布快照
+func (e *engineImpl[T]) exportDecisionStats(ctx context.Context) error {
+	stats := e.allDecisionStats()
+	if len(stats) == 0 {
+		return nil
+	}
+
+	if e.decisionExporter != nil {
+		return e.decisionExporter.ExportDecisionStats(ctx, stats)
+	}
+	if e.logger != nil {
+		for _, s := range stats {
+			e.logger.Infof(ctx, "决策分布", "bizCode", s.BizCode, "version", s.RuleSetVersion,
+				"samples", s.Samples, "fields", s.Fields)
+		}
+	}
+	return nil
+}
+
+// StartDecisionStatsExport 启动决策分布定期导出 - 未启用统计或导出间隔<=0时不启动
+func (e *engineImpl[T]) StartDecisionStatsExport() error {
+	if !e.decisionStatsEnabled() || e.config.DecisionStatsExportInterval <= 0 {
+		return nil
+	}
+
+	_, err := e.scheduler().AddFunc(fmt.Sprintf("@every %s", e.config.DecisionStatsExportInterval), func() {
+		if err := e.exportDecisionStats(context.Background()); err != nil && e.logger != nil {
+			e.logger.Warnf(context.Background(), "决策分布导出失败", "error", err)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("添加决策分布导出任务失败: %w", err)
+	}
+	e.scheduler().Start()
+	return nil
+}
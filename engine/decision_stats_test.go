@@ -0,0 +1,158 @@
+package engine
+
+import (
+	"context"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+// TestDecisionStats 测试决策分布统计
+func TestDecisionStats(t *testing.T) {
+	Convey("决策分布统计", t, func() {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		version := 1
+		mapper := rule.NewMockRuleMapper(ctrl)
+		mapper.EXPECT().FindByBizCode(gomock.Any(), "loan").DoAndReturn(func(ctx context.Context, bizCode string) ([]*rule.Rule, error) {
+			return []*rule.Rule{{
+				Name:    "score",
+				Version: version,
+				GRL: `rule Score "评分" {
+	when true
+	then
+		Result["score"] = Params["score"];
+		Result["approved"] = Params["score"] >= 600;
+		Retract("Score");
+}`,
+				Enabled: true,
+			}}, nil
+		}).AnyTimes()
+
+		cfg := config.DefaultConfig()
+		cfg.DecisionStatsFields = []string{"approved", "score", "level"}
+		cfg.DecisionStatsBuckets = []float64{600, 300}
+		engine := NewEngineImpl[map[string]any](
+			cfg, mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+			ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+		)
+		defer engine.Close()
+		ctx := context.Background()
+
+		exec := func(scores ...int) {
+			for _, score := range scores {
+				_, err := engine.Exec(ctx, "loan", map[string]any{"score": score})
+				So(err, ShouldBeNil)
+			}
+		}
+
+		Convey("未执行时返回nil", func() {
+			So(engine.DecisionStats("loan"), ShouldBeNil)
+		})
+
+		Convey("按取值计数并对数值分桶", func() {
+			exec(200, 450, 650, 700)
+
+			stats := engine.DecisionStats("loan")
+			So(stats, ShouldNotBeNil)
+			So(stats.BizCode, ShouldEqual, "loan")
+			So(stats.RuleSetVersion, ShouldEqual, 1)
+			So(stats.Samples, ShouldEqual, 4)
+			So(stats.Previous, ShouldBeNil)
+
+			So(stats.Fields["approved"].Values, ShouldResemble, map[string]int64{"true": 2, "false": 2})
+			So(stats.Fields["level"].Values, ShouldResemble, map[string]int64{DecisionStatsMissing: 4})
+
+			score := stats.Fields["score"]
+			So(score.Count, ShouldEqual, 4)
+			So(score.Sum, ShouldEqual, 2000)
+			So(score.Min, ShouldEqual, 200)
+			So(score.Max, ShouldEqual, 700)
+			So(score.Buckets, ShouldResemble, []BucketCount{
+				{UpperBound: 300, Count: 1},
+				{UpperBound: 600, Count: 1},
+				{UpperBound: math.Inf(1), Count: 2},
+			})
+		})
+
+		Convey("返回快照而非内部状态", func() {
+			exec(700)
+			stats := engine.DecisionStats("loan")
+			stats.Fields["approved"].Values["true"] = 100
+
+			So(engine.DecisionStats("loan").Fields["approved"].Values["true"], ShouldEqual, 1)
+		})
+
+		Convey("规则集版本变化时保留上一版本分布", func() {
+			exec(700, 700)
+
+			version = 2
+			So(engine.refreshCache("loan"), ShouldBeNil)
+			exec(100)
+
+			stats := engine.DecisionStats("loan")
+			So(stats.RuleSetVersion, ShouldEqual, 2)
+			So(stats.Samples, ShouldEqual, 1)
+			So(stats.Fields["approved"].Values, ShouldResemble, map[string]int64{"false": 1})
+			So(stats.Previous, ShouldNotBeNil)
+			So(stats.Previous.RuleSetVersion, ShouldEqual, 1)
+			So(stats.Previous.Fields["approved"].Values, ShouldResemble, map[string]int64{"true": 2})
+		})
+
+		Convey("按采样率记录", func() {
+			cfg.DecisionStatsSampleRate = 0.2
+			for i := 0; i < 500; i++ {
+				engine.recordDecision(ctx, "sampled", 1, map[string]interface{}{"approved": true}, nil)
+			}
+			So(engine.DecisionStats("sampled").Samples, ShouldBeBetween, 50, 150)
+		})
+
+		Convey("导出所有业务码的分布", func() {
+			exec(700)
+			engine.recordDecision(ctx, "card", 1, map[string]interface{}{"approved": false}, nil)
+
+			var exported []DecisionStats
+			engine.SetDecisionStatsExporter(DecisionStatsExporterFunc(func(ctx context.Context, stats []DecisionStats) error {
+				exported = stats
+				return nil
+			}))
+			So(engine.exportDecisionStats(ctx), ShouldBeNil)
+			So(exported, ShouldHaveLength, 2)
+			So(exported[0].BizCode, ShouldEqual, "card")
+			So(exported[1].BizCode, ShouldEqual, "loan")
+		})
+
+		Convey("定期导出", func() {
+			exec(700)
+
+			done := make(chan []DecisionStats, 1)
+			cfg.DecisionStatsExportInterval = time.Second
+			engine.SetDecisionStatsExporter(DecisionStatsExporterFunc(func(ctx context.Context, stats []DecisionStats) error {
+				select {
+				case done <- stats:
+				default:
+				}
+				return nil
+			}))
+			So(engine.StartDecisionStatsExport(), ShouldBeNil)
+
+			select {
+			case stats := <-done:
+				So(stats, ShouldHaveLength, 1)
+			case <-time.After(3 * time.Second):
+				So("导出超时", ShouldBeEmpty)
+			}
+		})
+	})
+}
@@ -0,0 +1,170 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// ============================================================================
+// 批量执行 - 结果与输入定义按下标一一对应
+// ============================================================================
+
+// ErrBatchAborted 快速失败模式下因前序规则失败而未执行
+var ErrBatchAborted = errors.New("批量执行已中止，规则未执行")
+
+// BatchResult 批量执行结果
+type BatchResult[T any] struct {
+	Results []T     // 按定义顺序排列的执行结果，失败位置为零值
+	Errors  []error // 按定义顺序排列的执行错误，成功位置为nil
+}
+
+// newBatchResult 创建批量执行结果
+func newBatchResult[T any](size int) *BatchResult[T] {
+	return &BatchResult[T]{
+		Results: make([]T, size),
+		Errors:  make([]error, size),
+	}
+}
+
+// Err 汇总所有失败项的错误 - 按下标顺序使用errors.Join合并，全部成功时返回nil
+func (r *BatchResult[T]) Err() error {
+	var errs []error
+	for i, err := range r.Errors {
+		if err != nil {
+			errs = append(errs, fmt.Errorf("第%d条规则执行失败: %w", i, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Failed 返回失败项的下标，按升序排列
+func (r *BatchResult[T]) Failed() []int {
+	var failed []int
+	for i, err := range r.Errors {
+		if err != nil {
+			failed = append(failed, i)
+		}
+	}
+	return failed
+}
+
+// ExecuteBatchResult 批量执行多个规则并返回逐条结果和错误
+//
+// 无论并行执行的完成顺序如何，Results和Errors均与definitions按下标一一对应。
+// 开启FailFast时首个失败后剩余规则不再执行，对应错误为ErrBatchAborted；
+// 调用方取消ctx时未执行的规则错误为ctx.Err()。执行中的规则在下一条规则求值前停止，
+// 错误包装了 context.Canceled。
+//
+// 使用示例:
+//
+//	batch := engine.ExecuteBatchResult(ctx, rules, input)
+//	if err := batch.Err(); err != nil {
+//	    log.Printf("部分规则失败 %v: %v", batch.Failed(), err)
+//	}
+func (e *DynamicEngine[T]) ExecuteBatchResult(
+	ctx context.Context,
+	definitions []interface{},
+	input any,
+) *BatchResult[T] {
+	if e.isClosed() {
+		batch := newBatchResult[T](len(definitions))
+		for i := range batch.Errors {
+			batch.Errors[i] = ErrEngineClosed
+		}
+		return batch
+	}
+
+	if !e.config.ParallelExecution {
+		return e.executeBatchSequential(ctx, definitions, input)
+	}
+
+	return e.executeBatchParallel(ctx, definitions, input)
+}
+
+// executeBatchSequential 顺序批量执行
+func (e *DynamicEngine[T]) executeBatchSequential(
+	ctx context.Context,
+	definitions []interface{},
+	input any,
+) *BatchResult[T] {
+	batch := newBatchResult[T](len(definitions))
+	aborted := false
+
+	for i, def := range definitions {
+		if err := ctx.Err(); err != nil {
+			batch.Errors[i] = err
+			continue
+		}
+		if aborted {
+			batch.Errors[i] = ErrBatchAborted
+			continue
+		}
+
+		batch.Results[i], batch.Errors[i] = e.ExecuteRuleDefinition(ctx, def, input)
+		if batch.Errors[i] != nil {
+			if e.logger != nil {
+				e.logger.Warnf(ctx, "规则执行失败，跳过", "index", i, "error", batch.Errors[i])
+			}
+			aborted = e.config.FailFast
+		}
+	}
+
+	return batch
+}
+
+// executeBatchParallel 并行批量执行 - 并发数受MaxParallelism限制
+func (e *DynamicEngine[T]) executeBatchParallel(
+	ctx context.Context,
+	definitions []interface{},
+	input any,
+) *BatchResult[T] {
+	batch := newBatchResult[T](len(definitions))
+
+	limit := e.config.MaxParallelism
+	if limit <= 0 {
+		limit = runtime.NumCPU()
+	}
+
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(limit)
+
+	for i, def := range definitions {
+		idx, definition := i, def
+		group.Go(func() error {
+			// 调用方取消或快速失败时跳过未开始的规则
+			if groupCtx.Err() != nil {
+				batch.Errors[idx] = batchSkipError(ctx)
+				return nil
+			}
+
+			batch.Results[idx], batch.Errors[idx] = e.ExecuteRuleDefinition(groupCtx, definition, input)
+			if batch.Errors[idx] != nil && e.config.FailFast {
+				return batch.Errors[idx]
+			}
+			return nil
+		})
+	}
+
+	_ = group.Wait()
+
+	// 记录错误
+	for i, err := range batch.Errors {
+		if err != nil && !errors.Is(err, ErrBatchAborted) && e.logger != nil {
+			e.logger.Warnf(ctx, "并行规则执行失败", "index", i, "error", err)
+		}
+	}
+
+	return batch
+}
+
+// batchSkipError 获取被跳过规则的错误 - 调用方取消时返回ctx错误，否则为快速失败中止
+func batchSkipError(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return ErrBatchAborted
+}
@@ -0,0 +1,263 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gitee.com/damengde/runehammer/rule"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// concurrencyProbe 记录规则执行的最大并发数
+type concurrencyProbe struct {
+	current atomic.Int64
+	max     atomic.Int64
+}
+
+func (p *concurrencyProbe) Enter() bool {
+	n := p.current.Add(1)
+	for {
+		m := p.max.Load()
+		if n <= m || p.max.CompareAndSwap(m, n) {
+			break
+		}
+	}
+	time.Sleep(5 * time.Millisecond)
+	p.current.Add(-1)
+	return true
+}
+
+// slowTicker 每次调用耗时5ms，用于构造不会自行结束的慢规则（动作修改N使条件重新求值）
+type slowTicker struct {
+	N     int64
+	calls atomic.Int64
+}
+
+func (s *slowTicker) Tick(n int64) bool {
+	s.calls.Add(1)
+	time.Sleep(5 * time.Millisecond)
+	return true
+}
+
+// failAfterTick 慢规则开始执行后以panic失败，保证快速失败发生在慢规则执行期间
+type failAfterTick struct {
+	ticker *slowTicker
+}
+
+func (f *failAfterTick) Fail() bool {
+	for f.ticker.calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	panic("慢规则执行中失败")
+}
+
+// TestExecuteBatchResult 测试批量执行结果
+func TestExecuteBatchResult(t *testing.T) {
+	Convey("批量执行结果", t, func() {
+		ctx := context.Background()
+
+		definitions := make([]interface{}, 0, 20)
+		for i := 0; i < 20; i++ {
+			when := fmt.Sprintf("Params >= %d", i)
+			if i%5 == 3 {
+				when = "invalid syntax here"
+			}
+			definitions = append(definitions, rule.SimpleRule{
+				When: when,
+				Then: map[string]string{"Result.Index": fmt.Sprintf("%d", i)},
+			})
+		}
+
+		for _, parallel := range []bool{true, false} {
+			Convey(fmt.Sprintf("并行=%v 结果按下标对应且错误被汇总", parallel), func() {
+				engine := NewDynamicEngine[map[string]interface{}](DynamicEngineConfig{
+					EnableCache:       false,
+					ParallelExecution: parallel,
+				})
+
+				batch := engine.ExecuteBatchResult(ctx, definitions, 100)
+				So(batch.Results, ShouldHaveLength, 20)
+				So(batch.Errors, ShouldHaveLength, 20)
+				So(batch.Failed(), ShouldResemble, []int{3, 8, 13, 18})
+
+				for i, result := range batch.Results {
+					if i%5 == 3 {
+						So(result, ShouldBeNil)
+						continue
+					}
+					So(result["Index"], ShouldEqual, i)
+				}
+
+				err := batch.Err()
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "第3条规则执行失败")
+				So(err.Error(), ShouldContainSubstring, "第18条规则执行失败")
+				So(errors.Is(err, batch.Errors[8]), ShouldBeTrue)
+			})
+		}
+
+		Convey("ExecuteBatch返回失败项的汇总错误", func() {
+			engine := NewDynamicEngine[map[string]interface{}]()
+
+			results, err := engine.ExecuteBatch(ctx, definitions[:5], 100)
+			So(results, ShouldHaveLength, 5)
+			So(results[3], ShouldBeNil)
+			So(results[4]["Index"], ShouldEqual, 4)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "第3条规则执行失败")
+
+			_, err = engine.ExecuteBatch(ctx, definitions[:3], 100)
+			So(err, ShouldBeNil)
+		})
+
+		Convey("全部成功时Err返回nil", func() {
+			engine := NewDynamicEngine[map[string]interface{}]()
+
+			batch := engine.ExecuteBatchResult(ctx, definitions[:3], 100)
+			So(batch.Err(), ShouldBeNil)
+			So(batch.Failed(), ShouldBeEmpty)
+		})
+
+		Convey("并行编译和共享缓存规则的执行互不影响", func() {
+			engine := NewDynamicEngine[map[string]interface{}](DynamicEngineConfig{
+				EnableCache:       true,
+				CacheTTL:          time.Minute,
+				MaxCacheSize:      100,
+				ParallelExecution: true,
+				MaxParallelism:    8,
+			})
+			defer engine.Close()
+
+			// 前半为各不相同的规则（并发编译），后半为同一规则（并发执行缓存的知识库）
+			mixed := make([]interface{}, 0, 64)
+			for i := 0; i < 32; i++ {
+				mixed = append(mixed, rule.SimpleRule{
+					When: fmt.Sprintf("Params >= %d", i),
+					Then: map[string]string{"Result.Index": fmt.Sprintf("%d", i)},
+				})
+			}
+			for i := 0; i < 32; i++ {
+				mixed = append(mixed, definitions[0])
+			}
+
+			for round := 0; round < 3; round++ {
+				batch := engine.ExecuteBatchResult(ctx, mixed, 100)
+				So(batch.Err(), ShouldBeNil)
+				for i, result := range batch.Results {
+					if i < 32 {
+						So(result["Index"], ShouldEqual, i)
+					} else {
+						So(result["Index"], ShouldEqual, 0)
+					}
+				}
+			}
+		})
+
+		Convey("并发数受MaxParallelism限制", func() {
+			probe := &concurrencyProbe{}
+			engine := NewDynamicEngine[map[string]interface{}](DynamicEngineConfig{
+				ParallelExecution: true,
+				MaxParallelism:    2,
+			})
+			engine.RegisterCustomObject("Probe", probe)
+
+			probeRules := make([]interface{}, 0, 10)
+			for i := 0; i < 10; i++ {
+				probeRules = append(probeRules, rule.SimpleRule{
+					When: fmt.Sprintf("Params >= %d", i),
+					Then: map[string]string{"Result.Entered": "Probe.Enter()"},
+				})
+			}
+
+			batch := engine.ExecuteBatchResult(ctx, probeRules, 100)
+			So(batch.Err(), ShouldBeNil)
+			So(probe.max.Load(), ShouldBeBetweenOrEqual, 1, 2)
+		})
+
+		Convey("快速失败时剩余规则不再执行", func() {
+			for _, parallel := range []bool{true, false} {
+				engine := NewDynamicEngine[map[string]interface{}](DynamicEngineConfig{
+					ParallelExecution: parallel,
+					MaxParallelism:    1,
+					FailFast:          true,
+				})
+
+				batch := engine.ExecuteBatchResult(ctx, definitions[:6], 100)
+				So(batch.Results[2]["Index"], ShouldEqual, 2)
+				So(batch.Errors[3], ShouldNotBeNil)
+				So(errors.Is(batch.Errors[3], ErrBatchAborted), ShouldBeFalse)
+				So(batch.Errors[4], ShouldEqual, ErrBatchAborted)
+				So(batch.Errors[5], ShouldEqual, ErrBatchAborted)
+			}
+		})
+
+		Convey("快速失败时中止执行中的慢规则", func() {
+			engine := NewDynamicEngine[map[string]interface{}](DynamicEngineConfig{
+				ParallelExecution: true,
+				MaxParallelism:    2,
+				FailFast:          true,
+			})
+			ticker := &slowTicker{}
+			engine.RegisterCustomObject("Slow", ticker)
+
+			// 慢规则不Retract，每个周期都会再次命中，直到达到最大周期数（约25秒）
+			slow := rule.RuleBundle{BizCode: "slow", Rules: []rule.Rule{{
+				Name:    "slow",
+				Enabled: true,
+				GRL:     `rule SlowLoop "慢规则" { when Slow.Tick(Slow.N) then Slow.N = Slow.N + 1; }`,
+			}}}
+			engine.RegisterCustomObject("Gate", &failAfterTick{ticker: ticker})
+			failing := rule.RuleBundle{BizCode: "fail", Rules: []rule.Rule{{
+				Name:    "fail",
+				Enabled: true,
+				GRL:     `rule FailLater "执行中失败" { when true then Gate.Fail(); Retract("FailLater"); }`,
+			}}}
+
+			start := time.Now()
+			batch := engine.ExecuteBatchResult(ctx, []interface{}{slow, failing}, 100)
+			So(time.Since(start), ShouldBeLessThan, 5*time.Second)
+			So(errors.Is(batch.Errors[1], ErrRulePanic), ShouldBeTrue)
+			So(errors.Is(batch.Errors[0], context.Canceled), ShouldBeTrue)
+		})
+
+		Convey("调用方取消时中止执行中的慢规则", func() {
+			engine := NewDynamicEngine[map[string]interface{}](DynamicEngineConfig{
+				ParallelExecution: true,
+			})
+			ticker := &slowTicker{}
+			engine.RegisterCustomObject("Slow", ticker)
+
+			slow := rule.RuleBundle{BizCode: "slow", Rules: []rule.Rule{{
+				Name:    "slow",
+				Enabled: true,
+				GRL:     `rule SlowLoop "慢规则" { when Slow.Tick(Slow.N) then Slow.N = Slow.N + 1; }`,
+			}}}
+
+			timeout, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+			defer cancel()
+			batch := engine.ExecuteBatchResult(timeout, []interface{}{slow}, 100)
+			So(errors.Is(batch.Errors[0], context.DeadlineExceeded), ShouldBeTrue)
+			So(ticker.calls.Load(), ShouldBeLessThan, 100)
+		})
+
+		Convey("调用方取消时未执行的规则返回ctx错误", func() {
+			canceled, cancel := context.WithCancel(ctx)
+			cancel()
+
+			for _, parallel := range []bool{true, false} {
+				engine := NewDynamicEngine[map[string]interface{}](DynamicEngineConfig{
+					ParallelExecution: parallel,
+				})
+
+				batch := engine.ExecuteBatchResult(canceled, definitions[:3], 100)
+				for _, err := range batch.Errors {
+					So(errors.Is(err, context.Canceled), ShouldBeTrue)
+				}
+			}
+		})
+	})
+}
@@ -0,0 +1,152 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+)
+
+// ============================================================================
+// 规则包导入 - 校验依赖清单后再接受外部规则集
+// ============================================================================
+
+// ImportBundle 导入规则包 - 解析并校验依赖清单
+//
+// 严格验证模式下依赖不满足直接拒绝导入，否则仅记录警告；超出规则集限制（RuleLimits）时总是拒绝导入。
+// 传入输入样例时同样校验清单中的输入字段（见 VerifyBundleInput）。
+// 返回的规则包可直接传给ExecuteRuleDefinition执行。
+//
+// 参数:
+//
+//	ctx   - 上下文
+//	data  - 规则包JSON
+//	input - 可选，执行时使用的输入（或其零值），如 MyInput{}
+//
+// 返回值:
+//
+//	*rule.RuleBundle - 解析后的规则包
+//	error            - 解析失败、超出规则集限制（*RuleLimitError）或依赖不满足
+func (e *DynamicEngine[T]) ImportBundle(ctx context.Context, data string, input ...any) (*rule.RuleBundle, error) {
+	bundle := &rule.RuleBundle{}
+	if err := bundle.FromJSON(data); err != nil {
+		return nil, fmt.Errorf("解析规则包失败: %w", err)
+	}
+
+	rules := make([]*rule.Rule, len(bundle.Rules))
+	for i := range bundle.Rules {
+		rules[i] = &bundle.Rules[i]
+	}
+	if err := checkRuleLimits(bundle.BizCode, rules, e.config.RuleLimits); err != nil {
+		return nil, err
+	}
+
+	errs := []error{e.VerifyBundle(bundle)}
+	for _, in := range input {
+		errs = append(errs, e.VerifyBundleInput(bundle, in))
+	}
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		if e.config.StrictValidation {
+			return nil, err
+		}
+		if e.logger != nil {
+			e.logger.Warnf(ctx, "规则包依赖不满足，执行时可能失败", "bizCode", bundle.BizCode, "error", err)
+		}
+	}
+
+	return bundle, nil
+}
+
+// VerifyBundle 校验规则包依赖 - 检查当前引擎是否提供清单中的函数和查找对象
+//
+// 返回值:
+//
+//	error - 依赖不满足时返回*rule.BundleRequirementError
+func (e *DynamicEngine[T]) VerifyBundle(bundle *rule.RuleBundle) error {
+	if bundle == nil {
+		return fmt.Errorf("规则包为空")
+	}
+
+	functions := e.availableFunctions()
+	return bundle.Check(
+		func(name string) bool { return functions[name] },
+		func(name string) bool {
+			_, ok := e.customObjects[name]
+			return ok
+		},
+	)
+}
+
+// VerifyBundleInput 校验输入类型是否提供规则包清单中的输入字段
+//
+// 按输入的类型检查，不要求字段有值：结构体字段按名称匹配，map、interface 的下级字段无法静态确定，视为提供。
+//
+// 参数:
+//
+//	bundle - 规则包
+//	input  - 执行时使用的输入（或其零值）
+//
+// 返回值:
+//
+//	error - 存在缺失字段时返回*rule.BundleRequirementError
+func (e *DynamicEngine[T]) VerifyBundleInput(bundle *rule.RuleBundle, input any) error {
+	if bundle == nil {
+		return fmt.Errorf("规则包为空")
+	}
+
+	inputType := reflect.TypeOf(input)
+	return bundle.CheckInputFields(func(path string) bool {
+		return typeHasField(inputType, path)
+	})
+}
+
+// typeHasField 判断类型是否包含点分路径的导出字段
+func typeHasField(t reflect.Type, path string) bool {
+	for _, segment := range strings.Split(path, ".") {
+		for t != nil && t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t == nil {
+			return false
+		}
+
+		switch t.Kind() {
+		case reflect.Map, reflect.Interface:
+			return true
+		case reflect.Struct:
+			field, ok := t.FieldByName(segment)
+			if !ok || !field.IsExported() {
+				return false
+			}
+			t = field.Type
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// availableFunctions 收集可用函数名 - Grule内置函数、引擎内置函数和自定义函数
+func (e *DynamicEngine[T]) availableFunctions() map[string]bool {
+	functions := make(map[string]bool)
+
+	builtinType := reflect.TypeOf(&ast.BuiltInFunctions{})
+	for i := 0; i < builtinType.NumMethod(); i++ {
+		functions[builtinType.Method(i).Name] = true
+	}
+
+	dataCtx := ast.NewDataContext()
+	e.injectBuiltinFunctions(dataCtx)
+	e.injectCustomFunctions(context.Background(), dataCtx, &functionGuard{})
+	for _, key := range dataCtx.GetKeys() {
+		functions[key] = true
+	}
+
+	return functions
+}
@@ -0,0 +1,96 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gitee.com/damengde/runehammer/rule"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// TestDynamicEngineBundle 测试动态引擎导入规则包
+func TestDynamicEngineBundle(t *testing.T) {
+	Convey("动态引擎规则包导入测试", t, func() {
+		grl := `rule R1 "检查" salience 10 {
+    when
+        Params.Customer.Age >= 18 && AgeChecker.ValidateAge(Params.Customer.Age)
+    then
+        Result["ok"] = true;
+        Retract("R1");
+}`
+		bundle := rule.NewRuleBundle("biz", []*rule.Rule{{Name: "R1", GRL: grl, Enabled: true}})
+		data, err := bundle.ToJSON()
+		So(err, ShouldBeNil)
+
+		Convey("严格模式下缺失依赖拒绝导入", func() {
+			engine := NewDynamicEngine[map[string]interface{}](DynamicEngineConfig{
+				StrictValidation: true,
+				DefaultTimeout:   time.Second,
+			})
+
+			_, err := engine.ImportBundle(context.Background(), data)
+			So(err, ShouldNotBeNil)
+
+			var reqErr *rule.BundleRequirementError
+			So(errors.As(err, &reqErr), ShouldBeTrue)
+			So(reqErr.MissingLookups, ShouldResemble, []string{"AgeChecker"})
+			So(reqErr.MissingFunctions, ShouldBeEmpty)
+		})
+
+		Convey("非严格模式仅告警", func() {
+			engine := NewDynamicEngine[map[string]interface{}]()
+
+			imported, err := engine.ImportBundle(context.Background(), data)
+			So(err, ShouldBeNil)
+			So(imported.BizCode, ShouldEqual, "biz")
+		})
+
+		Convey("依赖满足后可执行", func() {
+			engine := NewDynamicEngine[map[string]interface{}](DynamicEngineConfig{
+				StrictValidation: true,
+				DefaultTimeout:   time.Second,
+			})
+			engine.RegisterCustomObject("AgeChecker", customFuncHolder{})
+
+			imported, err := engine.ImportBundle(context.Background(), data)
+			So(err, ShouldBeNil)
+
+			result, err := engine.ExecuteRuleDefinition(context.Background(), imported, TestInput{Customer: TestCustomer{Age: 20}})
+			So(err, ShouldBeNil)
+			So(result["ok"], ShouldEqual, true)
+		})
+
+		Convey("按输入类型校验清单中的输入字段", func() {
+			engine := NewDynamicEngine[map[string]interface{}](DynamicEngineConfig{
+				StrictValidation: true,
+				DefaultTimeout:   time.Second,
+			})
+			engine.RegisterCustomObject("AgeChecker", customFuncHolder{})
+
+			So(engine.VerifyBundleInput(bundle, TestInput{}), ShouldBeNil)
+			So(engine.VerifyBundleInput(bundle, &TestInput{}), ShouldBeNil)
+			So(engine.VerifyBundleInput(bundle, map[string]any{}), ShouldBeNil)
+
+			type otherInput struct{ Order TestCustomer }
+			err := engine.VerifyBundleInput(bundle, otherInput{})
+			var reqErr *rule.BundleRequirementError
+			So(errors.As(err, &reqErr), ShouldBeTrue)
+			So(reqErr.MissingFields, ShouldResemble, []string{"Params.Customer.Age"})
+
+			_, err = engine.ImportBundle(context.Background(), data, otherInput{})
+			So(errors.As(err, &reqErr), ShouldBeTrue)
+			_, err = engine.ImportBundle(context.Background(), data, TestInput{})
+			So(err, ShouldBeNil)
+		})
+
+		Convey("无效输入", func() {
+			engine := NewDynamicEngine[map[string]interface{}]()
+			_, err := engine.ImportBundle(context.Background(), "{invalid")
+			So(err, ShouldNotBeNil)
+			So(engine.VerifyBundle(nil), ShouldNotBeNil)
+			So(engine.VerifyBundleInput(nil, TestInput{}), ShouldNotBeNil)
+		})
+	})
+}
@@ -6,17 +6,23 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
+	"math/rand"
 	"reflect"
+	"runtime"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"gitee.com/damengde/runehammer/config"
 	logger "gitee.com/damengde/runehammer/logger"
 	"gitee.com/damengde/runehammer/rule"
 	"github.com/hyperjumptech/grule-rule-engine/ast"
 	"github.com/hyperjumptech/grule-rule-engine/builder"
-	grengine "github.com/hyperjumptech/grule-rule-engine/engine"
 	"github.com/hyperjumptech/grule-rule-engine/pkg"
 )
 
@@ -26,24 +32,39 @@ import (
 
 // DynamicEngine 动态规则引擎
 type DynamicEngine[T any] struct {
-	converter        rule.RuleConverter     // 规则转换器
-	knowledgeLibrary *ast.KnowledgeLibrary  // Grule知识库
-	customFunctions  map[string]interface{} // 自定义函数库
-	customObjects    map[string]interface{} // 自定义对象库（包含方法）
-	validators       []RuleValidator        // 规则验证器
-	logger           logger.Logger          // 日志记录器
-	cache            *DynamicRuleCache      // 规则缓存（可选）
-	config           DynamicEngineConfig    // 引擎配置
+	converter        rule.RuleConverter       // 规则转换器
+	customFunctions  map[string]interface{}   // 自定义函数库
+	functionTimeouts map[string]time.Duration // 自定义函数超时时间
+	customObjects    map[string]interface{}   // 自定义对象库（包含方法）
+	validators       []RuleValidator          // 规则验证器
+	logger           logger.Logger            // 日志记录器
+	cache            *DynamicRuleCache        // 规则缓存（可选）
+	config           DynamicEngineConfig      // 引擎配置
+	metrics          *execMetrics             // 执行指标
+	closed           atomic.Bool              // 引擎是否已关闭
+	clock            Clock                    // 时间函数使用的时钟，为nil时使用系统时间
+	functionMutex    sync.RWMutex             // 保护customFunctions和functionTimeouts，注册可与执行并发
 }
 
 // DynamicEngineConfig 动态引擎配置
 type DynamicEngineConfig struct {
-	EnableCache       bool          // 是否启用缓存
-	CacheTTL          time.Duration // 缓存过期时间
-	MaxCacheSize      int           // 最大缓存大小
-	StrictValidation  bool          // 是否严格验证
-	ParallelExecution bool          // 是否支持并行执行
-	DefaultTimeout    time.Duration // 默认超时时间
+	EnableCache         bool              // 是否启用缓存
+	CacheTTL            time.Duration     // 缓存过期时间
+	MaxCacheSize        int               // 最大缓存大小
+	StrictValidation    bool              // 是否严格验证
+	CacheTTLJitter      float64           // 缓存TTL随机抖动比例（0~1），如0.1表示±10%
+	CacheSweepInterval  time.Duration     // 过期缓存后台清理间隔，>0时启动清理协程且须调用Close停止，默认0仅在访问时清理
+	ParallelExecution   bool              // 是否支持并行执行
+	MaxParallelism      int               // 并行批量执行的最大并发数，<=0时使用CPU核数
+	FailFast            bool              // 批量执行遇到首个错误时停止剩余规则，并中止执行中的规则
+	DefaultTimeout      time.Duration     // 默认超时时间
+	NullPolicy          config.NullPolicy // 缺失字段的比较语义，同时作用于规则定义转换和执行
+	CopyInput           bool              // 是否在注入前深拷贝输入，规则修改Params不影响调用方数据
+	Locale              string            // 格式化函数的默认区域，如 zh-CN，为空时为 en-US
+	SimilarityMaxLength int               // 相似度函数（Levenshtein、JaroWinkler）的最大输入长度，<=0时取1000
+	RuleLimits          config.RuleLimits // 导入规则包的规模与复杂度限制，<=0的项不限制
+	ParallelConditions  bool              // 规则定义中与/或复合条件的多个 Func.Call 函数条件并发求值，见 Func.AnyOf / Func.AllOf
+	FoldConstants       bool              // 转换规则定义时折叠常量子表达式，恒真/恒假的条件输出警告日志
 }
 
 // RuleValidator 规则验证器接口
@@ -56,8 +77,12 @@ type DynamicRuleCache struct {
 	cache   map[string]*CachedRule
 	mu      sync.RWMutex
 	ttl     time.Duration
+	jitter  float64 // TTL随机抖动比例，避免同时过期导致集中重编译
 	maxSize int
 	size    int
+	hits    atomic.Int64
+	misses  atomic.Int64
+	stopCh  chan struct{} // 过期清理协程停止信号
 }
 
 // CachedRule 缓存的规则
@@ -65,18 +90,23 @@ type CachedRule struct {
 	KB        *ast.KnowledgeBase
 	Hash      string
 	CreatedAt time.Time
+	ExpiresAt time.Time // 过期时间（含抖动），为零时按CreatedAt+TTL计算
 	HitCount  int64
 }
 
 // NewDynamicEngine 创建动态规则引擎
+//
+// 默认不启动后台协程；配置了 CacheSweepInterval 时启动缓存过期清理协程，不再使用时须调用 Close 停止。
 func NewDynamicEngine[T any](config ...DynamicEngineConfig) *DynamicEngine[T] {
 	// 默认配置
 	defaultConfig := DynamicEngineConfig{
 		EnableCache:       true,
 		CacheTTL:          30 * time.Minute,
+		CacheTTLJitter:    0.1,
 		MaxCacheSize:      1000,
 		StrictValidation:  false,
 		ParallelExecution: true,
+		MaxParallelism:    runtime.NumCPU(),
 		DefaultTimeout:    30 * time.Second,
 	}
 
@@ -85,50 +115,73 @@ func NewDynamicEngine[T any](config ...DynamicEngineConfig) *DynamicEngine[T] {
 	}
 
 	engine := &DynamicEngine[T]{
-		converter:        rule.NewGRLConverter(),
-		knowledgeLibrary: ast.NewKnowledgeLibrary(),
 		customFunctions:  make(map[string]interface{}),
+		functionTimeouts: make(map[string]time.Duration),
 		customObjects:    make(map[string]interface{}),
 		validators:       []RuleValidator{},
 		config:           defaultConfig,
+		metrics:          &execMetrics{},
 	}
+	engine.converter = rule.NewGRLConverter(rule.ConverterConfig{
+		NullPolicy:         defaultConfig.NullPolicy,
+		ParallelConditions: defaultConfig.ParallelConditions,
+		FoldConstants:      defaultConfig.FoldConstants,
+		OnWarning:          engine.conversionWarning,
+	})
 
-	// 初始化缓存
+	// 初始化缓存，配置了清理间隔时启动后台清理协程（由Close停止）
 	if defaultConfig.EnableCache {
 		engine.cache = NewDynamicRuleCache(defaultConfig.CacheTTL, defaultConfig.MaxCacheSize)
+		engine.cache.SetTTLJitter(defaultConfig.CacheTTLJitter)
+		engine.cache.StartSweeper(defaultConfig.CacheSweepInterval)
 	}
 
 	return engine
 }
 
 // ExecuteRuleDefinition 执行规则定义
+//
+// 执行过程中的panic会被捕获并转换为*RulePanicError（errors.Is(err, ErrRulePanic)成立），
+// 不会影响调用方协程。
 func (e *DynamicEngine[T]) ExecuteRuleDefinition(
 	ctx context.Context,
 	definition interface{},
 	input any,
-) (T, error) {
+) (result T, err error) {
 	var zero T
+	ruleHash := ""
+
+	if e.isClosed() {
+		return zero, ErrEngineClosed
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			result, err = zero, newRulePanicError(ruleHash, r, e.metrics)
+			if e.logger != nil {
+				e.logger.Errorf(ctx, "规则执行发生panic", "ruleID", ruleHash, "panic", r)
+			}
+		}
+	}()
+
+	// 1. 生成规则hash用于缓存
+	ruleHash = e.calculateRuleHash(definition)
 
-	// 1. 验证规则定义
+	// 2. 验证规则定义
 	if e.config.StrictValidation {
 		if err := e.validateRuleDefinition(definition); err != nil {
 			return zero, fmt.Errorf("规则验证失败: %w", err)
 		}
 	}
 
-	// 2. 生成规则hash用于缓存
-	ruleHash := e.calculateRuleHash(definition)
-
 	// 3. 检查缓存
 	var knowledgeBase *ast.KnowledgeBase
-	var err error
 
 	if e.cache != nil {
 		if cached := e.cache.Get(ruleHash); cached != nil {
 			knowledgeBase = cached.KB
-			cached.HitCount++
 			if e.logger != nil {
-				e.logger.Debugf(ctx, "使用缓存的规则", "hash", ruleHash, "hitCount", cached.HitCount)
+				e.logger.Debugf(ctx, "使用缓存的规则", "hash", ruleHash, "hitCount", atomic.LoadInt64(&cached.HitCount))
 			}
 		}
 	}
@@ -163,16 +216,19 @@ func (e *DynamicEngine[T]) ExecuteRuleDefinition(
 }
 
 // ExecuteBatch 批量执行多个规则
+//
+// 失败的规则对应位置为零值，返回的错误汇总各失败项（同BatchResult.Err）；需要逐条错误时使用ExecuteBatchResult。
 func (e *DynamicEngine[T]) ExecuteBatch(
 	ctx context.Context,
 	definitions []interface{},
 	input any,
 ) ([]T, error) {
-	if !e.config.ParallelExecution {
-		return e.executeBatchSequential(ctx, definitions, input)
+	if e.isClosed() {
+		return nil, ErrEngineClosed
 	}
 
-	return e.executeBatchParallel(ctx, definitions, input)
+	batch := e.ExecuteBatchResult(ctx, definitions, input)
+	return batch.Results, batch.Err()
 }
 
 // ExecuteWithTimeout 带超时的规则执行
@@ -194,13 +250,19 @@ func (e *DynamicEngine[T]) ExecuteWithTimeout(
 
 // RegisterCustomFunction 注册自定义函数
 func (e *DynamicEngine[T]) RegisterCustomFunction(name string, fn interface{}) {
+	e.functionMutex.Lock()
+	defer e.functionMutex.Unlock()
 	e.customFunctions[name] = fn
+	delete(e.functionTimeouts, name)
 }
 
 // RegisterCustomFunctions 批量注册自定义函数
 func (e *DynamicEngine[T]) RegisterCustomFunctions(functions map[string]interface{}) {
+	e.functionMutex.Lock()
+	defer e.functionMutex.Unlock()
 	for name, fn := range functions {
 		e.customFunctions[name] = fn
+		delete(e.functionTimeouts, name)
 	}
 }
 
@@ -209,6 +271,18 @@ func (e *DynamicEngine[T]) RegisterCustomObject(name string, obj interface{}) {
 	e.customObjects[name] = obj
 }
 
+// RegisterOperator 注册自定义条件操作符 - 已缓存的规则按新操作符重新转换
+func (e *DynamicEngine[T]) RegisterOperator(name string, handler rule.OperatorHandler) {
+	registry, ok := e.converter.(interface {
+		RegisterOperator(name string, handler rule.OperatorHandler)
+	})
+	if !ok {
+		return
+	}
+	registry.RegisterOperator(name, handler)
+	e.ClearCache()
+}
+
 // RegisterValidator 注册验证器
 func (e *DynamicEngine[T]) RegisterValidator(validator RuleValidator) {
 	e.validators = append(e.validators, validator)
@@ -219,6 +293,13 @@ func (e *DynamicEngine[T]) SetLogger(logger logger.Logger) {
 	e.logger = logger
 }
 
+// conversionWarning 记录规则转换警告（如条件恒为真/假）
+func (e *DynamicEngine[T]) conversionWarning(warning rule.ConversionWarning) {
+	if e.logger != nil {
+		e.logger.Warnf(context.Background(), "规则转换警告", "rule", warning.Rule, "warning", warning.Message, "expression", warning.Expression)
+	}
+}
+
 // GetCacheStats 获取缓存统计信息
 func (e *DynamicEngine[T]) GetCacheStats() CacheStats {
 	if e.cache == nil {
@@ -228,6 +309,15 @@ func (e *DynamicEngine[T]) GetCacheStats() CacheStats {
 	return e.cache.GetStats()
 }
 
+// ListCacheEntries 列出缓存项的年龄和命中信息（调试用）
+func (e *DynamicEngine[T]) ListCacheEntries() []CacheEntryInfo {
+	if e.cache == nil {
+		return []CacheEntryInfo{}
+	}
+
+	return e.cache.ListEntries()
+}
+
 // ClearCache 清空缓存
 func (e *DynamicEngine[T]) ClearCache() {
 	if e.cache != nil {
@@ -235,23 +325,51 @@ func (e *DynamicEngine[T]) ClearCache() {
 	}
 }
 
+// Close 关闭引擎 - 停止后台过期清理协程并释放缓存的知识库
+//
+// 关闭后执行规则返回ErrEngineClosed，重复调用直接返回nil。
+func (e *DynamicEngine[T]) Close() error {
+	if !e.closed.CompareAndSwap(false, true) {
+		return nil
+	}
+
+	if e.cache != nil {
+		e.cache.Close()
+		e.cache.Clear()
+	}
+
+	if e.logger != nil {
+		e.logger.Infof(context.Background(), "动态规则引擎已关闭")
+	}
+
+	return nil
+}
+
+// isClosed 判断引擎是否已关闭
+func (e *DynamicEngine[T]) isClosed() bool {
+	return e.closed.Load()
+}
+
 // ============================================================================
 // 内部实现方法
 // ============================================================================
 
 // compileGRL 编译GRL规则
+//
+// 每次编译使用独立的知识库，Grule的知识库不支持并发构建，批量并行执行时各规则同时编译。
 func (e *DynamicEngine[T]) compileGRL(grl, ruleID string) (*ast.KnowledgeBase, error) {
 	// 创建规则资源
 	ruleBytes := pkg.NewBytesResource([]byte(grl))
 
 	// 构建规则到知识库
-	ruleBuilder := builder.NewRuleBuilder(e.knowledgeLibrary)
+	library := ast.NewKnowledgeLibrary()
+	ruleBuilder := builder.NewRuleBuilder(library)
 	if err := ruleBuilder.BuildRuleFromResource(ruleID, "1.0.0", ruleBytes); err != nil {
 		return nil, fmt.Errorf("构建规则失败: %w", err)
 	}
 
 	// 获取知识库实例
-	knowledgeBase, err := e.knowledgeLibrary.NewKnowledgeBaseInstance(ruleID, "1.0.0")
+	knowledgeBase, err := library.NewKnowledgeBaseInstance(ruleID, "1.0.0")
 	if err != nil {
 		return nil, fmt.Errorf("创建知识库实例失败: %w", err)
 	}
@@ -271,6 +389,9 @@ func (e *DynamicEngine[T]) executeWithKnowledgeBase(
 	dataCtx := ast.NewDataContext()
 
 	// 注入输入数据
+	if e.config.CopyInput {
+		input = copyInput(input)
+	}
 	if err := e.injectInputData(dataCtx, input); err != nil {
 		return zero, fmt.Errorf("数据注入失败: %w", err)
 	}
@@ -279,80 +400,48 @@ func (e *DynamicEngine[T]) executeWithKnowledgeBase(
 	e.injectBuiltinFunctions(dataCtx)
 
 	// 注入自定义函数
-	e.injectCustomFunctions(dataCtx)
+	guard := &functionGuard{}
+	e.injectCustomFunctions(ctx, dataCtx, guard)
 
 	// 注入自定义对象
 	e.injectCustomObjects(dataCtx)
 
-	// 创建规则引擎
-	ruleEngine := grengine.NewGruleEngine()
-
 	// 验证知识库不为空
 	if knowledgeBase == nil {
 		return zero, fmt.Errorf("知识库为空")
 	}
-
-	// 执行规则
-	if err := ruleEngine.Execute(dataCtx, knowledgeBase); err != nil {
+	// 缓存的知识库由相同规则的并发执行共享，每次执行使用独立实例
+	instance, err := executionInstance(knowledgeBase)
+	if err != nil {
 		return zero, fmt.Errorf("规则执行失败: %w", err)
 	}
 
-	// 提取结果
-	return e.extractResult(dataCtx)
-}
-
-// executeBatchSequential 顺序批量执行
-func (e *DynamicEngine[T]) executeBatchSequential(
-	ctx context.Context,
-	definitions []interface{},
-	input any,
-) ([]T, error) {
-	var results []T
-
-	for i, def := range definitions {
-		result, err := e.ExecuteRuleDefinition(ctx, def, input)
-		if err != nil {
-			if e.logger != nil {
-				e.logger.Warnf(ctx, "规则执行失败，跳过", "index", i, "error", err)
-			}
-			var zero T
-			results = append(results, zero)
-			continue
-		}
-		results = append(results, result)
-	}
-
-	return results, nil
-}
-
-// executeBatchParallel 并行批量执行
-func (e *DynamicEngine[T]) executeBatchParallel(
-	ctx context.Context,
-	definitions []interface{},
-	input any,
-) ([]T, error) {
-	var wg sync.WaitGroup
-	results := make([]T, len(definitions))
-	errors := make([]error, len(definitions))
-
-	for i, def := range definitions {
-		wg.Add(1)
-		go func(idx int, definition interface{}) {
-			defer wg.Done()
-			results[idx], errors[idx] = e.ExecuteRuleDefinition(ctx, definition, input)
-		}(i, def)
+	// 以规则函数对象替换Grule内置函数，提供 Present 判断、按时钟取值的时间函数和区域格式化函数
+	functions := newRuleFunctions(ctx, e.logger, knowledgeBase.Name)
+	functions.clock = e.clock
+	functions.locale = e.config.Locale
+	functions.maxSimilarityLength = e.config.SimilarityMaxLength
+	execCtx := functions.wrap(dataCtx)
+
+	// 执行规则（捕获panic），ctx取消或超时时在下一条规则求值前停止执行
+	failOnCond := e.config.NullPolicy == config.NullPolicyError
+	err = safeExecute(ctx, execCtx, instance, instance.Name, e.metrics, failOnCond)
+	// 自定义函数超时或返回错误在条件中会被Grule视为不成立，以记录的错误为准
+	if timeoutErr := guard.Err(); timeoutErr != nil {
+		err = timeoutErr
+	} else if guardErr := functions.guard.Err(); guardErr != nil {
+		err = guardErr
+	} else if ctxErr := ctx.Err(); err != nil && ctxErr != nil && !errors.Is(err, ctxErr) {
+		// Grule以文本形式返回上下文错误，包装后可通过errors.Is判断取消和超时
+		err = fmt.Errorf("%w: %v", ctxErr, err)
 	}
-
-	wg.Wait()
-
-	// 记录错误
-	for i, err := range errors {
-		if err != nil && e.logger != nil {
-			e.logger.Warnf(ctx, "并行规则执行失败", "index", i, "error", err)
-		}
+	if err != nil {
+		return zero, fmt.Errorf("规则执行失败: %w", err)
 	}
 
-	return results, nil
+	// 提取结果
+	attachReasons(dataCtx, functions)
+	return e.extractResult(dataCtx)
 }
 
 // injectInputData 注入输入数据 - 将各种类型的输入数据注入到执行上下文
@@ -378,27 +467,21 @@ func (e *DynamicEngine[T]) injectInputData(dataCtx ast.IDataContext, input any)
 		return fmt.Errorf("注入Result变量失败: %w", err)
 	}
 
-	v := reflect.ValueOf(input)
-	t := reflect.TypeOf(input)
-
-	// 处理指针类型，获取实际的值和类型
-	if v.Kind() == reflect.Ptr {
-		v = v.Elem()
-		t = t.Elem()
-	}
+	// 按类型缓存的注入计划，指针已解引用
+	_, kind := planInput(input)
 
-	switch v.Kind() {
+	switch kind {
 	case reflect.Map:
 		return fmt.Errorf("不支持 map 类型，请使用结构体替代")
 	case reflect.Struct:
-		return e.injectStructData(dataCtx, input, t)
+		return e.injectStructData(dataCtx, input)
 	default:
 		return e.injectDefaultData(dataCtx, input)
 	}
 }
 
 // injectStructData 注入结构体数据 - 将整个结构体作为单个对象注入
-func (e *DynamicEngine[T]) injectStructData(dataCtx ast.IDataContext, input any, t reflect.Type) error {
+func (e *DynamicEngine[T]) injectStructData(dataCtx ast.IDataContext, input any) error {
 	// 统一使用Params作为输入变量名，保持与引擎一致
 	inputName := "Params"
 
@@ -420,13 +503,13 @@ func (e *DynamicEngine[T]) injectDefaultData(dataCtx ast.IDataContext, input any
 // injectBuiltinFunctions 注入内置函数
 func (e *DynamicEngine[T]) injectBuiltinFunctions(dataCtx ast.IDataContext) {
 	// 注入时间函数
+	clock := clockOrSystem(e.clock)
 	dataCtx.Add("Now", func() time.Time {
-		return time.Now()
+		return clock.Now()
 	})
 
 	dataCtx.Add("Today", func() time.Time {
-		now := time.Now()
-		return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		return startOfDay(clock.Now())
 	})
 
 	// 注入数学函数
@@ -462,13 +545,21 @@ func (e *DynamicEngine[T]) injectBuiltinFunctions(dataCtx ast.IDataContext) {
 			return 0
 		}
 	})
+
+	// 注入路径访问函数
+	injectPathFunctions(dataCtx)
+
+	// 注入结果合并函数
+	injectMergeFunctions(dataCtx)
 }
 
-// injectCustomFunctions 注入自定义函数
-func (e *DynamicEngine[T]) injectCustomFunctions(dataCtx ast.IDataContext) {
-	for name, fn := range e.customFunctions {
+// injectCustomFunctions 注入自定义函数及其调用对象，函数以ctx为执行上下文，超时和返回的错误记录到guard
+func (e *DynamicEngine[T]) injectCustomFunctions(ctx context.Context, dataCtx ast.IDataContext, guard *functionGuard) {
+	functions, timeouts := e.customFunctionSnapshot()
+	for name, fn := range customFunctionTable(ctx, guard, functions, timeouts) {
 		dataCtx.Add(name, fn)
 	}
+	injectFunctionCaller(ctx, dataCtx, guard, functions, timeouts)
 }
 
 // injectCustomObjects 注入自定义对象
@@ -566,36 +657,81 @@ func NewDynamicRuleCache(ttl time.Duration, maxSize int) *DynamicRuleCache {
 	}
 }
 
-// Get 获取缓存的规则
+// SetTTLJitter 设置TTL随机抖动比例 - 新写入的缓存项过期时间在TTL×(1±ratio)内随机分布
+func (c *DynamicRuleCache) SetTTLJitter(ratio float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.jitter = math.Max(0, math.Min(ratio, 1))
+}
+
+// Get 获取缓存的规则 - 命中时累加命中次数，未命中或已过期计为未命中
 func (c *DynamicRuleCache) Get(hash string) *CachedRule {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
 	cached, ok := c.cache[hash]
 	if !ok {
+		c.misses.Add(1)
 		return nil
 	}
 
 	// 检查是否过期
-	if time.Since(cached.CreatedAt) > c.ttl {
+	if c.expired(cached, time.Now()) {
+		c.misses.Add(1)
 		// 异步清理过期项
 		go func() {
 			c.mu.Lock()
-			delete(c.cache, hash)
-			c.size--
+			if c.cache[hash] == cached {
+				delete(c.cache, hash)
+				c.size--
+			}
 			c.mu.Unlock()
 		}()
 		return nil
 	}
 
+	c.hits.Add(1)
+	atomic.AddInt64(&cached.HitCount, 1)
 	return cached
 }
 
+// expired 判断缓存项是否过期
+func (c *DynamicRuleCache) expired(cached *CachedRule, now time.Time) bool {
+	if !cached.ExpiresAt.IsZero() {
+		return now.After(cached.ExpiresAt)
+	}
+	return now.Sub(cached.CreatedAt) > c.ttl
+}
+
+// jitteredTTL 计算带随机抖动的TTL
+func (c *DynamicRuleCache) jitteredTTL() time.Duration {
+	if c.jitter <= 0 {
+		return c.ttl
+	}
+	delta := (rand.Float64()*2 - 1) * c.jitter * float64(c.ttl)
+	return c.ttl + time.Duration(delta)
+}
+
 // Set 设置缓存
 func (c *DynamicRuleCache) Set(hash string, rule *CachedRule) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	// 计算带抖动的过期时间
+	if rule.CreatedAt.IsZero() {
+		rule.CreatedAt = time.Now()
+	}
+	if rule.ExpiresAt.IsZero() {
+		rule.ExpiresAt = rule.CreatedAt.Add(c.jitteredTTL())
+	}
+
+	// 覆盖已有项不占用额外容量
+	if _, exists := c.cache[hash]; exists {
+		c.cache[hash] = rule
+		return
+	}
+
 	// 检查容量
 	if c.size >= c.maxSize {
 		c.evictLRU()
@@ -605,6 +741,70 @@ func (c *DynamicRuleCache) Set(hash string, rule *CachedRule) {
 	c.size++
 }
 
+// StartSweeper 启动后台过期清理协程 - 定期移除过期项，释放不再访问的知识库内存
+//
+// 重复调用不会启动多个协程，通过Close停止。
+func (c *DynamicRuleCache) StartSweeper(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	if c.stopCh != nil {
+		c.mu.Unlock()
+		return
+	}
+	stopCh := make(chan struct{})
+	c.stopCh = stopCh
+	c.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				c.Sweep()
+			case <-stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Sweep 移除所有过期项
+//
+// 返回值:
+//
+//	int - 移除的缓存项数量
+func (c *DynamicRuleCache) Sweep() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := 0
+	now := time.Now()
+	for hash, cached := range c.cache {
+		if c.expired(cached, now) {
+			delete(c.cache, hash)
+			c.size--
+			removed++
+		}
+	}
+	return removed
+}
+
+// Close 停止后台过期清理协程，可重复调用
+func (c *DynamicRuleCache) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stopCh != nil {
+		close(c.stopCh)
+		c.stopCh = nil
+	}
+}
+
 // Clear 清空缓存
 func (c *DynamicRuleCache) Clear() {
 	c.mu.Lock()
@@ -625,24 +825,61 @@ func (c *DynamicRuleCache) GetStats() CacheStats {
 
 	now := time.Now()
 	for _, cached := range c.cache {
-		totalHits += cached.HitCount
-		if now.Sub(cached.CreatedAt) > c.ttl {
+		totalHits += atomic.LoadInt64(&cached.HitCount)
+		if c.expired(cached, now) {
 			expiredRules++
 		} else {
 			activeRules++
 		}
 	}
 
+	hits := c.hits.Load()
+	misses := c.misses.Load()
+	hitRate := 0.0
+	if hits+misses > 0 {
+		hitRate = float64(hits) / float64(hits+misses)
+	}
+
 	return CacheStats{
 		Size:         c.size,
 		MaxSize:      c.maxSize,
 		ActiveRules:  activeRules,
 		ExpiredRules: expiredRules,
 		TotalHits:    totalHits,
-		HitRate:      float64(totalHits) / float64(c.size+1), // 避免除零
+		Hits:         hits,
+		Misses:       misses,
+		HitRate:      hitRate,
 	}
 }
 
+// ListEntries 列出缓存项的年龄和命中信息（调试用），按创建时间先后排列
+func (c *DynamicRuleCache) ListEntries() []CacheEntryInfo {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	now := time.Now()
+	entries := make([]CacheEntryInfo, 0, len(c.cache))
+	for hash, cached := range c.cache {
+		expiresAt := cached.ExpiresAt
+		if expiresAt.IsZero() {
+			expiresAt = cached.CreatedAt.Add(c.ttl)
+		}
+		entries = append(entries, CacheEntryInfo{
+			Hash:      hash,
+			CreatedAt: cached.CreatedAt,
+			ExpiresAt: expiresAt,
+			Age:       now.Sub(cached.CreatedAt),
+			HitCount:  atomic.LoadInt64(&cached.HitCount),
+			Expired:   c.expired(cached, now),
+		})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].CreatedAt.Before(entries[j].CreatedAt)
+	})
+	return entries
+}
+
 // evictLRU 淘汰最少使用的缓存项
 func (c *DynamicRuleCache) evictLRU() {
 	var oldestHash string
@@ -671,6 +908,18 @@ type CacheStats struct {
 	MaxSize      int     `json:"maxSize"`      // 最大缓存大小
 	ActiveRules  int     `json:"activeRules"`  // 活跃规则数
 	ExpiredRules int     `json:"expiredRules"` // 过期规则数
-	TotalHits    int64   `json:"totalHits"`    // 总命中次数
-	HitRate      float64 `json:"hitRate"`      // 命中率
+	TotalHits    int64   `json:"totalHits"`    // 当前缓存项的累计命中次数
+	Hits         int64   `json:"hits"`         // 查询命中次数
+	Misses       int64   `json:"misses"`       // 查询未命中次数（含过期）
+	HitRate      float64 `json:"hitRate"`      // 命中率 = Hits / (Hits + Misses)
+}
+
+// CacheEntryInfo 缓存项调试信息
+type CacheEntryInfo struct {
+	Hash      string        `json:"hash"`      // 规则哈希
+	CreatedAt time.Time     `json:"createdAt"` // 创建时间
+	ExpiresAt time.Time     `json:"expiresAt"` // 过期时间（含抖动）
+	Age       time.Duration `json:"age"`       // 已存在时长
+	HitCount  int64         `json:"hitCount"`  // 命中次数
+	Expired   bool          `json:"expired"`   // 是否已过期
 }
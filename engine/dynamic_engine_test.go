@@ -2,6 +2,8 @@ package engine
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"testing"
 	"time"
 
@@ -39,6 +41,12 @@ func (customFuncHolder) CalculateDiscount(amount float64, rate float64) float64
 	return amount * rate
 }
 
+type geoHolder struct{}
+
+func (geoHolder) WithinRadius(distance float64, radius int64) bool {
+	return distance <= float64(radius)
+}
+
 // testValidator 实现 RuleValidator 接口的测试验证器
 type testValidator struct{}
 
@@ -283,8 +291,11 @@ func TestDynamicEngine(t *testing.T) {
 					Customer: TestCustomer{Age: 25},
 				}
 
-					// 使用很短的超时时间（规则应仍然成功执行）
-					result, err := engine.ExecuteWithTimeout(context.Background(), timeoutRule, input, 1*time.Nanosecond)
+					// 使用很短的超时时间（超时后规则停止执行）
+					_, err := engine.ExecuteWithTimeout(context.Background(), timeoutRule, input, 1*time.Nanosecond)
+					So(errors.Is(err, context.DeadlineExceeded), ShouldBeTrue)
+
+					result, err := engine.ExecuteWithTimeout(context.Background(), timeoutRule, input, time.Minute)
 					So(err, ShouldBeNil)
 					So(result["Processed"], ShouldEqual, true)
 			})
@@ -397,3 +408,182 @@ func TestDynamicEngine(t *testing.T) {
 		})
 	})
 }
+
+// TestDynamicRuleCache 测试动态规则缓存统计、TTL抖动和缓存项列表
+func TestDynamicRuleCache(t *testing.T) {
+	Convey("动态规则缓存", t, func() {
+
+		Convey("统计命中和未命中", func() {
+			c := NewDynamicRuleCache(time.Minute, 10)
+			So(c.Get("a"), ShouldBeNil)
+
+			c.Set("a", &CachedRule{Hash: "a", HitCount: 1})
+			So(c.Get("a"), ShouldNotBeNil)
+			So(c.Get("a"), ShouldNotBeNil)
+			So(c.Get("b"), ShouldBeNil)
+
+			stats := c.GetStats()
+			So(stats.Hits, ShouldEqual, 2)
+			So(stats.Misses, ShouldEqual, 2)
+			So(stats.HitRate, ShouldEqual, 0.5)
+			So(stats.TotalHits, ShouldEqual, 3)
+		})
+
+		Convey("过期项计为未命中", func() {
+			c := NewDynamicRuleCache(time.Minute, 10)
+			c.Set("old", &CachedRule{Hash: "old", CreatedAt: time.Now().Add(-2 * time.Minute)})
+
+			So(c.Get("old"), ShouldBeNil)
+			So(c.GetStats().Misses, ShouldEqual, 1)
+		})
+
+		Convey("TTL抖动在范围内分布", func() {
+			c := NewDynamicRuleCache(100*time.Second, 1000)
+			c.SetTTLJitter(0.2)
+
+			now := time.Now()
+			distinct := make(map[time.Time]bool)
+			for i := 0; i < 50; i++ {
+				hash := fmt.Sprintf("rule-%d", i)
+				c.Set(hash, &CachedRule{Hash: hash, CreatedAt: now})
+			}
+			for _, entry := range c.ListEntries() {
+				ttl := entry.ExpiresAt.Sub(now)
+				So(ttl, ShouldBeBetweenOrEqual, 80*time.Second, 120*time.Second)
+				distinct[entry.ExpiresAt] = true
+			}
+			So(len(distinct), ShouldBeGreaterThan, 1)
+		})
+
+		Convey("覆盖已有项不重复计数", func() {
+			c := NewDynamicRuleCache(time.Minute, 10)
+			c.Set("a", &CachedRule{Hash: "a"})
+			c.Set("a", &CachedRule{Hash: "a"})
+			So(c.GetStats().Size, ShouldEqual, 1)
+		})
+
+		Convey("Sweep移除过期项", func() {
+			c := NewDynamicRuleCache(time.Minute, 10)
+			c.Set("old", &CachedRule{Hash: "old", CreatedAt: time.Now().Add(-2 * time.Minute)})
+			c.Set("new", &CachedRule{Hash: "new"})
+
+			So(c.Sweep(), ShouldEqual, 1)
+			So(c.GetStats().Size, ShouldEqual, 1)
+			So(c.Get("new"), ShouldNotBeNil)
+		})
+
+		Convey("后台清理协程定期移除过期项", func() {
+			c := NewDynamicRuleCache(10*time.Millisecond, 10)
+			c.StartSweeper(5 * time.Millisecond)
+			defer c.Close()
+
+			c.Set("a", &CachedRule{Hash: "a"})
+			So(c.GetStats().Size, ShouldEqual, 1)
+
+			deadline := time.Now().Add(time.Second)
+			for c.GetStats().Size > 0 && time.Now().Before(deadline) {
+				time.Sleep(5 * time.Millisecond)
+			}
+			So(c.GetStats().Size, ShouldEqual, 0)
+		})
+
+		Convey("默认配置不启动清理协程", func() {
+			engine := NewDynamicEngine[map[string]interface{}]()
+			So(engine.cache, ShouldNotBeNil)
+			So(engine.cache.stopCh, ShouldBeNil)
+		})
+
+		Convey("配置清理间隔时启动清理协程，Close停止且可重复调用", func() {
+			engine := NewDynamicEngine[map[string]interface{}](DynamicEngineConfig{
+				EnableCache:        true,
+				CacheTTL:           time.Minute,
+				MaxCacheSize:       10,
+				CacheSweepInterval: time.Minute,
+			})
+			So(engine.cache.stopCh, ShouldNotBeNil)
+
+			So(engine.Close(), ShouldBeNil)
+			So(engine.cache.stopCh, ShouldBeNil)
+			So(engine.Close(), ShouldBeNil)
+		})
+
+		Convey("ListEntries返回年龄和命中信息", func() {
+			engine := NewDynamicEngine[map[string]interface{}]()
+			definition := rule.SimpleRule{
+				When: "Params > 0",
+				Then: map[string]string{"Result.Ok": "true"},
+			}
+
+			for i := 0; i < 3; i++ {
+				_, err := engine.ExecuteRuleDefinition(context.Background(), definition, 1)
+				So(err, ShouldBeNil)
+			}
+
+			entries := engine.ListCacheEntries()
+			So(entries, ShouldHaveLength, 1)
+			So(entries[0].HitCount, ShouldEqual, 3)
+			So(entries[0].Expired, ShouldBeFalse)
+			So(entries[0].Age, ShouldBeGreaterThanOrEqualTo, 0)
+		})
+	})
+}
+
+// TestDynamicEngineClose 测试动态引擎生命周期
+func TestDynamicEngineClose(t *testing.T) {
+	Convey("动态引擎关闭", t, func() {
+		ctx := context.Background()
+		engine := NewDynamicEngine[map[string]interface{}]()
+		definition := rule.SimpleRule{
+			When: "Params > 0",
+			Then: map[string]string{"Result.Ok": "true"},
+		}
+
+		_, err := engine.ExecuteRuleDefinition(ctx, definition, 1)
+		So(err, ShouldBeNil)
+		So(engine.GetCacheStats().Size, ShouldEqual, 1)
+
+		So(engine.Close(), ShouldBeNil)
+		So(engine.GetCacheStats().Size, ShouldEqual, 0)
+
+		Convey("关闭后执行返回ErrEngineClosed", func() {
+			_, err := engine.ExecuteRuleDefinition(ctx, definition, 1)
+			So(errors.Is(err, ErrEngineClosed), ShouldBeTrue)
+
+			_, err = engine.ExecuteWithTimeout(ctx, definition, 1, time.Second)
+			So(errors.Is(err, ErrEngineClosed), ShouldBeTrue)
+
+			results, err := engine.ExecuteBatch(ctx, []interface{}{definition}, 1)
+			So(results, ShouldBeNil)
+			So(errors.Is(err, ErrEngineClosed), ShouldBeTrue)
+
+			batch := engine.ExecuteBatchResult(ctx, []interface{}{definition, definition}, 1)
+			So(batch.Failed(), ShouldResemble, []int{0, 1})
+			So(errors.Is(batch.Err(), ErrEngineClosed), ShouldBeTrue)
+		})
+
+		Convey("重复关闭返回nil", func() {
+			So(engine.Close(), ShouldBeNil)
+		})
+	})
+}
+
+// TestDynamicEngineRegisterOperator 测试注册自定义操作符
+func TestDynamicEngineRegisterOperator(t *testing.T) {
+	Convey("动态引擎自定义操作符", t, func() {
+		engine := NewDynamicEngine[map[string]interface{}]()
+		defer engine.Close()
+
+		engine.RegisterCustomObject("Geo", geoHolder{})
+		engine.RegisterOperator("withinRadius", rule.OperatorFunc(func(left, right string) string {
+			return fmt.Sprintf("Geo.WithinRadius(%s, %s)", left, right)
+		}))
+
+		definition := rule.NewStandardRule("GEO_RULE", "距离检查")
+		definition.AddSimpleCondition("Params.Amount", rule.Operator("withinRadius"), 5)
+		definition.AddAction(rule.ActionTypeAssign, "Result.Nearby", true)
+
+		result, err := engine.ExecuteRuleDefinition(context.Background(), *definition, TestOrder{Amount: 3})
+		So(err, ShouldBeNil)
+		So(result["Nearby"], ShouldEqual, true)
+	})
+}
@@ -0,0 +1,32 @@
+package engine
+
+import (
+	"gitee.com/damengde/runehammer/rule"
+)
+
+// ============================================================================
+// 内置默认规则 - 类库随二进制附带的规则集，运行时可被数据库中的同名规则覆盖
+// ============================================================================
+//
+// 内置规则是规则分层（见 rule_layers.go）的最低层，被数据库规则和运行时覆盖中的同名规则覆盖。
+//
+// 内置规则不写入规则缓存，数据库规则变化后按原有同步机制重新合并编译。
+// 如需停用某条内置规则，在数据库中添加同名的空动作规则即可。
+
+// embeddedRules 业务码 -> 内置默认规则
+type embeddedRules map[string][]*rule.Rule
+
+// SetEmbeddedRules 设置内置默认规则，按业务码分组，重复调用时覆盖之前的设置
+func (e *engineImpl[T]) SetEmbeddedRules(rules []*rule.Rule) {
+	embedded := make(embeddedRules)
+	for _, r := range rules {
+		if r == nil || !r.Enabled {
+			continue
+		}
+		embedded[r.BizCode] = append(embedded[r.BizCode], r)
+	}
+	if len(embedded) == 0 {
+		embedded = nil
+	}
+	e.embedded = embedded
+}
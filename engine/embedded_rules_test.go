@@ -0,0 +1,66 @@
+package engine
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+// TestEmbeddedRules 测试内置默认规则
+func TestEmbeddedRules(t *testing.T) {
+	Convey("内置默认规则", t, func() {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mapper := rule.NewMockRuleMapper(ctrl)
+		mapper.EXPECT().FindByBizCode(gomock.Any(), "loan").Return([]*rule.Rule{
+			{BizCode: "loan", Name: "limit", Enabled: true, Version: 3,
+				GRL: `rule DBLimit "数据库额度" { when true then Result["limit"] = 500; Retract("DBLimit"); }`},
+		}, nil).AnyTimes()
+		mapper.EXPECT().FindByBizCode(gomock.Any(), "quote").Return(nil, nil).AnyTimes()
+
+		engine := NewEngineImpl[map[string]any](
+			config.DefaultConfig(), mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+			ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+		)
+		defer engine.Close()
+
+		engine.SetEmbeddedRules([]*rule.Rule{
+			{BizCode: "loan", Name: "limit", Enabled: true, Version: 1,
+				GRL: `rule Limit "默认额度" { when true then Result["limit"] = 100; Retract("Limit"); }`},
+			{BizCode: "loan", Name: "score", Enabled: true, Version: 1,
+				GRL: `rule Score "默认评分" { when true then Result["score"] = 600; Retract("Score"); }`},
+			{BizCode: "quote", Name: "price", Enabled: true, Version: 1,
+				GRL: `rule Price "默认报价" { when true then Result["price"] = 10; Retract("Price"); }`},
+		})
+
+		Convey("数据库同名规则覆盖内置规则，其余内置规则保留", func() {
+			result, err := engine.Exec(context.Background(), "loan", map[string]any{})
+			So(err, ShouldBeNil)
+			So(result["limit"], ShouldEqual, 500)
+			So(result["score"], ShouldEqual, 600)
+		})
+
+		Convey("数据库没有规则时使用内置规则", func() {
+			result, err := engine.Exec(context.Background(), "quote", map[string]any{})
+			So(err, ShouldBeNil)
+			So(result["price"], ShouldEqual, 10)
+		})
+
+		Convey("只合并同一业务码的内置规则", func() {
+			rules := engine.layerRules("quote", nil)
+			So(rules, ShouldHaveLength, 1)
+			So(rules[0].Name, ShouldEqual, "price")
+			So(engine.layerRules("other", nil), ShouldBeEmpty)
+		})
+	})
+}
@@ -0,0 +1,87 @@
+package engine
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+)
+
+// ============================================================================
+// UUID、哈希与编码函数 - 规则为下游动作构造幂等键、令牌时使用
+// ============================================================================
+
+// newUUIDv4 生成随机UUID（RFC 4122 版本4），如 3f2b8c1e-9a4d-4e7f-b1c2-5d6e7f8a9b0c
+func newUUIDv4() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Errorf("生成UUID失败: %w", err))
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// sha256Hex 字符串的SHA-256摘要，小写十六进制
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// md5Hex 字符串的MD5摘要，小写十六进制 - 仅用于兼容下游系统，不要用于安全场景
+func md5Hex(s string) string {
+	sum := md5.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// base64Encode 标准Base64编码（带填充）
+func base64Encode(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
+
+// base64Decode 标准Base64解码，输入无效时panic，由Grule作为执行错误返回
+func base64Decode(s string) string {
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		panic(fmt.Errorf("Base64Decode: %w", err))
+	}
+	return string(data)
+}
+
+// UUIDv4 生成随机UUID
+func (f *ruleFunctions) UUIDv4() string {
+	return newUUIDv4()
+}
+
+// Sha256Hex SHA-256摘要
+func (f *ruleFunctions) Sha256Hex(s any) string {
+	return sha256Hex(stringArg(s))
+}
+
+// Md5Hex MD5摘要
+func (f *ruleFunctions) Md5Hex(s any) string {
+	return md5Hex(stringArg(s))
+}
+
+// Base64Encode Base64编码
+func (f *ruleFunctions) Base64Encode(s any) string {
+	return base64Encode(stringArg(s))
+}
+
+// Base64Decode Base64解码
+func (f *ruleFunctions) Base64Decode(s any) string {
+	return base64Decode(stringArg(s))
+}
+
+// injectEncodingFunctions 注入UUID、哈希与编码函数
+func injectEncodingFunctions(dataCtx ast.IDataContext) {
+	dataCtx.Add("UUIDv4", newUUIDv4)
+	dataCtx.Add("Sha256Hex", sha256Hex)
+	dataCtx.Add("Md5Hex", md5Hex)
+	dataCtx.Add("Base64Encode", base64Encode)
+	dataCtx.Add("Base64Decode", base64Decode)
+}
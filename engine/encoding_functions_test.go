@@ -0,0 +1,71 @@
+package engine
+
+import (
+	"context"
+	"regexp"
+	"sync"
+	"testing"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+// TestEncodingFunctions 测试UUID、哈希与编码函数
+func TestEncodingFunctions(t *testing.T) {
+	Convey("UUID、哈希与编码函数", t, func() {
+		uuidPattern := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+		Convey("UUIDv4格式正确且每次不同", func() {
+			a, b := newUUIDv4(), newUUIDv4()
+			So(uuidPattern.MatchString(a), ShouldBeTrue)
+			So(a, ShouldNotEqual, b)
+		})
+
+		Convey("摘要", func() {
+			So(sha256Hex("abc"), ShouldEqual, "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad")
+			So(md5Hex("abc"), ShouldEqual, "900150983cd24fb0d6963f7d28e17f72")
+		})
+
+		Convey("Base64编解码", func() {
+			So(base64Encode("订单-1"), ShouldEqual, "6K6i5Y2VLTE=")
+			So(base64Decode("6K6i5Y2VLTE="), ShouldEqual, "订单-1")
+			So(func() { base64Decode("not base64!") }, ShouldPanic)
+		})
+
+		Convey("规则中使用", func() {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mapper := rule.NewMockRuleMapper(ctrl)
+			mapper.EXPECT().FindByBizCode(gomock.Any(), "order").Return([]*rule.Rule{
+				{Name: "key", Enabled: true, GRL: `rule IdempotencyKey "幂等键" {
+	when true
+	then
+		Result["key"] = Sha256Hex(Params["orderId"] + ":" + Params["userId"]);
+		Result["token"] = Base64Encode(Params["orderId"]);
+		Result["decoded"] = Base64Decode(Result["token"]);
+		Result["requestId"] = UUIDv4();
+		Retract("IdempotencyKey");
+}`},
+			}, nil).AnyTimes()
+
+			engine := NewEngineImpl[map[string]any](
+				config.DefaultConfig(), mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+				ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+			)
+			defer engine.Close()
+
+			result, err := engine.Exec(context.Background(), "order", map[string]any{"orderId": "A100", "userId": "u1"})
+			So(err, ShouldBeNil)
+			So(result["key"], ShouldEqual, sha256Hex("A100:u1"))
+			So(result["decoded"], ShouldEqual, "A100")
+			So(uuidPattern.MatchString(result["requestId"].(string)), ShouldBeTrue)
+		})
+	})
+}
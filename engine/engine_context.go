@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
-	"strings"
 
 	"github.com/hyperjumptech/grule-rule-engine/ast"
 )
@@ -35,30 +34,24 @@ func (e *engineImpl[T]) injectInputData(dataCtx ast.IDataContext, input any) err
 		return fmt.Errorf("注入Result变量失败: %w", err)
 	}
 
-	v := reflect.ValueOf(input)
-	t := reflect.TypeOf(input)
+	// 按类型缓存的注入计划，指针已解引用
+	plan, kind := planInput(input)
 
-	// 处理指针类型，获取实际的值和类型
-	if v.Kind() == reflect.Ptr {
-		v = v.Elem()
-		t = t.Elem()
-	}
-
-	switch v.Kind() {
+	switch kind {
 	case reflect.Map:
 		// Map 作为整体注入到 Params，符合 README 约定
 		return e.injectDefaultData(dataCtx, input)
 	case reflect.Struct:
-		return e.injectStructData(dataCtx, input, t)
+		return e.injectStructData(dataCtx, input, plan.name)
 	default:
 		return e.injectDefaultData(dataCtx, input)
 	}
 }
 
 // injectStructData 注入结构体数据 - 将整个结构体作为单个对象注入
-func (e *engineImpl[T]) injectStructData(dataCtx ast.IDataContext, input any, t reflect.Type) error {
-	// 使用结构体类型名作为变量名，转为小写
-	inputName := strings.ToLower(t.Name())
+func (e *engineImpl[T]) injectStructData(dataCtx ast.IDataContext, input any, typeName string) error {
+	// 使用结构体类型名作为变量名（小写）
+	inputName := typeName
 	if inputName == "" {
 		inputName = "Params" // 匿名结构体使用统一的Params名称
 	}
@@ -78,6 +71,20 @@ func (e *engineImpl[T]) injectDefaultData(dataCtx ast.IDataContext, input any) e
 	return nil
 }
 
+// resultMap 获取执行上下文中的Result map
+func resultMap(dataCtx ast.IDataContext) (map[string]interface{}, bool) {
+	resultValue := dataCtx.Get("Result")
+	if resultValue == nil {
+		return nil, false
+	}
+	value, err := resultValue.GetValue()
+	if err != nil {
+		return nil, false
+	}
+	result, ok := value.Interface().(map[string]interface{})
+	return result, ok
+}
+
 // extractResult 提取执行结果 - 从执行上下文中提取result变量并转换为目标类型
 //
 // 支持的结果类型转换:
@@ -0,0 +1,194 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"time"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	"gitee.com/damengde/runehammer/rule"
+)
+
+// ============================================================================
+// 引擎诊断 - 汇总运行时状态，便于排查问题和提交工单
+// ============================================================================
+
+// CompileInfo 知识库编译信息
+type CompileInfo struct {
+	BizCode    string    `json:"biz_code"`    // 业务码
+	Hash       string    `json:"hash"`        // 参与编译的GRL内容哈希
+	RuleCount  int       `json:"rule_count"`  // 编译的规则数
+	Version    int       `json:"version"`     // 规则集版本
+	CompiledAt time.Time `json:"compiled_at"` // 编译时间
+
+	NodeCount      int   `json:"node_count"`      // AST节点数
+	EstimatedBytes int64 `json:"estimated_bytes"` // 估算的内存占用字节数
+	ReusedRules    int   `json:"reused_rules"`    // 增量编译时复用上次编译结果的规则数
+
+	SharedCalls []rule.CommonCall `json:"shared_calls,omitempty"` // 提取为共享事实的公共调用
+	ExecMode    config.ExecMode   `json:"exec_mode,omitempty"`    // 编译时的执行模式，首条命中模式按规则顺序重写了salience
+}
+
+// CronEntryInfo 定时任务信息
+type CronEntryInfo struct {
+	ID   int       `json:"id"`   // 任务ID
+	Next time.Time `json:"next"` // 下次执行时间
+	Prev time.Time `json:"prev"` // 上次执行时间
+}
+
+// DebugSnapshot 诊断快照
+type DebugSnapshot struct {
+	GeneratedAt    time.Time       `json:"generated_at"`    // 生成时间
+	Closed         bool            `json:"closed"`          // 引擎是否已关闭
+	KnowledgeBases []CompileInfo   `json:"knowledge_bases"` // 已编译的知识库
+	Cache          any             `json:"cache"`           // 缓存统计
+	CronEntries    []CronEntryInfo `json:"cron_entries"`    // 定时任务
+	RecentErrors   []ErrorRecord   `json:"recent_errors"`   // 近期错误
+	Metrics        ExecMetrics     `json:"metrics"`         // 执行指标
+	LatencySLOs    []SLOStatus     `json:"latency_slos"`    // 延迟SLO状态
+	Config         map[string]any  `json:"config"`          // 配置快照（已脱敏）
+	Extra          map[string]any  `json:"extra,omitempty"` // 外部注册的诊断信息
+}
+
+// RegisterDiagnostics 注册诊断信息源 - 生成诊断快照时调用fn并以name输出
+func (e *engineImpl[T]) RegisterDiagnostics(name string, fn func() any) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.diagnosticsSources[name] = fn
+}
+
+// DebugDump 输出诊断快照 - 以JSON格式写入w
+//
+// 快照内容:
+//   - 已编译业务码及其GRL哈希
+//   - 缓存统计
+//   - 定时任务
+//   - 近期错误
+//   - 脱敏后的配置
+//   - 外部注册的诊断信息（如数据库连接池）
+func (e *engineImpl[T]) DebugDump(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(e.DebugSnapshot()); err != nil {
+		return fmt.Errorf("写入诊断信息失败: %w", err)
+	}
+	return nil
+}
+
+// DebugSnapshot 生成诊断快照
+//
+// 外部诊断信息源在释放引擎锁之后调用，信息源内可以调用引擎的其他方法
+func (e *engineImpl[T]) DebugSnapshot() DebugSnapshot {
+	snapshot, sources := e.lockedSnapshot()
+
+	// 外部诊断信息
+	if len(sources) > 0 {
+		snapshot.Extra = make(map[string]any, len(sources))
+		for name, fn := range sources {
+			snapshot.Extra[name] = fn()
+		}
+	}
+
+	return snapshot
+}
+
+// lockedSnapshot 在读锁内复制引擎状态和已注册的诊断信息源
+func (e *engineImpl[T]) lockedSnapshot() (DebugSnapshot, map[string]func() any) {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+
+	snapshot := DebugSnapshot{
+		GeneratedAt:    time.Now(),
+		Closed:         e.closed,
+		KnowledgeBases: []CompileInfo{},
+		CronEntries:    []CronEntryInfo{},
+		RecentErrors:   e.RecentErrors(),
+		Metrics:        e.Metrics(),
+		LatencySLOs:    e.SLOStatus(),
+		Config:         e.configSnapshot(),
+	}
+
+	// 已编译知识库
+	e.knowledgeBases.Range(func(key, value interface{}) bool {
+		bizCode, _ := key.(string)
+		info := CompileInfo{BizCode: bizCode}
+		if stored, ok := e.compileInfos.Load(bizCode); ok {
+			info = stored.(CompileInfo)
+		}
+		snapshot.KnowledgeBases = append(snapshot.KnowledgeBases, info)
+		return true
+	})
+	sort.Slice(snapshot.KnowledgeBases, func(i, j int) bool {
+		return snapshot.KnowledgeBases[i].BizCode < snapshot.KnowledgeBases[j].BizCode
+	})
+
+	// 缓存统计
+	switch c := e.cache.(type) {
+	case nil:
+		snapshot.Cache = map[string]any{"enabled": false}
+	case cache.StatsReporter:
+		snapshot.Cache = c.Stats()
+	default:
+		snapshot.Cache = map[string]any{"enabled": true, "type": fmt.Sprintf("%T", c)}
+	}
+
+	// 定时任务
+	for _, entry := range e.scheduler().Entries() {
+		snapshot.CronEntries = append(snapshot.CronEntries, CronEntryInfo{
+			ID:   int(entry.ID),
+			Next: entry.Next,
+			Prev: entry.Prev,
+		})
+	}
+
+	sources := make(map[string]func() any, len(e.diagnosticsSources))
+	for name, fn := range e.diagnosticsSources {
+		sources[name] = fn
+	}
+
+	return snapshot, sources
+}
+
+// dsnPasswordRegex 匹配DSN中的密码部分
+var dsnPasswordRegex = regexp.MustCompile(`:[^:@/]*@`)
+
+// configSnapshot 生成脱敏后的配置快照
+func (e *engineImpl[T]) configSnapshot() map[string]any {
+	if e.config == nil {
+		return nil
+	}
+
+	redisPassword := ""
+	if e.config.RedisPassword != "" {
+		redisPassword = "***"
+	}
+
+	return map[string]any{
+		"dsn":                dsnPasswordRegex.ReplaceAllString(e.config.DSN, ":***@"),
+		"auto_migrate":       e.config.AutoMigrate,
+		"schema_check":       e.config.SchemaCheck,
+		"index_advisor":      e.config.IndexAdvisor,
+		"read_only":          e.config.ReadOnly,
+		"db_max_open_conns":  e.config.DBMaxOpenConns,
+		"db_max_idle_conns":  e.config.DBMaxIdleConns,
+		"db_conn_max_life":   e.config.DBConnMaxLifetime.String(),
+		"slow_query":         e.config.SlowQueryThreshold.String(),
+		"cache_type":         e.config.CacheType,
+		"cache_ttl":          e.config.CacheTTL.String(),
+		"max_cache_size":     e.config.MaxCacheSize,
+		"redis_addr":         e.config.RedisAddr,
+		"redis_password":     redisPassword,
+		"redis_db":           e.config.RedisDB,
+		"sync_interval":      e.config.SyncInterval.String(),
+		"idempotency_window": e.config.IdempotencyWindow.String(),
+		"latency_slo_window": e.config.LatencySLOWindow.String(),
+		"recent_errors_size": e.config.RecentErrorsSize,
+		"rule_page_size":     e.config.RulePageSize,
+		"rule_count_warn":    e.config.RuleCountWarnThreshold,
+	}
+}
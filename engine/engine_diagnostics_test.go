@@ -0,0 +1,100 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+// TestEngineDiagnostics 测试诊断快照
+func TestEngineDiagnostics(t *testing.T) {
+	Convey("诊断快照测试", t, func() {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		cfg := config.DefaultConfig()
+		cfg.DSN = "user:secret@tcp(127.0.0.1:3306)/rules"
+		cfg.RedisPassword = "redis-secret"
+		cfg.SyncInterval = time.Hour
+
+		mapper := rule.NewMockRuleMapper(ctrl)
+		mapper.EXPECT().FindByBizCode(gomock.Any(), "diag_biz").Return([]*rule.Rule{
+			{Name: "R", GRL: `rule DiagRule "诊断" { when Params["x"] > 0 then Result["ok"] = true; Retract("DiagRule"); }`, Version: 2, Enabled: true},
+		}, nil).AnyTimes()
+
+		engine := NewEngineImpl[map[string]any](
+			cfg, mapper, cache.NewMemoryCache(10), cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+			ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+		)
+		So(engine.StartSync(), ShouldBeNil)
+		defer engine.Close()
+
+		_, err := engine.Exec(context.Background(), "diag_biz", map[string]any{"x": 1})
+		So(err, ShouldBeNil)
+
+		engine.RegisterDiagnostics("custom", func() any { return map[string]int{"n": 1} })
+
+		Convey("快照内容", func() {
+			snapshot := engine.DebugSnapshot()
+			So(snapshot.Closed, ShouldBeFalse)
+			So(snapshot.KnowledgeBases, ShouldHaveLength, 1)
+			So(snapshot.KnowledgeBases[0].BizCode, ShouldEqual, "diag_biz")
+			So(snapshot.KnowledgeBases[0].Hash, ShouldHaveLength, 64)
+			So(snapshot.KnowledgeBases[0].RuleCount, ShouldEqual, 1)
+			So(snapshot.KnowledgeBases[0].Version, ShouldEqual, 2)
+			So(snapshot.CronEntries, ShouldHaveLength, 1)
+			So(snapshot.Extra["custom"], ShouldNotBeNil)
+
+			stats, ok := snapshot.Cache.(cache.Stats)
+			So(ok, ShouldBeTrue)
+			So(stats.Misses, ShouldBeGreaterThan, 0)
+		})
+
+		Convey("诊断信息源在释放引擎锁后调用", func() {
+			engine.RegisterDiagnostics("reentrant", func() any {
+				// 注册需要写锁，在持有读锁时调用会死锁
+				engine.RegisterDiagnostics("late", func() any { return "late" })
+				return "ok"
+			})
+
+			done := make(chan DebugSnapshot, 1)
+			go func() { done <- engine.DebugSnapshot() }()
+
+			select {
+			case snapshot := <-done:
+				So(snapshot.Extra["reentrant"], ShouldEqual, "ok")
+				So(snapshot.Extra, ShouldNotContainKey, "late")
+			case <-time.After(2 * time.Second):
+				So("诊断快照死锁", ShouldBeEmpty)
+			}
+		})
+
+		Convey("敏感配置脱敏", func() {
+			snapshot := engine.DebugSnapshot()
+			So(snapshot.Config["dsn"], ShouldEqual, "user:***@tcp(127.0.0.1:3306)/rules")
+			So(snapshot.Config["redis_password"], ShouldEqual, "***")
+		})
+
+		Convey("输出JSON", func() {
+			var buf bytes.Buffer
+			So(engine.DebugDump(&buf), ShouldBeNil)
+			So(buf.String(), ShouldNotContainSubstring, "secret")
+
+			var decoded map[string]any
+			So(json.Unmarshal(buf.Bytes(), &decoded), ShouldBeNil)
+			So(decoded["knowledge_bases"], ShouldNotBeNil)
+		})
+	})
+}
@@ -0,0 +1,120 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// ============================================================================
+// 降级结果 - 规则缺失或编译失败时返回预先配置的结果
+// ============================================================================
+
+// FallbackProvider 降级结果提供者
+//
+// 当业务码无可用规则（加载失败、规则为空）或规则编译失败时调用，
+// 返回 ok=true 表示使用 value 作为本次执行结果，不再返回错误。
+type FallbackProvider interface {
+	Fallback(ctx context.Context, bizCode string, cause error) (value any, ok bool)
+}
+
+// FallbackFunc 函数形式的降级结果提供者
+type FallbackFunc func(ctx context.Context, bizCode string, cause error) (any, bool)
+
+// Fallback 实现FallbackProvider接口
+func (f FallbackFunc) Fallback(ctx context.Context, bizCode string, cause error) (any, bool) {
+	return f(ctx, bizCode, cause)
+}
+
+// staticFallbackProvider 按业务码配置的静态降级结果 - 每次返回深拷贝，调用方修改结果不影响其他执行
+type staticFallbackProvider struct {
+	results map[string]any
+	next    FallbackProvider
+}
+
+// NewStaticFallbackProvider 创建静态降级结果提供者
+//
+// 参数:
+//
+//	results - 业务码到降级结果的映射
+//	next    - 未配置静态结果时继续询问的提供者，可为nil
+//
+// 创建时复制results，之后修改传入的映射不影响提供者；每次降级返回结果的深拷贝（拷贝规则同 CopyInput）。
+func NewStaticFallbackProvider(results map[string]any, next FallbackProvider) FallbackProvider {
+	copied := make(map[string]any, len(results))
+	for bizCode, value := range results {
+		copied[bizCode] = copyInput(value)
+	}
+	return &staticFallbackProvider{results: copied, next: next}
+}
+
+// Fallback 实现FallbackProvider接口
+func (p *staticFallbackProvider) Fallback(ctx context.Context, bizCode string, cause error) (any, bool) {
+	if value, ok := p.results[bizCode]; ok {
+		return copyInput(value), true
+	}
+	if p.next != nil {
+		return p.next.Fallback(ctx, bizCode, cause)
+	}
+	return nil, false
+}
+
+// SetFallbackProvider 设置降级结果提供者
+func (e *engineImpl[T]) SetFallbackProvider(provider FallbackProvider) {
+	e.fallbackProvider = provider
+}
+
+// fallback 尝试返回降级结果
+//
+// 返回值:
+//
+//	T    - 降级结果
+//	bool - 是否成功降级
+func (e *engineImpl[T]) fallback(ctx context.Context, bizCode string, cause error, options *ExecOptions) (T, bool) {
+	var zero T
+
+	if e.fallbackProvider == nil {
+		return zero, false
+	}
+
+	value, ok := e.fallbackProvider.Fallback(ctx, bizCode, cause)
+	if !ok {
+		return zero, false
+	}
+
+	result, err := convertFallbackValue[T](value)
+	if err != nil {
+		if e.logger != nil {
+			e.logger.Errorf(ctx, "降级结果类型转换失败", "bizCode", bizCode, "error", err)
+		}
+		return zero, false
+	}
+
+	if e.logger != nil {
+		e.logger.Warnf(ctx, "返回降级结果", "bizCode", bizCode, "cause", cause)
+	}
+
+	if options.Report != nil {
+		options.Report.Degraded = true
+		options.Report.DegradedReason = cause
+	}
+
+	return result, true
+}
+
+// convertFallbackValue 将降级结果转换为目标类型，类型不一致时通过JSON转换
+func convertFallbackValue[T any](value any) (T, error) {
+	var result T
+
+	if typed, ok := value.(T); ok {
+		return typed, nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return result, err
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return result, err
+	}
+	return result, nil
+}
@@ -0,0 +1,110 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+// TestEngineFallback 测试降级结果
+func TestEngineFallback(t *testing.T) {
+	Convey("降级结果测试", t, func() {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mapper := rule.NewMockRuleMapper(ctrl)
+		engine := NewEngineImpl[map[string]any](
+			config.DefaultConfig(), mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+			ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+		)
+		engine.SetFallbackProvider(NewStaticFallbackProvider(map[string]any{
+			"open_biz": map[string]any{"approved": true},
+		}, FallbackFunc(func(ctx context.Context, bizCode string, cause error) (any, bool) {
+			if bizCode == "closed_biz" {
+				return struct {
+					Approved bool `json:"approved"`
+				}{Approved: false}, true
+			}
+			return nil, false
+		})))
+		ctx := context.Background()
+
+		Convey("无规则时返回静态降级结果", func() {
+			mapper.EXPECT().FindByBizCode(gomock.Any(), "open_biz").Return([]*rule.Rule{}, nil)
+
+			var report ExecReport
+			result, err := engine.Exec(ctx, "open_biz", map[string]any{}, WithExecReport(&report))
+			So(err, ShouldBeNil)
+			So(result["approved"], ShouldEqual, true)
+			So(report.Degraded, ShouldBeTrue)
+			So(report.DegradedReason, ShouldNotBeNil)
+		})
+
+		Convey("每次执行返回独立的静态降级结果", func() {
+			mapper.EXPECT().FindByBizCode(gomock.Any(), "open_biz").Return([]*rule.Rule{}, nil).Times(2)
+
+			first, err := engine.Exec(ctx, "open_biz", map[string]any{})
+			So(err, ShouldBeNil)
+			first["approved"] = false
+			first["tampered"] = true
+
+			second, err := engine.Exec(ctx, "open_biz", map[string]any{})
+			So(err, ShouldBeNil)
+			So(second["approved"], ShouldEqual, true)
+			So(second, ShouldNotContainKey, "tampered")
+		})
+
+		Convey("创建后修改配置的映射不影响降级结果", func() {
+			results := map[string]any{"biz": map[string]any{"limit": 100}}
+			provider := NewStaticFallbackProvider(results, nil)
+			results["biz"].(map[string]any)["limit"] = 0
+			results["other"] = map[string]any{}
+
+			value, ok := provider.Fallback(ctx, "biz", nil)
+			So(ok, ShouldBeTrue)
+			So(value.(map[string]any)["limit"], ShouldEqual, 100)
+			_, ok = provider.Fallback(ctx, "other", nil)
+			So(ok, ShouldBeFalse)
+		})
+
+		Convey("加载失败时询问降级提供者并转换类型", func() {
+			mapper.EXPECT().FindByBizCode(gomock.Any(), "closed_biz").Return(nil, fmt.Errorf("db down"))
+
+			var report ExecReport
+			result, err := engine.Exec(ctx, "closed_biz", map[string]any{}, WithExecReport(&report))
+			So(err, ShouldBeNil)
+			So(result["approved"], ShouldEqual, false)
+			So(report.Degraded, ShouldBeTrue)
+			So(report.DegradedReason.Error(), ShouldContainSubstring, "db down")
+		})
+
+		Convey("编译失败时返回降级结果", func() {
+			mapper.EXPECT().FindByBizCode(gomock.Any(), "open_biz").Return([]*rule.Rule{
+				{Name: "bad", GRL: "rule Broken {", Enabled: true},
+			}, nil)
+
+			result, err := engine.Exec(ctx, "open_biz", map[string]any{})
+			So(err, ShouldBeNil)
+			So(result["approved"], ShouldEqual, true)
+		})
+
+		Convey("未配置降级的业务码仍返回错误", func() {
+			mapper.EXPECT().FindByBizCode(gomock.Any(), "other_biz").Return([]*rule.Rule{}, nil)
+
+			var report ExecReport
+			_, err := engine.Exec(ctx, "other_biz", map[string]any{}, WithExecReport(&report))
+			So(err, ShouldNotBeNil)
+			So(report.Degraded, ShouldBeFalse)
+		})
+	})
+}
@@ -42,19 +42,52 @@ func (e *engineImpl[T]) injectBuiltinFunctions(dataCtx ast.IDataContext) {
 	
 	// 注入验证函数
 	e.injectValidationFunctions(dataCtx)
+
+	// 注入路径访问函数
+	injectPathFunctions(dataCtx)
+
+	// 注入结果合并函数
+	injectMergeFunctions(dataCtx)
+
+	// 注入区域格式化函数
+	e.injectLocaleFunctions(dataCtx)
+
+	// 注入JSON函数
+	injectJSONFunctions(dataCtx)
+
+	// 注入字符串相似度函数
+	e.injectSimilarityFunctions(dataCtx)
+
+	// 注入集合排序与分组函数
+	injectSortFunctions(dataCtx)
+
+	// 注入UUID、哈希与编码函数
+	injectEncodingFunctions(dataCtx)
+
+	// 注入区间分桶函数
+	injectBucketFunctions(dataCtx)
+
+	// 注入安全数学函数
+	injectSafeMathFunctions(dataCtx)
+
+	// 注入向量函数
+	injectVectorFunctions(dataCtx)
+
+	// 注入集合聚合函数
+	injectAggregateFunctions(dataCtx)
 }
 
 // injectTimeFunctions 注入时间函数
 func (e *engineImpl[T]) injectTimeFunctions(dataCtx ast.IDataContext) {
 	// 获取当前时间
+	clock := clockOrSystem(e.clock)
 	dataCtx.Add("Now", func() time.Time {
-		return time.Now()
+		return clock.Now()
 	})
 	
 	// 获取今天的开始时间（00:00:00）
 	dataCtx.Add("Today", func() time.Time {
-		now := time.Now()
-		return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		return startOfDay(clock.Now())
 	})
 	
 	// 格式化时间
@@ -78,7 +111,7 @@ func (e *engineImpl[T]) injectTimeFunctions(dataCtx ast.IDataContext) {
 	
 	// 毫秒时间戳相关函数
 	dataCtx.Add("NowMillis", func() int64 {
-		return time.Now().UnixMilli()
+		return clock.Now().UnixMilli()
 	})
 	
 	dataCtx.Add("TimeToMillis", func(t time.Time) int64 {
@@ -0,0 +1,93 @@
+package engine
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gitee.com/damengde/runehammer/rule"
+)
+
+// ============================================================================
+// 幂等结果存储 - 在配置的窗口期内复用同一幂等键的执行结果
+// ============================================================================
+
+// idempotencyEnabled 判断本次执行是否启用幂等
+func (e *engineImpl[T]) idempotencyEnabled(options *ExecOptions) bool {
+	return options.IdempotencyKey != "" && e.cache != nil && e.config.IdempotencyWindow > 0
+}
+
+// loadIdempotentResult 读取已存储的幂等结果
+//
+// 返回值:
+//
+//	T    - 已存储的结果
+//	bool - 是否命中
+func (e *engineImpl[T]) loadIdempotentResult(ctx context.Context, bizCode string, ruleSet string, key string) (T, bool) {
+	var zero T
+
+	data, err := e.cache.Get(ctx, e.cacheKeys.IdempotencyKey(bizCode, ruleSet, key))
+	if err != nil {
+		return zero, false
+	}
+
+	var result T
+	if err := json.Unmarshal(data, &result); err != nil {
+		if e.logger != nil {
+			e.logger.Warnf(ctx, "幂等结果反序列化失败", "bizCode", bizCode, "key", key, "error", err)
+		}
+		return zero, false
+	}
+
+	return result, true
+}
+
+// storeIdempotentResult 存储幂等结果，窗口期由配置决定
+func (e *engineImpl[T]) storeIdempotentResult(ctx context.Context, bizCode string, ruleSet string, key string, result T) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		if e.logger != nil {
+			e.logger.Warnf(ctx, "幂等结果序列化失败", "bizCode", bizCode, "key", key, "error", err)
+		}
+		return
+	}
+
+	cacheKey := e.cacheKeys.IdempotencyKey(bizCode, ruleSet, key)
+	if err := e.cache.Set(ctx, cacheKey, data, e.config.IdempotencyWindow); err != nil && e.logger != nil {
+		e.logger.Warnf(ctx, "幂等结果存储失败", "bizCode", bizCode, "key", key, "error", err)
+	}
+}
+
+// ruleSetVersion 计算规则集版本 - 取所有规则中的最大版本号，只用于展示和统计，规则集的标识见 ruleSetHash
+func ruleSetVersion(rules []*rule.Rule) int {
+	version := 0
+	for _, r := range rules {
+		if r != nil && r.Version > version {
+			version = r.Version
+		}
+	}
+	return version
+}
+
+// ruleSetHash 计算规则集内容哈希 - 按规则名称、启用状态、放量比例、版本号和GRL计算
+//
+// 分页获取的规则不含GRL，以规则ID、更新时间和声明的规则条目代表内容。
+func ruleSetHash(rules []*rule.Rule) string {
+	h := sha256.New()
+	for _, r := range rules {
+		if r == nil {
+			continue
+		}
+		fmt.Fprintf(h, "%d\x00%s\x00%t\x00%d\x00%d\x00", r.ID, r.Name, r.Enabled, r.RolloutPercent, r.Version)
+		if stripped(r) {
+			fmt.Fprintf(h, "%d\x00%s", r.UpdatedAt.UnixNano(), strings.Join(r.Entries, ","))
+		} else {
+			h.Write([]byte(r.GRL))
+		}
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
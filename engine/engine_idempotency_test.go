@@ -0,0 +1,98 @@
+package engine
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+// TestEngineIdempotency 测试幂等执行
+func TestEngineIdempotency(t *testing.T) {
+	Convey("幂等执行测试", t, func() {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		rules := []*rule.Rule{
+			{
+				ID:      1,
+				BizCode: "idem_biz",
+				Name:    "成年检查",
+				GRL:     `rule AdultRule "成年检查" { when Params["age"] >= 18 then Result["adult"] = true; Retract("AdultRule"); }`,
+				Version: 3,
+				Enabled: true,
+			},
+		}
+
+		cfg := config.DefaultConfig()
+		mapper := rule.NewMockRuleMapper(ctrl)
+		mapper.EXPECT().FindByBizCode(gomock.Any(), "idem_biz").Return(rules, nil).AnyTimes()
+		memCache := cache.NewMemoryCache(100)
+
+		engine := NewEngineImpl[map[string]any](
+			cfg, mapper, memCache, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+			ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+		)
+		defer engine.Close()
+
+		ctx := context.Background()
+
+		Convey("相同幂等键返回已存储的结果", func() {
+			first, err := engine.Exec(ctx, "idem_biz", map[string]any{"age": 25}, WithIdempotencyKey("req-1"))
+			So(err, ShouldBeNil)
+			So(first["adult"], ShouldEqual, true)
+
+			// 输入变化但幂等键相同，仍返回首次结果
+			second, err := engine.Exec(ctx, "idem_biz", map[string]any{"age": 10}, WithIdempotencyKey("req-1"))
+			So(err, ShouldBeNil)
+			So(second["adult"], ShouldEqual, true)
+
+			// 结果按规则集内容哈希存储
+			_, err = memCache.Get(ctx, cache.CacheKeyBuilder{}.IdempotencyKey("idem_biz", ruleSetHash(rules), "req-1"))
+			So(err, ShouldBeNil)
+		})
+
+		Convey("不同幂等键或无幂等键时重新执行", func() {
+			_, err := engine.Exec(ctx, "idem_biz", map[string]any{"age": 25}, WithIdempotencyKey("req-2"))
+			So(err, ShouldBeNil)
+
+			other, err := engine.Exec(ctx, "idem_biz", map[string]any{"age": 10}, WithIdempotencyKey("req-3"))
+			So(err, ShouldBeNil)
+			So(other["adult"], ShouldBeNil)
+
+			plain, err := engine.Exec(ctx, "idem_biz", map[string]any{"age": 10})
+			So(err, ShouldBeNil)
+			So(plain["adult"], ShouldBeNil)
+		})
+
+		Convey("窗口关闭时不复用结果", func() {
+			cfg.IdempotencyWindow = 0
+			_, err := engine.Exec(ctx, "idem_biz", map[string]any{"age": 25}, WithIdempotencyKey("req-4"))
+			So(err, ShouldBeNil)
+
+			result, err := engine.Exec(ctx, "idem_biz", map[string]any{"age": 10}, WithIdempotencyKey("req-4"))
+			So(err, ShouldBeNil)
+			So(result["adult"], ShouldBeNil)
+		})
+
+		Convey("窗口过期后重新执行", func() {
+			cfg.IdempotencyWindow = 20 * time.Millisecond
+			_, err := engine.Exec(ctx, "idem_biz", map[string]any{"age": 25}, WithIdempotencyKey("req-5"))
+			So(err, ShouldBeNil)
+
+			time.Sleep(40 * time.Millisecond)
+			result, err := engine.Exec(ctx, "idem_biz", map[string]any{"age": 10}, WithIdempotencyKey("req-5"))
+			So(err, ShouldBeNil)
+			So(result["adult"], ShouldBeNil)
+		})
+	})
+}
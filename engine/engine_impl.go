@@ -2,20 +2,24 @@ package engine
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"reflect"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"gitee.com/damengde/runehammer/alert"
 	"gitee.com/damengde/runehammer/cache"
 	"gitee.com/damengde/runehammer/config"
+	"gitee.com/damengde/runehammer/lock"
 	logger "gitee.com/damengde/runehammer/logger"
 	"gitee.com/damengde/runehammer/rule"
 	"github.com/hyperjumptech/grule-rule-engine/ast"
-	"github.com/hyperjumptech/grule-rule-engine/builder"
 	grengine "github.com/hyperjumptech/grule-rule-engine/engine"
-	"github.com/hyperjumptech/grule-rule-engine/pkg"
 	"github.com/robfig/cron/v3"
 )
 
@@ -35,25 +39,79 @@ type engineImpl[T any] struct {
 	// Grule引擎相关
 	knowledgeLibrary *ast.KnowledgeLibrary // Grule知识库
 	knowledgeBases   *sync.Map             // 编译后的知识库缓存
+	fragments        *sync.Map             // 业务码 -> 增量编译保留的知识库蓝本，只在持有mutex时访问
+	prebuilt         *sync.Map             // 业务码 -> 登记的预编译知识库实例
+	bizCodes         *sync.Map             // 已获取过规则的业务码，用于按模式刷新
+	versions         *sync.Map             // 业务码 -> 保留的规则集版本，用于固定版本执行
+	selectors        *sync.Map             // 选择器表达式 -> 编译后的规则选择器
+	selectorCount    atomic.Int64          // 已缓存的选择器数量
+
+	// 可选扩展
+	fallbackProvider FallbackProvider      // 降级结果提供者
+	decisionExporter DecisionStatsExporter // 决策分布导出器
+	anomalyAlerter   AnomalyAlerter        // 决策分布异常告警回调
+	deadRuleReporter DeadRuleReporter      // 失效规则报告回调
+	quotaProvider    QuotaProvider         // 配额提供者
+	usageReporter    UsageReporter         // 用量上报
+	retention        retentionState        // 数据清理任务状态
+	alertSink        alert.Sink            // 规则告警通道
+	clock            Clock                 // 规则时间函数使用的时钟，为nil时使用系统时间
+	rates            *rateCache            // 汇率缓存，未设置汇率提供者时为nil
+	calendar         CalendarProvider      // 节假日日历提供者
+	references       *rule.ReferenceData   // 规则引用的枚举与参考数据表
+	embedded         embeddedRules         // 内置默认规则，数据库同名规则优先
+	overrides        ruleOverrides         // 运行时覆盖规则，优先于数据库规则
+	peers            instanceSync          // 实例间同步（运行时覆盖、紧急停用）
+	killSwitch       killSwitch            // 已紧急停用的业务码
+	auditRecorder    AuditRecorder         // 运维操作审计记录
+	sloAlerter       SLOAlerter            // 延迟SLO告警回调
+	taskLock         *lock.Guard           // 分布式任务锁，为nil时周期任务在每个实例执行
+	stopInvalidation func()                // 停止监听上游数据变更，未设置变更来源时为nil
+
+	// 诊断信息
+	metrics            *execMetrics          // 执行指标
+	recentErrors       *errorRing            // 近期错误环形缓冲
+	compileInfos       *sync.Map             // 业务码 -> 编译信息
+	decisionStats      *sync.Map             // 业务码 -> 决策分布累计器
+	ruleActivity       *sync.Map             // 业务码 -> 规则命中记录，用于失效规则检测
+	sloTrackers        *sync.Map             // 业务码 -> 延迟SLO统计
+	diagnosticsSources map[string]func() any // 外部注册的诊断信息源
+
+	// 执行中间件
+	middlewares []ExecMiddleware // 已注册的中间件，先注册的在外层
+	handler     ExecFunc         // 中间件包裹后的执行函数，未注册中间件时为nil
 
 	// 系统状态管理
-	cron   *cron.Cron   // 定时任务调度器
-	closed bool         // 引擎是否已关闭
-	mutex  sync.RWMutex // 读写锁保护
+	cron     *cron.Cron   // 定时任务调度器，未传入时在首次使用时创建
+	cronOnce sync.Once    // 保证调度器只创建一次
+	closed   bool         // 引擎是否已关闭
+	mutex    sync.RWMutex // 读写锁保护
 }
 
 // NewEngineImpl 创建引擎实例
+//
+// 协作者均可为nil：cfg使用 config.DefaultConfig()，logger使用空日志，知识库和知识库缓存新建，
+// 定时任务调度器在首次使用时创建；mapper为nil时获取规则返回 ErrNoRuleMapper，cache为nil时不缓存。
 func NewEngineImpl[T any](
 	cfg *config.Config, // 使用config包的Config类型
 	mapper rule.RuleMapper,
 	cache cache.Cache,
 	cacheKeys cache.CacheKeyBuilder,
-	logger logger.Logger,
+	log logger.Logger,
 	knowledgeLibrary *ast.KnowledgeLibrary,
 	knowledgeBases *sync.Map,
 	cron *cron.Cron,
 	closed bool,
 ) *engineImpl[T] {
+	if cfg == nil {
+		cfg = config.DefaultConfig()
+	}
+	if log == nil {
+		log = logger.NewNoopLogger()
+	}
+	if knowledgeLibrary == nil {
+		knowledgeLibrary = ast.NewKnowledgeLibrary()
+	}
 	if knowledgeBases == nil {
 		knowledgeBases = &sync.Map{}
 	}
@@ -63,113 +121,358 @@ func NewEngineImpl[T any](
 		mapper:           mapper,
 		cache:            cache,
 		cacheKeys:        cacheKeys,
-		logger:           logger,
+		logger:           log,
 		knowledgeLibrary: knowledgeLibrary,
 		knowledgeBases:   knowledgeBases,
+		fragments:        &sync.Map{},
+		prebuilt:         &sync.Map{},
+		bizCodes:         &sync.Map{},
 		cron:             cron,
 		closed:           closed,
 		mutex:            sync.RWMutex{},
+
+		metrics:            &execMetrics{},
+		recentErrors:       newErrorRing(recentErrorsSize(cfg)),
+		compileInfos:       &sync.Map{},
+		decisionStats:      &sync.Map{},
+		ruleActivity:       &sync.Map{},
+		sloTrackers:        &sync.Map{},
+		versions:           &sync.Map{},
+		selectors:          &sync.Map{},
+		references:         rule.NewReferenceData(),
+		diagnosticsSources: make(map[string]func() any),
+	}
+}
+
+// scheduler 获取定时任务调度器，未传入时创建
+func (e *engineImpl[T]) scheduler() *cron.Cron {
+	e.cronOnce.Do(func() {
+		if e.cron == nil {
+			e.cron = cron.New()
+		}
+	})
+	return e.cron
+}
+
+// recentErrorsSize 获取近期错误缓冲容量
+func recentErrorsSize(cfg *config.Config) int {
+	if cfg == nil {
+		return 0
 	}
+	return cfg.RecentErrorsSize
 }
 
-// Exec 规则执行器的核心方法 - 根据业务码执行对应的GRL规则集
-func (e *engineImpl[T]) Exec(ctx context.Context, bizCode string, input any) (T, error) {
+// exec 规则执行管线 - 根据业务码执行对应的GRL规则集，位于执行中间件的最内层
+func (e *engineImpl[T]) exec(ctx context.Context, bizCode string, input any, opts ...ExecOption) (_ T, err error) {
 	var zero T
+	options := newExecOptions(opts)
+	defer func() { err = e.localizeError(bizCode, err) }()
 
 	// 1. 检查引擎状态
 	e.mutex.RLock()
 	if e.closed {
 		e.mutex.RUnlock()
-		return zero, fmt.Errorf("未定义错误: 引擎已关闭")
+		return zero, fmt.Errorf("未定义错误: %w", ErrEngineClosed)
 	}
 	e.mutex.RUnlock()
 
 	// 2. 参数验证
 	if strings.TrimSpace(bizCode) == "" {
-		return zero, fmt.Errorf("未定义错误: 无效的业务码")
+		return zero, fmt.Errorf("未定义错误: %w", ErrInvalidBizCode)
 	}
 	if input == nil {
-		return zero, fmt.Errorf("未定义错误: 输入参数为空")
+		return zero, fmt.Errorf("未定义错误: %w", ErrNilInput)
 	}
-
-	// 3. 获取规则
-	rules, err := e.getRules(ctx, bizCode)
-	if err != nil {
-		if e.logger != nil {
-			e.logger.Errorf(ctx, "获取规则失败", "bizCode", bizCode, "error", err)
+	if err := e.checkDisabled(bizCode); err != nil {
+		if result, ok := e.fallback(ctx, bizCode, err, options); ok {
+			return result, nil
+		}
+		return zero, err
+	}
+	if err := e.checkQuota(ctx, options.Tenant, bizCode); err != nil {
+		return zero, err
+	}
+	var rules []*rule.Rule
+	if _, ok := e.latencySLO(bizCode); ok {
+		start := time.Now()
+		defer func() { e.recordLatency(ctx, bizCode, time.Since(start), rules) }()
+	}
+	var meter *usageMeter
+	if e.usageReporter != nil {
+		meter = newUsageMeter(options.Tenant, bizCode)
+		// 上报的错误与返回给调用方的错误一致
+		defer func() {
+			err = e.localizeError(bizCode, err)
+			e.reportUsage(ctx, meter, err)
+		}()
+	}
+	var selector *rule.RuleSelector
+	if options.Selector != "" {
+		parsed, err := e.ruleSelector(options.Selector)
+		if err != nil {
+			return zero, err
+		}
+		selector = parsed
+		// 不同选择器的执行结果不同，幂等结果按选择器区分
+		if options.IdempotencyKey != "" {
+			options.IdempotencyKey += "|" + options.Selector
 		}
-		// 返回空结果而不是nil
-		return e.createEmptyResult(), fmt.Errorf("未定义错误: 规则未找到")
 	}
 
-	if len(rules) == 0 {
-		if e.logger != nil {
-			e.logger.Warnf(ctx, "未找到有效规则", "bizCode", bizCode)
+	// 3. 获取规则（启用层级继承时合并父级业务码规则），登记了预编译知识库的业务码直接使用该知识库
+	knowledgeBase, prebuilt := e.prebuiltKnowledgeBase(bizCode)
+	if prebuilt {
+		if err := e.checkPrebuiltExecMode(bizCode); err != nil {
+			return zero, err
+		}
+	} else {
+		rules, err = e.resolveRules(ctx, bizCode)
+		if err != nil {
+			if e.logger != nil {
+				e.logger.Errorf(ctx, "获取规则失败", "bizCode", bizCode, "error", err)
+			}
+			e.recordError(ctx, bizCode, ErrorClassFetch, err)
+			if result, ok := e.fallback(ctx, bizCode, err, options); ok {
+				return result, nil
+			}
+			// 返回空结果而不是nil
+			return e.createEmptyResult(), fmt.Errorf("未定义错误: %w", ErrRulesNotFound)
+		}
+
+		if len(rules) == 0 {
+			if e.logger != nil {
+				e.logger.Warnf(ctx, "未找到有效规则", "bizCode", bizCode)
+			}
+			if result, ok := e.fallback(ctx, bizCode, fmt.Errorf("未找到有效规则"), options); ok {
+				return result, nil
+			}
+			// 返回空结果而不是nil
+			return e.createEmptyResult(), fmt.Errorf("未定义错误: %w", ErrRulesNotFound)
 		}
-		// 返回空结果而不是nil
-		return e.createEmptyResult(), fmt.Errorf("未定义错误: 规则未找到")
 	}
 
-	// 4. 编译规则
-	knowledgeBase, err := e.compileRules(bizCode, rules)
-	if err != nil {
-		if e.logger != nil {
-			e.logger.Errorf(ctx, "规则编译失败", "bizCode", bizCode, "error", err)
+	// 固定版本执行：请求的版本不是最新版本时使用保留的历史版本
+	version, hash := ruleSetVersion(rules), ruleSetHash(rules)
+	if pinnedVersion(options, version, hash) {
+		retained, ok := e.loadVersion(bizCode, options)
+		if !ok {
+			return zero, e.versionNotFound(options, version)
 		}
-		return zero, fmt.Errorf("规则编译失败: %w", err)
+		rules, version, hash, knowledgeBase = retained.rules, retained.version, retained.hash, retained.kb
+	}
+	if options.Report != nil {
+		options.Report.RuleSetVersion = version
+		options.Report.RuleSetHash = hash
+	}
+	if meter != nil {
+		meter.usage.RuleSetVersion = version
 	}
 
-	// 5. 创建数据上下文和规则引擎
-	dataCtx := ast.NewDataContext()
-	ruleEngine := grengine.NewGruleEngine()
+	// 幂等：窗口期内直接返回已存储的结果
+	idempotent := e.idempotencyEnabled(options)
+	if idempotent {
+		if result, ok := e.loadIdempotentResult(ctx, bizCode, hash, options.IdempotencyKey); ok {
+			if e.logger != nil {
+				e.logger.Debugf(ctx, "命中幂等结果", "bizCode", bizCode, "key", options.IdempotencyKey)
+			}
+			return result, nil
+		}
+	}
 
-	// 6. 注入输入数据
-	if err := e.injectInputData(dataCtx, input); err != nil {
-		if e.logger != nil {
-			e.logger.Errorf(ctx, "数据注入失败", "bizCode", bizCode, "error", err)
+	// 4. 编译规则
+	if knowledgeBase == nil {
+		knowledgeBase, err = e.compileRules(ctx, bizCode, rules)
+		if err != nil {
+			if e.logger != nil {
+				e.logger.Errorf(ctx, "规则编译失败", "bizCode", bizCode, "error", err)
+			}
+			e.recordError(ctx, bizCode, ErrorClassCompile, err)
+			if result, ok := e.fallback(ctx, bizCode, err, options); ok {
+				return result, nil
+			}
+			return zero, fmt.Errorf("%w: %w", ErrCompileFailed, err)
 		}
-		return zero, fmt.Errorf("数据注入失败: %w", err)
 	}
 
-	// 7. 注入内置函数
-	e.injectBuiltinFunctions(dataCtx)
+	// 5. 创建数据上下文，注入输入数据和内置函数（会话执行复用会话中已填充的数据上下文）
+	dataCtx, input, err := e.dataContext(ctx, bizCode, input, options)
+	if err != nil {
+		return zero, err
+	}
+	if err := injectExecParams(dataCtx, options.Params); err != nil {
+		return zero, fmt.Errorf("%w: %w", ErrInjectFailed, err)
+	}
 
-	// 8. 执行规则
+	// 6. 执行规则
 	if knowledgeBase == nil {
 		if e.logger != nil {
 			e.logger.Errorf(ctx, "知识库为空", "bizCode", bizCode)
 		}
 		return zero, fmt.Errorf("知识库为空")
 	}
+	instance, err := executionInstance(knowledgeBase)
+	if err != nil {
+		e.recordError(ctx, bizCode, ErrorClassExecution, err)
+		return zero, fmt.Errorf("%w: %w", ErrExecFailed, err)
+	}
+
+	var fieldErrors *FieldErrorCollector
+	if e.fieldErrorsEnabled() {
+		if fieldErrors, err = injectFieldErrors(dataCtx, instance); err != nil {
+			return zero, fmt.Errorf("%w: %w", ErrInjectFailed, err)
+		}
+	}
+
+	var listeners []grengine.GruleEngineListener
+	excluded := e.rolloutExclusions(rules, input)
+	if selector != nil {
+		excluded = append(excluded, selectorExclusions(selector, rules)...)
+	}
+	if len(excluded) > 0 {
+		listeners = append(listeners, &retractGate{kb: instance, names: excluded})
+	}
+	if gate := newSingleMatchGate(e.ExecMode(bizCode), dataCtx); gate != nil {
+		listeners = append(listeners, gate)
+	}
+	var fires *fireRecorder
+	if e.decisionStatsEnabled() || e.deadRulesEnabled() {
+		fires = &fireRecorder{}
+		listeners = append(listeners, fires)
+	}
+
+	if meter != nil {
+		listeners = append(listeners, meter)
+	}
 
-	if err := ruleEngine.Execute(dataCtx, knowledgeBase); err != nil {
+	var profiler *profileRecorder
+	if options.Profile && options.Report != nil {
+		profiler = newProfileRecorder()
+		listeners = append(listeners, profiler)
+	}
+	var journal *journalRecorder
+	if options.Journal && options.Report != nil {
+		journal = newJournalRecorder(dataCtx)
+		listeners = append(listeners, journal)
+	}
+
+	functions := e.ruleFunctions(ctx, bizCode)
+	functions.sink = e.alertSink
+	functions.emitter.stream = options.Stream
+	listeners = append(listeners, functions)
+
+	owners := ruleOwners(rules)
+	if fieldErrors != nil && owners != nil {
+		fieldErrors.owner = func() (ruleOwnership, bool) {
+			owner, ok := owners[functions.rule]
+			return owner, ok
+		}
+	}
+
+	failOnCond := e.NullPolicy(bizCode) == config.NullPolicyError
+	err = safeExecute(ctx, functions.wrap(dataCtx), instance, bizCode, e.metrics, failOnCond, listeners...)
+	if guardErr := functions.guard.Err(); guardErr != nil {
+		err = guardErr
+	}
+	if err != nil {
+		err = withRuleOwner(err, owners, failedRule(err, functions))
+	}
+	if profiler != nil {
+		options.Report.Profile = profiler.finish()
+	}
+	if journal != nil {
+		options.Report.Journal = journal.finish()
+	}
+	functions.emitter.report(options.Report)
+	if err != nil {
+		var panicErr *RulePanicError
+		if errors.As(err, &panicErr) {
+			if e.logger != nil {
+				e.logger.Errorf(ctx, "规则执行发生panic", "bizCode", bizCode, "ruleID", panicErr.RuleID, "panic", panicErr.Value, "stack", panicErr.Stack)
+			}
+			e.recordError(ctx, bizCode, ErrorClassPanic, err)
+			return zero, fmt.Errorf("%w: %w", ErrExecFailed, err)
+		}
 		if e.logger != nil {
 			e.logger.Errorf(ctx, "规则执行失败", "bizCode", bizCode, "error", err)
 		}
-		return zero, fmt.Errorf("规则执行失败: %w", err)
+		e.recordError(ctx, bizCode, ErrorClassExecution, err)
+		return zero, fmt.Errorf("%w: %w", ErrExecFailed, err)
 	}
 
-	// 9. 提取结果
+	// 7. 提取结果
+	if fieldErrors != nil {
+		errs := attachFieldErrors(dataCtx, fieldErrors)
+		if options.Report != nil {
+			options.Report.FieldErrors = errs
+		}
+	}
+	attachReasons(dataCtx, functions)
+	if raw, ok := resultMap(dataCtx); ok {
+		if err := checkNonFinite(raw, e.config.NonFinitePolicy, e.config.NonFiniteDefault); err != nil {
+			if e.logger != nil {
+				e.logger.Errorf(ctx, "结果包含非有限数值", "bizCode", bizCode, "error", err)
+			}
+			e.recordError(ctx, bizCode, ErrorClassExecution, err)
+			return zero, fmt.Errorf("%w: %w", ErrExecFailed, err)
+		}
+	}
 	result, err := e.extractResult(dataCtx)
 	if err != nil {
 		if e.logger != nil {
 			e.logger.Errorf(ctx, "结果提取失败", "bizCode", bizCode, "error", err)
 		}
-		return zero, fmt.Errorf("结果提取失败: %w", err)
+		e.recordError(ctx, bizCode, ErrorClassConversion, err)
+		return zero, fmt.Errorf("%w: %w", ErrResultFailed, err)
+	}
+
+	if fires != nil {
+		raw, _ := resultMap(dataCtx)
+		e.recordDecision(ctx, bizCode, version, raw, fires.fired)
+		e.recordRuleActivity(bizCode, knowledgeBase, fires.fired)
+	}
+
+	if idempotent {
+		e.storeIdempotentResult(ctx, bizCode, hash, options.IdempotencyKey, result)
 	}
 
 	return result, nil
 }
 
+// normalizeInput 按配置归一化输入 - 拷贝、类型转换和扁平化路径别名
+func (e *engineImpl[T]) normalizeInput(input any) (any, []CoercionRecord) {
+	var coercions []CoercionRecord
+	if e.config != nil && e.config.CopyInput {
+		input = copyInput(input)
+	}
+	if e.config != nil && e.config.InputCoercion {
+		input, coercions = coerceInput(input, e.config.InputSchema)
+	}
+	if e.config != nil && e.config.FlattenInput {
+		input = flattenInput(input)
+	}
+	return input, coercions
+}
+
 // ============================================================================
 // 规则获取和缓存管理
 // ============================================================================
 
-// getRules 获取规则 - 支持缓存机制和数据库回退
+// getRules 获取业务码的生效规则 - 合并内置规则、数据库规则和运行时覆盖
 func (e *engineImpl[T]) getRules(ctx context.Context, bizCode string) ([]*rule.Rule, error) {
+	rules, err := e.loadRules(ctx, bizCode)
+	if err != nil {
+		return nil, err
+	}
+	return e.layerRules(bizCode, rules), nil
+}
+
+// loadRules 获取业务码的数据库规则 - 优先读取缓存，按运行环境选择变体
+func (e *engineImpl[T]) loadRules(ctx context.Context, bizCode string) ([]*rule.Rule, error) {
+	e.bizCodes.Store(bizCode, struct{}{})
+
 	// 1. 尝试从缓存获取
 	if e.cache != nil {
-		cacheKey := e.cacheKeys.RuleKey(bizCode)
+		cacheKey := e.ruleCacheKey(bizCode)
 		data, err := e.cache.Get(ctx, cacheKey)
 		if err == nil {
 			// 反序列化缓存数据
@@ -178,20 +481,15 @@ func (e *engineImpl[T]) getRules(ctx context.Context, bizCode string) ([]*rule.R
 				if e.logger != nil {
 					e.logger.Debugf(ctx, "从缓存获取规则成功", "bizCode", bizCode, "count", len(cacheItem.Rules))
 				}
-				// Convert cache.Rule (interface{}) back to []*Rule
-				rules := make([]*rule.Rule, len(cacheItem.Rules))
-				for i, r := range cacheItem.Rules {
-					if rule, ok := r.(*rule.Rule); ok {
-						rules[i] = rule
-					}
-				}
-				return rules, nil
+				return e.selectEnvironment(cachedRules(cacheItem)), nil
 			}
 		}
 	}
 
 	// 2. 从数据库获取
-	rules, err := e.mapper.FindByBizCode(ctx, bizCode)
+	start := time.Now()
+	rules, err := e.fetchRules(ctx, bizCode)
+	e.logSlowQuery(ctx, bizCode, time.Since(start), len(rules))
 	if err != nil {
 		return nil, err
 	}
@@ -210,22 +508,47 @@ func (e *engineImpl[T]) getRules(ctx context.Context, bizCode string) ([]*rule.R
 			Version:   1,
 		}
 		if data, err := cacheItem.ToBytes(); err == nil {
-			cacheKey := e.cacheKeys.RuleKey(bizCode)
-			// 缓存1小时
-			if err := e.cache.Set(ctx, cacheKey, data, time.Hour); err != nil && e.logger != nil {
+			cacheKey := e.ruleCacheKey(bizCode)
+			if err := e.cache.Set(ctx, cacheKey, data, e.ruleCacheTTL()); err != nil && e.logger != nil {
 				e.logger.Warnf(ctx, "规则缓存更新失败", "bizCode", bizCode, "error", err)
 			}
 		}
 	}
 
-	return rules, nil
+	return e.selectEnvironment(rules), nil
+}
+
+// ruleCacheKey 规则缓存键 - 分页获取的规则不含GRL，使用单独的缓存键，避免读取完整规则的实例命中
+func (e *engineImpl[T]) ruleCacheKey(bizCode string) string {
+	if _, ok := e.mapper.(rule.PagedRuleMapper); ok && e.rulePageSize() > 0 {
+		return e.cacheKeys.PagedRuleKey(bizCode)
+	}
+	return e.cacheKeys.RuleKey(bizCode)
+}
+
+// ruleCacheTTL 规则缓存时长 - 取 Config.CacheTTL，未配置时缓存1小时
+func (e *engineImpl[T]) ruleCacheTTL() time.Duration {
+	if e.config == nil || e.config.CacheTTL <= 0 {
+		return time.Hour
+	}
+	return e.config.CacheTTL
 }
 
-// compileRules 编译规则 - 将GRL规则转换为可执行的知识库
-func (e *engineImpl[T]) compileRules(bizCode string, rules []*rule.Rule) (*ast.KnowledgeBase, error) {
+// logSlowQuery 规则查询耗时超过慢查询阈值时记录警告日志
+func (e *engineImpl[T]) logSlowQuery(ctx context.Context, bizCode string, elapsed time.Duration, count int) {
+	threshold := e.config.SlowQueryThreshold
+	if threshold <= 0 || elapsed <= threshold || e.logger == nil {
+		return
+	}
+	e.logger.Warnf(ctx, "规则慢查询", "bizCode", bizCode, "elapsed", elapsed, "threshold", threshold, "count", count)
+}
+
+// compileRules 编译规则 - 将GRL规则转换为可执行的知识库，按页读取GRL时使用ctx
+func (e *engineImpl[T]) compileRules(ctx context.Context, bizCode string, rules []*rule.Rule) (*ast.KnowledgeBase, error) {
 	// 检查是否已编译缓存
-	if kb, ok := e.knowledgeBases.Load(bizCode); ok {
-		return kb.(*ast.KnowledgeBase), nil
+	mode := e.ExecMode(bizCode)
+	if kb, ok := e.compiledKnowledgeBase(bizCode, mode); ok {
+		return kb, nil
 	}
 
 	// 使用互斥锁保护编译过程，防止并发编译同一个业务码的规则
@@ -233,8 +556,13 @@ func (e *engineImpl[T]) compileRules(bizCode string, rules []*rule.Rule) (*ast.K
 	defer e.mutex.Unlock()
 
 	// 双重检查，防止在等待锁的过程中其他协程已经编译完成
-	if kb, ok := e.knowledgeBases.Load(bizCode); ok {
-		return kb.(*ast.KnowledgeBase), nil
+	if kb, ok := e.compiledKnowledgeBase(bizCode, mode); ok {
+		return kb, nil
+	}
+	// 执行模式变更后已编译的知识库的salience不再适用，清理后重新编译
+	if _, ok := e.knowledgeBases.LoadAndDelete(bizCode); ok {
+		e.fragments.Delete(bizCode)
+		e.dropLibraryEntry(bizCode)
 	}
 
 	// 创建新的知识库
@@ -242,20 +570,48 @@ func (e *engineImpl[T]) compileRules(bizCode string, rules []*rule.Rule) (*ast.K
 		return nil, fmt.Errorf("知识库库为空")
 	}
 
-	// 编译每个规则
-	for _, rule := range rules {
-		if !rule.Enabled {
-			continue // 跳过禁用的规则
+	// 编译每个规则，配置分页时按页读取并拼接GRL编译，启用增量编译时复用未变更规则的编译结果
+	ruleCount, reused := 0, 0
+	hasher := sha256.New()
+	var shared []rule.CommonCall
+	if e.pagedCompile(bizCode) {
+		e.fragments.Delete(bizCode)
+		count, err := e.buildRulePages(ctx, bizCode, rules, e.rulePageSize(), hasher)
+		if err != nil {
+			return nil, err
+		}
+		ruleCount = count
+	} else {
+		// 分页获取的规则需要同时分析全部规则时读取全部GRL
+		full, err := e.hydrateRules(ctx, rules)
+		if err != nil {
+			return nil, err
 		}
 
-		// 创建字节数组资源
-		ruleBytes := pkg.NewBytesResource([]byte(rule.GRL))
+		// 检查规则写入是否超出声明
+		if err := e.checkWriteDeclarations(bizCode, full); err != nil {
+			return nil, err
+		}
 
-		// 构建规则
-		ruleBuilder := builder.NewRuleBuilder(e.knowledgeLibrary)
-		if err := ruleBuilder.BuildRuleFromResource(bizCode, "1.0.0", ruleBytes); err != nil {
-			return nil, fmt.Errorf("编译规则 %s 失败: %w", rule.Name, err)
+		// 检查规则集预估成本是否超出延迟预算
+		if err := e.checkCostBudget(bizCode, full); err != nil {
+			return nil, err
 		}
+
+		// 解析枚举与参考数据引用，提取多条规则共用的调用，编译改写后的规则
+		built, err := e.resolveReferences(full)
+		if err != nil {
+			return nil, err
+		}
+		if e.hoistCommonCalls() {
+			built, shared = rule.HoistCommonCalls(built)
+		}
+
+		count, reusedCount, err := e.buildRules(bizCode, built, hasher)
+		if err != nil {
+			return nil, err
+		}
+		ruleCount, reused = count, reusedCount
 	}
 
 	// 从knowledge library中获取构建好的知识库
@@ -266,9 +622,24 @@ func (e *engineImpl[T]) compileRules(bizCode string, rules []*rule.Rule) (*ast.K
 	if knowledgeBase == nil {
 		return nil, fmt.Errorf("知识库实例为空")
 	}
+	applyExecMode(mode, rules, knowledgeBase)
 
 	// 缓存编译结果
 	e.knowledgeBases.Store(bizCode, knowledgeBase)
+	e.retainVersion(bizCode, rules, knowledgeBase)
+	nodes, bytes := estimateKnowledgeBase(knowledgeBase)
+	e.compileInfos.Store(bizCode, CompileInfo{
+		BizCode:        bizCode,
+		Hash:           hex.EncodeToString(hasher.Sum(nil)),
+		RuleCount:      ruleCount,
+		ReusedRules:    reused,
+		SharedCalls:    shared,
+		ExecMode:       mode,
+		Version:        ruleSetVersion(rules),
+		CompiledAt:     time.Now(),
+		NodeCount:      nodes,
+		EstimatedBytes: bytes,
+	})
 
 	return knowledgeBase, nil
 }
@@ -279,6 +650,11 @@ func (e *engineImpl[T]) compileRules(bizCode string, rules []*rule.Rule) (*ast.K
 
 // Close 关闭引擎 - 释放所有资源
 func (e *engineImpl[T]) Close() error {
+	// 先于加锁停止实例间同步，消息处理和到期移除会清理编译缓存（需要获取同一把锁）
+	e.stopInstanceSync()
+	e.stopInvalidationSource()
+	e.overrides.clear()
+
 	e.mutex.Lock()
 	defer e.mutex.Unlock()
 
@@ -287,9 +663,7 @@ func (e *engineImpl[T]) Close() error {
 	}
 
 	// 停止定时任务
-	if e.cron != nil {
-		e.cron.Stop()
-	}
+	e.scheduler().Stop()
 
 	// 关闭缓存连接
 	if e.cache != nil {
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"sync"
 	"testing"
+	"time"
 
 	"gitee.com/damengde/runehammer/cache"
 	"gitee.com/damengde/runehammer/config"
@@ -203,3 +204,40 @@ func TestEngineImpl(t *testing.T) {
 		})
 	})
 }
+
+// TestSlowQueryLog 测试规则慢查询日志
+func TestSlowQueryLog(t *testing.T) {
+	Convey("规则慢查询日志", t, func() {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mapper := rule.NewMockRuleMapper(ctrl)
+		mapper.EXPECT().FindByBizCode(gomock.Any(), gomock.Any()).AnyTimes().
+			DoAndReturn(func(ctx context.Context, bizCode string) ([]*rule.Rule, error) {
+				time.Sleep(20 * time.Millisecond)
+				return []*rule.Rule{}, nil
+			})
+		mockLogger := logger.NewMockLogger(ctrl)
+
+		cfg := config.DefaultConfig()
+		engine := NewEngineImpl[map[string]interface{}](
+			cfg, mapper, nil, cache.CacheKeyBuilder{}, mockLogger,
+			ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+		)
+
+		Convey("超过阈值记录警告", func() {
+			cfg.SlowQueryThreshold = 5 * time.Millisecond
+			mockLogger.EXPECT().Warnf(gomock.Any(), "规则慢查询", "bizCode", "slow_biz", "elapsed", gomock.Any(),
+				"threshold", 5*time.Millisecond, "count", 0).Times(1)
+
+			_, err := engine.getRules(context.Background(), "slow_biz")
+			So(err, ShouldBeNil)
+		})
+
+		Convey("未配置阈值不记录", func() {
+			cfg.SlowQueryThreshold = 0
+			_, err := engine.getRules(context.Background(), "slow_biz")
+			So(err, ShouldBeNil)
+		})
+	})
+}
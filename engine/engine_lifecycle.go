@@ -2,13 +2,22 @@ package engine
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	logger "gitee.com/damengde/runehammer/logger"
 )
 
 // ============================================================================
 // 生命周期管理 - 处理引擎的启动、同步和关闭
 // ============================================================================
 
+// ErrEngineClosed 引擎已关闭
+var ErrEngineClosed = errors.New("引擎已关闭")
+
 // startSync 启动同步任务 - 定期同步规则缓存
 //
 // 同步功能:
@@ -26,7 +35,7 @@ func (e *engineImpl[T]) StartSync() error {
 	}
 
 	// 添加同步任务到定时调度器
-	_, err := e.cron.AddFunc(fmt.Sprintf("@every %s", e.config.SyncInterval), func() {
+	_, err := e.scheduler().AddFunc(fmt.Sprintf("@every %s", e.config.SyncInterval), func() {
 		if err := e.syncRules(); err != nil && e.logger != nil {
 			e.logger.Errorf(context.Background(), "规则同步失败", "error", err)
 		}
@@ -37,7 +46,7 @@ func (e *engineImpl[T]) StartSync() error {
 	}
 
 	// 启动定时调度器
-	e.cron.Start()
+	e.scheduler().Start()
 
 	if e.logger != nil {
 		e.logger.Infof(context.Background(), "同步任务已启动", "interval", e.config.SyncInterval)
@@ -59,7 +68,19 @@ func (e *engineImpl[T]) StartSync() error {
 //	error - 同步过程中的错误
 func (e *engineImpl[T]) syncRules() error {
 	ctx := context.Background()
+	ran, err := e.taskLock.TryRun(ctx, syncLockTask, e.config.SyncInterval, e.syncShared)
+	if !ran {
+		// 同步由其他实例执行，本实例内存中的编译缓存仍需清理
+		e.clearExpiredKnowledgeBases()
+		if err == nil && e.logger != nil {
+			e.logger.Debugf(ctx, "规则同步由其他实例执行，仅清理本实例编译缓存")
+		}
+	}
+	return err
+}
 
+// syncShared 执行同步步骤 - 配置了分布式任务锁时每个同步周期只由持有锁的实例执行
+func (e *engineImpl[T]) syncShared(ctx context.Context) error {
 	if e.logger != nil {
 		e.logger.Debugf(ctx, "开始执行规则同步")
 	}
@@ -91,11 +112,31 @@ func (e *engineImpl[T]) clearExpiredKnowledgeBases() {
 	// 清理所有编译缓存，强制重新编译
 	// 这是一个简单的实现，生产环境中可以更智能地决定清理策略
 	e.knowledgeBases.Range(func(key, value interface{}) bool {
-		e.knowledgeBases.Delete(key)
+		if bizCode, ok := key.(string); ok {
+			e.dropKnowledgeBase(bizCode)
+		}
 		return true
 	})
 }
 
+// dropKnowledgeBase 清理业务码的编译知识库
+func (e *engineImpl[T]) dropKnowledgeBase(bizCode string) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.knowledgeBases.Delete(bizCode)
+	e.dropLibraryEntry(bizCode)
+}
+
+// dropLibraryEntry 移除知识库库中业务码的规则条目 - 调用方需持有写锁
+//
+// 只删除已编译的知识库而保留条目时，重新编译会因规则已存在而报错。
+func (e *engineImpl[T]) dropLibraryEntry(bizCode string) {
+	if e.knowledgeLibrary != nil {
+		delete(e.knowledgeLibrary.Library, fmt.Sprintf("%s:%s", bizCode, "1.0.0"))
+	}
+}
+
 // refreshCache 刷新指定业务码的缓存
 //
 // 参数:
@@ -113,12 +154,13 @@ func (e *engineImpl[T]) clearExpiredKnowledgeBases() {
 func (e *engineImpl[T]) refreshCache(bizCode string) error {
 	ctx := context.Background()
 
-	// 清理编译缓存
-	e.knowledgeBases.Delete(bizCode)
+	// 清理编译缓存，启用层级继承时一并清理子业务码
+	e.dropKnowledgeBase(bizCode)
+	e.invalidateDescendants(bizCode)
 
 	// 清理规则缓存
 	if e.cache != nil {
-		cacheKey := e.cacheKeys.RuleKey(bizCode)
+		cacheKey := e.ruleCacheKey(bizCode)
 		if err := e.cache.Del(ctx, cacheKey); err != nil && e.logger != nil {
 			e.logger.Warnf(ctx, "清理规则缓存失败", "bizCode", bizCode, "error", err)
 		}
@@ -137,6 +179,64 @@ func (e *engineImpl[T]) refreshCache(bizCode string) error {
 	return nil
 }
 
+// RefreshMatching 按模式失效业务码缓存 - 清理匹配业务码的编译知识库和规则缓存，下次执行时重新加载
+//
+// 模式包含 * ? [ 时按glob匹配（如 payments.*），否则按前缀匹配（如 payments.cards）。
+// 匹配范围为本引擎已获取过规则的业务码。
+//
+// 参数:
+//
+//	pattern - 业务码glob模式或前缀
+//
+// 返回值:
+//
+//	[]string - 已失效的业务码（按字典序）
+//	error    - 模式格式错误
+func (e *engineImpl[T]) RefreshMatching(pattern string) ([]string, error) {
+	match := func(bizCode string) (bool, error) {
+		return strings.HasPrefix(bizCode, pattern), nil
+	}
+	if strings.ContainsAny(pattern, "*?[") {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("无效的业务码模式 %q: %w", pattern, err)
+		}
+		match = func(bizCode string) (bool, error) {
+			return path.Match(pattern, bizCode)
+		}
+	}
+
+	candidates := make(map[string]struct{})
+	collect := func(key, value interface{}) bool {
+		if code, ok := key.(string); ok {
+			candidates[code] = struct{}{}
+		}
+		return true
+	}
+	e.bizCodes.Range(collect)
+	e.knowledgeBases.Range(collect)
+
+	ctx := context.Background()
+	var refreshed []string
+	for bizCode := range candidates {
+		if ok, _ := match(bizCode); !ok {
+			continue
+		}
+		e.dropKnowledgeBase(bizCode)
+		if e.cache != nil {
+			if err := e.cache.Del(ctx, e.ruleCacheKey(bizCode)); err != nil && e.logger != nil {
+				e.logger.Warnf(ctx, "清理规则缓存失败", "bizCode", bizCode, "error", err)
+			}
+		}
+		refreshed = append(refreshed, bizCode)
+	}
+	sort.Strings(refreshed)
+
+	if e.logger != nil {
+		e.logger.Infof(ctx, "按模式刷新缓存完成", "pattern", pattern, "count", len(refreshed))
+	}
+	return refreshed, nil
+}
+
 // getStats 获取引擎统计信息
 //
 // 返回值:
@@ -145,6 +245,7 @@ func (e *engineImpl[T]) refreshCache(bizCode string) error {
 //
 // 统计项目:
 //   - 编译缓存条目数
+//   - 知识库内存估算（按业务码，估算字节数降序）
 //   - 引擎状态
 //   - 运行时长等
 func (e *engineImpl[T]) getStats() map[string]interface{} {
@@ -158,11 +259,28 @@ func (e *engineImpl[T]) getStats() map[string]interface{} {
 		return true
 	})
 
+	memory := e.KnowledgeBaseMemory()
+	var totalBytes int64
+	for _, info := range memory {
+		totalBytes += info.EstimatedBytes
+	}
+
 	return map[string]interface{}{
-		"closed":          e.closed,
-		"knowledge_bases": kbCount,
-		"sync_interval":   e.config.SyncInterval,
-		"cache_enabled":   e.cache != nil,
-		"logger_enabled":  e.logger != nil,
+		"closed":                e.closed,
+		"knowledge_bases":       kbCount,
+		"knowledge_base_memory": memory,
+		"knowledge_base_bytes":  totalBytes,
+		"sync_interval":         e.config.SyncInterval,
+		"cache_enabled":         e.cache != nil,
+		"logger_enabled":        !isNoopLogger(e.logger),
+	}
+}
+
+// isNoopLogger 是否为空日志记录器（未传入日志时的默认值）
+func isNoopLogger(l logger.Logger) bool {
+	if l == nil {
+		return true
 	}
+	_, noop := l.(*logger.NoopLogger)
+	return noop
 }
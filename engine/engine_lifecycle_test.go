@@ -3,6 +3,7 @@ package engine
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -11,6 +12,7 @@ import (
 	"gitee.com/damengde/runehammer/config"
 	logger "gitee.com/damengde/runehammer/logger"
 	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
 	"github.com/robfig/cron/v3"
 	. "github.com/smartystreets/goconvey/convey"
 	"go.uber.org/mock/gomock"
@@ -1092,3 +1094,122 @@ func TestEngineLifecycleEdgeCases(t *testing.T) {
 		})
 	})
 }
+
+// TestRefreshMatching 测试按模式刷新缓存
+func TestRefreshMatching(t *testing.T) {
+	Convey("按模式刷新缓存", t, func() {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mapper := rule.NewMockRuleMapper(ctrl)
+		mapper.EXPECT().FindByBizCode(gomock.Any(), gomock.Any()).AnyTimes().
+			DoAndReturn(func(ctx context.Context, bizCode string) ([]*rule.Rule, error) {
+				name := strings.ReplaceAll(bizCode, ".", "_")
+				return []*rule.Rule{{
+					Name:    name,
+					GRL:     fmt.Sprintf(`rule %s "%s" { when true then Result["ok"] = true; Retract("%s"); }`, name, name, name),
+					Enabled: true,
+				}}, nil
+			})
+
+		engine := NewEngineImpl[map[string]interface{}](
+			config.DefaultConfig(), mapper, cache.NewMemoryCache(100), cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+			ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+		)
+		defer engine.Close()
+
+		ctx := context.Background()
+		for _, bizCode := range []string{"payments.cards", "payments.wallet", "payments", "orders.refund"} {
+			_, err := engine.Exec(ctx, bizCode, map[string]interface{}{"n": 1})
+			So(err, ShouldBeNil)
+		}
+
+		Convey("glob匹配", func() {
+			refreshed, err := engine.RefreshMatching("payments.*")
+			So(err, ShouldBeNil)
+			So(refreshed, ShouldResemble, []string{"payments.cards", "payments.wallet"})
+
+			_, ok := engine.knowledgeBases.Load("payments.cards")
+			So(ok, ShouldBeFalse)
+			_, ok = engine.knowledgeBases.Load("payments")
+			So(ok, ShouldBeTrue)
+			_, err = engine.cache.Get(ctx, engine.cacheKeys.RuleKey("payments.cards"))
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("前缀匹配", func() {
+			refreshed, err := engine.RefreshMatching("payments")
+			So(err, ShouldBeNil)
+			So(refreshed, ShouldResemble, []string{"payments", "payments.cards", "payments.wallet"})
+		})
+
+		Convey("无效模式", func() {
+			_, err := engine.RefreshMatching("payments.[")
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("刷新后重新执行", func() {
+			_, err := engine.RefreshMatching("orders.*")
+			So(err, ShouldBeNil)
+			result, err := engine.Exec(ctx, "orders.refund", map[string]interface{}{"n": 1})
+			So(err, ShouldBeNil)
+			So(result["ok"], ShouldEqual, true)
+		})
+
+		Convey("全量清理编译缓存后重新执行", func() {
+			engine.clearExpiredKnowledgeBases()
+			result, err := engine.Exec(ctx, "payments", map[string]interface{}{"n": 1})
+			So(err, ShouldBeNil)
+			So(result["ok"], ShouldEqual, true)
+		})
+	})
+}
+
+// TestDropKnowledgeBase 测试清理编译知识库时移除知识库库中的规则条目
+func TestDropKnowledgeBase(t *testing.T) {
+	Convey("清理编译知识库", t, func() {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		limit := 100
+		mapper := rule.NewMockRuleMapper(ctrl)
+		mapper.EXPECT().FindByBizCode(gomock.Any(), "loan").AnyTimes().
+			DoAndReturn(func(ctx context.Context, bizCode string) ([]*rule.Rule, error) {
+				return []*rule.Rule{{
+					Name:    "Limit",
+					GRL:     fmt.Sprintf(`rule Limit "额度" { when true then Result["limit"] = %d; Retract("Limit"); }`, limit),
+					Enabled: true,
+				}}, nil
+			})
+
+		library := ast.NewKnowledgeLibrary()
+		engine := NewEngineImpl[map[string]interface{}](
+			config.DefaultConfig(), mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+			library, &sync.Map{}, cron.New(), false,
+		)
+		defer engine.Close()
+
+		ctx := context.Background()
+		result, err := engine.Exec(ctx, "loan", map[string]interface{}{})
+		So(err, ShouldBeNil)
+		So(result["limit"], ShouldEqual, 100)
+		So(library.Library, ShouldContainKey, "loan:1.0.0")
+
+		Convey("移除知识库库中的条目", func() {
+			engine.dropKnowledgeBase("loan")
+
+			_, ok := engine.knowledgeBases.Load("loan")
+			So(ok, ShouldBeFalse)
+			So(library.Library, ShouldNotContainKey, "loan:1.0.0")
+		})
+
+		Convey("规则变更后重新编译", func() {
+			limit = 200
+			engine.dropKnowledgeBase("loan")
+
+			result, err := engine.Exec(ctx, "loan", map[string]interface{}{})
+			So(err, ShouldBeNil)
+			So(result["limit"], ShouldEqual, 200)
+		})
+	})
+}
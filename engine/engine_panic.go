@@ -0,0 +1,232 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	grengine "github.com/hyperjumptech/grule-rule-engine/engine"
+	"github.com/hyperjumptech/grule-rule-engine/model"
+)
+
+// ============================================================================
+// panic隔离 - 自定义函数panic不影响调用方协程
+// ============================================================================
+
+// ErrRulePanic 规则执行过程中发生panic
+var ErrRulePanic = errors.New("规则执行发生panic")
+
+// RulePanicError 规则panic详情 - 可通过errors.Is(err, ErrRulePanic)判断
+type RulePanicError struct {
+	RuleID string // 发生panic时正在执行的规则（无法定位时为业务码或规则哈希）
+	Value  any    // recover得到的值
+	Stack  string // panic发生时的堆栈
+}
+
+// Error 实现error接口
+func (e *RulePanicError) Error() string {
+	return fmt.Sprintf("规则 %s 执行发生panic: %v", e.RuleID, e.Value)
+}
+
+// Unwrap 支持errors.Is(err, ErrRulePanic)
+func (e *RulePanicError) Unwrap() error {
+	return ErrRulePanic
+}
+
+// ExecMetrics 执行指标
+type ExecMetrics struct {
+	Panics int64 `json:"panics"` // 已恢复的panic次数
+}
+
+// execMetrics 执行指标计数器
+type execMetrics struct {
+	panics atomic.Int64
+}
+
+// snapshot 获取指标快照
+func (m *execMetrics) snapshot() ExecMetrics {
+	return ExecMetrics{Panics: m.panics.Load()}
+}
+
+// ruleTracker 记录当前正在执行的规则，用于panic定位
+type ruleTracker struct {
+	current string
+}
+
+// EvaluateRuleEntry 实现GruleEngineListener（求值结束后才通知，不用于定位）
+func (t *ruleTracker) EvaluateRuleEntry(cycle uint64, entry *ast.RuleEntry, candidate bool) {}
+
+// ExecuteRuleEntry 实现GruleEngineListener
+func (t *ruleTracker) ExecuteRuleEntry(cycle uint64, entry *ast.RuleEntry) {
+	t.current = entry.RuleName
+}
+
+// BeginCycle 实现GruleEngineListener
+func (t *ruleTracker) BeginCycle(cycle uint64) {}
+
+// safeExecute 执行知识库并捕获panic
+//
+// Grule会自行恢复规则中的panic并只保留格式化的信息，因此规则调用的函数经 panicGuardContext 包装，
+// 在Grule恢复前记录带堆栈的 *RulePanicError；执行返回错误且记录了panic时以该panic为准。
+// 条件求值中的panic被Grule视为条件不成立（缺失字段语义为error时返回错误）。
+//
+// 参数:
+//
+//	ctx           - 上下文
+//	dataCtx       - 数据上下文
+//	knowledgeBase - 知识库
+//	fallbackID    - 无法定位具体规则时使用的标识
+//	metrics       - 执行指标，发生panic时计数
+//	failOnCond    - 条件求值出错时是否返回错误（缺失字段语义为error），否则该规则不命中
+//	listeners     - 附加的执行监听器，如灰度放量闸门
+//
+// 返回值:
+//
+//	error - 执行错误，panic时返回*RulePanicError
+func safeExecute(
+	ctx context.Context,
+	dataCtx ast.IDataContext,
+	knowledgeBase *ast.KnowledgeBase,
+	fallbackID string,
+	metrics *execMetrics,
+	failOnCond bool,
+	listeners ...grengine.GruleEngineListener,
+) (err error) {
+	tracker := &ruleTracker{}
+	recorder := &panicRecorder{}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = newRulePanicError(tracker.ruleID(fallbackID), r, metrics)
+		}
+	}()
+
+	ruleEngine := grengine.NewGruleEngine()
+	ruleEngine.ReturnErrOnFailedRuleEvaluation = failOnCond
+	ruleEngine.Listeners = append(ruleEngine.Listeners, tracker)
+	ruleEngine.Listeners = append(ruleEngine.Listeners, listeners...)
+	err = ruleEngine.ExecuteWithContext(ctx, &panicGuardContext{IDataContext: dataCtx, recorder: recorder}, knowledgeBase)
+	if err == nil {
+		return nil
+	}
+	if panicErr := recorder.recorded(); panicErr != nil {
+		panicErr.RuleID = tracker.ruleID(fallbackID)
+		if metrics != nil {
+			metrics.panics.Add(1)
+		}
+		return panicErr
+	}
+	return err
+}
+
+// ruleID 当前规则，无法定位时返回fallbackID
+func (t *ruleTracker) ruleID(fallbackID string) string {
+	if t.current == "" {
+		return fallbackID
+	}
+	return t.current
+}
+
+// panicRecorder 记录规则调用函数时发生的首个panic
+type panicRecorder struct {
+	mu  sync.Mutex
+	err *RulePanicError
+}
+
+// record 记录panic，需在defer中调用以保留堆栈
+func (r *panicRecorder) record(value any) *RulePanicError {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err == nil {
+		r.err = &RulePanicError{Value: value, Stack: string(debug.Stack())}
+	}
+	return r.err
+}
+
+// recorded 已记录的panic
+func (r *panicRecorder) recorded() *RulePanicError {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// panicGuardContext 包装数据上下文，规则通过其中的对象调用函数时在Grule恢复panic前记录
+type panicGuardContext struct {
+	ast.IDataContext
+	recorder *panicRecorder
+}
+
+// Get 实现ast.IDataContext
+func (c *panicGuardContext) Get(key string) model.ValueNode {
+	return guardNode(c.IDataContext.Get(key), c.recorder)
+}
+
+// panicGuardNode 记录函数调用panic的值节点，子节点同样包装
+type panicGuardNode struct {
+	model.ValueNode
+	recorder *panicRecorder
+}
+
+// guardNode 包装值节点，nil保持为nil
+func guardNode(node model.ValueNode, recorder *panicRecorder) model.ValueNode {
+	if node == nil {
+		return nil
+	}
+	return &panicGuardNode{ValueNode: node, recorder: recorder}
+}
+
+// CallFunction 实现model.ValueNode - 记录panic后继续panic，由Grule中断当前规则
+func (n *panicGuardNode) CallFunction(funcName string, args ...reflect.Value) (reflect.Value, error) {
+	defer func() {
+		if r := recover(); r != nil {
+			panic(n.recorder.record(r))
+		}
+	}()
+	return n.ValueNode.CallFunction(funcName, args...)
+}
+
+// ContinueWithValue 实现model.ValueNode
+func (n *panicGuardNode) ContinueWithValue(value reflect.Value, identifiedAs string) model.ValueNode {
+	return guardNode(n.ValueNode.ContinueWithValue(value, identifiedAs), n.recorder)
+}
+
+// GetChildNodeByIndex 实现model.ValueNode
+func (n *panicGuardNode) GetChildNodeByIndex(index int) (model.ValueNode, error) {
+	child, err := n.ValueNode.GetChildNodeByIndex(index)
+	return guardNode(child, n.recorder), err
+}
+
+// GetChildNodeBySelector 实现model.ValueNode
+func (n *panicGuardNode) GetChildNodeBySelector(index reflect.Value) (model.ValueNode, error) {
+	child, err := n.ValueNode.GetChildNodeBySelector(index)
+	return guardNode(child, n.recorder), err
+}
+
+// GetChildNodeByField 实现model.ValueNode
+func (n *panicGuardNode) GetChildNodeByField(field string) (model.ValueNode, error) {
+	child, err := n.ValueNode.GetChildNodeByField(field)
+	return guardNode(child, n.recorder), err
+}
+
+// newRulePanicError 根据recover的值构造panic错误并计数，需在defer中调用以保留堆栈
+func newRulePanicError(ruleID string, value any, metrics *execMetrics) *RulePanicError {
+	if metrics != nil {
+		metrics.panics.Add(1)
+	}
+	return &RulePanicError{RuleID: ruleID, Value: value, Stack: string(debug.Stack())}
+}
+
+// Metrics 获取执行指标
+func (e *engineImpl[T]) Metrics() ExecMetrics {
+	return e.metrics.snapshot()
+}
+
+// Metrics 获取执行指标
+func (e *DynamicEngine[T]) Metrics() ExecMetrics {
+	return e.metrics.snapshot()
+}
@@ -0,0 +1,121 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gitee.com/damengde/runehammer/rule"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// panicHolder 调用即panic的自定义对象
+type panicHolder struct{}
+
+func (panicHolder) Boom(value int64) bool {
+	panic("boom")
+}
+
+// nestedPanicHolder 通过字段访问panicHolder的自定义对象
+type nestedPanicHolder struct {
+	Checker panicHolder
+}
+
+// panicValidator 验证时panic的验证器
+type panicValidator struct{}
+
+func (panicValidator) Validate(definition interface{}) []rule.ValidationError {
+	panic("validator boom")
+}
+
+// TestPanicIsolation 测试规则执行panic隔离
+func TestPanicIsolation(t *testing.T) {
+	Convey("规则执行panic隔离", t, func() {
+		ctx := context.Background()
+
+		Convey("规则动作中的panic转换为ErrRulePanic", func() {
+			engine := NewDynamicEngine[map[string]interface{}]()
+			engine.RegisterCustomObject("Checker", panicHolder{})
+
+			_, err := engine.ExecuteRuleDefinition(ctx, rule.SimpleRule{
+				When: "Params > 0",
+				Then: map[string]string{"Result.Hit": "Checker.Boom(1)"},
+			}, 1)
+
+			So(errors.Is(err, ErrRulePanic), ShouldBeTrue)
+			var panicErr *RulePanicError
+			So(errors.As(err, &panicErr), ShouldBeTrue)
+			So(panicErr.RuleID, ShouldStartWith, "SimpleRule_")
+			So(panicErr.Value, ShouldEqual, "boom")
+			So(panicErr.Stack, ShouldContainSubstring, "panicHolder.Boom")
+			So(engine.Metrics().Panics, ShouldEqual, 1)
+		})
+
+		Convey("规则返回的普通错误不识别为panic", func() {
+			engine := NewDynamicEngine[map[string]interface{}]()
+			engine.RegisterCustomObject("Checker", panicHolder{})
+
+			// 错误信息同时包含 panic 和 recovered，不影响识别
+			_, err := engine.ExecuteRuleDefinition(ctx, rule.SimpleRule{
+				When: "Params > 0",
+				Then: map[string]string{"Result.Hit": "Checker.panic_recovered(1)"},
+			}, 1)
+
+			So(err, ShouldNotBeNil)
+			So(errors.Is(err, ErrRulePanic), ShouldBeFalse)
+			So(engine.Metrics().Panics, ShouldEqual, 0)
+		})
+
+		Convey("嵌套对象方法的panic同样携带堆栈", func() {
+			engine := NewDynamicEngine[map[string]interface{}]()
+			engine.RegisterCustomObject("Holder", &nestedPanicHolder{Checker: panicHolder{}})
+
+			_, err := engine.ExecuteRuleDefinition(ctx, rule.SimpleRule{
+				When: "Params > 0",
+				Then: map[string]string{"Result.Hit": "Holder.Checker.Boom(1)"},
+			}, 1)
+
+			var panicErr *RulePanicError
+			So(errors.As(err, &panicErr), ShouldBeTrue)
+			So(panicErr.Stack, ShouldContainSubstring, "panicHolder.Boom")
+		})
+
+		Convey("引擎内部panic携带堆栈", func() {
+			engine := NewDynamicEngine[map[string]interface{}](DynamicEngineConfig{
+				StrictValidation:  true,
+				ParallelExecution: true,
+			})
+			engine.RegisterValidator(panicValidator{})
+
+			_, err := engine.ExecuteRuleDefinition(ctx, rule.SimpleRule{
+				When: "Params > 0",
+				Then: map[string]string{"Result.Ok": "true"},
+			}, 1)
+
+			var panicErr *RulePanicError
+			So(errors.As(err, &panicErr), ShouldBeTrue)
+			So(panicErr.RuleID, ShouldNotBeEmpty)
+			So(panicErr.Value, ShouldEqual, "validator boom")
+			So(panicErr.Stack, ShouldContainSubstring, "panicValidator")
+			So(engine.Metrics().Panics, ShouldEqual, 1)
+		})
+
+		Convey("并行批量执行不影响调用方协程", func() {
+			engine := NewDynamicEngine[map[string]interface{}](DynamicEngineConfig{
+				StrictValidation:  true,
+				ParallelExecution: true,
+			})
+			engine.RegisterValidator(panicValidator{})
+
+			So(func() {
+				results, err := engine.ExecuteBatch(ctx, []interface{}{
+					rule.SimpleRule{When: "Params > 0", Then: map[string]string{"Result.Ok": "true"}},
+					rule.SimpleRule{When: "Params > 1", Then: map[string]string{"Result.Ok": "true"}},
+				}, 1)
+				So(errors.Is(err, ErrRulePanic), ShouldBeTrue)
+				So(results, ShouldHaveLength, 2)
+			}, ShouldNotPanic)
+			So(engine.Metrics().Panics, ShouldEqual, 2)
+		})
+	})
+}
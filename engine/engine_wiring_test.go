@@ -0,0 +1,48 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"gitee.com/damengde/runehammer/cache"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// TestEnginePartialWiring 测试协作者为nil时的默认行为
+func TestEnginePartialWiring(t *testing.T) {
+	Convey("部分装配的引擎", t, func() {
+		engine := NewEngineImpl[map[string]any](nil, nil, nil, cache.CacheKeyBuilder{}, nil, nil, nil, nil, false)
+
+		Convey("使用默认配置、空日志和新建的知识库", func() {
+			So(engine.config, ShouldNotBeNil)
+			So(engine.logger, ShouldNotBeNil)
+			So(engine.knowledgeLibrary, ShouldNotBeNil)
+			So(engine.knowledgeBases, ShouldNotBeNil)
+			So(engine.getStats()["logger_enabled"], ShouldBeFalse)
+		})
+
+		Convey("未配置映射器时执行返回错误而不是panic", func() {
+			_, err := engine.Exec(context.Background(), "loan", map[string]any{"amount": 1})
+			So(err, ShouldNotBeNil)
+
+			records := engine.RecentErrors()
+			So(records, ShouldHaveLength, 1)
+			So(records[0].Class, ShouldEqual, ErrorClassFetch)
+			So(records[0].Message, ShouldContainSubstring, ErrNoRuleMapper.Error())
+		})
+
+		Convey("定时任务调度器在首次使用时创建", func() {
+			So(engine.cron, ShouldBeNil)
+			So(engine.StartSync(), ShouldBeNil)
+			So(engine.cron, ShouldNotBeNil)
+			So(engine.scheduler().Entries(), ShouldHaveLength, 1)
+
+			var buf bytes.Buffer
+			So(engine.DebugDump(&buf), ShouldBeNil)
+			So(buf.String(), ShouldContainSubstring, "cron")
+		})
+
+		So(engine.Close(), ShouldBeNil)
+	})
+}
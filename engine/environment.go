@@ -0,0 +1,62 @@
+package engine
+
+import (
+	"gitee.com/damengde/runehammer/rule"
+)
+
+// ============================================================================
+// 环境变体 - 同一数据库保存各环境的规则变体，按引擎所在环境选择
+// ============================================================================
+
+// ruleVariant 规则变体标识 - 同一业务码下按规则名和环境区分
+type ruleVariant struct {
+	name        string
+	environment string
+}
+
+// variantOf 获取规则的变体标识
+func variantOf(r *rule.Rule) ruleVariant {
+	return ruleVariant{name: r.Name, environment: r.Environment}
+}
+
+// environment 引擎所在的运行环境，未配置时为空
+func (e *engineImpl[T]) environment() string {
+	if e.config == nil {
+		return ""
+	}
+	return e.config.Environment
+}
+
+// selectEnvironment 按运行环境选择规则变体
+//
+// 同名规则（Rule.Name）存在当前环境的变体时使用该变体，否则使用默认变体（Environment为空）；
+// 其他环境的变体不参与执行。结果保持获取顺序。
+func (e *engineImpl[T]) selectEnvironment(rules []*rule.Rule) []*rule.Rule {
+	env := e.environment()
+	matched := make(map[string]bool)
+	variants := false
+	for _, r := range rules {
+		if r.Environment == "" {
+			continue
+		}
+		variants = true
+		if r.Environment == env {
+			matched[r.Name] = true
+		}
+	}
+	if !variants {
+		return rules
+	}
+
+	selected := make([]*rule.Rule, 0, len(rules))
+	for _, r := range rules {
+		if r.Environment == "" {
+			if !matched[r.Name] {
+				selected = append(selected, r)
+			}
+		} else if r.Environment == env {
+			selected = append(selected, r)
+		}
+	}
+	return selected
+}
@@ -0,0 +1,66 @@
+package engine
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+// TestEnvironmentVariants 测试环境变体选择
+func TestEnvironmentVariants(t *testing.T) {
+	Convey("环境变体", t, func() {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		rules := []*rule.Rule{
+			{BizCode: "loan", Name: "limit", Enabled: true, GRL: `rule limit "默认额度" { when true then Result["limit"] = 1000; Retract("limit"); }`},
+			{BizCode: "loan", Name: "limit", Enabled: true, Environment: "prod", GRL: `rule limit "生产额度" { when true then Result["limit"] = 5000; Retract("limit"); }`},
+			{BizCode: "loan", Name: "audit", Enabled: true, Environment: "staging", GRL: `rule audit "预发审计" { when true then Result["audit"] = true; Retract("audit"); }`},
+		}
+		newEngine := func(env string) *engineImpl[map[string]any] {
+			cfg := config.DefaultConfig()
+			cfg.Environment = env
+			mapper := rule.NewMockRuleMapper(ctrl)
+			mapper.EXPECT().FindByBizCode(gomock.Any(), "loan").Return(rules, nil).AnyTimes()
+			return NewEngineImpl[map[string]any](
+				cfg, mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+				ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+			)
+		}
+
+		Convey("优先使用当前环境的变体", func() {
+			engine := newEngine("prod")
+			defer engine.Close()
+			result, err := engine.Exec(context.Background(), "loan", map[string]any{})
+			So(err, ShouldBeNil)
+			So(result["limit"], ShouldEqual, 5000)
+			So(result, ShouldNotContainKey, "audit")
+		})
+
+		Convey("没有当前环境的变体时使用默认变体", func() {
+			engine := newEngine("staging")
+			defer engine.Close()
+			result, err := engine.Exec(context.Background(), "loan", map[string]any{})
+			So(err, ShouldBeNil)
+			So(result["limit"], ShouldEqual, 1000)
+			So(result["audit"], ShouldEqual, true)
+		})
+
+		Convey("未设置环境时只执行默认变体", func() {
+			engine := newEngine("")
+			defer engine.Close()
+			selected := engine.selectEnvironment(rules)
+			So(selected, ShouldHaveLength, 1)
+			So(selected[0], ShouldEqual, rules[0])
+		})
+	})
+}
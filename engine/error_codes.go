@@ -0,0 +1,292 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ============================================================================
+// 错误码与本地化 - 执行错误带稳定的错误码，按区域（zh-CN、en-US）输出错误信息
+// ============================================================================
+//
+// Exec 返回的错误包装为 *LocalizedError：Code 为不随区域变化的机器可读错误码，用于日志聚合和告警；
+// Error() 按错误信息区域（Config.ErrorLocale，与格式化函数的 Config.Locale 相互独立）输出，
+// zh-CN 保持原有的中文错误信息（包含业务码、版本等细节），en-US 输出英文说明、错误码和业务码，
+// 并附上原始信息，细节不因区域丢失；原始信息也可通过 Detail() 单独获取。
+// 原错误保留在错误链中，errors.Is(err, ErrBizDisabled) 等判断不受影响。
+
+// 错误信息区域
+const (
+	LocaleZhCN = "zh-CN" // 中文，未设置区域时使用
+	LocaleEnUS = "en-US" // 英文，中文以外的区域均使用
+)
+
+// 错误码
+const (
+	ErrCodeEngineClosed          = "engine_closed"            // 引擎已关闭
+	ErrCodeInvalidBizCode        = "invalid_biz_code"         // 业务码为空
+	ErrCodeNilInput              = "nil_input"                // 输入参数为空
+	ErrCodeBizDisabled           = "biz_disabled"             // 业务码已被紧急停用
+	ErrCodeQuotaExceeded         = "quota_exceeded"           // 超出执行配额
+	ErrCodeReadOnly              = "read_only"                // 只读模式拒绝写操作
+	ErrCodeNoRuleMapper          = "no_rule_mapper"           // 未配置规则映射器
+	ErrCodeRulesNotFound         = "rules_not_found"          // 业务码没有有效规则
+	ErrCodeVersionNotFound       = "version_not_found"        // 规则集版本不可用
+	ErrCodeOverrideNotFound      = "override_not_found"       // 运行时覆盖规则不存在
+	ErrCodeKnowledgeBaseNotFound = "knowledge_base_not_found" // 预编译知识库不存在
+	ErrCodeInputTypeNotFound     = "input_type_not_found"     // 业务码未注册输入类型
+	ErrCodeCostBudgetExceeded    = "cost_budget_exceeded"     // 规则集预估成本超出延迟预算
+	ErrCodeRuleLimitExceeded     = "rule_limit_exceeded"      // 规则集超出规模或复杂度限制
+	ErrCodeUndeclaredWrite       = "undeclared_write"         // 规则写入未声明的结果字段
+	ErrCodeInvalidFunction       = "invalid_function"         // 自定义函数签名不合法
+	ErrCodeFunctionTimeout       = "function_timeout"         // 自定义函数执行超时
+	ErrCodeRulePanic             = "rule_panic"               // 规则执行发生panic
+	ErrCodeNonFiniteResult       = "non_finite_result"        // 结果包含非有限数值
+	ErrCodeStringTooLong         = "string_too_long"          // 相似度函数输入过长
+	ErrCodeInvalidBands          = "invalid_bands"            // 区间定义无效
+	ErrCodeInvalidVector         = "invalid_vector"           // 向量无效或维度不一致
+	ErrCodeNoRateProvider        = "no_rate_provider"         // 未设置汇率提供者
+	ErrCodeStaleRate             = "stale_rate"               // 汇率已过期
+	ErrCodeNoCalendar            = "no_calendar"              // 未设置节假日日历
+	ErrCodeUnknownRegion         = "unknown_region"           // 日历中没有该地区
+	ErrCodeCompileFailed         = "compile_failed"           // 规则编译失败
+	ErrCodeInjectFailed          = "inject_failed"            // 数据注入失败
+	ErrCodeExecFailed            = "exec_failed"              // 规则执行失败
+	ErrCodeResultFailed          = "result_failed"            // 结果提取失败
+	ErrCodeUnknown               = "unknown"                  // 未分类的错误
+)
+
+// 执行阶段错误，可通过errors.Is判断
+var (
+	// ErrInvalidBizCode 业务码为空
+	ErrInvalidBizCode = errors.New("无效的业务码")
+	// ErrNilInput 输入参数为空
+	ErrNilInput = errors.New("输入参数为空")
+	// ErrRulesNotFound 业务码没有有效规则，且未配置降级结果
+	ErrRulesNotFound = errors.New("规则未找到")
+	// ErrCompileFailed 规则编译失败
+	ErrCompileFailed = errors.New("规则编译失败")
+	// ErrInjectFailed 输入数据注入失败
+	ErrInjectFailed = errors.New("数据注入失败")
+	// ErrExecFailed 规则执行失败
+	ErrExecFailed = errors.New("规则执行失败")
+	// ErrResultFailed 结果提取失败
+	ErrResultFailed = errors.New("结果提取失败")
+)
+
+// errorCodes 哨兵错误 -> 错误码，按顺序匹配，具体原因在前、执行阶段在后
+var errorCodes = []struct {
+	err  error
+	code string
+}{
+	{ErrEngineClosed, ErrCodeEngineClosed},
+	{ErrInvalidBizCode, ErrCodeInvalidBizCode},
+	{ErrNilInput, ErrCodeNilInput},
+	{ErrBizDisabled, ErrCodeBizDisabled},
+	{ErrQuotaExceeded, ErrCodeQuotaExceeded},
+	{ErrReadOnly, ErrCodeReadOnly},
+	{ErrNoRuleMapper, ErrCodeNoRuleMapper},
+	{ErrVersionNotFound, ErrCodeVersionNotFound},
+	{ErrOverrideNotFound, ErrCodeOverrideNotFound},
+	{ErrKnowledgeBaseNotFound, ErrCodeKnowledgeBaseNotFound},
+	{ErrInputTypeNotRegistered, ErrCodeInputTypeNotFound},
+	{ErrCostBudgetExceeded, ErrCodeCostBudgetExceeded},
+	{ErrRuleLimitExceeded, ErrCodeRuleLimitExceeded},
+	{ErrUndeclaredWrite, ErrCodeUndeclaredWrite},
+	{ErrInvalidFunction, ErrCodeInvalidFunction},
+	{ErrFunctionTimeout, ErrCodeFunctionTimeout},
+	{ErrRulePanic, ErrCodeRulePanic},
+	{ErrNonFiniteResult, ErrCodeNonFiniteResult},
+	{ErrStringTooLong, ErrCodeStringTooLong},
+	{ErrInvalidBands, ErrCodeInvalidBands},
+	{ErrDimensionMismatch, ErrCodeInvalidVector},
+	{ErrInvalidVector, ErrCodeInvalidVector},
+	{ErrNoRateProvider, ErrCodeNoRateProvider},
+	{ErrStaleRate, ErrCodeStaleRate},
+	{ErrNoCalendar, ErrCodeNoCalendar},
+	{ErrUnknownRegion, ErrCodeUnknownRegion},
+	{ErrRulesNotFound, ErrCodeRulesNotFound},
+	{ErrCompileFailed, ErrCodeCompileFailed},
+	{ErrInjectFailed, ErrCodeInjectFailed},
+	{ErrExecFailed, ErrCodeExecFailed},
+	{ErrResultFailed, ErrCodeResultFailed},
+}
+
+// errorMessages 区域 -> 错误码 -> 错误说明
+var errorMessages = map[string]map[string]string{
+	LocaleZhCN: {
+		ErrCodeEngineClosed:          "引擎已关闭",
+		ErrCodeInvalidBizCode:        "无效的业务码",
+		ErrCodeNilInput:              "输入参数为空",
+		ErrCodeBizDisabled:           "业务码已被紧急停用",
+		ErrCodeQuotaExceeded:         "超出执行配额",
+		ErrCodeReadOnly:              "引擎为只读模式，禁止写操作",
+		ErrCodeNoRuleMapper:          "未配置规则映射器",
+		ErrCodeRulesNotFound:         "规则未找到",
+		ErrCodeVersionNotFound:       "规则集版本不可用",
+		ErrCodeOverrideNotFound:      "运行时覆盖规则不存在",
+		ErrCodeKnowledgeBaseNotFound: "知识库不存在",
+		ErrCodeInputTypeNotFound:     "业务码未注册输入类型",
+		ErrCodeCostBudgetExceeded:    "规则集预估成本超出延迟预算",
+		ErrCodeRuleLimitExceeded:     "规则集超出规模或复杂度限制",
+		ErrCodeUndeclaredWrite:       "规则写入未声明的结果字段",
+		ErrCodeInvalidFunction:       "自定义函数签名不合法",
+		ErrCodeFunctionTimeout:       "自定义函数执行超时",
+		ErrCodeRulePanic:             "规则执行发生panic",
+		ErrCodeNonFiniteResult:       "结果包含非有限数值",
+		ErrCodeStringTooLong:         "字符串超过相似度计算的最大长度",
+		ErrCodeInvalidBands:          "区间定义无效",
+		ErrCodeInvalidVector:         "无效的向量",
+		ErrCodeNoRateProvider:        "未设置汇率提供者",
+		ErrCodeStaleRate:             "汇率已过期",
+		ErrCodeNoCalendar:            "未设置节假日日历",
+		ErrCodeUnknownRegion:         "日历中没有该地区",
+		ErrCodeCompileFailed:         "规则编译失败",
+		ErrCodeInjectFailed:          "数据注入失败",
+		ErrCodeExecFailed:            "规则执行失败",
+		ErrCodeResultFailed:          "结果提取失败",
+		ErrCodeUnknown:               "未知错误",
+	},
+	LocaleEnUS: {
+		ErrCodeEngineClosed:          "engine is closed",
+		ErrCodeInvalidBizCode:        "invalid business code",
+		ErrCodeNilInput:              "input is nil",
+		ErrCodeBizDisabled:           "business code is disabled by kill switch",
+		ErrCodeQuotaExceeded:         "execution quota exceeded",
+		ErrCodeReadOnly:              "engine is read-only, write operations are rejected",
+		ErrCodeNoRuleMapper:          "no rule mapper configured",
+		ErrCodeRulesNotFound:         "no rules found",
+		ErrCodeVersionNotFound:       "rule set version is not available",
+		ErrCodeOverrideNotFound:      "runtime override not found",
+		ErrCodeKnowledgeBaseNotFound: "knowledge base not found",
+		ErrCodeInputTypeNotFound:     "no input type registered for business code",
+		ErrCodeCostBudgetExceeded:    "estimated rule set cost exceeds latency budget",
+		ErrCodeRuleLimitExceeded:     "rule set exceeds size or complexity limits",
+		ErrCodeUndeclaredWrite:       "rule writes an undeclared result field",
+		ErrCodeInvalidFunction:       "invalid custom function signature",
+		ErrCodeFunctionTimeout:       "custom function timed out",
+		ErrCodeRulePanic:             "rule execution panicked",
+		ErrCodeNonFiniteResult:       "result contains non-finite number",
+		ErrCodeStringTooLong:         "string exceeds maximum similarity length",
+		ErrCodeInvalidBands:          "invalid band definition",
+		ErrCodeInvalidVector:         "invalid vector",
+		ErrCodeNoRateProvider:        "no exchange rate provider configured",
+		ErrCodeStaleRate:             "exchange rate is stale",
+		ErrCodeNoCalendar:            "no holiday calendar configured",
+		ErrCodeUnknownRegion:         "region not found in calendar",
+		ErrCodeCompileFailed:         "rule compilation failed",
+		ErrCodeInjectFailed:          "input injection failed",
+		ErrCodeExecFailed:            "rule execution failed",
+		ErrCodeResultFailed:          "result extraction failed",
+		ErrCodeUnknown:               "unknown error",
+	},
+}
+
+// LocalizedError 带错误码的本地化错误
+type LocalizedError struct {
+	Code    string // 错误码，不随区域变化
+	Locale  string // 错误信息区域，LocaleZhCN 或 LocaleEnUS
+	BizCode string // 业务码，可为空
+	Err     error  // 原始错误
+}
+
+// Error 按区域输出错误信息，非中文区域在说明、错误码和业务码之后附上原始信息
+func (e *LocalizedError) Error() string {
+	if e.Locale == LocaleZhCN {
+		return e.Err.Error()
+	}
+	if e.BizCode != "" {
+		return fmt.Sprintf("%s (code=%s, bizCode=%s): %s", e.Message(), e.Code, e.BizCode, e.Err.Error())
+	}
+	return fmt.Sprintf("%s (code=%s): %s", e.Message(), e.Code, e.Err.Error())
+}
+
+// Unwrap 返回原始错误
+func (e *LocalizedError) Unwrap() error {
+	return e.Err
+}
+
+// Message 错误码在当前区域的说明，不含细节
+func (e *LocalizedError) Message() string {
+	return ErrorMessage(e.Code, e.Locale)
+}
+
+// Detail 原始错误信息，包含业务码、版本、规则名等细节
+func (e *LocalizedError) Detail() string {
+	return e.Err.Error()
+}
+
+// ErrorCode 获取错误的错误码 - 优先使用错误链中的 *LocalizedError，否则按哨兵错误匹配
+//
+// 返回值:
+//
+//	string - 错误码，err为nil时返回空字符串，无法分类时返回 ErrCodeUnknown
+func ErrorCode(err error) string {
+	if err == nil {
+		return ""
+	}
+	var localized *LocalizedError
+	if errors.As(err, &localized) {
+		return localized.Code
+	}
+	for _, entry := range errorCodes {
+		if errors.Is(err, entry.err) {
+			return entry.code
+		}
+	}
+	return ErrCodeUnknown
+}
+
+// ErrorMessage 错误码在区域中的说明，未收录的错误码返回错误码本身
+func ErrorMessage(code, locale string) string {
+	if message, ok := errorMessages[ErrorLocale(locale)][code]; ok {
+		return message
+	}
+	return code
+}
+
+// ErrorLocale 解析错误信息区域 - 为空或中文时为 LocaleZhCN，其他区域为 LocaleEnUS
+func ErrorLocale(locale string) string {
+	locale = strings.TrimSpace(locale)
+	if locale == "" {
+		return LocaleZhCN
+	}
+	language, _, _ := strings.Cut(strings.ReplaceAll(locale, "_", "-"), "-")
+	if strings.EqualFold(language, "zh") {
+		return LocaleZhCN
+	}
+	return LocaleEnUS
+}
+
+// LocalizeError 包装为指定区域的 *LocalizedError，err本身已包装时按新区域重新包装，区域相同时原样返回
+//
+// 参数:
+//
+//	err     - 原始错误，为nil时返回nil
+//	locale  - 区域，按 ErrorLocale 解析
+//	bizCode - 业务码，可为空
+func LocalizeError(err error, locale, bizCode string) error {
+	if err == nil {
+		return nil
+	}
+	if localized, ok := err.(*LocalizedError); ok {
+		if bizCode == "" {
+			bizCode = localized.BizCode
+		}
+		if localized.Locale == ErrorLocale(locale) && localized.BizCode == bizCode {
+			return localized
+		}
+		return &LocalizedError{Code: localized.Code, Locale: ErrorLocale(locale), BizCode: bizCode, Err: localized.Err}
+	}
+	return &LocalizedError{Code: ErrorCode(err), Locale: ErrorLocale(locale), BizCode: bizCode, Err: err}
+}
+
+// localizeError 按引擎的错误信息区域包装执行错误
+func (e *engineImpl[T]) localizeError(bizCode string, err error) error {
+	locale := ""
+	if e.config != nil {
+		locale = e.config.ErrorLocale
+	}
+	return LocalizeError(err, locale, bizCode)
+}
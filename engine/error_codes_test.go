@@ -0,0 +1,97 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+// TestErrorCodes 测试错误码和本地化错误信息
+func TestErrorCodes(t *testing.T) {
+	Convey("错误码与本地化", t, func() {
+		Convey("按哨兵错误匹配错误码，具体原因优先于执行阶段", func() {
+			So(ErrorCode(nil), ShouldEqual, "")
+			So(ErrorCode(fmt.Errorf("业务码 PAY: %w", ErrBizDisabled)), ShouldEqual, ErrCodeBizDisabled)
+			So(ErrorCode(fmt.Errorf("%w: %w", ErrCompileFailed, ErrCostBudgetExceeded)), ShouldEqual, ErrCodeCostBudgetExceeded)
+			So(ErrorCode(fmt.Errorf("%w: 语法错误", ErrCompileFailed)), ShouldEqual, ErrCodeCompileFailed)
+			So(ErrorCode(errors.New("其他错误")), ShouldEqual, ErrCodeUnknown)
+		})
+
+		Convey("解析错误信息区域", func() {
+			So(ErrorLocale(""), ShouldEqual, LocaleZhCN)
+			So(ErrorLocale("zh_TW"), ShouldEqual, LocaleZhCN)
+			So(ErrorLocale("en-GB"), ShouldEqual, LocaleEnUS)
+			So(ErrorLocale("de-DE"), ShouldEqual, LocaleEnUS)
+		})
+
+		Convey("每个错误码都有中英文说明", func() {
+			for _, entry := range errorCodes {
+				So(errorMessages[LocaleZhCN], ShouldContainKey, entry.code)
+				So(errorMessages[LocaleEnUS], ShouldContainKey, entry.code)
+			}
+			So(ErrorMessage("missing_code", LocaleEnUS), ShouldEqual, "missing_code")
+		})
+
+		Convey("中文保留原始信息，英文输出说明、错误码、业务码和原始信息", func() {
+			cause := fmt.Errorf("业务码 PAY（故障）: %w", ErrBizDisabled)
+
+			zh := LocalizeError(cause, "", "PAY")
+			So(zh.Error(), ShouldEqual, cause.Error())
+			So(errors.Is(zh, ErrBizDisabled), ShouldBeTrue)
+
+			en := LocalizeError(zh, "en-US", "")
+			So(en.Error(), ShouldEqual, "business code is disabled by kill switch (code=biz_disabled, bizCode=PAY): "+cause.Error())
+			So(errors.Is(en, ErrBizDisabled), ShouldBeTrue)
+
+			var localized *LocalizedError
+			So(errors.As(en, &localized), ShouldBeTrue)
+			So(localized.Detail(), ShouldEqual, cause.Error())
+			So(localized.Message(), ShouldEqual, "business code is disabled by kill switch")
+			So(LocalizeError(en, "en", "PAY"), ShouldEqual, en)
+			So(LocalizeError(nil, "en-US", "PAY"), ShouldBeNil)
+			So(LocalizeError(cause, "en-US", "").Error(), ShouldEqual, "business code is disabled by kill switch (code=biz_disabled): "+cause.Error())
+		})
+
+		Convey("Exec 按引擎的错误信息区域返回带错误码的错误", func() {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mapper := rule.NewMockRuleMapper(ctrl)
+			mapper.EXPECT().FindByBizCode(gomock.Any(), "empty").Return(nil, nil).AnyTimes()
+
+			cfg := config.DefaultConfig()
+			engine := NewEngineImpl[map[string]any](
+				cfg, mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+				ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+			)
+			defer engine.Close()
+			ctx := context.Background()
+
+			_, err := engine.Exec(ctx, "empty", map[string]any{})
+			So(ErrorCode(err), ShouldEqual, ErrCodeRulesNotFound)
+			So(errors.Is(err, ErrRulesNotFound), ShouldBeTrue)
+			So(err.Error(), ShouldEqual, "未定义错误: 规则未找到")
+
+			// 格式化区域不影响错误信息语言
+			cfg.Locale = "en-US"
+			_, err = engine.Exec(ctx, "empty", nil)
+			So(ErrorCode(err), ShouldEqual, ErrCodeNilInput)
+			So(err.Error(), ShouldEqual, "未定义错误: 输入参数为空")
+
+			cfg.ErrorLocale = "en-US"
+			_, err = engine.Exec(ctx, "empty", nil)
+			So(ErrorCode(err), ShouldEqual, ErrCodeNilInput)
+			So(err.Error(), ShouldEqual, "input is nil (code=nil_input, bizCode=empty): 未定义错误: 输入参数为空")
+		})
+	})
+}
@@ -0,0 +1,105 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// 近期错误环形缓冲 - 生产环境无需开启详细日志即可定位问题
+// ============================================================================
+
+// ErrorClass 错误分类
+type ErrorClass string
+
+const (
+	ErrorClassFetch      ErrorClass = "fetch"      // 规则获取失败
+	ErrorClassCompile    ErrorClass = "compile"    // 规则编译失败
+	ErrorClassConversion ErrorClass = "conversion" // 数据注入或结果转换失败
+	ErrorClassTimeout    ErrorClass = "timeout"    // 执行超时或取消
+	ErrorClassExecution  ErrorClass = "execution"  // 规则执行失败
+	ErrorClassPanic      ErrorClass = "panic"      // 规则执行发生panic
+)
+
+// ErrorRecord 错误记录
+type ErrorRecord struct {
+	Time    time.Time  `json:"time"`     // 发生时间
+	BizCode string     `json:"biz_code"` // 业务码
+	Class   ErrorClass `json:"class"`    // 错误分类
+	Message string     `json:"message"`  // 错误信息
+}
+
+// errorRing 固定容量的错误环形缓冲，写满后覆盖最旧的记录
+type errorRing struct {
+	mu      sync.Mutex
+	records []ErrorRecord
+	next    int
+	full    bool
+}
+
+// newErrorRing 创建错误环形缓冲，容量<=0时返回nil（不记录）
+func newErrorRing(capacity int) *errorRing {
+	if capacity <= 0 {
+		return nil
+	}
+	return &errorRing{records: make([]ErrorRecord, capacity)}
+}
+
+// add 追加错误记录
+func (r *errorRing) add(record ErrorRecord) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.records[r.next] = record
+	r.next = (r.next + 1) % len(r.records)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// snapshot 按时间先后返回所有记录
+func (r *errorRing) snapshot() []ErrorRecord {
+	if r == nil {
+		return []ErrorRecord{}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.full {
+		return append([]ErrorRecord{}, r.records[:r.next]...)
+	}
+
+	result := make([]ErrorRecord, 0, len(r.records))
+	result = append(result, r.records[r.next:]...)
+	return append(result, r.records[:r.next]...)
+}
+
+// RecentErrors 获取近期错误记录，按发生时间先后排列
+func (e *engineImpl[T]) RecentErrors() []ErrorRecord {
+	return e.recentErrors.snapshot()
+}
+
+// recordError 记录错误，超时和取消统一归类为timeout
+func (e *engineImpl[T]) recordError(ctx context.Context, bizCode string, class ErrorClass, err error) {
+	if err == nil {
+		return
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) || ctx.Err() != nil {
+		class = ErrorClassTimeout
+	}
+
+	e.recentErrors.add(ErrorRecord{
+		Time:    time.Now(),
+		BizCode: bizCode,
+		Class:   class,
+		Message: err.Error(),
+	})
+}
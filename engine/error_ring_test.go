@@ -0,0 +1,130 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+// TestErrorRing 测试近期错误环形缓冲
+func TestErrorRing(t *testing.T) {
+	Convey("近期错误环形缓冲", t, func() {
+
+		Convey("容量内按顺序返回", func() {
+			ring := newErrorRing(3)
+			ring.add(ErrorRecord{Message: "a"})
+			ring.add(ErrorRecord{Message: "b"})
+
+			records := ring.snapshot()
+			So(records, ShouldHaveLength, 2)
+			So(records[0].Message, ShouldEqual, "a")
+			So(records[1].Message, ShouldEqual, "b")
+		})
+
+		Convey("写满后覆盖最旧记录", func() {
+			ring := newErrorRing(2)
+			for _, msg := range []string{"a", "b", "c"} {
+				ring.add(ErrorRecord{Message: msg})
+			}
+
+			records := ring.snapshot()
+			So(records, ShouldHaveLength, 2)
+			So(records[0].Message, ShouldEqual, "b")
+			So(records[1].Message, ShouldEqual, "c")
+		})
+
+		Convey("容量为0时不记录", func() {
+			ring := newErrorRing(0)
+			So(ring, ShouldBeNil)
+			ring.add(ErrorRecord{Message: "a"})
+			So(ring.snapshot(), ShouldBeEmpty)
+		})
+	})
+
+	Convey("引擎错误分类", t, func() {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mapper := rule.NewMockRuleMapper(ctrl)
+		engine := NewEngineImpl[map[string]any](
+			config.DefaultConfig(), mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+			ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+		)
+
+		mapper.EXPECT().FindByBizCode(gomock.Any(), "fetch_biz").Return(nil, fmt.Errorf("db down"))
+		mapper.EXPECT().FindByBizCode(gomock.Any(), "compile_biz").Return([]*rule.Rule{
+			{Name: "bad", GRL: "rule Broken {", Enabled: true},
+		}, nil)
+		mapper.EXPECT().FindByBizCode(gomock.Any(), "timeout_biz").Return([]*rule.Rule{
+			{Name: "ok", GRL: `rule T "超时" { when Params["x"] > 0 then Result["x"] = 1; Retract("T"); }`, Enabled: true},
+		}, nil)
+
+		ctx := context.Background()
+		_, _ = engine.Exec(ctx, "fetch_biz", map[string]any{})
+		_, _ = engine.Exec(ctx, "compile_biz", map[string]any{})
+
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		_, _ = engine.Exec(cancelled, "timeout_biz", map[string]any{"x": 1})
+
+		records := engine.RecentErrors()
+		So(records, ShouldHaveLength, 3)
+		So(records[0].BizCode, ShouldEqual, "fetch_biz")
+		So(records[0].Class, ShouldEqual, ErrorClassFetch)
+		So(records[1].Class, ShouldEqual, ErrorClassCompile)
+		So(records[2].Class, ShouldEqual, ErrorClassTimeout)
+		So(engine.DebugSnapshot().RecentErrors, ShouldHaveLength, 3)
+	})
+	Convey("执行中途取消上下文", t, func() {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mapper := rule.NewMockRuleMapper(ctrl)
+		mapper.EXPECT().FindByBizCode(gomock.Any(), "cancel_biz").Return([]*rule.Rule{
+			{Name: "first", GRL: `rule First "先执行" salience 10 { when true then Emit("items", 1); Retract("First"); }`, Enabled: true},
+			{Name: "second", GRL: `rule Second "后执行" { when true then Emit("items", 2); Retract("Second"); }`, Enabled: true},
+		}, nil).AnyTimes()
+		engine := NewEngineImpl[map[string]any](
+			config.DefaultConfig(), mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+			ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+		)
+		defer engine.Close()
+
+		// 第一条规则的动作中取消上下文，下一周期开始前停止执行
+		cancelled, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		var items []any
+		_, err := engine.Exec(cancelled, "cancel_biz", map[string]any{}, WithResultStream("items", 0, func(element any) error {
+			items = append(items, element)
+			cancel()
+			return nil
+		}))
+
+		So(errors.Is(err, context.Canceled), ShouldBeTrue)
+		So(errors.Is(err, ErrExecFailed), ShouldBeTrue)
+		So(items, ShouldHaveLength, 1)
+		records := engine.RecentErrors()
+		So(records, ShouldHaveLength, 1)
+		So(records[0].Class, ShouldEqual, ErrorClassTimeout)
+
+		// 取消不影响之后的执行
+		items = nil
+		_, err = engine.Exec(context.Background(), "cancel_biz", map[string]any{}, WithResultStream("items", 0, func(element any) error {
+			items = append(items, element)
+			return nil
+		}))
+		So(err, ShouldBeNil)
+		So(items, ShouldHaveLength, 2)
+	})
+}
@@ -0,0 +1,65 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+)
+
+// ============================================================================
+// 执行中间件 - 由使用方包裹Exec管线，实现鉴权、指标、故障注入、缓存等横切逻辑
+// ============================================================================
+
+// ExecFunc 规则执行函数 - Exec管线及中间件包裹后的形式，结果为引擎的结果类型
+type ExecFunc func(ctx context.Context, bizCode string, input any, opts ...ExecOption) (any, error)
+
+// ExecMiddleware 执行中间件 - 接收下一环节并返回包裹后的执行函数
+//
+// 中间件可以在调用next前后附加逻辑、修改参数，或不调用next直接返回（如鉴权失败、命中缓存）；
+// 直接返回的结果须为引擎的结果类型。
+type ExecMiddleware func(next ExecFunc) ExecFunc
+
+// Use 注册执行中间件 - 先注册的中间件在外层，多次调用时追加
+//
+// Exec、ExecVersion和ExecWhere均经过中间件。
+func (e *engineImpl[T]) Use(middleware ...ExecMiddleware) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	for _, m := range middleware {
+		if m != nil {
+			e.middlewares = append(e.middlewares, m)
+		}
+	}
+
+	var handler ExecFunc = func(ctx context.Context, bizCode string, input any, opts ...ExecOption) (any, error) {
+		return e.exec(ctx, bizCode, input, opts...)
+	}
+	for i := len(e.middlewares) - 1; i >= 0; i-- {
+		handler = e.middlewares[i](handler)
+	}
+	e.handler = handler
+}
+
+// Exec 规则执行器的核心方法 - 根据业务码执行对应的GRL规则集，注册了中间件时经中间件调用
+func (e *engineImpl[T]) Exec(ctx context.Context, bizCode string, input any, opts ...ExecOption) (T, error) {
+	e.mutex.RLock()
+	handler := e.handler
+	e.mutex.RUnlock()
+	if handler == nil {
+		return e.exec(ctx, bizCode, input, opts...)
+	}
+
+	var zero T
+	result, err := handler(ctx, bizCode, input, opts...)
+	if result == nil {
+		return zero, err
+	}
+	typed, ok := result.(T)
+	if !ok {
+		if err != nil {
+			return zero, err
+		}
+		return zero, fmt.Errorf("执行中间件返回的结果类型 %T 与引擎结果类型 %T 不符", result, zero)
+	}
+	return typed, err
+}
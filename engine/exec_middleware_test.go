@@ -0,0 +1,93 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+// TestExecMiddleware 测试执行中间件
+func TestExecMiddleware(t *testing.T) {
+	Convey("执行中间件", t, func() {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mapper := rule.NewMockRuleMapper(ctrl)
+		mapper.EXPECT().FindByBizCode(gomock.Any(), "mw").Return([]*rule.Rule{
+			{Name: "ok", Enabled: true, GRL: `rule Ok "通过" { when Params["amount"] > 0 then Result["ok"] = true; Retract("Ok"); }`},
+		}, nil).AnyTimes()
+		engine := NewEngineImpl[map[string]any](
+			config.DefaultConfig(), mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+			ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+		)
+		defer engine.Close()
+		ctx := context.Background()
+		input := map[string]any{"amount": 1}
+
+		trace := func(name string, calls *[]string) ExecMiddleware {
+			return func(next ExecFunc) ExecFunc {
+				return func(ctx context.Context, bizCode string, input any, opts ...ExecOption) (any, error) {
+					*calls = append(*calls, name+">")
+					result, err := next(ctx, bizCode, input, opts...)
+					*calls = append(*calls, "<"+name)
+					return result, err
+				}
+			}
+		}
+
+		Convey("先注册的中间件在外层", func() {
+			var calls []string
+			engine.Use(trace("auth", &calls), nil)
+			engine.Use(trace("metrics", &calls))
+
+			result, err := engine.Exec(ctx, "mw", input)
+			So(err, ShouldBeNil)
+			So(result["ok"], ShouldEqual, true)
+			So(calls, ShouldResemble, []string{"auth>", "metrics>", "<metrics", "<auth"})
+
+			calls = nil
+			_, err = engine.ExecWhere(ctx, "mw", `name == "ok"`, input)
+			So(err, ShouldBeNil)
+			So(calls, ShouldHaveLength, 4)
+		})
+
+		Convey("中间件可直接返回", func() {
+			errDenied := errors.New("denied")
+			engine.Use(func(next ExecFunc) ExecFunc {
+				return func(ctx context.Context, bizCode string, input any, opts ...ExecOption) (any, error) {
+					if bizCode == "mw" {
+						return map[string]any{"cached": true}, nil
+					}
+					return nil, errDenied
+				}
+			})
+
+			result, err := engine.Exec(ctx, "mw", input)
+			So(err, ShouldBeNil)
+			So(result, ShouldResemble, map[string]any{"cached": true})
+
+			_, err = engine.Exec(ctx, "other", input)
+			So(err, ShouldEqual, errDenied)
+		})
+
+		Convey("返回类型不符时报错", func() {
+			engine.Use(func(next ExecFunc) ExecFunc {
+				return func(ctx context.Context, bizCode string, input any, opts ...ExecOption) (any, error) {
+					return "not a map", nil
+				}
+			})
+			_, err := engine.Exec(ctx, "mw", input)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
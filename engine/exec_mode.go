@@ -0,0 +1,102 @@
+package engine
+
+import (
+	"gitee.com/damengde/runehammer/config"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+)
+
+// ============================================================================
+// 执行模式 - 由引擎控制一次执行命中的规则数，规则作者无需手写Retract编排
+// ============================================================================
+//
+// Grule每个周期只执行条件成立的规则中salience最高的一条，再重新求值进入下一周期：
+//
+//	ExecModeAllMatching  不做干预，直到没有条件成立的规则
+//	ExecModeBestMatch    首个周期选中的规则执行后结束，即salience最高的命中规则
+//	ExecModeFirstMatch   编译时按规则顺序重写salience（越靠前越高），再按BestMatch执行，即规则顺序中第一条命中的规则
+
+// ExecMode 获取业务码的规则执行模式
+//
+// 优先使用 Config.BizExecModes 中业务码的配置，启用层级继承时依次查找父业务码，均未配置时使用 Config.ExecMode。
+func (e *engineImpl[T]) ExecMode(bizCode string) config.ExecMode {
+	if e.config == nil {
+		return config.ExecModeAllMatching
+	}
+	if len(e.config.BizExecModes) > 0 {
+		chain := []string{bizCode}
+		if e.inheritanceEnabled() {
+			chain = bizCodeChain(bizCode)
+		}
+		for _, code := range chain {
+			if mode, ok := e.config.BizExecModes[code]; ok {
+				return mode
+			}
+		}
+	}
+	return e.config.ExecMode
+}
+
+// compiledKnowledgeBase 获取按执行模式mode编译的知识库，编译时的执行模式不同时视为未编译
+func (e *engineImpl[T]) compiledKnowledgeBase(bizCode string, mode config.ExecMode) (*ast.KnowledgeBase, bool) {
+	kb, ok := e.knowledgeBases.Load(bizCode)
+	if !ok {
+		return nil, false
+	}
+	if info, ok := e.compileInfos.Load(bizCode); ok && info.(CompileInfo).ExecMode != mode {
+		return nil, false
+	}
+	return kb.(*ast.KnowledgeBase), true
+}
+
+// applyExecMode 按执行模式调整编译后的知识库 - 首条命中模式下按规则顺序重写salience
+//
+// 规则顺序为获取顺序（启用继承时子级在前），同一规则内按GRL声明顺序；未在规则列表中找到的规则排在最后。
+func applyExecMode(mode config.ExecMode, rules []*rule.Rule, kb *ast.KnowledgeBase) {
+	if mode != config.ExecModeFirstMatch || kb == nil {
+		return
+	}
+
+	order := make(map[string]int)
+	for _, r := range rules {
+		if !r.Enabled {
+			continue
+		}
+		for _, name := range ruleEntryNames(r) {
+			if _, ok := order[name]; !ok {
+				order[name] = len(order)
+			}
+		}
+	}
+	for name, entry := range kb.RuleEntries {
+		if idx, ok := order[name]; ok {
+			entry.Salience = len(order) - idx
+		} else {
+			entry.Salience = 0
+		}
+	}
+}
+
+// singleMatchGate 单条命中闸门 - 首条规则执行时结束本次执行，规则的动作仍完整执行
+type singleMatchGate struct {
+	data ast.IDataContext
+}
+
+// newSingleMatchGate 按执行模式创建闸门，全部命中模式返回nil
+func newSingleMatchGate(mode config.ExecMode, dataCtx ast.IDataContext) *singleMatchGate {
+	if mode != config.ExecModeFirstMatch && mode != config.ExecModeBestMatch {
+		return nil
+	}
+	return &singleMatchGate{data: dataCtx}
+}
+
+// EvaluateRuleEntry 实现GruleEngineListener
+func (g *singleMatchGate) EvaluateRuleEntry(cycle uint64, entry *ast.RuleEntry, candidate bool) {}
+
+// ExecuteRuleEntry 实现GruleEngineListener - Grule在规则动作执行后检查完成标记
+func (g *singleMatchGate) ExecuteRuleEntry(cycle uint64, entry *ast.RuleEntry) {
+	g.data.Complete()
+}
+
+// BeginCycle 实现GruleEngineListener
+func (g *singleMatchGate) BeginCycle(cycle uint64) {}
@@ -0,0 +1,97 @@
+package engine
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+// TestExecMode 测试规则执行模式
+func TestExecMode(t *testing.T) {
+	Convey("规则执行模式", t, func() {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		cfg := config.DefaultConfig()
+		cfg.BizCodeInheritance = true
+		cfg.BizExecModes = map[string]config.ExecMode{
+			"first": config.ExecModeFirstMatch,
+			"best":  config.ExecModeBestMatch,
+		}
+		mapper := rule.NewMockRuleMapper(ctrl)
+		engine := NewEngineImpl[map[string]any](
+			cfg, mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+			ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+		)
+		defer engine.Close()
+
+		// 三条规则条件可同时成立，salience与声明顺序相反
+		rulesFor := func(bizCode string) []*rule.Rule {
+			return []*rule.Rule{
+				{BizCode: bizCode, Name: "bronze", Enabled: true, GRL: `rule bronze "铜牌" salience 1 { when Params["score"] > 10 then Result["bronze"] = true; Retract("bronze"); }`},
+				{BizCode: bizCode, Name: "silver", Enabled: true, GRL: `rule silver "银牌" salience 2 { when Params["score"] > 20 then Result["silver"] = true; Retract("silver"); }`},
+				{BizCode: bizCode, Name: "gold", Enabled: true, GRL: `rule gold "金牌" salience 3 { when Params["score"] > 30 then Result["gold"] = true; Retract("gold"); }`},
+			}
+		}
+		for _, bizCode := range []string{"all", "first", "best"} {
+			mapper.EXPECT().FindByBizCode(gomock.Any(), bizCode).Return(rulesFor(bizCode), nil).AnyTimes()
+		}
+		mapper.EXPECT().FindByBizCode(gomock.Any(), "first.vip").Return(nil, nil).AnyTimes()
+		exec := func(bizCode string, score int) []string {
+			result, err := engine.Exec(context.Background(), bizCode, map[string]any{"score": score})
+			So(err, ShouldBeNil)
+			var hits []string
+			for _, name := range []string{"bronze", "silver", "gold"} {
+				if result[name] == true {
+					hits = append(hits, name)
+				}
+			}
+			return hits
+		}
+
+		Convey("按业务码解析执行模式", func() {
+			So(engine.ExecMode("all"), ShouldEqual, config.ExecModeAllMatching)
+			So(engine.ExecMode("first.vip"), ShouldEqual, config.ExecModeFirstMatch)
+			So(engine.ExecMode("best"), ShouldEqual, config.ExecModeBestMatch)
+		})
+
+		Convey("全部命中模式执行所有条件成立的规则", func() {
+			So(exec("all", 50), ShouldResemble, []string{"bronze", "silver", "gold"})
+		})
+
+		Convey("首条命中模式按规则顺序只执行第一条", func() {
+			So(exec("first", 50), ShouldResemble, []string{"bronze"})
+			So(exec("first", 25), ShouldResemble, []string{"bronze"})
+			So(exec("first", 5), ShouldBeEmpty)
+		})
+
+		Convey("执行模式变更后重新编译", func() {
+			So(exec("all", 50), ShouldResemble, []string{"bronze", "silver", "gold"})
+
+			cfg.BizExecModes["all"] = config.ExecModeFirstMatch
+			So(exec("all", 50), ShouldResemble, []string{"bronze"})
+			info, _ := engine.compileInfos.Load("all")
+			So(info.(CompileInfo).ExecMode, ShouldEqual, config.ExecModeFirstMatch)
+
+			// 首条命中重写的salience不残留到其他模式
+			delete(cfg.BizExecModes, "all")
+			So(exec("all", 50), ShouldResemble, []string{"bronze", "silver", "gold"})
+			cfg.BizExecModes["all"] = config.ExecModeBestMatch
+			So(exec("all", 50), ShouldResemble, []string{"gold"})
+		})
+
+		Convey("最优命中模式只执行salience最高的命中规则", func() {
+			So(exec("best", 50), ShouldResemble, []string{"gold"})
+			So(exec("best", 25), ShouldResemble, []string{"silver"})
+		})
+	})
+}
@@ -0,0 +1,65 @@
+package engine
+
+// ============================================================================
+// 执行选项 - 单次Exec调用级别的可选参数
+// ============================================================================
+
+// ExecOption 执行选项 - 作用于单次规则执行
+type ExecOption func(*ExecOptions)
+
+// ExecOptions 单次执行的选项集合
+type ExecOptions struct {
+	IdempotencyKey string         // 幂等键，非空时在幂等窗口内复用已存储的执行结果
+	Report         *ExecReport    // 执行报告，非空时执行结束后填充
+	Version        int            // 固定执行的规则集版本，<=0表示使用最新版本
+	RuleSetHash    string         // 按内容哈希固定执行的规则集，非空时优先于Version
+	Selector       string         // 规则选择器表达式，非空时只执行被选中的规则
+	Profile        bool           // 是否采集执行剖析，结果写入 Report.Profile
+	Journal        bool           // 是否记录结果变更日志，结果写入 Report.Journal
+	Tenant         string         // 执行所属的租户，用于配额检查和用量上报
+	Params         map[string]any // 执行参数，以Config对象注入，与业务输入分离
+	Stream         *ResultStream  // 结果流式输出，Emit 写入该键的元素交给回调而不写入Result
+
+	session *sessionState // 所在的执行会话，由 Session.Exec 设置
+}
+
+// ExecReport 执行报告 - 由调用方传入，执行结束后填充执行过程信息
+type ExecReport struct {
+	Degraded       bool              // 是否返回了降级结果
+	DegradedReason error             // 降级原因（规则未找到、编译失败等）
+	Coercions      []CoercionRecord  // 输入类型归一化执行的转换
+	FieldErrors    []FieldError      // 规则记录的字段错误（启用字段错误累积时填充）
+	RuleSetVersion int               // 本次执行使用的规则集版本（规则最大版本号），可用于 ExecVersion 固定版本
+	RuleSetHash    string            // 本次执行使用的规则集内容哈希，可用于 WithRuleSetHash 精确固定规则集
+	Profile        *ExecutionProfile // 执行剖析（使用 WithProfiling 时填充）
+	Journal        []ResultChange    // 结果变更日志（使用 WithResultJournal 时填充）
+	Streamed       int               // 流式输出的结果元素数（使用 WithResultStream 时填充）
+	Truncated      bool              // 是否有结果元素因超出上限被丢弃（使用 WithResultStream 时填充）
+}
+
+// WithIdempotencyKey 设置幂等键 - 重试等重复请求在窗口期内直接返回已存储的结果
+//
+// 结果按 (bizCode, 规则集内容哈希, key) 存储，规则集内容变化后旧结果自动失效。
+func WithIdempotencyKey(key string) ExecOption {
+	return func(o *ExecOptions) {
+		o.IdempotencyKey = key
+	}
+}
+
+// WithExecReport 设置执行报告 - 执行结束后将降级等信息写入report
+func WithExecReport(report *ExecReport) ExecOption {
+	return func(o *ExecOptions) {
+		o.Report = report
+	}
+}
+
+// newExecOptions 合并执行选项
+func newExecOptions(opts []ExecOption) *ExecOptions {
+	options := &ExecOptions{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(options)
+		}
+	}
+	return options
+}
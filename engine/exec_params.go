@@ -0,0 +1,44 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+)
+
+// ============================================================================
+// 执行参数 - 与业务输入分离的运行时参数，以Config对象注入，便于按环境调整阈值而无需修改规则
+// ============================================================================
+
+// ExecParamsFact 执行参数在规则中的对象名，规则通过 Config["threshold"] 读取
+const ExecParamsFact = "Config"
+
+// WithParams 设置执行参数 - 多次调用时合并，相同键以后设置的为准
+//
+// 参数以map注入为 Config 对象，未传入参数时注入空map。读取不存在的键会使条件求值出错（规则不命中），
+// 规则可先以 Present("Config.threshold") 判断参数是否传入。
+func WithParams(params map[string]any) ExecOption {
+	return func(o *ExecOptions) {
+		if len(params) == 0 {
+			return
+		}
+		if o.Params == nil {
+			o.Params = make(map[string]any, len(params))
+		}
+		for key, value := range params {
+			o.Params[key] = value
+		}
+	}
+}
+
+// injectExecParams 注入执行参数，复制后注入以免规则修改调用方的map
+func injectExecParams(dataCtx ast.IDataContext, params map[string]any) error {
+	config := make(map[string]any, len(params))
+	for key, value := range params {
+		config[key] = value
+	}
+	if err := dataCtx.Add(ExecParamsFact, config); err != nil {
+		return fmt.Errorf("注入%s变量失败: %w", ExecParamsFact, err)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package engine
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+// TestExecParams 测试执行参数
+func TestExecParams(t *testing.T) {
+	Convey("执行参数", t, func() {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mapper := rule.NewMockRuleMapper(ctrl)
+		engine := NewEngineImpl[map[string]any](
+			config.DefaultConfig(), mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+			ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+		)
+		defer engine.Close()
+
+		rules := []*rule.Rule{
+			{BizCode: "risk", Name: "override", Enabled: true, GRL: `rule override "按参数阈值" salience 10 {
+				when Present("Config.threshold_override") && Params["score"] >= Config["threshold_override"]
+				then Result["flagged"] = true; Result["threshold"] = Config["threshold_override"]; Retract("override"); }`},
+			{BizCode: "risk", Name: "default", Enabled: true, GRL: `rule default "默认阈值" {
+				when !Present("Config.threshold_override") && Params["score"] >= 0.9
+				then Result["flagged"] = true; Retract("default"); }`},
+		}
+		mapper.EXPECT().FindByBizCode(gomock.Any(), "risk").Return(rules, nil).AnyTimes()
+
+		Convey("规则通过Config读取执行参数", func() {
+			result, err := engine.Exec(context.Background(), "risk", map[string]any{"score": 0.85},
+				WithParams(map[string]any{"threshold_override": 0.8}))
+			So(err, ShouldBeNil)
+			So(result["flagged"], ShouldEqual, true)
+			So(result["threshold"], ShouldEqual, 0.8)
+		})
+
+		Convey("未传入参数时使用规则中的默认值", func() {
+			result, err := engine.Exec(context.Background(), "risk", map[string]any{"score": 0.85})
+			So(err, ShouldBeNil)
+			So(result, ShouldNotContainKey, "flagged")
+		})
+
+		Convey("多次设置时合并参数", func() {
+			options := newExecOptions([]ExecOption{
+				WithParams(map[string]any{"a": 1, "b": 2}),
+				WithParams(map[string]any{"b": 3}),
+				WithParams(nil),
+			})
+			So(options.Params, ShouldResemble, map[string]any{"a": 1, "b": 3})
+		})
+	})
+}
@@ -0,0 +1,169 @@
+package engine
+
+import (
+	"sort"
+	"time"
+
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+)
+
+// ============================================================================
+// 执行剖析 - 通过Grule监听器采集周期、事实断言和条件求值耗时，附加到执行报告
+// ============================================================================
+//
+// Grule每个周期依次对未撤回的规则求值条件，再执行优先级最高的候选规则。
+// 事实断言按执行规则动作中的赋值语句计数（Grule的变更计数不包含对map和数组元素的赋值）。
+// 监听器只在条件求值结束后收到通知，因此单条规则的求值耗时按相邻两次通知的间隔计算，
+// 包含其他监听器的开销，适合用于比较规则间的相对开销，不宜作为精确计时。
+
+// ExecutionProfile 执行剖析结果
+type ExecutionProfile struct {
+	Duration       time.Duration  // 规则执行总耗时
+	Cycles         []CycleProfile // 每个周期的统计，最后一个周期通常没有可执行的规则
+	Evaluations    int            // 条件求值总次数
+	FactAssertions int            // 事实断言总次数（执行的规则动作中的赋值语句数）
+	Rules          []RuleProfile  // 每条规则的统计，按条件求值耗时降序
+}
+
+// CycleProfile 单个执行周期的统计
+type CycleProfile struct {
+	Cycle          uint64        // 周期序号，从1开始
+	Evaluations    int           // 求值的规则数
+	Candidates     int           // 条件成立的规则数
+	Fired          string        // 执行的规则名，没有可执行的规则时为空
+	FactAssertions int           // 执行的规则动作中的赋值语句数
+	Duration       time.Duration // 周期耗时（条件求值与动作执行）
+}
+
+// RuleProfile 单条规则的统计
+type RuleProfile struct {
+	Rule           string        // GRL规则名
+	Evaluations    int           // 条件求值次数
+	Candidates     int           // 条件成立次数
+	Fires          int           // 动作执行次数
+	EvalTime       time.Duration // 条件求值累计耗时
+	ExecTime       time.Duration // 动作执行累计耗时
+	FactAssertions int           // 动作中执行的赋值语句累计数
+}
+
+// WithProfiling 启用执行剖析 - 执行结束后将剖析结果写入 ExecReport.Profile，需同时使用 WithExecReport
+func WithProfiling() ExecOption {
+	return func(o *ExecOptions) {
+		o.Profile = true
+	}
+}
+
+// profileRecorder 执行剖析采集器 - 实现GruleEngineListener
+type profileRecorder struct {
+	start     time.Time
+	last      time.Time // 上一次通知的时间
+	profile   ExecutionProfile
+	rules     map[string]*RuleProfile
+	cycle     *CycleProfile
+	cycleAt   time.Time    // 当前周期开始的时间
+	executing *RuleProfile // 正在执行动作的规则
+}
+
+// newProfileRecorder 创建执行剖析采集器
+func newProfileRecorder() *profileRecorder {
+	now := time.Now()
+	return &profileRecorder{start: now, last: now, rules: make(map[string]*RuleProfile)}
+}
+
+// BeginCycle 实现GruleEngineListener
+func (p *profileRecorder) BeginCycle(cycle uint64) {
+	now := time.Now()
+	p.endCycle(now)
+	p.profile.Cycles = append(p.profile.Cycles, CycleProfile{Cycle: cycle})
+	p.cycle = &p.profile.Cycles[len(p.profile.Cycles)-1]
+	p.cycleAt, p.last = now, now
+}
+
+// EvaluateRuleEntry 实现GruleEngineListener
+func (p *profileRecorder) EvaluateRuleEntry(cycle uint64, entry *ast.RuleEntry, candidate bool) {
+	now := time.Now()
+	r := p.rule(entry.RuleName)
+	r.Evaluations++
+	r.EvalTime += now.Sub(p.last)
+	p.profile.Evaluations++
+	if p.cycle != nil {
+		p.cycle.Evaluations++
+	}
+	if candidate {
+		r.Candidates++
+		if p.cycle != nil {
+			p.cycle.Candidates++
+		}
+	}
+	p.last = now
+}
+
+// ExecuteRuleEntry 实现GruleEngineListener
+func (p *profileRecorder) ExecuteRuleEntry(cycle uint64, entry *ast.RuleEntry) {
+	assertions := countAssertions(entry)
+	p.executing = p.rule(entry.RuleName)
+	p.executing.Fires++
+	p.executing.FactAssertions += assertions
+	p.profile.FactAssertions += assertions
+	if p.cycle != nil {
+		p.cycle.Fired = entry.RuleName
+		p.cycle.FactAssertions = assertions
+	}
+	p.last = time.Now()
+}
+
+// countAssertions 统计规则动作中的赋值语句数
+func countAssertions(entry *ast.RuleEntry) int {
+	if entry.ThenScope == nil || entry.ThenScope.ThenExpressionList == nil {
+		return 0
+	}
+	count := 0
+	for _, expr := range entry.ThenScope.ThenExpressionList.ThenExpressions {
+		if expr != nil && expr.Assignment != nil {
+			count++
+		}
+	}
+	return count
+}
+
+// endCycle 结束当前周期 - 累计动作执行耗时和周期耗时
+func (p *profileRecorder) endCycle(now time.Time) {
+	if p.executing != nil {
+		p.executing.ExecTime += now.Sub(p.last)
+		p.executing = nil
+	}
+	if p.cycle != nil {
+		p.cycle.Duration = now.Sub(p.cycleAt)
+		p.cycle = nil
+	}
+}
+
+// rule 获取规则的统计项
+func (p *profileRecorder) rule(name string) *RuleProfile {
+	r, ok := p.rules[name]
+	if !ok {
+		r = &RuleProfile{Rule: name}
+		p.rules[name] = r
+	}
+	return r
+}
+
+// finish 结束采集并返回剖析结果
+func (p *profileRecorder) finish() *ExecutionProfile {
+	now := time.Now()
+	p.endCycle(now)
+
+	profile := p.profile
+	profile.Duration = now.Sub(p.start)
+	profile.Rules = make([]RuleProfile, 0, len(p.rules))
+	for _, r := range p.rules {
+		profile.Rules = append(profile.Rules, *r)
+	}
+	sort.Slice(profile.Rules, func(i, j int) bool {
+		if profile.Rules[i].EvalTime != profile.Rules[j].EvalTime {
+			return profile.Rules[i].EvalTime > profile.Rules[j].EvalTime
+		}
+		return profile.Rules[i].Rule < profile.Rules[j].Rule
+	})
+	return &profile
+}
@@ -0,0 +1,85 @@
+package engine
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+// TestExecutionProfile 测试执行剖析
+func TestExecutionProfile(t *testing.T) {
+	Convey("执行剖析", t, func() {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mapper := rule.NewMockRuleMapper(ctrl)
+		mapper.EXPECT().FindByBizCode(gomock.Any(), "profile").Return([]*rule.Rule{
+			{Name: "score", Enabled: true,
+				GRL: `rule Score "评分" salience 10 { when Params["amount"] > 100 then Result["score"] = 700; Result["level"] = "A"; Retract("Score"); }`},
+			{Name: "approve", Enabled: true,
+				GRL: `rule Approve "审批" { when Result["score"] >= 600 then Result["approved"] = true; Retract("Approve"); }`},
+			{Name: "never", Enabled: true,
+				GRL: `rule Never "从不命中" { when Params["amount"] < 0 then Result["never"] = true; }`},
+		}, nil).AnyTimes()
+
+		engine := NewEngineImpl[map[string]any](
+			config.DefaultConfig(), mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+			ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+		)
+		defer engine.Close()
+		ctx := context.Background()
+
+		Convey("报告中附加周期、事实变更和规则统计", func() {
+			var report ExecReport
+			result, err := engine.Exec(ctx, "profile", map[string]any{"amount": 200}, WithExecReport(&report), WithProfiling())
+			So(err, ShouldBeNil)
+			So(result["approved"], ShouldEqual, true)
+
+			profile := report.Profile
+			So(profile, ShouldNotBeNil)
+			So(profile.Cycles, ShouldHaveLength, 3)
+			So(profile.Cycles[0].Fired, ShouldEqual, "Score")
+			So(profile.Cycles[0].Evaluations, ShouldEqual, 3)
+			So(profile.Cycles[0].Candidates, ShouldEqual, 1)
+			So(profile.Cycles[1].Fired, ShouldEqual, "Approve")
+			So(profile.Cycles[2].Fired, ShouldBeEmpty)
+			So(profile.Evaluations, ShouldEqual, 3+2+1)
+			So(profile.Duration, ShouldBeGreaterThan, 0)
+
+			rules := map[string]RuleProfile{}
+			for _, r := range profile.Rules {
+				rules[r.Rule] = r
+			}
+			So(rules, ShouldHaveLength, 3)
+			So(rules["Never"].Evaluations, ShouldEqual, 3)
+			So(rules["Never"].Candidates, ShouldEqual, 0)
+			So(rules["Score"].Fires, ShouldEqual, 1)
+			So(rules["Approve"].Fires, ShouldEqual, 1)
+
+			// 赋值语句计入执行规则的事实断言，Retract不计入
+			So(rules["Score"].FactAssertions, ShouldEqual, 2)
+			So(rules["Approve"].FactAssertions, ShouldEqual, 1)
+			So(profile.Cycles[0].FactAssertions, ShouldEqual, 2)
+			So(profile.FactAssertions, ShouldEqual, 3)
+		})
+
+		Convey("未启用或未传入报告时不采集", func() {
+			var report ExecReport
+			_, err := engine.Exec(ctx, "profile", map[string]any{"amount": 200}, WithExecReport(&report))
+			So(err, ShouldBeNil)
+			So(report.Profile, ShouldBeNil)
+
+			_, err = engine.Exec(ctx, "profile", map[string]any{"amount": 200}, WithProfiling())
+			So(err, ShouldBeNil)
+		})
+	})
+}
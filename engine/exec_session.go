@@ -0,0 +1,186 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+)
+
+// ============================================================================
+// 执行会话 - 同一输入依次执行多个业务码时复用已注入输入和内置函数的数据上下文
+// ============================================================================
+//
+// 普通执行每次都归一化并注入输入、注入全部内置函数。会话首次执行时完成这些工作，
+// 之后的执行只替换Result、执行参数等单次执行的对象；撤回、完成标记等执行状态每次重新开始。
+// 规则对输入的修改对后续执行可见，与依次调用Exec传入同一输入相同。
+
+// Session 执行会话 - 以同一输入依次执行多个业务码，各次执行的Result互相独立
+//
+// 会话中的执行串行进行，不能跨请求共享。
+type Session[T any] struct {
+	ctx   context.Context
+	input any
+	exec  func(ctx context.Context, bizCode string, input any, opts ...ExecOption) (T, error)
+	state *sessionState
+}
+
+// sessionState 会话中已填充的数据上下文
+type sessionState struct {
+	mu        sync.Mutex
+	owner     any              // 填充数据上下文的引擎
+	base      ast.IDataContext // 已注入输入和内置函数的数据上下文
+	input     any              // 归一化后的输入
+	coercions []CoercionRecord // 输入类型归一化执行的转换
+}
+
+// NewSession 创建执行会话
+//
+// 参数:
+//
+//	ctx   - 会话中各次执行使用的上下文
+//	input - 输入数据
+//	exec  - 执行函数，通常为引擎的Exec
+//
+// 返回值:
+//
+//	*Session[T] - 执行会话
+func NewSession[T any](ctx context.Context, input any, exec func(ctx context.Context, bizCode string, input any, opts ...ExecOption) (T, error)) *Session[T] {
+	return &Session[T]{ctx: ctx, input: input, exec: exec, state: &sessionState{}}
+}
+
+// Exec 以会话的输入执行业务码的规则，执行选项同引擎的Exec
+func (s *Session[T]) Exec(bizCode string, opts ...ExecOption) (T, error) {
+	s.state.mu.Lock()
+	defer s.state.mu.Unlock()
+	return s.exec(s.ctx, bizCode, s.input, append(opts, withSession(s.state))...)
+}
+
+// withSession 在会话中执行
+func withSession(state *sessionState) ExecOption {
+	return func(o *ExecOptions) {
+		o.session = state
+	}
+}
+
+// NewSession 创建执行会话 - 同一输入依次执行多个业务码时只注入一次输入和内置函数
+//
+// 使用示例:
+//
+//	session := engine.NewSession(ctx, input)
+//	for _, bizCode := range []string{"KYC", "RISK", "LIMIT"} {
+//	    result, err := session.Exec(bizCode)
+//	}
+func (e *engineImpl[T]) NewSession(ctx context.Context, input any) *Session[T] {
+	return NewSession[T](ctx, input, e.Exec)
+}
+
+// dataContext 创建本次执行的数据上下文 - 注入输入和内置函数，会话执行复用会话中已填充的数据上下文
+//
+// 返回值:
+//
+//	ast.IDataContext - 数据上下文
+//	any              - 归一化后的输入
+//	error            - 注入错误
+func (e *engineImpl[T]) dataContext(ctx context.Context, bizCode string, input any, options *ExecOptions) (ast.IDataContext, any, error) {
+	session := options.session
+	var (
+		dataCtx   ast.IDataContext
+		coercions []CoercionRecord
+		err       error
+	)
+	switch {
+	case session == nil:
+		dataCtx = ast.NewDataContext()
+		if input, coercions, err = e.populateDataContext(ctx, bizCode, dataCtx, input); err != nil {
+			return nil, nil, err
+		}
+	case session.owner != e:
+		base := ast.NewDataContext()
+		if input, coercions, err = e.populateDataContext(ctx, bizCode, base, input); err != nil {
+			return nil, nil, err
+		}
+		session.owner, session.base, session.input, session.coercions = e, base, input, coercions
+		dataCtx = newSessionContext(base)
+	default:
+		if err := session.base.Add("Result", make(map[string]interface{})); err != nil {
+			return nil, nil, fmt.Errorf("%w: 注入Result变量失败: %w", ErrInjectFailed, err)
+		}
+		input, coercions = session.input, session.coercions
+		dataCtx = newSessionContext(session.base)
+	}
+
+	if options.Report != nil && e.config != nil && e.config.InputCoercion {
+		options.Report.Coercions = coercions
+	}
+	return dataCtx, input, nil
+}
+
+// populateDataContext 归一化并注入输入数据，注入内置函数
+func (e *engineImpl[T]) populateDataContext(ctx context.Context, bizCode string, dataCtx ast.IDataContext, input any) (any, []CoercionRecord, error) {
+	input, coercions := e.normalizeInput(input)
+	if len(coercions) > 0 && e.logger != nil {
+		e.logger.Debugf(ctx, "输入类型已归一化", "bizCode", bizCode, "count", len(coercions))
+	}
+	if err := e.injectInputData(dataCtx, input); err != nil {
+		if e.logger != nil {
+			e.logger.Errorf(ctx, "数据注入失败", "bizCode", bizCode, "error", err)
+		}
+		e.recordError(ctx, bizCode, ErrorClassConversion, err)
+		return nil, nil, fmt.Errorf("%w: %w", ErrInjectFailed, err)
+	}
+
+	e.injectBuiltinFunctions(dataCtx)
+	if e.hoistCommonCalls() {
+		injectSharedFacts(dataCtx)
+	}
+	return input, coercions, nil
+}
+
+// sessionContext 会话中单次执行的数据上下文 - 对象与会话共享，撤回、完成标记和变量变更计数每次执行独立
+type sessionContext struct {
+	ast.IDataContext
+	retracted []string
+	changes   uint64
+	complete  bool
+}
+
+// newSessionContext 创建单次执行的数据上下文
+func newSessionContext(base ast.IDataContext) *sessionContext {
+	return &sessionContext{IDataContext: base}
+}
+
+// ResetVariableChangeCount 实现ast.IDataContext
+func (c *sessionContext) ResetVariableChangeCount() { c.changes = 0 }
+
+// IncrementVariableChangeCount 实现ast.IDataContext
+func (c *sessionContext) IncrementVariableChangeCount() { c.changes++ }
+
+// HasVariableChange 实现ast.IDataContext
+func (c *sessionContext) HasVariableChange() bool { return c.changes > 0 }
+
+// Retract 实现ast.IDataContext
+func (c *sessionContext) Retract(key string) { c.retracted = append(c.retracted, key) }
+
+// IsRetracted 实现ast.IDataContext
+func (c *sessionContext) IsRetracted(key string) bool {
+	for _, retracted := range c.retracted {
+		if retracted == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Retracted 实现ast.IDataContext
+func (c *sessionContext) Retracted() []string { return c.retracted }
+
+// Reset 实现ast.IDataContext
+func (c *sessionContext) Reset() { c.retracted = nil }
+
+// Complete 实现ast.IDataContext
+func (c *sessionContext) Complete() { c.complete = true }
+
+// IsComplete 实现ast.IDataContext
+func (c *sessionContext) IsComplete() bool { return c.complete }
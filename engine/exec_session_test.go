@@ -0,0 +1,82 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+// TestExecSession 测试执行会话
+func TestExecSession(t *testing.T) {
+	Convey("执行会话", t, func() {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		ctx := context.Background()
+
+		mapper := rule.NewMockRuleMapper(ctrl)
+		mapper.EXPECT().FindByBizCode(gomock.Any(), "kyc").Return([]*rule.Rule{
+			{BizCode: "kyc", Name: "Check", Enabled: true, Version: 1,
+				GRL: `rule Check "实名" { when Params["age"] >= 18 then Result["adult"] = true; Params["checked"] = true; Complete(); }`},
+		}, nil).AnyTimes()
+		mapper.EXPECT().FindByBizCode(gomock.Any(), "risk").Return([]*rule.Rule{
+			{BizCode: "risk", Name: "Check", Enabled: true, Version: 1,
+				GRL: `rule Check "风险" { when Params["age"] < Config["limit"] then Result["young"] = true; Retract("Check"); }`},
+			{BizCode: "risk", Name: "Seen", Enabled: true, Version: 1,
+				GRL: `rule Seen "已实名" { when Params["checked"] == true then Result["seen"] = true; Retract("Seen"); }`},
+		}, nil).AnyTimes()
+		mapper.EXPECT().FindByBizCode(gomock.Any(), "missing").Return(nil, nil).AnyTimes()
+
+		engine := NewEngineImpl[map[string]any](
+			config.DefaultConfig(), mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+			ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+		)
+		defer engine.Close()
+
+		Convey("复用数据上下文，Result和执行状态每次独立", func() {
+			session := engine.NewSession(ctx, map[string]any{"age": 20})
+
+			kyc, err := session.Exec("kyc")
+			So(err, ShouldBeNil)
+			So(kyc, ShouldResemble, map[string]any{"adult": true})
+			base := session.state.base
+
+			risk, err := session.Exec("risk", WithParams(map[string]any{"limit": 25}))
+			So(err, ShouldBeNil)
+			So(risk, ShouldResemble, map[string]any{"young": true, "seen": true})
+			So(kyc, ShouldNotContainKey, "young")
+
+			risk, err = session.Exec("risk", WithParams(map[string]any{"limit": 18}))
+			So(err, ShouldBeNil)
+			So(risk, ShouldResemble, map[string]any{"seen": true})
+			So(session.state.base, ShouldEqual, base)
+		})
+
+		Convey("执行错误不影响会话后续执行", func() {
+			session := engine.NewSession(ctx, map[string]any{"age": 20})
+			_, err := session.Exec("missing")
+			So(err, ShouldNotBeNil)
+
+			_, err = session.Exec("risk")
+			So(err, ShouldBeNil)
+
+			kyc, err := session.Exec("kyc")
+			So(err, ShouldBeNil)
+			So(kyc["adult"], ShouldEqual, true)
+		})
+
+		Convey("空输入返回错误", func() {
+			_, err := engine.NewSession(ctx, nil).Exec("kyc")
+			So(errors.Is(err, ErrNilInput), ShouldBeTrue)
+		})
+	})
+}
@@ -0,0 +1,168 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+)
+
+// ============================================================================
+// 固定版本执行 - 长时间运行的批处理在发布新版本后仍使用一致的规则集版本
+// ============================================================================
+//
+// 规则集以内容哈希（ExecReport.RuleSetHash）标识：规则最大版本号不变时删除规则、直接修改GRL
+// 或调整放量比例都会得到不同的哈希，按哈希固定的执行不会误用内容已变化的规则集。
+// 按版本号（ExecVersion）固定时，同一版本号对应多个保留的规则集则使用最近编译的一个。
+//
+// 引擎按业务码在内存中保留最近编译过的若干规则集（Config.VersionRetention，<=0 时不保留，
+// 只能执行最新规则集）。规则表只保存最新规则，引擎重启后或已被淘汰的规则集无法固定，
+// 需要跨重启固定的批处理应在 ErrVersionNotFound 时重新开始。
+
+// ErrVersionNotFound 请求的规则集版本既不是最新版本也未被保留
+var ErrVersionNotFound = errors.New("规则集版本不可用")
+
+// retainedVersion 保留的规则集
+type retainedVersion struct {
+	version    int
+	hash       string
+	rules      []*rule.Rule
+	kb         *ast.KnowledgeBase
+	retainedAt time.Time
+	lastUsed   time.Time
+}
+
+// versionStore 单个业务码保留的规则集，按内容哈希索引
+type versionStore struct {
+	mu       sync.Mutex
+	versions map[string]*retainedVersion
+}
+
+// WithVersion 固定执行的规则集版本 - 绕过最新版本，version<=0表示使用最新版本
+func WithVersion(version int) ExecOption {
+	return func(o *ExecOptions) {
+		o.Version = version
+	}
+}
+
+// WithRuleSetHash 按内容哈希固定执行的规则集 - 哈希可从 ExecReport.RuleSetHash 获取，为空表示不固定
+//
+// 与 WithVersion 同时设置时以哈希为准。
+func WithRuleSetHash(hash string) ExecOption {
+	return func(o *ExecOptions) {
+		o.RuleSetHash = hash
+	}
+}
+
+// ExecVersion 按指定规则集版本执行
+//
+// 参数:
+//
+//	ctx     - 上下文
+//	bizCode - 业务码
+//	version - 规则集版本（规则最大版本号，可从 ExecReport.RuleSetVersion 获取）
+//	input   - 输入数据
+//	opts    - 执行选项
+//
+// 返回值:
+//
+//	T     - 执行结果
+//	error - 版本不可用时返回 ErrVersionNotFound，其余同Exec
+func (e *engineImpl[T]) ExecVersion(ctx context.Context, bizCode string, version int, input any, opts ...ExecOption) (T, error) {
+	return e.Exec(ctx, bizCode, input, append(append([]ExecOption(nil), opts...), WithVersion(version))...)
+}
+
+// versionRetention 每个业务码保留的规则集版本数
+func (e *engineImpl[T]) versionRetention() int {
+	if e.config == nil {
+		return 0
+	}
+	return e.config.VersionRetention
+}
+
+// retainVersion 保留编译完成的规则集，超出保留数量时淘汰最久未使用的规则集
+func (e *engineImpl[T]) retainVersion(bizCode string, rules []*rule.Rule, kb *ast.KnowledgeBase) {
+	limit := e.versionRetention()
+	if limit <= 0 {
+		return
+	}
+
+	value, _ := e.versions.LoadOrStore(bizCode, &versionStore{versions: make(map[string]*retainedVersion)})
+	store := value.(*versionStore)
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	now := time.Now()
+	hash := ruleSetHash(rules)
+	store.versions[hash] = &retainedVersion{
+		version:    ruleSetVersion(rules),
+		hash:       hash,
+		rules:      rules,
+		kb:         kb,
+		retainedAt: now,
+		lastUsed:   now,
+	}
+	for len(store.versions) > limit {
+		oldest, oldestUsed := "", time.Time{}
+		for h, retained := range store.versions {
+			if h == hash {
+				continue
+			}
+			if oldestUsed.IsZero() || retained.lastUsed.Before(oldestUsed) {
+				oldest, oldestUsed = h, retained.lastUsed
+			}
+		}
+		delete(store.versions, oldest)
+	}
+}
+
+// pinnedVersion 本次执行是否固定到最新规则集以外的规则集
+func pinnedVersion(options *ExecOptions, version int, hash string) bool {
+	if options.RuleSetHash != "" {
+		return options.RuleSetHash != hash
+	}
+	return options.Version > 0 && options.Version != version
+}
+
+// loadVersion 获取固定执行的保留规则集并更新使用时间 - 设置了哈希时按哈希查找，否则按版本号查找最近编译的规则集
+func (e *engineImpl[T]) loadVersion(bizCode string, options *ExecOptions) (*retainedVersion, bool) {
+	value, ok := e.versions.Load(bizCode)
+	if !ok {
+		return nil, false
+	}
+	store := value.(*versionStore)
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	retained, ok := store.versions[options.RuleSetHash]
+	if options.RuleSetHash == "" {
+		for _, candidate := range store.versions {
+			if candidate.version == options.Version && (retained == nil || candidate.retainedAt.After(retained.retainedAt)) {
+				retained = candidate
+			}
+		}
+		ok = retained != nil
+	}
+	if ok {
+		retained.lastUsed = time.Now()
+	}
+	return retained, ok
+}
+
+// versionNotFound 固定的规则集不可用时的错误，未启用版本保留时提示配置
+func (e *engineImpl[T]) versionNotFound(options *ExecOptions, version int) error {
+	requested := fmt.Sprint(options.Version)
+	if options.RuleSetHash != "" {
+		requested = options.RuleSetHash
+	}
+	if e.versionRetention() <= 0 {
+		return fmt.Errorf("规则集版本 %s (最新版本 %d，未启用版本保留): %w", requested, version, ErrVersionNotFound)
+	}
+	return fmt.Errorf("规则集版本 %s (最新版本 %d): %w", requested, version, ErrVersionNotFound)
+}
@@ -0,0 +1,170 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+// TestExecVersion 测试固定版本执行
+func TestExecVersion(t *testing.T) {
+	Convey("固定版本执行", t, func() {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		version, fee := 1, 0
+		mapper := rule.NewMockRuleMapper(ctrl)
+		mapper.EXPECT().FindByBizCode(gomock.Any(), "settle").DoAndReturn(func(ctx context.Context, bizCode string) ([]*rule.Rule, error) {
+			amount := fee
+			if amount == 0 {
+				amount = version * 10
+			}
+			return []*rule.Rule{{
+				Name:    "fee",
+				Version: version,
+				Enabled: true,
+				GRL:     fmt.Sprintf(`rule Fee "手续费" { when true then Result["fee"] = %d; Retract("Fee"); }`, amount),
+			}}, nil
+		}).AnyTimes()
+
+		cfg := config.DefaultConfig()
+		engine := NewEngineImpl[map[string]any](
+			cfg, mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+			ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+		)
+		defer engine.Close()
+		ctx := context.Background()
+		input := map[string]any{"amount": 100}
+
+		publish := func(v int) {
+			version = v
+			So(engine.refreshCache("settle"), ShouldBeNil)
+			_, err := engine.Exec(ctx, "settle", input)
+			So(err, ShouldBeNil)
+		}
+		execVersion := func(v int) (any, error) {
+			result, err := engine.ExecVersion(ctx, "settle", v, input)
+			return result["fee"], err
+		}
+
+		Convey("执行报告返回所用版本", func() {
+			var report ExecReport
+			_, err := engine.Exec(ctx, "settle", input, WithExecReport(&report))
+			So(err, ShouldBeNil)
+			So(report.RuleSetVersion, ShouldEqual, 1)
+			So(report.RuleSetHash, ShouldNotBeEmpty)
+		})
+
+		Convey("版本号不变而内容变化时按内容哈希固定", func() {
+			publish(1)
+			var report ExecReport
+			_, err := engine.Exec(ctx, "settle", input, WithExecReport(&report))
+			So(err, ShouldBeNil)
+
+			// 直接修改GRL而未提升版本号
+			fee = 15
+			publish(1)
+
+			var latest ExecReport
+			result, err := engine.Exec(ctx, "settle", input, WithExecReport(&latest))
+			So(err, ShouldBeNil)
+			So(result["fee"], ShouldEqual, 15)
+			So(latest.RuleSetVersion, ShouldEqual, report.RuleSetVersion)
+			So(latest.RuleSetHash, ShouldNotEqual, report.RuleSetHash)
+
+			result, err = engine.Exec(ctx, "settle", input, WithRuleSetHash(report.RuleSetHash))
+			So(err, ShouldBeNil)
+			So(result["fee"], ShouldEqual, 10)
+
+			result, err = engine.Exec(ctx, "settle", input, WithRuleSetHash(latest.RuleSetHash), WithVersion(7))
+			So(err, ShouldBeNil)
+			So(result["fee"], ShouldEqual, 15)
+
+			_, err = engine.Exec(ctx, "settle", input, WithRuleSetHash("unknown"))
+			So(errors.Is(err, ErrVersionNotFound), ShouldBeTrue)
+		})
+
+		Convey("发布新版本后仍可按旧版本执行", func() {
+			publish(1)
+			publish(2)
+
+			result, err := engine.Exec(ctx, "settle", input)
+			So(err, ShouldBeNil)
+			So(result["fee"], ShouldEqual, 20)
+
+			fee, err := execVersion(1)
+			So(err, ShouldBeNil)
+			So(fee, ShouldEqual, 10)
+
+			fee, err = execVersion(2)
+			So(err, ShouldBeNil)
+			So(fee, ShouldEqual, 20)
+
+			var report ExecReport
+			_, err = engine.ExecVersion(ctx, "settle", 1, input, WithExecReport(&report))
+			So(err, ShouldBeNil)
+			So(report.RuleSetVersion, ShouldEqual, 1)
+		})
+
+		Convey("不修改调用方选项切片的底层数组", func() {
+			publish(1)
+			var report ExecReport
+			opts := make([]ExecOption, 1, 2)
+			opts[0] = WithExecReport(&report)
+
+			_, err := engine.ExecVersion(ctx, "settle", 1, input, opts...)
+			So(err, ShouldBeNil)
+			So(report.RuleSetVersion, ShouldEqual, 1)
+			So(opts[:2][1], ShouldBeNil)
+		})
+
+		Convey("未保留的版本返回ErrVersionNotFound", func() {
+			publish(1)
+			_, err := execVersion(7)
+			So(errors.Is(err, ErrVersionNotFound), ShouldBeTrue)
+		})
+
+		Convey("超出保留数量时淘汰最久未使用的版本", func() {
+			cfg.VersionRetention = 2
+			publish(1)
+			publish(2)
+
+			// 批处理持续使用版本1，版本2成为最久未使用
+			_, err := execVersion(1)
+			So(err, ShouldBeNil)
+			publish(3)
+
+			fee, err := execVersion(1)
+			So(err, ShouldBeNil)
+			So(fee, ShouldEqual, 10)
+
+			_, err = execVersion(2)
+			So(errors.Is(err, ErrVersionNotFound), ShouldBeTrue)
+		})
+
+		Convey("不保留历史版本时只能执行最新版本", func() {
+			cfg.VersionRetention = 0
+			publish(1)
+			publish(2)
+
+			fee, err := execVersion(2)
+			So(err, ShouldBeNil)
+			So(fee, ShouldEqual, 20)
+
+			_, err = execVersion(1)
+			So(errors.Is(err, ErrVersionNotFound), ShouldBeTrue)
+			So(err.Error(), ShouldContainSubstring, "未启用版本保留")
+		})
+	})
+}
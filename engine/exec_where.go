@@ -0,0 +1,73 @@
+package engine
+
+import (
+	"context"
+
+	"gitee.com/damengde/runehammer/rule"
+)
+
+// ============================================================================
+// 规则选择执行 - 按规则元数据选择本次执行的规则
+// ============================================================================
+
+// maxCachedSelectors 缓存的选择器数量上限，超出后不再缓存新表达式
+const maxCachedSelectors = 1024
+
+// WithSelector 设置规则选择器 - 只执行被选中的规则，语法见 rule.ParseSelector
+func WithSelector(selector string) ExecOption {
+	return func(o *ExecOptions) {
+		o.Selector = selector
+	}
+}
+
+// ExecWhere 只执行满足选择器的规则
+//
+// 选择器是基于规则元数据的类SQL表达式，编译后按表达式缓存，如:
+//
+//	tags CONTAINS 'fast' AND priority >= 50
+//
+// 参数:
+//
+//	ctx      - 上下文
+//	bizCode  - 业务码
+//	selector - 选择器表达式
+//	input    - 输入数据
+//	opts     - 执行选项
+//
+// 返回值:
+//
+//	T     - 执行结果
+//	error - 选择器语法错误时直接返回，其余同Exec
+func (e *engineImpl[T]) ExecWhere(ctx context.Context, bizCode string, selector string, input any, opts ...ExecOption) (T, error) {
+	return e.Exec(ctx, bizCode, input, append(append([]ExecOption(nil), opts...), WithSelector(selector))...)
+}
+
+// ruleSelector 获取编译后的选择器，相同表达式只编译一次
+func (e *engineImpl[T]) ruleSelector(source string) (*rule.RuleSelector, error) {
+	if cached, ok := e.selectors.Load(source); ok {
+		return cached.(*rule.RuleSelector), nil
+	}
+
+	selector, err := rule.ParseSelector(source)
+	if err != nil {
+		return nil, err
+	}
+	if e.selectorCount.Load() < maxCachedSelectors {
+		if _, loaded := e.selectors.LoadOrStore(source, selector); !loaded {
+			e.selectorCount.Add(1)
+		}
+	}
+	return selector, nil
+}
+
+// selectorExclusions 未被选中的规则对应的GRL规则名
+func selectorExclusions(selector *rule.RuleSelector, rules []*rule.Rule) []string {
+	var names []string
+	for _, r := range rules {
+		if !r.Enabled || selector.Match(r) {
+			continue
+		}
+		names = append(names, ruleEntryNames(r)...)
+	}
+	return names
+}
@@ -0,0 +1,130 @@
+package engine
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+// TestExecWhere 测试按选择器执行规则
+func TestExecWhere(t *testing.T) {
+	Convey("按选择器执行规则", t, func() {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mapper := rule.NewMockRuleMapper(ctrl)
+		mapper.EXPECT().FindByBizCode(gomock.Any(), "risk").Return([]*rule.Rule{
+			{Name: "blacklist", Tags: []string{"fast"}, Enabled: true,
+				GRL: `rule Blacklist "黑名单" salience 90 { when true then Result["blacklist"] = true; Retract("Blacklist"); }`},
+			{Name: "velocity", Tags: []string{"fast"}, Enabled: true,
+				GRL: `rule Velocity "频次" salience 10 { when true then Result["velocity"] = true; Retract("Velocity"); }`},
+			{Name: "graph", Tags: []string{"slow"}, Enabled: true,
+				GRL: `rule Graph "关系图" salience 60 { when true then Result["graph"] = true; Retract("Graph"); }`},
+		}, nil).AnyTimes()
+
+		engine := NewEngineImpl[map[string]any](
+			config.DefaultConfig(), mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+			ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+		)
+		defer engine.Close()
+		ctx := context.Background()
+		input := map[string]any{"userId": "u1"}
+
+		Convey("只执行被选中的规则", func() {
+			result, err := engine.ExecWhere(ctx, "risk", "tags CONTAINS 'fast' AND priority >= 50", input)
+			So(err, ShouldBeNil)
+			So(result["blacklist"], ShouldEqual, true)
+			So(result["velocity"], ShouldBeNil)
+			So(result["graph"], ShouldBeNil)
+
+			result, err = engine.ExecWhere(ctx, "risk", "NOT name = 'blacklist'", input)
+			So(err, ShouldBeNil)
+			So(result["blacklist"], ShouldBeNil)
+			So(result["velocity"], ShouldEqual, true)
+			So(result["graph"], ShouldEqual, true)
+		})
+
+		Convey("不修改调用方选项切片的底层数组", func() {
+			var report ExecReport
+			opts := make([]ExecOption, 1, 2)
+			opts[0] = WithExecReport(&report)
+
+			result, err := engine.ExecWhere(ctx, "risk", "name = 'graph'", input, opts...)
+			So(err, ShouldBeNil)
+			So(result["graph"], ShouldEqual, true)
+			So(opts[:2][1], ShouldBeNil)
+		})
+
+		Convey("选择执行不影响后续完整执行", func() {
+			_, err := engine.ExecWhere(ctx, "risk", "tags CONTAINS 'slow'", input)
+			So(err, ShouldBeNil)
+
+			result, err := engine.Exec(ctx, "risk", input)
+			So(err, ShouldBeNil)
+			So(result["blacklist"], ShouldEqual, true)
+			So(result["velocity"], ShouldEqual, true)
+			So(result["graph"], ShouldEqual, true)
+		})
+
+		Convey("并发的选择执行与完整执行互不影响", func() {
+			var (
+				wg         sync.WaitGroup
+				mismatches atomic.Int64
+			)
+			for g := 0; g < 16; g++ {
+				wg.Add(1)
+				go func(g int) {
+					defer wg.Done()
+					for i := 0; i < 50; i++ {
+						var (
+							result map[string]any
+							err    error
+							want   [3]bool
+						)
+						switch (g + i) % 3 {
+						case 0:
+							result, err = engine.Exec(ctx, "risk", input)
+							want = [3]bool{true, true, true}
+						case 1:
+							result, err = engine.ExecWhere(ctx, "risk", "tags CONTAINS 'slow'", input)
+							want = [3]bool{false, false, true}
+						default:
+							result, err = engine.ExecWhere(ctx, "risk", "name = 'blacklist'", input)
+							want = [3]bool{true, false, false}
+						}
+						got := [3]bool{result["blacklist"] == true, result["velocity"] == true, result["graph"] == true}
+						if err != nil || got != want {
+							mismatches.Add(1)
+						}
+					}
+				}(g)
+			}
+			wg.Wait()
+			So(mismatches.Load(), ShouldEqual, 0)
+		})
+
+		Convey("相同表达式只编译一次", func() {
+			first, err := engine.ruleSelector("priority > 1")
+			So(err, ShouldBeNil)
+			second, err := engine.ruleSelector("priority > 1")
+			So(err, ShouldBeNil)
+			So(second, ShouldEqual, first)
+		})
+
+		Convey("选择器语法错误时不执行", func() {
+			_, err := engine.ExecWhere(ctx, "risk", "priority >>", input)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "规则选择器")
+		})
+	})
+}
@@ -0,0 +1,101 @@
+package engine
+
+import (
+	"context"
+	"sort"
+)
+
+// ============================================================================
+// 决策解释 - 将Result字段映射回写入它的规则，满足自动化决策的可解释性要求
+// ============================================================================
+//
+// Explain 以结果变更日志执行一次规则，按字段汇总各规则的写入（含写入前后的值），
+// 回答"这个字段的值是由哪些规则、按什么顺序得出的"。
+
+// Explanation 一次执行的决策解释
+type Explanation struct {
+	BizCode        string             // 业务码
+	RuleSetVersion int                // 执行使用的规则集版本
+	Degraded       bool               // 是否返回了降级结果，降级时没有规则写入记录
+	Fields         []FieldExplanation // 各Result字段的解释，按字段路径排序
+}
+
+// FieldExplanation Result字段的解释
+type FieldExplanation struct {
+	Field   string         // 字段路径，嵌套map以点分隔，如 risk.level
+	Value   any            // 执行结束时的值，被删除时为nil
+	Deleted bool           // 执行结束时字段是否已被删除
+	Changes []ResultChange // 按执行顺序排列的写入记录
+}
+
+// Rules 参与该字段取值的规则，按首次写入顺序去重
+func (f FieldExplanation) Rules() []string {
+	var rules []string
+	seen := make(map[string]bool)
+	for _, change := range f.Changes {
+		if !seen[change.Rule] {
+			seen[change.Rule] = true
+			rules = append(rules, change.Rule)
+		}
+	}
+	return rules
+}
+
+// Field 按字段路径查找解释，字段未被任何规则写入时返回nil
+func (e *Explanation) Field(path string) *FieldExplanation {
+	for i := range e.Fields {
+		if e.Fields[i].Field == path {
+			return &e.Fields[i]
+		}
+	}
+	return nil
+}
+
+// Explain 执行规则并解释每个Result字段由哪些规则写入
+//
+// 参数:
+//
+//	ctx     - 上下文
+//	bizCode - 业务码
+//	input   - 输入数据，与 Exec 相同
+//	opts    - 执行选项，与 Exec 相同；执行报告和变更日志由 Explain 设置
+//
+// 返回值:
+//
+//	*Explanation - 决策解释
+//	error        - 执行失败
+func (e *engineImpl[T]) Explain(ctx context.Context, bizCode string, input any, opts ...ExecOption) (*Explanation, error) {
+	var report ExecReport
+	opts = append(opts, WithExecReport(&report), WithResultJournal())
+	if _, err := e.Exec(ctx, bizCode, input, opts...); err != nil {
+		return nil, err
+	}
+	return explainJournal(bizCode, &report), nil
+}
+
+// explainJournal 按字段汇总变更日志
+func explainJournal(bizCode string, report *ExecReport) *Explanation {
+	explanation := &Explanation{
+		BizCode:        bizCode,
+		RuleSetVersion: report.RuleSetVersion,
+		Degraded:       report.Degraded,
+	}
+
+	index := make(map[string]int)
+	for _, change := range report.Journal {
+		i, ok := index[change.Key]
+		if !ok {
+			i = len(explanation.Fields)
+			index[change.Key] = i
+			explanation.Fields = append(explanation.Fields, FieldExplanation{Field: change.Key})
+		}
+		field := &explanation.Fields[i]
+		field.Changes = append(field.Changes, change)
+		field.Value, field.Deleted = change.New, change.Deleted
+	}
+
+	sort.Slice(explanation.Fields, func(i, j int) bool {
+		return explanation.Fields[i].Field < explanation.Fields[j].Field
+	})
+	return explanation
+}
@@ -0,0 +1,73 @@
+package engine
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+// TestExplain 测试决策解释
+func TestExplain(t *testing.T) {
+	Convey("决策解释", t, func() {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mapper := rule.NewMockRuleMapper(ctrl)
+		mapper.EXPECT().FindByBizCode(gomock.Any(), "loan").Return([]*rule.Rule{
+			{Name: "score", Enabled: true,
+				GRL: `rule Score "评分" salience 20 { when Params["income"] > 0 then Result["score"] = 650; Result["approved"] = false; Retract("Score"); }`},
+			{Name: "approve", Enabled: true,
+				GRL: `rule Approve "审批" salience 10 { when Result["score"] >= 600 then Result["approved"] = true; Retract("Approve"); }`},
+			{Name: "vip", Enabled: true,
+				GRL: `rule Vip "VIP" { when Params["vip"] == true then Result["score"] = 800; Retract("Vip"); }`},
+		}, nil).AnyTimes()
+
+		engine := NewEngineImpl[map[string]any](
+			config.DefaultConfig(), mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+			ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+		)
+		defer engine.Close()
+
+		Convey("按字段汇总写入规则和前后值", func() {
+			explanation, err := engine.Explain(context.Background(), "loan", map[string]any{"income": 5000, "vip": true})
+			So(err, ShouldBeNil)
+			So(explanation.BizCode, ShouldEqual, "loan")
+			So(explanation.Fields, ShouldHaveLength, 2)
+			So(explanation.Fields[0].Field, ShouldEqual, "approved")
+			So(explanation.Fields[1].Field, ShouldEqual, "score")
+
+			approved := explanation.Field("approved")
+			So(approved.Value, ShouldEqual, true)
+			So(approved.Rules(), ShouldResemble, []string{"Score", "Approve"})
+			So(approved.Changes[1].Old, ShouldEqual, false)
+			So(approved.Changes[1].New, ShouldEqual, true)
+
+			score := explanation.Field("score")
+			So(score.Value, ShouldEqual, 800)
+			So(score.Rules(), ShouldResemble, []string{"Score", "Vip"})
+			So(score.Changes[1].Old, ShouldEqual, 650)
+
+			So(explanation.Field("missing"), ShouldBeNil)
+		})
+
+		Convey("规则未写入的字段不出现", func() {
+			explanation, err := engine.Explain(context.Background(), "loan", map[string]any{"income": 0, "vip": false})
+			So(err, ShouldBeNil)
+			So(explanation.Fields, ShouldBeEmpty)
+		})
+
+		Convey("同一规则多次写入时规则只列一次", func() {
+			field := FieldExplanation{Changes: []ResultChange{{Rule: "A"}, {Rule: "B"}, {Rule: "A"}}}
+			So(field.Rules(), ShouldResemble, []string{"A", "B"})
+		})
+	})
+}
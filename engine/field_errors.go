@@ -0,0 +1,114 @@
+package engine
+
+import (
+	"sync"
+
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+)
+
+// ============================================================================
+// 字段错误累积 - 规则通过 Errors.AddError 记录字段级错误，引擎统一汇总到结果
+// ============================================================================
+
+const (
+	// FieldErrorsObject 规则中访问错误收集器的对象名
+	FieldErrorsObject = "Errors"
+	// FieldErrorsKey 结果中字段错误列表的键
+	FieldErrorsKey = "errors"
+)
+
+// FieldError 字段错误
+type FieldError struct {
+	Field   string `json:"field"`             // 字段路径
+	Code    string `json:"code"`              // 错误码
+	Message string `json:"message"`           // 错误消息
+	Owner   string `json:"owner,omitempty"`   // 记录该错误的规则的负责人
+	DocURL  string `json:"doc_url,omitempty"` // 记录该错误的规则的说明文档链接
+}
+
+// FieldErrorCollector 字段错误收集器 - 以 Errors 名称注入规则上下文
+//
+// 规则示例:
+//
+//	rule AgeCheck "年龄校验" {
+//	    when Params["age"] < 18
+//	    then Errors.AddError("age", "AGE_TOO_LOW", "年龄不能小于18");
+//	         Retract("AgeCheck");
+//	}
+type FieldErrorCollector struct {
+	mu     sync.Mutex
+	errors []FieldError
+	forget func()                       // 使工作内存中对收集器的求值缓存失效
+	owner  func() (ruleOwnership, bool) // 记录错误时正在执行动作的规则的负责人信息
+}
+
+// AddError 记录字段错误
+func (c *FieldErrorCollector) AddError(field, code, message string) {
+	fe := FieldError{Field: field, Code: code, Message: message}
+	if c.owner != nil {
+		if owner, ok := c.owner(); ok {
+			fe.Owner, fe.DocURL = owner.owner, owner.docURL
+		}
+	}
+
+	c.mu.Lock()
+	c.errors = append(c.errors, fe)
+	c.mu.Unlock()
+
+	// Grule缓存方法调用结果，记录后需重置，后续规则中的 Errors.HasErrors() 才能看到新错误
+	if c.forget != nil {
+		c.forget()
+	}
+}
+
+// HasErrors 是否已记录错误 - 供后续规则在条件中判断
+func (c *FieldErrorCollector) HasErrors() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.errors) > 0
+}
+
+// HasError 指定字段是否已记录错误
+func (c *FieldErrorCollector) HasError(field string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, fe := range c.errors {
+		if fe.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// Errors 返回已记录错误的副本，按记录顺序排列
+func (c *FieldErrorCollector) Errors() []FieldError {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]FieldError{}, c.errors...)
+}
+
+// fieldErrorsEnabled 是否启用字段错误累积
+func (e *engineImpl[T]) fieldErrorsEnabled() bool {
+	return e.config != nil && e.config.FieldErrors
+}
+
+// injectFieldErrors 注入字段错误收集器
+func injectFieldErrors(dataCtx ast.IDataContext, kb *ast.KnowledgeBase) (*FieldErrorCollector, error) {
+	collector := &FieldErrorCollector{}
+	if kb != nil && kb.WorkingMemory != nil {
+		collector.forget = func() { kb.WorkingMemory.Reset(FieldErrorsObject) }
+	}
+	if err := dataCtx.Add(FieldErrorsObject, collector); err != nil {
+		return nil, err
+	}
+	return collector, nil
+}
+
+// attachFieldErrors 将汇总的字段错误写入Result - 未记录错误时写入空列表，保持结果结构稳定
+func attachFieldErrors(dataCtx ast.IDataContext, collector *FieldErrorCollector) []FieldError {
+	errs := collector.Errors()
+	if result, ok := resultMap(dataCtx); ok {
+		result[FieldErrorsKey] = errs
+	}
+	return errs
+}
@@ -0,0 +1,91 @@
+package engine
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+type fieldErrorsResult struct {
+	Approved bool         `json:"approved"`
+	Errors   []FieldError `json:"errors"`
+}
+
+// TestFieldErrors 测试字段错误累积
+func TestFieldErrors(t *testing.T) {
+	Convey("字段错误累积", t, func() {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mapper := rule.NewMockRuleMapper(ctrl)
+		mapper.EXPECT().FindByBizCode(gomock.Any(), "apply").Return([]*rule.Rule{
+			{Name: "age", GRL: `rule AgeCheck "年龄" salience 100 { when Params["age"] < 18 then Errors.AddError("age", "AGE_TOO_LOW", "年龄不能小于18"); Retract("AgeCheck"); }`, Enabled: true},
+			{Name: "income", GRL: `rule IncomeCheck "收入" salience 90 { when Params["income"] <= 0 then Errors.AddError("income", "INCOME_REQUIRED", "收入必须大于0"); Retract("IncomeCheck"); }`, Enabled: true},
+			{Name: "approve", GRL: `rule Approve "审批" salience 10 { when Errors.HasErrors() == false then Result["approved"] = true; Retract("Approve"); }`, Enabled: true},
+		}, nil).AnyTimes()
+
+		cfg := config.DefaultConfig()
+		cfg.FieldErrors = true
+		newEngine := func() *engineImpl[map[string]any] {
+			return NewEngineImpl[map[string]any](
+				cfg, mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+				ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+			)
+		}
+
+		Convey("错误按记录顺序汇总到结果和执行报告", func() {
+			engine := newEngine()
+			defer engine.Close()
+
+			var report ExecReport
+			result, err := engine.Exec(context.Background(), "apply", map[string]any{"age": 16, "income": 0}, WithExecReport(&report))
+			So(err, ShouldBeNil)
+			So(result[FieldErrorsKey], ShouldResemble, []FieldError{
+				{Field: "age", Code: "AGE_TOO_LOW", Message: "年龄不能小于18"},
+				{Field: "income", Code: "INCOME_REQUIRED", Message: "收入必须大于0"},
+			})
+			So(report.FieldErrors, ShouldHaveLength, 2)
+			So(result["approved"], ShouldBeNil)
+		})
+
+		Convey("无错误时为空列表", func() {
+			engine := newEngine()
+			defer engine.Close()
+
+			result, err := engine.Exec(context.Background(), "apply", map[string]any{"age": 30, "income": 100})
+			So(err, ShouldBeNil)
+			So(result[FieldErrorsKey], ShouldResemble, []FieldError{})
+			So(result["approved"], ShouldEqual, true)
+		})
+
+		Convey("结构体结果通过errors字段接收", func() {
+			engine := NewEngineImpl[fieldErrorsResult](
+				cfg, mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+				ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+			)
+			defer engine.Close()
+
+			result, err := engine.Exec(context.Background(), "apply", map[string]any{"age": 16, "income": 100})
+			So(err, ShouldBeNil)
+			So(result.Approved, ShouldBeFalse)
+			So(result.Errors, ShouldResemble, []FieldError{{Field: "age", Code: "AGE_TOO_LOW", Message: "年龄不能小于18"}})
+		})
+
+		Convey("收集器按字段查询", func() {
+			collector := &FieldErrorCollector{}
+			So(collector.HasErrors(), ShouldBeFalse)
+			collector.AddError("age", "X", "x")
+			So(collector.HasError("age"), ShouldBeTrue)
+			So(collector.HasError("income"), ShouldBeFalse)
+		})
+	})
+}
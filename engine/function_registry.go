@@ -0,0 +1,143 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ============================================================================
+// 自定义函数签名校验 - 注册时检查参数和返回值类型，避免执行时才出现难以理解的Grule错误
+// ============================================================================
+
+// ErrInvalidFunction 自定义函数签名不合法
+var ErrInvalidFunction = errors.New("自定义函数签名不合法")
+
+// functionNamePattern 函数名须为合法标识符
+var functionNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+var (
+	errorType    = reflect.TypeOf((*error)(nil)).Elem()
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// supportedFunctionKinds 支持的参数和返回值类型说明
+const supportedFunctionKinds = "bool、整数、浮点、string、time.Time、time.Duration、切片、数组、map、结构体、指针、interface"
+
+// RegisterFunction 注册自定义函数并校验签名
+//
+// 与 RegisterCustomFunction 相比，注册时即检查函数签名，不合法时返回 ErrInvalidFunction 及具体原因：
+//
+//	参数     - 支持 bool、整数、浮点、string、time.Time、time.Duration、切片、数组、map、结构体、指针、interface，
+//	           第一个参数可为context.Context（由引擎传入执行上下文，规则中调用时省略）
+//	返回值   - 无返回值、一个返回值，或 (值, error)；返回的error使本次执行失败
+//
+// 规则中通过 Func.Call("name", 参数...) 调用，参数按签名适配：整数与浮点互转，
+// string转time.Duration（如"1h30m"）和time.Time（RFC3339），以及底层类型相同的自定义类型。
+func (e *DynamicEngine[T]) RegisterFunction(name string, fn interface{}) error {
+	if err := validateFunction(name, fn); err != nil {
+		return err
+	}
+	e.functionMutex.Lock()
+	defer e.functionMutex.Unlock()
+	e.customFunctions[name] = fn
+	delete(e.functionTimeouts, name)
+	return nil
+}
+
+// RegisterFunctions 批量注册自定义函数并校验签名，任一函数不合法时全部不注册
+func (e *DynamicEngine[T]) RegisterFunctions(functions map[string]interface{}) error {
+	var errs []error
+	for name, fn := range functions {
+		if err := validateFunction(name, fn); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	e.functionMutex.Lock()
+	defer e.functionMutex.Unlock()
+	for name, fn := range functions {
+		e.customFunctions[name] = fn
+		delete(e.functionTimeouts, name)
+	}
+	return nil
+}
+
+// validateFunction 校验函数名和签名
+func validateFunction(name string, fn interface{}) error {
+	if !functionNamePattern.MatchString(name) {
+		return fmt.Errorf("%w: 函数名 %q 不是合法标识符", ErrInvalidFunction, name)
+	}
+	t := reflect.TypeOf(fn)
+	if t == nil || t.Kind() != reflect.Func {
+		return fmt.Errorf("%w: %s 不是函数，而是 %T", ErrInvalidFunction, name, fn)
+	}
+
+	for i := 0; i < t.NumIn(); i++ {
+		in := t.In(i)
+		if i == 0 && in == contextType {
+			continue
+		}
+		if t.IsVariadic() && i == t.NumIn()-1 {
+			in = in.Elem()
+		}
+		if !supportedFunctionType(in) {
+			return fmt.Errorf("%w: %s 第 %d 个参数类型 %s 不受支持，支持%s", ErrInvalidFunction, name, i+1, in, supportedFunctionKinds)
+		}
+	}
+
+	switch t.NumOut() {
+	case 0:
+	case 1:
+		if !supportedFunctionType(t.Out(0)) {
+			return fmt.Errorf("%w: %s 返回值类型 %s 不受支持，支持%s", ErrInvalidFunction, name, t.Out(0), supportedFunctionKinds)
+		}
+	case 2:
+		if t.Out(1) != errorType {
+			return fmt.Errorf("%w: %s 有两个返回值时第二个必须是error，当前为 %s", ErrInvalidFunction, name, t.Out(1))
+		}
+		if !supportedFunctionType(t.Out(0)) {
+			return fmt.Errorf("%w: %s 返回值类型 %s 不受支持，支持%s", ErrInvalidFunction, name, t.Out(0), supportedFunctionKinds)
+		}
+	default:
+		return fmt.Errorf("%w: %s 有 %d 个返回值，最多为 (值, error)", ErrInvalidFunction, name, t.NumOut())
+	}
+	return nil
+}
+
+// supportedFunctionType 是否为规则中可传递的类型
+func supportedFunctionType(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool, reflect.String, reflect.Interface,
+		reflect.Slice, reflect.Array, reflect.Map, reflect.Struct, reflect.Ptr:
+		return true
+	}
+	return isNumberKind(t.Kind())
+}
+
+// adaptCommonType 常用Go类型的参数适配 - string转time.Duration和time.Time，底层类型相同的自定义类型直接转换
+func adaptCommonType(target reflect.Type, value reflect.Value) (reflect.Value, bool, error) {
+	switch {
+	case target == durationType && value.Kind() == reflect.String:
+		d, err := time.ParseDuration(value.String())
+		if err != nil {
+			return reflect.Value{}, true, fmt.Errorf("不能将 %q 解析为time.Duration: %w", value.String(), err)
+		}
+		return reflect.ValueOf(d), true, nil
+	case target == timeType && value.Kind() == reflect.String:
+		tm, err := time.Parse(time.RFC3339, strings.TrimSpace(value.String()))
+		if err != nil {
+			return reflect.Value{}, true, fmt.Errorf("不能将 %q 解析为time.Time: %w", value.String(), err)
+		}
+		return reflect.ValueOf(tm), true, nil
+	case target.Kind() == value.Kind() && (target.Kind() == reflect.String || target.Kind() == reflect.Bool):
+		return value.Convert(target), true, nil
+	}
+	return reflect.Value{}, false, nil
+}
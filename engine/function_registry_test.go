@@ -0,0 +1,97 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gitee.com/damengde/runehammer/rule"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// TestRegisterFunction 测试自定义函数签名校验和参数适配
+func TestRegisterFunction(t *testing.T) {
+	Convey("自定义函数签名校验", t, func() {
+		engine := NewDynamicEngine[map[string]interface{}]()
+		defer engine.Close()
+
+		Convey("注册时拒绝不合法的签名", func() {
+			cases := map[string]interface{}{
+				"notFunc":  "abc",
+				"chanArg":  func(ch chan int) bool { return true },
+				"funcArg":  func(f func()) bool { return true },
+				"twoValue": func() (int, int) { return 1, 2 },
+				"threeOut": func() (int, int, error) { return 1, 2, nil },
+			}
+			for name, fn := range cases {
+				err := engine.RegisterFunction(name, fn)
+				So(errors.Is(err, ErrInvalidFunction), ShouldBeTrue)
+			}
+			So(engine.RegisterFunction("bad-name", func() int { return 1 }), ShouldWrap, ErrInvalidFunction)
+			So(engine.customFunctions, ShouldBeEmpty)
+		})
+
+		Convey("批量注册任一不合法时全部不注册", func() {
+			err := engine.RegisterFunctions(map[string]interface{}{
+				"ok":  func(v int) int { return v },
+				"bad": func(ch chan int) int { return 0 },
+			})
+			So(errors.Is(err, ErrInvalidFunction), ShouldBeTrue)
+			So(engine.customFunctions, ShouldNotContainKey, "ok")
+		})
+
+		Convey("按签名适配参数", func() {
+			type Level string
+			So(engine.RegisterFunctions(map[string]interface{}{
+				"Minutes": func(d time.Duration) int { return int(d.Minutes()) },
+				"Year":    func(ctx context.Context, t time.Time) int { return t.Year() },
+				"IsGold":  func(level Level) bool { return level == "gold" },
+				"Sum": func(values ...int) int {
+					total := 0
+					for _, v := range values {
+						total += v
+					}
+					return total
+				},
+			}), ShouldBeNil)
+
+			definition := rule.SimpleRule{
+				When: "Params.Amount > 0",
+				Then: map[string]string{
+					"Result.Minutes": `Func.Call("Minutes", "1h30m")`,
+					"Result.Year":    `Func.Call("Year", "2024-06-01T00:00:00Z")`,
+					"Result.Gold":    `Func.Call("IsGold", Params.Status)`,
+					"Result.Sum":     `Func.Call("Sum", 1, 2, Params.Amount)`,
+				},
+			}
+			result, err := engine.ExecuteRuleDefinition(context.Background(), definition, TestOrder{Amount: 3, Status: "gold"})
+			So(err, ShouldBeNil)
+			So(result["Minutes"], ShouldEqual, 90)
+			So(result["Year"], ShouldEqual, 2024)
+			So(result["Gold"], ShouldEqual, true)
+			So(result["Sum"], ShouldEqual, 6)
+		})
+
+		Convey("函数返回的error使执行失败", func() {
+			errRemote := errors.New("remote unavailable")
+			So(engine.RegisterFunction("Lookup", func(id string) (float64, error) {
+				if id == "" {
+					return 0, errRemote
+				}
+				return 1, nil
+			}), ShouldBeNil)
+
+			definition := rule.SimpleRule{
+				When: `Func.Call("Lookup", Params.Status) > 0`,
+				Then: map[string]string{"Result.Hit": "true"},
+			}
+			result, err := engine.ExecuteRuleDefinition(context.Background(), definition, TestOrder{Status: "vip"})
+			So(err, ShouldBeNil)
+			So(result["Hit"], ShouldEqual, true)
+
+			_, err = engine.ExecuteRuleDefinition(context.Background(), definition, TestOrder{})
+			So(errors.Is(err, errRemote), ShouldBeTrue)
+		})
+	})
+}
@@ -0,0 +1,324 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+)
+
+// ============================================================================
+// 自定义函数超时 - 执行I/O的自定义函数超时后返回规则错误，避免挂起整个执行
+// ============================================================================
+
+// CustomFunctionCaller 自定义函数调用对象在规则中的名称，规则通过 Func.Call("名称", 参数...) 调用已注册的函数
+const CustomFunctionCaller = "Func"
+
+// ErrFunctionTimeout 自定义函数执行超时
+var ErrFunctionTimeout = errors.New("自定义函数执行超时")
+
+// FunctionTimeoutError 自定义函数超时详情 - 可通过errors.Is(err, ErrFunctionTimeout)判断
+type FunctionTimeoutError struct {
+	Name    string        // 函数名
+	Timeout time.Duration // 超时时间
+	Err     error         // 调用上下文的错误，超时为context.DeadlineExceeded，执行被取消为context.Canceled
+}
+
+// Error 实现error接口
+func (e *FunctionTimeoutError) Error() string {
+	return fmt.Sprintf("自定义函数 %s 执行超过 %s: %v", e.Name, e.Timeout, e.Err)
+}
+
+// Unwrap 支持errors.Is(err, ErrFunctionTimeout)及errors.Is(err, context.DeadlineExceeded)
+func (e *FunctionTimeoutError) Unwrap() []error {
+	return []error{ErrFunctionTimeout, e.Err}
+}
+
+// contextType context.Context接口类型
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+
+// RegisterCustomFunctionWithTimeout 注册带超时的自定义函数
+//
+// 每次调用在独立协程中执行，超过timeout后立即返回，本次执行以 *FunctionTimeoutError 失败。
+// 函数第一个参数为context.Context时由引擎传入带超时的上下文（规则中调用时省略该参数），
+// 函数应在上下文取消后尽快返回，否则其协程会继续运行至结束。
+//
+// 参数:
+//
+//	name    - 函数名，规则中通过 Func.Call("name", 参数...) 调用
+//	fn      - 函数，签名要求同 RegisterFunction
+//	timeout - 单次调用超时时间，必须大于0
+func (e *DynamicEngine[T]) RegisterCustomFunctionWithTimeout(name string, fn interface{}, timeout time.Duration) error {
+	if err := validateFunction(name, fn); err != nil {
+		return err
+	}
+	if timeout <= 0 {
+		return fmt.Errorf("自定义函数 %s 的超时时间必须大于0，当前为 %s", name, timeout)
+	}
+
+	e.functionMutex.Lock()
+	defer e.functionMutex.Unlock()
+	e.customFunctions[name] = fn
+	e.functionTimeouts[name] = timeout
+	return nil
+}
+
+// functionGuard 单次执行的自定义函数错误记录 - 超时或函数返回的error
+type functionGuard struct {
+	mu  sync.Mutex
+	err error // 首个错误
+}
+
+// fail 记录错误，只保留首个
+func (g *functionGuard) fail(err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.err == nil {
+		g.err = err
+	}
+}
+
+// Err 本次执行中的自定义函数错误
+func (g *functionGuard) Err() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.err
+}
+
+// withTimeout 包装带超时的函数 - 包装后的签名去掉首个context.Context参数
+//
+// 超时后记录错误并以panic中断规则求值：条件中视为不成立，动作中由Grule转为执行错误，
+// 执行结束后统一以记录的超时错误返回。
+func withTimeout(ctx context.Context, guard *functionGuard, name string, fn interface{}, timeout time.Duration) interface{} {
+	fnValue := reflect.ValueOf(fn)
+	fnType := fnValue.Type()
+	withContext := fnType.NumIn() > 0 && fnType.In(0) == contextType
+
+	in := inTypes(fnType)
+	if withContext {
+		in = in[1:]
+	}
+	out := make([]reflect.Type, 0, fnType.NumOut())
+	for i := 0; i < fnType.NumOut(); i++ {
+		out = append(out, fnType.Out(i))
+	}
+	wrappedType := reflect.FuncOf(in, out, fnType.IsVariadic())
+
+	return reflect.MakeFunc(wrappedType, func(args []reflect.Value) []reflect.Value {
+		callCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		if withContext {
+			args = append([]reflect.Value{reflect.ValueOf(callCtx)}, args...)
+		}
+
+		type outcome struct {
+			results []reflect.Value
+			panic   any
+		}
+		done := make(chan outcome, 1)
+		go func() {
+			defer func() {
+				if r := recover(); r != nil {
+					done <- outcome{panic: r}
+				}
+			}()
+			if fnType.IsVariadic() {
+				done <- outcome{results: fnValue.CallSlice(args)}
+			} else {
+				done <- outcome{results: fnValue.Call(args)}
+			}
+		}()
+
+		select {
+		case result := <-done:
+			if result.panic != nil {
+				panic(result.panic)
+			}
+			return result.results
+		case <-callCtx.Done():
+			err := &FunctionTimeoutError{Name: name, Timeout: timeout, Err: callCtx.Err()}
+			guard.fail(err)
+			panic(err)
+		}
+	}).Interface()
+}
+
+// customFunctionCaller 自定义函数调用对象 - Grule只能调用对象方法，已注册的函数通过该对象按名称调用
+type customFunctionCaller struct {
+	ctx       context.Context
+	guard     *functionGuard
+	functions map[string]reflect.Value // 未包装超时的函数
+	timeouts  map[string]time.Duration // 函数超时时间，调用时以ctx包装
+}
+
+// Call 按名称调用自定义函数
+//
+// 参数按函数签名适配，首个参数为context.Context时传入执行上下文；
+// 函数返回非nil的error时记录到本次执行并中断规则求值。
+func (c *customFunctionCaller) Call(name string, args ...interface{}) interface{} {
+	fn, ok := c.functions[name]
+	if !ok {
+		panic(fmt.Errorf("未注册的自定义函数: %s", name))
+	}
+	if timeout, ok := c.timeouts[name]; ok {
+		fn = reflect.ValueOf(withTimeout(c.ctx, c.guard, name, fn.Interface(), timeout))
+	}
+	fnType := fn.Type()
+	var in []reflect.Value
+	if fnType.NumIn() > 0 && fnType.In(0) == contextType {
+		rest, err := adaptArgs(name, reflect.FuncOf(inTypes(fnType)[1:], nil, fnType.IsVariadic()), args)
+		if err != nil {
+			panic(err)
+		}
+		in = append([]reflect.Value{reflect.ValueOf(c.ctx)}, rest...)
+	} else {
+		var err error
+		if in, err = adaptArgs(name, fnType, args); err != nil {
+			panic(err)
+		}
+	}
+
+	var results []reflect.Value
+	if fn.Type().IsVariadic() {
+		results = fn.CallSlice(in)
+	} else {
+		results = fn.Call(in)
+	}
+	if n := len(results); n > 0 && fnType.Out(n-1) == errorType {
+		if err, _ := results[n-1].Interface().(error); err != nil {
+			err = fmt.Errorf("自定义函数 %s 返回错误: %w", name, err)
+			c.guard.fail(err)
+			panic(err)
+		}
+		results = results[:n-1]
+	}
+	if len(results) == 0 {
+		return nil
+	}
+	return results[0].Interface()
+}
+
+// inTypes 函数的参数类型列表
+func inTypes(fnType reflect.Type) []reflect.Type {
+	in := make([]reflect.Type, fnType.NumIn())
+	for i := range in {
+		in[i] = fnType.In(i)
+	}
+	return in
+}
+
+// adaptArgs 将规则传入的参数转换为函数参数类型，可变参数函数的剩余参数合并为切片
+func adaptArgs(name string, fnType reflect.Type, args []interface{}) ([]reflect.Value, error) {
+	fixed := fnType.NumIn()
+	if fnType.IsVariadic() {
+		fixed--
+	}
+	if len(args) < fixed || (!fnType.IsVariadic() && len(args) > fixed) {
+		return nil, fmt.Errorf("自定义函数 %s 需要 %d 个参数，实际传入 %d 个", name, fixed, len(args))
+	}
+
+	in := make([]reflect.Value, 0, fnType.NumIn())
+	for i := 0; i < fixed; i++ {
+		value, err := adaptArg(fnType.In(i), args[i])
+		if err != nil {
+			return nil, fmt.Errorf("自定义函数 %s 第 %d 个参数: %w", name, i+1, err)
+		}
+		in = append(in, value)
+	}
+	if fnType.IsVariadic() {
+		sliceType := fnType.In(fixed)
+		rest := reflect.MakeSlice(sliceType, 0, len(args)-fixed)
+		for i := fixed; i < len(args); i++ {
+			value, err := adaptArg(sliceType.Elem(), args[i])
+			if err != nil {
+				return nil, fmt.Errorf("自定义函数 %s 第 %d 个参数: %w", name, i+1, err)
+			}
+			rest = reflect.Append(rest, value)
+		}
+		in = append(in, rest)
+	}
+	return in, nil
+}
+
+// adaptArg 转换单个参数 - 可直接赋值时原样传入，数值类型之间按目标类型转换，其余按常用类型适配
+func adaptArg(target reflect.Type, arg interface{}) (reflect.Value, error) {
+	if arg == nil {
+		switch target.Kind() {
+		case reflect.Interface, reflect.Ptr, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+			return reflect.Zero(target), nil
+		}
+		return reflect.Value{}, fmt.Errorf("不能将nil传给 %s", target)
+	}
+
+	value := reflect.ValueOf(arg)
+	if value.Type().AssignableTo(target) {
+		return value, nil
+	}
+	if isNumberKind(value.Kind()) && isNumberKind(target.Kind()) {
+		return value.Convert(target), nil
+	}
+	if adapted, ok, err := adaptCommonType(target, value); ok {
+		return adapted, err
+	}
+	return reflect.Value{}, fmt.Errorf("不能将 %s 转换为 %s", value.Type(), target)
+}
+
+// isNumberKind 是否为整数或浮点类型
+func isNumberKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// customFunctionSnapshot 复制已注册的自定义函数及超时时间，执行期间的注册不影响本次执行
+func (e *DynamicEngine[T]) customFunctionSnapshot() (map[string]interface{}, map[string]time.Duration) {
+	e.functionMutex.RLock()
+	defer e.functionMutex.RUnlock()
+
+	functions := make(map[string]interface{}, len(e.customFunctions))
+	for name, fn := range e.customFunctions {
+		functions[name] = fn
+	}
+	timeouts := make(map[string]time.Duration, len(e.functionTimeouts))
+	for name, timeout := range e.functionTimeouts {
+		timeouts[name] = timeout
+	}
+	return functions, timeouts
+}
+
+// customFunctionTable 构建单次执行的函数表，带超时的函数以执行上下文包装
+func customFunctionTable(ctx context.Context, guard *functionGuard, functions map[string]interface{}, timeouts map[string]time.Duration) map[string]interface{} {
+	table := make(map[string]interface{}, len(functions))
+	for name, fn := range functions {
+		if timeout, ok := timeouts[name]; ok {
+			fn = withTimeout(ctx, guard, name, fn, timeout)
+		}
+		table[name] = fn
+	}
+	return table
+}
+
+// injectFunctionCaller 注入自定义函数调用对象，未注册自定义函数时不注入
+//
+// 调用对象持有未包装的函数，带超时的函数在每次调用时以调用对象的上下文包装，
+// 并行条件的各分支由此使用可取消的分支上下文。
+func injectFunctionCaller(ctx context.Context, dataCtx ast.IDataContext, guard *functionGuard, functions map[string]interface{}, timeouts map[string]time.Duration) {
+	if len(functions) == 0 {
+		return
+	}
+	caller := &customFunctionCaller{ctx: ctx, guard: guard, functions: make(map[string]reflect.Value, len(functions)), timeouts: timeouts}
+	for name, fn := range functions {
+		if value := reflect.ValueOf(fn); value.Kind() == reflect.Func {
+			caller.functions[name] = value
+		}
+	}
+	dataCtx.Add(CustomFunctionCaller, caller)
+}
@@ -0,0 +1,103 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"gitee.com/damengde/runehammer/rule"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// TestRegisterCustomFunctionWithTimeout 测试带超时的自定义函数
+func TestRegisterCustomFunctionWithTimeout(t *testing.T) {
+	Convey("带超时的自定义函数", t, func() {
+		engine := NewDynamicEngine[map[string]interface{}]()
+		defer engine.Close()
+
+		So(engine.RegisterCustomFunctionWithTimeout("double", func(ctx context.Context, v float64) float64 {
+			return v * 2
+		}, time.Second), ShouldBeNil)
+		So(engine.RegisterCustomFunctionWithTimeout("lookup", func(ctx context.Context, v float64) float64 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(5 * time.Second):
+			}
+			return v
+		}, 20*time.Millisecond), ShouldBeNil)
+
+		Convey("未超时时返回函数结果", func() {
+			definition := rule.SimpleRule{
+				When: "Params.Amount > 0",
+				Then: map[string]string{"Result.Doubled": `Func.Call("double", Params.Amount)`},
+			}
+			result, err := engine.ExecuteRuleDefinition(context.Background(), definition, TestOrder{Amount: 21})
+			So(err, ShouldBeNil)
+			So(result["Doubled"], ShouldEqual, 42.0)
+		})
+
+		Convey("动作中超时返回超时错误", func() {
+			definition := rule.SimpleRule{
+				When: "Params.Amount > 0",
+				Then: map[string]string{"Result.Value": `Func.Call("lookup", Params.Amount)`},
+			}
+			start := time.Now()
+			_, err := engine.ExecuteRuleDefinition(context.Background(), definition, TestOrder{Amount: 1})
+			So(time.Since(start), ShouldBeLessThan, time.Second)
+			So(errors.Is(err, ErrFunctionTimeout), ShouldBeTrue)
+			So(errors.Is(err, context.DeadlineExceeded), ShouldBeTrue)
+
+			var timeoutErr *FunctionTimeoutError
+			So(errors.As(err, &timeoutErr), ShouldBeTrue)
+			So(timeoutErr.Name, ShouldEqual, "lookup")
+		})
+
+		Convey("条件中超时同样返回超时错误", func() {
+			definition := rule.SimpleRule{
+				When: `Func.Call("lookup", Params.Amount) > 0`,
+				Then: map[string]string{"Result.Hit": "true"},
+			}
+			_, err := engine.ExecuteRuleDefinition(context.Background(), definition, TestOrder{Amount: 1})
+			So(errors.Is(err, ErrFunctionTimeout), ShouldBeTrue)
+		})
+
+		Convey("非法函数注册失败", func() {
+			So(engine.RegisterCustomFunctionWithTimeout("bad", 42, time.Second), ShouldNotBeNil)
+			So(engine.RegisterCustomFunctionWithTimeout("pair", func() (int, int) { return 1, 2 }, time.Second), ShouldNotBeNil)
+			So(engine.RegisterCustomFunctionWithTimeout("zero", func() int { return 1 }, 0), ShouldNotBeNil)
+		})
+
+		Convey("执行期间可并发注册函数", func() {
+			definition := rule.SimpleRule{
+				When: "Params.Amount > 0",
+				Then: map[string]string{"Result.Doubled": `Func.Call("double", Params.Amount)`},
+			}
+
+			var wg sync.WaitGroup
+			errs := make(chan error, 40)
+			for i := 0; i < 20; i++ {
+				wg.Add(2)
+				go func(i int) {
+					defer wg.Done()
+					errs <- engine.RegisterCustomFunctionWithTimeout(fmt.Sprintf("extra%d", i), func(v float64) float64 {
+						return v
+					}, time.Second)
+				}(i)
+				go func() {
+					defer wg.Done()
+					_, err := engine.ExecuteRuleDefinition(context.Background(), definition, TestOrder{Amount: 21})
+					errs <- err
+				}()
+			}
+			wg.Wait()
+			close(errs)
+
+			for err := range errs {
+				So(err, ShouldBeNil)
+			}
+		})
+	})
+}
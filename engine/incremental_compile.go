@@ -0,0 +1,144 @@
+package engine
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/hyperjumptech/grule-rule-engine/builder"
+	"github.com/hyperjumptech/grule-rule-engine/pkg"
+)
+
+// ============================================================================
+// 增量编译 - 规则集变更后只编译新增或修改的规则，复用其余规则的编译结果
+// ============================================================================
+//
+// 知识库实例由知识库蓝本克隆而来，编译的主要开销是逐条解析GRL构建蓝本。启用增量编译后，
+// 业务码的蓝本在清理编译缓存后仍然保留，并记录每条规则（按GRL哈希）编译出的规则条目；
+// 再次编译时从蓝本移除已删除或修改的规则，只解析新增或修改的规则。
+//
+// 移除的规则条目在蓝本和编译缓存的知识库中标记为已删除（其表达式仍在工作内存中，克隆需要），
+// 只在每次执行克隆出的实例中删除这些条目；已删除的条目多于有效规则时重新完整编译，避免蓝本无限增长。
+
+// ruleFragments 业务码的知识库蓝本及各规则编译出的规则条目
+type ruleFragments struct {
+	blueprint *ast.KnowledgeBase  // 知识库蓝本
+	entries   map[string][]string // 规则GRL哈希 -> 编译出的规则条目名称
+	deleted   int                 // 蓝本中已标记删除的规则条目数
+}
+
+// grlHash 规则GRL的哈希
+func grlHash(grl string) string {
+	sum := sha256.Sum256([]byte(grl))
+	return hex.EncodeToString(sum[:])
+}
+
+// incrementalCompile 是否启用增量编译
+func (e *engineImpl[T]) incrementalCompile() bool {
+	return e.config != nil && e.config.IncrementalCompile
+}
+
+// buildRules 将启用的规则逐条编译到业务码的知识库蓝本，调用方需持有mutex
+//
+// 返回值:
+//
+//	int   - 编译的规则数量
+//	int   - 复用上次编译结果的规则数量
+//	error - 编译错误
+func (e *engineImpl[T]) buildRules(bizCode string, rules []*rule.Rule, hasher hash.Hash) (int, int, error) {
+	enabled := make([]*rule.Rule, 0, len(rules))
+	for _, r := range rules {
+		if !r.Enabled {
+			continue // 跳过禁用的规则
+		}
+		hasher.Write([]byte(r.GRL))
+		enabled = append(enabled, r)
+	}
+
+	if !e.incrementalCompile() {
+		e.fragments.Delete(bizCode)
+		for _, r := range enabled {
+			ruleBuilder := builder.NewRuleBuilder(e.knowledgeLibrary)
+			if err := ruleBuilder.BuildRuleFromResource(bizCode, "1.0.0", pkg.NewBytesResource([]byte(r.GRL))); err != nil {
+				return 0, 0, fmt.Errorf("编译规则 %s 失败: %w", r.Name, err)
+			}
+		}
+		return len(enabled), 0, nil
+	}
+
+	hashes := make([]string, len(enabled))
+	wanted := make(map[string]bool, len(enabled))
+	duplicated := false
+	for i, r := range enabled {
+		hashes[i] = grlHash(r.GRL)
+		duplicated = duplicated || wanted[hashes[i]]
+		wanted[hashes[i]] = true
+	}
+
+	// 编译失败时蓝本处于中间状态，先取出，成功后再保存
+	key := fmt.Sprintf("%s:%s", bizCode, "1.0.0")
+	previous, _ := e.fragments.LoadAndDelete(bizCode)
+	fragments, _ := previous.(*ruleFragments)
+	if fragments == nil || duplicated || fragments.deleted > len(fragments.entries) {
+		// 重复的GRL按完整编译报告错误
+		delete(e.knowledgeLibrary.Library, key)
+		fragments = &ruleFragments{entries: make(map[string][]string)}
+	} else {
+		e.knowledgeLibrary.Library[key] = fragments.blueprint
+		for h, names := range fragments.entries {
+			if wanted[h] {
+				continue
+			}
+			for _, name := range names {
+				e.knowledgeLibrary.RemoveRuleEntry(name, bizCode, "1.0.0")
+				fragments.deleted++
+			}
+			delete(fragments.entries, h)
+		}
+	}
+
+	blueprint := e.knowledgeLibrary.GetKnowledgeBase(bizCode, "1.0.0")
+	reused := 0
+	for i, r := range enabled {
+		if _, ok := fragments.entries[hashes[i]]; ok {
+			reused++
+			continue
+		}
+
+		before := make(map[string]bool, len(blueprint.RuleEntries))
+		for name := range blueprint.RuleEntries {
+			before[name] = true
+		}
+		ruleBuilder := builder.NewRuleBuilder(e.knowledgeLibrary)
+		if err := ruleBuilder.BuildRuleFromResource(bizCode, "1.0.0", pkg.NewBytesResource([]byte(r.GRL))); err != nil {
+			delete(e.knowledgeLibrary.Library, key)
+			return 0, 0, fmt.Errorf("编译规则 %s 失败: %w", r.Name, err)
+		}
+
+		var names []string
+		for name := range blueprint.RuleEntries {
+			if !before[name] {
+				names = append(names, name)
+			}
+		}
+		fragments.entries[hashes[i]] = names
+	}
+
+	fragments.blueprint = blueprint
+	e.fragments.Store(bizCode, fragments)
+	return len(enabled), reused, nil
+}
+
+// pruneDeletedEntries 删除执行实例中标记为已删除的规则条目，执行监听器只看到有效规则
+//
+// 删除后实例的工作内存仍引用这些条目的表达式，实例不能再被克隆。
+func pruneDeletedEntries(kb *ast.KnowledgeBase) {
+	for name, entry := range kb.RuleEntries {
+		if entry.Deleted {
+			delete(kb.RuleEntries, name)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+// TestIncrementalCompile 测试增量编译
+func TestIncrementalCompile(t *testing.T) {
+	Convey("增量编译", t, func() {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		ctx := context.Background()
+
+		field := func(name, key string, value int) *rule.Rule {
+			return &rule.Rule{BizCode: "loan", Name: name, Enabled: true, Version: 1,
+				GRL: fmt.Sprintf(`rule %s "%s" { when true then Result["%s"] = %d; Retract("%s"); }`, name, name, key, value, name)}
+		}
+		current := []*rule.Rule{field("Limit", "limit", 100), field("Score", "score", 700), field("Tier", "tier", 1)}
+
+		mapper := rule.NewMockRuleMapper(ctrl)
+		mapper.EXPECT().FindByBizCode(gomock.Any(), "loan").DoAndReturn(func(context.Context, string) ([]*rule.Rule, error) {
+			return current, nil
+		}).AnyTimes()
+
+		cfg := config.DefaultConfig()
+		cfg.IncrementalCompile = true
+		engine := NewEngineImpl[map[string]any](
+			cfg, mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+			ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+		)
+		defer engine.Close()
+
+		exec := func() map[string]any {
+			engine.dropKnowledgeBase("loan")
+			result, err := engine.Exec(ctx, "loan", map[string]any{})
+			So(err, ShouldBeNil)
+			return result
+		}
+		compileInfo := func() CompileInfo {
+			info, _ := engine.compileInfos.Load("loan")
+			return info.(CompileInfo)
+		}
+
+		So(exec()["score"], ShouldEqual, 700)
+		So(compileInfo().ReusedRules, ShouldEqual, 0)
+
+		Convey("只编译修改的规则，删除的规则不再执行", func() {
+			current = []*rule.Rule{field("Limit", "limit", 100), field("Score", "score", 720)}
+			result := exec()
+			So(result["score"], ShouldEqual, 720)
+			So(result["limit"], ShouldEqual, 100)
+			So(result, ShouldNotContainKey, "tier")
+
+			info := compileInfo()
+			So(info.RuleCount, ShouldEqual, 2)
+			So(info.ReusedRules, ShouldEqual, 1)
+
+			kb, _ := engine.knowledgeBases.Load("loan")
+			instance, err := executionInstance(kb.(*ast.KnowledgeBase))
+			So(err, ShouldBeNil)
+			So(instance.RuleEntries, ShouldHaveLength, 2)
+		})
+
+		Convey("编译失败后修复的规则集可以继续增量编译", func() {
+			current = []*rule.Rule{field("Limit", "limit", 100), {BizCode: "loan", Name: "Bad", Enabled: true, GRL: `rule Bad {`}}
+			engine.dropKnowledgeBase("loan")
+			_, err := engine.Exec(ctx, "loan", map[string]any{})
+			So(err, ShouldNotBeNil)
+
+			current = []*rule.Rule{field("Limit", "limit", 200), field("Score", "score", 700)}
+			So(exec()["limit"], ShouldEqual, 200)
+			So(exec()["score"], ShouldEqual, 700)
+			So(compileInfo().ReusedRules, ShouldEqual, 2)
+		})
+
+		Convey("已删除的条目多于有效规则时完整编译", func() {
+			for i := 0; i < 4; i++ {
+				current = []*rule.Rule{field("Score", "score", 800+i)}
+				So(exec()["score"], ShouldEqual, 800+i)
+			}
+			fragments, _ := engine.fragments.Load("loan")
+			So(fragments.(*ruleFragments).deleted, ShouldBeLessThanOrEqualTo, 2)
+			So(compileInfo().ReusedRules, ShouldEqual, 0)
+		})
+
+		Convey("未启用时每次完整编译", func() {
+			cfg.IncrementalCompile = false
+			So(exec()["score"], ShouldEqual, 700)
+			So(compileInfo().ReusedRules, ShouldEqual, 0)
+			_, ok := engine.fragments.Load("loan")
+			So(ok, ShouldBeFalse)
+		})
+	})
+}
@@ -0,0 +1,157 @@
+package engine
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ============================================================================
+// 输入类型归一化 - 将JSON输入中的字符串数值/布尔值转换为规则期望的类型
+// ============================================================================
+
+// 声明类型
+const (
+	CoerceTypeInt    = "int"    // 整数，转换为int64
+	CoerceTypeFloat  = "float"  // 浮点数，转换为float64
+	CoerceTypeNumber = "number" // 数值，整数转换为int64，否则转换为float64
+	CoerceTypeBool   = "bool"   // 布尔值
+	CoerceTypeString = "string" // 字符串，保持不变
+)
+
+// CoercionRecord 类型转换记录
+type CoercionRecord struct {
+	Path string `json:"path"` // 字段路径，如 customer.age、items[0].qty
+	From string `json:"from"` // 原始字符串值
+	To   any    `json:"to"`   // 转换后的值
+	Type string `json:"type"` // 目标类型
+}
+
+var (
+	// coercionIndexRegex 匹配路径中的数组下标
+	coercionIndexRegex = regexp.MustCompile(`\[\d+\]`)
+	// inferNumberRegex 推断为数值的字符串，排除前导零（如邮编、编号）和科学计数法
+	inferNumberRegex = regexp.MustCompile(`^-?(0|[1-9]\d*)(\.\d+)?$`)
+)
+
+// coerceInput 归一化输入数据 - 仅处理map输入，返回转换后的副本，不修改原始输入
+//
+// 参数:
+//
+//	input  - 输入数据
+//	schema - 字段路径到声明类型的映射（路径不含数组下标，如 items.qty），
+//	         为空时对所有字符串值按内容推断类型
+//
+// 返回值:
+//
+//	any              - 归一化后的输入
+//	[]CoercionRecord - 执行的转换记录
+func coerceInput(input any, schema map[string]string) (any, []CoercionRecord) {
+	data, ok := input.(map[string]any)
+	if !ok {
+		return input, nil
+	}
+
+	c := &inputCoercer{schema: schema}
+	return c.coerceMap("", data), c.records
+}
+
+// inputCoercer 递归类型转换器
+type inputCoercer struct {
+	schema  map[string]string
+	records []CoercionRecord
+}
+
+// coerceValue 转换单个值
+func (c *inputCoercer) coerceValue(path string, value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		return c.coerceMap(path, v)
+	case []any:
+		items := make([]any, len(v))
+		for i, item := range v {
+			items[i] = c.coerceValue(fmt.Sprintf("%s[%d]", path, i), item)
+		}
+		return items
+	case string:
+		return c.coerceString(path, v)
+	default:
+		return value
+	}
+}
+
+// coerceMap 转换map中的所有字段
+func (c *inputCoercer) coerceMap(path string, data map[string]any) map[string]any {
+	result := make(map[string]any, len(data))
+	for key, value := range data {
+		childPath := key
+		if path != "" {
+			childPath = path + "." + key
+		}
+		result[key] = c.coerceValue(childPath, value)
+	}
+	return result
+}
+
+// coerceString 按声明类型或推断类型转换字符串
+func (c *inputCoercer) coerceString(path, value string) any {
+	var targetType string
+	if len(c.schema) == 0 {
+		targetType = inferType(value)
+	} else {
+		targetType = c.schema[coercionIndexRegex.ReplaceAllString(path, "")]
+	}
+	if targetType == "" || targetType == CoerceTypeString {
+		return value
+	}
+
+	converted, ok := convertString(strings.TrimSpace(value), targetType)
+	if !ok {
+		return value
+	}
+
+	c.records = append(c.records, CoercionRecord{Path: path, From: value, To: converted, Type: targetType})
+	return converted
+}
+
+// inferType 推断字符串内容的类型，无法推断时返回空字符串
+func inferType(value string) string {
+	switch {
+	case inferNumberRegex.MatchString(value):
+		return CoerceTypeNumber
+	case strings.EqualFold(value, "true") || strings.EqualFold(value, "false"):
+		return CoerceTypeBool
+	default:
+		return ""
+	}
+}
+
+// convertString 将字符串转换为目标类型
+func convertString(value, targetType string) (any, bool) {
+	switch targetType {
+	case CoerceTypeInt:
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return n, true
+		}
+		if f, err := strconv.ParseFloat(value, 64); err == nil && f == float64(int64(f)) {
+			return int64(f), true
+		}
+	case CoerceTypeFloat:
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f, true
+		}
+	case CoerceTypeNumber:
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return n, true
+		}
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f, true
+		}
+	case CoerceTypeBool:
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b, true
+		}
+	}
+	return nil, false
+}
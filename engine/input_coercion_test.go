@@ -0,0 +1,124 @@
+package engine
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+// TestCoerceInput 测试输入类型归一化
+func TestCoerceInput(t *testing.T) {
+	Convey("输入类型归一化", t, func() {
+
+		Convey("按内容推断类型", func() {
+			input := map[string]any{
+				"age":   "18",
+				"score": "92.5",
+				"vip":   "true",
+				"zip":   "00123",
+				"name":  "alice",
+				"customer": map[string]any{
+					"level": "3",
+				},
+				"items": []any{map[string]any{"qty": "2"}},
+			}
+
+			coerced, records := coerceInput(input, nil)
+			data := coerced.(map[string]any)
+
+			So(data["age"], ShouldEqual, int64(18))
+			So(data["score"], ShouldEqual, 92.5)
+			So(data["vip"], ShouldEqual, true)
+			So(data["zip"], ShouldEqual, "00123")
+			So(data["name"], ShouldEqual, "alice")
+			So(data["customer"].(map[string]any)["level"], ShouldEqual, int64(3))
+			So(data["items"].([]any)[0].(map[string]any)["qty"], ShouldEqual, int64(2))
+			So(records, ShouldHaveLength, 5)
+
+			// 不修改原始输入
+			So(input["age"], ShouldEqual, "18")
+		})
+
+		Convey("按声明类型转换", func() {
+			input := map[string]any{
+				"age":   "18",
+				"code":  "42",
+				"price": "10",
+				"items": []any{map[string]any{"qty": "2"}},
+			}
+			schema := map[string]string{
+				"age":       CoerceTypeInt,
+				"code":      CoerceTypeString,
+				"price":     CoerceTypeFloat,
+				"items.qty": CoerceTypeInt,
+			}
+
+			coerced, records := coerceInput(input, schema)
+			data := coerced.(map[string]any)
+
+			So(data["age"], ShouldEqual, int64(18))
+			So(data["code"], ShouldEqual, "42")
+			So(data["price"], ShouldEqual, float64(10))
+			So(data["items"].([]any)[0].(map[string]any)["qty"], ShouldEqual, int64(2))
+			So(records, ShouldHaveLength, 3)
+		})
+
+		Convey("无法转换时保持原值", func() {
+			coerced, records := coerceInput(map[string]any{"age": "abc"}, map[string]string{"age": CoerceTypeInt})
+			So(coerced.(map[string]any)["age"], ShouldEqual, "abc")
+			So(records, ShouldBeEmpty)
+		})
+
+		Convey("非map输入保持不变", func() {
+			coerced, records := coerceInput("18", nil)
+			So(coerced, ShouldEqual, "18")
+			So(records, ShouldBeNil)
+		})
+	})
+}
+
+// TestEngineInputCoercion 测试引擎执行时的输入归一化
+func TestEngineInputCoercion(t *testing.T) {
+	Convey("引擎输入归一化", t, func() {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		rules := []*rule.Rule{
+			{
+				ID:      1,
+				BizCode: "coerce_biz",
+				Name:    "成年检查",
+				GRL:     `rule AdultRule "成年检查" { when Params["age"] > 18 then Result["adult"] = true; Retract("AdultRule"); }`,
+				Enabled: true,
+			},
+		}
+
+		cfg := config.DefaultConfig()
+		cfg.InputCoercion = true
+		mapper := rule.NewMockRuleMapper(ctrl)
+		mapper.EXPECT().FindByBizCode(gomock.Any(), "coerce_biz").Return(rules, nil).AnyTimes()
+
+		engine := NewEngineImpl[map[string]any](
+			cfg, mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+			ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+		)
+		defer engine.Close()
+
+		var report ExecReport
+		result, err := engine.Exec(context.Background(), "coerce_biz", map[string]any{"age": "25"}, WithExecReport(&report))
+		So(err, ShouldBeNil)
+		So(result["adult"], ShouldEqual, true)
+		So(report.Coercions, ShouldHaveLength, 1)
+		So(report.Coercions[0].Path, ShouldEqual, "age")
+		So(report.Coercions[0].To, ShouldEqual, int64(25))
+	})
+}
@@ -0,0 +1,131 @@
+package engine
+
+import (
+	"reflect"
+)
+
+// ============================================================================
+// 输入拷贝 - 注入前深拷贝调用方的map/结构体，规则修改Params不影响调用方数据
+// ============================================================================
+
+// copyInput 深拷贝输入
+//
+// 不含引用类型的值类型输入（如只有数值、字符串字段的结构体）本身已是副本，直接返回；
+// 指向此类结构体的指针只复制一层。结构体的未导出字段按值复制，不递归拷贝。
+func copyInput(input any) any {
+	if input == nil {
+		return nil
+	}
+
+	plan, kind := planInput(input)
+	if plan.flat {
+		if !plan.pointer {
+			return input
+		}
+		if kind == reflect.Invalid {
+			return input // nil指针
+		}
+		v := reflect.ValueOf(input)
+		copied := reflect.New(v.Type().Elem())
+		copied.Elem().Set(v.Elem())
+		return copied.Interface()
+	}
+
+	c := &inputCopier{visited: make(map[uintptr]reflect.Value)}
+	return c.copy(reflect.ValueOf(input)).Interface()
+}
+
+// inputCopier 递归深拷贝，指针按地址去重以保留共享引用并避免循环引用
+type inputCopier struct {
+	visited map[uintptr]reflect.Value
+}
+
+// copy 拷贝单个值，返回值类型与原值相同
+func (c *inputCopier) copy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		if copied, ok := c.visited[v.Pointer()]; ok {
+			return copied
+		}
+		copied := reflect.New(v.Type().Elem())
+		c.visited[v.Pointer()] = copied
+		copied.Elem().Set(c.copy(v.Elem()))
+		return copied
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(c.copy(v.Elem()))
+		return copied
+
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			copied.SetMapIndex(iter.Key(), c.copy(iter.Value()))
+		}
+		return copied
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			copied.Index(i).Set(c.copy(v.Index(i)))
+		}
+		return copied
+
+	case reflect.Array:
+		copied := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			copied.Index(i).Set(c.copy(v.Index(i)))
+		}
+		return copied
+
+	case reflect.Struct:
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				copied.Field(i).Set(c.copy(v.Field(i)))
+			}
+		}
+		return copied
+
+	default:
+		return v
+	}
+}
+
+// isFlatType 类型是否不含引用类型 - 值拷贝即可与原值隔离
+func isFlatType(t reflect.Type) bool {
+	if t == timeType {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return true
+	case reflect.Array:
+		return isFlatType(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if !isFlatType(t.Field(i).Type) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
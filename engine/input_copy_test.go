@@ -0,0 +1,104 @@
+package engine
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+// copyTestOrder 含引用类型字段的输入
+type copyTestOrder struct {
+	ID    string
+	Tags  []string
+	Attrs map[string]any
+	Owner *TestCustomer
+	Buyer *TestCustomer
+}
+
+// TestCopyInput 测试输入深拷贝
+func TestCopyInput(t *testing.T) {
+	Convey("输入深拷贝", t, func() {
+		Convey("map输入逐层拷贝", func() {
+			input := map[string]any{
+				"amount": 100,
+				"customer": map[string]any{
+					"tags": []any{"vip"},
+				},
+			}
+			copied := copyInput(input).(map[string]any)
+			copied["amount"] = 0
+			copied["customer"].(map[string]any)["tags"].([]any)[0] = "normal"
+
+			So(input["amount"], ShouldEqual, 100)
+			So(input["customer"].(map[string]any)["tags"].([]any)[0], ShouldEqual, "vip")
+		})
+
+		Convey("结构体指针拷贝引用字段并保留共享指针", func() {
+			owner := &TestCustomer{Name: "张三"}
+			input := &copyTestOrder{
+				ID:    "A1",
+				Tags:  []string{"new"},
+				Attrs: map[string]any{"channel": "app"},
+				Owner: owner,
+				Buyer: owner,
+			}
+			copied := copyInput(input).(*copyTestOrder)
+			So(copied, ShouldNotPointTo, input)
+			So(copied.Owner, ShouldNotPointTo, owner)
+			So(copied.Buyer, ShouldPointTo, copied.Owner)
+
+			copied.Tags[0] = "old"
+			copied.Attrs["channel"] = "web"
+			copied.Owner.Name = "李四"
+			So(input.Tags[0], ShouldEqual, "new")
+			So(input.Attrs["channel"], ShouldEqual, "app")
+			So(owner.Name, ShouldEqual, "张三")
+		})
+
+		Convey("不含引用类型的输入走快速路径", func() {
+			order := TestOrder{Amount: 1}
+			So(copyInput(order), ShouldResemble, order)
+
+			ptr := &TestOrder{Amount: 1}
+			copied := copyInput(ptr).(*TestOrder)
+			So(copied, ShouldNotPointTo, ptr)
+			So(*copied, ShouldResemble, *ptr)
+
+			var nilOrder *TestOrder
+			So(copyInput(nilOrder), ShouldEqual, nilOrder)
+			So(copyInput(nil), ShouldBeNil)
+		})
+
+		Convey("启用后规则修改Params不影响调用方", func() {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			cfg := config.DefaultConfig()
+			cfg.CopyInput = true
+			mapper := rule.NewMockRuleMapper(ctrl)
+			mapper.EXPECT().FindByBizCode(gomock.Any(), "mutate").Return([]*rule.Rule{
+				{BizCode: "mutate", Name: "mutate", Enabled: true, GRL: `rule mutate "修改输入" { when Params["amount"] > 0 then Params["amount"] = 0; Result["done"] = true; Retract("mutate"); }`},
+			}, nil).AnyTimes()
+			engine := NewEngineImpl[map[string]any](
+				cfg, mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+				ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+			)
+			defer engine.Close()
+
+			input := map[string]any{"amount": 100}
+			result, err := engine.Exec(context.Background(), "mutate", input)
+			So(err, ShouldBeNil)
+			So(result["done"], ShouldEqual, true)
+			So(input["amount"], ShouldEqual, 100)
+		})
+	})
+}
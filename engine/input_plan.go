@@ -0,0 +1,61 @@
+package engine
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// ============================================================================
+// 输入注入计划缓存 - 按输入类型缓存反射结果，高QPS下避免每次执行重复解析类型
+// ============================================================================
+
+// inputPlan 输入类型的注入计划
+type inputPlan struct {
+	kind    reflect.Kind // 解引用后的类型种类
+	pointer bool         // 输入是否为指针，nil指针按非结构体处理
+	name    string       // 结构体类型名（小写），匿名结构体为空
+	flat    bool         // 解引用后的类型不含引用类型，拷贝输入时值拷贝即可
+}
+
+// inputPlans 注入计划缓存，键为reflect.Type，进程内所有引擎共享
+var inputPlans sync.Map
+
+// nilInputPlan nil输入的注入计划
+var nilInputPlan = &inputPlan{kind: reflect.Invalid, flat: true}
+
+// planInput 获取输入的注入计划及解引用后的类型种类
+func planInput(input any) (*inputPlan, reflect.Kind) {
+	t := reflect.TypeOf(input)
+	if t == nil {
+		return nilInputPlan, reflect.Invalid
+	}
+
+	var plan *inputPlan
+	if cached, ok := inputPlans.Load(t); ok {
+		plan = cached.(*inputPlan)
+	} else {
+		plan = newInputPlan(t)
+		inputPlans.Store(t, plan)
+	}
+
+	if plan.pointer && reflect.ValueOf(input).IsNil() {
+		return plan, reflect.Invalid
+	}
+	return plan, plan.kind
+}
+
+// newInputPlan 解析输入类型生成注入计划
+func newInputPlan(t reflect.Type) *inputPlan {
+	plan := &inputPlan{}
+	if t.Kind() == reflect.Ptr {
+		plan.pointer = true
+		t = t.Elem()
+	}
+	plan.kind = t.Kind()
+	plan.flat = isFlatType(t)
+	if plan.kind == reflect.Struct {
+		plan.name = strings.ToLower(t.Name())
+	}
+	return plan
+}
@@ -0,0 +1,55 @@
+package engine
+
+import (
+	"reflect"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// TestPlanInput 测试输入注入计划缓存
+func TestPlanInput(t *testing.T) {
+	Convey("输入注入计划", t, func() {
+		Convey("结构体按小写类型名注入并缓存计划", func() {
+			plan, kind := planInput(TestOrder{Amount: 1})
+			So(kind, ShouldEqual, reflect.Struct)
+			So(plan.name, ShouldEqual, "testorder")
+
+			cached, ok := inputPlans.Load(reflect.TypeOf(TestOrder{}))
+			So(ok, ShouldBeTrue)
+			again, _ := planInput(TestOrder{Amount: 2})
+			So(again, ShouldEqual, cached)
+		})
+
+		Convey("结构体指针解引用，nil指针不按结构体注入", func() {
+			plan, kind := planInput(&TestOrder{})
+			So(kind, ShouldEqual, reflect.Struct)
+			So(plan.name, ShouldEqual, "testorder")
+
+			var order *TestOrder
+			_, kind = planInput(order)
+			So(kind, ShouldEqual, reflect.Invalid)
+		})
+
+		Convey("匿名结构体、map和nil", func() {
+			plan, kind := planInput(struct{ Age int }{Age: 18})
+			So(kind, ShouldEqual, reflect.Struct)
+			So(plan.name, ShouldBeEmpty)
+
+			_, kind = planInput(map[string]any{"age": 18})
+			So(kind, ShouldEqual, reflect.Map)
+
+			_, kind = planInput(nil)
+			So(kind, ShouldEqual, reflect.Invalid)
+		})
+	})
+}
+
+// BenchmarkPlanInput 注入计划缓存命中时的开销
+func BenchmarkPlanInput(b *testing.B) {
+	input := &TestOrder{Amount: 100}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		planInput(input)
+	}
+}
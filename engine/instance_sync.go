@@ -0,0 +1,110 @@
+package engine
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"gitee.com/damengde/runehammer/pubsub"
+)
+
+// ============================================================================
+// 实例间同步 - 通过消息通道把只保存在内存中的运维操作广播给其他实例
+// ============================================================================
+//
+// 每类操作使用独立频道（运行时覆盖见 override_sync.go，紧急停用见 kill_switch.go，
+// 幂等结果失效见 result_invalidation.go），消息带发出实例的标识，实例忽略自己发出的消息。
+// 消息不持久化，广播之后启动的实例不会收到之前的消息。
+
+// syncMessage 实例间同步消息
+type syncMessage struct {
+	Source  string          `json:"source"`  // 发出消息的实例标识
+	Payload json.RawMessage `json:"payload"` // 操作内容
+}
+
+// instanceSync 实例间同步状态
+type instanceSync struct {
+	bus         pubsub.PubSub // 消息通道，为nil时不同步
+	source      string        // 本实例标识
+	unsubscribe []func()      // 取消各频道的订阅
+}
+
+// SetPubSub 设置实例间消息通道 - 订阅其他实例的运行时覆盖、紧急停用和幂等结果失效，重复调用时替换之前的通道
+//
+// 返回值:
+//
+//	error - 订阅失败
+func (e *engineImpl[T]) SetPubSub(bus pubsub.PubSub) error {
+	e.stopInstanceSync()
+	if bus == nil {
+		return nil
+	}
+
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return fmt.Errorf("生成实例标识失败: %w", err)
+	}
+	peers := instanceSync{bus: bus, source: hex.EncodeToString(id)}
+
+	handlers := map[string]func(payload []byte){
+		overrideChannel:     e.handleOverrideEvent,
+		killSwitchChannel:   e.handleKillSwitchEvent,
+		invalidationChannel: e.handleInvalidationEvent,
+	}
+	for channel, handler := range handlers {
+		unsubscribe, err := bus.Subscribe(context.Background(), channel, e.receive(peers.source, handler))
+		if err != nil {
+			for _, cancel := range peers.unsubscribe {
+				cancel()
+			}
+			return fmt.Errorf("订阅频道 %s 失败: %w", channel, err)
+		}
+		peers.unsubscribe = append(peers.unsubscribe, unsubscribe)
+	}
+	e.peers = peers
+	return nil
+}
+
+// stopInstanceSync 取消订阅全部频道
+func (e *engineImpl[T]) stopInstanceSync() {
+	for _, unsubscribe := range e.peers.unsubscribe {
+		unsubscribe()
+	}
+	e.peers = instanceSync{}
+}
+
+// publish 向频道广播操作，未设置消息通道时忽略
+func (e *engineImpl[T]) publish(ctx context.Context, channel string, event any) error {
+	if e.peers.bus == nil {
+		return nil
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("序列化消息失败: %w", err)
+	}
+	message, err := json.Marshal(syncMessage{Source: e.peers.source, Payload: payload})
+	if err != nil {
+		return fmt.Errorf("序列化消息失败: %w", err)
+	}
+	return e.peers.bus.Publish(ctx, channel, message)
+}
+
+// receive 包装频道处理函数 - 解析消息并忽略本实例发出的消息
+func (e *engineImpl[T]) receive(source string, handler func(payload []byte)) pubsub.Handler {
+	return func(data []byte) {
+		var message syncMessage
+		if err := json.Unmarshal(data, &message); err != nil {
+			if e.logger != nil {
+				e.logger.Warnf(context.Background(), "解析实例间同步消息失败", "error", err)
+			}
+			return
+		}
+		if message.Source == source {
+			return
+		}
+		handler(message.Payload)
+	}
+}
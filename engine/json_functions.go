@@ -0,0 +1,213 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+)
+
+// ============================================================================
+// JSON函数 - 规则直接读取输入中原始JSON字符串字段，无需调用方预先解析
+// ============================================================================
+//
+// 路径语法与gjson一致：以 . 分隔字段，数字段为数组下标，\. 表示字段名中的点；
+// # 位于末尾时取数组长度，位于中间时对数组每个元素取后续路径，如 items.#.sku。
+// 同一次执行中相同的JSON文本只解析一次；文本不是合法JSON时视为路径不存在。
+
+// jsonDocument 已解析的JSON文档
+type jsonDocument struct {
+	value any
+	ok    bool // 是否为合法JSON
+}
+
+// jsonCache 单次执行内的JSON解析缓存
+type jsonCache map[string]jsonDocument
+
+// parse 解析JSON文本，相同文本复用解析结果
+func (c jsonCache) parse(text string) (any, bool) {
+	if doc, ok := c[text]; ok {
+		return doc.value, doc.ok
+	}
+	value, ok := parseJSON(text)
+	c[text] = jsonDocument{value: value, ok: ok}
+	return value, ok
+}
+
+// parseJSON 解析JSON文本 - 整数转为int64，其余数字转为float64，便于与GRL数值比较
+func parseJSON(text string) (any, bool) {
+	decoder := json.NewDecoder(strings.NewReader(text))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, false
+	}
+	if decoder.More() {
+		return nil, false
+	}
+	return normalizeJSONNumbers(value), true
+}
+
+// normalizeJSONNumbers 递归转换json.Number
+func normalizeJSONNumbers(value any) any {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]any:
+		for key, item := range v {
+			v[key] = normalizeJSONNumbers(item)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = normalizeJSONNumbers(item)
+		}
+	}
+	return value
+}
+
+// jsonText 取JSON参数的文本，支持string和[]byte
+func jsonText(data any) (string, bool) {
+	switch v := data.(type) {
+	case string:
+		return v, true
+	case []byte:
+		return string(bytes.TrimSpace(v)), true
+	case json.RawMessage:
+		return string(v), true
+	}
+	return "", false
+}
+
+// splitJSONPath 拆分gjson风格路径，a.b\.c.0 -> [a b.c 0]
+func splitJSONPath(path string) []string {
+	if path == "" {
+		return nil
+	}
+
+	var segments []string
+	var segment strings.Builder
+	for i := 0; i < len(path); i++ {
+		switch c := path[i]; {
+		case c == '\\' && i+1 < len(path):
+			i++
+			segment.WriteByte(path[i])
+		case c == '.':
+			segments = append(segments, segment.String())
+			segment.Reset()
+		default:
+			segment.WriteByte(c)
+		}
+	}
+	return append(segments, segment.String())
+}
+
+// jsonLookup 按路径查找JSON值
+func jsonLookup(value any, segments []string) (any, bool) {
+	for i, segment := range segments {
+		switch v := value.(type) {
+		case map[string]any:
+			item, ok := v[segment]
+			if !ok {
+				return nil, false
+			}
+			value = item
+		case []any:
+			if segment == "#" {
+				if i == len(segments)-1 {
+					return int64(len(v)), true
+				}
+				collected := make([]any, 0, len(v))
+				for _, item := range v {
+					if found, ok := jsonLookup(item, segments[i+1:]); ok {
+						collected = append(collected, found)
+					}
+				}
+				return collected, true
+			}
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(v) {
+				return nil, false
+			}
+			value = v[index]
+		default:
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// jsonGet 解析JSON文本并按路径取值
+func jsonGet(cache jsonCache, data any, path string) (any, bool) {
+	text, ok := jsonText(data)
+	if !ok {
+		return nil, false
+	}
+	doc, ok := cache.parse(text)
+	if !ok {
+		return nil, false
+	}
+	return jsonLookup(doc, splitJSONPath(path))
+}
+
+// jsonLen JSON数组的元素数或对象的字段数，路径不存在或为其他类型时为0
+func jsonLen(cache jsonCache, data any, path string) int64 {
+	value, _ := jsonGet(cache, data, path)
+	switch v := value.(type) {
+	case []any:
+		return int64(len(v))
+	case map[string]any:
+		return int64(len(v))
+	}
+	return 0
+}
+
+// jsonDocuments 本次执行的JSON解析缓存
+func (f *ruleFunctions) jsonDocuments() jsonCache {
+	if f.json == nil {
+		f.json = make(jsonCache)
+	}
+	return f.json
+}
+
+// JSONGet 按路径读取JSON字符串中的值，路径不存在或文本不是合法JSON时返回nil
+func (f *ruleFunctions) JSONGet(data any, path string) any {
+	value, _ := jsonGet(f.jsonDocuments(), data, path)
+	return value
+}
+
+// JSONExists 判断JSON字符串中路径是否存在，值为null时也视为存在
+func (f *ruleFunctions) JSONExists(data any, path string) bool {
+	_, ok := jsonGet(f.jsonDocuments(), data, path)
+	return ok
+}
+
+// JSONLen JSON字符串中路径处数组的元素数或对象的字段数
+func (f *ruleFunctions) JSONLen(data any, path string) int64 {
+	return jsonLen(f.jsonDocuments(), data, path)
+}
+
+// injectJSONFunctions 注入JSON函数
+func injectJSONFunctions(dataCtx ast.IDataContext) {
+	cache := make(jsonCache)
+
+	dataCtx.Add("JSONGet", func(data interface{}, path string) interface{} {
+		value, _ := jsonGet(cache, data, path)
+		return value
+	})
+
+	dataCtx.Add("JSONExists", func(data interface{}, path string) bool {
+		_, ok := jsonGet(cache, data, path)
+		return ok
+	})
+
+	dataCtx.Add("JSONLen", func(data interface{}, path string) int64 {
+		return jsonLen(cache, data, path)
+	})
+}
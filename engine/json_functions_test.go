@@ -0,0 +1,101 @@
+package engine
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+// TestJSONFunctions 测试JSON函数
+func TestJSONFunctions(t *testing.T) {
+	Convey("JSON函数", t, func() {
+		payload := `{"user":{"name":"alice","tags":["vip","new"],"score":98.5},"items":[{"sku":"A","qty":2},{"sku":"B","qty":1}],"a.b":1,"empty":null}`
+		docs := make(jsonCache)
+
+		Convey("按gjson风格路径取值", func() {
+			value, ok := jsonGet(docs, payload, "user.name")
+			So(ok, ShouldBeTrue)
+			So(value, ShouldEqual, "alice")
+
+			value, _ = jsonGet(docs, payload, "user.tags.1")
+			So(value, ShouldEqual, "new")
+			value, _ = jsonGet(docs, payload, "items.1.qty")
+			So(value, ShouldEqual, int64(1))
+			value, _ = jsonGet(docs, payload, "user.score")
+			So(value, ShouldEqual, 98.5)
+			value, _ = jsonGet(docs, payload, `a\.b`)
+			So(value, ShouldEqual, int64(1))
+
+			value, _ = jsonGet(docs, payload, "items.#")
+			So(value, ShouldEqual, int64(2))
+			value, _ = jsonGet(docs, payload, "items.#.sku")
+			So(value, ShouldResemble, []any{"A", "B"})
+		})
+
+		Convey("路径不存在或文本非法", func() {
+			_, ok := jsonGet(docs, payload, "user.age")
+			So(ok, ShouldBeFalse)
+			_, ok = jsonGet(docs, payload, "items.5")
+			So(ok, ShouldBeFalse)
+			_, ok = jsonGet(docs, payload, "empty")
+			So(ok, ShouldBeTrue)
+			_, ok = jsonGet(docs, `{"a":`, "a")
+			So(ok, ShouldBeFalse)
+			_, ok = jsonGet(docs, `{} {}`, "")
+			So(ok, ShouldBeFalse)
+			_, ok = jsonGet(docs, 42, "a")
+			So(ok, ShouldBeFalse)
+		})
+
+		Convey("长度", func() {
+			So(jsonLen(docs, payload, "items"), ShouldEqual, 2)
+			So(jsonLen(docs, payload, "user"), ShouldEqual, 3)
+			So(jsonLen(docs, payload, "user.name"), ShouldEqual, 0)
+			So(jsonLen(docs, []byte(`[1,2,3]`), ""), ShouldEqual, 3)
+		})
+
+		Convey("相同文本只解析一次", func() {
+			jsonGet(docs, payload, "user.name")
+			jsonGet(docs, payload, "items.0.sku")
+			jsonGet(docs, `[1]`, "0")
+			So(docs, ShouldHaveLength, 2)
+		})
+
+		Convey("规则中读取JSON字段", func() {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mapper := rule.NewMockRuleMapper(ctrl)
+			mapper.EXPECT().FindByBizCode(gomock.Any(), "json").Return([]*rule.Rule{
+				{Name: "json", Enabled: true, GRL: `rule Json "JSON字段" {
+	when JSONExists(Params["payload"], "user.tags") && JSONGet(Params["payload"], "user.tags.0") == "vip" && JSONLen(Params["payload"], "items") > 1
+	then
+		Result["name"] = JSONGet(Params["payload"], "user.name");
+		Result["count"] = JSONGet(Params["payload"], "items.#");
+		Result["missing"] = JSONExists(Params["payload"], "user.age");
+		Retract("Json");
+}`},
+			}, nil).AnyTimes()
+			engine := NewEngineImpl[map[string]any](
+				config.DefaultConfig(), mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+				ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+			)
+			defer engine.Close()
+
+			result, err := engine.Exec(context.Background(), "json", map[string]any{"payload": payload})
+			So(err, ShouldBeNil)
+			So(result["name"], ShouldEqual, "alice")
+			So(result["count"], ShouldEqual, int64(2))
+			So(result["missing"], ShouldEqual, false)
+		})
+	})
+}
@@ -0,0 +1,118 @@
+package engine
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+)
+
+// ============================================================================
+// 知识库内存估算 - 编译时统计AST节点数和估算字节数，用于定位占用内存较多的业务码
+// ============================================================================
+//
+// 估算按反射遍历规则条目和工作内存：结构体按类型大小、字符串按长度、切片按容量、
+// map按条目计入，每个指针只计一次。结果为近似值，不含Go运行时的分配开销，
+// 适合比较业务码之间的相对大小。估算在编译后、首次执行前进行，不包含执行期绑定的输入数据。
+
+// mapEntryOverhead map每个条目的估算额外开销（桶、哈希等）
+const mapEntryOverhead = 16
+
+// KnowledgeBaseMemory 获取已编译知识库的内存占用估算，按估算字节数降序
+//
+// 只包含各业务码的最新知识库，固定版本执行保留的历史版本不计入。
+func (e *engineImpl[T]) KnowledgeBaseMemory() []CompileInfo {
+	var infos []CompileInfo
+	e.knowledgeBases.Range(func(key, value interface{}) bool {
+		if stored, ok := e.compileInfos.Load(key); ok {
+			infos = append(infos, stored.(CompileInfo))
+		}
+		return true
+	})
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].EstimatedBytes != infos[j].EstimatedBytes {
+			return infos[i].EstimatedBytes > infos[j].EstimatedBytes
+		}
+		return infos[i].BizCode < infos[j].BizCode
+	})
+	return infos
+}
+
+// estimateKnowledgeBase 估算知识库的AST节点数和字节数
+func estimateKnowledgeBase(kb *ast.KnowledgeBase) (nodes int, bytes int64) {
+	if kb == nil {
+		return 0, 0
+	}
+	nodes = len(kb.MakeCatalog().Data)
+
+	sizer := &memorySizer{visited: make(map[uintptr]bool)}
+	bytes = int64(reflect.TypeOf(kb).Elem().Size())
+	sizer.walk(reflect.ValueOf(kb.RuleEntries))
+	sizer.walk(reflect.ValueOf(kb.WorkingMemory))
+	return nodes, bytes + sizer.bytes
+}
+
+// memorySizer 反射遍历对象图累计估算字节数
+type memorySizer struct {
+	visited map[uintptr]bool
+	bytes   int64
+}
+
+// reflectValueType reflect.Value 类型，常量节点中保存的值不再深入遍历
+var reflectValueType = reflect.TypeOf(reflect.Value{})
+
+// walk 累计v引用的（不含v自身的）内存
+func (s *memorySizer) walk(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() || s.visited[v.Pointer()] {
+			return
+		}
+		s.visited[v.Pointer()] = true
+		s.bytes += int64(v.Type().Elem().Size())
+		s.walk(v.Elem())
+	case reflect.Interface:
+		if v.IsNil() {
+			return
+		}
+		elem := v.Elem()
+		if elem.Kind() != reflect.Ptr {
+			s.bytes += int64(elem.Type().Size())
+		}
+		s.walk(elem)
+	case reflect.Struct:
+		if v.Type() == reflectValueType {
+			return
+		}
+		for i := 0; i < v.NumField(); i++ {
+			s.walk(v.Field(i))
+		}
+	case reflect.String:
+		s.bytes += int64(v.Len())
+	case reflect.Slice:
+		if v.IsNil() || s.visited[v.Pointer()] {
+			return
+		}
+		s.visited[v.Pointer()] = true
+		s.bytes += int64(v.Cap()) * int64(v.Type().Elem().Size())
+		for i := 0; i < v.Len(); i++ {
+			s.walk(v.Index(i))
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			s.walk(v.Index(i))
+		}
+	case reflect.Map:
+		if v.IsNil() || s.visited[v.Pointer()] {
+			return
+		}
+		s.visited[v.Pointer()] = true
+		entry := int64(v.Type().Key().Size()+v.Type().Elem().Size()) + mapEntryOverhead
+		s.bytes += int64(v.Len()) * entry
+		iter := v.MapRange()
+		for iter.Next() {
+			s.walk(iter.Key())
+			s.walk(iter.Value())
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+// TestKnowledgeBaseMemory 测试知识库内存估算
+func TestKnowledgeBaseMemory(t *testing.T) {
+	Convey("知识库内存估算", t, func() {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var large []*rule.Rule
+		for i := 0; i < 20; i++ {
+			large = append(large, &rule.Rule{Name: fmt.Sprintf("r%d", i), Enabled: true, GRL: fmt.Sprintf(
+				`rule R%d "规则%d" { when Params["amount"] > %d && Params["level"] == "L%d" then Result["r%d"] = Params["amount"] * 2; Retract("R%d"); }`,
+				i, i, i*10, i, i, i)})
+		}
+
+		mapper := rule.NewMockRuleMapper(ctrl)
+		mapper.EXPECT().FindByBizCode(gomock.Any(), "small").Return([]*rule.Rule{
+			{Name: "only", Enabled: true, GRL: `rule Only "唯一" { when true then Result["ok"] = true; Retract("Only"); }`},
+		}, nil).AnyTimes()
+		mapper.EXPECT().FindByBizCode(gomock.Any(), "large").Return(large, nil).AnyTimes()
+
+		engine := NewEngineImpl[map[string]any](
+			config.DefaultConfig(), mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+			ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+		)
+		defer engine.Close()
+		ctx := context.Background()
+
+		for _, bizCode := range []string{"small", "large"} {
+			_, err := engine.Exec(ctx, bizCode, map[string]any{"amount": 50, "level": "L1"})
+			So(err, ShouldBeNil)
+		}
+
+		Convey("按估算字节数降序返回", func() {
+			memory := engine.KnowledgeBaseMemory()
+			So(memory, ShouldHaveLength, 2)
+			So(memory[0].BizCode, ShouldEqual, "large")
+			So(memory[0].RuleCount, ShouldEqual, 20)
+			So(memory[1].RuleCount, ShouldEqual, 1)
+			So(memory[1].NodeCount, ShouldBeGreaterThan, 0)
+			So(memory[0].NodeCount, ShouldBeGreaterThan, memory[1].NodeCount*10)
+			So(memory[1].EstimatedBytes, ShouldBeGreaterThan, 0)
+			So(memory[0].EstimatedBytes, ShouldBeGreaterThan, memory[1].EstimatedBytes*10)
+		})
+
+		Convey("估算在执行后保持不变", func() {
+			before := engine.KnowledgeBaseMemory()
+			_, err := engine.Exec(ctx, "large", map[string]any{"amount": 500, "level": strings.Repeat("x", 1<<16)})
+			So(err, ShouldBeNil)
+			So(engine.KnowledgeBaseMemory(), ShouldResemble, before)
+		})
+
+		Convey("统计信息与诊断快照包含内存估算", func() {
+			stats := engine.getStats()
+			memory := stats["knowledge_base_memory"].([]CompileInfo)
+			So(memory, ShouldHaveLength, 2)
+			So(stats["knowledge_base_bytes"], ShouldEqual, memory[0].EstimatedBytes+memory[1].EstimatedBytes)
+
+			snapshot := engine.DebugSnapshot()
+			So(snapshot.KnowledgeBases[0].BizCode, ShouldEqual, "large")
+			So(snapshot.KnowledgeBases[0].EstimatedBytes, ShouldEqual, memory[0].EstimatedBytes)
+		})
+	})
+}
@@ -0,0 +1,294 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"gitee.com/damengde/runehammer/rule"
+)
+
+// ============================================================================
+// 紧急停用 - 故障期间按业务码立即停止规则执行，返回降级结果或 ErrBizDisabled
+// ============================================================================
+//
+// 停用在参数验证之后、配额检查和获取规则之前生效，不消耗配额也不访问数据库。
+// 配置了降级结果提供者时返回降级结果（降级原因包装 ErrBizDisabled），否则返回错误。
+// 启用业务码层级继承时，停用父级业务码同时停用其子业务码。
+//
+// 规则映射器实现 rule.DisablementMapper 时（默认的数据库映射器已实现）停用状态持久化，
+// 实例启动时在提供执行前加载（LoadDisablements），重启不会使停用失效；未实现时只保存在内存中。
+// 设置了消息通道时广播给其他实例，发起操作的实例写入持久化和审计记录。
+
+// ErrBizDisabled 业务码已被紧急停用，可通过errors.Is判断
+var ErrBizDisabled = errors.New("业务码已被紧急停用")
+
+// killSwitchChannel 紧急停用的广播频道
+const killSwitchChannel = "runehammer:kill_switch"
+
+// Disablement 业务码停用记录
+type Disablement struct {
+	BizCode    string    `json:"biz_code"`    // 业务码
+	Reason     string    `json:"reason"`      // 停用原因
+	DisabledAt time.Time `json:"disabled_at"` // 停用时间
+}
+
+// AuditAction 审计操作类型
+type AuditAction string
+
+const (
+	AuditDisable AuditAction = "disable" // 紧急停用业务码
+	AuditEnable  AuditAction = "enable"  // 恢复业务码
+)
+
+// AuditEvent 运维操作审计记录
+type AuditEvent struct {
+	Action  AuditAction `json:"action"`           // 操作类型
+	BizCode string      `json:"biz_code"`         // 业务码
+	Reason  string      `json:"reason,omitempty"` // 操作原因
+	Time    time.Time   `json:"time"`             // 操作时间
+}
+
+// AuditRecorder 审计记录 - 在发起操作的实例上同步调用
+type AuditRecorder interface {
+	// RecordAudit 写入审计记录，返回错误时操作已生效，错误返回给调用方
+	RecordAudit(ctx context.Context, event AuditEvent) error
+}
+
+// AuditFunc 函数形式的审计记录
+type AuditFunc func(ctx context.Context, event AuditEvent) error
+
+// RecordAudit 实现AuditRecorder
+func (f AuditFunc) RecordAudit(ctx context.Context, event AuditEvent) error {
+	return f(ctx, event)
+}
+
+// SetAuditRecorder 设置审计记录，为nil时只输出日志
+func (e *engineImpl[T]) SetAuditRecorder(recorder AuditRecorder) {
+	e.auditRecorder = recorder
+}
+
+// killSwitch 已停用的业务码
+type killSwitch struct {
+	mu       sync.RWMutex
+	disabled map[string]Disablement
+}
+
+// set 停用业务码，已停用时更新原因和时间
+func (k *killSwitch) set(d Disablement) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if k.disabled == nil {
+		k.disabled = make(map[string]Disablement)
+	}
+	k.disabled[d.BizCode] = d
+}
+
+// remove 恢复业务码，返回之前是否已停用
+func (k *killSwitch) remove(bizCode string) bool {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	_, ok := k.disabled[bizCode]
+	delete(k.disabled, bizCode)
+	return ok
+}
+
+// get 业务码的停用记录
+func (k *killSwitch) get(bizCode string) (Disablement, bool) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	d, ok := k.disabled[bizCode]
+	return d, ok
+}
+
+// list 全部停用记录，按业务码排序
+func (k *killSwitch) list() []Disablement {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	list := make([]Disablement, 0, len(k.disabled))
+	for _, d := range k.disabled {
+		list = append(list, d)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].BizCode < list[j].BizCode })
+	return list
+}
+
+// killSwitchEvent 紧急停用变更消息
+type killSwitchEvent struct {
+	Action      AuditAction `json:"action"`
+	Disablement Disablement `json:"disablement"`
+}
+
+// Disable 紧急停用业务码 - 立即生效，Exec 返回降级结果或 ErrBizDisabled
+//
+// 参数:
+//
+//	ctx     - 上下文
+//	bizCode - 业务码
+//	reason  - 停用原因，写入审计记录
+//
+// 返回值:
+//
+//	error - 参数无效、引擎为只读模式，或停用已在本实例生效但持久化、审计记录、广播失败
+func (e *engineImpl[T]) Disable(ctx context.Context, bizCode, reason string) error {
+	if err := e.checkWritable("紧急停用"); err != nil {
+		return err
+	}
+	if bizCode == "" {
+		return fmt.Errorf("业务码不能为空")
+	}
+	if reason == "" {
+		return fmt.Errorf("停用原因不能为空")
+	}
+
+	d := Disablement{BizCode: bizCode, Reason: reason, DisabledAt: time.Now()}
+	e.killSwitch.set(d)
+	if e.logger != nil {
+		e.logger.Warnf(ctx, "业务码已紧急停用", "bizCode", bizCode, "reason", reason)
+	}
+	return e.finishKillSwitch(ctx, AuditDisable, d)
+}
+
+// Enable 恢复紧急停用的业务码，未停用时同样广播和审计，保证所有实例恢复
+//
+// 返回值:
+//
+//	error - 参数无效、引擎为只读模式，或恢复已在本实例生效但持久化、审计记录、广播失败
+func (e *engineImpl[T]) Enable(ctx context.Context, bizCode string) error {
+	if err := e.checkWritable("恢复业务码"); err != nil {
+		return err
+	}
+	if bizCode == "" {
+		return fmt.Errorf("业务码不能为空")
+	}
+
+	e.killSwitch.remove(bizCode)
+	if e.logger != nil {
+		e.logger.Infof(ctx, "业务码已恢复", "bizCode", bizCode)
+	}
+	return e.finishKillSwitch(ctx, AuditEnable, Disablement{BizCode: bizCode, DisabledAt: time.Now()})
+}
+
+// Disabled 获取已紧急停用的业务码，按业务码排序
+func (e *engineImpl[T]) Disabled() []Disablement {
+	return e.killSwitch.list()
+}
+
+// LoadDisablements 加载持久化的停用记录 - 须在引擎提供执行前调用
+//
+// 规则映射器未实现 rule.DisablementMapper 时不做任何操作。
+//
+// 返回值:
+//
+//	error - 查询停用记录失败，此时不能确定哪些业务码已停用
+func (e *engineImpl[T]) LoadDisablements(ctx context.Context) error {
+	store, ok := e.mapper.(rule.DisablementMapper)
+	if !ok {
+		return nil
+	}
+
+	list, err := store.FindDisablements(ctx)
+	if err != nil {
+		return fmt.Errorf("加载紧急停用状态失败: %w", err)
+	}
+	for _, d := range list {
+		e.killSwitch.set(Disablement{BizCode: d.BizCode, Reason: d.Reason, DisabledAt: d.DisabledAt})
+		if e.logger != nil {
+			e.logger.Warnf(ctx, "业务码处于紧急停用状态", "bizCode", d.BizCode, "reason", d.Reason)
+		}
+	}
+	return nil
+}
+
+// persistKillSwitch 持久化停用状态，规则映射器未实现 rule.DisablementMapper 时忽略
+func (e *engineImpl[T]) persistKillSwitch(ctx context.Context, action AuditAction, d Disablement) error {
+	store, ok := e.mapper.(rule.DisablementMapper)
+	if !ok {
+		return nil
+	}
+	if action == AuditEnable {
+		return store.DeleteDisablement(ctx, d.BizCode)
+	}
+	return store.SaveDisablement(ctx, &rule.BizDisablement{BizCode: d.BizCode, Reason: d.Reason, DisabledAt: d.DisabledAt})
+}
+
+// finishKillSwitch 持久化停用状态、写入审计记录并广播给其他实例
+func (e *engineImpl[T]) finishKillSwitch(ctx context.Context, action AuditAction, d Disablement) error {
+	var errs []error
+	if err := e.persistKillSwitch(ctx, action, d); err != nil {
+		errs = append(errs, fmt.Errorf("持久化停用状态失败: %w", err))
+	}
+	if e.auditRecorder != nil {
+		event := AuditEvent{Action: action, BizCode: d.BizCode, Reason: d.Reason, Time: d.DisabledAt}
+		if err := e.auditRecorder.RecordAudit(ctx, event); err != nil {
+			errs = append(errs, fmt.Errorf("写入审计记录失败: %w", err))
+		}
+	}
+	if err := e.publish(ctx, killSwitchChannel, killSwitchEvent{Action: action, Disablement: d}); err != nil {
+		errs = append(errs, fmt.Errorf("广播到其他实例失败: %w", err))
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+
+	err := errors.Join(errs...)
+	if e.logger != nil {
+		e.logger.Errorf(ctx, "紧急停用操作未完成", "bizCode", d.BizCode, "action", action, "error", err)
+	}
+	if action == AuditEnable {
+		return fmt.Errorf("业务码 %s 已在本实例恢复: %w", d.BizCode, err)
+	}
+	return fmt.Errorf("业务码 %s 已在本实例停用: %w", d.BizCode, err)
+}
+
+// handleKillSwitchEvent 处理其他实例的紧急停用变更
+func (e *engineImpl[T]) handleKillSwitchEvent(payload []byte) {
+	ctx := context.Background()
+
+	var event killSwitchEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		if e.logger != nil {
+			e.logger.Warnf(ctx, "解析紧急停用消息失败", "error", err)
+		}
+		return
+	}
+	bizCode := event.Disablement.BizCode
+	if bizCode == "" {
+		return
+	}
+
+	switch event.Action {
+	case AuditDisable:
+		e.killSwitch.set(event.Disablement)
+		if e.logger != nil {
+			e.logger.Warnf(ctx, "业务码已被其他实例紧急停用", "bizCode", bizCode, "reason", event.Disablement.Reason)
+		}
+	case AuditEnable:
+		e.killSwitch.remove(bizCode)
+		if e.logger != nil {
+			e.logger.Infof(ctx, "业务码已被其他实例恢复", "bizCode", bizCode)
+		}
+	}
+}
+
+// checkDisabled 检查业务码是否已停用，启用层级继承时同时检查父级业务码
+func (e *engineImpl[T]) checkDisabled(bizCode string) error {
+	chain := []string{bizCode}
+	if e.inheritanceEnabled() {
+		chain = bizCodeChain(bizCode)
+	}
+	for _, code := range chain {
+		if d, ok := e.killSwitch.get(code); ok {
+			return fmt.Errorf("业务码 %s（%s）: %w", code, d.Reason, ErrBizDisabled)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/pubsub"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// TestKillSwitch 测试业务码紧急停用
+func TestKillSwitch(t *testing.T) {
+	Convey("紧急停用", t, func() {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		ctx := context.Background()
+
+		mapper := rule.NewMockRuleMapper(ctrl)
+		mapper.EXPECT().FindByBizCode(gomock.Any(), gomock.Any()).Return([]*rule.Rule{
+			{BizCode: "pay", Name: "approve", Enabled: true, Version: 1,
+				GRL: `rule Approve "通过" { when true then Result["approved"] = true; Retract("Approve"); }`},
+		}, nil).AnyTimes()
+
+		cfg := config.DefaultConfig()
+		newEngine := func() *engineImpl[map[string]any] {
+			return NewEngineImpl[map[string]any](
+				cfg, mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+				ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+			)
+		}
+		engine := newEngine()
+		defer engine.Close()
+
+		var audits []AuditEvent
+		engine.SetAuditRecorder(AuditFunc(func(_ context.Context, event AuditEvent) error {
+			audits = append(audits, event)
+			return nil
+		}))
+
+		Convey("停用后执行返回ErrBizDisabled，恢复后正常执行", func() {
+			So(engine.Disable(ctx, "pay", "下游故障"), ShouldBeNil)
+			_, err := engine.Exec(ctx, "pay", map[string]any{})
+			So(errors.Is(err, ErrBizDisabled), ShouldBeTrue)
+			So(err.Error(), ShouldContainSubstring, "下游故障")
+			So(engine.Disabled(), ShouldHaveLength, 1)
+			So(engine.Disabled()[0].Reason, ShouldEqual, "下游故障")
+
+			_, err = engine.Exec(ctx, "other", map[string]any{})
+			So(err, ShouldBeNil)
+
+			So(engine.Enable(ctx, "pay"), ShouldBeNil)
+			result, err := engine.Exec(ctx, "pay", map[string]any{})
+			So(err, ShouldBeNil)
+			So(result["approved"], ShouldEqual, true)
+			So(engine.Disabled(), ShouldBeEmpty)
+
+			So(audits, ShouldHaveLength, 2)
+			So(audits[0].Action, ShouldEqual, AuditDisable)
+			So(audits[0].Reason, ShouldEqual, "下游故障")
+			So(audits[1].Action, ShouldEqual, AuditEnable)
+		})
+
+		Convey("配置了降级结果时返回降级结果", func() {
+			var cause error
+			engine.SetFallbackProvider(FallbackFunc(func(_ context.Context, _ string, err error) (any, bool) {
+				cause = err
+				return map[string]any{"approved": false}, true
+			}))
+			So(engine.Disable(ctx, "pay", "下游故障"), ShouldBeNil)
+
+			var report ExecReport
+			result, err := engine.Exec(ctx, "pay", map[string]any{}, WithExecReport(&report))
+			So(err, ShouldBeNil)
+			So(result["approved"], ShouldEqual, false)
+			So(errors.Is(cause, ErrBizDisabled), ShouldBeTrue)
+			So(report.Degraded, ShouldBeTrue)
+		})
+
+		Convey("启用层级继承时停用父级业务码同时停用子业务码", func() {
+			cfg.BizCodeInheritance = true
+			So(engine.Disable(ctx, "pay", "下游故障"), ShouldBeNil)
+			_, err := engine.Exec(ctx, "pay.card", map[string]any{})
+			So(errors.Is(err, ErrBizDisabled), ShouldBeTrue)
+		})
+
+		Convey("停用和恢复广播到其他实例，审计只在发起实例写入", func() {
+			bus := pubsub.NewMemory()
+			other := newEngine()
+			defer other.Close()
+			So(engine.SetPubSub(bus), ShouldBeNil)
+			So(other.SetPubSub(bus), ShouldBeNil)
+
+			So(engine.Disable(ctx, "pay", "下游故障"), ShouldBeNil)
+			_, err := other.Exec(ctx, "pay", map[string]any{})
+			So(errors.Is(err, ErrBizDisabled), ShouldBeTrue)
+
+			So(engine.Enable(ctx, "pay"), ShouldBeNil)
+			_, err = other.Exec(ctx, "pay", map[string]any{})
+			So(err, ShouldBeNil)
+			So(audits, ShouldHaveLength, 2)
+		})
+
+		Convey("审计记录失败时停用已生效并返回错误", func() {
+			engine.SetAuditRecorder(AuditFunc(func(context.Context, AuditEvent) error {
+				return errors.New("audit store unavailable")
+			}))
+			err := engine.Disable(ctx, "pay", "下游故障")
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "audit store unavailable")
+			So(engine.checkDisabled("pay"), ShouldNotBeNil)
+		})
+
+		Convey("参数无效或只读模式时拒绝", func() {
+			So(engine.Disable(ctx, "", "下游故障"), ShouldNotBeNil)
+			So(engine.Disable(ctx, "pay", ""), ShouldNotBeNil)
+			So(engine.Enable(ctx, ""), ShouldNotBeNil)
+
+			cfg.ReadOnly = true
+			So(errors.Is(engine.Disable(ctx, "pay", "下游故障"), ErrReadOnly), ShouldBeTrue)
+			So(errors.Is(engine.Enable(ctx, "pay"), ErrReadOnly), ShouldBeTrue)
+			So(engine.Disabled(), ShouldBeEmpty)
+		})
+	})
+}
+
+// TestKillSwitchPersistence 测试停用状态的持久化与启动加载
+func TestKillSwitchPersistence(t *testing.T) {
+	Convey("停用状态持久化", t, func() {
+		ctx := context.Background()
+		db, err := gorm.Open(sqlite.Open("file:kill_switch_persistence?mode=memory&cache=shared"), &gorm.Config{})
+		So(err, ShouldBeNil)
+		So(db.AutoMigrate(rule.Models()...), ShouldBeNil)
+		defer db.Exec("DELETE FROM runehammer_biz_disablements")
+
+		newEngine := func(db *gorm.DB) *engineImpl[map[string]any] {
+			return NewEngineImpl[map[string]any](
+				config.DefaultConfig(), rule.NewRuleMapper(db), nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+				ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+			)
+		}
+
+		Convey("重启后在执行前恢复停用状态", func() {
+			first := newEngine(db)
+			So(first.Disable(ctx, "pay", "下游故障"), ShouldBeNil)
+			So(first.Close(), ShouldBeNil)
+
+			restarted := newEngine(db)
+			defer restarted.Close()
+			So(restarted.Disabled(), ShouldBeEmpty)
+			So(restarted.LoadDisablements(ctx), ShouldBeNil)
+			So(restarted.Disabled(), ShouldHaveLength, 1)
+			So(restarted.Disabled()[0].Reason, ShouldEqual, "下游故障")
+			_, err := restarted.Exec(ctx, "pay", map[string]any{})
+			So(errors.Is(err, ErrBizDisabled), ShouldBeTrue)
+
+			So(restarted.Enable(ctx, "pay"), ShouldBeNil)
+			again := newEngine(db)
+			defer again.Close()
+			So(again.LoadDisablements(ctx), ShouldBeNil)
+			So(again.Disabled(), ShouldBeEmpty)
+		})
+
+		Convey("再次停用更新原因", func() {
+			engine := newEngine(db)
+			defer engine.Close()
+			So(engine.Disable(ctx, "pay", "下游故障"), ShouldBeNil)
+			So(engine.Disable(ctx, "pay", "数据异常"), ShouldBeNil)
+
+			var list []*rule.BizDisablement
+			So(db.Find(&list).Error, ShouldBeNil)
+			So(list, ShouldHaveLength, 1)
+			So(list[0].Reason, ShouldEqual, "数据异常")
+		})
+
+		Convey("未迁移时加载为空，停用在本实例生效并返回持久化错误", func() {
+			bare, err := gorm.Open(sqlite.Open("file:kill_switch_bare?mode=memory&cache=shared"), &gorm.Config{})
+			So(err, ShouldBeNil)
+			engine := newEngine(bare)
+			defer engine.Close()
+
+			So(engine.LoadDisablements(ctx), ShouldBeNil)
+			err = engine.Disable(ctx, "pay", "下游故障")
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "持久化停用状态失败")
+			So(engine.checkDisabled("pay"), ShouldNotBeNil)
+		})
+
+		Convey("映射器未实现持久化时只保存在内存中", func() {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			engine := NewEngineImpl[map[string]any](
+				config.DefaultConfig(), rule.NewMockRuleMapper(ctrl), nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+				ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+			)
+			defer engine.Close()
+
+			So(engine.LoadDisablements(ctx), ShouldBeNil)
+			So(engine.Disable(ctx, "pay", "下游故障"), ShouldBeNil)
+		})
+	})
+}
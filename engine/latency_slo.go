@@ -0,0 +1,294 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gitee.com/damengde/runehammer/config"
+	"gitee.com/damengde/runehammer/rule"
+)
+
+// ============================================================================
+// 延迟SLO - 按业务码统计窗口内的p95/p99执行延迟，超出目标时告警并标记为不健康
+// ============================================================================
+//
+// 延迟从参数验证通过到返回结果，包含获取规则、编译和执行，失败的执行同样计入。
+// 窗口内样本数达到最小样本数后才判断，状态变化（违反、恢复）时各告警一次，
+// 告警附带业务码规则的负责人（Rule.Owner），便于联系规则作者优化。
+
+const (
+	defaultSLOWindow     = 5 * time.Minute // 默认统计窗口
+	defaultSLOMinSamples = 100             // 默认最小样本数
+	maxSLOSamples        = 10000           // 每个业务码窗口内保留的最大样本数，超出时丢弃最早的样本
+)
+
+// sloEvalInterval 同一业务码两次判断的最小间隔，避免每次执行都排序样本
+var sloEvalInterval = time.Second
+
+// SLOAlert 延迟SLO告警
+type SLOAlert struct {
+	BizCode    string        // 业务码
+	Percentile string        // 分位，p95或p99
+	Target     time.Duration // SLO目标
+	Observed   time.Duration // 窗口内的实际分位延迟
+	Samples    int           // 窗口内的样本数
+	Window     time.Duration // 统计窗口
+	Owners     []string      // 业务码规则的负责人
+	Recovered  bool          // 是否为恢复通知
+}
+
+// String 告警描述
+func (a SLOAlert) String() string {
+	state := "违反SLO"
+	if a.Recovered {
+		state = "恢复"
+	}
+	s := fmt.Sprintf("%s %s %s: %s (目标 %s, 窗口 %s, 样本 %d)", a.BizCode, a.Percentile, state, a.Observed, a.Target, a.Window, a.Samples)
+	if len(a.Owners) > 0 {
+		s += " 负责人: " + strings.Join(a.Owners, ", ")
+	}
+	return s
+}
+
+// SLOAlerter 延迟SLO告警回调 - 在执行协程中同步调用，实现应避免阻塞
+type SLOAlerter interface {
+	OnSLOAlert(ctx context.Context, alert SLOAlert)
+}
+
+// SLOAlertFunc 函数形式的延迟SLO告警回调
+type SLOAlertFunc func(ctx context.Context, alert SLOAlert)
+
+// OnSLOAlert 实现SLOAlerter
+func (f SLOAlertFunc) OnSLOAlert(ctx context.Context, alert SLOAlert) {
+	f(ctx, alert)
+}
+
+// SetSLOAlerter 设置延迟SLO告警回调，为nil时以警告日志输出
+func (e *engineImpl[T]) SetSLOAlerter(alerter SLOAlerter) {
+	e.sloAlerter = alerter
+}
+
+// SLOStatus 业务码的延迟SLO状态
+type SLOStatus struct {
+	BizCode       string            `json:"biz_code"`                 // 业务码
+	Target        config.LatencySLO `json:"target"`                   // SLO目标
+	P95           time.Duration     `json:"p95"`                      // 窗口内的p95延迟
+	P99           time.Duration     `json:"p99"`                      // 窗口内的p99延迟
+	Samples       int               `json:"samples"`                  // 窗口内的样本数
+	Healthy       bool              `json:"healthy"`                  // 是否满足SLO，样本不足时保持上次判断结果
+	ViolatedSince time.Time         `json:"violated_since,omitempty"` // 开始违反SLO的时间
+	Owners        []string          `json:"owners,omitempty"`         // 业务码规则的负责人
+}
+
+// latencySample 单次执行延迟
+type latencySample struct {
+	at      time.Time
+	elapsed time.Duration
+}
+
+// latencyTracker 业务码的延迟统计
+type latencyTracker struct {
+	mu            sync.Mutex
+	samples       []latencySample // 按时间排列
+	rules         []*rule.Rule    // 最近一次执行的规则，用于获取负责人
+	violated      map[string]bool // 分位 -> 是否违反SLO
+	violatedSince time.Time
+	lastEval      time.Time
+	p95, p99      time.Duration
+}
+
+// latencySLO 获取业务码的延迟SLO，启用层级继承时依次查找父业务码
+func (e *engineImpl[T]) latencySLO(bizCode string) (config.LatencySLO, bool) {
+	if e.config == nil || len(e.config.LatencySLOs) == 0 {
+		return config.LatencySLO{}, false
+	}
+	chain := []string{bizCode}
+	if e.inheritanceEnabled() {
+		chain = bizCodeChain(bizCode)
+	}
+	for _, code := range chain {
+		if slo, ok := e.config.LatencySLOs[code]; ok && (slo.P95 > 0 || slo.P99 > 0) {
+			return slo, true
+		}
+	}
+	return config.LatencySLO{}, false
+}
+
+// sloWindow 延迟SLO统计窗口
+func (e *engineImpl[T]) sloWindow() time.Duration {
+	if e.config.LatencySLOWindow > 0 {
+		return e.config.LatencySLOWindow
+	}
+	return defaultSLOWindow
+}
+
+// sloMinSamples 延迟SLO最小样本数
+func (e *engineImpl[T]) sloMinSamples() int {
+	if e.config.LatencySLOMinSamples > 0 {
+		return e.config.LatencySLOMinSamples
+	}
+	return defaultSLOMinSamples
+}
+
+// recordLatency 记录一次执行延迟，按间隔判断是否违反SLO并发送状态变化告警
+func (e *engineImpl[T]) recordLatency(ctx context.Context, bizCode string, elapsed time.Duration, rules []*rule.Rule) {
+	slo, ok := e.latencySLO(bizCode)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	value, _ := e.sloTrackers.LoadOrStore(bizCode, &latencyTracker{})
+	tracker := value.(*latencyTracker)
+
+	tracker.mu.Lock()
+	tracker.add(now, elapsed, e.sloWindow())
+	if rules != nil {
+		tracker.rules = rules
+	}
+	var alerts []SLOAlert
+	if now.Sub(tracker.lastEval) >= sloEvalInterval {
+		tracker.lastEval = now
+		alerts = tracker.evaluate(bizCode, slo, e.sloWindow(), e.sloMinSamples(), now)
+	}
+	tracker.mu.Unlock()
+
+	for _, alert := range alerts {
+		if e.sloAlerter != nil {
+			e.sloAlerter.OnSLOAlert(ctx, alert)
+		} else if e.logger != nil {
+			e.logger.Warnf(ctx, "延迟SLO状态变化", "alert", alert.String())
+		}
+	}
+}
+
+// SLOStatus 获取已统计业务码的延迟SLO状态，按业务码排序
+func (e *engineImpl[T]) SLOStatus() []SLOStatus {
+	statuses := []SLOStatus{}
+	now := time.Now()
+	e.sloTrackers.Range(func(key, value any) bool {
+		bizCode := key.(string)
+		slo, ok := e.latencySLO(bizCode)
+		if !ok {
+			return true
+		}
+		tracker := value.(*latencyTracker)
+
+		tracker.mu.Lock()
+		tracker.trim(now, e.sloWindow())
+		tracker.p95, tracker.p99 = tracker.percentiles()
+		statuses = append(statuses, SLOStatus{
+			BizCode:       bizCode,
+			Target:        slo,
+			P95:           tracker.p95,
+			P99:           tracker.p99,
+			Samples:       len(tracker.samples),
+			Healthy:       tracker.violatedSince.IsZero(),
+			ViolatedSince: tracker.violatedSince,
+			Owners:        ruleOwnerNames(tracker.rules),
+		})
+		tracker.mu.Unlock()
+		return true
+	})
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].BizCode < statuses[j].BizCode })
+	return statuses
+}
+
+// add 追加样本并移除窗口外和超出容量的样本
+func (t *latencyTracker) add(now time.Time, elapsed time.Duration, window time.Duration) {
+	t.samples = append(t.samples, latencySample{at: now, elapsed: elapsed})
+	t.trim(now, window)
+	if excess := len(t.samples) - maxSLOSamples; excess > 0 {
+		t.samples = append(t.samples[:0], t.samples[excess:]...)
+	}
+}
+
+// trim 移除窗口外的样本
+func (t *latencyTracker) trim(now time.Time, window time.Duration) {
+	cutoff := now.Add(-window)
+	i := sort.Search(len(t.samples), func(i int) bool { return t.samples[i].at.After(cutoff) })
+	if i > 0 {
+		t.samples = append(t.samples[:0], t.samples[i:]...)
+	}
+}
+
+// percentiles 计算窗口内的p95和p99延迟（最近秩法）
+func (t *latencyTracker) percentiles() (p95, p99 time.Duration) {
+	n := len(t.samples)
+	if n == 0 {
+		return 0, 0
+	}
+	sorted := make([]time.Duration, n)
+	for i, s := range t.samples {
+		sorted[i] = s.elapsed
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	rank := func(p float64) time.Duration {
+		return sorted[int(math.Ceil(p*float64(n)))-1]
+	}
+	return rank(0.95), rank(0.99)
+}
+
+// evaluate 判断是否违反SLO，返回状态发生变化的分位告警
+func (t *latencyTracker) evaluate(bizCode string, slo config.LatencySLO, window time.Duration, minSamples int, now time.Time) []SLOAlert {
+	t.p95, t.p99 = t.percentiles()
+	if len(t.samples) < minSamples {
+		return nil
+	}
+	if t.violated == nil {
+		t.violated = make(map[string]bool)
+	}
+
+	var alerts []SLOAlert
+	check := func(percentile string, target, observed time.Duration) {
+		if target <= 0 {
+			return
+		}
+		violated := observed > target
+		if violated == t.violated[percentile] {
+			return
+		}
+		t.violated[percentile] = violated
+		alerts = append(alerts, SLOAlert{
+			BizCode:    bizCode,
+			Percentile: percentile,
+			Target:     target,
+			Observed:   observed,
+			Samples:    len(t.samples),
+			Window:     window,
+			Owners:     ruleOwnerNames(t.rules),
+			Recovered:  !violated,
+		})
+	}
+	check("p95", slo.P95, t.p95)
+	check("p99", slo.P99, t.p99)
+
+	healthy := !t.violated["p95"] && !t.violated["p99"]
+	switch {
+	case healthy:
+		t.violatedSince = time.Time{}
+	case t.violatedSince.IsZero():
+		t.violatedSince = now
+	}
+	return alerts
+}
+
+// ruleOwnerNames 规则负责人去重后排序
+func ruleOwnerNames(rules []*rule.Rule) []string {
+	seen := make(map[string]bool)
+	var owners []string
+	for _, r := range rules {
+		if r == nil || r.Owner == "" || seen[r.Owner] {
+			continue
+		}
+		seen[r.Owner] = true
+		owners = append(owners, r.Owner)
+	}
+	sort.Strings(owners)
+	return owners
+}
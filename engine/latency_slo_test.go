@@ -0,0 +1,125 @@
+package engine
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+// TestLatencySLO 测试延迟SLO统计和告警
+func TestLatencySLO(t *testing.T) {
+	Convey("延迟SLO", t, func() {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		ctx := context.Background()
+
+		interval := sloEvalInterval
+		sloEvalInterval = 0
+		defer func() { sloEvalInterval = interval }()
+
+		mapper := rule.NewMockRuleMapper(ctrl)
+		mapper.EXPECT().FindByBizCode(gomock.Any(), gomock.Any()).Return([]*rule.Rule{
+			{BizCode: "loan", Name: "limit", Enabled: true, Version: 1, Owner: "alice",
+				GRL: `rule Limit "额度" { when true then Result["limit"] = 100; Retract("Limit"); }`},
+			{BizCode: "loan", Name: "score", Enabled: true, Version: 1, Owner: "bob",
+				GRL: `rule Score "评分" { when true then Result["score"] = 700; Retract("Score"); }`},
+		}, nil).AnyTimes()
+
+		cfg := config.DefaultConfig()
+		cfg.LatencySLOs = map[string]config.LatencySLO{"loan": {P95: time.Hour, P99: time.Hour}}
+		cfg.LatencySLOMinSamples = 5
+		engine := NewEngineImpl[map[string]any](
+			cfg, mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+			ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+		)
+		defer engine.Close()
+
+		var alerts []SLOAlert
+		engine.SetSLOAlerter(SLOAlertFunc(func(ctx context.Context, alert SLOAlert) {
+			alerts = append(alerts, alert)
+		}))
+
+		Convey("执行延迟计入统计，满足SLO时不告警", func() {
+			for i := 0; i < 10; i++ {
+				_, err := engine.Exec(ctx, "loan", map[string]any{})
+				So(err, ShouldBeNil)
+			}
+			_, err := engine.Exec(ctx, "other", map[string]any{})
+			So(err, ShouldBeNil)
+
+			So(alerts, ShouldBeEmpty)
+			statuses := engine.SLOStatus()
+			So(statuses, ShouldHaveLength, 1)
+			So(statuses[0].BizCode, ShouldEqual, "loan")
+			So(statuses[0].Samples, ShouldEqual, 10)
+			So(statuses[0].Healthy, ShouldBeTrue)
+			So(statuses[0].P99, ShouldBeGreaterThan, 0)
+			So(statuses[0].Owners, ShouldResemble, []string{"alice", "bob"})
+			So(engine.DebugSnapshot().LatencySLOs, ShouldHaveLength, 1)
+		})
+
+		Convey("超出目标时告警一次并标记不健康，恢复后发送恢复通知", func() {
+			cfg.LatencySLOs["loan"] = config.LatencySLO{P99: time.Millisecond}
+			for i := 0; i < 5; i++ {
+				engine.recordLatency(ctx, "loan", 10*time.Millisecond, nil)
+			}
+			So(alerts, ShouldHaveLength, 1)
+			So(alerts[0].Percentile, ShouldEqual, "p99")
+			So(alerts[0].Observed, ShouldEqual, 10*time.Millisecond)
+			So(alerts[0].Recovered, ShouldBeFalse)
+			So(alerts[0].String(), ShouldContainSubstring, "违反SLO")
+
+			status := engine.SLOStatus()[0]
+			So(status.Healthy, ShouldBeFalse)
+			So(status.ViolatedSince.IsZero(), ShouldBeFalse)
+
+			// 慢样本占比降到1%以下后p99恢复
+			for i := 0; i < 600; i++ {
+				engine.recordLatency(ctx, "loan", time.Microsecond, nil)
+			}
+			So(alerts, ShouldHaveLength, 2)
+			So(alerts[1].Recovered, ShouldBeTrue)
+			So(engine.SLOStatus()[0].Healthy, ShouldBeTrue)
+		})
+
+		Convey("样本不足时不判断", func() {
+			cfg.LatencySLOs["loan"] = config.LatencySLO{P95: time.Millisecond}
+			for i := 0; i < 4; i++ {
+				engine.recordLatency(ctx, "loan", time.Second, nil)
+			}
+			So(alerts, ShouldBeEmpty)
+			So(engine.SLOStatus()[0].Healthy, ShouldBeTrue)
+		})
+
+		Convey("启用层级继承时使用父业务码的SLO", func() {
+			cfg.BizCodeInheritance = true
+			slo, ok := engine.latencySLO("loan.retail")
+			So(ok, ShouldBeTrue)
+			So(slo.P95, ShouldEqual, time.Hour)
+
+			_, ok = engine.latencySLO("other")
+			So(ok, ShouldBeFalse)
+		})
+
+		Convey("最近秩法计算分位", func() {
+			tracker := &latencyTracker{}
+			now := time.Now()
+			for i := 1; i <= 100; i++ {
+				tracker.add(now, time.Duration(i)*time.Millisecond, time.Minute)
+			}
+			p95, p99 := tracker.percentiles()
+			So(p95, ShouldEqual, 95*time.Millisecond)
+			So(p99, ShouldEqual, 99*time.Millisecond)
+		})
+	})
+}
@@ -0,0 +1,236 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+)
+
+// ============================================================================
+// 区域格式化函数 - 规则生成面向用户的文本时按区域格式化数字和金额
+// ============================================================================
+//
+// 规则中调用 FormatNumber(value, locale)、FormatCurrency(amount, currency, locale)、
+// ParseLocalizedNumber(text, locale)，locale 为空字符串时使用引擎默认区域（Config.Locale）。
+// 区域按 zh-CN、en_US 等形式匹配，不区分大小写；未收录的区域按语言匹配，仍未找到时使用默认区域。
+
+// DefaultLocale 未配置默认区域时使用的区域
+const DefaultLocale = "en-US"
+
+// localeFormat 区域数字格式
+type localeFormat struct {
+	decimal     string // 小数点
+	group       string // 千分位分隔符
+	indian      bool   // 印度分组：末三位之前每两位一组
+	symbolAfter bool   // 货币符号位于数字之后
+	symbolSpace string // 货币符号与数字之间的分隔
+}
+
+// localeFormats 已收录的区域格式
+var localeFormats = map[string]localeFormat{
+	"en-US": {decimal: ".", group: ","},
+	"en-GB": {decimal: ".", group: ","},
+	"en-IN": {decimal: ".", group: ",", indian: true},
+	"zh-CN": {decimal: ".", group: ","},
+	"zh-TW": {decimal: ".", group: ","},
+	"zh-HK": {decimal: ".", group: ","},
+	"ja-JP": {decimal: ".", group: ","},
+	"ko-KR": {decimal: ".", group: ","},
+	"de-DE": {decimal: ",", group: ".", symbolAfter: true, symbolSpace: "\u00a0"},
+	"es-ES": {decimal: ",", group: ".", symbolAfter: true, symbolSpace: "\u00a0"},
+	"it-IT": {decimal: ",", group: ".", symbolAfter: true, symbolSpace: "\u00a0"},
+	"pt-BR": {decimal: ",", group: ".", symbolSpace: "\u00a0"},
+	"fr-FR": {decimal: ",", group: "\u202f", symbolAfter: true, symbolSpace: "\u00a0"},
+	"ru-RU": {decimal: ",", group: "\u00a0", symbolAfter: true, symbolSpace: "\u00a0"},
+}
+
+// languageLocales 语言 -> 默认区域，用于只指定语言或区域未收录时匹配
+var languageLocales = map[string]string{
+	"en": "en-US",
+	"zh": "zh-CN",
+	"ja": "ja-JP",
+	"ko": "ko-KR",
+	"de": "de-DE",
+	"es": "es-ES",
+	"it": "it-IT",
+	"pt": "pt-BR",
+	"fr": "fr-FR",
+	"ru": "ru-RU",
+}
+
+// currencySymbols 货币代码 -> 符号，未收录的货币使用代码本身
+var currencySymbols = map[string]string{
+	"CNY": "¥",
+	"JPY": "¥",
+	"USD": "$",
+	"EUR": "€",
+	"GBP": "£",
+	"KRW": "₩",
+	"INR": "₹",
+	"RUB": "₽",
+	"BRL": "R$",
+	"HKD": "HK$",
+	"TWD": "NT$",
+}
+
+// currencyDigits 货币的小数位数，未收录的货币为2位
+var currencyDigits = map[string]int{
+	"JPY": 0,
+	"KRW": 0,
+}
+
+// resolveLocale 解析区域格式 - 依次按完整区域、语言、默认区域匹配
+func resolveLocale(locale, fallback string) localeFormat {
+	for _, candidate := range []string{locale, fallback, DefaultLocale} {
+		if candidate == "" {
+			continue
+		}
+		if format, ok := lookupLocale(candidate); ok {
+			return format
+		}
+	}
+	return localeFormats[DefaultLocale]
+}
+
+// lookupLocale 查找区域格式，区域未收录时按语言匹配
+func lookupLocale(locale string) (localeFormat, bool) {
+	parts := strings.SplitN(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"), "-", 2)
+	language := strings.ToLower(parts[0])
+	if len(parts) == 2 {
+		if format, ok := localeFormats[language+"-"+strings.ToUpper(parts[1])]; ok {
+			return format, true
+		}
+	}
+	if name, ok := languageLocales[language]; ok {
+		return localeFormats[name], true
+	}
+	return localeFormat{}, false
+}
+
+// formatNumber 按区域格式化数字 - 保留数值的全部有效小数位
+func formatNumber(value float64, format localeFormat) string {
+	return formatDigits(strconv.FormatFloat(value, 'f', -1, 64), format)
+}
+
+// formatCurrency 按区域格式化金额 - 小数位数由货币决定，四舍五入（远离零）
+func formatCurrency(amount float64, currency string, format localeFormat) string {
+	code := strings.ToUpper(strings.TrimSpace(currency))
+	digits, ok := currencyDigits[code]
+	if !ok {
+		digits = 2
+	}
+	symbol, ok := currencySymbols[code]
+	if !ok {
+		symbol = code
+	}
+
+	scale := math.Pow10(digits)
+	rounded := math.Round(math.Abs(amount)*scale) / scale
+	number := formatDigits(strconv.FormatFloat(rounded, 'f', digits, 64), format)
+	sign := ""
+	if amount < 0 && strings.Trim(number, "0"+format.group+format.decimal) != "" {
+		sign = "-"
+	}
+	if format.symbolAfter {
+		return sign + number + format.symbolSpace + symbol
+	}
+	return sign + symbol + format.symbolSpace + number
+}
+
+// formatDigits 为十进制字符串添加区域分组和小数点
+func formatDigits(digits string, format localeFormat) string {
+	sign := ""
+	if strings.HasPrefix(digits, "-") {
+		sign, digits = "-", digits[1:]
+	}
+	integer, fraction, hasFraction := strings.Cut(digits, ".")
+
+	var groups []string
+	if len(integer) > 3 {
+		groups = append(groups, integer[len(integer)-3:])
+		integer = integer[:len(integer)-3]
+		size := 3
+		if format.indian {
+			size = 2
+		}
+		for len(integer) > size {
+			groups = append(groups, integer[len(integer)-size:])
+			integer = integer[:len(integer)-size]
+		}
+	}
+	groups = append(groups, integer)
+	for i, j := 0, len(groups)-1; i < j; i, j = i+1, j-1 {
+		groups[i], groups[j] = groups[j], groups[i]
+	}
+
+	result := sign + strings.Join(groups, format.group)
+	if hasFraction {
+		result += format.decimal + fraction
+	}
+	return result
+}
+
+// parseLocalizedNumber 按区域解析数字文本 - 忽略分组分隔符和空白
+func parseLocalizedNumber(text string, format localeFormat) (float64, error) {
+	normalized := strings.TrimSpace(text)
+	for _, sep := range []string{format.group, " ", "\u00a0", "\u202f"} {
+		normalized = strings.ReplaceAll(normalized, sep, "")
+	}
+	normalized = strings.Replace(normalized, format.decimal, ".", 1)
+
+	value, err := strconv.ParseFloat(normalized, 64)
+	if err != nil {
+		return 0, fmt.Errorf("无法解析数字 %q", text)
+	}
+	return value, nil
+}
+
+// FormatNumber 按区域格式化数字，locale为空时使用引擎默认区域
+func (f *ruleFunctions) FormatNumber(value any, locale string) string {
+	number, ok := toFloat(value)
+	if !ok {
+		panic(fmt.Errorf("FormatNumber 需要数值参数，实际为 %T", value))
+	}
+	return formatNumber(number, resolveLocale(locale, f.locale))
+}
+
+// FormatCurrency 按区域格式化金额，currency为ISO 4217货币代码，locale为空时使用引擎默认区域
+func (f *ruleFunctions) FormatCurrency(amount any, currency, locale string) string {
+	number, ok := toFloat(amount)
+	if !ok {
+		panic(fmt.Errorf("FormatCurrency 需要数值参数，实际为 %T", amount))
+	}
+	return formatCurrency(number, currency, resolveLocale(locale, f.locale))
+}
+
+// ParseLocalizedNumber 按区域解析数字文本，如 de-DE 的 "1.234,5"，无法解析时规则执行返回错误
+func (f *ruleFunctions) ParseLocalizedNumber(text, locale string) float64 {
+	value, err := parseLocalizedNumber(text, resolveLocale(locale, f.locale))
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+// injectLocaleFunctions 注入区域格式化函数
+func (e *engineImpl[T]) injectLocaleFunctions(dataCtx ast.IDataContext) {
+	defaultLocale := ""
+	if e.config != nil {
+		defaultLocale = e.config.Locale
+	}
+
+	dataCtx.Add("FormatNumber", func(value float64, locale string) string {
+		return formatNumber(value, resolveLocale(locale, defaultLocale))
+	})
+
+	dataCtx.Add("FormatCurrency", func(amount float64, currency, locale string) string {
+		return formatCurrency(amount, currency, resolveLocale(locale, defaultLocale))
+	})
+
+	dataCtx.Add("ParseLocalizedNumber", func(text, locale string) (float64, error) {
+		return parseLocalizedNumber(text, resolveLocale(locale, defaultLocale))
+	})
+}
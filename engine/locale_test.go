@@ -0,0 +1,117 @@
+package engine
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+// TestLocaleFormatting 测试区域格式化函数
+func TestLocaleFormatting(t *testing.T) {
+	Convey("区域格式化函数", t, func() {
+		Convey("格式化数字", func() {
+			So(formatNumber(1234567.891, resolveLocale("en-US", "")), ShouldEqual, "1,234,567.891")
+			So(formatNumber(1234567.5, resolveLocale("de-DE", "")), ShouldEqual, "1.234.567,5")
+			So(formatNumber(1234567, resolveLocale("fr_fr", "")), ShouldEqual, "1\u202f234\u202f567")
+			So(formatNumber(12345678, resolveLocale("en-IN", "")), ShouldEqual, "1,23,45,678")
+			So(formatNumber(-999, resolveLocale("en-US", "")), ShouldEqual, "-999")
+			So(formatNumber(-1000, resolveLocale("en-US", "")), ShouldEqual, "-1,000")
+		})
+
+		Convey("格式化金额", func() {
+			So(formatCurrency(1234.5, "CNY", resolveLocale("zh-CN", "")), ShouldEqual, "¥1,234.50")
+			So(formatCurrency(1234.5, "eur", resolveLocale("de-DE", "")), ShouldEqual, "1.234,50\u00a0€")
+			So(formatCurrency(1234.5, "JPY", resolveLocale("ja-JP", "")), ShouldEqual, "¥1,235")
+			So(formatCurrency(-12.346, "USD", resolveLocale("en-US", "")), ShouldEqual, "-$12.35")
+			So(formatCurrency(-0.001, "USD", resolveLocale("en-US", "")), ShouldEqual, "$0.00")
+			So(formatCurrency(10, "CHF", resolveLocale("en-US", "")), ShouldEqual, "CHF10.00")
+		})
+
+		Convey("解析本地化数字", func() {
+			value, err := parseLocalizedNumber("1.234,5", resolveLocale("de-DE", ""))
+			So(err, ShouldBeNil)
+			So(value, ShouldEqual, 1234.5)
+
+			value, err = parseLocalizedNumber(" 1 234,5 ", resolveLocale("fr-FR", ""))
+			So(err, ShouldBeNil)
+			So(value, ShouldEqual, 1234.5)
+
+			_, err = parseLocalizedNumber("abc", resolveLocale("en-US", ""))
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("区域匹配", func() {
+			So(resolveLocale("de-AT", ""), ShouldResemble, localeFormats["de-DE"])
+			So(resolveLocale("", "de-DE"), ShouldResemble, localeFormats["de-DE"])
+			So(resolveLocale("xx-YY", "fr"), ShouldResemble, localeFormats["fr-FR"])
+			So(resolveLocale("xx-YY", ""), ShouldResemble, localeFormats[DefaultLocale])
+		})
+
+		Convey("规则中按引擎默认区域格式化", func() {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mapper := rule.NewMockRuleMapper(ctrl)
+			mapper.EXPECT().FindByBizCode(gomock.Any(), "locale").Return([]*rule.Rule{
+				{Name: "format", Enabled: true, GRL: `rule Format "格式化" {
+	when Params["amount"] > 0
+	then
+		Result["amount"] = FormatCurrency(Params["amount"], "EUR", "");
+		Result["us"] = FormatNumber(Params["amount"], "en-US");
+		Result["parsed"] = ParseLocalizedNumber("1.234,5", "");
+		Retract("Format");
+}`},
+				{Name: "invalid", Enabled: true, GRL: `rule Invalid "无效" {
+	when Params["bad"] == true
+	then
+		Result["parsed"] = ParseLocalizedNumber("abc", "");
+		Retract("Invalid");
+}`},
+			}, nil).AnyTimes()
+
+			cfg := config.DefaultConfig()
+			cfg.Locale = "de-DE"
+			engine := NewEngineImpl[map[string]any](
+				cfg, mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+				ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+			)
+			defer engine.Close()
+
+			result, err := engine.Exec(context.Background(), "locale", map[string]any{"amount": 1234.5, "bad": false})
+			So(err, ShouldBeNil)
+			So(result["amount"], ShouldEqual, "1.234,50\u00a0€")
+			So(result["us"], ShouldEqual, "1,234.5")
+			So(result["parsed"], ShouldEqual, 1234.5)
+
+			_, err = engine.Exec(context.Background(), "locale", map[string]any{"amount": 0, "bad": true})
+			So(err, ShouldNotBeNil)
+
+			metadata := engine.builtinFunctionInfos()
+			names := make([]string, 0, len(metadata))
+			for _, fn := range metadata {
+				names = append(names, fn.Name)
+			}
+			So(names, ShouldContain, "FormatCurrency")
+			So(names, ShouldContain, "ParseLocalizedNumber")
+		})
+
+		Convey("动态引擎", func() {
+			engine := NewDynamicEngine[map[string]interface{}](DynamicEngineConfig{EnableCache: false, Locale: "zh-CN"})
+			result, err := engine.ExecuteRuleDefinition(context.Background(), rule.SimpleRule{
+				When: "Params.Amount > 0",
+				Then: map[string]string{"Result.Text": `FormatCurrency(Params.Amount, "CNY", "")`},
+			}, struct{ Amount float64 }{Amount: 1234.5})
+			So(err, ShouldBeNil)
+			So(result["Text"], ShouldEqual, "¥1,234.50")
+		})
+	})
+}
@@ -0,0 +1,84 @@
+package engine
+
+import (
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+)
+
+// ============================================================================
+// 结果合并函数 - 多条规则写入同一列表或嵌套map时累加而非覆盖
+// ============================================================================
+
+// injectMergeFunctions 注入结果合并函数
+func injectMergeFunctions(dataCtx ast.IDataContext) {
+	// 向target[key]列表追加元素，列表不存在时创建，返回追加后的长度
+	dataCtx.Add("AppendTo", func(target map[string]interface{}, key string, value interface{}) int {
+		return appendTo(target, key, value)
+	})
+
+	// 将values深度合并到target[key]，target[key]不存在时创建
+	dataCtx.Add("MergeMap", func(target map[string]interface{}, key string, values map[string]interface{}) map[string]interface{} {
+		return mergeInto(target, key, values)
+	})
+}
+
+// appendTo 向target[key]列表追加元素
+//
+// 已有值不是列表时将其作为首个元素，避免丢失先执行规则写入的值。
+func appendTo(target map[string]interface{}, key string, value interface{}) int {
+	if target == nil {
+		return 0
+	}
+
+	var list []interface{}
+	switch existing := target[key].(type) {
+	case nil:
+	case []interface{}:
+		list = existing
+	default:
+		list = []interface{}{existing}
+	}
+
+	list = append(list, value)
+	target[key] = list
+	return len(list)
+}
+
+// mergeInto 将values深度合并到target[key]
+func mergeInto(target map[string]interface{}, key string, values map[string]interface{}) map[string]interface{} {
+	if target == nil {
+		return nil
+	}
+
+	existing, ok := target[key].(map[string]interface{})
+	if !ok {
+		existing = make(map[string]interface{}, len(values))
+		target[key] = existing
+	}
+
+	deepMerge(existing, values)
+	return existing
+}
+
+// deepMerge 深度合并src到dst - 双方均为map的字段递归合并，双方均为列表的字段拼接，其余字段以src覆盖
+func deepMerge(dst, src map[string]interface{}) {
+	for key, value := range src {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			if existing, ok := dst[key].(map[string]interface{}); ok {
+				deepMerge(existing, v)
+				continue
+			}
+			copied := make(map[string]interface{}, len(v))
+			deepMerge(copied, v)
+			dst[key] = copied
+		case []interface{}:
+			if existing, ok := dst[key].([]interface{}); ok {
+				dst[key] = append(existing, v...)
+				continue
+			}
+			dst[key] = append([]interface{}{}, v...)
+		default:
+			dst[key] = value
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package engine
+
+import (
+	"sync"
+	"testing"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// TestMergeFunctions 测试结果合并函数
+func TestMergeFunctions(t *testing.T) {
+	Convey("结果合并函数", t, func() {
+
+		Convey("AppendTo追加列表元素", func() {
+			result := map[string]interface{}{}
+			So(appendTo(result, "reasons", "年龄不足"), ShouldEqual, 1)
+			So(appendTo(result, "reasons", "信用不足"), ShouldEqual, 2)
+			So(result["reasons"], ShouldResemble, []interface{}{"年龄不足", "信用不足"})
+
+			// 已有非列表值时保留为首个元素
+			result["tags"] = "vip"
+			So(appendTo(result, "tags", "new"), ShouldEqual, 2)
+			So(result["tags"], ShouldResemble, []interface{}{"vip", "new"})
+		})
+
+		Convey("MergeMap深度合并嵌套map", func() {
+			result := map[string]interface{}{}
+			mergeInto(result, "detail", map[string]interface{}{
+				"score": 10,
+				"flags": []interface{}{"a"},
+				"risk":  map[string]interface{}{"level": "low"},
+			})
+			mergeInto(result, "detail", map[string]interface{}{
+				"score": 20,
+				"flags": []interface{}{"b"},
+				"risk":  map[string]interface{}{"source": "rule2"},
+			})
+
+			So(result["detail"], ShouldResemble, map[string]interface{}{
+				"score": 20,
+				"flags": []interface{}{"a", "b"},
+				"risk":  map[string]interface{}{"level": "low", "source": "rule2"},
+			})
+		})
+
+		Convey("作为内置函数注入", func() {
+			dataCtx := ast.NewDataContext()
+			injectMergeFunctions(dataCtx)
+			So(dataCtx.Get("AppendTo"), ShouldNotBeNil)
+			So(dataCtx.Get("MergeMap"), ShouldNotBeNil)
+		})
+
+		Convey("嵌套结果提取为结构体", func() {
+			type riskDetail struct {
+				Level  string `json:"level"`
+				Source string `json:"source"`
+			}
+			type decision struct {
+				Reasons []string   `json:"reasons"`
+				Risk    riskDetail `json:"risk"`
+			}
+
+			engine := NewEngineImpl[decision](
+				config.DefaultConfig(), nil, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+				ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+			)
+
+			result := map[string]interface{}{}
+			appendTo(result, "reasons", "年龄不足")
+			appendTo(result, "reasons", "信用不足")
+			mergeInto(result, "risk", map[string]interface{}{"level": "high"})
+			mergeInto(result, "risk", map[string]interface{}{"source": "rule2"})
+
+			extracted, err := engine.extractGenericResult(result)
+			So(err, ShouldBeNil)
+			So(extracted.Reasons, ShouldResemble, []string{"年龄不足", "信用不足"})
+			So(extracted.Risk, ShouldResemble, riskDetail{Level: "high", Source: "rule2"})
+		})
+	})
+}
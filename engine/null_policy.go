@@ -0,0 +1,49 @@
+package engine
+
+import (
+	"reflect"
+
+	"gitee.com/damengde/runehammer/config"
+)
+
+// ============================================================================
+// 缺失字段语义 - 按业务码确定缺失字段的比较语义，并提供转换器生成的 Present 判断
+// ============================================================================
+//
+// 语义为error时条件求值出错（如访问不存在的map键）直接返回执行错误；
+// 其余语义下出错的条件不命中，false/unknown语义的差异由转换器生成的存在性判断体现。
+// 转换规则定义时应使用同一语义: rule.NewGRLConverter(rule.ConverterConfig{NullPolicy: engine.NullPolicy(bizCode)})。
+
+// NullPolicy 获取业务码的缺失字段比较语义
+//
+// 优先使用 Config.BizNullPolicies 中业务码的配置，启用层级继承时依次查找父业务码，均未配置时使用 Config.NullPolicy。
+func (e *engineImpl[T]) NullPolicy(bizCode string) config.NullPolicy {
+	if e.config == nil {
+		return config.NullPolicyDefault
+	}
+	if len(e.config.BizNullPolicies) > 0 {
+		chain := []string{bizCode}
+		if e.inheritanceEnabled() {
+			chain = bizCodeChain(bizCode)
+		}
+		for _, code := range chain {
+			if policy, ok := e.config.BizNullPolicies[code]; ok {
+				return policy
+			}
+		}
+	}
+	return e.config.NullPolicy
+}
+
+// Present 判断字段存在且不为nil（含nil指针、map、切片），路径首段为数据上下文中的名称时从该对象取值，否则从Params取值
+func (f *ruleFunctions) Present(path string) bool {
+	value, ok := f.resolve(path)
+	if !ok || value == nil {
+		return false
+	}
+	switch v := reflect.ValueOf(value); v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface, reflect.Func, reflect.Chan:
+		return !v.IsNil()
+	}
+	return true
+}
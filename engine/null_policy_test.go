@@ -0,0 +1,122 @@
+package engine
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+// NullApplicant 缺失字段语义测试用的结构体输入
+type NullApplicant struct {
+	Attrs map[string]any
+}
+
+// TestNullPolicy 测试缺失字段语义
+func TestNullPolicy(t *testing.T) {
+	Convey("缺失字段语义", t, func() {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		cfg := config.DefaultConfig()
+		cfg.NullPolicy = config.NullPolicyFalse
+		cfg.BizCodeInheritance = true
+		cfg.BizNullPolicies = map[string]config.NullPolicy{
+			"strict":   config.NullPolicyError,
+			"payments": config.NullPolicyUnknown,
+			"legacy":   config.NullPolicyDefault,
+		}
+		mapper := rule.NewMockRuleMapper(ctrl)
+		engine := NewEngineImpl[map[string]any](
+			cfg, mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+			ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+		)
+		defer engine.Close()
+
+		// 按业务码语义转换"等级不是gold"规则
+		notGold := func(bizCode string) []*rule.Rule {
+			converter := rule.NewGRLConverter(rule.ConverterConfig{NullPolicy: engine.NullPolicy(bizCode)})
+			r := rule.NewStandardRule("not_gold", "非gold")
+			r.Conditions = rule.Field(`Params["level"]`).Ne("gold")
+			r.Actions = []rule.Action{{Type: rule.ActionTypeAssign, Target: "Result.not_gold", Value: true}}
+			grl, err := converter.ConvertRule(*r, rule.Definitions{})
+			So(err, ShouldBeNil)
+			return []*rule.Rule{{BizCode: bizCode, Name: "not_gold", Enabled: true, GRL: grl}}
+		}
+		for _, bizCode := range []string{"loan", "strict", "payments", "legacy"} {
+			mapper.EXPECT().FindByBizCode(gomock.Any(), bizCode).DoAndReturn(
+				func(context.Context, string) ([]*rule.Rule, error) { return notGold(bizCode), nil }).AnyTimes()
+		}
+		mapper.EXPECT().FindByBizCode(gomock.Any(), "payments.cards").Return(nil, nil).AnyTimes()
+
+		Convey("按业务码解析语义，子业务码沿用父业务码", func() {
+			So(engine.NullPolicy("loan"), ShouldEqual, config.NullPolicyFalse)
+			So(engine.NullPolicy("strict"), ShouldEqual, config.NullPolicyError)
+			So(engine.NullPolicy("payments.cards"), ShouldEqual, config.NullPolicyUnknown)
+			So(engine.NullPolicy("legacy"), ShouldEqual, config.NullPolicyDefault)
+		})
+
+		Convey("false语义下缺失字段的比较为false", func() {
+			result, err := engine.Exec(context.Background(), "loan", map[string]any{})
+			So(err, ShouldBeNil)
+			So(result, ShouldNotContainKey, "not_gold")
+
+			result, err = engine.Exec(context.Background(), "loan", map[string]any{"level": "silver"})
+			So(err, ShouldBeNil)
+			So(result["not_gold"], ShouldEqual, true)
+		})
+
+		Convey("三值逻辑下缺失字段的比较不命中", func() {
+			result, err := engine.Exec(context.Background(), "payments.cards", map[string]any{"level": nil})
+			So(err, ShouldBeNil)
+			So(result, ShouldNotContainKey, "not_gold")
+
+			result, err = engine.Exec(context.Background(), "payments.cards", map[string]any{"level": "silver"})
+			So(err, ShouldBeNil)
+			So(result["not_gold"], ShouldEqual, true)
+		})
+
+		Convey("error语义下缺失字段返回执行错误", func() {
+			_, err := engine.Exec(context.Background(), "strict", map[string]any{})
+			So(err, ShouldNotBeNil)
+
+			result, err := engine.Exec(context.Background(), "strict", map[string]any{"level": "silver"})
+			So(err, ShouldBeNil)
+			So(result["not_gold"], ShouldEqual, true)
+		})
+
+		Convey("动态引擎按配置的语义转换并执行", func() {
+			r := rule.NewStandardRule("not_gold", "非gold")
+			r.Conditions = rule.Field(`Params.Attrs["level"]`).Ne("gold")
+			r.Actions = []rule.Action{{Type: rule.ActionTypeAssign, Target: "Result.not_gold", Value: true}}
+
+			dynamic := NewDynamicEngine[map[string]any](DynamicEngineConfig{NullPolicy: config.NullPolicyFalse})
+			defer dynamic.Close()
+			result, err := dynamic.ExecuteRuleDefinition(context.Background(), *r, NullApplicant{Attrs: map[string]any{}})
+			So(err, ShouldBeNil)
+			So(result, ShouldNotContainKey, "not_gold")
+			result, err = dynamic.ExecuteRuleDefinition(context.Background(), *r, NullApplicant{Attrs: map[string]any{"level": "silver"}})
+			So(err, ShouldBeNil)
+			So(result["not_gold"], ShouldEqual, true)
+
+			dynamic = NewDynamicEngine[map[string]any](DynamicEngineConfig{NullPolicy: config.NullPolicyError})
+			defer dynamic.Close()
+			_, err = dynamic.ExecuteRuleDefinition(context.Background(), *r, NullApplicant{Attrs: map[string]any{}})
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("默认语义沿用Grule行为，条件出错时不命中", func() {
+			result, err := engine.Exec(context.Background(), "legacy", map[string]any{})
+			So(err, ShouldBeNil)
+			So(result, ShouldNotContainKey, "not_gold")
+		})
+	})
+}
@@ -0,0 +1,77 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gitee.com/damengde/runehammer/rule"
+)
+
+// ============================================================================
+// 运行时覆盖同步 - 通过消息通道（见 instance_sync.go）把 AddOverride/RemoveOverride 广播给其他实例
+// ============================================================================
+//
+// 每个实例收到消息后在本地添加或移除覆盖规则，过期时间为绝对时间，各实例按本地时钟到期移除。
+// 消息不持久化，广播之后启动的实例不会收到之前添加的覆盖规则；只读实例同样接收覆盖规则，
+// 保证紧急拦截在所有实例上生效。
+
+// overrideChannel 运行时覆盖的广播频道
+const overrideChannel = "runehammer:overrides"
+
+// overrideOp 运行时覆盖变更类型
+type overrideOp string
+
+const (
+	overrideAdd    overrideOp = "add"    // 添加或替换覆盖规则
+	overrideRemove overrideOp = "remove" // 移除覆盖规则
+)
+
+// overrideEvent 运行时覆盖变更消息
+type overrideEvent struct {
+	Op        overrideOp `json:"op"`
+	BizCode   string     `json:"biz_code"`
+	Name      string     `json:"name"`
+	Rule      *rule.Rule `json:"rule,omitempty"`
+	ExpiresAt time.Time  `json:"expires_at"` // 为零值表示不过期
+}
+
+// publishOverride 广播运行时覆盖变更，未设置消息通道时忽略
+func (e *engineImpl[T]) publishOverride(ctx context.Context, event overrideEvent) error {
+	if err := e.publish(ctx, overrideChannel, event); err != nil {
+		if e.logger != nil {
+			e.logger.Warnf(ctx, "广播运行时覆盖失败", "bizCode", event.BizCode, "rule", event.Name, "error", err)
+		}
+		return fmt.Errorf("运行时覆盖已在本实例生效，广播到其他实例失败: %w", err)
+	}
+	return nil
+}
+
+// handleOverrideEvent 处理其他实例的运行时覆盖变更
+func (e *engineImpl[T]) handleOverrideEvent(payload []byte) {
+	ctx := context.Background()
+
+	var event overrideEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		if e.logger != nil {
+			e.logger.Warnf(ctx, "解析运行时覆盖消息失败", "error", err)
+		}
+		return
+	}
+	if event.BizCode == "" {
+		return
+	}
+
+	switch event.Op {
+	case overrideAdd:
+		if event.Rule == nil || event.Rule.Name == "" {
+			return
+		}
+		event.Rule.BizCode = event.BizCode
+		event.Rule.Enabled = true
+		e.applyOverride(ctx, event.BizCode, event.Rule, event.ExpiresAt)
+	case overrideRemove:
+		e.revokeOverride(ctx, event.BizCode, event.Name)
+	}
+}
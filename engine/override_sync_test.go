@@ -0,0 +1,109 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/pubsub"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+// failingPubSub 发布总是失败的消息通道
+type failingPubSub struct{ pubsub.PubSub }
+
+func (failingPubSub) Publish(context.Context, string, []byte) error {
+	return errors.New("connection refused")
+}
+
+// TestOverrideSync 测试运行时覆盖的实例间同步
+func TestOverrideSync(t *testing.T) {
+	Convey("运行时覆盖同步", t, func() {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		ctx := context.Background()
+
+		mapper := rule.NewMockRuleMapper(ctrl)
+		mapper.EXPECT().FindByBizCode(gomock.Any(), "pay").Return([]*rule.Rule{
+			{BizCode: "pay", Name: "block", Enabled: true, Version: 1,
+				GRL: `rule Allow "放行" { when true then Result["blocked"] = false; Retract("Allow"); }`},
+		}, nil).AnyTimes()
+
+		newEngine := func() *engineImpl[map[string]any] {
+			return NewEngineImpl[map[string]any](
+				config.DefaultConfig(), mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+				ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+			)
+		}
+		bus := pubsub.NewMemory()
+		first, second := newEngine(), newEngine()
+		defer first.Close()
+		defer second.Close()
+		So(first.SetPubSub(bus), ShouldBeNil)
+		So(second.SetPubSub(bus), ShouldBeNil)
+
+		block := &rule.Rule{Name: "block",
+			GRL: `rule Block "紧急拦截" { when Params["merchant"] == "M1" then Result["blocked"] = true; Retract("Block"); }`}
+		input := map[string]any{"merchant": "M1"}
+
+		Convey("添加和移除广播到其他实例", func() {
+			result, err := second.Exec(ctx, "pay", input)
+			So(err, ShouldBeNil)
+			So(result["blocked"], ShouldEqual, false)
+
+			So(first.AddOverride(ctx, "pay", block, time.Hour), ShouldBeNil)
+			result, err = second.Exec(ctx, "pay", input)
+			So(err, ShouldBeNil)
+			So(result["blocked"], ShouldEqual, true)
+
+			resolution, err := second.Resolve(ctx, "pay")
+			So(err, ShouldBeNil)
+			resolved, _ := resolution.Find("block")
+			So(resolved.Layer, ShouldEqual, LayerOverride)
+			So(resolved.ExpiresAt, ShouldNotBeNil)
+
+			So(first.RemoveOverride(ctx, "pay", "block"), ShouldBeNil)
+			result, err = second.Exec(ctx, "pay", input)
+			So(err, ShouldBeNil)
+			So(result["blocked"], ShouldEqual, false)
+		})
+
+		Convey("本实例不存在时仍然广播移除", func() {
+			So(second.AddOverride(ctx, "pay", block, 0), ShouldBeNil)
+			So(first.revokeOverride(ctx, "pay", "block"), ShouldBeTrue)
+
+			err := first.RemoveOverride(ctx, "pay", "block")
+			So(errors.Is(err, ErrOverrideNotFound), ShouldBeTrue)
+			So(second.overrides.list("pay"), ShouldBeEmpty)
+		})
+
+		Convey("忽略已过期和格式错误的消息", func() {
+			second.handleOverrideEvent([]byte(`{"op":"add","biz_code":"pay","name":"block","rule":{"name":"block","grl":"x"},"expires_at":"2000-01-01T00:00:00Z"}`))
+			second.handleOverrideEvent([]byte(`not json`))
+			So(second.overrides.list("pay"), ShouldBeEmpty)
+		})
+
+		Convey("关闭后不再接收消息", func() {
+			So(second.Close(), ShouldBeNil)
+			So(first.AddOverride(ctx, "pay", block, 0), ShouldBeNil)
+			So(second.overrides.list("pay"), ShouldBeEmpty)
+		})
+
+		Convey("广播失败时本实例已生效并返回错误", func() {
+			So(first.SetPubSub(failingPubSub{bus}), ShouldBeNil)
+			err := first.AddOverride(ctx, "pay", block, 0)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "connection refused")
+			So(first.overrides.list("pay"), ShouldHaveLength, 1)
+		})
+	})
+}
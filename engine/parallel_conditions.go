@@ -0,0 +1,120 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+)
+
+// ============================================================================
+// 并行条件 - 复合条件中相互独立的高开销函数条件并发求值，得到决定性结果后立即短路
+// ============================================================================
+//
+// 规则中以 Func.Bind("名称", 参数...) 描述一次函数调用（不执行），交给 Func.AnyOf / Func.AllOf 并发求值：
+//
+//	Func.AnyOf(Func.Bind("InBlacklist", Params.UserID), Func.Bind("HitFraudModel", Params.UserID))
+//
+// 顺序保证:
+//  1. 各分支按声明顺序启动、并发执行，函数必须是并发安全的，分支之间的副作用顺序不确定
+//  2. 任一分支得到决定性结果（AnyOf为true，AllOf为false）时立即返回，其余分支的上下文被取消且不再等待，
+//     其错误（包括取消导致的超时错误）不影响本次执行
+//  3. 没有决定性结果时等待全部分支结束；有分支失败时以声明顺序最靠前的失败分支的错误结束本次执行，
+//     与顺序求值遇到的首个错误一致
+//  4. 没有错误时结果与 && / || 顺序求值相同；空参数时AnyOf为false、AllOf为true
+
+// boundCall 绑定参数的自定义函数调用，由 Func.Bind 创建
+type boundCall struct {
+	name string
+	args []interface{}
+}
+
+// branchOutcome 单个分支的求值结果
+type branchOutcome struct {
+	index int
+	value bool
+	err   error
+}
+
+// Bind 绑定自定义函数及参数但不调用，供 AnyOf / AllOf 并发求值
+func (c *customFunctionCaller) Bind(name string, args ...interface{}) interface{} {
+	if _, ok := c.functions[name]; !ok {
+		err := fmt.Errorf("未注册的自定义函数: %s", name)
+		c.guard.fail(err)
+		panic(err)
+	}
+	return &boundCall{name: name, args: args}
+}
+
+// AnyOf 并发调用绑定的bool函数，任一返回true时立即返回true
+func (c *customFunctionCaller) AnyOf(calls ...interface{}) bool {
+	return c.evaluateConcurrently(calls, true)
+}
+
+// AllOf 并发调用绑定的bool函数，任一返回false时立即返回false
+func (c *customFunctionCaller) AllOf(calls ...interface{}) bool {
+	return c.evaluateConcurrently(calls, false)
+}
+
+// evaluateConcurrently 并发求值各分支，得到decisive结果时短路返回，否则返回!decisive
+//
+// 分支使用独立的错误记录和可取消的上下文，只有最终采用的错误记录到本次执行。
+func (c *customFunctionCaller) evaluateConcurrently(calls []interface{}, decisive bool) bool {
+	bound := make([]*boundCall, len(calls))
+	for i, call := range calls {
+		b, ok := call.(*boundCall)
+		if !ok {
+			err := fmt.Errorf("并行条件第 %d 个参数必须由 Func.Bind 创建，实际为 %T", i+1, call)
+			c.guard.fail(err)
+			panic(err)
+		}
+		bound[i] = b
+	}
+	if len(bound) == 0 {
+		return !decisive
+	}
+
+	ctx, cancel := context.WithCancel(c.ctx)
+	defer cancel()
+
+	done := make(chan branchOutcome, len(bound))
+	for i, b := range bound {
+		branch := &customFunctionCaller{ctx: ctx, guard: &functionGuard{}, functions: c.functions, timeouts: c.timeouts}
+		go branch.evaluateBranch(i, b, done)
+	}
+
+	errs := make([]error, len(bound))
+	for range bound {
+		outcome := <-done
+		if outcome.err == nil && outcome.value == decisive {
+			return decisive
+		}
+		errs[outcome.index] = outcome.err
+	}
+	for _, err := range errs {
+		if err != nil {
+			c.guard.fail(err)
+			panic(err)
+		}
+	}
+	return !decisive
+}
+
+// evaluateBranch 调用单个分支并发送结果，函数panic或返回非bool值时作为分支错误
+func (c *customFunctionCaller) evaluateBranch(index int, call *boundCall, done chan<- branchOutcome) {
+	defer func() {
+		if r := recover(); r != nil {
+			err, ok := r.(error)
+			if !ok {
+				err = fmt.Errorf("自定义函数 %s panic: %v", call.name, r)
+			}
+			done <- branchOutcome{index: index, err: err}
+		}
+	}()
+
+	result := c.Call(call.name, call.args...)
+	value, ok := result.(bool)
+	if !ok {
+		done <- branchOutcome{index: index, err: fmt.Errorf("并行条件中的自定义函数 %s 必须返回bool，实际为 %T", call.name, result)}
+		return
+	}
+	done <- branchOutcome{index: index, value: value}
+}
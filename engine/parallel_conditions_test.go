@@ -0,0 +1,143 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gitee.com/damengde/runehammer/rule"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// TestParallelConditions 测试并行条件求值
+func TestParallelConditions(t *testing.T) {
+	Convey("并行条件求值", t, func() {
+		engine := NewDynamicEngine[map[string]interface{}](DynamicEngineConfig{ParallelConditions: true})
+		defer engine.Close()
+		ctx := context.Background()
+
+		var cancelled atomic.Int32
+		So(engine.RegisterFunctions(map[string]interface{}{
+			"Yes": func() bool { return true },
+			"No":  func() bool { return false },
+			"Hang": func(ctx context.Context) bool {
+				<-ctx.Done()
+				cancelled.Add(1)
+				return false
+			},
+			"FailAfter": func(ms int) (bool, error) {
+				time.Sleep(time.Duration(ms) * time.Millisecond)
+				return false, fmt.Errorf("after %dms", ms)
+			},
+			"Number": func() int { return 1 },
+		}), ShouldBeNil)
+		So(engine.RegisterCustomFunctionWithTimeout("SlowWithTimeout", func(ctx context.Context) bool {
+			<-ctx.Done()
+			return false
+		}, time.Second), ShouldBeNil)
+
+		exec := func(when string) (map[string]interface{}, error) {
+			return engine.ExecuteRuleDefinition(ctx, rule.SimpleRule{When: when, Then: map[string]string{"Result.Hit": "true"}}, TestOrder{Amount: 1})
+		}
+
+		Convey("得到决定性结果后立即返回并取消其余分支", func() {
+			start := time.Now()
+			result, err := exec(`Func.AnyOf(Func.Bind("Hang"), Func.Bind("SlowWithTimeout"), Func.Bind("Yes"))`)
+			So(err, ShouldBeNil)
+			So(result["Hit"], ShouldEqual, true)
+			So(time.Since(start), ShouldBeLessThan, 500*time.Millisecond)
+
+			result, err = exec(`Func.AllOf(Func.Bind("Hang"), Func.Bind("No"))`)
+			So(err, ShouldBeNil)
+			So(result, ShouldNotContainKey, "Hit")
+			So(func() bool { time.Sleep(50 * time.Millisecond); return cancelled.Load() == 2 }(), ShouldBeTrue)
+		})
+
+		Convey("没有决定性结果时与顺序求值相同", func() {
+			result, err := exec(`Func.AllOf(Func.Bind("Yes"), Func.Bind("Yes"))`)
+			So(err, ShouldBeNil)
+			So(result["Hit"], ShouldEqual, true)
+
+			result, err = exec(`Func.AnyOf(Func.Bind("No"), Func.Bind("No")) || Func.AnyOf()`)
+			So(err, ShouldBeNil)
+			So(result, ShouldNotContainKey, "Hit")
+		})
+
+		Convey("决定性结果优先于其他分支的错误", func() {
+			result, err := exec(`Func.AnyOf(Func.Bind("FailAfter", 0), Func.Bind("Yes"))`)
+			So(err, ShouldBeNil)
+			So(result["Hit"], ShouldEqual, true)
+		})
+
+		Convey("没有决定性结果时报告声明顺序最靠前的错误", func() {
+			_, err := exec(`Func.AnyOf(Func.Bind("FailAfter", 30), Func.Bind("FailAfter", 0), Func.Bind("No"))`)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "after 30ms")
+		})
+
+		Convey("非bool返回值、未注册函数和非绑定参数报错", func() {
+			_, err := exec(`Func.AllOf(Func.Bind("Number"), Func.Bind("Yes"))`)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "必须返回bool")
+
+			_, err = exec(`Func.AllOf(Func.Bind("Missing"), Func.Bind("Yes"))`)
+			So(err, ShouldNotBeNil)
+
+			_, err = exec(`Func.AllOf(Func.Bind("Yes"), true)`)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "Func.Bind")
+		})
+
+		Convey("规则定义的复合函数条件转换为并行求值", func() {
+			definition := rule.StandardRule{
+				ID: "PARALLEL", Name: "并行条件", Enabled: true,
+				Conditions: rule.Condition{Type: rule.ConditionTypeComposite, Operator: rule.OpOr, Children: []rule.Condition{
+					{Type: rule.ConditionTypeFunction, Expression: `Func.Call("Hang")`},
+					{Type: rule.ConditionTypeFunction, Expression: `Func.Call("Yes")`},
+				}},
+				Actions: []rule.Action{{Type: rule.ActionTypeAssign, Target: "Result.Hit", Value: true}},
+			}
+			result, err := engine.ExecuteRuleDefinition(ctx, definition, TestOrder{Amount: 1})
+			So(err, ShouldBeNil)
+			So(result["Hit"], ShouldEqual, true)
+		})
+	})
+}
+
+// BenchmarkCompositeConditions 比较复合函数条件顺序求值与并行求值的耗时
+//
+// 每个函数耗时1ms，均不成立的或条件需要求值全部分支：顺序求值约为分支数×1ms，并行求值约为1ms。
+func BenchmarkCompositeConditions(b *testing.B) {
+	engine := NewDynamicEngine[map[string]interface{}](DynamicEngineConfig{EnableCache: true, CacheTTL: time.Hour, MaxCacheSize: 10})
+	defer engine.Close()
+	if err := engine.RegisterFunction("Check", func(id int) bool {
+		time.Sleep(time.Millisecond)
+		return false
+	}); err != nil {
+		b.Fatal(err)
+	}
+
+	const branches = 4
+	sequential, parallel := "", ""
+	for i := 0; i < branches; i++ {
+		if i > 0 {
+			sequential += " || "
+			parallel += ", "
+		}
+		sequential += fmt.Sprintf(`Func.Call("Check", %d)`, i)
+		parallel += fmt.Sprintf(`Func.Bind("Check", %d)`, i)
+	}
+
+	for name, when := range map[string]string{"sequential": sequential, "parallel": "Func.AnyOf(" + parallel + ")"} {
+		definition := rule.SimpleRule{When: when, Then: map[string]string{"Result.Hit": "true"}}
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := engine.ExecuteRuleDefinition(context.Background(), definition, TestOrder{Amount: 1}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
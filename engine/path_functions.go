@@ -0,0 +1,148 @@
+package engine
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+)
+
+// ============================================================================
+// 嵌套路径访问 - 以 customer.address.city、items[0].qty 形式访问深层字段
+// ============================================================================
+
+// injectPathFunctions 注入路径访问函数
+func injectPathFunctions(dataCtx ast.IDataContext) {
+	// 按路径取值，路径不存在或中途为nil时返回默认值
+	dataCtx.Add("Get", func(data interface{}, path string, defaultValue interface{}) interface{} {
+		if value, ok := lookupPath(data, path); ok && value != nil {
+			return value
+		}
+		return defaultValue
+	})
+
+	// 判断路径是否存在
+	dataCtx.Add("HasPath", func(data interface{}, path string) bool {
+		_, ok := lookupPath(data, path)
+		return ok
+	})
+}
+
+// lookupPath 按路径查找值 - 支持map、切片/数组下标和结构体字段
+//
+// 参数:
+//
+//	data - 根数据
+//	path - 字段路径，如 customer.address.city、items[0].qty
+//
+// 返回值:
+//
+//	any  - 找到的值
+//	bool - 路径是否存在
+func lookupPath(data any, path string) (any, bool) {
+	current := reflect.ValueOf(data)
+	for _, segment := range splitPath(path) {
+		for current.IsValid() && (current.Kind() == reflect.Interface || current.Kind() == reflect.Ptr) {
+			if current.IsNil() {
+				return nil, false
+			}
+			current = current.Elem()
+		}
+		if !current.IsValid() {
+			return nil, false
+		}
+
+		switch current.Kind() {
+		case reflect.Map:
+			if current.Type().Key().Kind() != reflect.String {
+				return nil, false
+			}
+			current = current.MapIndex(reflect.ValueOf(segment).Convert(current.Type().Key()))
+		case reflect.Slice, reflect.Array:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= current.Len() {
+				return nil, false
+			}
+			current = current.Index(index)
+		case reflect.Struct:
+			// 经由nil嵌入指针提升的字段视为不存在，FieldByName 在此情况下会panic
+			field, ok := current.Type().FieldByName(segment)
+			if !ok {
+				return nil, false
+			}
+			value, err := current.FieldByIndexErr(field.Index)
+			if err != nil {
+				return nil, false
+			}
+			current = value
+		default:
+			return nil, false
+		}
+
+		if !current.IsValid() {
+			return nil, false
+		}
+	}
+
+	if !current.IsValid() || !current.CanInterface() {
+		return nil, false
+	}
+	return current.Interface(), true
+}
+
+// splitPath 拆分路径，items[0].qty -> [items 0 qty]
+func splitPath(path string) []string {
+	path = strings.ReplaceAll(path, "[", ".")
+	path = strings.ReplaceAll(path, "]", "")
+
+	var segments []string
+	for _, segment := range strings.Split(path, ".") {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	return segments
+}
+
+// flattenInput 为map输入添加扁平化别名 - 返回副本，原有键保持不变
+//
+// 嵌套字段以完整路径为键追加到顶层，如 Params["customer.address.city"]、Params["items[0].qty"]。
+func flattenInput(input any) any {
+	data, ok := input.(map[string]any)
+	if !ok {
+		return input
+	}
+
+	result := make(map[string]any, len(data))
+	for key, value := range data {
+		result[key] = value
+	}
+	for key, value := range data {
+		collectFlattened(result, key, value)
+	}
+	return result
+}
+
+// collectFlattened 递归收集嵌套字段的扁平化路径
+func collectFlattened(result map[string]any, path string, value any) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			childPath := path + "." + key
+			if _, exists := result[childPath]; !exists {
+				result[childPath] = child
+			}
+			collectFlattened(result, childPath, child)
+		}
+	case []any:
+		for i, child := range v {
+			childPath := fmt.Sprintf("%s[%d]", path, i)
+			if _, exists := result[childPath]; !exists {
+				result[childPath] = child
+			}
+			collectFlattened(result, childPath, child)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package engine
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+// TestPathFunctions 测试嵌套路径访问
+func TestPathFunctions(t *testing.T) {
+	Convey("嵌套路径访问", t, func() {
+		type address struct {
+			City string
+		}
+		input := map[string]any{
+			"customer": map[string]any{
+				"address": map[string]any{"city": "Shanghai"},
+				"profile": &address{City: "Hangzhou"},
+				"empty":   nil,
+			},
+			"items": []any{map[string]any{"qty": 2}},
+		}
+
+		Convey("lookupPath支持map、下标和结构体字段", func() {
+			value, ok := lookupPath(input, "customer.address.city")
+			So(ok, ShouldBeTrue)
+			So(value, ShouldEqual, "Shanghai")
+
+			value, ok = lookupPath(input, "items[0].qty")
+			So(ok, ShouldBeTrue)
+			So(value, ShouldEqual, 2)
+
+			value, ok = lookupPath(input, "customer.profile.City")
+			So(ok, ShouldBeTrue)
+			So(value, ShouldEqual, "Hangzhou")
+
+			_, ok = lookupPath(input, "customer.missing.city")
+			So(ok, ShouldBeFalse)
+			_, ok = lookupPath(input, "customer.empty.city")
+			So(ok, ShouldBeFalse)
+			_, ok = lookupPath(input, "items[5].qty")
+			So(ok, ShouldBeFalse)
+		})
+
+		Convey("经由nil嵌入指针的字段视为不存在", func() {
+			type Contact struct {
+				Phone string
+			}
+			type member struct {
+				*Contact
+				Name string
+			}
+
+			_, ok := lookupPath(member{Name: "alice"}, "Phone")
+			So(ok, ShouldBeFalse)
+			value, ok := lookupPath(map[string]any{"member": &member{}}, "member.Phone")
+			So(ok, ShouldBeFalse)
+			So(value, ShouldBeNil)
+
+			value, ok = lookupPath(member{Contact: &Contact{Phone: "123"}}, "Phone")
+			So(ok, ShouldBeTrue)
+			So(value, ShouldEqual, "123")
+		})
+
+		Convey("Get和HasPath内置函数", func() {
+			dataCtx := ast.NewDataContext()
+			injectPathFunctions(dataCtx)
+
+			getValue, err := dataCtx.Get("Get").GetValue()
+			So(err, ShouldBeNil)
+			get := getValue.Interface().(func(interface{}, string, interface{}) interface{})
+			So(get(input, "customer.address.city", ""), ShouldEqual, "Shanghai")
+			So(get(input, "customer.address.zip", "000000"), ShouldEqual, "000000")
+			So(get(nil, "a.b", 0), ShouldEqual, 0)
+
+			hasValue, err := dataCtx.Get("HasPath").GetValue()
+			So(err, ShouldBeNil)
+			hasPath := hasValue.Interface().(func(interface{}, string) bool)
+			So(hasPath(input, "items[0]"), ShouldBeTrue)
+			So(hasPath(input, "items[1]"), ShouldBeFalse)
+		})
+
+		Convey("flattenInput追加扁平化别名且不修改原始输入", func() {
+			flattened := flattenInput(input).(map[string]any)
+			So(flattened["customer.address.city"], ShouldEqual, "Shanghai")
+			So(flattened["items[0].qty"], ShouldEqual, 2)
+			So(flattened["customer"], ShouldEqual, input["customer"])
+			So(input, ShouldNotContainKey, "customer.address.city")
+		})
+	})
+}
+
+// TestEngineFlattenInput 测试引擎执行时的扁平化别名
+func TestEngineFlattenInput(t *testing.T) {
+	Convey("引擎扁平化输入", t, func() {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		rules := []*rule.Rule{
+			{
+				ID:      1,
+				BizCode: "flatten_biz",
+				Name:    "城市检查",
+				GRL:     `rule CityRule "城市检查" { when Params["customer.address.city"] == "Shanghai" then Result["local"] = true; Retract("CityRule"); }`,
+				Enabled: true,
+			},
+		}
+
+		cfg := config.DefaultConfig()
+		cfg.FlattenInput = true
+		mapper := rule.NewMockRuleMapper(ctrl)
+		mapper.EXPECT().FindByBizCode(gomock.Any(), "flatten_biz").Return(rules, nil).AnyTimes()
+
+		engine := NewEngineImpl[map[string]any](
+			cfg, mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+			ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+		)
+		defer engine.Close()
+
+		result, err := engine.Exec(context.Background(), "flatten_biz", map[string]any{
+			"customer": map[string]any{"address": map[string]any{"city": "Shanghai"}},
+		})
+		So(err, ShouldBeNil)
+		So(result["local"], ShouldEqual, true)
+	})
+}
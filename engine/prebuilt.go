@@ -0,0 +1,80 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gitee.com/damengde/runehammer/config"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+)
+
+// ============================================================================
+// 预编译知识库 - 复用在其他地方编译好的Grule知识库，由引擎负责执行、缓存和结果提取
+// ============================================================================
+
+// ErrKnowledgeBaseNotFound 知识库中不存在指定名称和版本的知识库
+var ErrKnowledgeBaseNotFound = errors.New("知识库不存在")
+
+// ErrPrebuiltFirstMatch 预编译知识库没有规则顺序，不支持首条命中模式
+var ErrPrebuiltFirstMatch = errors.New("预编译知识库不支持首条命中模式")
+
+// RegisterPrebuiltKnowledgeBase 将知识库中已编译的知识库登记到业务码
+//
+// 登记后该业务码的执行不再从映射器获取规则，直接使用知识库 name:version 的实例；
+// 输入注入、幂等、降级、结果提取等与普通业务码相同。
+// 知识库在登记时实例化，之后在知识库中重新编译时需再次登记才会生效。
+//
+// 预编译知识库没有对应的规则元数据，规则灰度、选择器等依赖规则元数据的功能不生效，
+// 规则集版本为0，固定版本执行返回 ErrVersionNotFound。首条命中模式需要规则顺序，
+// 业务码配置为首条命中时登记和执行均返回 ErrPrebuiltFirstMatch。
+//
+// 参数:
+//
+//	bizCode - 业务码
+//	name    - 知识库名称
+//	version - 知识库版本
+//
+// 返回值:
+//
+//	error - 知识库不存在时返回 ErrKnowledgeBaseNotFound
+func (e *engineImpl[T]) RegisterPrebuiltKnowledgeBase(bizCode, name, version string) error {
+	if strings.TrimSpace(bizCode) == "" {
+		return fmt.Errorf("无效的业务码")
+	}
+	if err := e.checkPrebuiltExecMode(bizCode); err != nil {
+		return err
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if _, ok := e.knowledgeLibrary.Library[fmt.Sprintf("%s:%s", name, version)]; !ok {
+		return fmt.Errorf("%s:%s: %w", name, version, ErrKnowledgeBaseNotFound)
+	}
+	kb, err := e.knowledgeLibrary.NewKnowledgeBaseInstance(name, version)
+	if err != nil {
+		return fmt.Errorf("创建知识库实例失败: %w", err)
+	}
+
+	e.prebuilt.Store(bizCode, kb)
+	e.knowledgeBases.Delete(bizCode)
+	return nil
+}
+
+// prebuiltKnowledgeBase 获取业务码登记的预编译知识库
+func (e *engineImpl[T]) prebuiltKnowledgeBase(bizCode string) (*ast.KnowledgeBase, bool) {
+	kb, ok := e.prebuilt.Load(bizCode)
+	if !ok {
+		return nil, false
+	}
+	return kb.(*ast.KnowledgeBase), true
+}
+
+// checkPrebuiltExecMode 检查业务码的执行模式是否适用于预编译知识库
+func (e *engineImpl[T]) checkPrebuiltExecMode(bizCode string) error {
+	if e.ExecMode(bizCode) == config.ExecModeFirstMatch {
+		return fmt.Errorf("业务码 %s: %w", bizCode, ErrPrebuiltFirstMatch)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/hyperjumptech/grule-rule-engine/builder"
+	"github.com/hyperjumptech/grule-rule-engine/pkg"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+// TestPrebuiltKnowledgeBase 测试执行预编译知识库
+func TestPrebuiltKnowledgeBase(t *testing.T) {
+	Convey("预编译知识库", t, func() {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		lib := ast.NewKnowledgeLibrary()
+		grl := `rule Legacy "存量规则" { when Params["amount"] > 100 then Result["review"] = true; Retract("Legacy"); }`
+		So(builder.NewRuleBuilder(lib).BuildRuleFromResource("Legacy", "2.0.0", pkg.NewBytesResource([]byte(grl))), ShouldBeNil)
+
+		// 映射器没有任何预期，登记的业务码不会从映射器获取规则
+		mapper := rule.NewMockRuleMapper(ctrl)
+		engine := NewEngineImpl[map[string]any](nil, mapper, nil, cache.CacheKeyBuilder{}, nil, lib, nil, nil, false)
+		defer engine.Close()
+
+		Convey("登记后按业务码执行", func() {
+			So(engine.RegisterPrebuiltKnowledgeBase("LEGACY_RISK", "Legacy", "2.0.0"), ShouldBeNil)
+
+			var report ExecReport
+			result, err := engine.Exec(context.Background(), "LEGACY_RISK", map[string]any{"amount": 500}, WithExecReport(&report))
+			So(err, ShouldBeNil)
+			So(result["review"], ShouldEqual, true)
+			So(report.RuleSetVersion, ShouldEqual, 0)
+
+			result, err = engine.Exec(context.Background(), "LEGACY_RISK", map[string]any{"amount": 50})
+			So(err, ShouldBeNil)
+			So(result, ShouldNotContainKey, "review")
+		})
+
+		Convey("首条命中模式不适用于预编译知识库", func() {
+			cfg := config.DefaultConfig()
+			cfg.BizExecModes = map[string]config.ExecMode{"FIRST": config.ExecModeFirstMatch}
+			firstEngine := NewEngineImpl[map[string]any](cfg, mapper, nil, cache.CacheKeyBuilder{}, nil, lib, nil, nil, false)
+			defer firstEngine.Close()
+
+			err := firstEngine.RegisterPrebuiltKnowledgeBase("FIRST", "Legacy", "2.0.0")
+			So(errors.Is(err, ErrPrebuiltFirstMatch), ShouldBeTrue)
+
+			// 登记后改为首条命中时执行返回错误
+			So(firstEngine.RegisterPrebuiltKnowledgeBase("LATER", "Legacy", "2.0.0"), ShouldBeNil)
+			cfg.BizExecModes["LATER"] = config.ExecModeFirstMatch
+			_, err = firstEngine.Exec(context.Background(), "LATER", map[string]any{"amount": 500})
+			So(errors.Is(err, ErrPrebuiltFirstMatch), ShouldBeTrue)
+		})
+
+		Convey("知识库不存在时登记失败", func() {
+			err := engine.RegisterPrebuiltKnowledgeBase("LEGACY_RISK", "Legacy", "9.9.9")
+			So(errors.Is(err, ErrKnowledgeBaseNotFound), ShouldBeTrue)
+			So(engine.RegisterPrebuiltKnowledgeBase(" ", "Legacy", "2.0.0"), ShouldNotBeNil)
+		})
+	})
+}
@@ -0,0 +1,124 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+)
+
+// ============================================================================
+// 配额与计量 - 按租户检查执行配额，执行结束后上报用量，用于SaaS平台按客户计费
+// ============================================================================
+//
+// 租户由执行选项 WithTenant 指定，未指定时为空。配额检查在参数验证之后、获取规则之前进行，
+// 被拒绝的执行不上报用量；通过检查的执行无论成功与否都会上报一次用量。
+
+// ErrQuotaExceeded 租户超出执行配额，可通过errors.Is判断
+var ErrQuotaExceeded = errors.New("超出执行配额")
+
+// QuotaProvider 配额提供者 - 执行前按 (租户, 业务码) 检查配额
+type QuotaProvider interface {
+	// CheckQuota 返回错误时拒绝执行，超出配额应返回ErrQuotaExceeded或包装它的错误
+	CheckQuota(ctx context.Context, tenant, bizCode string) error
+}
+
+// QuotaFunc 函数形式的配额提供者
+type QuotaFunc func(ctx context.Context, tenant, bizCode string) error
+
+// CheckQuota 实现QuotaProvider
+func (f QuotaFunc) CheckQuota(ctx context.Context, tenant, bizCode string) error {
+	return f(ctx, tenant, bizCode)
+}
+
+// Usage 单次执行的用量
+type Usage struct {
+	Tenant         string        // 租户
+	BizCode        string        // 业务码
+	RuleSetVersion int           // 执行的规则集版本，获取规则失败时为0
+	Evaluations    int           // 规则条件求值次数
+	Fired          int           // 规则动作执行次数
+	Duration       time.Duration // 执行耗时
+	Err            error         // 执行错误，成功或返回降级结果时为nil
+}
+
+// UsageReporter 用量上报 - 在执行协程中同步调用，实现应避免阻塞
+type UsageReporter interface {
+	ReportUsage(ctx context.Context, usage Usage)
+}
+
+// UsageReportFunc 函数形式的用量上报
+type UsageReportFunc func(ctx context.Context, usage Usage)
+
+// ReportUsage 实现UsageReporter
+func (f UsageReportFunc) ReportUsage(ctx context.Context, usage Usage) {
+	f(ctx, usage)
+}
+
+// WithTenant 设置执行所属的租户 - 用于配额检查和用量上报
+func WithTenant(tenant string) ExecOption {
+	return func(o *ExecOptions) {
+		o.Tenant = tenant
+	}
+}
+
+// SetQuotaProvider 设置配额提供者，为nil时不检查配额
+func (e *engineImpl[T]) SetQuotaProvider(provider QuotaProvider) {
+	e.quotaProvider = provider
+}
+
+// SetUsageReporter 设置用量上报，为nil时不统计用量
+func (e *engineImpl[T]) SetUsageReporter(reporter UsageReporter) {
+	e.usageReporter = reporter
+}
+
+// checkQuota 执行前检查配额
+func (e *engineImpl[T]) checkQuota(ctx context.Context, tenant, bizCode string) error {
+	if e.quotaProvider == nil {
+		return nil
+	}
+	err := e.quotaProvider.CheckQuota(ctx, tenant, bizCode)
+	if err == nil {
+		return nil
+	}
+	if e.logger != nil {
+		e.logger.Warnf(ctx, "执行被配额拒绝", "tenant", tenant, "bizCode", bizCode, "error", err)
+	}
+	if errors.Is(err, ErrQuotaExceeded) {
+		return err
+	}
+	return fmt.Errorf("配额检查失败: %w", err)
+}
+
+// reportUsage 执行结束后上报用量
+func (e *engineImpl[T]) reportUsage(ctx context.Context, meter *usageMeter, err error) {
+	meter.usage.Duration = time.Since(meter.start)
+	meter.usage.Err = err
+	e.usageReporter.ReportUsage(ctx, meter.usage)
+}
+
+// usageMeter 用量计量 - 作为执行监听器统计规则求值和动作执行次数
+type usageMeter struct {
+	start time.Time
+	usage Usage
+}
+
+// newUsageMeter 创建用量计量
+func newUsageMeter(tenant, bizCode string) *usageMeter {
+	return &usageMeter{start: time.Now(), usage: Usage{Tenant: tenant, BizCode: bizCode}}
+}
+
+// EvaluateRuleEntry 实现GruleEngineListener
+func (m *usageMeter) EvaluateRuleEntry(cycle uint64, entry *ast.RuleEntry, candidate bool) {
+	m.usage.Evaluations++
+}
+
+// ExecuteRuleEntry 实现GruleEngineListener
+func (m *usageMeter) ExecuteRuleEntry(cycle uint64, entry *ast.RuleEntry) {
+	m.usage.Fired++
+}
+
+// BeginCycle 实现GruleEngineListener
+func (m *usageMeter) BeginCycle(cycle uint64) {}
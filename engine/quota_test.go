@@ -0,0 +1,92 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+// TestQuota 测试配额检查与用量上报
+func TestQuota(t *testing.T) {
+	Convey("配额检查与用量上报", t, func() {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mapper := rule.NewMockRuleMapper(ctrl)
+		engine := NewEngineImpl[map[string]any](
+			config.DefaultConfig(), mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+			ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+		)
+		defer engine.Close()
+
+		var checked []string
+		engine.SetQuotaProvider(QuotaFunc(func(ctx context.Context, tenant, bizCode string) error {
+			checked = append(checked, tenant+"/"+bizCode)
+			switch tenant {
+			case "free":
+				return ErrQuotaExceeded
+			case "broken":
+				return errors.New("quota backend unavailable")
+			}
+			return nil
+		}))
+		var usages []Usage
+		engine.SetUsageReporter(UsageReportFunc(func(ctx context.Context, usage Usage) {
+			usages = append(usages, usage)
+		}))
+
+		Convey("通过检查的执行上报用量", func() {
+			mapper.EXPECT().FindByBizCode(gomock.Any(), "score").Return([]*rule.Rule{
+				{Name: "a", Enabled: true, Version: 2, GRL: `rule A "A" salience 20 { when Params["amount"] > 100 then Result["large"] = true; Retract("A"); }`},
+				{Name: "b", Enabled: true, Version: 2, GRL: `rule B "B" salience 10 { when Params["amount"] > 1000 then Result["huge"] = true; Retract("B"); }`},
+			}, nil)
+
+			result, err := engine.Exec(context.Background(), "score", map[string]any{"amount": 500}, WithTenant("acme"))
+			So(err, ShouldBeNil)
+			So(result["large"], ShouldEqual, true)
+			So(checked, ShouldResemble, []string{"acme/score"})
+			So(usages, ShouldHaveLength, 1)
+			So(usages[0].Tenant, ShouldEqual, "acme")
+			So(usages[0].BizCode, ShouldEqual, "score")
+			So(usages[0].RuleSetVersion, ShouldEqual, 2)
+			So(usages[0].Evaluations, ShouldBeGreaterThanOrEqualTo, 2)
+			So(usages[0].Fired, ShouldEqual, 1)
+			So(usages[0].Duration, ShouldBeGreaterThan, 0)
+			So(usages[0].Err, ShouldBeNil)
+		})
+
+		Convey("超出配额时拒绝执行且不上报用量", func() {
+			_, err := engine.Exec(context.Background(), "score", map[string]any{"amount": 500}, WithTenant("free"))
+			So(errors.Is(err, ErrQuotaExceeded), ShouldBeTrue)
+			So(usages, ShouldBeEmpty)
+		})
+
+		Convey("配额检查失败时拒绝执行", func() {
+			_, err := engine.Exec(context.Background(), "score", map[string]any{"amount": 500}, WithTenant("broken"))
+			So(err, ShouldNotBeNil)
+			So(errors.Is(err, ErrQuotaExceeded), ShouldBeFalse)
+			So(err.Error(), ShouldContainSubstring, "配额检查失败")
+		})
+
+		Convey("执行失败时上报错误", func() {
+			mapper.EXPECT().FindByBizCode(gomock.Any(), "missing").Return(nil, errors.New("db down"))
+
+			_, err := engine.Exec(context.Background(), "missing", map[string]any{"amount": 1})
+			So(err, ShouldNotBeNil)
+			So(checked, ShouldResemble, []string{"/missing"})
+			So(usages, ShouldHaveLength, 1)
+			So(usages[0].Err, ShouldEqual, err)
+			So(usages[0].Evaluations, ShouldEqual, 0)
+		})
+	})
+}
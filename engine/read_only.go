@@ -0,0 +1,29 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ============================================================================
+// 只读模式 - 嵌入不受信任或影响面大的服务时，禁止实例上的全部写操作
+// ============================================================================
+//
+// 只读实例只获取、编译和执行规则：规则复制（CloneBizCode）和数据清理（RunRetention、定时清理）
+// 返回 ErrReadOnly，配置校验拒绝同时启用自动迁移或数据清理。演练类操作（CloneBizCodeDryRun）不写入数据，仍可使用。
+
+// ErrReadOnly 引擎为只读模式，拒绝写操作
+var ErrReadOnly = errors.New("引擎为只读模式，禁止写操作")
+
+// ReadOnly 引擎是否为只读模式
+func (e *engineImpl[T]) ReadOnly() bool {
+	return e.config != nil && e.config.ReadOnly
+}
+
+// checkWritable 只读模式下拒绝写操作
+func (e *engineImpl[T]) checkWritable(operation string) error {
+	if e.ReadOnly() {
+		return fmt.Errorf("%s: %w", operation, ErrReadOnly)
+	}
+	return nil
+}
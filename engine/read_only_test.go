@@ -0,0 +1,62 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+// TestReadOnly 测试只读模式
+func TestReadOnly(t *testing.T) {
+	Convey("只读模式", t, func() {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mapper := rule.NewMockRuleMapper(ctrl)
+		mapper.EXPECT().FindByBizCode(gomock.Any(), "quote").Return([]*rule.Rule{
+			{Name: "price", Enabled: true, GRL: `rule Price "报价" { when true then Result["price"] = 10; Retract("Price"); }`},
+		}, nil).AnyTimes()
+
+		cfg := config.DefaultConfig()
+		cfg.ReadOnly = true
+		cfg.RuleVersionRetention = 3
+		engine := NewEngineImpl[map[string]any](
+			cfg, mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+			ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+		)
+		defer engine.Close()
+
+		Convey("规则执行不受影响", func() {
+			So(engine.ReadOnly(), ShouldBeTrue)
+			result, err := engine.Exec(context.Background(), "quote", map[string]any{})
+			So(err, ShouldBeNil)
+			So(result["price"], ShouldEqual, 10)
+		})
+
+		Convey("规则复制和数据清理返回ErrReadOnly", func() {
+			_, err := engine.CloneBizCode(context.Background(), "", "acme", "quote")
+			So(errors.Is(err, ErrReadOnly), ShouldBeTrue)
+
+			_, err = engine.RunRetention(context.Background())
+			So(errors.Is(err, ErrReadOnly), ShouldBeTrue)
+
+			So(engine.StartRetention(), ShouldBeNil)
+			So(engine.scheduler().Entries(), ShouldBeEmpty)
+		})
+
+		Convey("演练复制不写入数据，不受只读限制", func() {
+			_, err := engine.CloneBizCodeDryRun(context.Background(), "", "acme", "quote")
+			So(errors.Is(err, ErrReadOnly), ShouldBeFalse)
+		})
+	})
+}
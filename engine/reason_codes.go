@@ -0,0 +1,81 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+)
+
+// ============================================================================
+// 原因码 - 规则通过 AddReason 记录决策原因，引擎按影响排序后汇总到结果
+// ============================================================================
+//
+// 拒绝通知（adverse action）等场景需要各规则集以一致的结构给出原因：
+// 规则动作调用 AddReason(code, weight)，同一原因码多次记录时权重累加，
+// 执行结束后按权重绝对值从大到小排序（相同时保持首次记录顺序）写入 Result["reasons"]。
+// map结果得到 []ReasonCode，结构体结果通过 json:"reasons" 字段接收。未记录原因时不写入。
+
+// ReasonsKey 结果中原因码列表的键
+const ReasonsKey = "reasons"
+
+// ReasonCode 决策原因
+type ReasonCode struct {
+	Code   string  `json:"code"`           // 原因码
+	Weight float64 `json:"weight"`         // 影响权重，同一原因码多次记录时累加
+	Rule   string  `json:"rule,omitempty"` // 首次记录该原因的规则
+}
+
+// reasonCollector 本次执行记录的原因码
+type reasonCollector struct {
+	reasons []ReasonCode
+	index   map[string]int
+}
+
+// add 记录原因，同一原因码累加权重
+func (c *reasonCollector) add(code string, weight float64, rule string) {
+	if i, ok := c.index[code]; ok {
+		c.reasons[i].Weight += weight
+		return
+	}
+	if c.index == nil {
+		c.index = make(map[string]int)
+	}
+	c.index[code] = len(c.reasons)
+	c.reasons = append(c.reasons, ReasonCode{Code: code, Weight: weight, Rule: rule})
+}
+
+// sorted 按影响排序的原因码副本
+func (c *reasonCollector) sorted() []ReasonCode {
+	reasons := append([]ReasonCode{}, c.reasons...)
+	sort.SliceStable(reasons, func(i, j int) bool {
+		return math.Abs(reasons[i].Weight) > math.Abs(reasons[j].Weight)
+	})
+	return reasons
+}
+
+// AddReason 记录决策原因，weight 为影响权重（如扣分），原因码为空或权重不是数值时执行返回错误
+func (f *ruleFunctions) AddReason(code any, weight any) {
+	name := stringArg(code)
+	if name == "" {
+		panic(fmt.Errorf("AddReason: 原因码不能为空"))
+	}
+	value, ok := toFloat(weight)
+	if !ok {
+		panic(fmt.Errorf("AddReason: 原因 %s 的权重必须是数值，实际为 %T", name, weight))
+	}
+	f.reasons.add(name, value, f.rule)
+}
+
+// attachReasons 将按影响排序的原因码写入Result，未记录原因时不写入
+func attachReasons(dataCtx ast.IDataContext, functions *ruleFunctions) []ReasonCode {
+	if len(functions.reasons.reasons) == 0 {
+		return nil
+	}
+	reasons := functions.reasons.sorted()
+	if result, ok := resultMap(dataCtx); ok {
+		result[ReasonsKey] = reasons
+	}
+	return reasons
+}
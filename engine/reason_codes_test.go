@@ -0,0 +1,103 @@
+package engine
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+// TestReasonCodes 测试原因码累积与提取
+func TestReasonCodes(t *testing.T) {
+	Convey("原因码", t, func() {
+		Convey("同一原因码累加权重，按影响排序", func() {
+			var c reasonCollector
+			c.add("LOW_INCOME", 10, "Income")
+			c.add("LATE_PAYMENT", -25, "History")
+			c.add("SHORT_HISTORY", 10, "History")
+			c.add("LOW_INCOME", 5, "Income2")
+
+			So(c.sorted(), ShouldResemble, []ReasonCode{
+				{Code: "LATE_PAYMENT", Weight: -25, Rule: "History"},
+				{Code: "LOW_INCOME", Weight: 15, Rule: "Income"},
+				{Code: "SHORT_HISTORY", Weight: 10, Rule: "History"},
+			})
+		})
+
+		Convey("参数无效时报错", func() {
+			f := &ruleFunctions{}
+			So(func() { f.AddReason("", 1) }, ShouldPanic)
+			So(func() { f.AddReason("X", "heavy") }, ShouldPanic)
+		})
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mapper := rule.NewMockRuleMapper(ctrl)
+		mapper.EXPECT().FindByBizCode(gomock.Any(), "loan").Return([]*rule.Rule{
+			{Name: "income", Enabled: true, GRL: `rule Income "收入" salience 20 {
+	when Params["income"] < 3000
+	then
+		AddReason("LOW_INCOME", 20);
+		Retract("Income");
+}`},
+			{Name: "history", Enabled: true, GRL: `rule History "历史" salience 10 {
+	when Params["late"] > 0
+	then
+		AddReason("LATE_PAYMENT", Params["late"] * 15);
+		Result["approved"] = false;
+		Retract("History");
+}`},
+		}, nil).AnyTimes()
+
+		newEngine := func() *engineImpl[map[string]any] {
+			return NewEngineImpl[map[string]any](
+				config.DefaultConfig(), mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+				ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+			)
+		}
+
+		Convey("map结果", func() {
+			engine := newEngine()
+			defer engine.Close()
+
+			result, err := engine.Exec(context.Background(), "loan", map[string]any{"income": 2000, "late": 2})
+			So(err, ShouldBeNil)
+			So(result[ReasonsKey], ShouldResemble, []ReasonCode{
+				{Code: "LATE_PAYMENT", Weight: 30, Rule: "History"},
+				{Code: "LOW_INCOME", Weight: 20, Rule: "Income"},
+			})
+
+			result, err = engine.Exec(context.Background(), "loan", map[string]any{"income": 5000, "late": 0})
+			So(err, ShouldBeNil)
+			So(result, ShouldNotContainKey, ReasonsKey)
+		})
+
+		Convey("结构体结果", func() {
+			type decision struct {
+				Approved bool         `json:"approved"`
+				Reasons  []ReasonCode `json:"reasons"`
+			}
+			engine := NewEngineImpl[decision](
+				config.DefaultConfig(), mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+				ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+			)
+			defer engine.Close()
+
+			result, err := engine.Exec(context.Background(), "loan", map[string]any{"income": 2000, "late": 1})
+			So(err, ShouldBeNil)
+			So(result.Approved, ShouldBeFalse)
+			So(result.Reasons, ShouldHaveLength, 2)
+			So(result.Reasons[0].Code, ShouldEqual, "LOW_INCOME")
+			So(result.Reasons[1], ShouldResemble, ReasonCode{Code: "LATE_PAYMENT", Weight: 15, Rule: "History"})
+		})
+	})
+}
@@ -0,0 +1,69 @@
+package engine
+
+import (
+	"fmt"
+
+	"gitee.com/damengde/runehammer/rule"
+)
+
+// ============================================================================
+// 枚举与参考数据 - 规则条件以符号名引用登记的取值，编译时解析并校验
+// ============================================================================
+//
+// 登记后规则中的 OrderStatus.PAID、Countries.CN.risk 在编译时替换为字面量，引用不存在的成员时编译失败。
+// 登记或替换枚举、参考数据表会清理已编译的知识库，之后的执行按新的登记重新编译。
+
+// RegisterEnum 登记枚举 - 规则以 name.成员 引用，成员名即其取值，重复登记时替换
+//
+// 使用示例:
+//
+//	engine.RegisterEnum("OrderStatus", "PENDING", "PAID", "REFUNDED")
+//	// 规则中: when Params.status == OrderStatus.PAID then ...
+func (e *engineImpl[T]) RegisterEnum(name string, values ...string) error {
+	if err := e.references.AddEnum(name, values...); err != nil {
+		return err
+	}
+	e.clearExpiredKnowledgeBases()
+	return nil
+}
+
+// RegisterRefTable 登记参考数据表 - 规则以 name.行 引用行键，以 name.行.列 引用单元格的值，重复登记时替换
+//
+// 使用示例:
+//
+//	engine.RegisterRefTable("Countries", map[string]map[string]any{
+//	    "CN": {"currency": "CNY", "risk": 1},
+//	    "US": {"currency": "USD", "risk": 2},
+//	})
+//	// 规则中: when Params.country == Countries.CN && Params.risk <= Countries.CN.risk then ...
+func (e *engineImpl[T]) RegisterRefTable(name string, rows map[string]map[string]any) error {
+	if err := e.references.AddTable(name, rows); err != nil {
+		return err
+	}
+	e.clearExpiredKnowledgeBases()
+	return nil
+}
+
+// resolveReferences 解析规则中对枚举和参考数据表的引用，引用了的规则替换为解析后的副本
+func (e *engineImpl[T]) resolveReferences(rules []*rule.Rule) ([]*rule.Rule, error) {
+	if e.references == nil || e.references.Empty() {
+		return rules, nil
+	}
+	resolved := make([]*rule.Rule, len(rules))
+	for i, r := range rules {
+		resolved[i] = r
+		if r == nil || !r.Enabled {
+			continue
+		}
+		grl, err := e.references.Resolve(r.GRL)
+		if err != nil {
+			return nil, fmt.Errorf("规则 %s: %w", r.Name, err)
+		}
+		if grl != r.GRL {
+			copied := *r
+			copied.GRL = grl
+			resolved[i] = &copied
+		}
+	}
+	return resolved, nil
+}
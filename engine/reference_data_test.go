@@ -0,0 +1,83 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+// TestReferenceData 测试规则引用枚举与参考数据
+func TestReferenceData(t *testing.T) {
+	Convey("枚举与参考数据", t, func() {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		ctx := context.Background()
+
+		current := []*rule.Rule{{BizCode: "orders", Name: "Paid", Enabled: true, Version: 1,
+			GRL: `rule Paid "已支付" { when Params["status"] == OrderStatus.PAID && Params["amount"] <= Countries.CN.limit then Result["currency"] = Countries.CN.currency; Retract("Paid"); }`}}
+		mapper := rule.NewMockRuleMapper(ctrl)
+		mapper.EXPECT().FindByBizCode(gomock.Any(), "orders").DoAndReturn(func(context.Context, string) ([]*rule.Rule, error) {
+			return current, nil
+		}).AnyTimes()
+
+		engine := NewEngineImpl[map[string]any](
+			config.DefaultConfig(), mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+			ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+		)
+		defer engine.Close()
+
+		So(engine.RegisterEnum("OrderStatus", "PENDING", "PAID"), ShouldBeNil)
+		So(engine.RegisterRefTable("Countries", map[string]map[string]any{
+			"CN": {"currency": "CNY", "limit": 5000},
+		}), ShouldBeNil)
+		input := map[string]any{"status": "PAID", "amount": 3000}
+
+		Convey("符号引用在编译时解析", func() {
+			result, err := engine.Exec(ctx, "orders", input)
+			So(err, ShouldBeNil)
+			So(result["currency"], ShouldEqual, "CNY")
+		})
+
+		Convey("重新登记后按新的取值重新编译", func() {
+			_, err := engine.Exec(ctx, "orders", input)
+			So(err, ShouldBeNil)
+
+			So(engine.RegisterRefTable("Countries", map[string]map[string]any{
+				"CN": {"currency": "CNY", "limit": 1000},
+			}), ShouldBeNil)
+			result, err := engine.Exec(ctx, "orders", input)
+			So(err, ShouldBeNil)
+			So(result, ShouldNotContainKey, "currency")
+		})
+
+		Convey("引用不存在的成员时编译失败", func() {
+			current = []*rule.Rule{{BizCode: "orders", Name: "Typo", Enabled: true, Version: 2,
+				GRL: `rule Typo "拼写错误" { when Params["status"] == OrderStatus.PAYED then Result["hit"] = true; Retract("Typo"); }`}}
+			_, err := engine.Exec(ctx, "orders", input)
+			So(errors.Is(err, ErrCompileFailed), ShouldBeTrue)
+			So(err.Error(), ShouldContainSubstring, "OrderStatus 没有成员 PAYED")
+
+			err = engine.AddOverride(ctx, "orders", &rule.Rule{Name: "Typo",
+				GRL: `rule Typo "拼写错误" { when Params["status"] == OrderStatus.PAYED then Result["hit"] = true; Retract("Typo"); }`}, 0)
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("规则测试用例同样解析引用", func() {
+			report, err := engine.runRuleTests(ctx, "orders", current, []*rule.RuleTestCase{
+				{Name: "已支付", Input: input, Expected: map[string]any{"currency": "CNY"}},
+			})
+			So(err, ShouldBeNil)
+			So(report.Passed, ShouldEqual, 1)
+		})
+	})
+}
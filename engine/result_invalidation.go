@@ -0,0 +1,139 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gitee.com/damengde/runehammer/cache"
+)
+
+// ============================================================================
+// 幂等结果失效 - 上游数据变更时清除已存储的幂等结果，避免在窗口期内返回过时的决策
+// ============================================================================
+//
+// 幂等结果按 "业务码:规则集版本:幂等键" 存储，Invalidate 按业务码和幂等键前缀删除所有版本的结果。
+// 幂等键通常包含客户等业务主键（如 "cust-42:order-7"），客户资料变更时以 "cust-42:" 为前缀失效。
+// 设置了消息通道时失效操作广播给其他实例，保证各实例的内存缓存一致。
+
+// ErrInvalidationNotSupported 缓存不支持按前缀删除（未实现 cache.PrefixDeleter），可通过errors.Is判断
+var ErrInvalidationNotSupported = errors.New("缓存不支持按前缀失效幂等结果")
+
+// invalidationChannel 幂等结果失效的广播频道
+const invalidationChannel = "runehammer:invalidate"
+
+// InvalidateFunc 失效幂等结果的函数，由引擎传给 InvalidationSource
+type InvalidateFunc func(ctx context.Context, bizCode, inputKeyPrefix string) error
+
+// InvalidationSource 上游数据变更来源 - 应用实现该接口把数据变更（如CDC消息、领域事件）转换为失效操作
+type InvalidationSource interface {
+	// Watch 开始监听数据变更，数据变更时调用invalidate，返回停止监听的函数；引擎关闭时调用stop
+	Watch(ctx context.Context, invalidate InvalidateFunc) (stop func(), err error)
+}
+
+// invalidationEvent 幂等结果失效消息
+type invalidationEvent struct {
+	BizCode string `json:"biz_code"`
+	Prefix  string `json:"prefix"`
+}
+
+// Invalidate 失效业务码下幂等键以inputKeyPrefix开头的幂等结果，inputKeyPrefix为空时失效业务码的全部结果
+//
+// 参数:
+//
+//	ctx            - 上下文
+//	bizCode        - 业务码，只匹配该业务码本身，不包含子业务码
+//	inputKeyPrefix - 幂等键前缀
+//
+// 返回值:
+//
+//	error - 业务码为空、缓存不支持按前缀删除，或已在本实例失效但广播失败
+func (e *engineImpl[T]) Invalidate(ctx context.Context, bizCode, inputKeyPrefix string) error {
+	if bizCode == "" {
+		return fmt.Errorf("业务码不能为空")
+	}
+	if _, err := e.evictIdempotentResults(ctx, bizCode, inputKeyPrefix); err != nil {
+		return err
+	}
+
+	if err := e.publish(ctx, invalidationChannel, invalidationEvent{BizCode: bizCode, Prefix: inputKeyPrefix}); err != nil {
+		if e.logger != nil {
+			e.logger.Warnf(ctx, "广播幂等结果失效失败", "bizCode", bizCode, "prefix", inputKeyPrefix, "error", err)
+		}
+		return fmt.Errorf("幂等结果已在本实例失效，广播到其他实例失败: %w", err)
+	}
+	return nil
+}
+
+// evictIdempotentResults 删除本实例缓存中匹配的幂等结果，未配置缓存时没有可失效的结果
+func (e *engineImpl[T]) evictIdempotentResults(ctx context.Context, bizCode, inputKeyPrefix string) (int, error) {
+	if e.cache == nil {
+		return 0, nil
+	}
+	deleter, ok := e.cache.(cache.PrefixDeleter)
+	if !ok {
+		return 0, fmt.Errorf("缓存类型 %T: %w", e.cache, ErrInvalidationNotSupported)
+	}
+
+	prefix := e.cacheKeys.IdempotencyPrefix(bizCode)
+	deleted, err := deleter.DelPrefix(ctx, prefix, func(key string) bool {
+		// 键的其余部分为 "规则集哈希:幂等键"
+		_, idempotencyKey, ok := strings.Cut(strings.TrimPrefix(key, prefix), ":")
+		return ok && strings.HasPrefix(idempotencyKey, inputKeyPrefix)
+	})
+	if err != nil {
+		return deleted, fmt.Errorf("失效幂等结果失败: %w", err)
+	}
+	if e.logger != nil {
+		e.logger.Debugf(ctx, "幂等结果已失效", "bizCode", bizCode, "prefix", inputKeyPrefix, "count", deleted)
+	}
+	return deleted, nil
+}
+
+// handleInvalidationEvent 处理其他实例的幂等结果失效
+func (e *engineImpl[T]) handleInvalidationEvent(payload []byte) {
+	ctx := context.Background()
+
+	var event invalidationEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		if e.logger != nil {
+			e.logger.Warnf(ctx, "解析幂等结果失效消息失败", "error", err)
+		}
+		return
+	}
+	if event.BizCode == "" {
+		return
+	}
+	if _, err := e.evictIdempotentResults(ctx, event.BizCode, event.Prefix); err != nil && e.logger != nil {
+		e.logger.Warnf(ctx, "处理其他实例的幂等结果失效失败", "bizCode", event.BizCode, "error", err)
+	}
+}
+
+// SetInvalidationSource 设置上游数据变更来源并开始监听，重复调用时停止之前的监听
+//
+// 返回值:
+//
+//	error - 开始监听失败
+func (e *engineImpl[T]) SetInvalidationSource(source InvalidationSource) error {
+	e.stopInvalidationSource()
+	if source == nil {
+		return nil
+	}
+
+	stop, err := source.Watch(context.Background(), e.Invalidate)
+	if err != nil {
+		return fmt.Errorf("监听上游数据变更失败: %w", err)
+	}
+	e.stopInvalidation = stop
+	return nil
+}
+
+// stopInvalidationSource 停止监听上游数据变更
+func (e *engineImpl[T]) stopInvalidationSource() {
+	if e.stopInvalidation != nil {
+		e.stopInvalidation()
+		e.stopInvalidation = nil
+	}
+}
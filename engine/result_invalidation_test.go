@@ -0,0 +1,115 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/pubsub"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+// stubInvalidationSource 记录引擎传入的失效函数
+type stubInvalidationSource struct {
+	invalidate InvalidateFunc
+	stopped    bool
+}
+
+func (s *stubInvalidationSource) Watch(ctx context.Context, invalidate InvalidateFunc) (func(), error) {
+	s.invalidate = invalidate
+	return func() { s.stopped = true }, nil
+}
+
+// TestResultInvalidation 测试幂等结果失效
+func TestResultInvalidation(t *testing.T) {
+	Convey("幂等结果失效", t, func() {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		ctx := context.Background()
+
+		mapper := rule.NewMockRuleMapper(ctrl)
+		mapper.EXPECT().FindByBizCode(gomock.Any(), "loan").Return([]*rule.Rule{
+			{BizCode: "loan", Name: "score", Enabled: true, Version: 1,
+				GRL: `rule Score "评分" { when true then Result["score"] = Params["score"]; Retract("Score"); }`},
+		}, nil).AnyTimes()
+
+		newEngine := func(c cache.Cache) *engineImpl[map[string]any] {
+			cfg := config.DefaultConfig()
+			cfg.IdempotencyWindow = time.Minute
+			return NewEngineImpl[map[string]any](
+				cfg, mapper, c, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+				ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+			)
+		}
+		score := func(engine *engineImpl[map[string]any], key string, value int) any {
+			result, err := engine.Exec(ctx, "loan", map[string]any{"score": value}, WithIdempotencyKey(key))
+			So(err, ShouldBeNil)
+			return result["score"]
+		}
+
+		engine := newEngine(cache.NewMemoryCache(100))
+		defer engine.Close()
+
+		Convey("按幂等键前缀失效，其他键保留", func() {
+			So(score(engine, "cust-42:order-1", 1), ShouldEqual, 1)
+			So(score(engine, "cust-7:order-1", 1), ShouldEqual, 1)
+			So(score(engine, "cust-42:order-1", 2), ShouldEqual, 1)
+
+			So(engine.Invalidate(ctx, "loan", "cust-42:"), ShouldBeNil)
+			So(score(engine, "cust-42:order-1", 2), ShouldEqual, 2)
+			So(score(engine, "cust-7:order-1", 2), ShouldEqual, 1)
+
+			So(engine.Invalidate(ctx, "loan", ""), ShouldBeNil)
+			So(score(engine, "cust-7:order-1", 3), ShouldEqual, 3)
+		})
+
+		Convey("广播到其他实例", func() {
+			bus := pubsub.NewMemory()
+			other := newEngine(cache.NewMemoryCache(100))
+			defer other.Close()
+			So(engine.SetPubSub(bus), ShouldBeNil)
+			So(other.SetPubSub(bus), ShouldBeNil)
+
+			So(score(other, "cust-42:order-1", 1), ShouldEqual, 1)
+			So(engine.Invalidate(ctx, "loan", "cust-42"), ShouldBeNil)
+			So(score(other, "cust-42:order-1", 2), ShouldEqual, 2)
+		})
+
+		Convey("变更来源触发失效，关闭引擎时停止监听", func() {
+			source := &stubInvalidationSource{}
+			So(engine.SetInvalidationSource(source), ShouldBeNil)
+			So(score(engine, "cust-42:order-1", 1), ShouldEqual, 1)
+
+			So(source.invalidate(ctx, "loan", "cust-42:"), ShouldBeNil)
+			So(score(engine, "cust-42:order-1", 2), ShouldEqual, 2)
+
+			So(engine.Close(), ShouldBeNil)
+			So(source.stopped, ShouldBeTrue)
+		})
+
+		Convey("未配置缓存时没有可失效的结果", func() {
+			noCache := newEngine(nil)
+			defer noCache.Close()
+			So(noCache.Invalidate(ctx, "loan", "cust-42:"), ShouldBeNil)
+		})
+
+		Convey("缓存不支持按前缀删除时返回ErrInvalidationNotSupported", func() {
+			custom := newEngine(cache.NewMockCache(ctrl))
+			err := custom.Invalidate(ctx, "loan", "cust-42:")
+			So(errors.Is(err, ErrInvalidationNotSupported), ShouldBeTrue)
+		})
+
+		Convey("业务码不能为空", func() {
+			So(engine.Invalidate(ctx, "", "cust-42:"), ShouldNotBeNil)
+		})
+	})
+}
@@ -0,0 +1,132 @@
+package engine
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+)
+
+// ============================================================================
+// 结果变更日志 - 记录每条规则对Result的写入，回答"谁覆盖了我的字段"
+// ============================================================================
+//
+// Grule通过反射直接写入Result map，无法拦截单次赋值。记录器在规则动作执行前保存Result快照，
+// 在下一周期开始（或执行结束）时与当前Result比较，将差异归属到刚执行的规则。
+// 嵌套的 map[string]any 按点分路径逐层比较，其他值整体比较。
+
+// ResultChange Result字段的一次变更
+type ResultChange struct {
+	Cycle   uint64 // 规则执行所在的周期
+	Rule    string // 写入的规则名
+	Key     string // 字段路径，嵌套map以点分隔，如 risk.level
+	Old     any    // 变更前的值，新增字段时为nil
+	New     any    // 变更后的值，删除字段时为nil
+	Deleted bool   // 字段是否被删除
+}
+
+// WithResultJournal 启用结果变更日志 - 执行结束后将每条规则对Result的写入记录到 ExecReport.Journal，需同时使用 WithExecReport
+func WithResultJournal() ExecOption {
+	return func(o *ExecOptions) {
+		o.Journal = true
+	}
+}
+
+// journalRecorder 结果变更记录器 - 实现GruleEngineListener
+type journalRecorder struct {
+	data     ast.IDataContext
+	rule     string         // 正在执行动作的规则，为空表示没有待比较的快照
+	cycle    uint64         // 正在执行动作的规则所在周期
+	snapshot map[string]any // 规则动作执行前的Result深拷贝
+	changes  []ResultChange
+}
+
+// newJournalRecorder 创建结果变更记录器
+func newJournalRecorder(dataCtx ast.IDataContext) *journalRecorder {
+	return &journalRecorder{data: dataCtx}
+}
+
+// BeginCycle 实现GruleEngineListener - 上一周期执行的规则动作已结束
+func (j *journalRecorder) BeginCycle(cycle uint64) {
+	j.flush()
+}
+
+// EvaluateRuleEntry 实现GruleEngineListener
+func (j *journalRecorder) EvaluateRuleEntry(cycle uint64, entry *ast.RuleEntry, candidate bool) {}
+
+// ExecuteRuleEntry 实现GruleEngineListener - 规则动作执行前保存快照
+func (j *journalRecorder) ExecuteRuleEntry(cycle uint64, entry *ast.RuleEntry) {
+	j.flush()
+	result, ok := resultMap(j.data)
+	if !ok {
+		return
+	}
+	j.rule, j.cycle = entry.RuleName, cycle
+	j.snapshot = copyInput(result).(map[string]any)
+}
+
+// flush 比较快照与当前Result，记录刚执行的规则产生的变更
+func (j *journalRecorder) flush() {
+	if j.rule == "" {
+		return
+	}
+	if result, ok := resultMap(j.data); ok {
+		j.diff("", j.snapshot, result)
+	}
+	j.rule, j.snapshot = "", nil
+}
+
+// diff 逐字段比较，键按字典序输出保证日志稳定
+func (j *journalRecorder) diff(prefix string, before, after map[string]any) {
+	keys := make([]string, 0, len(before)+len(after))
+	for key := range before {
+		keys = append(keys, key)
+	}
+	for key := range after {
+		if _, ok := before[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		oldValue, existed := before[key]
+		newValue, exists := after[key]
+		switch {
+		case !exists:
+			j.record(path, oldValue, nil, true)
+		case !existed:
+			j.record(path, nil, newValue, false)
+		default:
+			oldMap, oldIsMap := oldValue.(map[string]any)
+			newMap, newIsMap := newValue.(map[string]any)
+			if oldIsMap && newIsMap {
+				j.diff(path, oldMap, newMap)
+			} else if !reflect.DeepEqual(oldValue, newValue) {
+				j.record(path, oldValue, newValue, false)
+			}
+		}
+	}
+}
+
+// record 追加一条变更，新值拷贝后保存，避免后续规则修改影响已记录的值
+func (j *journalRecorder) record(path string, oldValue, newValue any, deleted bool) {
+	j.changes = append(j.changes, ResultChange{
+		Cycle:   j.cycle,
+		Rule:    j.rule,
+		Key:     path,
+		Old:     oldValue,
+		New:     copyInput(newValue),
+		Deleted: deleted,
+	})
+}
+
+// finish 结束记录并返回变更日志
+func (j *journalRecorder) finish() []ResultChange {
+	j.flush()
+	return j.changes
+}
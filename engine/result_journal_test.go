@@ -0,0 +1,75 @@
+package engine
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+// TestResultJournal 测试结果变更日志
+func TestResultJournal(t *testing.T) {
+	Convey("结果变更日志", t, func() {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mapper := rule.NewMockRuleMapper(ctrl)
+		mapper.EXPECT().FindByBizCode(gomock.Any(), "journal").Return([]*rule.Rule{
+			{Name: "score", Enabled: true,
+				GRL: `rule Score "评分" salience 10 { when Params["amount"] > 100 then Result["score"] = 700; Result["level"] = "B"; Retract("Score"); }`},
+			{Name: "override", Enabled: true,
+				GRL: `rule Override "调级" { when Result["score"] >= 600 then Result["level"] = "A"; Retract("Override"); }`},
+		}, nil).AnyTimes()
+
+		engine := NewEngineImpl[map[string]any](
+			config.DefaultConfig(), mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+			ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+		)
+		defer engine.Close()
+
+		Convey("记录每条规则写入的字段和新旧值", func() {
+			var report ExecReport
+			result, err := engine.Exec(context.Background(), "journal", map[string]any{"amount": 200},
+				WithExecReport(&report), WithResultJournal())
+			So(err, ShouldBeNil)
+			So(result["level"], ShouldEqual, "A")
+
+			So(report.Journal, ShouldHaveLength, 3)
+			So(report.Journal[0], ShouldResemble, ResultChange{Cycle: report.Journal[0].Cycle, Rule: "Score", Key: "level", New: "B"})
+			So(report.Journal[1].Rule, ShouldEqual, "Score")
+			So(report.Journal[1].Key, ShouldEqual, "score")
+			So(report.Journal[2].Rule, ShouldEqual, "Override")
+			So(report.Journal[2].Key, ShouldEqual, "level")
+			So(report.Journal[2].Old, ShouldEqual, "B")
+			So(report.Journal[2].New, ShouldEqual, "A")
+			So(report.Journal[2].Cycle, ShouldBeGreaterThan, report.Journal[0].Cycle)
+		})
+
+		Convey("未启用时不记录", func() {
+			var report ExecReport
+			_, err := engine.Exec(context.Background(), "journal", map[string]any{"amount": 200}, WithExecReport(&report))
+			So(err, ShouldBeNil)
+			So(report.Journal, ShouldBeNil)
+		})
+
+		Convey("嵌套map按路径比较", func() {
+			j := &journalRecorder{rule: "R", cycle: 1}
+			j.diff("",
+				map[string]any{"risk": map[string]any{"level": "low", "score": 1}, "gone": true},
+				map[string]any{"risk": map[string]any{"level": "high", "score": 1}},
+			)
+			So(j.changes, ShouldResemble, []ResultChange{
+				{Cycle: 1, Rule: "R", Key: "gone", Old: true, Deleted: true},
+				{Cycle: 1, Rule: "R", Key: "risk.level", Old: "low", New: "high"},
+			})
+		})
+	})
+}
@@ -0,0 +1,113 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+)
+
+// ============================================================================
+// 结果流式输出 - 规则以 Emit 逐个产出的大量结果元素交给回调，不在Result中累积
+// ============================================================================
+//
+// 规则动作调用 Emit("offers", offer) 产出结果元素：未配置流式输出时与 AppendTo(Result, "offers", offer)
+// 相同，追加到 Result["offers"] 列表；配置了该键的流式输出时元素在产出时立即交给回调，不写入Result。
+
+// errStreamStopped 迭代器的使用方停止迭代
+var errStreamStopped = errors.New("结果流已停止")
+
+// ResultStream 结果流式输出配置
+type ResultStream struct {
+	Key        string                  // 流式输出的结果键，规则以 Emit(Key, 元素) 产出
+	MaxResults int                     // 最多输出的元素数，超出的元素丢弃，<=0表示不限制
+	Yield      func(element any) error // 接收元素的回调，返回错误时中止执行并返回该错误
+}
+
+// WithResultStream 设置结果流式输出 - Emit 写入key的元素依次交给yield，不写入Result
+//
+// 输出的元素数达到maxResults后，之后产出的元素被丢弃，使用 WithExecReport 时
+// ExecReport.Streamed 为输出的元素数，ExecReport.Truncated 表示是否有元素被丢弃。
+func WithResultStream(key string, maxResults int, yield func(element any) error) ExecOption {
+	return func(o *ExecOptions) {
+		o.Stream = &ResultStream{Key: key, MaxResults: maxResults, Yield: yield}
+	}
+}
+
+// resultEmitter 单次执行的结果流状态
+type resultEmitter struct {
+	stream   *ResultStream
+	streamed int // 已输出的元素数
+	dropped  int // 超出上限被丢弃的元素数
+}
+
+// report 将输出统计写入执行报告
+func (r *resultEmitter) report(report *ExecReport) {
+	if report == nil || r.stream == nil {
+		return
+	}
+	report.Streamed = r.streamed
+	report.Truncated = r.dropped > 0
+}
+
+// Emit 产出结果元素 - 配置了该键的流式输出时交给回调，否则追加到Result[key]列表
+func (f *ruleFunctions) Emit(key any, element any) {
+	name := stringArg(key)
+	if name == "" {
+		f.raise("Emit", fmt.Errorf("结果键不能为空"))
+	}
+
+	stream := f.emitter.stream
+	if stream == nil || stream.Key != name || stream.Yield == nil {
+		if result, ok := resultMap(f.data); ok {
+			appendTo(result, name, element)
+		}
+		return
+	}
+	if stream.MaxResults > 0 && f.emitter.streamed >= stream.MaxResults {
+		f.emitter.dropped++
+		return
+	}
+	f.emitter.streamed++
+	if err := stream.Yield(element); err != nil {
+		f.raise("Emit", err)
+	}
+}
+
+// StreamResults 以迭代器流式获取规则产出的结果元素
+//
+// 规则执行期间每产出一个key的元素即交给迭代器，使用方停止迭代时中止执行；
+// 执行失败时最后产出 (nil, err)。执行结果中的其他字段不返回，需要时使用 WithResultStream。
+//
+// 参数:
+//
+//	ctx        - 上下文
+//	bizCode    - 业务码
+//	input      - 输入数据
+//	key        - 流式输出的结果键
+//	maxResults - 最多输出的元素数，<=0表示不限制
+//	opts       - 执行选项
+//
+// 使用示例:
+//
+//	for offer, err := range engine.StreamResults(ctx, "OFFERS", input, "offers", 1000) {
+//	    if err != nil {
+//	        return err
+//	    }
+//	    send(offer)
+//	}
+func (e *engineImpl[T]) StreamResults(ctx context.Context, bizCode string, input any, key string, maxResults int, opts ...ExecOption) iter.Seq2[any, error] {
+	return func(yield func(any, error) bool) {
+		stopped := false
+		_, err := e.Exec(ctx, bizCode, input, append(opts, WithResultStream(key, maxResults, func(element any) error {
+			if !yield(element, nil) {
+				stopped = true
+				return errStreamStopped
+			}
+			return nil
+		}))...)
+		if err != nil && !stopped {
+			yield(nil, err)
+		}
+	}
+}
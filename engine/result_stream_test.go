@@ -0,0 +1,109 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+// TestResultStream 测试结果流式输出
+func TestResultStream(t *testing.T) {
+	Convey("结果流式输出", t, func() {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		ctx := context.Background()
+
+		mapper := rule.NewMockRuleMapper(ctrl)
+		mapper.EXPECT().FindByBizCode(gomock.Any(), "offers").Return([]*rule.Rule{
+			{BizCode: "offers", Name: "Cards", Enabled: true, Version: 1,
+				GRL: `rule Cards "信用卡" salience 10 { when true then Emit("offers", "card-a"); Emit("offers", "card-b"); Retract("Cards"); }`},
+			{BizCode: "offers", Name: "Loans", Enabled: true, Version: 1,
+				GRL: `rule Loans "贷款" { when Params["vip"] == true then Emit("offers", "loan-" + Params["tier"]); Result["done"] = true; Retract("Loans"); }`},
+		}, nil).AnyTimes()
+
+		engine := NewEngineImpl[map[string]any](
+			config.DefaultConfig(), mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+			ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+		)
+		defer engine.Close()
+		input := map[string]any{"vip": true, "tier": "gold"}
+
+		Convey("未配置流式输出时追加到Result列表", func() {
+			result, err := engine.Exec(ctx, "offers", input)
+			So(err, ShouldBeNil)
+			So(result["offers"], ShouldResemble, []interface{}{"card-a", "card-b", "loan-gold"})
+		})
+
+		Convey("按产出顺序交给回调，不写入Result", func() {
+			var elements []any
+			var report ExecReport
+			result, err := engine.Exec(ctx, "offers", input, WithExecReport(&report), WithResultStream("offers", 0, func(element any) error {
+				elements = append(elements, element)
+				return nil
+			}))
+			So(err, ShouldBeNil)
+			So(elements, ShouldResemble, []any{"card-a", "card-b", "loan-gold"})
+			So(result, ShouldNotContainKey, "offers")
+			So(result["done"], ShouldEqual, true)
+			So(report.Streamed, ShouldEqual, 3)
+			So(report.Truncated, ShouldBeFalse)
+		})
+
+		Convey("超出上限的元素被丢弃，其余规则照常执行", func() {
+			var elements []any
+			var report ExecReport
+			result, err := engine.Exec(ctx, "offers", input, WithExecReport(&report), WithResultStream("offers", 2, func(element any) error {
+				elements = append(elements, element)
+				return nil
+			}))
+			So(err, ShouldBeNil)
+			So(elements, ShouldResemble, []any{"card-a", "card-b"})
+			So(result["done"], ShouldEqual, true)
+			So(report.Streamed, ShouldEqual, 2)
+			So(report.Truncated, ShouldBeTrue)
+		})
+
+		Convey("回调返回错误时中止执行", func() {
+			boom := errors.New("下游已断开")
+			_, err := engine.Exec(ctx, "offers", input, WithResultStream("offers", 0, func(element any) error {
+				return boom
+			}))
+			So(errors.Is(err, boom), ShouldBeTrue)
+		})
+
+		Convey("迭代器逐个产出元素，停止迭代时中止执行", func() {
+			var elements []any
+			for element, err := range engine.StreamResults(ctx, "offers", input, "offers", 0) {
+				So(err, ShouldBeNil)
+				elements = append(elements, element)
+			}
+			So(elements, ShouldResemble, []any{"card-a", "card-b", "loan-gold"})
+
+			elements = nil
+			for element := range engine.StreamResults(ctx, "offers", input, "offers", 0) {
+				elements = append(elements, element)
+				break
+			}
+			So(elements, ShouldResemble, []any{"card-a"})
+		})
+
+		Convey("迭代器最后产出执行错误", func() {
+			var errs []error
+			for _, err := range engine.StreamResults(ctx, "offers", map[string]any{"vip": true}, "offers", 0) {
+				errs = append(errs, err)
+			}
+			So(errs, ShouldHaveLength, 3)
+			So(errs[2], ShouldNotBeNil)
+		})
+	})
+}
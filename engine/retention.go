@@ -0,0 +1,199 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gitee.com/damengde/runehammer/rule"
+)
+
+// ============================================================================
+// 数据保留 - 定时分批清理规则历史版本和审计类记录
+// ============================================================================
+//
+// 规则历史版本通过映射器的 rule.RuleRetentionMapper 扩展清理，只删除禁用的历史版本；
+// 审计类记录由注册的 rule.RetentionTarget 按保留时长清理。每个目标分批删除，
+// 批之间检查上下文，单个目标失败不影响其他目标。累计统计通过 RetentionStats 和诊断快照获取。
+
+const (
+	defaultRetentionInterval  = time.Hour // 默认清理间隔
+	defaultRetentionBatchSize = 500       // 默认每批删除数
+)
+
+// ruleVersionsTarget 规则历史版本清理的统计名称
+const ruleVersionsTarget = "rule_versions"
+
+// RetentionReport 单次清理报告
+type RetentionReport struct {
+	StartedAt time.Time        // 开始时间
+	Duration  time.Duration    // 耗时
+	Deleted   map[string]int64 // 清理目标 -> 删除的记录数，规则历史版本为 rule_versions
+	Batches   int              // 执行的删除批次数
+}
+
+// RetentionStats 清理任务累计统计
+type RetentionStats struct {
+	Runs         int64            `json:"runs"`                 // 执行次数
+	Deleted      map[string]int64 `json:"deleted"`              // 清理目标 -> 累计删除的记录数
+	LastRun      time.Time        `json:"last_run"`             // 最近一次执行时间
+	LastDuration time.Duration    `json:"last_duration"`        // 最近一次执行耗时
+	LastError    string           `json:"last_error,omitempty"` // 最近一次执行的错误
+}
+
+// retentionState 清理任务状态
+type retentionState struct {
+	mu      sync.Mutex
+	running sync.Mutex // 保证同一时间只有一次清理
+	targets []rule.RetentionTarget
+	stats   RetentionStats
+}
+
+// AddRetentionTarget 注册按保留时长清理的目标，配置了审计记录保留时长时由清理任务清理
+func (e *engineImpl[T]) AddRetentionTarget(target rule.RetentionTarget) {
+	if target == nil {
+		return
+	}
+	e.retention.mu.Lock()
+	defer e.retention.mu.Unlock()
+	e.retention.targets = append(e.retention.targets, target)
+}
+
+// retentionEnabled 是否配置了数据保留
+func (e *engineImpl[T]) retentionEnabled() bool {
+	return e.config != nil && (e.config.AuditRetention > 0 || e.config.RuleVersionRetention > 0)
+}
+
+// StartRetention 启动定时清理任务，未配置数据保留或只读模式时不启动
+func (e *engineImpl[T]) StartRetention() error {
+	if !e.retentionEnabled() || e.ReadOnly() {
+		return nil
+	}
+
+	interval := e.config.RetentionInterval
+	if interval <= 0 {
+		interval = defaultRetentionInterval
+	}
+	_, err := e.scheduler().AddFunc(fmt.Sprintf("@every %s", interval), func() {
+		_, err := e.taskLock.TryRun(context.Background(), retentionLockTask, interval, func(ctx context.Context) error {
+			_, err := e.RunRetention(ctx)
+			return err
+		})
+		if err != nil && e.logger != nil {
+			e.logger.Warnf(context.Background(), "数据清理失败", "error", err)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("添加数据清理任务失败: %w", err)
+	}
+	e.RegisterDiagnostics("retention", func() any { return e.RetentionStats() })
+	e.scheduler().Start()
+	return nil
+}
+
+// RunRetention 立即执行一次数据清理 - 删除超出保留数的规则历史版本和超过保留时长的审计记录
+//
+// 各目标分批删除直到清理完毕，返回的报告包含各目标的删除数；部分目标失败时返回已完成的报告和合并的错误。
+func (e *engineImpl[T]) RunRetention(ctx context.Context) (*RetentionReport, error) {
+	if err := e.checkWritable("数据清理"); err != nil {
+		return nil, err
+	}
+
+	e.retention.running.Lock()
+	defer e.retention.running.Unlock()
+
+	report := &RetentionReport{StartedAt: time.Now(), Deleted: make(map[string]int64)}
+	batchSize := e.config.RetentionBatchSize
+	if batchSize <= 0 {
+		batchSize = defaultRetentionBatchSize
+	}
+
+	var errs []error
+	purge := func(name string, fn func(batchSize int) (int64, error)) {
+		deleted, batches, err := purgeInBatches(ctx, batchSize, fn)
+		report.Deleted[name] += deleted
+		report.Batches += batches
+		if err != nil {
+			errs = append(errs, fmt.Errorf("清理 %s 失败: %w", name, err))
+		}
+	}
+
+	if keep := e.config.RuleVersionRetention; keep > 0 {
+		if mapper, ok := e.mapper.(rule.RuleRetentionMapper); ok {
+			purge(ruleVersionsTarget, func(batchSize int) (int64, error) {
+				return mapper.PurgeRuleVersions(ctx, keep, batchSize)
+			})
+		} else if e.logger != nil {
+			e.logger.Warnf(ctx, "规则映射器不支持清理历史版本，已跳过", "keep", keep)
+		}
+	}
+
+	if e.config.AuditRetention > 0 {
+		cutoff := report.StartedAt.Add(-e.config.AuditRetention)
+		e.retention.mu.Lock()
+		targets := append([]rule.RetentionTarget(nil), e.retention.targets...)
+		e.retention.mu.Unlock()
+		for _, target := range targets {
+			purge(target.Name(), func(batchSize int) (int64, error) {
+				return target.PurgeBefore(ctx, cutoff, batchSize)
+			})
+		}
+	}
+
+	report.Duration = time.Since(report.StartedAt)
+	err := errors.Join(errs...)
+	e.recordRetention(report, err)
+	if e.logger != nil {
+		e.logger.Infof(ctx, "数据清理完成", "deleted", report.Deleted, "batches", report.Batches, "duration", report.Duration)
+	}
+	return report, err
+}
+
+// RetentionStats 获取清理任务累计统计
+func (e *engineImpl[T]) RetentionStats() RetentionStats {
+	e.retention.mu.Lock()
+	defer e.retention.mu.Unlock()
+	stats := e.retention.stats
+	stats.Deleted = make(map[string]int64, len(e.retention.stats.Deleted))
+	for name, n := range e.retention.stats.Deleted {
+		stats.Deleted[name] = n
+	}
+	return stats
+}
+
+// recordRetention 累计清理统计
+func (e *engineImpl[T]) recordRetention(report *RetentionReport, err error) {
+	e.retention.mu.Lock()
+	defer e.retention.mu.Unlock()
+	stats := &e.retention.stats
+	stats.Runs++
+	stats.LastRun = report.StartedAt
+	stats.LastDuration = report.Duration
+	stats.LastError = ""
+	if err != nil {
+		stats.LastError = err.Error()
+	}
+	if stats.Deleted == nil {
+		stats.Deleted = make(map[string]int64)
+	}
+	for name, n := range report.Deleted {
+		stats.Deleted[name] += n
+	}
+}
+
+// purgeInBatches 分批删除直到某批删除数小于批大小，批之间检查上下文
+func purgeInBatches(ctx context.Context, batchSize int, purge func(batchSize int) (int64, error)) (deleted int64, batches int, err error) {
+	for {
+		if err := ctx.Err(); err != nil {
+			return deleted, batches, err
+		}
+		n, err := purge(batchSize)
+		deleted += n
+		batches++
+		if err != nil || n < int64(batchSize) {
+			return deleted, batches, err
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// failingTarget 清理失败的保留目标
+type failingTarget struct{}
+
+func (failingTarget) Name() string { return "broken" }
+
+func (failingTarget) PurgeBefore(ctx context.Context, cutoff time.Time, batchSize int) (int64, error) {
+	return 0, errors.New("table locked")
+}
+
+// TestRetention 测试数据清理
+func TestRetention(t *testing.T) {
+	Convey("数据清理", t, func() {
+		db, err := gorm.Open(sqlite.Open("file:retention.db?mode=memory&cache=shared"), &gorm.Config{})
+		So(err, ShouldBeNil)
+		So(db.AutoMigrate(&rule.Rule{}), ShouldBeNil)
+		db.Where("1 = 1").Delete(&rule.Rule{})
+		So(db.Exec("CREATE TABLE IF NOT EXISTS decision_audit (id INTEGER PRIMARY KEY AUTOINCREMENT, created_at DATETIME)").Error, ShouldBeNil)
+		db.Exec("DELETE FROM decision_audit")
+
+		grl := `rule R "R" { when true then Retract("R"); }`
+		var seed []*rule.Rule
+		for v := 1; v <= 5; v++ {
+			seed = append(seed, &rule.Rule{BizCode: "loan", Name: "limit", GRL: grl, Version: v, Enabled: v == 5})
+		}
+		// 旧版本仍启用的规则不删除
+		seed = append(seed,
+			&rule.Rule{BizCode: "loan", Name: "score", GRL: grl, Version: 1, Enabled: true},
+			&rule.Rule{BizCode: "loan", Name: "score", GRL: grl, Version: 2, Enabled: false},
+			&rule.Rule{BizCode: "loan", Name: "score", GRL: grl, Version: 3, Enabled: false},
+		)
+		So(db.Create(&seed).Error, ShouldBeNil)
+
+		now := time.Now()
+		for i := 0; i < 7; i++ {
+			So(db.Exec("INSERT INTO decision_audit (created_at) VALUES (?)", now.Add(-time.Duration(100+i)*24*time.Hour)).Error, ShouldBeNil)
+		}
+		So(db.Exec("INSERT INTO decision_audit (created_at) VALUES (?)", now.Add(-time.Hour)).Error, ShouldBeNil)
+
+		cfg := config.DefaultConfig()
+		cfg.RuleVersionRetention = 2
+		cfg.AuditRetention = 90 * 24 * time.Hour
+		cfg.RetentionBatchSize = 2
+		engine := NewEngineImpl[map[string]any](
+			cfg, rule.NewRuleMapper(db), nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+			ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+		)
+		defer engine.Close()
+		engine.AddRetentionTarget(rule.NewTableRetention(db, "decision_audit", "created_at"))
+
+		Convey("分批删除超出保留数的禁用版本和过期审计记录", func() {
+			report, err := engine.RunRetention(context.Background())
+			So(err, ShouldBeNil)
+			So(report.Deleted, ShouldResemble, map[string]int64{"rule_versions": 3, "decision_audit": 7})
+			So(report.Batches, ShouldEqual, 2+4)
+
+			var versions []int
+			db.Model(&rule.Rule{}).Where("name = ?", "limit").Order("version").Pluck("version", &versions)
+			So(versions, ShouldResemble, []int{4, 5})
+			db.Model(&rule.Rule{}).Where("name = ?", "score").Order("version").Pluck("version", &versions)
+			So(versions, ShouldResemble, []int{1, 2, 3})
+
+			var remaining int64
+			db.Table("decision_audit").Count(&remaining)
+			So(remaining, ShouldEqual, 1)
+
+			// 再次执行无可删除记录，累计统计
+			report, err = engine.RunRetention(context.Background())
+			So(err, ShouldBeNil)
+			So(report.Deleted, ShouldResemble, map[string]int64{"rule_versions": 0, "decision_audit": 0})
+			stats := engine.RetentionStats()
+			So(stats.Runs, ShouldEqual, 2)
+			So(stats.Deleted, ShouldResemble, map[string]int64{"rule_versions": 3, "decision_audit": 7})
+			So(stats.LastError, ShouldBeEmpty)
+		})
+
+		Convey("单个目标失败不影响其他目标", func() {
+			engine.AddRetentionTarget(failingTarget{})
+			report, err := engine.RunRetention(context.Background())
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "table locked")
+			So(report.Deleted["decision_audit"], ShouldEqual, 7)
+			So(engine.RetentionStats().LastError, ShouldContainSubstring, "broken")
+		})
+
+		Convey("上下文取消时停止清理", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			report, err := engine.RunRetention(ctx)
+			So(errors.Is(err, context.Canceled), ShouldBeTrue)
+			So(report.Deleted["rule_versions"], ShouldEqual, 0)
+		})
+
+		Convey("配置保留后启动定时任务并注册诊断信息", func() {
+			So(engine.StartRetention(), ShouldBeNil)
+			So(engine.cron.Entries(), ShouldHaveLength, 1)
+			snapshot := engine.DebugSnapshot()
+			So(snapshot.Extra, ShouldContainKey, "retention")
+		})
+	})
+}
@@ -0,0 +1,101 @@
+package engine
+
+import (
+	"fmt"
+	"hash/fnv"
+	"regexp"
+
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/hyperjumptech/grule-rule-engine/pkg"
+)
+
+// ============================================================================
+// 规则灰度放量 - 按输入字段确定性地只对部分执行启用规则
+// ============================================================================
+
+// grlRuleNamePattern 匹配GRL规则声明中的规则名
+var grlRuleNamePattern = regexp.MustCompile(`(?m)^\s*rule\s+([A-Za-z_]\w*)`)
+
+// rolloutPartial 规则是否处于部分放量状态
+func rolloutPartial(r *rule.Rule) bool {
+	return r.RolloutPercent > 0 && r.RolloutPercent < 100
+}
+
+// rolloutBucket 计算放量分桶 [0, 100) - 以规则名加盐，不同规则的放量人群相互独立
+func rolloutBucket(ruleName string, key any) int {
+	h := fnv.New32a()
+	h.Write([]byte(ruleName))
+	h.Write([]byte{0})
+	h.Write([]byte(fmt.Sprint(key)))
+	return int(h.Sum32() % 100)
+}
+
+// rolloutExclusions 计算本次执行需排除的GRL规则名
+//
+// 分桶值小于放量百分比的执行包含该规则，同一键值的结果稳定，放量比例调大时原有人群保持包含。
+// 未配置放量键字段或输入中缺少该字段时，部分放量的规则一律排除。
+func (e *engineImpl[T]) rolloutExclusions(rules []*rule.Rule, input any) []string {
+	var (
+		key     any
+		hasKey  bool
+		checked bool
+		names   []string
+	)
+
+	for _, r := range rules {
+		if !r.Enabled || !rolloutPartial(r) {
+			continue
+		}
+		if !checked {
+			checked = true
+			if e.config != nil && e.config.RolloutKeyField != "" {
+				key, hasKey = lookupPath(input, e.config.RolloutKeyField)
+				hasKey = hasKey && key != nil
+			}
+		}
+		if hasKey && rolloutBucket(r.Name, key) < r.RolloutPercent {
+			continue
+		}
+		names = append(names, ruleEntryNames(r)...)
+	}
+	return names
+}
+
+// executionInstance 克隆本次执行使用的知识库实例
+//
+// 编译缓存中的知识库由同一业务码的所有执行共享，只作为蓝本：撤回（灰度放量、规则选择器、
+// 规则动作中的Retract）和工作内存都是知识库上的状态，在共享实例上执行会互相影响。
+func executionInstance(kb *ast.KnowledgeBase) (*ast.KnowledgeBase, error) {
+	instance, err := kb.Clone(pkg.NewCloneTable())
+	if err != nil {
+		return nil, fmt.Errorf("克隆知识库实例失败: %w", err)
+	}
+	pruneDeletedEntries(instance)
+	return instance, nil
+}
+
+// retractGate 撤回闸门 - 在首个执行周期开始时撤回本次执行排除的规则（灰度放量、规则选择器）
+//
+// Grule在执行开始时会恢复所有已撤回的规则，因此需在周期开始后而非执行前撤回；
+// kb须为本次执行独占的实例（见 executionInstance），不能是编译缓存中共享的知识库。
+type retractGate struct {
+	kb    *ast.KnowledgeBase
+	names []string
+}
+
+// EvaluateRuleEntry 实现GruleEngineListener
+func (g *retractGate) EvaluateRuleEntry(cycle uint64, entry *ast.RuleEntry, candidate bool) {}
+
+// ExecuteRuleEntry 实现GruleEngineListener
+func (g *retractGate) ExecuteRuleEntry(cycle uint64, entry *ast.RuleEntry) {}
+
+// BeginCycle 实现GruleEngineListener
+func (g *retractGate) BeginCycle(cycle uint64) {
+	if cycle != 1 {
+		return
+	}
+	for _, name := range g.names {
+		g.kb.RetractRule(name)
+	}
+}
@@ -0,0 +1,116 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+// TestRollout 测试规则灰度放量
+func TestRollout(t *testing.T) {
+	Convey("规则灰度放量", t, func() {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mapper := rule.NewMockRuleMapper(ctrl)
+		mapper.EXPECT().FindByBizCode(gomock.Any(), "risk").Return([]*rule.Rule{
+			{Name: "base", GRL: `rule Base "基础" { when true then Result["base"] = true; Retract("Base"); }`, Enabled: true},
+			{Name: "new_model", GRL: `rule NewModel "新模型" { when true then Result["newModel"] = true; Retract("NewModel"); }`, Enabled: true, RolloutPercent: 30},
+		}, nil).AnyTimes()
+
+		cfg := config.DefaultConfig()
+		cfg.RolloutKeyField = "user.id"
+		engine := NewEngineImpl[map[string]any](
+			cfg, mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+			ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+		)
+		defer engine.Close()
+		ctx := context.Background()
+
+		exec := func(userID string) map[string]any {
+			result, err := engine.Exec(ctx, "risk", map[string]any{"user": map[string]any{"id": userID}})
+			So(err, ShouldBeNil)
+			So(result["base"], ShouldEqual, true)
+			return result
+		}
+
+		Convey("按比例放量且同一键结果稳定", func() {
+			included := 0
+			for i := 0; i < 1000; i++ {
+				userID := fmt.Sprintf("u%d", i)
+				result := exec(userID)
+				expected := rolloutBucket("new_model", userID) < 30
+				So(result["newModel"] == true, ShouldEqual, expected)
+				if expected {
+					included++
+				}
+			}
+			So(included, ShouldBeBetween, 250, 350)
+
+			// 同一键重复执行结果一致（撤回状态不会残留到下次执行）
+			for i := 0; i < 5; i++ {
+				So(exec("u1")["newModel"] == true, ShouldEqual, rolloutBucket("new_model", "u1") < 30)
+				So(exec("u2")["newModel"] == true, ShouldEqual, rolloutBucket("new_model", "u2") < 30)
+			}
+		})
+
+		Convey("并发执行时排除的规则不影响其他执行", func() {
+			var (
+				wg         sync.WaitGroup
+				mismatches atomic.Int64
+			)
+			for g := 0; g < 16; g++ {
+				wg.Add(1)
+				go func(g int) {
+					defer wg.Done()
+					for i := 0; i < 50; i++ {
+						userID := fmt.Sprintf("u%d", g*50+i)
+						result, err := engine.Exec(ctx, "risk", map[string]any{"user": map[string]any{"id": userID}})
+						if err != nil || result["base"] != true || (result["newModel"] == true) != (rolloutBucket("new_model", userID) < 30) {
+							mismatches.Add(1)
+						}
+					}
+				}(g)
+			}
+			wg.Wait()
+			So(mismatches.Load(), ShouldEqual, 0)
+		})
+
+		Convey("缺少放量键时不执行部分放量的规则", func() {
+			result, err := engine.Exec(ctx, "risk", map[string]any{"amount": 1})
+			So(err, ShouldBeNil)
+			So(result["base"], ShouldEqual, true)
+			So(result["newModel"], ShouldBeNil)
+		})
+
+		Convey("放量比例调大时已命中的键保持命中", func() {
+			for i := 0; i < 200; i++ {
+				key := fmt.Sprintf("u%d", i)
+				if rolloutBucket("new_model", key) < 10 {
+					So(rolloutBucket("new_model", key) < 50, ShouldBeTrue)
+				}
+			}
+		})
+
+		Convey("全量与未设置时始终包含", func() {
+			rules := []*rule.Rule{
+				{Name: "a", GRL: `rule A "a" { when true then Retract("A"); }`, Enabled: true, RolloutPercent: 100},
+				{Name: "b", GRL: `rule B "b" { when true then Retract("B"); }`, Enabled: true},
+				{Name: "c", GRL: "rule C1 \"c\" { when true then Retract(\"C1\"); }\nrule C2 \"c\" { when true then Retract(\"C2\"); }", Enabled: true, RolloutPercent: 1},
+			}
+			excluded := engine.rolloutExclusions(rules, map[string]any{})
+			So(excluded, ShouldResemble, []string{"C1", "C2"})
+		})
+	})
+}
@@ -0,0 +1,47 @@
+package engine
+
+import (
+	"encoding/json"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/rule"
+)
+
+// ============================================================================
+// 规则缓存还原 - 缓存中的规则列表反序列化后还原为规则模型
+// ============================================================================
+//
+// RuleCacheItem.Rules 的元素类型为 cache.Rule（interface{}），写入进程内缓存时保留 *rule.Rule，
+// 经字节序列化（Redis、内存缓存）读回后为 map[string]any，需经JSON重新解码；
+// 无法还原的规则项被跳过，不以nil规则参与编译。
+
+// cachedRules 还原缓存项中的规则列表
+func cachedRules(item cache.RuleCacheItem) []*rule.Rule {
+	rules := make([]*rule.Rule, 0, len(item.Rules))
+	for _, r := range item.Rules {
+		if converted := toRule(r); converted != nil {
+			rules = append(rules, converted)
+		}
+	}
+	return rules
+}
+
+// toRule 将缓存中的规则项还原为规则模型
+func toRule(item cache.Rule) *rule.Rule {
+	switch r := item.(type) {
+	case *rule.Rule:
+		return r
+	case nil:
+		return nil
+	default:
+		data, err := json.Marshal(r)
+		if err != nil {
+			return nil
+		}
+		var converted rule.Rule
+		if err := json.Unmarshal(data, &converted); err != nil {
+			return nil
+		}
+		return &converted
+	}
+}
@@ -0,0 +1,74 @@
+package engine
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+// TestRuleCache 测试缓存规则的还原
+func TestRuleCache(t *testing.T) {
+	Convey("缓存规则还原", t, func() {
+
+		Convey("序列化后的规则项还原为规则模型", func() {
+			item := cache.RuleCacheItem{Rules: []cache.Rule{
+				&rule.Rule{ID: 7, Name: "fee", GRL: "rule Fee {}", Version: 3, Enabled: true, Tags: []string{"fast"}},
+				&rule.Rule{ID: 8, Name: "paged", Version: 1, Enabled: true, Entries: []string{"Paged"}},
+			}}
+			data, err := item.ToBytes()
+			So(err, ShouldBeNil)
+
+			var restored cache.RuleCacheItem
+			So(restored.FromBytes(data), ShouldBeNil)
+			_, isMap := restored.Rules[0].(map[string]any)
+			So(isMap, ShouldBeTrue)
+
+			rules := cachedRules(restored)
+			So(rules, ShouldHaveLength, 2)
+			So(rules[0].ID, ShouldEqual, 7)
+			So(rules[0].GRL, ShouldEqual, "rule Fee {}")
+			So(rules[0].Tags, ShouldResemble, []string{"fast"})
+			So(rules[1].Entries, ShouldResemble, []string{"Paged"})
+		})
+
+		Convey("无法还原的规则项被跳过", func() {
+			rules := cachedRules(cache.RuleCacheItem{Rules: []cache.Rule{nil, "not a rule", &rule.Rule{Name: "ok"}}})
+			So(rules, ShouldHaveLength, 1)
+			So(rules[0].Name, ShouldEqual, "ok")
+		})
+
+		Convey("命中字节缓存的执行使用还原的规则", func() {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mapper := rule.NewMockRuleMapper(ctrl)
+			mapper.EXPECT().FindByBizCode(gomock.Any(), "cached_biz").Return([]*rule.Rule{
+				{ID: 1, Name: "hit", GRL: `rule Hit "命中" { when Params["n"] > 0 then Result["hit"] = true; Retract("Hit"); }`, Version: 1, Enabled: true},
+			}, nil).Times(1)
+
+			engine := NewEngineImpl[map[string]any](
+				config.DefaultConfig(), mapper, cache.NewMemoryCache(100), cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+				ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+			)
+			defer engine.Close()
+			ctx := context.Background()
+
+			// 第二次执行清理编译缓存后从字节缓存读取规则重新编译
+			for i := 0; i < 2; i++ {
+				engine.dropKnowledgeBase("cached_biz")
+				result, err := engine.Exec(ctx, "cached_biz", map[string]any{"n": 1})
+				So(err, ShouldBeNil)
+				So(result["hit"], ShouldEqual, true)
+			}
+		})
+	})
+}
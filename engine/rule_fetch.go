@@ -0,0 +1,259 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash"
+	"strings"
+
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/builder"
+	"github.com/hyperjumptech/grule-rule-engine/pkg"
+)
+
+// ============================================================================
+// 规则分页获取 - 规则数量巨大的业务码按页获取、按页拼接GRL编译
+// ============================================================================
+
+// ErrNoRuleMapper 引擎未配置规则映射器，无法从数据库获取规则
+var ErrNoRuleMapper = errors.New("未配置规则映射器")
+
+// rulePageSize 分页大小，<=0表示不分页
+func (e *engineImpl[T]) rulePageSize() int {
+	if e.config == nil {
+		return 0
+	}
+	return e.config.RulePageSize
+}
+
+// fetchRules 从数据库获取规则 - 配置分页且映射器实现PagedRuleMapper时按页获取
+//
+// 分页获取时每页的规则只保留元数据和规则条目名称（GRL置空），整页随即释放，
+// 编译时再按页读取GRL（见 buildRulePages），业务码的全部GRL不会同时驻留内存。
+func (e *engineImpl[T]) fetchRules(ctx context.Context, bizCode string) ([]*rule.Rule, error) {
+	if e.mapper == nil {
+		return nil, ErrNoRuleMapper
+	}
+	paged, ok := e.mapper.(rule.PagedRuleMapper)
+	pageSize := e.rulePageSize()
+	if !ok || pageSize <= 0 {
+		rules, err := e.mapper.FindByBizCode(ctx, bizCode)
+		if err != nil {
+			return nil, err
+		}
+		e.warnRuleCount(ctx, bizCode, len(rules))
+		return rules, nil
+	}
+
+	var rules []*rule.Rule
+	pages := 0
+	err := paged.FindByBizCodePaged(ctx, bizCode, pageSize, func(page []*rule.Rule) error {
+		pages++
+		for _, r := range page {
+			rules = append(rules, stripGRL(r))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if e.logger != nil {
+		e.logger.Debugf(ctx, "分页获取规则完成", "bizCode", bizCode, "pages", pages, "count", len(rules))
+	}
+	e.warnRuleCount(ctx, bizCode, len(rules))
+	return rules, nil
+}
+
+// stripGRL 复制规则的元数据，以规则条目名称代替GRL
+func stripGRL(r *rule.Rule) *rule.Rule {
+	stripped := *r
+	stripped.Entries = ruleEntryNames(r)
+	stripped.GRL = ""
+	return &stripped
+}
+
+// stripped 规则是否为分页获取时置空GRL的元数据
+func stripped(r *rule.Rule) bool {
+	return r.GRL == "" && r.ID != 0 && len(r.Entries) > 0
+}
+
+// ruleEntryNames 规则GRL声明的规则条目名称，分页获取的规则使用保留的名称
+func ruleEntryNames(r *rule.Rule) []string {
+	if r.GRL == "" {
+		return r.Entries
+	}
+	var names []string
+	for _, m := range grlRuleNamePattern.FindAllStringSubmatch(r.GRL, -1) {
+		names = append(names, m[1])
+	}
+	return names
+}
+
+// streamRules 依次以带GRL的规则调用fn - 分页获取的规则按业务码重新按页读取GRL，每页处理后即释放
+//
+// 读取时已不存在的规则（获取元数据后被删除或停用）跳过并记录警告日志。
+func (e *engineImpl[T]) streamRules(ctx context.Context, rules []*rule.Rule, fn func(r *rule.Rule) error) error {
+	wanted := make(map[string]map[uint64]bool)
+	var codes []string
+	for _, r := range rules {
+		if !stripped(r) {
+			if err := fn(r); err != nil {
+				return err
+			}
+			continue
+		}
+		if wanted[r.BizCode] == nil {
+			wanted[r.BizCode] = make(map[uint64]bool)
+			codes = append(codes, r.BizCode)
+		}
+		wanted[r.BizCode][r.ID] = true
+	}
+	if len(codes) == 0 {
+		return nil
+	}
+
+	paged, ok := e.mapper.(rule.PagedRuleMapper)
+	if !ok {
+		return fmt.Errorf("规则映射器不支持分页获取，无法读取规则GRL")
+	}
+	for _, code := range codes {
+		ids := wanted[code]
+		err := paged.FindByBizCodePaged(ctx, code, e.rulePageSize(), func(page []*rule.Rule) error {
+			for _, r := range page {
+				if !ids[r.ID] {
+					continue
+				}
+				delete(ids, r.ID)
+				if err := fn(r); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if len(ids) > 0 && e.logger != nil {
+			e.logger.Warnf(ctx, "分页读取GRL时部分规则已不存在", "bizCode", code, "missing", len(ids))
+		}
+	}
+	return nil
+}
+
+// hydrateRules 读取分页获取的规则的GRL，用于需要完整规则的按需分析（成本估算、数据流）
+func (e *engineImpl[T]) hydrateRules(ctx context.Context, rules []*rule.Rule) ([]*rule.Rule, error) {
+	hydrated := make(map[uint64]*rule.Rule)
+	err := e.streamRules(ctx, rules, func(r *rule.Rule) error {
+		if r.ID != 0 {
+			hydrated[r.ID] = r
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	full := make([]*rule.Rule, 0, len(rules))
+	for _, r := range rules {
+		if !stripped(r) {
+			full = append(full, r)
+		} else if h, ok := hydrated[r.ID]; ok {
+			full = append(full, h)
+		}
+	}
+	return full, nil
+}
+
+// warnRuleCount 业务码规则数量超过告警阈值时记录警告日志
+func (e *engineImpl[T]) warnRuleCount(ctx context.Context, bizCode string, count int) {
+	if e.config == nil || e.logger == nil {
+		return
+	}
+	threshold := e.config.RuleCountWarnThreshold
+	if threshold <= 0 || count <= threshold {
+		return
+	}
+	e.logger.Warnf(ctx, "业务码规则数量超过阈值", "bizCode", bizCode, "count", count, "threshold", threshold)
+}
+
+// pagedCompile 是否按页编译业务码的规则
+//
+// 成本预算检查和公共调用提取需要同时分析全部规则，启用时读取全部GRL完整编译。
+func (e *engineImpl[T]) pagedCompile(bizCode string) bool {
+	if e.rulePageSize() <= 0 {
+		return false
+	}
+	return !e.hoistCommonCalls() && !e.costCheckEnabled(bizCode)
+}
+
+// buildRulePages 按页拼接启用规则的GRL并编译，每页构建一次资源
+//
+// 分页获取的规则在此按页读取GRL，逐条检查写入声明、解析枚举与参考数据引用后拼接到当前页，
+// 编译后即释放；需要成本预算检查或公共调用提取时不按页编译（见 pagedCompile）。
+//
+// 返回值:
+//
+//	int   - 编译的规则数量
+//	error - 编译错误
+func (e *engineImpl[T]) buildRulePages(ctx context.Context, bizCode string, rules []*rule.Rule, pageSize int, hasher hash.Hash) (int, error) {
+	var (
+		grl       strings.Builder
+		pageCount int
+		pageFirst string
+		total     int
+		pageNo    int
+	)
+
+	flush := func() error {
+		if pageCount == 0 {
+			return nil
+		}
+		pageNo++
+		ruleBuilder := builder.NewRuleBuilder(e.knowledgeLibrary)
+		if err := ruleBuilder.BuildRuleFromResource(bizCode, "1.0.0", pkg.NewBytesResource([]byte(grl.String()))); err != nil {
+			return fmt.Errorf("编译第%d页规则失败（%s 等%d条）: %w", pageNo, pageFirst, pageCount, err)
+		}
+		grl.Reset()
+		pageCount = 0
+		return nil
+	}
+
+	err := e.streamRules(ctx, rules, func(r *rule.Rule) error {
+		if !r.Enabled {
+			return nil
+		}
+		if err := e.checkWriteDeclarations(bizCode, []*rule.Rule{r}); err != nil {
+			return err
+		}
+		resolved, err := e.resolveReferences([]*rule.Rule{r})
+		if err != nil {
+			return err
+		}
+		r = resolved[0]
+		total++
+		hasher.Write([]byte(r.GRL))
+
+		if pageCount == 0 {
+			pageFirst = r.Name
+		} else {
+			grl.WriteString("\n\n")
+		}
+		grl.WriteString(r.GRL)
+		pageCount++
+
+		if pageCount >= pageSize {
+			return flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if err := flush(); err != nil {
+		return 0, err
+	}
+	return total, nil
+}
@@ -0,0 +1,214 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// TestPagedRuleFetch 测试规则分页获取与按页编译
+func TestPagedRuleFetch(t *testing.T) {
+	Convey("规则分页获取", t, func() {
+		db, err := gorm.Open(sqlite.Open("file:paged_fetch.db?mode=memory&cache=shared"), &gorm.Config{})
+		So(err, ShouldBeNil)
+		So(db.AutoMigrate(&rule.Rule{}), ShouldBeNil)
+		db.Where("biz_code = ?", "paged_biz").Delete(&rule.Rule{})
+
+		for i := 0; i < 25; i++ {
+			So(db.Create(&rule.Rule{
+				BizCode: "paged_biz",
+				Name:    fmt.Sprintf("规则%d", i),
+				GRL:     fmt.Sprintf(`rule R%d "规则%d" { when Params["n"] > 0 then Result["r%d"] = true; Retract("R%d"); }`, i, i, i, i),
+				Version: i + 1,
+				Enabled: true,
+			}).Error, ShouldBeNil)
+		}
+		mapper := rule.NewRuleMapper(db)
+
+		Convey("映射器按页回调", func() {
+			var sizes []int
+			err := mapper.(rule.PagedRuleMapper).FindByBizCodePaged(context.Background(), "paged_biz", 10, func(page []*rule.Rule) error {
+				sizes = append(sizes, len(page))
+				return nil
+			})
+			So(err, ShouldBeNil)
+			So(sizes, ShouldResemble, []int{10, 10, 5})
+
+			all, err := mapper.FindByBizCode(context.Background(), "paged_biz")
+			So(err, ShouldBeNil)
+			So(all[0].Version, ShouldEqual, 25)
+		})
+
+		Convey("游标分页在版本相同时不重复不遗漏", func() {
+			db.Where("biz_code = ?", "same_version").Delete(&rule.Rule{})
+			for i := 0; i < 7; i++ {
+				So(db.Create(&rule.Rule{BizCode: "same_version", Name: fmt.Sprintf("s%d", i), GRL: "-", Version: 1 + i%2, Enabled: true}).Error, ShouldBeNil)
+			}
+
+			seen := make(map[uint64]bool)
+			var versions []int
+			err := mapper.(rule.PagedRuleMapper).FindByBizCodePaged(context.Background(), "same_version", 3, func(page []*rule.Rule) error {
+				for _, r := range page {
+					So(seen[r.ID], ShouldBeFalse)
+					seen[r.ID] = true
+					versions = append(versions, r.Version)
+				}
+				return nil
+			})
+			So(err, ShouldBeNil)
+			So(seen, ShouldHaveLength, 7)
+			So(versions, ShouldResemble, []int{2, 2, 2, 1, 1, 1, 1})
+		})
+
+		Convey("分页执行并在超过阈值时告警", func() {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockLogger := logger.NewMockLogger(ctrl)
+			mockLogger.EXPECT().Debugf(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+			mockLogger.EXPECT().Warnf(gomock.Any(), "业务码规则数量超过阈值",
+				"bizCode", "paged_biz", "count", 25, "threshold", 20).Times(1)
+
+			cfg := config.DefaultConfig()
+			cfg.RulePageSize = 10
+			cfg.RuleCountWarnThreshold = 20
+			engine := NewEngineImpl[map[string]any](
+				cfg, mapper, nil, cache.CacheKeyBuilder{}, mockLogger,
+				ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+			)
+
+			result, err := engine.Exec(context.Background(), "paged_biz", map[string]any{"n": 1})
+			So(err, ShouldBeNil)
+			So(result, ShouldHaveLength, 25)
+
+			info, ok := engine.compileInfos.Load("paged_biz")
+			So(ok, ShouldBeTrue)
+			So(info.(CompileInfo).RuleCount, ShouldEqual, 25)
+		})
+
+		Convey("分页获取的规则支持按需分析和选择执行", func() {
+			cfg := config.DefaultConfig()
+			cfg.RulePageSize = 10
+			engine := NewEngineImpl[map[string]any](
+				cfg, mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+				ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+			)
+			ctx := context.Background()
+
+			// 获取的规则只保留元数据和规则条目名称，GRL在编译时按页读取
+			rules, err := engine.loadRules(ctx, "paged_biz")
+			So(err, ShouldBeNil)
+			So(rules, ShouldHaveLength, 25)
+			for _, r := range rules {
+				So(r.GRL, ShouldBeEmpty)
+				So(r.Entries, ShouldHaveLength, 1)
+			}
+
+			result, err := engine.ExecWhere(ctx, "paged_biz", "name = '规则3'", map[string]any{"n": 1})
+			So(err, ShouldBeNil)
+			So(result, ShouldResemble, map[string]any{"r3": true})
+
+			graph, err := engine.DataFlow(ctx, "paged_biz")
+			So(err, ShouldBeNil)
+			So(graph.Nodes, ShouldHaveLength, 25)
+		})
+
+		Convey("分页获取的规则按配置的时长单独缓存", func() {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			cfg := config.DefaultConfig()
+			cfg.RulePageSize = 10
+			cfg.CacheTTL = 3 * time.Minute
+			keys := cache.CacheKeyBuilder{}
+
+			mockCache := cache.NewMockCache(ctrl)
+			mockCache.EXPECT().Get(gomock.Any(), keys.PagedRuleKey("paged_biz")).Return(nil, errors.New("miss"))
+			mockCache.EXPECT().Set(gomock.Any(), keys.PagedRuleKey("paged_biz"), gomock.Any(), 3*time.Minute).Return(nil)
+
+			engine := NewEngineImpl[map[string]any](
+				cfg, mapper, mockCache, keys, logger.NewNoopLogger(),
+				ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+			)
+			rules, err := engine.loadRules(context.Background(), "paged_biz")
+			So(err, ShouldBeNil)
+			So(rules, ShouldHaveLength, 25)
+		})
+
+		Convey("分页获取时仍检查成本预算", func() {
+			cfg := config.DefaultConfig()
+			cfg.RulePageSize = 10
+			cfg.CostCheck = config.CostCheckError
+			cfg.LatencyBudget = time.Microsecond
+			engine := NewEngineImpl[map[string]any](
+				cfg, mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+				ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+			)
+
+			_, err := engine.Exec(context.Background(), "paged_biz", map[string]any{"n": 1})
+			So(errors.Is(err, ErrCostBudgetExceeded), ShouldBeTrue)
+		})
+
+		Convey("分页获取时仍提取公共调用", func() {
+			cfg := config.DefaultConfig()
+			cfg.RulePageSize = 10
+			cfg.HoistCommonCalls = true
+			engine := NewEngineImpl[map[string]any](
+				cfg, mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+				ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+			)
+
+			result, err := engine.Exec(context.Background(), "paged_biz", map[string]any{"n": 1})
+			So(err, ShouldBeNil)
+			So(result, ShouldHaveLength, 25)
+			So(engine.pagedCompile("paged_biz"), ShouldBeFalse)
+		})
+
+		Convey("按页编译使用执行的上下文", func() {
+			cfg := config.DefaultConfig()
+			cfg.RulePageSize = 10
+			engine := NewEngineImpl[map[string]any](
+				cfg, mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+				ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+			)
+			rules, err := engine.loadRules(context.Background(), "paged_biz")
+			So(err, ShouldBeNil)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			_, err = engine.compileRules(ctx, "paged_biz", rules)
+			So(errors.Is(err, context.Canceled), ShouldBeTrue)
+		})
+
+		Convey("按页编译失败时报告页号", func() {
+			cfg := config.DefaultConfig()
+			cfg.RulePageSize = 2
+			engine := NewEngineImpl[map[string]any](
+				cfg, mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+				ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+			)
+
+			_, err := engine.compileRules(context.Background(), "broken_biz", []*rule.Rule{
+				{Name: "ok", GRL: `rule Ok "ok" { when true then Retract("Ok"); }`, Enabled: true},
+				{Name: "ok2", GRL: `rule Ok2 "ok" { when true then Retract("Ok2"); }`, Enabled: true},
+				{Name: "bad", GRL: `rule Bad { when then }`, Enabled: true},
+			})
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "编译第2页规则失败（bad 等1条）")
+		})
+	})
+}
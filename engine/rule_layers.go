@@ -0,0 +1,393 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/hyperjumptech/grule-rule-engine/builder"
+	"github.com/hyperjumptech/grule-rule-engine/pkg"
+)
+
+// ============================================================================
+// 规则分层 - 内置默认规则 < 数据库规则 < 运行时覆盖，按规则名（Rule.Name）逐层覆盖
+// ============================================================================
+//
+// 业务码的生效规则由三层合并而成，优先级从低到高:
+//  1. 内置规则（WithEmbeddedRules），随二进制附带的默认规则集
+//  2. 数据库规则，持久化的规则集
+//  3. 运行时覆盖（AddOverride），只保存在内存中，不写入数据库，可设置有效时长，
+//     设置了消息通道时广播给其他实例（见 override_sync.go）
+//
+// 每层先按运行环境选择变体，高层的同名规则覆盖低层规则；同一层内的规则不去重。
+// 合并后的规则按高层在前排列，同层保持获取顺序。Resolve 返回每条生效规则的来源层，用于排查。
+
+// RuleLayer 规则来源层
+type RuleLayer string
+
+const (
+	LayerEmbedded RuleLayer = "embedded" // 内置默认规则
+	LayerDatabase RuleLayer = "database" // 数据库规则
+	LayerOverride RuleLayer = "override" // 运行时覆盖
+)
+
+// ErrOverrideNotFound 运行时覆盖规则不存在
+var ErrOverrideNotFound = errors.New("运行时覆盖规则不存在")
+
+// ResolvedRule 生效规则及其来源
+type ResolvedRule struct {
+	BizCode   string      `json:"biz_code"`             // 规则所属业务码，启用层级继承时可能为父级业务码
+	Name      string      `json:"name"`                 // 规则名
+	Layer     RuleLayer   `json:"layer"`                // 生效规则所在层
+	Version   int         `json:"version"`              // 生效规则版本
+	Shadowed  []RuleLayer `json:"shadowed,omitempty"`   // 存在被覆盖的同名规则的低层
+	Rule      *rule.Rule  `json:"-"`                    // 生效规则
+	ExpiresAt *time.Time  `json:"expires_at,omitempty"` // 运行时覆盖的过期时间，其他层或不过期的覆盖为nil
+}
+
+// RuleResolution 业务码的规则分层解析结果
+type RuleResolution struct {
+	BizCode string         `json:"biz_code"` // 业务码
+	Rules   []ResolvedRule `json:"rules"`    // 生效规则，顺序与编译顺序一致
+}
+
+// Find 按规则名查找生效规则
+func (r *RuleResolution) Find(name string) (ResolvedRule, bool) {
+	for _, resolved := range r.Rules {
+		if resolved.Name == name {
+			return resolved, true
+		}
+	}
+	return ResolvedRule{}, false
+}
+
+// overrideEntry 运行时覆盖规则及其过期时间
+type overrideEntry struct {
+	rule      *rule.Rule
+	expiresAt time.Time   // 为零值表示不过期
+	timer     *time.Timer // 到期移除定时器
+}
+
+// ruleOverrides 运行时覆盖规则 - 业务码 -> 覆盖规则，按添加顺序排列
+type ruleOverrides struct {
+	mu    sync.RWMutex
+	rules map[string][]*overrideEntry
+}
+
+// set 添加或替换同名覆盖规则，设置了过期时间时到期自动移除并调用onExpire
+func (o *ruleOverrides) set(bizCode string, entry *overrideEntry, onExpire func()) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.rules == nil {
+		o.rules = make(map[string][]*overrideEntry)
+	}
+	if !entry.expiresAt.IsZero() {
+		entry.timer = time.AfterFunc(time.Until(entry.expiresAt), func() {
+			if o.expire(bizCode, entry) {
+				onExpire()
+			}
+		})
+	}
+
+	entries := o.rules[bizCode]
+	for i, existing := range entries {
+		if existing.rule.Name == entry.rule.Name {
+			existing.stop()
+			entries[i] = entry
+			return
+		}
+	}
+	o.rules[bizCode] = append(entries, entry)
+}
+
+// remove 移除覆盖规则，返回是否存在
+func (o *ruleOverrides) remove(bizCode, name string) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.removeLocked(bizCode, func(entry *overrideEntry) bool { return entry.rule.Name == name })
+}
+
+// expire 移除到期的覆盖规则，已被替换或移除时返回false
+func (o *ruleOverrides) expire(bizCode string, expired *overrideEntry) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.removeLocked(bizCode, func(entry *overrideEntry) bool { return entry == expired })
+}
+
+// removeLocked 移除第一条匹配的覆盖规则，调用方持有写锁
+func (o *ruleOverrides) removeLocked(bizCode string, match func(*overrideEntry) bool) bool {
+	entries := o.rules[bizCode]
+	for i, entry := range entries {
+		if !match(entry) {
+			continue
+		}
+		entry.stop()
+		entries = append(entries[:i:i], entries[i+1:]...)
+		if len(entries) == 0 {
+			delete(o.rules, bizCode)
+		} else {
+			o.rules[bizCode] = entries
+		}
+		return true
+	}
+	return false
+}
+
+// list 业务码的覆盖规则快照
+func (o *ruleOverrides) list(bizCode string) []*rule.Rule {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	rules := make([]*rule.Rule, 0, len(o.rules[bizCode]))
+	for _, entry := range o.rules[bizCode] {
+		rules = append(rules, entry.rule)
+	}
+	return rules
+}
+
+// expiry 覆盖规则的过期时间，不存在或不过期时返回零值
+func (o *ruleOverrides) expiry(bizCode, name string) time.Time {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	for _, entry := range o.rules[bizCode] {
+		if entry.rule.Name == name {
+			return entry.expiresAt
+		}
+	}
+	return time.Time{}
+}
+
+// clear 移除全部覆盖规则并停止到期定时器
+func (o *ruleOverrides) clear() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for _, entries := range o.rules {
+		for _, entry := range entries {
+			entry.stop()
+		}
+	}
+	o.rules = nil
+}
+
+// stop 停止到期定时器
+func (entry *overrideEntry) stop() {
+	if entry.timer != nil {
+		entry.timer.Stop()
+	}
+}
+
+// AddOverride 添加运行时覆盖规则 - 立即生效，覆盖内置和数据库中的同名规则，到期自动移除
+//
+// 覆盖规则只保存在内存中，不写入数据库；同一业务码下同名的覆盖规则被替换。
+// 覆盖规则的GRL大小和条件嵌套深度受业务码的规则集限制约束。
+// 设置了消息通道（SetPubSub）时广播给其他实例，广播失败时本实例已生效，返回的错误包含失败原因。
+//
+// 参数:
+//
+//	ctx     - 上下文
+//	bizCode - 业务码
+//	r       - 覆盖规则，Name 和 GRL 必填，业务码以参数为准
+//	ttl     - 有效时长，为0表示不过期（直到移除或实例重启）
+//
+// 返回值:
+//
+//	error - 参数无效、超出规则集限制、GRL编译失败、引擎为只读模式或广播失败
+func (e *engineImpl[T]) AddOverride(ctx context.Context, bizCode string, r *rule.Rule, ttl time.Duration) error {
+	if err := e.checkWritable("添加运行时覆盖"); err != nil {
+		return err
+	}
+	if bizCode == "" {
+		return fmt.Errorf("业务码不能为空")
+	}
+	if r == nil || r.Name == "" || r.GRL == "" {
+		return fmt.Errorf("覆盖规则的名称和GRL不能为空")
+	}
+	if ttl < 0 {
+		return fmt.Errorf("覆盖规则有效时长不能为负数，当前为 %s", ttl)
+	}
+	if err := e.CheckRuleLimits(bizCode, []*rule.Rule{r}); err != nil {
+		return err
+	}
+
+	// 单独编译一次，避免无效的覆盖规则导致整个业务码编译失败
+	grl, err := e.references.Resolve(r.GRL)
+	if err != nil {
+		return fmt.Errorf("编译覆盖规则 %s 失败: %w", r.Name, err)
+	}
+	ruleBuilder := builder.NewRuleBuilder(ast.NewKnowledgeLibrary())
+	if err := ruleBuilder.BuildRuleFromResource(bizCode, "1.0.0", pkg.NewBytesResource([]byte(grl))); err != nil {
+		return fmt.Errorf("编译覆盖规则 %s 失败: %w", r.Name, err)
+	}
+
+	override := *r
+	override.BizCode = bizCode
+	override.Enabled = true
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl)
+	}
+	e.applyOverride(ctx, bizCode, &override, expiresAt)
+
+	return e.publishOverride(ctx, overrideEvent{
+		Op:        overrideAdd,
+		BizCode:   bizCode,
+		Name:      override.Name,
+		Rule:      &override,
+		ExpiresAt: expiresAt,
+	})
+}
+
+// applyOverride 在本实例添加覆盖规则并清理编译缓存
+func (e *engineImpl[T]) applyOverride(ctx context.Context, bizCode string, r *rule.Rule, expiresAt time.Time) {
+	if !expiresAt.IsZero() && !expiresAt.After(time.Now()) {
+		return
+	}
+	e.overrides.set(bizCode, &overrideEntry{rule: r, expiresAt: expiresAt}, func() {
+		e.dropKnowledgeBase(bizCode)
+		e.invalidateDescendants(bizCode)
+		if e.logger != nil {
+			e.logger.Infof(context.Background(), "运行时覆盖规则已到期", "bizCode", bizCode, "rule", r.Name)
+		}
+	})
+	e.dropKnowledgeBase(bizCode)
+	e.invalidateDescendants(bizCode)
+
+	if e.logger != nil {
+		e.logger.Infof(ctx, "添加运行时覆盖规则", "bizCode", bizCode, "rule", r.Name, "expiresAt", expiresAt)
+	}
+}
+
+// RemoveOverride 移除运行时覆盖规则，被覆盖的同名规则重新生效
+//
+// 设置了消息通道时同时广播给其他实例。
+//
+// 返回值:
+//
+//	error - 本实例不存在该覆盖规则时返回ErrOverrideNotFound，引擎为只读模式时返回ErrReadOnly，广播失败时返回失败原因
+func (e *engineImpl[T]) RemoveOverride(ctx context.Context, bizCode, name string) error {
+	if err := e.checkWritable("移除运行时覆盖"); err != nil {
+		return err
+	}
+	// 本实例不存在时仍然广播，其他实例可能在本实例启动前收到了该覆盖规则
+	found := e.revokeOverride(ctx, bizCode, name)
+	if err := e.publishOverride(ctx, overrideEvent{Op: overrideRemove, BizCode: bizCode, Name: name}); err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("业务码 %s 的规则 %s: %w", bizCode, name, ErrOverrideNotFound)
+	}
+	return nil
+}
+
+// revokeOverride 在本实例移除覆盖规则并清理编译缓存，返回是否存在
+func (e *engineImpl[T]) revokeOverride(ctx context.Context, bizCode, name string) bool {
+	if !e.overrides.remove(bizCode, name) {
+		return false
+	}
+	e.dropKnowledgeBase(bizCode)
+	e.invalidateDescendants(bizCode)
+
+	if e.logger != nil {
+		e.logger.Infof(ctx, "移除运行时覆盖规则", "bizCode", bizCode, "rule", name)
+	}
+	return true
+}
+
+// Resolve 解析业务码的生效规则及每条规则的来源层
+//
+// 启用业务码层级继承时包含父级业务码的规则，子级同名规则优先。
+// 配置分页获取时数据库规则只有元数据，GRL为空，规则条目名称见 Rule.Entries。
+//
+// 返回值:
+//
+//	*RuleResolution - 解析结果
+//	error           - 获取数据库规则失败
+func (e *engineImpl[T]) Resolve(ctx context.Context, bizCode string) (*RuleResolution, error) {
+	chain := []string{bizCode}
+	if e.inheritanceEnabled() {
+		chain = bizCodeChain(bizCode)
+	}
+
+	resolution := &RuleResolution{BizCode: bizCode, Rules: []ResolvedRule{}}
+	seen := make(map[string]bool)
+	for _, code := range chain {
+		database, err := e.loadRules(ctx, code)
+		if err != nil {
+			return nil, err
+		}
+		for _, resolved := range e.resolveLayers(code, database) {
+			if seen[resolved.Name] {
+				continue
+			}
+			seen[resolved.Name] = true
+			resolution.Rules = append(resolution.Rules, resolved)
+		}
+	}
+	return resolution, nil
+}
+
+// resolveLayers 合并业务码的三层规则，记录每条生效规则的来源层
+func (e *engineImpl[T]) resolveLayers(bizCode string, database []*rule.Rule) []ResolvedRule {
+	layers := []struct {
+		layer RuleLayer
+		rules []*rule.Rule
+	}{
+		{LayerOverride, e.selectEnvironment(e.overrides.list(bizCode))},
+		{LayerDatabase, database},
+		{LayerEmbedded, e.selectEnvironment(e.embedded[bizCode])},
+	}
+
+	var resolved []ResolvedRule
+	index := make(map[string]int)
+	for _, l := range layers {
+		names := make(map[string]int)
+		for _, r := range l.rules {
+			if i, ok := index[r.Name]; ok {
+				if shadowed := resolved[i].Shadowed; len(shadowed) == 0 || shadowed[len(shadowed)-1] != l.layer {
+					resolved[i].Shadowed = append(shadowed, l.layer)
+				}
+				continue
+			}
+			if _, ok := names[r.Name]; !ok {
+				names[r.Name] = len(resolved)
+			}
+			resolved = append(resolved, ResolvedRule{
+				BizCode: bizCode,
+				Name:    r.Name,
+				Layer:   l.layer,
+				Version: r.Version,
+				Rule:    r,
+			})
+			if l.layer == LayerOverride {
+				if expiresAt := e.overrides.expiry(bizCode, r.Name); !expiresAt.IsZero() {
+					resolved[len(resolved)-1].ExpiresAt = &expiresAt
+				}
+			}
+		}
+		for name, i := range names {
+			index[name] = i
+		}
+	}
+	return resolved
+}
+
+// layerRules 合并业务码的生效规则，没有内置规则和覆盖规则时直接返回数据库规则
+func (e *engineImpl[T]) layerRules(bizCode string, database []*rule.Rule) []*rule.Rule {
+	if len(e.embedded[bizCode]) == 0 && len(e.overrides.list(bizCode)) == 0 {
+		return database
+	}
+
+	resolved := e.resolveLayers(bizCode, database)
+	rules := make([]*rule.Rule, len(resolved))
+	for i, r := range resolved {
+		rules[i] = r.Rule
+	}
+	return rules
+}
@@ -0,0 +1,157 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+// TestRuleLayers 测试规则分层和运行时覆盖
+func TestRuleLayers(t *testing.T) {
+	Convey("规则分层", t, func() {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		ctx := context.Background()
+
+		mapper := rule.NewMockRuleMapper(ctrl)
+		mapper.EXPECT().FindByBizCode(gomock.Any(), "loan").Return([]*rule.Rule{
+			{BizCode: "loan", Name: "limit", Enabled: true, Version: 3,
+				GRL: `rule DBLimit "数据库额度" { when true then Result["limit"] = 500; Retract("DBLimit"); }`},
+			{BizCode: "loan", Name: "score", Enabled: true, Version: 2,
+				GRL: `rule DBScore "数据库评分" { when true then Result["score"] = 700; Retract("DBScore"); }`},
+		}, nil).AnyTimes()
+
+		cfg := config.DefaultConfig()
+		engine := NewEngineImpl[map[string]any](
+			cfg, mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+			ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+		)
+		defer engine.Close()
+
+		engine.SetEmbeddedRules([]*rule.Rule{
+			{BizCode: "loan", Name: "limit", Enabled: true, Version: 1,
+				GRL: `rule Limit "默认额度" { when true then Result["limit"] = 100; Retract("Limit"); }`},
+			{BizCode: "loan", Name: "grade", Enabled: true, Version: 1,
+				GRL: `rule Grade "默认等级" { when true then Result["grade"] = "B"; Retract("Grade"); }`},
+		})
+
+		override := &rule.Rule{Name: "score",
+			GRL: `rule OverrideScore "临时评分" { when true then Result["score"] = 0; Retract("OverrideScore"); }`}
+
+		Convey("运行时覆盖立即生效，移除后恢复数据库规则", func() {
+			result, err := engine.Exec(ctx, "loan", map[string]any{})
+			So(err, ShouldBeNil)
+			So(result["score"], ShouldEqual, 700)
+
+			So(engine.AddOverride(ctx, "loan", override, 0), ShouldBeNil)
+			result, err = engine.Exec(ctx, "loan", map[string]any{})
+			So(err, ShouldBeNil)
+			So(result["score"], ShouldEqual, 0)
+			So(result["limit"], ShouldEqual, 500)
+			So(result["grade"], ShouldEqual, "B")
+
+			So(engine.RemoveOverride(ctx, "loan", "score"), ShouldBeNil)
+			result, err = engine.Exec(ctx, "loan", map[string]any{})
+			So(err, ShouldBeNil)
+			So(result["score"], ShouldEqual, 700)
+		})
+
+		Convey("Resolve 报告每条生效规则的来源层", func() {
+			So(engine.AddOverride(ctx, "loan", override, 0), ShouldBeNil)
+			So(engine.AddOverride(ctx, "loan", &rule.Rule{Name: "limit",
+				GRL: `rule OverrideLimit "临时额度" { when true then Result["limit"] = 1; Retract("OverrideLimit"); }`}, 0), ShouldBeNil)
+
+			resolution, err := engine.Resolve(ctx, "loan")
+			So(err, ShouldBeNil)
+			So(resolution.Rules, ShouldHaveLength, 3)
+
+			limit, ok := resolution.Find("limit")
+			So(ok, ShouldBeTrue)
+			So(limit.Layer, ShouldEqual, LayerOverride)
+			So(limit.Shadowed, ShouldResemble, []RuleLayer{LayerDatabase, LayerEmbedded})
+
+			score, _ := resolution.Find("score")
+			So(score.Layer, ShouldEqual, LayerOverride)
+			So(score.Shadowed, ShouldResemble, []RuleLayer{LayerDatabase})
+			So(score.Rule.BizCode, ShouldEqual, "loan")
+
+			grade, _ := resolution.Find("grade")
+			So(grade.Layer, ShouldEqual, LayerEmbedded)
+			So(grade.Shadowed, ShouldBeEmpty)
+
+			_, ok = resolution.Find("missing")
+			So(ok, ShouldBeFalse)
+		})
+
+		Convey("同名覆盖规则被替换", func() {
+			So(engine.AddOverride(ctx, "loan", override, 0), ShouldBeNil)
+			So(engine.AddOverride(ctx, "loan", &rule.Rule{Name: "score",
+				GRL: `rule OverrideScore "临时评分" { when true then Result["score"] = 1; Retract("OverrideScore"); }`}, 0), ShouldBeNil)
+			So(engine.overrides.list("loan"), ShouldHaveLength, 1)
+
+			result, err := engine.Exec(ctx, "loan", map[string]any{})
+			So(err, ShouldBeNil)
+			So(result["score"], ShouldEqual, 1)
+		})
+
+		Convey("有效时长到期后自动移除", func() {
+			So(engine.AddOverride(ctx, "loan", override, 50*time.Millisecond), ShouldBeNil)
+			resolution, err := engine.Resolve(ctx, "loan")
+			So(err, ShouldBeNil)
+			score, _ := resolution.Find("score")
+			So(score.Layer, ShouldEqual, LayerOverride)
+			So(score.ExpiresAt, ShouldNotBeNil)
+
+			result, err := engine.Exec(ctx, "loan", map[string]any{})
+			So(err, ShouldBeNil)
+			So(result["score"], ShouldEqual, 0)
+
+			So(func() bool {
+				deadline := time.Now().Add(2 * time.Second)
+				for len(engine.overrides.list("loan")) > 0 && time.Now().Before(deadline) {
+					time.Sleep(10 * time.Millisecond)
+				}
+				return len(engine.overrides.list("loan")) == 0
+			}(), ShouldBeTrue)
+			result, err = engine.Exec(ctx, "loan", map[string]any{})
+			So(err, ShouldBeNil)
+			So(result["score"], ShouldEqual, 700)
+		})
+
+		Convey("替换后旧的到期定时器不再生效", func() {
+			So(engine.AddOverride(ctx, "loan", override, 20*time.Millisecond), ShouldBeNil)
+			So(engine.AddOverride(ctx, "loan", override, 0), ShouldBeNil)
+			time.Sleep(60 * time.Millisecond)
+			So(engine.overrides.list("loan"), ShouldHaveLength, 1)
+		})
+
+		Convey("拒绝无效的覆盖规则", func() {
+			So(engine.AddOverride(ctx, "", override, 0), ShouldNotBeNil)
+			So(engine.AddOverride(ctx, "loan", &rule.Rule{Name: "score"}, 0), ShouldNotBeNil)
+			So(engine.AddOverride(ctx, "loan", &rule.Rule{Name: "score", GRL: "rule Broken {"}, 0), ShouldNotBeNil)
+			So(engine.AddOverride(ctx, "loan", override, -time.Second), ShouldNotBeNil)
+			So(engine.overrides.list("loan"), ShouldBeEmpty)
+		})
+
+		Convey("移除不存在的覆盖规则返回ErrOverrideNotFound", func() {
+			err := engine.RemoveOverride(ctx, "loan", "score")
+			So(errors.Is(err, ErrOverrideNotFound), ShouldBeTrue)
+		})
+
+		Convey("只读模式拒绝运行时覆盖", func() {
+			cfg.ReadOnly = true
+			So(errors.Is(engine.AddOverride(ctx, "loan", override, 0), ErrReadOnly), ShouldBeTrue)
+		})
+	})
+}
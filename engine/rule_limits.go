@@ -0,0 +1,76 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gitee.com/damengde/runehammer/config"
+	"gitee.com/damengde/runehammer/rule"
+)
+
+// ============================================================================
+// 规则集限制 - 在发布、复制和导入规则时检查规模与复杂度，保护共享平台不受单个租户的病态规则集影响
+// ============================================================================
+//
+// 限制按租户（业务码前缀）配置：acme.payments.cards 依次查找 acme.payments.cards、acme.payments、acme
+// 的 Config.TenantRuleLimits，均未配置时使用 Config.RuleLimits。
+
+// ErrRuleLimitExceeded 规则集超出规模或复杂度限制，可通过errors.Is判断，具体超限项通过errors.As获取*RuleLimitError
+var ErrRuleLimitExceeded = errors.New("规则集超出限制")
+
+// RuleLimitError 规则集超限错误 - 列出全部超限项
+type RuleLimitError struct {
+	BizCode    string                // 业务码
+	Violations []rule.LimitViolation // 超限项
+}
+
+// Error 实现error接口
+func (e *RuleLimitError) Error() string {
+	parts := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		parts = append(parts, v.String())
+	}
+	return fmt.Sprintf("%s: 业务码 %s %s", ErrRuleLimitExceeded, e.BizCode, strings.Join(parts, "; "))
+}
+
+// Unwrap 支持errors.Is(err, ErrRuleLimitExceeded)
+func (e *RuleLimitError) Unwrap() error {
+	return ErrRuleLimitExceeded
+}
+
+// checkRuleLimits 检查规则集是否超出限制，超出时返回*RuleLimitError
+func checkRuleLimits(bizCode string, rules []*rule.Rule, limits config.RuleLimits) error {
+	violations := rule.CheckLimits(rules, limits)
+	if len(violations) == 0 {
+		return nil
+	}
+	return &RuleLimitError{BizCode: bizCode, Violations: violations}
+}
+
+// ruleLimits 获取业务码的规则集限制
+func (e *engineImpl[T]) ruleLimits(bizCode string) config.RuleLimits {
+	if e.config == nil {
+		return config.RuleLimits{}
+	}
+	for _, code := range bizCodeChain(bizCode) {
+		if limits, ok := e.config.TenantRuleLimits[code]; ok {
+			return limits
+		}
+	}
+	return e.config.RuleLimits
+}
+
+// CheckRuleLimits 检查候选规则集是否超出业务码的规模与复杂度限制 - 供应用在自己的发布流程中调用
+//
+// 参数:
+//
+//	bizCode - 业务码，用于查找租户的限制
+//	rules   - 发布后业务码的完整规则集（含禁用规则）
+//
+// 返回值:
+//
+//	error - 超出限制时返回*RuleLimitError
+func (e *engineImpl[T]) CheckRuleLimits(bizCode string, rules []*rule.Rule) error {
+	return checkRuleLimits(bizCode, rules, e.ruleLimits(bizCode))
+}
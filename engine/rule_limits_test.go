@@ -0,0 +1,102 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// TestRuleLimits 测试规则集限制
+func TestRuleLimits(t *testing.T) {
+	Convey("规则集限制", t, func() {
+		db, err := gorm.Open(sqlite.Open("file:rule_limits.db?mode=memory&cache=shared"), &gorm.Config{})
+		So(err, ShouldBeNil)
+		So(db.AutoMigrate(&rule.Rule{}), ShouldBeNil)
+		db.Where("1 = 1").Delete(&rule.Rule{})
+
+		seed := []*rule.Rule{
+			{BizCode: "template.payments", Name: "limit", GRL: `rule Limit "限额" { when true then Result["limit"] = 1000; Retract("Limit"); }`, Version: 1, Enabled: true},
+			{BizCode: "template.payments", Name: "risk", GRL: `rule Risk "风险" { when (Params.a > 0 && (Params.b > 0)) then Result["risk"] = true; Retract("Risk"); }`, Version: 1, Enabled: true},
+		}
+		So(db.Create(&seed).Error, ShouldBeNil)
+
+		cfg := config.DefaultConfig()
+		cfg.RuleLimits = config.RuleLimits{MaxRules: 10, MaxConditionDepth: 5}
+		cfg.TenantRuleLimits = map[string]config.RuleLimits{"trial": {MaxRules: 1, MaxConditionDepth: 2}}
+		engine := NewEngineImpl[map[string]any](
+			cfg, rule.NewRuleMapper(db), nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+			ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+		)
+		defer engine.Close()
+		ctx := context.Background()
+
+		Convey("按租户前缀查找最近的限制", func() {
+			So(engine.ruleLimits("trial.payments.cards").MaxRules, ShouldEqual, 1)
+			So(engine.ruleLimits("acme.payments").MaxRules, ShouldEqual, 10)
+
+			err := engine.CheckRuleLimits("trial.payments", seed)
+			var limitErr *RuleLimitError
+			So(errors.As(err, &limitErr), ShouldBeTrue)
+			So(limitErr.BizCode, ShouldEqual, "trial.payments")
+			So(limitErr.Violations, ShouldHaveLength, 2)
+			So(errors.Is(err, ErrRuleLimitExceeded), ShouldBeTrue)
+			So(ErrorCode(err), ShouldEqual, ErrCodeRuleLimitExceeded)
+			So(err.Error(), ShouldContainSubstring, "规则数 2 超过上限 1")
+
+			So(engine.CheckRuleLimits("acme.payments", seed), ShouldBeNil)
+		})
+
+		Convey("复制后超出目标租户的限制时不写入任何规则", func() {
+			_, err := engine.CloneBizCodeDryRun(ctx, "template", "trial", "payments")
+			So(errors.Is(err, ErrRuleLimitExceeded), ShouldBeTrue)
+
+			_, err = engine.CloneBizCode(ctx, "template", "trial", "payments")
+			So(errors.Is(err, ErrRuleLimitExceeded), ShouldBeTrue)
+			var count int64
+			db.Model(&rule.Rule{}).Where("biz_code = ?", "trial.payments").Count(&count)
+			So(count, ShouldEqual, 0)
+
+			report, err := engine.CloneBizCode(ctx, "template", "acme", "payments")
+			So(err, ShouldBeNil)
+			So(report.Cloned, ShouldHaveLength, 2)
+		})
+
+		Convey("运行时覆盖规则超出限制时拒绝", func() {
+			err := engine.AddOverride(ctx, "trial.payments", seed[1], 0)
+			So(errors.Is(err, ErrRuleLimitExceeded), ShouldBeTrue)
+			So(engine.AddOverride(ctx, "acme.payments", seed[1], 0), ShouldBeNil)
+		})
+	})
+}
+
+// TestImportBundleRuleLimits 测试导入规则包时检查规则集限制
+func TestImportBundleRuleLimits(t *testing.T) {
+	Convey("导入规则包时检查规则集限制", t, func() {
+		bundle := rule.NewRuleBundle("loan", []*rule.Rule{
+			{Name: "a", GRL: `rule A "A" { when true then Retract("A"); }`},
+			{Name: "b", GRL: `rule B "B" { when true then Retract("B"); }`},
+		})
+		data, err := bundle.ToJSON()
+		So(err, ShouldBeNil)
+
+		engine := NewDynamicEngine[map[string]interface{}](DynamicEngineConfig{RuleLimits: config.RuleLimits{MaxRules: 1}})
+		_, err = engine.ImportBundle(context.Background(), data)
+		So(errors.Is(err, ErrRuleLimitExceeded), ShouldBeTrue)
+
+		engine = NewDynamicEngine[map[string]interface{}](DynamicEngineConfig{RuleLimits: config.RuleLimits{MaxRules: 2}})
+		imported, err := engine.ImportBundle(context.Background(), data)
+		So(err, ShouldBeNil)
+		So(imported.Rules, ShouldHaveLength, 2)
+	})
+}
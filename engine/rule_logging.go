@@ -0,0 +1,225 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"gitee.com/damengde/runehammer/alert"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+)
+
+// ============================================================================
+// 规则日志与告警 - 将GRL中的 Log/Alert 调用路由到引擎日志记录器
+// ============================================================================
+//
+// Grule将独立函数调用（如 Log("...")）解析为内置函数对象DEFUNC的方法，Log默认输出到Grule自身的日志。
+// 执行时以 ruleFunctions 替换DEFUNC：覆盖日志和告警方法，其余内置函数仍由Grule提供。
+// 消息中的 ${path} 模板在执行时按数据上下文取值，如 ${user.id}、${Params.amount}、${Result.score}，
+// $${path} 输出字面量 ${path}。赋值的模板字符串由转换器生成 Template("...") 调用，同样在执行时插值。
+// 配置告警通道（SetAlertSink）时，Alert 在输出日志的同时发送到通道。
+
+// ruleFunctions 替换Grule内置函数对象，覆盖日志与告警函数并提供模板插值
+type ruleFunctions struct {
+	*ast.BuiltInFunctions
+
+	ctx     context.Context
+	logger  logger.Logger
+	bizCode string
+	data    ast.IDataContext
+	rule    string          // 正在执行动作的规则名，每个周期开始时清空
+	failed  string          // 首个内置函数错误发生时执行动作的规则名，条件中出错时为空
+	sink    alert.Sink      // 告警通道，为nil时只输出日志
+	clock   Clock           // 时间函数使用的时钟，为nil时使用系统时间
+	locale  string          // 格式化函数的默认区域
+	json    jsonCache       // 本次执行的JSON解析缓存
+	reasons reasonCollector // 本次执行记录的原因码
+	emitter resultEmitter   // 本次执行的结果流式输出
+	rates   *rateCache      // 货币换算使用的汇率缓存，为nil时只能换算相同货币
+
+	maxSimilarityLength int              // 相似度函数的最大输入长度，<=0时取默认值
+	calendar            CalendarProvider // 日历函数使用的节假日日历，为nil时执行返回错误
+	guard               functionGuard    // 内置函数参数错误，条件中出错时Grule视为不成立，执行后以该错误为准
+}
+
+// newRuleFunctions 创建规则函数对象
+func newRuleFunctions(ctx context.Context, log logger.Logger, bizCode string) *ruleFunctions {
+	if log == nil {
+		log = logger.NewNoopLogger()
+	}
+	return &ruleFunctions{ctx: ctx, logger: log, bizCode: bizCode}
+}
+
+// ruleFunctions 创建引擎执行使用的规则函数对象 - 时钟、区域、汇率和日历取自引擎配置
+//
+// 线上执行与规则测试用例共用，告警通道和结果流由线上执行另行设置。
+func (e *engineImpl[T]) ruleFunctions(ctx context.Context, bizCode string) *ruleFunctions {
+	functions := newRuleFunctions(ctx, e.logger, bizCode)
+	functions.clock = e.clock
+	functions.locale = e.config.Locale
+	functions.maxSimilarityLength = e.config.SimilarityMaxLength
+	functions.rates = e.rates
+	functions.calendar = e.calendar
+	return functions
+}
+
+// raise 记录内置函数错误并中断求值 - 条件中被Grule视为不成立时仍以该错误结束执行
+func (f *ruleFunctions) raise(name string, err error) {
+	err = fmt.Errorf("%s: %w", name, err)
+	if f.guard.Err() == nil {
+		f.failed = f.rule
+	}
+	f.guard.fail(err)
+	panic(err)
+}
+
+// SetAlertSink 设置规则告警通道 - 规则动作中的 Alert 调用在输出日志的同时发送到该通道
+func (e *engineImpl[T]) SetAlertSink(sink alert.Sink) {
+	e.alertSink = sink
+}
+
+// Log 记录信息级别日志
+func (f *ruleFunctions) Log(message string) {
+	msg, keyvals := f.payload(message)
+	f.logger.Infof(f.ctx, msg, keyvals...)
+}
+
+// LogDebug 记录调试级别日志
+func (f *ruleFunctions) LogDebug(message string) {
+	msg, keyvals := f.payload(message)
+	f.logger.Debugf(f.ctx, msg, keyvals...)
+}
+
+// LogWarn 记录警告级别日志
+func (f *ruleFunctions) LogWarn(message string) {
+	msg, keyvals := f.payload(message)
+	f.logger.Warnf(f.ctx, msg, keyvals...)
+}
+
+// LogError 记录错误级别日志
+func (f *ruleFunctions) LogError(message string) {
+	msg, keyvals := f.payload(message)
+	f.logger.Errorf(f.ctx, msg, keyvals...)
+}
+
+// Alert 发送告警 - 以警告级别日志输出，附加 alert=true，配置告警通道时同时发送到通道
+func (f *ruleFunctions) Alert(message string) {
+	msg, keyvals := f.payload(message)
+	f.logger.Warnf(f.ctx, msg, append(keyvals, "alert", true)...)
+	if f.sink == nil {
+		return
+	}
+
+	fields := make(map[string]any)
+	for i := 4; i+1 < len(keyvals); i += 2 {
+		fields[keyvals[i].(string)] = keyvals[i+1]
+	}
+	a := alert.Alert{BizCode: f.bizCode, Rule: f.rule, Message: msg, Fields: fields, Time: time.Now()}
+	if err := f.sink.Send(f.ctx, a); err != nil {
+		f.logger.Errorf(f.ctx, "告警发送失败", "bizCode", f.bizCode, "rule", f.rule, "error", err)
+	}
+}
+
+// Template 插值模板字符串，用于赋值动作
+func (f *ruleFunctions) Template(text string) string {
+	return f.interpolate(text, nil)
+}
+
+// payload 插值消息并生成结构化字段：业务码、规则名和模板变量的取值
+func (f *ruleFunctions) payload(message string) (string, []any) {
+	keyvals := []any{"bizCode", f.bizCode, "rule", f.rule}
+	seen := make(map[string]bool)
+	msg := f.interpolate(message, func(path string, value any) {
+		if !seen[path] {
+			seen[path] = true
+			keyvals = append(keyvals, path, value)
+		}
+	})
+	return msg, keyvals
+}
+
+// interpolate 替换文本中的模板变量 - 无法取值的保留原文，转义的输出字面量，resolved 接收每次取到的值
+func (f *ruleFunctions) interpolate(text string, resolved func(path string, value any)) string {
+	return rule.TemplatePattern.ReplaceAllStringFunc(text, func(match string) string {
+		if rule.IsEscapedTemplate(match) {
+			return match[1:]
+		}
+		path := rule.TemplatePattern.FindStringSubmatch(match)[1]
+		value, ok := f.resolve(path)
+		if !ok {
+			return match
+		}
+		if resolved != nil {
+			resolved(path, value)
+		}
+		return fmt.Sprint(value)
+	})
+}
+
+// resolve 按路径取值 - 首段为数据上下文中的名称（Params、Result、结构体事实名）时从该对象取值，否则从Params取值
+func (f *ruleFunctions) resolve(path string) (any, bool) {
+	if f.data == nil {
+		return nil, false
+	}
+	root, rest, _ := strings.Cut(path, ".")
+	if value, ok := f.fact(root); ok {
+		if rest == "" {
+			return value, true
+		}
+		return lookupPath(value, rest)
+	}
+	if params, ok := f.fact("Params"); ok {
+		return lookupPath(params, path)
+	}
+	return nil, false
+}
+
+// fact 获取数据上下文中的对象
+func (f *ruleFunctions) fact(name string) (any, bool) {
+	node := f.data.Get(name)
+	if node == nil {
+		return nil, false
+	}
+	value := node.Value()
+	if !value.IsValid() || !value.CanInterface() {
+		return nil, false
+	}
+	return value.Interface(), true
+}
+
+// EvaluateRuleEntry 实现GruleEngineListener
+func (f *ruleFunctions) EvaluateRuleEntry(cycle uint64, entry *ast.RuleEntry, candidate bool) {}
+
+// ExecuteRuleEntry 实现GruleEngineListener - 记录正在执行动作的规则
+func (f *ruleFunctions) ExecuteRuleEntry(cycle uint64, entry *ast.RuleEntry) {
+	f.rule = entry.RuleName
+}
+
+// BeginCycle 实现GruleEngineListener - 周期开始后先求值条件，清空规则名以区分条件和动作中的错误
+func (f *ruleFunctions) BeginCycle(cycle uint64) {
+	f.rule = ""
+}
+
+// wrap 包装数据上下文，Grule注入内置函数对象时替换为ruleFunctions
+func (f *ruleFunctions) wrap(dataCtx ast.IDataContext) ast.IDataContext {
+	f.data = dataCtx
+	return &ruleFunctionsContext{IDataContext: dataCtx, functions: f}
+}
+
+// ruleFunctionsContext 替换内置函数对象的数据上下文
+type ruleFunctionsContext struct {
+	ast.IDataContext
+	functions *ruleFunctions
+}
+
+// Add 实现ast.IDataContext - 以ruleFunctions替换Grule注入的DEFUNC
+func (c *ruleFunctionsContext) Add(key string, obj interface{}) error {
+	if builtins, ok := obj.(*ast.BuiltInFunctions); ok && key == "DEFUNC" {
+		c.functions.BuiltInFunctions = builtins
+		return c.IDataContext.Add(key, c.functions)
+	}
+	return c.IDataContext.Add(key, obj)
+}
@@ -0,0 +1,143 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"gitee.com/damengde/runehammer/alert"
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+// Applicant 规则日志测试用的结构体输入
+type Applicant struct {
+	ID    string
+	Score int
+}
+
+// TestRuleLogging 测试规则日志与告警
+func TestRuleLogging(t *testing.T) {
+	Convey("规则日志与告警", t, func() {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		type traceKey struct{}
+		ctx := context.WithValue(context.Background(), traceKey{}, "trace-1")
+		traced := gomock.Cond(func(x any) bool {
+			c, ok := x.(context.Context)
+			return ok && c.Value(traceKey{}) == "trace-1"
+		})
+
+		log := logger.NewMockLogger(ctrl)
+		log.EXPECT().Debugf(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+		log.EXPECT().Infof(gomock.Any(), "规则引擎已关闭").AnyTimes()
+		mapper := rule.NewMockRuleMapper(ctrl)
+		newEngine := func() *engineImpl[map[string]any] {
+			return NewEngineImpl[map[string]any](
+				config.DefaultConfig(), mapper, nil, cache.CacheKeyBuilder{}, log,
+				ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+			)
+		}
+
+		Convey("按级别输出并插值模板变量", func() {
+			mapper.EXPECT().FindByBizCode(gomock.Any(), "loan").Return([]*rule.Rule{
+				{Name: "notify", Enabled: true, GRL: `rule Notify "通知" salience 10 {
+	when Params["amount"] > 0
+	then
+		Result["score"] = 720;
+		Log("用户 ${user.id} 申请 ${amount}");
+		LogWarn("金额 ${Params.amount} 超过 ${Params.limit}");
+		Alert("评分 ${Result.score} \"需复核\"");
+		Retract("Notify");
+}`},
+			}, nil)
+
+			log.EXPECT().Infof(traced, "用户 u-42 申请 9000",
+				"bizCode", "loan", "rule", "Notify", "user.id", "u-42", "amount", 9000)
+			log.EXPECT().Warnf(traced, "金额 9000 超过 ${Params.limit}",
+				"bizCode", "loan", "rule", "Notify", "Params.amount", 9000)
+			log.EXPECT().Warnf(traced, `评分 720 "需复核"`,
+				"bizCode", "loan", "rule", "Notify", "Result.score", int64(720), "alert", true)
+
+			engine := newEngine()
+			defer engine.Close()
+			result, err := engine.Exec(ctx, "loan", map[string]any{
+				"amount": 9000,
+				"user":   map[string]any{"id": "u-42"},
+			})
+			So(err, ShouldBeNil)
+			So(result["score"], ShouldEqual, 720)
+		})
+
+		Convey("结构体输入按事实名取值", func() {
+			mapper.EXPECT().FindByBizCode(gomock.Any(), "struct").Return([]*rule.Rule{
+				{Name: "check", Enabled: true, GRL: `rule Check "检查" { when applicant.Score < 600 then LogError("申请人 ${applicant.ID} 评分 ${applicant.Score} 过低"); Retract("Check"); }`},
+			}, nil)
+
+			log.EXPECT().Errorf(traced, "申请人 A1 评分 550 过低",
+				"bizCode", "struct", "rule", "Check", "applicant.ID", "A1", "applicant.Score", 550)
+
+			engine := newEngine()
+			defer engine.Close()
+			_, err := engine.Exec(ctx, "struct", Applicant{ID: "A1", Score: 550})
+			So(err, ShouldBeNil)
+		})
+
+		Convey("赋值模板插值与转义", func() {
+			mapper.EXPECT().FindByBizCode(gomock.Any(), "assign").Return([]*rule.Rule{
+				{Name: "assign", Enabled: true, GRL: `rule Assign "赋值" { when Params["amount"] > 0 then Result["message"] = Template("用户 ${user.id} 申请 ${amount}，模板写法 $${user.id}，未知 ${missing}"); Retract("Assign"); }`},
+			}, nil)
+
+			engine := newEngine()
+			defer engine.Close()
+			result, err := engine.Exec(ctx, "assign", map[string]any{
+				"amount": 9000,
+				"user":   map[string]any{"id": "u-42"},
+			})
+			So(err, ShouldBeNil)
+			So(result["message"], ShouldEqual, "用户 u-42 申请 9000，模板写法 ${user.id}，未知 ${missing}")
+		})
+
+		Convey("告警发送到告警通道", func() {
+			mapper.EXPECT().FindByBizCode(gomock.Any(), "risk").Return([]*rule.Rule{
+				{Name: "risk", Enabled: true, GRL: `rule Risk "高风险" { when Params["score"] > 90 then Alert("用户 ${user.id} 风险分 ${score}"); Retract("Risk"); }`},
+			}, nil).Times(2)
+			log.EXPECT().Warnf(traced, "用户 u-42 风险分 95", gomock.Any()).Times(2)
+
+			var sent []alert.Alert
+			engine := newEngine()
+			defer engine.Close()
+			engine.SetAlertSink(alert.SinkFunc(func(c context.Context, a alert.Alert) error {
+				So(c.Value(traceKey{}), ShouldEqual, "trace-1")
+				sent = append(sent, a)
+				if len(sent) > 1 {
+					return errors.New("webhook unavailable")
+				}
+				return nil
+			}))
+
+			input := map[string]any{"score": 95, "user": map[string]any{"id": "u-42"}}
+			_, err := engine.Exec(ctx, "risk", input)
+			So(err, ShouldBeNil)
+			So(sent, ShouldHaveLength, 1)
+			So(sent[0].BizCode, ShouldEqual, "risk")
+			So(sent[0].Rule, ShouldEqual, "Risk")
+			So(sent[0].Message, ShouldEqual, "用户 u-42 风险分 95")
+			So(sent[0].Fields, ShouldResemble, map[string]any{"user.id": "u-42", "score": 95})
+
+			// 发送失败只记录错误日志，不影响执行
+			log.EXPECT().Errorf(traced, "告警发送失败", "bizCode", "risk", "rule", "Risk", "error", gomock.Any())
+			_, err = engine.Exec(ctx, "risk", input)
+			So(err, ShouldBeNil)
+			So(sent, ShouldHaveLength, 2)
+		})
+	})
+}
@@ -0,0 +1,88 @@
+package engine
+
+import (
+	"errors"
+	"strings"
+
+	"gitee.com/damengde/runehammer/rule"
+)
+
+// ============================================================================
+// 规则负责人 - 规则出错或记录字段错误时附加负责人和文档链接，值班人员可直接定位联系人和规则说明
+// ============================================================================
+
+// RuleError 规则执行错误 - 出错规则设置了负责人或文档链接时包装原错误，可通过errors.As获取
+type RuleError struct {
+	Rule   string // 出错的规则名（GRL中的规则名）
+	Owner  string // 规则负责人
+	DocURL string // 规则说明文档链接
+	Err    error  // 原始错误
+}
+
+// Error 实现error接口
+func (e *RuleError) Error() string {
+	var b strings.Builder
+	b.WriteString(e.Err.Error())
+	b.WriteString(" [规则 ")
+	b.WriteString(e.Rule)
+	if e.Owner != "" {
+		b.WriteString("，负责人 ")
+		b.WriteString(e.Owner)
+	}
+	if e.DocURL != "" {
+		b.WriteString("，文档 ")
+		b.WriteString(e.DocURL)
+	}
+	b.WriteString("]")
+	return b.String()
+}
+
+// Unwrap 支持errors.Is/As判断原始错误
+func (e *RuleError) Unwrap() error {
+	return e.Err
+}
+
+// ruleOwnership 规则负责人信息
+type ruleOwnership struct {
+	owner  string
+	docURL string
+}
+
+// ruleOwners 按GRL规则名索引负责人信息，未设置负责人和文档链接的规则不记录
+func ruleOwners(rules []*rule.Rule) map[string]ruleOwnership {
+	var owners map[string]ruleOwnership
+	for _, r := range rules {
+		if r == nil || (r.Owner == "" && r.DocURL == "") {
+			continue
+		}
+		if owners == nil {
+			owners = make(map[string]ruleOwnership)
+		}
+		for _, name := range ruleEntryNames(r) {
+			owners[name] = ruleOwnership{owner: r.Owner, docURL: r.DocURL}
+		}
+	}
+	return owners
+}
+
+// failedRule 定位执行错误所属的规则 - panic取发生时执行的规则，内置函数错误取出错时执行动作的规则，
+// 其余错误取正在执行动作的规则；条件求值中的错误无法定位，返回空字符串
+func failedRule(err error, functions *ruleFunctions) string {
+	var panicErr *RulePanicError
+	if errors.As(err, &panicErr) {
+		return panicErr.RuleID
+	}
+	if functions.guard.Err() != nil {
+		return functions.failed
+	}
+	return functions.rule
+}
+
+// withRuleOwner 出错规则设置了负责人信息时包装为*RuleError
+func withRuleOwner(err error, owners map[string]ruleOwnership, name string) error {
+	owner, ok := owners[name]
+	if !ok {
+		return err
+	}
+	return &RuleError{Rule: name, Owner: owner.owner, DocURL: owner.docURL, Err: err}
+}
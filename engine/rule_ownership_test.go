@@ -0,0 +1,86 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+// TestRuleOwnership 测试规则负责人信息附加到错误
+func TestRuleOwnership(t *testing.T) {
+	Convey("规则负责人", t, func() {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mapper := rule.NewMockRuleMapper(ctrl)
+		mapper.EXPECT().FindByBizCode(gomock.Any(), "settle").Return([]*rule.Rule{
+			{Name: "fee", Enabled: true, Owner: "alice", DocURL: "https://wiki.example.com/fee",
+				GRL: `rule Fee "手续费" salience 10 { when Params["mode"] == "action" then Result["fee"] = DotProduct("[1,2]", "[1]"); Retract("Fee"); }`},
+			{Name: "check", Enabled: true,
+				GRL: `rule Check "条件" { when Params["mode"] == "cond" && DotProduct("[1,2]", "[1]") > 0 then Result["ok"] = true; Retract("Check"); }`},
+			{Name: "limit", Enabled: true, Owner: "bob",
+				GRL: `rule Limit "限额" { when Params["amount"] > 100 then Errors.AddError("amount", "OVER_LIMIT", "超出限额"); Retract("Limit"); }`},
+			{Name: "plain", Enabled: true,
+				GRL: `rule Plain "无负责人" { when Params["amount"] < 0 then Errors.AddError("amount", "NEGATIVE", "金额为负"); Retract("Plain"); }`},
+		}, nil).AnyTimes()
+
+		cfg := config.DefaultConfig()
+		cfg.FieldErrors = true
+		engine := NewEngineImpl[map[string]any](
+			cfg, mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+			ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+		)
+		defer engine.Close()
+
+		Convey("动作出错时错误附带负责人和文档链接", func() {
+			_, err := engine.Exec(context.Background(), "settle", map[string]any{"mode": "action", "amount": 1})
+			So(err, ShouldNotBeNil)
+			var ruleErr *RuleError
+			So(errors.As(err, &ruleErr), ShouldBeTrue)
+			So(ruleErr.Rule, ShouldEqual, "Fee")
+			So(ruleErr.Owner, ShouldEqual, "alice")
+			So(ruleErr.DocURL, ShouldEqual, "https://wiki.example.com/fee")
+			So(errors.Is(err, ErrDimensionMismatch), ShouldBeTrue)
+			So(err.Error(), ShouldContainSubstring, "负责人 alice")
+		})
+
+		Convey("条件中出错无法定位规则时保留原错误", func() {
+			_, err := engine.Exec(context.Background(), "settle", map[string]any{"mode": "cond", "amount": 1})
+			So(errors.Is(err, ErrDimensionMismatch), ShouldBeTrue)
+			var ruleErr *RuleError
+			So(errors.As(err, &ruleErr), ShouldBeFalse)
+		})
+
+		Convey("字段错误附带记录规则的负责人", func() {
+			result, err := engine.Exec(context.Background(), "settle", map[string]any{"mode": "", "amount": 200})
+			So(err, ShouldBeNil)
+			So(result[FieldErrorsKey], ShouldResemble, []FieldError{
+				{Field: "amount", Code: "OVER_LIMIT", Message: "超出限额", Owner: "bob"},
+			})
+
+			result, err = engine.Exec(context.Background(), "settle", map[string]any{"mode": "", "amount": -1})
+			So(err, ShouldBeNil)
+			So(result[FieldErrorsKey], ShouldResemble, []FieldError{
+				{Field: "amount", Code: "NEGATIVE", Message: "金额为负"},
+			})
+		})
+
+		Convey("panic按规则定位负责人", func() {
+			err := withRuleOwner(&RulePanicError{RuleID: "Fee", Value: "boom"}, ruleOwners([]*rule.Rule{
+				{GRL: `rule Fee "x" { when true then Retract("Fee"); }`, Owner: "alice"},
+			}), "Fee")
+			So(errors.Is(err, ErrRulePanic), ShouldBeTrue)
+			So(err.Error(), ShouldEndWith, "[规则 Fee，负责人 alice]")
+		})
+	})
+}
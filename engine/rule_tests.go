@@ -0,0 +1,283 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gitee.com/damengde/runehammer/config"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/hyperjumptech/grule-rule-engine/builder"
+	grengine "github.com/hyperjumptech/grule-rule-engine/engine"
+	"github.com/hyperjumptech/grule-rule-engine/pkg"
+)
+
+// ============================================================================
+// 规则测试用例 - 以声明式输入/期望输出校验待发布的规则
+// ============================================================================
+//
+// 测试在独立的知识库中编译和执行，不影响线上编译缓存；RunRuleTests 直接从映射器读取规则，
+// 因此校验的是存储中尚未同步到缓存的最新规则（草稿），发布前可据此拦截错误规则。
+
+// ErrRuleTestsNotSupported 规则映射器未实现RuleTestMapper，无法读取测试用例
+var ErrRuleTestsNotSupported = errors.New("规则映射器不支持测试用例")
+
+// ErrRuleTestsFailed 规则测试未全部通过
+var ErrRuleTestsFailed = errors.New("规则测试未通过")
+
+// RuleTestReport 规则测试报告
+type RuleTestReport struct {
+	BizCode        string           // 业务码
+	RuleSetVersion int              // 被测规则集版本
+	Results        []RuleTestResult // 各用例结果，顺序与用例一致
+	Passed         int              // 通过数
+	Failed         int              // 失败数
+}
+
+// RuleTestResult 单个用例的测试结果
+type RuleTestResult struct {
+	Name       string             // 用例名称
+	Passed     bool               // 是否通过
+	Mismatches []RuleTestMismatch // 与期望不一致的结果字段
+	Error      string             // 执行错误
+}
+
+// RuleTestMismatch 结果字段不一致
+type RuleTestMismatch struct {
+	Field    string // 字段路径，嵌套字段以.分隔
+	Expected any    // 期望值
+	Actual   any    // 实际值，结果中缺少该字段时为nil
+}
+
+// OK 是否全部通过
+func (r *RuleTestReport) OK() bool {
+	return r.Failed == 0
+}
+
+// Err 未全部通过时返回包装ErrRuleTestsFailed的错误，可作为发布前的校验
+func (r *RuleTestReport) Err() error {
+	if r.OK() {
+		return nil
+	}
+	var failed []string
+	for _, result := range r.Results {
+		if !result.Passed {
+			failed = append(failed, result.Name)
+		}
+	}
+	return fmt.Errorf("业务码 %s 有 %d 个用例失败 [%s]: %w", r.BizCode, r.Failed, strings.Join(failed, ", "), ErrRuleTestsFailed)
+}
+
+// RunRuleTests 执行业务码存储的测试用例
+//
+// 规则和用例均直接从映射器读取（不经过缓存），映射器需实现 rule.RuleTestMapper。
+//
+// 参数:
+//
+//	ctx     - 上下文
+//	bizCode - 业务码
+//
+// 返回值:
+//
+//	*RuleTestReport - 测试报告，用例失败不作为错误返回，可通过 report.Err() 判断
+//	error           - 映射器不支持、读取失败或规则编译失败
+func (e *engineImpl[T]) RunRuleTests(ctx context.Context, bizCode string) (*RuleTestReport, error) {
+	mapper, ok := e.mapper.(rule.RuleTestMapper)
+	if !ok {
+		return nil, ErrRuleTestsNotSupported
+	}
+
+	rules, err := e.mapper.FindByBizCode(ctx, bizCode)
+	if err != nil {
+		return nil, fmt.Errorf("读取规则失败: %w", err)
+	}
+	tests, err := mapper.FindTestsByBizCode(ctx, bizCode)
+	if err != nil {
+		return nil, fmt.Errorf("读取测试用例失败: %w", err)
+	}
+
+	return e.runRuleTests(ctx, bizCode, e.selectEnvironment(rules), tests)
+}
+
+// RunBundleTests 以规则包中的规则执行规则包附带的测试用例 - 导入或发布规则包前的校验
+func (e *engineImpl[T]) RunBundleTests(ctx context.Context, bundle *rule.RuleBundle) (*RuleTestReport, error) {
+	if bundle == nil {
+		return nil, fmt.Errorf("规则包为空")
+	}
+
+	rules := make([]*rule.Rule, 0, len(bundle.Rules))
+	for i := range bundle.Rules {
+		rules = append(rules, &bundle.Rules[i])
+	}
+	tests := make([]*rule.RuleTestCase, 0, len(bundle.Tests))
+	for i := range bundle.Tests {
+		tests = append(tests, &bundle.Tests[i])
+	}
+
+	return e.runRuleTests(ctx, bundle.BizCode, rules, tests)
+}
+
+// runRuleTests 在独立知识库中编译规则并逐个执行用例
+func (e *engineImpl[T]) runRuleTests(ctx context.Context, bizCode string, rules []*rule.Rule, tests []*rule.RuleTestCase) (*RuleTestReport, error) {
+	e.mutex.RLock()
+	closed := e.closed
+	e.mutex.RUnlock()
+	if closed {
+		return nil, ErrEngineClosed
+	}
+
+	resolved, err := e.resolveReferences(rules)
+	if err != nil {
+		return nil, err
+	}
+	if e.hoistCommonCalls() {
+		resolved, _ = rule.HoistCommonCalls(resolved)
+	}
+	library := ast.NewKnowledgeLibrary()
+	ruleBuilder := builder.NewRuleBuilder(library)
+	for _, r := range resolved {
+		if !r.Enabled {
+			continue
+		}
+		if err := ruleBuilder.BuildRuleFromResource(bizCode, "1.0.0", pkg.NewBytesResource([]byte(r.GRL))); err != nil {
+			return nil, fmt.Errorf("编译规则 %s 失败: %w", r.Name, err)
+		}
+	}
+
+	report := &RuleTestReport{BizCode: bizCode, RuleSetVersion: ruleSetVersion(rules)}
+	for _, test := range tests {
+		result := e.runRuleTest(ctx, library, bizCode, rules, test)
+		if result.Passed {
+			report.Passed++
+		} else {
+			report.Failed++
+		}
+		report.Results = append(report.Results, result)
+	}
+
+	if e.logger != nil {
+		e.logger.Infof(ctx, "规则测试完成", "bizCode", bizCode, "version", report.RuleSetVersion,
+			"passed", report.Passed, "failed", report.Failed)
+	}
+	return report, nil
+}
+
+// runRuleTest 执行单个用例，每个用例使用独立的知识库实例
+func (e *engineImpl[T]) runRuleTest(ctx context.Context, library *ast.KnowledgeLibrary, bizCode string, rules []*rule.Rule, test *rule.RuleTestCase) RuleTestResult {
+	result := RuleTestResult{Name: test.Name}
+	fail := func(err error) RuleTestResult {
+		result.Error = err.Error()
+		return result
+	}
+
+	if len(library.Library) == 0 {
+		return fail(fmt.Errorf("没有启用的规则"))
+	}
+	knowledgeBase, err := library.NewKnowledgeBaseInstance(bizCode, "1.0.0")
+	if err != nil {
+		return fail(fmt.Errorf("获取知识库实例失败: %w", err))
+	}
+	mode := e.ExecMode(bizCode)
+	applyExecMode(mode, rules, knowledgeBase)
+
+	input := any(test.Input)
+	if test.Input == nil {
+		input = map[string]any{}
+	}
+	input, _ = e.normalizeInput(input)
+
+	dataCtx := ast.NewDataContext()
+	if err := e.injectInputData(dataCtx, input); err != nil {
+		return fail(fmt.Errorf("数据注入失败: %w", err))
+	}
+	e.injectBuiltinFunctions(dataCtx)
+	if e.hoistCommonCalls() {
+		injectSharedFacts(dataCtx)
+	}
+
+	var fieldErrors *FieldErrorCollector
+	if e.fieldErrorsEnabled() {
+		if fieldErrors, err = injectFieldErrors(dataCtx, knowledgeBase); err != nil {
+			return fail(fmt.Errorf("数据注入失败: %w", err))
+		}
+	}
+
+	// 与线上执行相同，以规则函数对象提供内置函数（SafeDiv、AddReason、Emit等）
+	functions := e.ruleFunctions(ctx, bizCode)
+	var listeners []grengine.GruleEngineListener
+	if gate := newSingleMatchGate(mode, dataCtx); gate != nil {
+		listeners = append(listeners, gate)
+	}
+	listeners = append(listeners, functions)
+
+	failOnCond := e.NullPolicy(bizCode) == config.NullPolicyError
+	err = safeExecute(ctx, functions.wrap(dataCtx), knowledgeBase, bizCode, nil, failOnCond, listeners...)
+	if guardErr := functions.guard.Err(); guardErr != nil {
+		err = guardErr
+	}
+	if err != nil {
+		return fail(fmt.Errorf("规则执行失败: %w", err))
+	}
+	if fieldErrors != nil {
+		attachFieldErrors(dataCtx, fieldErrors)
+	}
+	attachReasons(dataCtx, functions)
+
+	actual, _ := resultMap(dataCtx)
+	result.Mismatches = compareExpected("", test.Expected, actual)
+	result.Passed = len(result.Mismatches) == 0
+	return result
+}
+
+// compareExpected 逐字段比较期望值与实际结果，期望中的嵌套map按字段递归比较
+func compareExpected(prefix string, expected, actual map[string]any) []RuleTestMismatch {
+	keys := make([]string, 0, len(expected))
+	for key := range expected {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var mismatches []RuleTestMismatch
+	for _, key := range keys {
+		field := key
+		if prefix != "" {
+			field = prefix + "." + key
+		}
+
+		want := normalizeJSON(expected[key])
+		got, ok := actual[key]
+		got = normalizeJSON(got)
+
+		if wantMap, isMap := want.(map[string]any); isMap {
+			if gotMap, isMap := got.(map[string]any); isMap {
+				mismatches = append(mismatches, compareExpected(field, wantMap, gotMap)...)
+				continue
+			}
+		}
+		if !ok || !reflect.DeepEqual(want, got) {
+			mismatches = append(mismatches, RuleTestMismatch{Field: field, Expected: want, Actual: got})
+		}
+	}
+	return mismatches
+}
+
+// normalizeJSON 经JSON往返归一化取值，数值统一为float64，结构体转换为map
+func normalizeJSON(value any) any {
+	if value == nil {
+		return nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var normalized any
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return value
+	}
+	return normalized
+}
@@ -0,0 +1,170 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// TestRunRuleTests 测试规则测试用例
+func TestRunRuleTests(t *testing.T) {
+	Convey("规则测试用例", t, func() {
+		db, err := gorm.Open(sqlite.Open("file:rule_tests.db?mode=memory&cache=shared"), &gorm.Config{})
+		So(err, ShouldBeNil)
+		So(db.AutoMigrate(&rule.Rule{}, &rule.RuleTestCase{}), ShouldBeNil)
+		db.Where("1 = 1").Delete(&rule.Rule{})
+		db.Where("1 = 1").Delete(&rule.RuleTestCase{})
+
+		approve := &rule.Rule{BizCode: "loan", Name: "approve", Version: 2, Enabled: true, GRL: `rule Approve "审批" {
+	when Params["score"] >= 600
+	then
+		Result["approved"] = true;
+		Result["detail"] = Params["detail"];
+		Retract("Approve");
+}`}
+		So(db.Create(approve).Error, ShouldBeNil)
+		So(db.Create(&[]*rule.RuleTestCase{
+			{BizCode: "loan", Name: "高分通过", Input: map[string]any{"score": 700, "detail": map[string]any{"tier": "A", "limit": 5000}},
+				Expected: map[string]any{"approved": true, "detail": map[string]any{"limit": 5000}}},
+			{BizCode: "loan", Name: "低分拒绝", Input: map[string]any{"score": 500}, Expected: map[string]any{"approved": false}},
+			{BizCode: "other", Name: "其他业务码", Input: map[string]any{}, Expected: map[string]any{}},
+		}).Error, ShouldBeNil)
+
+		engine := NewEngineImpl[map[string]any](
+			config.DefaultConfig(), rule.NewRuleMapper(db), nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+			ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+		)
+		defer engine.Close()
+		ctx := context.Background()
+
+		Convey("执行存储的用例并报告不一致字段", func() {
+			report, err := engine.RunRuleTests(ctx, "loan")
+			So(err, ShouldBeNil)
+			So(report.BizCode, ShouldEqual, "loan")
+			So(report.RuleSetVersion, ShouldEqual, 2)
+			So(report.Results, ShouldHaveLength, 2)
+			So(report.Passed, ShouldEqual, 1)
+			So(report.Failed, ShouldEqual, 1)
+			So(report.OK(), ShouldBeFalse)
+
+			So(report.Results[0].Name, ShouldEqual, "高分通过")
+			So(report.Results[0].Passed, ShouldBeTrue)
+			So(report.Results[1].Mismatches, ShouldResemble, []RuleTestMismatch{
+				{Field: "approved", Expected: false, Actual: nil},
+			})
+
+			err = report.Err()
+			So(errors.Is(err, ErrRuleTestsFailed), ShouldBeTrue)
+			So(err.Error(), ShouldContainSubstring, "低分拒绝")
+		})
+
+		Convey("校验存储中的最新规则而非缓存中的版本", func() {
+			result, err := engine.Exec(ctx, "loan", map[string]any{"score": 500})
+			So(err, ShouldBeNil)
+			So(result["approved"], ShouldBeNil)
+
+			So(db.Model(approve).Updates(map[string]any{"version": 3, "grl": `rule Approve "审批" {
+	when true
+	then
+		Result["approved"] = Params["score"] >= 600;
+		Retract("Approve");
+}`}).Error, ShouldBeNil)
+
+			report, err := engine.RunRuleTests(ctx, "loan")
+			So(err, ShouldBeNil)
+			So(report.RuleSetVersion, ShouldEqual, 3)
+			So(report.Results[1].Passed, ShouldBeTrue)
+			So(report.Results[0].Mismatches, ShouldResemble, []RuleTestMismatch{
+				{Field: "detail", Expected: map[string]any{"limit": float64(5000)}, Actual: nil},
+			})
+
+			// 线上编译缓存不受影响
+			result, err = engine.Exec(ctx, "loan", map[string]any{"score": 500})
+			So(err, ShouldBeNil)
+			So(result["approved"], ShouldBeNil)
+		})
+
+		Convey("执行规则包附带的用例", func() {
+			bundle := rule.NewRuleBundle("loan", []*rule.Rule{approve})
+			bundle.Tests = []rule.RuleTestCase{
+				{Name: "通过", Input: map[string]any{"score": 650, "detail": map[string]any{}}, Expected: map[string]any{"approved": true}},
+			}
+			data, err := bundle.ToJSON()
+			So(err, ShouldBeNil)
+
+			parsed := &rule.RuleBundle{}
+			So(parsed.FromJSON(data), ShouldBeNil)
+			So(parsed.Tests, ShouldHaveLength, 1)
+
+			report, err := engine.RunBundleTests(ctx, parsed)
+			So(err, ShouldBeNil)
+			So(report.OK(), ShouldBeTrue)
+		})
+
+		Convey("规则调用内置函数时与线上执行结果一致", func() {
+			ratio := &rule.Rule{BizCode: "ratio", Name: "ratio", Enabled: true, GRL: `rule Ratio "负债率" {
+	when true
+	then
+		Result["ratio"] = SafeDiv(Params["debt"], Params["income"], -1);
+		Emit("tags", "checked");
+		AddReason("HIGH_DEBT", 10);
+		Retract("Ratio");
+}`}
+			bundle := rule.NewRuleBundle("ratio", []*rule.Rule{ratio})
+			bundle.Tests = []rule.RuleTestCase{
+				{Name: "正常", Input: map[string]any{"debt": 50, "income": 200},
+					Expected: map[string]any{"ratio": 0.25, "tags": []any{"checked"}}},
+				{Name: "收入为零", Input: map[string]any{"debt": 50, "income": 0}, Expected: map[string]any{"ratio": -1}},
+			}
+
+			report, err := engine.RunBundleTests(ctx, bundle)
+			So(err, ShouldBeNil)
+			for _, result := range report.Results {
+				So(result.Error, ShouldBeEmpty)
+			}
+			So(report.OK(), ShouldBeTrue)
+		})
+
+		Convey("嵌套字段逐层比较且忽略未列出的字段", func() {
+			mismatches := compareExpected("",
+				map[string]any{"detail": map[string]any{"tier": "A", "limit": 5000}},
+				map[string]any{"detail": map[string]any{"tier": "A", "limit": 3000, "extra": true}, "other": 1},
+			)
+			So(mismatches, ShouldResemble, []RuleTestMismatch{
+				{Field: "detail.limit", Expected: float64(5000), Actual: float64(3000)},
+			})
+		})
+
+		Convey("规则编译失败时返回错误", func() {
+			bundle := rule.NewRuleBundle("loan", []*rule.Rule{{Name: "bad", GRL: "rule {", Enabled: true}})
+			_, err := engine.RunBundleTests(ctx, bundle)
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("映射器不支持测试用例", func() {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			other := NewEngineImpl[map[string]any](
+				config.DefaultConfig(), rule.NewMockRuleMapper(ctrl), nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+				ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+			)
+			defer other.Close()
+
+			_, err := other.RunRuleTests(ctx, "loan")
+			So(errors.Is(err, ErrRuleTestsNotSupported), ShouldBeTrue)
+		})
+	})
+}
@@ -0,0 +1,137 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"gitee.com/damengde/runehammer/config"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+)
+
+// ============================================================================
+// 安全数学函数与非有限数值检查 - 避免NaN、±Inf静默进入结果
+// ============================================================================
+//
+// 除以零、对负数取对数等运算得到NaN或±Inf，Grule不会报错。SafeDiv、SafeLog、SafeSqrt
+// 在结果不是有限数值时返回调用方给出的默认值；Config.NonFinitePolicy 在执行结束后检查Result，
+// 出现非有限数值时执行返回 ErrNonFiniteResult，或替换为 Config.NonFiniteDefault。
+
+// ErrNonFiniteResult Result中出现NaN或±Inf
+var ErrNonFiniteResult = errors.New("结果包含非有限数值")
+
+// finiteOr 结果为有限数值时返回结果，否则返回默认值
+func finiteOr(value, fallback float64) float64 {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return fallback
+	}
+	return value
+}
+
+// floatArg 数值参数，非数值时panic，由Grule作为执行错误返回
+func floatArg(name string, value any) float64 {
+	n, ok := toFloat(value)
+	if !ok {
+		panic(fmt.Errorf("%s 的参数必须是数值，实际为 %T", name, value))
+	}
+	return n
+}
+
+// safeDiv 除法，除数为0或结果非有限时返回默认值
+func safeDiv(a, b, fallback float64) float64 {
+	if b == 0 {
+		return fallback
+	}
+	return finiteOr(a/b, fallback)
+}
+
+// safeLog 自然对数，x<=0时返回默认值
+func safeLog(x, fallback float64) float64 {
+	if x <= 0 {
+		return fallback
+	}
+	return finiteOr(math.Log(x), fallback)
+}
+
+// safeSqrt 平方根，x<0时返回默认值
+func safeSqrt(x, fallback float64) float64 {
+	if x < 0 {
+		return fallback
+	}
+	return finiteOr(math.Sqrt(x), fallback)
+}
+
+// SafeDiv 除法，除数为0或结果为NaN、±Inf时返回fallback
+func (f *ruleFunctions) SafeDiv(a, b, fallback any) float64 {
+	return safeDiv(floatArg("SafeDiv", a), floatArg("SafeDiv", b), floatArg("SafeDiv", fallback))
+}
+
+// SafeLog 自然对数，x<=0时返回fallback
+func (f *ruleFunctions) SafeLog(x, fallback any) float64 {
+	return safeLog(floatArg("SafeLog", x), floatArg("SafeLog", fallback))
+}
+
+// SafeSqrt 平方根，x<0时返回fallback
+func (f *ruleFunctions) SafeSqrt(x, fallback any) float64 {
+	return safeSqrt(floatArg("SafeSqrt", x), floatArg("SafeSqrt", fallback))
+}
+
+// injectSafeMathFunctions 注入安全数学函数
+func injectSafeMathFunctions(dataCtx ast.IDataContext) {
+	dataCtx.Add("SafeDiv", safeDiv)
+	dataCtx.Add("SafeLog", safeLog)
+	dataCtx.Add("SafeSqrt", safeSqrt)
+}
+
+// checkNonFinite 按策略检查Result中的非有限数值 - 嵌套map和切片逐层检查，替换在原处进行
+func checkNonFinite(result map[string]any, policy config.NonFinitePolicy, fallback float64) error {
+	if policy == config.NonFiniteAllow || result == nil {
+		return nil
+	}
+	var paths []string
+	replaceNonFinite(result, "", policy == config.NonFiniteSubstitute, fallback, &paths)
+	if policy == config.NonFiniteError && len(paths) > 0 {
+		sort.Strings(paths)
+		return fmt.Errorf("%w: %v", ErrNonFiniteResult, paths)
+	}
+	return nil
+}
+
+// replaceNonFinite 记录非有限数值的路径，substitute为true时替换为fallback，返回替换后的值
+func replaceNonFinite(value any, path string, substitute bool, fallback float64, paths *[]string) any {
+	switch v := value.(type) {
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			*paths = append(*paths, path)
+			if substitute {
+				return fallback
+			}
+		}
+	case float32:
+		if math.IsNaN(float64(v)) || math.IsInf(float64(v), 0) {
+			*paths = append(*paths, path)
+			if substitute {
+				return fallback
+			}
+		}
+	case map[string]any:
+		for key, item := range v {
+			child := key
+			if path != "" {
+				child = path + "." + key
+			}
+			v[key] = replaceNonFinite(item, child, substitute, fallback, paths)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = replaceNonFinite(item, path+"["+strconv.Itoa(i)+"]", substitute, fallback, paths)
+		}
+	case []float64:
+		for i, item := range v {
+			v[i] = replaceNonFinite(item, path+"["+strconv.Itoa(i)+"]", substitute, fallback, paths).(float64)
+		}
+	}
+	return value
+}
@@ -0,0 +1,112 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"testing"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+// TestSafeMath 测试安全数学函数与非有限数值检查
+func TestSafeMath(t *testing.T) {
+	Convey("安全数学函数", t, func() {
+		Convey("非法运算返回默认值", func() {
+			So(safeDiv(10, 4, -1), ShouldEqual, 2.5)
+			So(safeDiv(10, 0, -1), ShouldEqual, -1)
+			So(safeDiv(math.MaxFloat64, 1e-300, -1), ShouldEqual, -1)
+			So(safeLog(math.E, 0), ShouldAlmostEqual, 1)
+			So(safeLog(0, -99), ShouldEqual, -99)
+			So(safeLog(-1, -99), ShouldEqual, -99)
+			So(safeSqrt(9, 0), ShouldEqual, 3)
+			So(safeSqrt(-9, 0), ShouldEqual, 0)
+		})
+
+		Convey("非数值参数报错", func() {
+			f := &ruleFunctions{}
+			So(func() { f.SafeDiv("a", 1, 0) }, ShouldPanic)
+			So(f.SafeDiv(int64(9), 3, 0), ShouldEqual, 3)
+		})
+
+		Convey("按策略检查Result", func() {
+			newResult := func() map[string]any {
+				return map[string]any{
+					"ratio": math.NaN(),
+					"ok":    1.5,
+					"risk":  map[string]any{"score": math.Inf(1)},
+					"list":  []any{1.0, math.Inf(-1)},
+				}
+			}
+
+			So(checkNonFinite(newResult(), config.NonFiniteAllow, 0), ShouldBeNil)
+
+			err := checkNonFinite(newResult(), config.NonFiniteError, 0)
+			So(errors.Is(err, ErrNonFiniteResult), ShouldBeTrue)
+			So(err.Error(), ShouldContainSubstring, "[list[1] ratio risk.score]")
+
+			result := newResult()
+			So(checkNonFinite(result, config.NonFiniteSubstitute, -1), ShouldBeNil)
+			So(result["ratio"], ShouldEqual, -1)
+			So(result["ok"], ShouldEqual, 1.5)
+			So(result["risk"].(map[string]any)["score"], ShouldEqual, -1)
+			So(result["list"], ShouldResemble, []any{1.0, -1.0})
+		})
+
+		Convey("规则中使用", func() {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mapper := rule.NewMockRuleMapper(ctrl)
+			mapper.EXPECT().FindByBizCode(gomock.Any(), "ratio").Return([]*rule.Rule{
+				{Name: "ratio", Enabled: true, GRL: `rule Ratio "比率" {
+	when true
+	then
+		Result["safe"] = SafeDiv(Params["debt"], Params["income"], 0);
+		Result["raw"] = Params["debt"] / Params["income"];
+		Retract("Ratio");
+}`},
+			}, nil).AnyTimes()
+
+			newEngine := func(cfg *config.Config) *engineImpl[map[string]any] {
+				return NewEngineImpl[map[string]any](
+					cfg, mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+					ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+				)
+			}
+			input := map[string]any{"debt": 500.0, "income": 0.0}
+
+			cfg := config.DefaultConfig()
+			engine := newEngine(cfg)
+			result, err := engine.Exec(context.Background(), "ratio", input)
+			So(err, ShouldBeNil)
+			So(result["safe"], ShouldEqual, 0)
+			So(math.IsInf(result["raw"].(float64), 1), ShouldBeTrue)
+			engine.Close()
+
+			cfg = config.DefaultConfig()
+			cfg.NonFinitePolicy = config.NonFiniteError
+			engine = newEngine(cfg)
+			_, err = engine.Exec(context.Background(), "ratio", input)
+			So(errors.Is(err, ErrNonFiniteResult), ShouldBeTrue)
+			engine.Close()
+
+			cfg = config.DefaultConfig()
+			cfg.NonFinitePolicy = config.NonFiniteSubstitute
+			cfg.NonFiniteDefault = -1
+			engine = newEngine(cfg)
+			result, err = engine.Exec(context.Background(), "ratio", input)
+			So(err, ShouldBeNil)
+			So(result["raw"], ShouldEqual, -1)
+			engine.Close()
+		})
+	})
+}
@@ -0,0 +1,228 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+)
+
+// ============================================================================
+// 字符串相似度函数 - 去重、KYC姓名匹配等规则使用的模糊匹配
+// ============================================================================
+//
+// Levenshtein 与 JaroWinkler 的计算量与两个字符串长度之积成正比，输入可能来自外部，
+// 超过最大长度（Config.SimilarityMaxLength，按字符计）时执行返回错误，避免长字符串拖慢执行。
+
+// DefaultSimilarityMaxLength 未配置时相似度函数的最大输入长度（字符数）
+const DefaultSimilarityMaxLength = 1000
+
+// ErrStringTooLong 相似度函数的输入超过最大长度
+var ErrStringTooLong = errors.New("字符串超过相似度计算的最大长度")
+
+// similarityGuard 检查相似度函数的输入长度
+func similarityGuard(name string, maxLength int, a, b []rune) {
+	if maxLength <= 0 {
+		maxLength = DefaultSimilarityMaxLength
+	}
+	if len(a) > maxLength || len(b) > maxLength {
+		panic(fmt.Errorf("%s: %w（%d、%d > %d）", name, ErrStringTooLong, len(a), len(b), maxLength))
+	}
+}
+
+// levenshtein 编辑距离 - 插入、删除、替换各计1，按字符计算
+func levenshtein(a, b []rune) int {
+	if len(a) < len(b) {
+		a, b = b, a
+	}
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+// jaroWinkler Jaro-Winkler相似度 (0~1) - 公共前缀最多4个字符，缩放系数0.1
+func jaroWinkler(a, b []rune) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	window := max(len(a), len(b))/2 - 1
+	if window < 0 {
+		window = 0
+	}
+	matchedA := make([]bool, len(a))
+	matchedB := make([]bool, len(b))
+	matches := 0
+	for i := range a {
+		lo, hi := max(0, i-window), min(len(b)-1, i+window)
+		for j := lo; j <= hi; j++ {
+			if !matchedB[j] && a[i] == b[j] {
+				matchedA[i], matchedB[j] = true, true
+				matches++
+				break
+			}
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	transpositions := 0
+	j := 0
+	for i := range a {
+		if !matchedA[i] {
+			continue
+		}
+		for !matchedB[j] {
+			j++
+		}
+		if a[i] != b[j] {
+			transpositions++
+		}
+		j++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(len(a)) + m/float64(len(b)) + (m-float64(transpositions)/2)/m) / 3
+
+	prefix := 0
+	for prefix < min(4, len(a), len(b)) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
+
+// soundexCodes 字母的Soundex编码，元音及H、W、Y为0
+var soundexCodes = [26]byte{
+	'0', '1', '2', '3', '0', '1', '2', '0', '0', '2', '2', '4', '5',
+	'5', '0', '1', '2', '6', '2', '3', '0', '1', '0', '2', '0', '2',
+}
+
+// soundex 美式Soundex编码 - 只处理ASCII字母，没有字母时返回空字符串
+func soundex(s string) string {
+	var code []byte
+	var last byte
+	for _, r := range strings.ToUpper(s) {
+		if r < 'A' || r > 'Z' {
+			continue
+		}
+		digit := soundexCodes[r-'A']
+		if code == nil {
+			code = append(code, byte(r))
+			last = digit
+			continue
+		}
+		if digit != '0' && digit != last {
+			code = append(code, digit)
+			if len(code) == 4 {
+				break
+			}
+		}
+		// H、W 不分隔相同编码，元音分隔
+		if r != 'H' && r != 'W' {
+			last = digit
+		}
+	}
+	if code == nil {
+		return ""
+	}
+	for len(code) < 4 {
+		code = append(code, '0')
+	}
+	return string(code)
+}
+
+// normalizeWhitespace 去除首尾空白，连续空白（含全角空格、换行）合并为一个半角空格
+func normalizeWhitespace(s string) string {
+	return strings.Join(strings.FieldsFunc(s, unicode.IsSpace), " ")
+}
+
+// stringArg 规则函数的字符串参数 - map中的值以interface传入，nil视为空字符串
+func stringArg(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	}
+	return fmt.Sprint(value)
+}
+
+// checkSimilarityInput 检查相似度函数的输入长度 - 超出时记录错误，条件中被Grule视为不成立时仍以该错误结束执行
+func (f *ruleFunctions) checkSimilarityInput(name string, a, b []rune) {
+	defer func() {
+		if r := recover(); r != nil {
+			if err, ok := r.(error); ok {
+				f.guard.fail(err)
+			}
+			panic(r)
+		}
+	}()
+	similarityGuard(name, f.maxSimilarityLength, a, b)
+}
+
+// Levenshtein 编辑距离，输入超过最大长度时执行返回错误
+func (f *ruleFunctions) Levenshtein(a, b any) int64 {
+	ra, rb := []rune(stringArg(a)), []rune(stringArg(b))
+	f.checkSimilarityInput("Levenshtein", ra, rb)
+	return int64(levenshtein(ra, rb))
+}
+
+// JaroWinkler Jaro-Winkler相似度 (0~1)，输入超过最大长度时执行返回错误
+func (f *ruleFunctions) JaroWinkler(a, b any) float64 {
+	ra, rb := []rune(stringArg(a)), []rune(stringArg(b))
+	f.checkSimilarityInput("JaroWinkler", ra, rb)
+	return jaroWinkler(ra, rb)
+}
+
+// Soundex 美式Soundex编码，如 Robert -> R163
+func (f *ruleFunctions) Soundex(s any) string {
+	return soundex(stringArg(s))
+}
+
+// NormalizeWhitespace 规范化空白
+func (f *ruleFunctions) NormalizeWhitespace(s any) string {
+	return normalizeWhitespace(stringArg(s))
+}
+
+// injectSimilarityFunctions 注入字符串相似度函数
+func (e *engineImpl[T]) injectSimilarityFunctions(dataCtx ast.IDataContext) {
+	maxLength := 0
+	if e.config != nil {
+		maxLength = e.config.SimilarityMaxLength
+	}
+
+	dataCtx.Add("Levenshtein", func(a, b string) int64 {
+		ra, rb := []rune(a), []rune(b)
+		similarityGuard("Levenshtein", maxLength, ra, rb)
+		return int64(levenshtein(ra, rb))
+	})
+
+	dataCtx.Add("JaroWinkler", func(a, b string) float64 {
+		ra, rb := []rune(a), []rune(b)
+		similarityGuard("JaroWinkler", maxLength, ra, rb)
+		return jaroWinkler(ra, rb)
+	})
+
+	dataCtx.Add("Soundex", soundex)
+	dataCtx.Add("NormalizeWhitespace", normalizeWhitespace)
+}
@@ -0,0 +1,92 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+// TestSimilarityFunctions 测试字符串相似度函数
+func TestSimilarityFunctions(t *testing.T) {
+	Convey("字符串相似度函数", t, func() {
+		Convey("编辑距离", func() {
+			So(levenshtein([]rune("kitten"), []rune("sitting")), ShouldEqual, 3)
+			So(levenshtein([]rune(""), []rune("abc")), ShouldEqual, 3)
+			So(levenshtein([]rune("张三丰"), []rune("张三")), ShouldEqual, 1)
+			So(levenshtein([]rune("same"), []rune("same")), ShouldEqual, 0)
+		})
+
+		Convey("Jaro-Winkler相似度", func() {
+			So(jaroWinkler([]rune("MARTHA"), []rune("MARHTA")), ShouldAlmostEqual, 0.9611, 0.0001)
+			So(jaroWinkler([]rune("DIXON"), []rune("DICKSONX")), ShouldAlmostEqual, 0.8133, 0.0001)
+			So(jaroWinkler([]rune("abc"), []rune("xyz")), ShouldEqual, 0)
+			So(jaroWinkler([]rune(""), []rune("")), ShouldEqual, 1)
+			So(jaroWinkler([]rune("a"), []rune("a")), ShouldEqual, 1)
+		})
+
+		Convey("Soundex编码", func() {
+			So(soundex("Robert"), ShouldEqual, "R163")
+			So(soundex("Rupert"), ShouldEqual, "R163")
+			So(soundex("Ashcraft"), ShouldEqual, "A261")
+			So(soundex("Tymczak"), ShouldEqual, "T522")
+			So(soundex("Pfister"), ShouldEqual, "P236")
+			So(soundex("Lee"), ShouldEqual, "L000")
+			So(soundex("王"), ShouldEqual, "")
+		})
+
+		Convey("规范化空白", func() {
+			So(normalizeWhitespace("  Zhang \t San\n　Feng "), ShouldEqual, "Zhang San Feng")
+		})
+
+		Convey("超过最大长度时报错", func() {
+			long := []rune(strings.Repeat("a", 11))
+			So(func() { similarityGuard("Levenshtein", 10, long, []rune("a")) }, ShouldPanic)
+			So(func() { similarityGuard("Levenshtein", 0, long, []rune("a")) }, ShouldNotPanic)
+		})
+
+		Convey("规则中使用", func() {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mapper := rule.NewMockRuleMapper(ctrl)
+			mapper.EXPECT().FindByBizCode(gomock.Any(), "kyc").Return([]*rule.Rule{
+				{Name: "match", Enabled: true, GRL: `rule Match "姓名匹配" {
+	when Levenshtein(NormalizeWhitespace(Params["name"]), Params["listed"]) <= 2 && JaroWinkler(Params["name"], Params["listed"]) > 0.8
+	then
+		Result["soundex"] = Soundex(Params["listed"]);
+		Result["hit"] = true;
+		Retract("Match");
+}`},
+			}, nil).AnyTimes()
+
+			cfg := config.DefaultConfig()
+			cfg.SimilarityMaxLength = 20
+			engine := NewEngineImpl[map[string]any](
+				cfg, mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+				ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+			)
+			defer engine.Close()
+			ctx := context.Background()
+
+			result, err := engine.Exec(ctx, "kyc", map[string]any{"name": " Jon  Smith ", "listed": "John Smith"})
+			So(err, ShouldBeNil)
+			So(result["hit"], ShouldEqual, true)
+			So(result["soundex"], ShouldEqual, "J525")
+
+			_, err = engine.Exec(ctx, "kyc", map[string]any{"name": strings.Repeat("x", 21), "listed": "John Smith"})
+			So(err, ShouldNotBeNil)
+			So(errors.Is(err, ErrStringTooLong), ShouldBeTrue)
+		})
+	})
+}
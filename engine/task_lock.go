@@ -0,0 +1,31 @@
+package engine
+
+import (
+	"gitee.com/damengde/runehammer/lock"
+)
+
+// ============================================================================
+// 分布式任务锁 - 多实例部署时规则同步和数据清理每个周期只由一个实例执行
+// ============================================================================
+//
+// 锁的有效期取任务的执行间隔，持有锁的实例每个周期续期，停止后锁在有效期到期时由其他实例接管。
+// 编译缓存只保存在各实例内存中，未获取同步锁的实例仍会清理本实例的编译缓存。
+// 手动调用的 RunRetention 不受锁限制。
+
+// 任务锁名称
+const (
+	syncLockTask      = "sync"      // 规则同步
+	retentionLockTask = "retention" // 数据清理
+)
+
+// SetTaskLock 设置分布式任务锁，锁统计以 distributed_lock 输出到诊断快照
+//
+// 参数:
+//
+//	guard - 任务锁，为nil或未设置分布式锁时周期任务在每个实例执行
+func (e *engineImpl[T]) SetTaskLock(guard *lock.Guard) {
+	e.taskLock = guard
+	if guard.Enabled() {
+		e.RegisterDiagnostics("distributed_lock", func() any { return guard.Stats() })
+	}
+}
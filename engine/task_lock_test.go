@@ -0,0 +1,51 @@
+package engine
+
+import (
+	"testing"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	"gitee.com/damengde/runehammer/lock"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// TestTaskLock 测试分布式任务锁
+func TestTaskLock(t *testing.T) {
+	Convey("分布式任务锁", t, func() {
+		locker := lock.NewMemory()
+		newEngine := func(owner string) *engineImpl[map[string]any] {
+			e := NewEngineImpl[map[string]any](config.DefaultConfig(), nil, nil, cache.CacheKeyBuilder{}, nil, nil, nil, nil, false)
+			e.SetTaskLock(lock.NewGuard(locker, owner))
+			return e
+		}
+		a, b := newEngine("a"), newEngine("b")
+		defer a.Close()
+		defer b.Close()
+
+		Convey("同一周期只由一个实例执行同步，其他实例仍清理本实例编译缓存", func() {
+			So(a.syncRules(), ShouldBeNil)
+			b.knowledgeBases.Store("orders", struct{}{})
+			So(b.syncRules(), ShouldBeNil)
+
+			_, cached := b.knowledgeBases.Load("orders")
+			So(cached, ShouldBeFalse)
+			So(a.taskLock.Stats()[syncLockTask].Runs, ShouldEqual, 1)
+			So(b.taskLock.Stats()[syncLockTask].Skipped, ShouldEqual, 1)
+		})
+
+		Convey("锁统计输出到诊断快照", func() {
+			So(a.syncRules(), ShouldBeNil)
+			stats, ok := a.DebugSnapshot().Extra["distributed_lock"].(map[string]lock.TaskStats)
+			So(ok, ShouldBeTrue)
+			So(stats[syncLockTask].Runs, ShouldEqual, 1)
+		})
+
+		Convey("未设置分布式锁时每个实例都执行且不输出锁统计", func() {
+			e := NewEngineImpl[map[string]any](config.DefaultConfig(), nil, nil, cache.CacheKeyBuilder{}, nil, nil, nil, nil, false)
+			defer e.Close()
+			e.SetTaskLock(lock.NewGuard(nil, ""))
+			So(e.syncRules(), ShouldBeNil)
+			So(e.DebugSnapshot().Extra["distributed_lock"], ShouldBeNil)
+		})
+	})
+}
@@ -0,0 +1,29 @@
+package engine
+
+import (
+	"math"
+
+	"gitee.com/damengde/runehammer/rule"
+)
+
+// ============================================================================
+// 数值容差与单位函数 - 转换器为 approxEq 操作符及 Money/Percent 操作数生成的GRL函数
+// ============================================================================
+
+// ApproxEq 判断两个数值之差不超过容差，epsilon不大于0时使用 rule.DefaultEpsilon
+func (f *ruleFunctions) ApproxEq(a, b, epsilon float64) bool {
+	if epsilon <= 0 {
+		epsilon = rule.DefaultEpsilon
+	}
+	return math.Abs(a-b) <= epsilon
+}
+
+// Money 按币种精度舍入的金额，如 Money("JPY", 100.4) 为 100
+func (f *ruleFunctions) Money(currency string, amount float64) float64 {
+	return rule.RoundMoney(currency, amount)
+}
+
+// Percent 百分数对应的比例值，如 Percent(5) 为 0.05
+func (f *ruleFunctions) Percent(percent float64) float64 {
+	return rule.Percent(percent).Ratio()
+}
@@ -0,0 +1,77 @@
+package engine
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+// Payment 单位函数测试用的结构体输入
+type Payment struct {
+	Total float64
+	Fee   float64
+	Rate  float64
+}
+
+// TestUnitFunctions 测试数值容差与单位函数
+func TestUnitFunctions(t *testing.T) {
+	Convey("数值容差与单位函数", t, func() {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		converter := rule.NewGRLConverter(rule.ConverterConfig{StrictMode: true, VariablePrefix: map[string]string{"payment": "payment"}})
+		convert := func(id string, cond rule.Condition, actions ...rule.Action) *rule.Rule {
+			r := rule.NewStandardRule(id, id)
+			r.Conditions = cond
+			r.Actions = actions
+			grl, err := converter.ConvertRule(*r, rule.Definitions{})
+			So(err, ShouldBeNil)
+			return &rule.Rule{Name: id, Enabled: true, GRL: grl}
+		}
+
+		mapper := rule.NewMockRuleMapper(ctrl)
+		mapper.EXPECT().FindByBizCode(gomock.Any(), "pay").Return([]*rule.Rule{
+			// 0.1 + 0.2 与 0.3 直接比较不相等，按金额容差比较相等
+			convert("Total", rule.Field("payment.Total").Eq(rule.Money("USD", 0.3)),
+				rule.Action{Type: rule.ActionTypeAssign, Target: "Result.matched", Value: true}),
+			convert("Fee", rule.Field("payment.Fee").ApproxEq(0.015, 0.001),
+				rule.Action{Type: rule.ActionTypeAssign, Target: "Result.fee_ok", Value: true}),
+			convert("Rate", rule.Field("payment.Rate").Gte(rule.Percent(5)),
+				rule.Action{Type: rule.ActionTypeAssign, Target: "Result.discount", Value: rule.Percent(12.5)},
+				rule.Action{Type: rule.ActionTypeAssign, Target: "Result.yen", Value: rule.Money("JPY", 100.4)}),
+		}, nil).AnyTimes()
+
+		engine := NewEngineImpl[map[string]any](
+			config.DefaultConfig(), mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+			ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+		)
+		defer engine.Close()
+
+		Convey("按容差比较并换算单位", func() {
+			total := 0.1
+			result, err := engine.Exec(context.Background(), "pay", Payment{Total: total + 0.2, Fee: 0.0152, Rate: 0.05})
+			So(err, ShouldBeNil)
+			So(result["matched"], ShouldEqual, true)
+			So(result["fee_ok"], ShouldEqual, true)
+			So(result["discount"], ShouldEqual, 0.125)
+			So(result["yen"], ShouldEqual, 100)
+		})
+
+		Convey("超出容差不命中", func() {
+			result, err := engine.Exec(context.Background(), "pay", Payment{Total: 0.31, Fee: 0.017, Rate: 0.049})
+			So(err, ShouldBeNil)
+			So(result, ShouldNotContainKey, "matched")
+			So(result, ShouldNotContainKey, "fee_ok")
+			So(result, ShouldNotContainKey, "discount")
+		})
+	})
+}
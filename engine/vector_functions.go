@@ -0,0 +1,133 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+)
+
+// ============================================================================
+// 向量函数 - 规则中比较预先计算的向量（如欺诈画像embedding）
+// ============================================================================
+//
+// 向量可以是 []float64、[]any 等数值切片，或JSON数组字符串。维度不一致或元素不是数值时
+// 执行返回 ErrDimensionMismatch / ErrInvalidVector，条件中出错同样结束执行而不是视为不成立。
+
+var (
+	// ErrDimensionMismatch 两个向量的维度不一致
+	ErrDimensionMismatch = errors.New("向量维度不一致")
+	// ErrInvalidVector 参数不是数值向量
+	ErrInvalidVector = errors.New("无效的向量")
+)
+
+// toVector 转换为数值向量
+func toVector(value any) ([]float64, error) {
+	if v, ok := value.([]float64); ok {
+		return v, nil
+	}
+	if text, ok := value.(string); ok {
+		parsed, ok := parseJSON(text)
+		if !ok {
+			return nil, fmt.Errorf("%w: 无效的JSON数组", ErrInvalidVector)
+		}
+		value = parsed
+	}
+	items, ok := sliceItems(value)
+	if !ok {
+		return nil, fmt.Errorf("%w: 不支持的类型 %T", ErrInvalidVector, value)
+	}
+	vector := make([]float64, len(items))
+	for i, item := range items {
+		n, ok := toFloat(item)
+		if !ok {
+			return nil, fmt.Errorf("%w: 第%d个元素 %v 不是数值", ErrInvalidVector, i+1, item)
+		}
+		vector[i] = n
+	}
+	return vector, nil
+}
+
+// vectorPair 转换两个向量并检查维度
+func vectorPair(a, b any) ([]float64, []float64, error) {
+	va, err := toVector(a)
+	if err != nil {
+		return nil, nil, err
+	}
+	vb, err := toVector(b)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(va) != len(vb) {
+		return nil, nil, fmt.Errorf("%w: %d 与 %d", ErrDimensionMismatch, len(va), len(vb))
+	}
+	return va, vb, nil
+}
+
+// dotProduct 点积
+func dotProduct(a, b []float64) float64 {
+	sum := 0.0
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
+
+// norm 欧几里得范数
+func norm(v []float64) float64 {
+	return math.Sqrt(dotProduct(v, v))
+}
+
+// cosineSimilarity 余弦相似度 (-1~1)，任一向量为零向量时为0
+func cosineSimilarity(a, b []float64) float64 {
+	na, nb := norm(a), norm(b)
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dotProduct(a, b) / (na * nb)
+}
+
+// CosineSimilarity 余弦相似度 (-1~1)，任一向量为零向量时为0
+func (f *ruleFunctions) CosineSimilarity(a, b any) float64 {
+	va, vb, err := vectorPair(a, b)
+	if err != nil {
+		f.raise("CosineSimilarity", err)
+	}
+	return cosineSimilarity(va, vb)
+}
+
+// DotProduct 点积
+func (f *ruleFunctions) DotProduct(a, b any) float64 {
+	va, vb, err := vectorPair(a, b)
+	if err != nil {
+		f.raise("DotProduct", err)
+	}
+	return dotProduct(va, vb)
+}
+
+// Norm 欧几里得范数
+func (f *ruleFunctions) Norm(v any) float64 {
+	vector, err := toVector(v)
+	if err != nil {
+		f.raise("Norm", err)
+	}
+	return norm(vector)
+}
+
+// injectVectorFunctions 注入向量函数
+func injectVectorFunctions(dataCtx ast.IDataContext) {
+	dataCtx.Add("CosineSimilarity", func(a, b []float64) float64 {
+		if len(a) != len(b) {
+			panic(fmt.Errorf("CosineSimilarity: %w: %d 与 %d", ErrDimensionMismatch, len(a), len(b)))
+		}
+		return cosineSimilarity(a, b)
+	})
+	dataCtx.Add("DotProduct", func(a, b []float64) float64 {
+		if len(a) != len(b) {
+			panic(fmt.Errorf("DotProduct: %w: %d 与 %d", ErrDimensionMismatch, len(a), len(b)))
+		}
+		return dotProduct(a, b)
+	})
+	dataCtx.Add("Norm", norm)
+}
@@ -0,0 +1,89 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+// TestVectorFunctions 测试向量函数
+func TestVectorFunctions(t *testing.T) {
+	Convey("向量函数", t, func() {
+		Convey("点积、范数和余弦相似度", func() {
+			So(dotProduct([]float64{1, 2, 3}, []float64{4, 5, 6}), ShouldEqual, 32)
+			So(norm([]float64{3, 4}), ShouldEqual, 5)
+			So(cosineSimilarity([]float64{1, 0}, []float64{1, 0}), ShouldAlmostEqual, 1)
+			So(cosineSimilarity([]float64{1, 0}, []float64{0, 1}), ShouldAlmostEqual, 0)
+			So(cosineSimilarity([]float64{1, 2}, []float64{-1, -2}), ShouldAlmostEqual, -1)
+			So(cosineSimilarity([]float64{0, 0}, []float64{1, 1}), ShouldEqual, 0)
+		})
+
+		Convey("支持多种向量表示", func() {
+			v, err := toVector([]any{1, int64(2), 3.5})
+			So(err, ShouldBeNil)
+			So(v, ShouldResemble, []float64{1, 2, 3.5})
+
+			v, err = toVector("[0.5, -1]")
+			So(err, ShouldBeNil)
+			So(v, ShouldResemble, []float64{0.5, -1})
+
+			_, err = toVector([]any{1, "x"})
+			So(errors.Is(err, ErrInvalidVector), ShouldBeTrue)
+			_, err = toVector(42)
+			So(errors.Is(err, ErrInvalidVector), ShouldBeTrue)
+		})
+
+		Convey("维度不一致时报错", func() {
+			_, _, err := vectorPair([]float64{1, 2}, []float64{1, 2, 3})
+			So(errors.Is(err, ErrDimensionMismatch), ShouldBeTrue)
+			So(err.Error(), ShouldContainSubstring, "2 与 3")
+		})
+
+		Convey("规则中使用", func() {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mapper := rule.NewMockRuleMapper(ctrl)
+			mapper.EXPECT().FindByBizCode(gomock.Any(), "fraud").Return([]*rule.Rule{
+				{Name: "profile", Enabled: true, GRL: `rule Profile "画像相似" {
+	when CosineSimilarity(Params["embedding"], Params["fraudProfile"]) > 0.9
+	then
+		Result["suspicious"] = true;
+		Result["strength"] = Norm(Params["embedding"]);
+		Retract("Profile");
+}`},
+			}, nil).AnyTimes()
+
+			engine := NewEngineImpl[map[string]any](
+				config.DefaultConfig(), mapper, nil, cache.CacheKeyBuilder{}, logger.NewNoopLogger(),
+				ast.NewKnowledgeLibrary(), &sync.Map{}, cron.New(), false,
+			)
+			defer engine.Close()
+			ctx := context.Background()
+
+			result, err := engine.Exec(ctx, "fraud", map[string]any{
+				"embedding":    []any{0.6, 0.8, 0.0},
+				"fraudProfile": []float64{0.6, 0.79, 0.01},
+			})
+			So(err, ShouldBeNil)
+			So(result["suspicious"], ShouldEqual, true)
+			So(result["strength"], ShouldAlmostEqual, 1)
+
+			_, err = engine.Exec(ctx, "fraud", map[string]any{
+				"embedding":    []any{0.6, 0.8},
+				"fraudProfile": []float64{0.6, 0.79, 0.01},
+			})
+			So(errors.Is(err, ErrDimensionMismatch), ShouldBeTrue)
+		})
+	})
+}
@@ -0,0 +1,60 @@
+package runehammer
+
+import (
+	"reflect"
+	"sync"
+)
+
+// ============================================================================
+// 引擎管理器 - 多种结果类型共享同一个底层引擎
+// ============================================================================
+//
+// 每个 New[T] 都会创建独立的数据库连接、缓存、定时同步任务和知识库。业务码较多、
+// 结果类型各不相同时，应通过 EngineManager 共享一个底层引擎，按结果类型获取强类型视图。
+// 视图只做结果类型转换，创建开销很小，同一类型的视图只创建一次。
+
+// EngineManager 引擎管理器 - 持有共享的底层引擎，按结果类型提供 TypedEngine 视图
+type EngineManager struct {
+	base  BaseEngine
+	views sync.Map // reflect.Type -> *TypedEngine[T]
+}
+
+// NewEngineManager 创建引擎管理器 - 选项与 New 相同，所有视图共享配置、规则映射器、缓存和定时任务
+//
+// 使用示例:
+//
+//	manager, err := NewEngineManager(WithDSN(dsn), WithRedisCache("localhost:6379", "", 0))
+//	defer manager.Close()
+//
+//	risk, err := Typed[RiskResult](manager).Exec(ctx, "RISK_CHECK", input)
+//	price, err := Typed[PriceResult](manager).Exec(ctx, "PRICING", input)
+func NewEngineManager(opts ...Option) (*EngineManager, error) {
+	base, err := NewBaseEngine(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &EngineManager{base: base}, nil
+}
+
+// Base 获取共享的底层引擎
+func (m *EngineManager) Base() BaseEngine {
+	return m.base
+}
+
+// Close 关闭共享的底层引擎，关闭后所有视图的执行都返回 ErrEngineClosed
+func (m *EngineManager) Close() error {
+	return m.base.Close()
+}
+
+// Typed 获取结果类型为T的引擎视图
+//
+// Go不支持泛型方法，因此以函数形式提供（对应 manager.Typed[T]()）。同一类型返回同一视图，
+// 视图的 Close 不关闭共享引擎，由 EngineManager.Close 统一关闭。
+func Typed[T any](m *EngineManager) *TypedEngine[T] {
+	key := reflect.TypeOf((*T)(nil)).Elem()
+	if view, ok := m.views.Load(key); ok {
+		return view.(*TypedEngine[T])
+	}
+	view, _ := m.views.LoadOrStore(key, &TypedEngine[T]{base: m.base, shared: true})
+	return view.(*TypedEngine[T])
+}
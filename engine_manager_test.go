@@ -0,0 +1,72 @@
+package runehammer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gitee.com/damengde/runehammer/rule"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+)
+
+// RiskResult 引擎管理器测试用的结果类型
+type RiskResult struct {
+	Level string `json:"level"`
+}
+
+// PriceResult 引擎管理器测试用的结果类型
+type PriceResult struct {
+	Discount float64 `json:"discount"`
+}
+
+// TestEngineManager 测试引擎管理器
+func TestEngineManager(t *testing.T) {
+	Convey("引擎管理器", t, func() {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mapper := rule.NewMockRuleMapper(ctrl)
+		mapper.EXPECT().FindByBizCode(gomock.Any(), "RISK").Return([]*rule.Rule{
+			{Name: "risk", Enabled: true, GRL: `rule Risk "风险" { when Params["score"] < 600 then Result["level"] = "high"; Retract("Risk"); }`},
+		}, nil).AnyTimes()
+		mapper.EXPECT().FindByBizCode(gomock.Any(), "PRICE").Return([]*rule.Rule{
+			{Name: "price", Enabled: true, GRL: `rule Price "定价" { when Params["vip"] == true then Result["discount"] = 0.8; Retract("Price"); }`},
+		}, nil).AnyTimes()
+
+		manager, err := NewEngineManager(
+			WithDSN("sqlite:file:engine_manager.db?mode=memory&cache=shared&_fk=1"),
+			WithCustomRuleMapper(mapper),
+			WithNoCache(),
+		)
+		So(err, ShouldBeNil)
+		defer manager.Close()
+
+		Convey("不同结果类型共享底层引擎", func() {
+			risk, err := Typed[RiskResult](manager).Exec(context.Background(), "RISK", map[string]any{"score": 550})
+			So(err, ShouldBeNil)
+			So(risk.Level, ShouldEqual, "high")
+
+			price, err := Typed[PriceResult](manager).Exec(context.Background(), "PRICE", map[string]any{"vip": true})
+			So(err, ShouldBeNil)
+			So(price.Discount, ShouldEqual, 0.8)
+
+			So(manager.Base().(Diagnostics).KnowledgeBaseMemory(), ShouldHaveLength, 2)
+		})
+
+		Convey("同一类型复用视图", func() {
+			So(Typed[RiskResult](manager), ShouldEqual, Typed[RiskResult](manager))
+			So(Typed[*RiskResult](manager), ShouldNotBeNil)
+		})
+
+		Convey("视图的Close不关闭共享引擎", func() {
+			So(Typed[RiskResult](manager).Close(), ShouldBeNil)
+			_, err := Typed[PriceResult](manager).Exec(context.Background(), "PRICE", map[string]any{"vip": true})
+			So(err, ShouldBeNil)
+
+			So(manager.Close(), ShouldBeNil)
+			_, err = Typed[RiskResult](manager).Exec(context.Background(), "RISK", map[string]any{"score": 550})
+			So(errors.Is(err, ErrEngineClosed), ShouldBeTrue)
+		})
+	})
+}
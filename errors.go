@@ -1,9 +1,218 @@
 package runehammer
 
-import "errors"
+import (
+	"errors"
+
+	"gitee.com/damengde/runehammer/engine"
+	"gitee.com/damengde/runehammer/rule"
+)
 
 // ErrNoDatabaseConfig 未配置数据库错误
 var ErrNoDatabaseConfig = errors.New("no database configuration provided")
 
+// ErrReadOnly 引擎为只读模式，拒绝写操作，可通过errors.Is判断
+var ErrReadOnly = engine.ErrReadOnly
+
+// ErrBizDisabled 业务码已被紧急停用（Disable），可通过errors.Is判断
+var ErrBizDisabled = engine.ErrBizDisabled
+
+// ErrOverrideNotFound 运行时覆盖规则不存在，可通过errors.Is判断
+var ErrOverrideNotFound = engine.ErrOverrideNotFound
+
+// ErrInvalidationNotSupported 缓存不支持按前缀失效幂等结果（自定义缓存未实现 cache.PrefixDeleter），可通过errors.Is判断
+var ErrInvalidationNotSupported = engine.ErrInvalidationNotSupported
+
+// ErrSchemaMismatch 数据库表结构与模型不一致，可通过errors.Is判断
+var ErrSchemaMismatch = rule.ErrSchemaMismatch
+
+// SchemaError 表结构检查错误 - 包含全部不一致项
+type SchemaError = rule.SchemaError
+
+// SchemaMismatch 表结构不一致项
+type SchemaMismatch = rule.SchemaMismatch
+
+// ErrExplainUnsupported 数据库类型不支持查询计划诊断，可通过errors.Is判断
+var ErrExplainUnsupported = rule.ErrExplainUnsupported
+
 // ErrInvalidConfig 无效配置错误  
-var ErrInvalidConfig = errors.New("invalid configuration")
\ No newline at end of file
+var ErrInvalidConfig = errors.New("invalid configuration")
+
+// ErrOptionConflict 严格选项模式下存在冲突的选项，可通过errors.Is判断
+var ErrOptionConflict = errors.New("配置选项冲突")
+
+// ErrNoRuleMapper 引擎未配置规则映射器 - 执行时记录为fetch类近期错误
+var ErrNoRuleMapper = engine.ErrNoRuleMapper
+
+// ErrKnowledgeBaseNotFound 登记预编译知识库时知识库中不存在指定名称和版本，可通过errors.Is判断
+var ErrKnowledgeBaseNotFound = engine.ErrKnowledgeBaseNotFound
+
+// ErrEngineClosed 引擎已关闭，可通过errors.Is判断
+var ErrEngineClosed = engine.ErrEngineClosed
+
+// ErrInputTypeNotRegistered 业务码未注册输入类型，可通过errors.Is判断
+var ErrInputTypeNotRegistered = engine.ErrInputTypeNotRegistered
+
+// ErrCloneNotSupported 规则映射器不支持复制规则，可通过errors.Is判断
+var ErrCloneNotSupported = engine.ErrCloneNotSupported
+
+// ErrVersionNotFound 请求的规则集版本不可用（非最新版本且未被保留），可通过errors.Is判断
+var ErrVersionNotFound = engine.ErrVersionNotFound
+
+// ErrRuleTestsNotSupported 规则映射器不支持测试用例，可通过errors.Is判断
+var ErrRuleTestsNotSupported = engine.ErrRuleTestsNotSupported
+
+// ErrRuleTestsFailed 规则测试未全部通过，可通过errors.Is判断
+var ErrRuleTestsFailed = engine.ErrRuleTestsFailed
+
+// ErrUndeclaredWrite 规则写入了未声明的结果字段（WriteCheckError模式），可通过errors.Is判断
+var ErrUndeclaredWrite = engine.ErrUndeclaredWrite
+
+// ErrCostBudgetExceeded 规则集预估成本超出业务码的延迟预算（CostCheckError模式），可通过errors.Is判断
+var ErrCostBudgetExceeded = engine.ErrCostBudgetExceeded
+
+// ErrRuleLimitExceeded 规则集超出规模或复杂度限制（WithRuleLimits），具体超限项通过errors.As获取*RuleLimitError
+var ErrRuleLimitExceeded = engine.ErrRuleLimitExceeded
+
+// ErrStringTooLong 相似度函数的输入超过最大长度（WithSimilarityMaxLength），可通过errors.Is判断
+var ErrStringTooLong = engine.ErrStringTooLong
+
+// ErrInvalidBands Bucket/Band 的阈值或标签无效，可通过errors.Is判断
+var ErrInvalidBands = engine.ErrInvalidBands
+
+// ErrNonFiniteResult Result中出现NaN或±Inf（WithNonFinitePolicy(NonFiniteError)），可通过errors.Is判断
+var ErrNonFiniteResult = engine.ErrNonFiniteResult
+
+// ErrDimensionMismatch 向量函数的两个向量维度不一致，可通过errors.Is判断
+var ErrDimensionMismatch = engine.ErrDimensionMismatch
+
+// ErrInvalidVector 向量函数的参数不是数值向量，可通过errors.Is判断
+var ErrInvalidVector = engine.ErrInvalidVector
+
+// ErrNoRateProvider 规则调用 ConvertCurrency 换算不同货币但未设置汇率提供者（WithRateProvider），可通过errors.Is判断
+var ErrNoRateProvider = engine.ErrNoRateProvider
+
+// ErrStaleRate 汇率超过最大时效（WithRateMaxAge），可通过errors.Is判断
+var ErrStaleRate = engine.ErrStaleRate
+
+// ErrNoCalendar 规则调用日历函数但未设置节假日日历（WithCalendar），可通过errors.Is判断
+var ErrNoCalendar = engine.ErrNoCalendar
+
+// ErrUnknownRegion 节假日日历中没有规则使用的地区，可通过errors.Is判断
+var ErrUnknownRegion = engine.ErrUnknownRegion
+
+// ErrQuotaExceeded 租户超出执行配额，可通过errors.Is判断
+var ErrQuotaExceeded = engine.ErrQuotaExceeded
+
+// ErrInvalidBizCode 执行时业务码为空，可通过errors.Is判断
+var ErrInvalidBizCode = engine.ErrInvalidBizCode
+
+// ErrNilInput 执行时输入参数为空，可通过errors.Is判断
+var ErrNilInput = engine.ErrNilInput
+
+// ErrRulesNotFound 业务码没有有效规则且未配置降级结果，可通过errors.Is判断
+var ErrRulesNotFound = engine.ErrRulesNotFound
+
+// ErrCompileFailed 规则编译失败，可通过errors.Is判断
+var ErrCompileFailed = engine.ErrCompileFailed
+
+// ErrInjectFailed 输入数据注入失败，可通过errors.Is判断
+var ErrInjectFailed = engine.ErrInjectFailed
+
+// ErrExecFailed 规则执行失败，可通过errors.Is判断
+var ErrExecFailed = engine.ErrExecFailed
+
+// ErrResultFailed 结果提取失败，可通过errors.Is判断
+var ErrResultFailed = engine.ErrResultFailed
+
+// ErrRulePanic 规则执行发生panic，可通过errors.Is判断
+var ErrRulePanic = engine.ErrRulePanic
+
+// RulePanicError 规则panic详情 - 包含规则ID和堆栈
+type RulePanicError = engine.RulePanicError
+
+// RuleError 规则执行错误 - 出错规则设置了负责人或文档链接时包装原错误，包含规则名、负责人和文档链接
+type RuleError = engine.RuleError
+
+// ErrFunctionTimeout 自定义函数执行超时，可通过errors.Is判断
+var ErrFunctionTimeout = engine.ErrFunctionTimeout
+
+// FunctionTimeoutError 自定义函数超时详情 - 包含函数名和超时时间
+type FunctionTimeoutError = engine.FunctionTimeoutError
+
+// ErrInvalidFunction 自定义函数签名不合法，可通过errors.Is判断
+var ErrInvalidFunction = engine.ErrInvalidFunction
+
+// ErrorRecord 近期错误记录
+type ErrorRecord = engine.ErrorRecord
+
+// ErrorClass 错误分类
+type ErrorClass = engine.ErrorClass
+
+// 错误分类枚举
+const (
+	ErrorClassFetch      = engine.ErrorClassFetch      // 规则获取失败
+	ErrorClassCompile    = engine.ErrorClassCompile    // 规则编译失败
+	ErrorClassConversion = engine.ErrorClassConversion // 数据注入或结果转换失败
+	ErrorClassTimeout    = engine.ErrorClassTimeout    // 执行超时或取消
+	ErrorClassExecution  = engine.ErrorClassExecution  // 规则执行失败
+	ErrorClassPanic      = engine.ErrorClassPanic      // 规则执行发生panic
+)
+
+// LocalizedError 带错误码的本地化错误 - Exec 返回的错误均为该类型，按 WithLocale 的区域输出错误信息
+type LocalizedError = engine.LocalizedError
+
+// 错误信息区域
+const (
+	LocaleZhCN = engine.LocaleZhCN // 中文，未设置区域时使用
+	LocaleEnUS = engine.LocaleEnUS // 英文，中文以外的区域均使用
+)
+
+// 错误码，不随区域变化，用于日志聚合和告警
+const (
+	ErrCodeEngineClosed          = engine.ErrCodeEngineClosed
+	ErrCodeInvalidBizCode        = engine.ErrCodeInvalidBizCode
+	ErrCodeNilInput              = engine.ErrCodeNilInput
+	ErrCodeBizDisabled           = engine.ErrCodeBizDisabled
+	ErrCodeQuotaExceeded         = engine.ErrCodeQuotaExceeded
+	ErrCodeReadOnly              = engine.ErrCodeReadOnly
+	ErrCodeNoRuleMapper          = engine.ErrCodeNoRuleMapper
+	ErrCodeRulesNotFound         = engine.ErrCodeRulesNotFound
+	ErrCodeVersionNotFound       = engine.ErrCodeVersionNotFound
+	ErrCodeOverrideNotFound      = engine.ErrCodeOverrideNotFound
+	ErrCodeKnowledgeBaseNotFound = engine.ErrCodeKnowledgeBaseNotFound
+	ErrCodeInputTypeNotFound     = engine.ErrCodeInputTypeNotFound
+	ErrCodeCostBudgetExceeded    = engine.ErrCodeCostBudgetExceeded
+	ErrCodeRuleLimitExceeded     = engine.ErrCodeRuleLimitExceeded
+	ErrCodeUndeclaredWrite       = engine.ErrCodeUndeclaredWrite
+	ErrCodeInvalidFunction       = engine.ErrCodeInvalidFunction
+	ErrCodeFunctionTimeout       = engine.ErrCodeFunctionTimeout
+	ErrCodeRulePanic             = engine.ErrCodeRulePanic
+	ErrCodeNonFiniteResult       = engine.ErrCodeNonFiniteResult
+	ErrCodeStringTooLong         = engine.ErrCodeStringTooLong
+	ErrCodeInvalidBands          = engine.ErrCodeInvalidBands
+	ErrCodeInvalidVector         = engine.ErrCodeInvalidVector
+	ErrCodeNoRateProvider        = engine.ErrCodeNoRateProvider
+	ErrCodeStaleRate             = engine.ErrCodeStaleRate
+	ErrCodeNoCalendar            = engine.ErrCodeNoCalendar
+	ErrCodeUnknownRegion         = engine.ErrCodeUnknownRegion
+	ErrCodeCompileFailed         = engine.ErrCodeCompileFailed
+	ErrCodeInjectFailed          = engine.ErrCodeInjectFailed
+	ErrCodeExecFailed            = engine.ErrCodeExecFailed
+	ErrCodeResultFailed          = engine.ErrCodeResultFailed
+	ErrCodeUnknown               = engine.ErrCodeUnknown
+)
+
+// ErrorCode 获取错误的错误码，err为nil时返回空字符串，无法分类时返回 ErrCodeUnknown
+func ErrorCode(err error) string {
+	return engine.ErrorCode(err)
+}
+
+// ErrorMessage 错误码在区域中的说明
+func ErrorMessage(code, locale string) string {
+	return engine.ErrorMessage(code, locale)
+}
+
+// LocalizeError 把任意错误包装为指定区域的 *LocalizedError - 用于 Exec 以外的方法返回的错误
+func LocalizeError(err error, locale string) error {
+	return engine.LocalizeError(err, locale, "")
+}
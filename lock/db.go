@@ -0,0 +1,89 @@
+package lock
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Record 数据库锁记录
+//
+// 表名：runehammer_locks
+type Record struct {
+	Name      string    `gorm:"primaryKey;size:191" json:"name"`  // 锁名称
+	Owner     string    `gorm:"size:191;not null" json:"owner"`   // 持有者标识
+	ExpiresAt time.Time `gorm:"not null;index" json:"expires_at"` // 过期时间，过期后可被其他实例接管
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"` // 最近一次获取或续期的时间
+}
+
+// TableName 自定义表名
+func (Record) TableName() string {
+	return "runehammer_locks"
+}
+
+// DB 基于数据库锁表的分布式锁 - 锁记录持久化，不依赖Redis，可接管过期锁并区分为 TakenOver
+//
+// 过期判断使用各实例的本地时间，实例间时钟偏差应远小于锁的有效期。
+type DB struct {
+	db  *gorm.DB
+	now func() time.Time
+}
+
+// NewDB 创建数据库分布式锁，锁表需先通过 Migrate 创建
+func NewDB(db *gorm.DB) *DB {
+	return &DB{db: db, now: time.Now}
+}
+
+// Migrate 创建或更新锁表
+func (d *DB) Migrate() error {
+	if err := d.db.AutoMigrate(&Record{}); err != nil {
+		return fmt.Errorf("锁表迁移失败: %w", err)
+	}
+	return nil
+}
+
+// Acquire 尝试获取锁 - 依次尝试续期自己的锁、接管过期的锁、插入新锁，每一步都是单条原子语句
+func (d *DB) Acquire(ctx context.Context, key, owner string, ttl time.Duration) (Status, error) {
+	now := d.now()
+	expiresAt := now.Add(ttl)
+	db := d.db.WithContext(ctx).Model(&Record{})
+
+	renewed := db.Where("name = ? AND owner = ?", key, owner).
+		Updates(map[string]any{"expires_at": expiresAt, "updated_at": now})
+	if renewed.Error != nil {
+		return Held, fmt.Errorf("续期锁 %s 失败: %w", key, renewed.Error)
+	}
+	if renewed.RowsAffected > 0 {
+		return Acquired, nil
+	}
+
+	taken := d.db.WithContext(ctx).Model(&Record{}).Where("name = ? AND expires_at < ?", key, now).
+		Updates(map[string]any{"owner": owner, "expires_at": expiresAt, "updated_at": now})
+	if taken.Error != nil {
+		return Held, fmt.Errorf("接管锁 %s 失败: %w", key, taken.Error)
+	}
+	if taken.RowsAffected > 0 {
+		return TakenOver, nil
+	}
+
+	created := d.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&Record{Name: key, Owner: owner, ExpiresAt: expiresAt, UpdatedAt: now})
+	if created.Error != nil {
+		return Held, fmt.Errorf("创建锁 %s 失败: %w", key, created.Error)
+	}
+	if created.RowsAffected > 0 {
+		return Acquired, nil
+	}
+	return Held, nil
+}
+
+// Release 释放自己持有的锁
+func (d *DB) Release(ctx context.Context, key, owner string) error {
+	if err := d.db.WithContext(ctx).Where("name = ? AND owner = ?", key, owner).Delete(&Record{}).Error; err != nil {
+		return fmt.Errorf("释放锁 %s 失败: %w", key, err)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package lock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// TestDB 测试数据库锁
+func TestDB(t *testing.T) {
+	Convey("数据库锁", t, func() {
+		db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+		So(err, ShouldBeNil)
+
+		ctx := context.Background()
+		now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		locker := NewDB(db)
+		locker.now = func() time.Time { return now }
+		So(locker, ShouldImplement, (*Locker)(nil))
+
+		Convey("锁表不存在时返回错误", func() {
+			_, err := locker.Acquire(ctx, "sync", "a", time.Minute)
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("获取、续期、接管和释放", func() {
+			So(locker.Migrate(), ShouldBeNil)
+
+			status, err := locker.Acquire(ctx, "sync", "a", time.Minute)
+			So(err, ShouldBeNil)
+			So(status, ShouldEqual, Acquired)
+			status, _ = locker.Acquire(ctx, "sync", "b", time.Minute)
+			So(status, ShouldEqual, Held)
+
+			now = now.Add(50 * time.Second)
+			status, _ = locker.Acquire(ctx, "sync", "a", time.Minute)
+			So(status, ShouldEqual, Acquired)
+			now = now.Add(50 * time.Second)
+			status, _ = locker.Acquire(ctx, "sync", "b", time.Minute)
+			So(status, ShouldEqual, Held)
+
+			now = now.Add(2 * time.Minute)
+			status, _ = locker.Acquire(ctx, "sync", "b", time.Minute)
+			So(status, ShouldEqual, TakenOver)
+
+			var record Record
+			So(db.First(&record, "name = ?", "sync").Error, ShouldBeNil)
+			So(record.Owner, ShouldEqual, "b")
+
+			So(locker.Release(ctx, "sync", "a"), ShouldBeNil)
+			status, _ = locker.Acquire(ctx, "sync", "a", time.Minute)
+			So(status, ShouldEqual, Held)
+			So(locker.Release(ctx, "sync", "b"), ShouldBeNil)
+			status, _ = locker.Acquire(ctx, "sync", "a", time.Minute)
+			So(status, ShouldEqual, Acquired)
+		})
+	})
+}
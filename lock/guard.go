@@ -0,0 +1,162 @@
+package lock
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// KeyPrefix 任务锁名称前缀
+const KeyPrefix = "runehammer:lock:"
+
+// retryInterval Run 等待锁释放时的重试间隔
+const retryInterval = 200 * time.Millisecond
+
+// TaskStats 任务的锁统计
+type TaskStats struct {
+	Runs      int64     `json:"runs"`                 // 获取锁并执行的次数
+	Skipped   int64     `json:"skipped"`              // 锁由其他实例持有而跳过（一次性任务为等待）的次数
+	TakeOvers int64     `json:"takeovers"`            // 接管其他实例过期锁的次数
+	Errors    int64     `json:"errors"`               // 锁存储访问失败的次数
+	LastRun   time.Time `json:"last_run"`             // 最近一次执行时间
+	LastError string    `json:"last_error,omitempty"` // 最近一次锁存储访问的错误
+}
+
+// Guard 任务锁 - 以分布式锁保证任务同一时间只由一个实例执行，并按任务统计锁的获取情况
+//
+// 未设置锁（locker为nil）时任务总是执行，便于单实例部署使用同一代码路径。
+type Guard struct {
+	locker Locker
+	owner  string
+	mu     sync.Mutex
+	stats  map[string]*TaskStats
+}
+
+// NewGuard 创建任务锁
+//
+// 参数:
+//
+//	locker - 分布式锁，为nil时不加锁
+//	owner  - 本实例的持有者标识，为空时使用 NewOwnerID 生成
+func NewGuard(locker Locker, owner string) *Guard {
+	if owner == "" {
+		owner = NewOwnerID()
+	}
+	return &Guard{locker: locker, owner: owner, stats: make(map[string]*TaskStats)}
+}
+
+// Owner 本实例的持有者标识
+func (g *Guard) Owner() string {
+	return g.owner
+}
+
+// Enabled 是否设置了分布式锁
+func (g *Guard) Enabled() bool {
+	return g != nil && g.locker != nil
+}
+
+// TryRun 执行周期任务 - 锁由其他实例持有时跳过本次执行
+//
+// 执行后不释放锁，同一周期内其他实例的执行被跳过；本实例下次执行时续期，
+// 本实例停止后锁在ttl到期时由其他实例接管。ttl通常取任务的执行间隔。
+//
+// 返回值:
+//
+//	bool  - 是否执行了任务
+//	error - 获取锁失败或任务返回的错误
+func (g *Guard) TryRun(ctx context.Context, task string, ttl time.Duration, fn func(ctx context.Context) error) (bool, error) {
+	if !g.Enabled() {
+		return true, fn(ctx)
+	}
+
+	status, err := g.acquire(ctx, task, ttl)
+	if err != nil {
+		return false, err
+	}
+	if status == Held {
+		g.record(task, func(s *TaskStats) { s.Skipped++ })
+		return false, nil
+	}
+	return true, fn(ctx)
+}
+
+// Run 执行一次性任务 - 锁由其他实例持有时等待其释放或过期，执行完成后释放锁
+//
+// 用于数据库迁移等所有实例都需要在其完成后才能继续的任务：等待的实例在持有者完成后
+// 自己再执行一次，任务应当幂等。
+//
+// 返回值:
+//
+//	error - 上下文取消、获取锁失败或任务返回的错误
+func (g *Guard) Run(ctx context.Context, task string, ttl time.Duration, fn func(ctx context.Context) error) error {
+	if !g.Enabled() {
+		return fn(ctx)
+	}
+
+	for waited := false; ; waited = true {
+		status, err := g.acquire(ctx, task, ttl)
+		if err != nil {
+			return err
+		}
+		if status != Held {
+			break
+		}
+		if !waited {
+			g.record(task, func(s *TaskStats) { s.Skipped++ })
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("等待任务 %s 的分布式锁超时: %w", task, ctx.Err())
+		case <-time.After(retryInterval):
+		}
+	}
+	defer g.locker.Release(context.WithoutCancel(ctx), KeyPrefix+task, g.owner)
+	return fn(ctx)
+}
+
+// acquire 获取任务锁并记录统计
+func (g *Guard) acquire(ctx context.Context, task string, ttl time.Duration) (Status, error) {
+	status, err := g.locker.Acquire(ctx, KeyPrefix+task, g.owner, ttl)
+	if err != nil {
+		g.record(task, func(s *TaskStats) {
+			s.Errors++
+			s.LastError = err.Error()
+		})
+		return Held, fmt.Errorf("获取任务 %s 的分布式锁失败: %w", task, err)
+	}
+	if status != Held {
+		g.record(task, func(s *TaskStats) {
+			s.Runs++
+			s.LastRun = time.Now()
+			if status == TakenOver {
+				s.TakeOvers++
+			}
+		})
+	}
+	return status, nil
+}
+
+// record 更新任务统计
+func (g *Guard) record(task string, update func(s *TaskStats)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	stats, ok := g.stats[task]
+	if !ok {
+		stats = &TaskStats{}
+		g.stats[task] = stats
+	}
+	update(stats)
+}
+
+// Stats 各任务的锁统计快照
+func (g *Guard) Stats() map[string]TaskStats {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	snapshot := make(map[string]TaskStats, len(g.stats))
+	for task, stats := range g.stats {
+		snapshot[task] = *stats
+	}
+	return snapshot
+}
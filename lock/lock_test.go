@@ -0,0 +1,143 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// failingLocker 总是返回错误的锁
+type failingLocker struct{}
+
+func (failingLocker) Acquire(context.Context, string, string, time.Duration) (Status, error) {
+	return Held, errors.New("连接失败")
+}
+
+func (failingLocker) Release(context.Context, string, string) error { return nil }
+
+// TestMemory 测试进程内锁
+func TestMemory(t *testing.T) {
+	Convey("进程内锁", t, func() {
+		ctx := context.Background()
+		now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		locker := NewMemory()
+		locker.now = func() time.Time { return now }
+		So(locker, ShouldImplement, (*Locker)(nil))
+
+		status, err := locker.Acquire(ctx, "sync", "a", time.Minute)
+		So(err, ShouldBeNil)
+		So(status, ShouldEqual, Acquired)
+
+		Convey("其他实例在有效期内无法获取，持有者可续期", func() {
+			status, _ = locker.Acquire(ctx, "sync", "b", time.Minute)
+			So(status, ShouldEqual, Held)
+			now = now.Add(50 * time.Second)
+			status, _ = locker.Acquire(ctx, "sync", "a", time.Minute)
+			So(status, ShouldEqual, Acquired)
+			now = now.Add(50 * time.Second)
+			status, _ = locker.Acquire(ctx, "sync", "b", time.Minute)
+			So(status, ShouldEqual, Held)
+		})
+
+		Convey("过期后由其他实例接管", func() {
+			now = now.Add(2 * time.Minute)
+			status, _ = locker.Acquire(ctx, "sync", "b", time.Minute)
+			So(status, ShouldEqual, TakenOver)
+			So(status.String(), ShouldEqual, "taken_over")
+		})
+
+		Convey("只释放自己持有的锁", func() {
+			So(locker.Release(ctx, "sync", "b"), ShouldBeNil)
+			status, _ = locker.Acquire(ctx, "sync", "b", time.Minute)
+			So(status, ShouldEqual, Held)
+			So(locker.Release(ctx, "sync", "a"), ShouldBeNil)
+			status, _ = locker.Acquire(ctx, "sync", "b", time.Minute)
+			So(status, ShouldEqual, Acquired)
+		})
+	})
+}
+
+// TestGuard 测试任务锁
+func TestGuard(t *testing.T) {
+	Convey("任务锁", t, func() {
+		ctx := context.Background()
+		locker := NewMemory()
+		a, b := NewGuard(locker, "a"), NewGuard(locker, "b")
+		var runs atomic.Int64
+		task := func(context.Context) error {
+			runs.Add(1)
+			return nil
+		}
+
+		Convey("周期任务同一周期只由一个实例执行", func() {
+			ran, err := a.TryRun(ctx, "sync", time.Minute, task)
+			So(err, ShouldBeNil)
+			So(ran, ShouldBeTrue)
+			ran, err = b.TryRun(ctx, "sync", time.Minute, task)
+			So(err, ShouldBeNil)
+			So(ran, ShouldBeFalse)
+			ran, _ = a.TryRun(ctx, "sync", time.Minute, task)
+			So(ran, ShouldBeTrue)
+			So(runs.Load(), ShouldEqual, 2)
+
+			So(a.Stats()["sync"].Runs, ShouldEqual, 2)
+			So(b.Stats()["sync"].Skipped, ShouldEqual, 1)
+		})
+
+		Convey("接管过期锁计入统计", func() {
+			locker.now = func() time.Time { return time.Now().Add(-time.Hour) }
+			_, _ = a.TryRun(ctx, "sync", time.Minute, task)
+			locker.now = time.Now
+			ran, _ := b.TryRun(ctx, "sync", time.Minute, task)
+			So(ran, ShouldBeTrue)
+			So(b.Stats()["sync"].TakeOvers, ShouldEqual, 1)
+		})
+
+		Convey("一次性任务等待持有者释放后执行", func() {
+			_, _ = a.TryRun(ctx, "migrate", time.Minute, task)
+			go func() {
+				time.Sleep(50 * time.Millisecond)
+				_ = locker.Release(ctx, KeyPrefix+"migrate", "a")
+			}()
+			So(b.Run(ctx, "migrate", time.Minute, task), ShouldBeNil)
+			So(runs.Load(), ShouldEqual, 2)
+			So(b.Stats()["migrate"].Skipped, ShouldEqual, 1)
+
+			// 执行完成后释放锁
+			status, _ := locker.Acquire(ctx, KeyPrefix+"migrate", "c", time.Minute)
+			So(status, ShouldEqual, Acquired)
+		})
+
+		Convey("等待超时返回错误", func() {
+			_, _ = a.TryRun(ctx, "migrate", time.Minute, task)
+			timeout, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+			defer cancel()
+			err := b.Run(timeout, "migrate", time.Minute, task)
+			So(errors.Is(err, context.DeadlineExceeded), ShouldBeTrue)
+		})
+
+		Convey("锁存储失败时不执行并记录错误", func() {
+			guard := NewGuard(failingLocker{}, "a")
+			ran, err := guard.TryRun(ctx, "sync", time.Minute, task)
+			So(ran, ShouldBeFalse)
+			So(err.Error(), ShouldContainSubstring, "连接失败")
+			So(guard.Stats()["sync"].Errors, ShouldEqual, 1)
+			So(guard.Run(ctx, "migrate", time.Minute, task), ShouldNotBeNil)
+			So(runs.Load(), ShouldEqual, 0)
+		})
+
+		Convey("未设置锁时总是执行", func() {
+			var guard *Guard
+			ran, err := guard.TryRun(ctx, "sync", time.Minute, task)
+			So(ran, ShouldBeTrue)
+			So(err, ShouldBeNil)
+			So(NewGuard(nil, "").Run(ctx, "migrate", time.Minute, task), ShouldBeNil)
+			So(NewGuard(nil, "").Owner(), ShouldNotBeEmpty)
+			So(runs.Load(), ShouldEqual, 2)
+		})
+	})
+}
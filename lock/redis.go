@@ -0,0 +1,61 @@
+package lock
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// acquireScript 持有者相同时续期，否则仅在锁不存在时设置
+var acquireScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	redis.call("PEXPIRE", KEYS[1], ARGV[2])
+	return 1
+end
+if redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then
+	return 1
+end
+return 0
+`)
+
+// releaseScript 仅删除自己持有的锁
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// Redis 基于Redis SET NX的分布式锁 - 多进程部署时使用
+//
+// 过期的锁由Redis自动删除，接管过期锁时返回 Acquired，无法区分为 TakenOver。
+type Redis struct {
+	client *redis.Client
+}
+
+// NewRedis 创建Redis分布式锁，可与Redis缓存共用客户端
+func NewRedis(client *redis.Client) *Redis {
+	return &Redis{client: client}
+}
+
+// Acquire 尝试获取锁
+func (r *Redis) Acquire(ctx context.Context, key, owner string, ttl time.Duration) (Status, error) {
+	ok, err := acquireScript.Run(ctx, r.client, []string{key}, owner, ttl.Milliseconds()).Int()
+	if err != nil {
+		return Held, fmt.Errorf("获取锁 %s 失败: %w", key, err)
+	}
+	if ok == 1 {
+		return Acquired, nil
+	}
+	return Held, nil
+}
+
+// Release 释放自己持有的锁
+func (r *Redis) Release(ctx context.Context, key, owner string) error {
+	if err := releaseScript.Run(ctx, r.client, []string{key}, owner).Err(); err != nil {
+		return fmt.Errorf("释放锁 %s 失败: %w", key, err)
+	}
+	return nil
+}
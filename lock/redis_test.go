@@ -0,0 +1,31 @@
+package lock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// TestRedis 测试Redis分布式锁
+func TestRedis(t *testing.T) {
+	Convey("Redis分布式锁", t, func() {
+		client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", DialTimeout: 100 * time.Millisecond, MaxRetries: -1})
+		defer client.Close()
+
+		locker := NewRedis(client)
+		So(locker, ShouldImplement, (*Locker)(nil))
+
+		Convey("连接失败时返回错误", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+
+			status, err := locker.Acquire(ctx, "sync", "a", time.Minute)
+			So(err, ShouldNotBeNil)
+			So(status, ShouldEqual, Held)
+			So(locker.Release(ctx, "sync", "a"), ShouldNotBeNil)
+		})
+	})
+}
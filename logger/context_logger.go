@@ -0,0 +1,59 @@
+package runehammer
+
+import (
+	"context"
+)
+
+// ============================================================================
+// 请求级日志 - 按上下文选择日志记录器，使规则日志与请求日志携带相同的追踪信息
+// ============================================================================
+
+// ContextLogger 上下文日志记录器 - 每次记录时通过resolve从上下文获取日志记录器，
+// resolve返回nil时使用base
+type ContextLogger struct {
+	base    Logger
+	resolve func(ctx context.Context) Logger
+}
+
+// NewContextLogger 创建上下文日志记录器
+//
+// 参数:
+//
+//	base    - 上下文中没有请求级日志记录器时使用的日志记录器
+//	resolve - 从上下文获取请求级日志记录器，如读取链路追踪ID并返回带该ID的日志记录器
+func NewContextLogger(base Logger, resolve func(ctx context.Context) Logger) Logger {
+	if base == nil {
+		base = NewNoopLogger()
+	}
+	return &ContextLogger{base: base, resolve: resolve}
+}
+
+// loggerFor 获取上下文对应的日志记录器
+func (l *ContextLogger) loggerFor(ctx context.Context) Logger {
+	if ctx != nil && l.resolve != nil {
+		if logger := l.resolve(ctx); logger != nil {
+			return logger
+		}
+	}
+	return l.base
+}
+
+// Debugf 调试日志
+func (l *ContextLogger) Debugf(ctx context.Context, msg string, keyvals ...any) {
+	l.loggerFor(ctx).Debugf(ctx, msg, keyvals...)
+}
+
+// Infof 信息日志
+func (l *ContextLogger) Infof(ctx context.Context, msg string, keyvals ...any) {
+	l.loggerFor(ctx).Infof(ctx, msg, keyvals...)
+}
+
+// Warnf 警告日志
+func (l *ContextLogger) Warnf(ctx context.Context, msg string, keyvals ...any) {
+	l.loggerFor(ctx).Warnf(ctx, msg, keyvals...)
+}
+
+// Errorf 错误日志
+func (l *ContextLogger) Errorf(ctx context.Context, msg string, keyvals ...any) {
+	l.loggerFor(ctx).Errorf(ctx, msg, keyvals...)
+}
@@ -0,0 +1,108 @@
+package runehammer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ============================================================================
+// 选项冲突检测 - 多个选项设置同一项配置时，报告最终生效的选项
+// ============================================================================
+
+// 冲突检测覆盖的配置项
+const (
+	SettingDatabase = "database" // 数据库来源：WithDSN、WithCustomDB
+	SettingCache    = "cache"    // 缓存来源：WithMemoryCache、WithRedisCache、WithNoCache、WithCustomCache
+)
+
+// 选项的生效优先级 - 优先级高的选项生效，优先级相同时后应用的选项生效
+const (
+	claimIgnored  = iota // 不生效的选项，如 WithCustomCache(nil)
+	claimConfig          // 配置参数
+	claimInstance        // 注入的实例，初始化时优先于配置参数
+)
+
+// OptionConflict 选项冲突
+type OptionConflict struct {
+	Setting string   // 冲突的配置项，如 database、cache
+	Options []string // 设置该配置项的选项，按应用顺序
+	Winner  string   // 最终生效的选项
+}
+
+// String 返回冲突描述
+func (c OptionConflict) String() string {
+	return fmt.Sprintf("%s 由 %s 共同设置，生效的是 %s", c.Setting, strings.Join(c.Options, "、"), c.Winner)
+}
+
+// optionClaim 选项对配置项的设置记录
+type optionClaim struct {
+	option string
+	rank   int
+}
+
+// claim 记录选项设置了某项配置
+func (ctx *RuntimeContext) claim(setting, option string, rank int) {
+	if ctx.claims == nil {
+		ctx.claims = make(map[string][]optionClaim)
+	}
+	ctx.claims[setting] = append(ctx.claims[setting], optionClaim{option: option, rank: rank})
+}
+
+// optionConflicts 检测冲突的选项 - 同一配置项被不同选项设置时报告生效的选项
+func (ctx *RuntimeContext) optionConflicts() []OptionConflict {
+	var conflicts []OptionConflict
+	for _, setting := range []string{SettingDatabase, SettingCache} {
+		claims := ctx.claims[setting]
+		var options []string
+		seen := make(map[string]bool)
+		winner := optionClaim{rank: -1}
+		for _, c := range claims {
+			if !seen[c.option] {
+				seen[c.option] = true
+				options = append(options, c.option)
+			}
+			if c.rank >= winner.rank {
+				winner = c
+			}
+		}
+		if len(options) > 1 {
+			conflicts = append(conflicts, OptionConflict{Setting: setting, Options: options, Winner: winner.option})
+		}
+	}
+	return conflicts
+}
+
+// checkOptionConflicts 严格模式下存在冲突时返回错误
+func (ctx *RuntimeContext) checkOptionConflicts() error {
+	if !ctx.strictOptions {
+		return nil
+	}
+	conflicts := ctx.optionConflicts()
+	if len(conflicts) == 0 {
+		return nil
+	}
+	descriptions := make([]string, len(conflicts))
+	for i, c := range conflicts {
+		descriptions[i] = c.String()
+	}
+	return fmt.Errorf("%w: %s", ErrOptionConflict, strings.Join(descriptions, "; "))
+}
+
+// warnOptionConflicts 记录冲突选项的警告日志
+func (ctx *RuntimeContext) warnOptionConflicts() {
+	if ctx.Logger == nil {
+		return
+	}
+	for _, c := range ctx.optionConflicts() {
+		ctx.Logger.Warnf(context.Background(), "配置选项冲突", "setting", c.Setting, "options", c.Options, "winner", c.Winner)
+	}
+}
+
+// WithStrictOptions 启用严格选项模式 - 存在冲突的选项时 New 返回 ErrOptionConflict，默认只记录警告日志
+func WithStrictOptions() Option {
+	return func(ctx *RuntimeContext) error {
+		ctx.strictOptions = true
+		return nil
+	}
+}
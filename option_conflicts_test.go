@@ -0,0 +1,83 @@
+package runehammer
+
+import (
+	"errors"
+	"testing"
+
+	"gitee.com/damengde/runehammer/cache"
+	"gitee.com/damengde/runehammer/config"
+	logger "gitee.com/damengde/runehammer/logger"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.uber.org/mock/gomock"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// TestOptionConflicts 测试选项冲突检测
+func TestOptionConflicts(t *testing.T) {
+	Convey("选项冲突检测", t, func() {
+		apply := func(opts ...Option) *RuntimeContext {
+			ctx := newRuntimeContext(config.DefaultConfig())
+			for _, opt := range opts {
+				So(opt(ctx), ShouldBeNil)
+			}
+			return ctx
+		}
+
+		db, err := gorm.Open(sqlite.Open("file:option_conflicts.db?mode=memory&cache=shared"), &gorm.Config{})
+		So(err, ShouldBeNil)
+
+		Convey("注入的实例优先于配置参数", func() {
+			ctx := apply(WithCustomDB(db), WithDSN("sqlite:file::memory:"), WithCustomCache(cache.NewMemoryCache(10)), WithRedisCache("localhost:6379", "", 0))
+			So(ctx.optionConflicts(), ShouldResemble, []OptionConflict{
+				{Setting: SettingDatabase, Options: []string{"WithCustomDB", "WithDSN"}, Winner: "WithCustomDB"},
+				{Setting: SettingCache, Options: []string{"WithCustomCache", "WithRedisCache"}, Winner: "WithCustomCache"},
+			})
+		})
+
+		Convey("配置参数之间后应用的生效，nil实例不生效", func() {
+			ctx := apply(WithDSN("sqlite:file::memory:"), WithRedisCache("localhost:6379", "secret", 1), WithCustomCache(nil), WithMemoryCache(100))
+			conflicts := ctx.optionConflicts()
+			So(conflicts, ShouldHaveLength, 1)
+			So(conflicts[0].Options, ShouldResemble, []string{"WithRedisCache", "WithCustomCache(nil)", "WithMemoryCache"})
+			So(conflicts[0].Winner, ShouldEqual, "WithMemoryCache")
+
+			// 切换缓存来源后不再保留失效的Redis参数
+			So(ctx.config.RedisAddr, ShouldBeEmpty)
+			So(ctx.config.Validate(), ShouldBeNil)
+
+			ctx = apply(WithMemoryCache(100), WithCustomCache(nil))
+			So(ctx.optionConflicts()[0].Winner, ShouldEqual, "WithMemoryCache")
+		})
+
+		Convey("重复应用同一选项不视为冲突", func() {
+			ctx := apply(WithDSN("a"), WithDSN("b"), WithMemoryCache(10))
+			So(ctx.optionConflicts(), ShouldBeEmpty)
+			So(ctx.checkOptionConflicts(), ShouldBeNil)
+		})
+
+		Convey("默认记录警告日志", func() {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			log := logger.NewMockLogger(ctrl)
+			log.EXPECT().Warnf(gomock.Any(), "配置选项冲突",
+				"setting", SettingCache, "options", []string{"WithNoCache", "WithMemoryCache"}, "winner", "WithMemoryCache")
+
+			ctx := apply(WithNoCache(), WithMemoryCache(10), WithCustomLogger(log))
+			So(ctx.checkOptionConflicts(), ShouldBeNil)
+			ctx.warnOptionConflicts()
+		})
+
+		Convey("严格模式下New返回错误", func() {
+			eng, err := New[map[string]any](
+				WithStrictOptions(),
+				WithDSN("sqlite:file:option_conflicts_strict.db?mode=memory&cache=shared"),
+				WithCustomDB(db),
+			)
+			So(eng, ShouldBeNil)
+			So(errors.Is(err, ErrOptionConflict), ShouldBeTrue)
+			So(err.Error(), ShouldContainSubstring, "生效的是 WithCustomDB")
+		})
+	})
+}
@@ -0,0 +1,78 @@
+package pubsub
+
+import (
+	"context"
+	"sync"
+)
+
+// ============================================================================
+// 实例间消息通道 - 多实例部署时广播运行时覆盖等只保存在内存中的状态变更
+// ============================================================================
+//
+// 消息只投递给发布时已订阅的实例，不持久化、不重放：新启动的实例不会收到之前的消息。
+// 订阅处理函数应尽快返回，耗时操作放到独立协程中执行。
+
+// Handler 消息处理函数
+type Handler func(payload []byte)
+
+// PubSub 消息通道
+type PubSub interface {
+	// Publish 向频道发布消息
+	Publish(ctx context.Context, channel string, payload []byte) error
+
+	// Subscribe 订阅频道
+	//
+	// 返回值:
+	//   func()  - 取消订阅
+	//   error   - 订阅失败
+	Subscribe(ctx context.Context, channel string, handler Handler) (func(), error)
+}
+
+// Memory 进程内消息通道 - 同一进程中的多个引擎实例共享，用于测试和单机多实例
+type Memory struct {
+	mu       sync.RWMutex
+	handlers map[string]map[int]Handler
+	nextID   int
+}
+
+// NewMemory 创建进程内消息通道
+func NewMemory() *Memory {
+	return &Memory{handlers: make(map[string]map[int]Handler)}
+}
+
+// Publish 同步调用频道的全部订阅处理函数
+func (m *Memory) Publish(_ context.Context, channel string, payload []byte) error {
+	m.mu.RLock()
+	handlers := make([]Handler, 0, len(m.handlers[channel]))
+	for _, handler := range m.handlers[channel] {
+		handlers = append(handlers, handler)
+	}
+	m.mu.RUnlock()
+
+	for _, handler := range handlers {
+		handler(payload)
+	}
+	return nil
+}
+
+// Subscribe 订阅频道
+func (m *Memory) Subscribe(_ context.Context, channel string, handler Handler) (func(), error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nextID++
+	id := m.nextID
+	if m.handlers[channel] == nil {
+		m.handlers[channel] = make(map[int]Handler)
+	}
+	m.handlers[channel][id] = handler
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			delete(m.handlers[channel], id)
+		})
+	}, nil
+}
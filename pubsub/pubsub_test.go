@@ -0,0 +1,38 @@
+package pubsub
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// TestMemory 测试进程内消息通道
+func TestMemory(t *testing.T) {
+	Convey("进程内消息通道", t, func() {
+		ctx := context.Background()
+		bus := NewMemory()
+		So(bus, ShouldImplement, (*PubSub)(nil))
+
+		var first, second []string
+		cancel, err := bus.Subscribe(ctx, "overrides", func(payload []byte) { first = append(first, string(payload)) })
+		So(err, ShouldBeNil)
+		_, err = bus.Subscribe(ctx, "overrides", func(payload []byte) { second = append(second, string(payload)) })
+		So(err, ShouldBeNil)
+
+		Convey("消息投递给频道的全部订阅者", func() {
+			So(bus.Publish(ctx, "overrides", []byte("a")), ShouldBeNil)
+			So(bus.Publish(ctx, "other", []byte("b")), ShouldBeNil)
+			So(first, ShouldResemble, []string{"a"})
+			So(second, ShouldResemble, []string{"a"})
+		})
+
+		Convey("取消订阅后不再收到消息", func() {
+			cancel()
+			cancel()
+			So(bus.Publish(ctx, "overrides", []byte("a")), ShouldBeNil)
+			So(first, ShouldBeEmpty)
+			So(second, ShouldResemble, []string{"a"})
+		})
+	})
+}
@@ -0,0 +1,52 @@
+package pubsub
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis 基于Redis发布订阅的消息通道 - 多进程部署时使用
+type Redis struct {
+	client *redis.Client
+}
+
+// NewRedis 创建Redis消息通道，可与Redis缓存共用客户端
+func NewRedis(client *redis.Client) *Redis {
+	return &Redis{client: client}
+}
+
+// Publish 向频道发布消息
+func (r *Redis) Publish(ctx context.Context, channel string, payload []byte) error {
+	if err := r.client.Publish(ctx, channel, payload).Err(); err != nil {
+		return fmt.Errorf("发布消息到频道 %s 失败: %w", channel, err)
+	}
+	return nil
+}
+
+// Subscribe 订阅频道，确认订阅成功后返回，消息在独立协程中按顺序处理
+func (r *Redis) Subscribe(ctx context.Context, channel string, handler Handler) (func(), error) {
+	sub := r.client.Subscribe(ctx, channel)
+	if _, err := sub.Receive(ctx); err != nil {
+		sub.Close()
+		return nil, fmt.Errorf("订阅频道 %s 失败: %w", channel, err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for msg := range sub.Channel() {
+			handler([]byte(msg.Payload))
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			sub.Close()
+			<-done
+		})
+	}, nil
+}
@@ -0,0 +1,30 @@
+package pubsub
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// TestRedis 测试Redis消息通道
+func TestRedis(t *testing.T) {
+	Convey("Redis消息通道", t, func() {
+		client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", DialTimeout: 100 * time.Millisecond, MaxRetries: -1})
+		defer client.Close()
+
+		bus := NewRedis(client)
+		So(bus, ShouldImplement, (*PubSub)(nil))
+
+		Convey("连接失败时返回错误", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+
+			So(bus.Publish(ctx, "overrides", []byte("a")), ShouldNotBeNil)
+			_, err := bus.Subscribe(ctx, "overrides", func([]byte) {})
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
@@ -0,0 +1,200 @@
+package runehammer
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ============================================================================
+// 结果比对 - 字段级差异，用于回归比对和影子执行
+// ============================================================================
+
+// DiffKind 差异类型
+type DiffKind string
+
+const (
+	DiffKindChanged DiffKind = "changed" // 值变化
+	DiffKindAdded   DiffKind = "added"   // 仅存在于右侧
+	DiffKindRemoved DiffKind = "removed" // 仅存在于左侧
+)
+
+// FieldDiff 字段差异
+type FieldDiff struct {
+	Path  string   `json:"path"`  // 字段路径，如 order.items[0].amount
+	Kind  DiffKind `json:"kind"`  // 差异类型
+	Left  any      `json:"left"`  // 左侧值
+	Right any      `json:"right"` // 右侧值
+}
+
+// CompareOptions 结果比对选项
+type CompareOptions struct {
+	NumericTolerance float64            // 默认数值容差（绝对值）
+	FieldTolerances  map[string]float64 // 按字段路径指定的数值容差，优先于默认容差
+	IgnoreFields     []string           // 忽略的字段路径，同时忽略其所有子字段
+}
+
+// CompareResults 比对两个结果的字段级差异
+//
+// 结果先通过JSON归一化，因此结构体按json标签命名字段，数值统一按float64比较。
+//
+// 参数:
+//
+//	a, b - 待比对的结果
+//	opts - 比对选项
+//
+// 返回值:
+//
+//	[]FieldDiff - 按路径排序的差异列表，无差异时为空
+//	error       - 结果无法归一化时返回错误
+//
+// 使用示例:
+//
+//	diffs, err := CompareResults(oldResult, newResult, CompareOptions{
+//	    NumericTolerance: 1e-6,
+//	    IgnoreFields:     []string{"requestId"},
+//	})
+func CompareResults[T any](a, b T, opts CompareOptions) ([]FieldDiff, error) {
+	left, err := normalizeForCompare(a)
+	if err != nil {
+		return nil, fmt.Errorf("归一化左侧结果失败: %w", err)
+	}
+	right, err := normalizeForCompare(b)
+	if err != nil {
+		return nil, fmt.Errorf("归一化右侧结果失败: %w", err)
+	}
+
+	c := &resultComparator{opts: opts}
+	c.compare("", left, right)
+
+	sort.Slice(c.diffs, func(i, j int) bool {
+		return c.diffs[i].Path < c.diffs[j].Path
+	})
+	return c.diffs, nil
+}
+
+// normalizeForCompare 通过JSON将结果转换为通用结构
+func normalizeForCompare(value any) (any, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var normalized any
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
+
+// resultComparator 递归比对器
+type resultComparator struct {
+	opts  CompareOptions
+	diffs []FieldDiff
+}
+
+// compare 递归比对两个归一化后的值
+func (c *resultComparator) compare(path string, left, right any) {
+	if c.ignored(path) {
+		return
+	}
+
+	switch l := left.(type) {
+	case map[string]any:
+		if r, ok := right.(map[string]any); ok {
+			c.compareMaps(path, l, r)
+			return
+		}
+	case []any:
+		if r, ok := right.([]any); ok {
+			c.compareSlices(path, l, r)
+			return
+		}
+	case float64:
+		if r, ok := right.(float64); ok {
+			if math.Abs(l-r) > c.tolerance(path) {
+				c.add(path, DiffKindChanged, left, right)
+			}
+			return
+		}
+	}
+
+	if !reflect.DeepEqual(left, right) {
+		c.add(path, DiffKindChanged, left, right)
+	}
+}
+
+// compareMaps 比对map
+func (c *resultComparator) compareMaps(path string, left, right map[string]any) {
+	for key, lv := range left {
+		childPath := joinDiffPath(path, key)
+		rv, ok := right[key]
+		if !ok {
+			if !c.ignored(childPath) {
+				c.add(childPath, DiffKindRemoved, lv, nil)
+			}
+			continue
+		}
+		c.compare(childPath, lv, rv)
+	}
+	for key, rv := range right {
+		if _, ok := left[key]; ok {
+			continue
+		}
+		childPath := joinDiffPath(path, key)
+		if !c.ignored(childPath) {
+			c.add(childPath, DiffKindAdded, nil, rv)
+		}
+	}
+}
+
+// compareSlices 比对切片，按下标逐项比对
+func (c *resultComparator) compareSlices(path string, left, right []any) {
+	for i := 0; i < len(left) || i < len(right); i++ {
+		childPath := fmt.Sprintf("%s[%d]", path, i)
+		switch {
+		case i >= len(right):
+			c.add(childPath, DiffKindRemoved, left[i], nil)
+		case i >= len(left):
+			c.add(childPath, DiffKindAdded, nil, right[i])
+		default:
+			c.compare(childPath, left[i], right[i])
+		}
+	}
+}
+
+// tolerance 获取字段的数值容差
+func (c *resultComparator) tolerance(path string) float64 {
+	if tol, ok := c.opts.FieldTolerances[path]; ok {
+		return tol
+	}
+	return c.opts.NumericTolerance
+}
+
+// ignored 判断字段是否被忽略
+func (c *resultComparator) ignored(path string) bool {
+	if path == "" {
+		return false
+	}
+	for _, field := range c.opts.IgnoreFields {
+		if path == field || strings.HasPrefix(path, field+".") || strings.HasPrefix(path, field+"[") {
+			return true
+		}
+	}
+	return false
+}
+
+// add 记录差异
+func (c *resultComparator) add(path string, kind DiffKind, left, right any) {
+	c.diffs = append(c.diffs, FieldDiff{Path: path, Kind: kind, Left: left, Right: right})
+}
+
+// joinDiffPath 拼接字段路径
+func joinDiffPath(parent, key string) string {
+	if parent == "" {
+		return key
+	}
+	return parent + "." + key
+}
@@ -0,0 +1,73 @@
+package runehammer
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCompareResults(t *testing.T) {
+	Convey("结果比对", t, func() {
+		Convey("相同结果无差异", func() {
+			a := map[string]any{"score": 10, "tags": []any{"a", "b"}}
+			diffs, err := CompareResults(a, a, CompareOptions{})
+			So(err, ShouldBeNil)
+			So(diffs, ShouldBeEmpty)
+		})
+
+		Convey("字段级差异按路径排序", func() {
+			a := map[string]any{"score": 10.0, "level": "A", "items": []any{1, 2}, "gone": true}
+			b := map[string]any{"score": 12.5, "level": "A", "items": []any{1, 3, 4}, "new": "x"}
+
+			diffs, err := CompareResults(a, b, CompareOptions{})
+			So(err, ShouldBeNil)
+			So(diffs, ShouldResemble, []FieldDiff{
+				{Path: "gone", Kind: DiffKindRemoved, Left: true},
+				{Path: "items[1]", Kind: DiffKindChanged, Left: 2.0, Right: 3.0},
+				{Path: "items[2]", Kind: DiffKindAdded, Right: 4.0},
+				{Path: "new", Kind: DiffKindAdded, Right: "x"},
+				{Path: "score", Kind: DiffKindChanged, Left: 10.0, Right: 12.5},
+			})
+		})
+
+		Convey("数值容差与字段容差", func() {
+			a := map[string]any{"rate": 0.1000001, "amount": 100.0}
+			b := map[string]any{"rate": 0.1000002, "amount": 100.4}
+
+			diffs, err := CompareResults(a, b, CompareOptions{
+				NumericTolerance: 1e-6,
+				FieldTolerances:  map[string]float64{"amount": 0.5},
+			})
+			So(err, ShouldBeNil)
+			So(diffs, ShouldBeEmpty)
+		})
+
+		Convey("忽略字段及其子字段", func() {
+			type nested struct {
+				ID    string         `json:"id"`
+				Trace map[string]any `json:"trace"`
+				Score int            `json:"score"`
+			}
+			a := nested{ID: "1", Trace: map[string]any{"at": 1}, Score: 1}
+			b := nested{ID: "2", Trace: map[string]any{"at": 2}, Score: 1}
+
+			diffs, err := CompareResults(a, b, CompareOptions{IgnoreFields: []string{"id", "trace"}})
+			So(err, ShouldBeNil)
+			So(diffs, ShouldBeEmpty)
+		})
+
+		Convey("类型不同视为变化", func() {
+			diffs, err := CompareResults[any](map[string]any{"v": "1"}, map[string]any{"v": 1}, CompareOptions{})
+			So(err, ShouldBeNil)
+			So(diffs, ShouldHaveLength, 1)
+			So(diffs[0].Kind, ShouldEqual, DiffKindChanged)
+		})
+
+		Convey("无法序列化的结果返回错误", func() {
+			_, err := CompareResults[any](make(chan int), 1, CompareOptions{})
+			So(err, ShouldNotBeNil)
+			_, err = CompareResults[any](1, make(chan int), CompareOptions{})
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
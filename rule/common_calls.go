@@ -0,0 +1,211 @@
+package rule
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ============================================================================
+// 公共子表达式提取 - 业务码内多条规则条件中相同的函数调用改写为共享事实，每次执行只求值一次
+// ============================================================================
+//
+// Grule不缓存函数调用的结果，多条规则条件中相同的 Params.email.MatchString("...")、Levenshtein(Params.name, "...")
+// 在每次执行的每个周期都会重复求值。HoistCommonCalls 找出在至少两条规则的when条件中出现的调用，
+// 改写为共享事实对象的调用：
+//
+//	Levenshtein(Params.name, "abc")          => Shared.Eval("Levenshtein", Params.name, "abc")
+//	Params.email.MatchString("^[a-z]+@")     => Shared.Method(Params.email, "MatchString", "^[a-z]+@")
+//
+// 共享事实对象按调用名和参数值缓存结果，参数值不变时同一次执行只调用一次；方法调用只在接收者为字符串时缓存。
+// 只提取参数中不含函数调用的调用；无参数的调用和日志、撤回、随机数等有副作用的内置函数不提取。
+// 无法按Go表达式解析的条件不参与提取。
+
+// SharedFactsObject 共享事实对象在规则中的名称
+const SharedFactsObject = "Shared"
+
+// CommonCall 被提取的公共调用
+type CommonCall struct {
+	Expression string   `json:"expression"` // 调用表达式（规范化后）
+	Rules      []string `json:"rules"`      // 使用该调用的规则名称
+}
+
+// sideEffectFunctions 有副作用或依赖执行状态、不能缓存结果的内置函数
+var sideEffectFunctions = map[string]bool{
+	"Retract": true, "Complete": true, "Changed": true, "Forget": true,
+	"Log": true, "LogDebug": true, "LogWarn": true, "LogError": true, "LogFormat": true,
+	"Alert": true, "AddReason": true, "Emit": true, "Now": true, "NowMillis": true, "Today": true, "UUIDv4": true,
+}
+
+// grlWhenSpanRegex 匹配when条件的位置（在已屏蔽注释和字符串的源码上使用）
+var grlWhenSpanRegex = regexp.MustCompile(`(?s)\bwhen\b(.*?)\bthen\b`)
+
+// callSite when条件中的一次调用
+type callSite struct {
+	start, end int    // 在GRL中的位置
+	key        string // 规范化的调用表达式
+	rewritten  string // 改写后的表达式
+}
+
+// HoistCommonCalls 提取多条规则共用的调用
+//
+// 参数:
+//
+//	rules - 规则集，禁用的规则不参与
+//
+// 返回值:
+//
+//	[]*Rule      - 改写后的规则集，未改写的规则为原对象，改写的规则为副本
+//	[]CommonCall - 被提取的调用，按表达式排序
+func HoistCommonCalls(rules []*Rule) ([]*Rule, []CommonCall) {
+	sites := make([][]callSite, len(rules))
+	users := make(map[string][]string)
+	for i, r := range rules {
+		if r == nil || !r.Enabled {
+			continue
+		}
+		sites[i] = findCallSites(r.GRL)
+		seen := make(map[string]bool)
+		for _, site := range sites[i] {
+			if !seen[site.key] {
+				seen[site.key] = true
+				users[site.key] = append(users[site.key], r.Name)
+			}
+		}
+	}
+
+	var common []CommonCall
+	for key, names := range users {
+		if len(names) >= 2 {
+			common = append(common, CommonCall{Expression: key, Rules: names})
+		}
+	}
+	if len(common) == 0 {
+		return rules, nil
+	}
+	sort.Slice(common, func(i, j int) bool { return common[i].Expression < common[j].Expression })
+
+	result := make([]*Rule, len(rules))
+	for i, r := range rules {
+		result[i] = r
+		var sb strings.Builder
+		pos := 0
+		for _, site := range sites[i] {
+			if len(users[site.key]) < 2 {
+				continue
+			}
+			sb.WriteString(r.GRL[pos:site.start])
+			sb.WriteString(site.rewritten)
+			pos = site.end
+		}
+		if pos > 0 {
+			sb.WriteString(r.GRL[pos:])
+			hoisted := *r
+			hoisted.GRL = sb.String()
+			result[i] = &hoisted
+		}
+	}
+	return result, common
+}
+
+// findCallSites 找出GRL中when条件里可提取的调用，按位置排序且互不重叠
+func findCallSites(grl string) []callSite {
+	var sites []callSite
+	masked := maskGRL(grl)
+	for _, span := range grlWhenSpanRegex.FindAllStringSubmatchIndex(masked, -1) {
+		start, end := span[2], span[3]
+		fset := token.NewFileSet()
+		expr, err := parser.ParseExprFrom(fset, "", grl[start:end], 0)
+		if err != nil {
+			continue
+		}
+		offset := func(pos token.Pos) int { return start + fset.Position(pos).Offset }
+
+		ast.Inspect(expr, func(node ast.Node) bool {
+			call, ok := node.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			if !hoistableCall(call) {
+				// 不可提取的调用继续查找参数中的调用
+				return true
+			}
+			source := func(node ast.Node) string { return grl[offset(node.Pos()):offset(node.End())] }
+			var args []string
+			method := "Eval"
+			switch fun := call.Fun.(type) {
+			case *ast.Ident:
+				args = append(args, strconv.Quote(fun.Name))
+			case *ast.SelectorExpr:
+				method = "Method"
+				args = append(args, source(fun.X), strconv.Quote(fun.Sel.Name))
+			}
+			for _, arg := range call.Args {
+				args = append(args, source(arg))
+			}
+			sites = append(sites, callSite{
+				start:     offset(call.Pos()),
+				end:       offset(call.End()),
+				key:       types.ExprString(call),
+				rewritten: SharedFactsObject + "." + method + "(" + strings.Join(args, ", ") + ")",
+			})
+			return false
+		})
+	}
+	return sites
+}
+
+// hoistableCall 调用是否可提取 - 内置函数调用，或接收者为事实字段访问的方法调用
+func hoistableCall(call *ast.CallExpr) bool {
+	if len(call.Args) == 0 || call.Ellipsis.IsValid() {
+		return false
+	}
+	for _, arg := range call.Args {
+		if hasCall(arg) {
+			return false
+		}
+	}
+
+	switch fun := call.Fun.(type) {
+	case *ast.Ident:
+		return !sideEffectFunctions[fun.Name]
+	case *ast.SelectorExpr:
+		// 接收者为对象本身（如 DEFUNC、Shared）时不是字段访问
+		if _, ok := fun.X.(*ast.Ident); ok {
+			return false
+		}
+		return !hasCall(fun.X)
+	}
+	return false
+}
+
+// hasCall 表达式中是否含有函数调用
+func hasCall(expr ast.Expr) bool {
+	found := false
+	ast.Inspect(expr, func(node ast.Node) bool {
+		switch node.(type) {
+		case *ast.CallExpr, *ast.FuncLit:
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// maskGRL 以空格屏蔽注释和字符串内容，保持位置不变
+func maskGRL(grl string) string {
+	masked := []byte(grl)
+	for _, pattern := range []*regexp.Regexp{grlStringLiteralRegex, grlLineCommentRegex} {
+		for _, loc := range pattern.FindAllIndex(masked, -1) {
+			for i := loc[0]; i < loc[1]; i++ {
+				masked[i] = ' '
+			}
+		}
+	}
+	return string(masked)
+}
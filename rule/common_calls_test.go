@@ -0,0 +1,78 @@
+package rule
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// TestHoistCommonCalls 测试公共调用提取
+func TestHoistCommonCalls(t *testing.T) {
+	Convey("公共调用提取", t, func() {
+		newRule := func(name, when string) *Rule {
+			return &Rule{Name: name, Enabled: true, GRL: `rule ` + name + ` "说明" { when ` + when + ` then Result["` + name + `"] = true; Retract("` + name + `"); }`}
+		}
+
+		Convey("至少两条规则共用的调用改写为共享事实调用", func() {
+			rules := []*Rule{
+				newRule("A", `Levenshtein(Params.name, "alice") <= 1 && Params.email.MatchString("^[a-z]+@")`),
+				newRule("B", `Levenshtein(Params.name,"alice") > 3 || Params.age > 18`),
+				newRule("C", `Params.email.MatchString("^[a-z]+@") && Sha256Hex(Params.id) == "x"`),
+			}
+			hoisted, common := HoistCommonCalls(rules)
+			So(common, ShouldHaveLength, 2)
+			So(common[0].Expression, ShouldEqual, `Levenshtein(Params.name, "alice")`)
+			So(common[0].Rules, ShouldResemble, []string{"A", "B"})
+			So(common[1].Expression, ShouldEqual, `Params.email.MatchString("^[a-z]+@")`)
+			So(common[1].Rules, ShouldResemble, []string{"A", "C"})
+
+			So(hoisted[0].GRL, ShouldContainSubstring, `when Shared.Eval("Levenshtein", Params.name, "alice") <= 1 && Shared.Method(Params.email, "MatchString", "^[a-z]+@") then`)
+			So(hoisted[1].GRL, ShouldContainSubstring, `when Shared.Eval("Levenshtein", Params.name, "alice") > 3`)
+			So(hoisted[2].GRL, ShouldContainSubstring, `Sha256Hex(Params.id) == "x"`)
+			So(rules[0].GRL, ShouldContainSubstring, `when Levenshtein(`)
+		})
+
+		Convey("单条规则使用、禁用规则和then中的调用不提取", func() {
+			disabled := newRule("B", `Levenshtein(Params.name, "alice") > 3`)
+			disabled.Enabled = false
+			rules := []*Rule{newRule("A", `Levenshtein(Params.name, "alice") <= 1`), disabled}
+			hoisted, common := HoistCommonCalls(rules)
+			So(common, ShouldBeEmpty)
+			So(hoisted[0], ShouldEqual, rules[0])
+
+			rules = []*Rule{
+				{Name: "A", Enabled: true, GRL: `rule A { when Params.ok then Result["h"] = Sha256Hex(Params.id); }`},
+				{Name: "B", Enabled: true, GRL: `rule B { when Params.ok then Result["h"] = Sha256Hex(Params.id); }`},
+			}
+			_, common = HoistCommonCalls(rules)
+			So(common, ShouldBeEmpty)
+		})
+
+		Convey("有副作用、无参数、链式调用和对象方法不提取，嵌套调用只提取内层", func() {
+			for _, when := range []string{
+				`Now() > 0`,
+				`DEFUNC.Levenshtein(Params.a, "b") > 0`,
+				`Params.name.ToUpper() == "A"`,
+				`Params.name.Trim().HasPrefix("a")`,
+			} {
+				_, common := HoistCommonCalls([]*Rule{newRule("A", when), newRule("B", when)})
+				So(common, ShouldBeEmpty)
+			}
+
+			_, common := HoistCommonCalls([]*Rule{newRule("A", `Len(Sha256Hex(Params.id)) > 0`), newRule("B", `Len(Sha256Hex(Params.id)) > 1`)})
+			So(common, ShouldHaveLength, 1)
+			So(common[0].Expression, ShouldEqual, `Sha256Hex(Params.id)`)
+		})
+
+		Convey("字符串和注释中的内容不影响提取", func() {
+			rules := []*Rule{
+				{Name: "A", Enabled: true, GRL: "rule A \"when x then\" {\n // when Levenshtein(a, b) then\n when Levenshtein(Params.a, \"then\") > 1 then Result[\"a\"] = 1; }"},
+				{Name: "B", Enabled: true, GRL: `rule B { when Levenshtein(Params.a, "then") > 2 then Result["b"] = 1; }`},
+			}
+			hoisted, common := HoistCommonCalls(rules)
+			So(common, ShouldHaveLength, 1)
+			So(hoisted[0].GRL, ShouldContainSubstring, `// when Levenshtein(a, b) then`)
+			So(hoisted[0].GRL, ShouldContainSubstring, `when Shared.Eval("Levenshtein", Params.a, "then") > 1 then`)
+		})
+	})
+}
@@ -0,0 +1,430 @@
+package rule
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ============================================================================
+// 常量折叠 - 转换时计算只含常量的子表达式，消除恒真/恒假的子条件并给出警告
+// ============================================================================
+//
+// 启用 ConverterConfig.FoldConstants 后，条件和计算表达式中的常量子表达式在转换时求值：
+//
+//	Params.amount > 1000 * 1.2       => Params.amount > 1200.0
+//	Len("abc") > 2 && Params.vip     => Params.vip（并警告 Len("abc") > 2 恒为 true）
+//
+// 求值语义与Grule执行时一致：整数运算为int64，整数除法结果为float64，整数与浮点混合运算为float64。
+// 只折叠字面量、算术/比较/逻辑运算和 Len(字符串字面量)；无法按Go表达式解析的表达式原样保留。
+// 逻辑运算只在不改变求值的情况下消除子条件：左侧常量按短路语义消除，右侧为恒假（与）/恒真（或）时
+// 仅当左侧不含函数调用才消除左侧。
+
+// ConversionWarning 转换警告 - 规则条件恒为真或恒为假等不影响转换结果的问题
+type ConversionWarning struct {
+	Rule       string // 规则名
+	Expression string // 折叠前的条件表达式
+	Message    string // 警告内容
+}
+
+// String 警告描述
+func (w ConversionWarning) String() string {
+	return fmt.Sprintf("规则 %s: %s (%s)", w.Rule, w.Message, w.Expression)
+}
+
+// foldedExpr 折叠后的子表达式
+type foldedExpr struct {
+	text   string      // 折叠后的文本
+	value  interface{} // 常量值（int64/float64/string/bool），非常量为nil
+	folded bool        // 是否由求值得到（而非原样的字面量）
+	pure   bool        // 是否不含函数调用，消除时不会跳过副作用
+}
+
+// constantFolder 单个表达式的常量折叠
+type constantFolder struct {
+	fset     *token.FileSet
+	src      string
+	warnings []string
+}
+
+// foldConstants 折叠表达式中的常量子表达式
+//
+// 返回值:
+//
+//	string      - 折叠后的表达式，无法解析时为原表达式
+//	interface{} - 整个表达式折叠后的常量值，非常量为nil
+//	[]string    - 被消除的恒真/恒假子条件的警告
+func foldConstants(expr string) (string, interface{}, []string) {
+	f := &constantFolder{fset: token.NewFileSet(), src: expr}
+	node, err := parser.ParseExprFrom(f.fset, "", expr, 0)
+	if err != nil {
+		return expr, nil, nil
+	}
+	result := f.fold(node)
+	// 表达式首尾的空白不属于任何节点
+	text := expr[:f.offset(node.Pos())] + result.text + expr[f.offset(node.End()):]
+	if !result.folded {
+		return text, nil, f.warnings
+	}
+	return text, result.value, f.warnings
+}
+
+// foldCondition 折叠条件表达式，恒真/恒假时通过OnWarning报告
+func (c *GRLConverter) foldCondition(ruleName, expr string) string {
+	if !c.config.FoldConstants {
+		return expr
+	}
+	folded, value, warnings := foldConstants(expr)
+	if b, ok := value.(bool); ok {
+		if b {
+			warnings = append(warnings, "条件恒为 true，规则总是触发")
+		} else {
+			warnings = append(warnings, "条件恒为 false，规则永远不会触发")
+		}
+	}
+	if c.config.OnWarning != nil {
+		for _, message := range warnings {
+			c.config.OnWarning(ConversionWarning{Rule: ruleName, Expression: expr, Message: message})
+		}
+	}
+	return folded
+}
+
+// foldExpression 折叠计算表达式
+func (c *GRLConverter) foldExpression(expr string) string {
+	if !c.config.FoldConstants {
+		return expr
+	}
+	folded, _, _ := foldConstants(expr)
+	return folded
+}
+
+// offset 位置在源文本中的偏移
+func (f *constantFolder) offset(pos token.Pos) int {
+	return f.fset.Position(pos).Offset
+}
+
+// source 节点的原始文本
+func (f *constantFolder) source(node ast.Node) string {
+	return f.src[f.offset(node.Pos()):f.offset(node.End())]
+}
+
+// splice 以折叠后的子节点文本替换原始文本中的对应部分
+func (f *constantFolder) splice(node ast.Node, children ...foldedChild) string {
+	var sb strings.Builder
+	pos := f.offset(node.Pos())
+	for _, child := range children {
+		sb.WriteString(f.src[pos:f.offset(child.node.Pos())])
+		sb.WriteString(child.text)
+		pos = f.offset(child.node.End())
+	}
+	sb.WriteString(f.src[pos:f.offset(node.End())])
+	return sb.String()
+}
+
+// foldedChild 子节点及其折叠后的文本
+type foldedChild struct {
+	node ast.Node
+	text string
+}
+
+// fold 折叠节点
+func (f *constantFolder) fold(node ast.Expr) foldedExpr {
+	switch n := node.(type) {
+	case *ast.BasicLit:
+		return foldedExpr{text: n.Value, value: literalValue(n), pure: true}
+
+	case *ast.Ident:
+		switch n.Name {
+		case "true", "false":
+			return foldedExpr{text: n.Name, value: n.Name == "true", pure: true}
+		}
+		return foldedExpr{text: n.Name, pure: true}
+
+	case *ast.ParenExpr:
+		inner := f.fold(n.X)
+		if inner.folded {
+			return inner
+		}
+		return foldedExpr{text: f.splice(n, foldedChild{n.X, inner.text}), value: inner.value, pure: inner.pure}
+
+	case *ast.UnaryExpr:
+		x := f.fold(n.X)
+		if value, ok := unaryValue(n.Op, x.value); ok {
+			return constant(value)
+		}
+		return foldedExpr{text: f.splice(n, foldedChild{n.X, x.text}), pure: x.pure}
+
+	case *ast.BinaryExpr:
+		return f.foldBinary(n)
+
+	case *ast.CallExpr:
+		children := []foldedChild{{n.Fun, f.fold(n.Fun).text}}
+		args := make([]interface{}, len(n.Args))
+		for i, arg := range n.Args {
+			a := f.fold(arg)
+			children = append(children, foldedChild{arg, a.text})
+			args[i] = a.value
+		}
+		if ident, ok := n.Fun.(*ast.Ident); ok && ident.Name == "Len" && len(args) == 1 {
+			if s, ok := args[0].(string); ok {
+				return constant(int64(len(s)))
+			}
+		}
+		return foldedExpr{text: f.splice(n, children...)}
+
+	case *ast.SelectorExpr:
+		x := f.fold(n.X)
+		return foldedExpr{text: f.splice(n, foldedChild{n.X, x.text}), pure: x.pure}
+
+	case *ast.IndexExpr:
+		x, index := f.fold(n.X), f.fold(n.Index)
+		return foldedExpr{text: f.splice(n, foldedChild{n.X, x.text}, foldedChild{n.Index, index.text}), pure: x.pure && index.pure}
+
+	default:
+		return foldedExpr{text: f.source(n)}
+	}
+}
+
+// foldBinary 折叠二元运算，逻辑运算按短路语义消除常量子条件
+func (f *constantFolder) foldBinary(n *ast.BinaryExpr) foldedExpr {
+	x, y := f.fold(n.X), f.fold(n.Y)
+
+	if n.Op == token.LAND || n.Op == token.LOR {
+		// 与运算中false、或运算中true决定结果
+		decisive := n.Op == token.LOR
+		if b, ok := x.value.(bool); ok {
+			f.trivial(n.X, x, b)
+			if b == decisive {
+				return constant(decisive)
+			}
+			return remaining(y)
+		}
+		if b, ok := y.value.(bool); ok {
+			if b != decisive {
+				f.trivial(n.Y, y, b)
+				return remaining(x)
+			}
+			if x.pure {
+				f.trivial(n.Y, y, b)
+				return constant(decisive)
+			}
+		}
+	} else if value, ok := binaryValue(n.Op, x.value, y.value); ok {
+		return constant(value)
+	}
+
+	return foldedExpr{
+		text: f.splice(n, foldedChild{n.X, x.text}, foldedChild{n.Y, y.text}),
+		pure: x.pure && y.pure,
+	}
+}
+
+// remaining 消除常量子条件后剩余的操作数，其本身为常量时整个运算为常量
+func remaining(operand foldedExpr) foldedExpr {
+	if operand.value != nil {
+		return constant(operand.value)
+	}
+	return operand
+}
+
+// trivial 记录由求值得到的恒真/恒假子条件
+func (f *constantFolder) trivial(node ast.Node, expr foldedExpr, value bool) {
+	if expr.folded {
+		f.warnings = append(f.warnings, fmt.Sprintf("子条件 %s 恒为 %t，已消除", f.source(node), value))
+	}
+}
+
+// constant 求值得到的常量
+func constant(value interface{}) foldedExpr {
+	return foldedExpr{text: renderConstant(value), value: value, folded: true, pure: true}
+}
+
+// literalValue 字面量的值，字符字面量等Grule与Go语义不同的字面量不作为常量
+func literalValue(lit *ast.BasicLit) interface{} {
+	switch lit.Kind {
+	case token.INT:
+		if v, err := strconv.ParseInt(lit.Value, 0, 64); err == nil {
+			return v
+		}
+	case token.FLOAT:
+		if v, err := strconv.ParseFloat(lit.Value, 64); err == nil {
+			return v
+		}
+	case token.STRING:
+		if strings.HasPrefix(lit.Value, `"`) {
+			if v, err := strconv.Unquote(lit.Value); err == nil {
+				return v
+			}
+		}
+	}
+	return nil
+}
+
+// renderConstant 将常量输出为GRL字面量，负数加括号，浮点数保留小数点以免被解析为整数
+func renderConstant(value interface{}) string {
+	switch v := value.(type) {
+	case int64:
+		if v < 0 {
+			return "(" + strconv.FormatInt(v, 10) + ")"
+		}
+		return strconv.FormatInt(v, 10)
+	case float64:
+		text := strconv.FormatFloat(v, 'f', -1, 64)
+		if !strings.Contains(text, ".") {
+			text += ".0"
+		}
+		if v < 0 {
+			return "(" + text + ")"
+		}
+		return text
+	case string:
+		return strconv.Quote(v)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return fmt.Sprint(value)
+}
+
+// unaryValue 一元运算的常量结果
+func unaryValue(op token.Token, x interface{}) (interface{}, bool) {
+	switch v := x.(type) {
+	case bool:
+		if op == token.NOT {
+			return !v, true
+		}
+	case int64:
+		if op == token.SUB {
+			return -v, true
+		}
+	case float64:
+		if op == token.SUB {
+			return -v, true
+		}
+	}
+	return nil, false
+}
+
+// binaryValue 算术和比较运算的常量结果，除数为0、结果非有限值或类型不匹配时不折叠
+func binaryValue(op token.Token, x, y interface{}) (interface{}, bool) {
+	switch l := x.(type) {
+	case int64:
+		switch r := y.(type) {
+		case int64:
+			return intValue(op, l, r)
+		case float64:
+			return floatValue(op, float64(l), r)
+		}
+	case float64:
+		switch r := y.(type) {
+		case int64:
+			return floatValue(op, l, float64(r))
+		case float64:
+			return floatValue(op, l, r)
+		}
+	case string:
+		if r, ok := y.(string); ok {
+			switch op {
+			case token.ADD:
+				return l + r, true
+			case token.EQL:
+				return l == r, true
+			case token.NEQ:
+				return l != r, true
+			case token.LSS:
+				return l < r, true
+			case token.LEQ:
+				return l <= r, true
+			case token.GTR:
+				return l > r, true
+			case token.GEQ:
+				return l >= r, true
+			}
+		}
+	case bool:
+		if r, ok := y.(bool); ok {
+			switch op {
+			case token.EQL:
+				return l == r, true
+			case token.NEQ:
+				return l != r, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// intValue 整数运算，除法与Grule一致按浮点计算
+func intValue(op token.Token, l, r int64) (interface{}, bool) {
+	switch op {
+	case token.ADD:
+		return l + r, true
+	case token.SUB:
+		return l - r, true
+	case token.MUL:
+		return l * r, true
+	case token.QUO:
+		if r == 0 {
+			return nil, false
+		}
+		return float64(l) / float64(r), true
+	case token.REM:
+		if r == 0 {
+			return nil, false
+		}
+		return l % r, true
+	case token.EQL:
+		return l == r, true
+	case token.NEQ:
+		return l != r, true
+	case token.LSS:
+		return l < r, true
+	case token.LEQ:
+		return l <= r, true
+	case token.GTR:
+		return l > r, true
+	case token.GEQ:
+		return l >= r, true
+	}
+	return nil, false
+}
+
+// floatValue 浮点运算
+func floatValue(op token.Token, l, r float64) (interface{}, bool) {
+	var result float64
+	switch op {
+	case token.ADD:
+		result = l + r
+	case token.SUB:
+		result = l - r
+	case token.MUL:
+		result = l * r
+	case token.QUO:
+		if r == 0 {
+			return nil, false
+		}
+		result = l / r
+	case token.EQL:
+		return l == r, true
+	case token.NEQ:
+		return l != r, true
+	case token.LSS:
+		return l < r, true
+	case token.LEQ:
+		return l <= r, true
+	case token.GTR:
+		return l > r, true
+	case token.GEQ:
+		return l >= r, true
+	default:
+		return nil, false
+	}
+	if math.IsInf(result, 0) || math.IsNaN(result) {
+		return nil, false
+	}
+	return result, true
+}
@@ -0,0 +1,96 @@
+package rule
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// TestConstantFolding 测试常量折叠
+func TestConstantFolding(t *testing.T) {
+	Convey("常量折叠", t, func() {
+		fold := func(expr string) (string, interface{}, []string) {
+			return foldConstants(expr)
+		}
+
+		Convey("按Grule语义计算常量子表达式", func() {
+			folded, value, _ := fold(`Params.amount > 1000 * 1.2`)
+			So(folded, ShouldEqual, `Params.amount > 1200.0`)
+			So(value, ShouldBeNil)
+
+			folded, _, _ = fold(`Params.ratio == 7 / 2 && Params.count > 2 * 3 - 10`)
+			So(folded, ShouldEqual, `Params.ratio == 3.5 && Params.count > (-4)`)
+
+			folded, _, _ = fold(`Params.total * (2.0 * 3)`)
+			So(folded, ShouldEqual, `Params.total * 6.0`)
+
+			folded, _, _ = fold(`Params.name == "a" + "b" && Params.code == Len("中文")`)
+			So(folded, ShouldEqual, `Params.name == "ab" && Params.code == 6`)
+		})
+
+		Convey("消除恒真/恒假的子条件并给出警告", func() {
+			folded, value, warnings := fold(`Len("abc") > 2 && Params.vip`)
+			So(folded, ShouldEqual, `Params.vip`)
+			So(value, ShouldBeNil)
+			So(warnings, ShouldHaveLength, 1)
+			So(warnings[0], ShouldContainSubstring, `Len("abc") > 2 恒为 true`)
+
+			folded, value, _ = fold(`(1 > 2) && Params.vip`)
+			So(folded, ShouldEqual, `false`)
+			So(value, ShouldEqual, false)
+
+			folded, value, _ = fold(`Params.vip || 3 >= 3`)
+			So(folded, ShouldEqual, `true`)
+			So(value, ShouldEqual, true)
+		})
+
+		Convey("不跳过含函数调用的子条件", func() {
+			folded, value, _ := fold(`Func.Call("Audit", Params.id) && 1 > 2`)
+			So(folded, ShouldEqual, `Func.Call("Audit", Params.id) && false`)
+			So(value, ShouldBeNil)
+		})
+
+		Convey("无法折叠或解析的表达式原样保留", func() {
+			for _, expr := range []string{`Params.a > 1 / 0`, `Params.type == 'x'`, `true`, `Now() > 0`, ` Params.a > 1 `} {
+				folded, value, warnings := fold(expr)
+				So(folded, ShouldEqual, expr)
+				So(value, ShouldBeNil)
+				So(warnings, ShouldBeEmpty)
+			}
+		})
+
+		Convey("转换器启用后折叠条件和计算表达式并报告警告", func() {
+			var warnings []ConversionWarning
+			converter := NewGRLConverter(ConverterConfig{FoldConstants: true, OnWarning: func(w ConversionWarning) {
+				warnings = append(warnings, w)
+			}})
+
+			grl, err := converter.ConvertToGRL(SimpleRule{
+				When: `Params.amount > 1000 * 1.5 && 2 > 1`,
+				Then: map[string]string{"Result.fee": "Params.amount * (5 / 1000)"},
+			})
+			So(err, ShouldBeNil)
+			So(grl, ShouldContainSubstring, `Params.amount > 1500.0`)
+			So(grl, ShouldContainSubstring, `Params.amount * 0.005`)
+			So(warnings, ShouldHaveLength, 1)
+
+			warnings = nil
+			standard := StandardRule{
+				ID: "NEVER", Name: "永不触发", Enabled: true,
+				Conditions: Condition{Type: ConditionTypeExpression, Expression: `Len("ab") > 5`},
+				Actions:    []Action{{Type: ActionTypeCalculate, Target: "Result.limit", Expression: "1000 * 12"}},
+			}
+			grl, err = converter.ConvertToGRL(standard)
+			So(err, ShouldBeNil)
+			So(grl, ShouldContainSubstring, "when\n        false\n")
+			So(grl, ShouldContainSubstring, "= 12000;")
+			So(warnings, ShouldHaveLength, 1)
+			So(warnings[0].Rule, ShouldEqual, "NEVER")
+			So(warnings[0].String(), ShouldContainSubstring, "永远不会触发")
+
+			grl, err = NewGRLConverter().ConvertToGRL(standard)
+			So(err, ShouldBeNil)
+			So(grl, ShouldContainSubstring, `Len("ab") > 5`)
+		})
+	})
+}
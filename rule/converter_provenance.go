@@ -0,0 +1,54 @@
+package rule
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ============================================================================
+// 来源注释 - 在生成的GRL头部标注来源规则、版本、作者、生成时间和定义哈希
+// ============================================================================
+
+// provenance 生成规则来源注释，未开启EmitProvenance时返回空字符串
+//
+// 输出示例:
+//
+//	// source: rule R004 v3
+//	// author: alice
+//	// generatedAt: 2026-01-02T15:04:05Z
+//	// definitionHash: 3f2a...
+func (c *GRLConverter) provenance(source string, version int, author string, definition interface{}) string {
+	if !c.config.EmitProvenance {
+		return ""
+	}
+
+	var b strings.Builder
+	if version > 0 {
+		b.WriteString(fmt.Sprintf("// source: %s v%d\n", commentSafe(source), version))
+	} else {
+		b.WriteString(fmt.Sprintf("// source: %s\n", commentSafe(source)))
+	}
+	if author != "" {
+		b.WriteString(fmt.Sprintf("// author: %s\n", commentSafe(author)))
+	}
+	b.WriteString(fmt.Sprintf("// generatedAt: %s\n", time.Now().UTC().Format(time.RFC3339)))
+	b.WriteString(fmt.Sprintf("// definitionHash: %s\n", DefinitionHash(definition)))
+	return b.String()
+}
+
+// DefinitionHash 计算规则定义哈希 - JSON序列化后取SHA256，与动态引擎缓存键一致
+func DefinitionHash(definition interface{}) string {
+	data, err := json.Marshal(definition)
+	if err != nil {
+		data = []byte(fmt.Sprintf("%+v", definition))
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(data))
+}
+
+// commentSafe 去除换行，避免注释内容破坏GRL结构
+func commentSafe(s string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(s)
+}
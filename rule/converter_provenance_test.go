@@ -0,0 +1,56 @@
+package rule
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// TestConverterProvenance 测试GRL来源注释
+func TestConverterProvenance(t *testing.T) {
+	Convey("GRL来源注释", t, func() {
+		rule := NewStandardRule("R004", "成年检查")
+		rule.Version = 3
+		rule.Author = "alice"
+		rule.AddSimpleCondition("customer.age", OpGreaterThanOrEqual, 18)
+		rule.AddAction(ActionTypeAssign, "Result.adult", true)
+
+		Convey("默认不输出来源注释", func() {
+			grl, err := NewGRLConverter().ConvertToGRL(*rule)
+			So(err, ShouldBeNil)
+			So(grl, ShouldNotContainSubstring, "// source:")
+		})
+
+		Convey("开启后在规则头部输出来源注释", func() {
+			converter := NewGRLConverter(ConverterConfig{EmitProvenance: true, StrictMode: true})
+			grl, err := converter.ConvertToGRL(*rule)
+			So(err, ShouldBeNil)
+			So(grl, ShouldStartWith, "// source: rule R004 v3\n")
+			So(grl, ShouldContainSubstring, "// author: alice\n")
+			So(grl, ShouldContainSubstring, "// generatedAt: ")
+			So(grl, ShouldContainSubstring, "// definitionHash: "+DefinitionHash(*rule)+"\n")
+			So(strings.Index(grl, "// definitionHash"), ShouldBeLessThan, strings.Index(grl, "\nrule R004 "))
+		})
+
+		Convey("简化规则和指标规则", func() {
+			converter := NewGRLConverter(ConverterConfig{EmitProvenance: true})
+
+			grl, err := converter.ConvertToGRL(SimpleRule{When: "customer.age > 18", Then: map[string]string{"Result.ok": "true"}})
+			So(err, ShouldBeNil)
+			So(grl, ShouldStartWith, "// source: simple rule\n")
+			So(grl, ShouldNotContainSubstring, "// author:")
+
+			grl, err = converter.ConvertToGRL(MetricRule{Name: "score", Formula: "customer.age * 2"})
+			So(err, ShouldBeNil)
+			So(grl, ShouldStartWith, "// source: metric score\n")
+		})
+
+		Convey("注释内容中的换行被替换", func() {
+			rule.Author = "alice\nrule Evil"
+			grl, err := NewGRLConverter(ConverterConfig{EmitProvenance: true}).ConvertToGRL(*rule)
+			So(err, ShouldBeNil)
+			So(grl, ShouldContainSubstring, "// author: alice rule Evil\n")
+		})
+	})
+}
@@ -0,0 +1,106 @@
+package rule
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/hyperjumptech/grule-rule-engine/builder"
+	"github.com/hyperjumptech/grule-rule-engine/pkg"
+)
+
+// ============================================================================
+// 严格模式 - 未知操作符/函数、未声明前缀的目标直接报错，生成的GRL必须可解析
+// ============================================================================
+
+var (
+	// functionCallPattern 匹配独立函数调用（不含对象方法调用 obj.Method()）
+	functionCallPattern = regexp.MustCompile(`(^|[^\w.])([A-Za-z_]\w*)\s*\(`)
+	// stringLiteralPattern 匹配字符串字面量，扫描函数调用前剔除
+	stringLiteralPattern = regexp.MustCompile(`"(?:[^"\\]|\\.)*"`)
+	// lineCommentPattern 匹配行注释（如来源注释），扫描函数调用前剔除
+	lineCommentPattern = regexp.MustCompile(`//[^\n]*`)
+)
+
+// converterFunctions 转换器自身生成的函数调用
+var converterFunctions = []string{"Retract", "Log", "LogDebug", "LogWarn", "LogError", "Alert", "Template", "ApproxEq", "Money", "Percent", "Present"}
+
+// logFunctions 日志级别 -> 日志动作生成的函数
+var logFunctions = map[string]string{
+	"":      "Log",
+	"info":  "Log",
+	"debug": "LogDebug",
+	"warn":  "LogWarn",
+	"error": "LogError",
+}
+
+// checkOperator 严格模式下校验操作符 - 必须在操作符映射中或已注册处理器
+func (c *GRLConverter) checkOperator(op string) error {
+	if !c.config.StrictMode {
+		return nil
+	}
+	if _, ok := c.config.OperatorMapping[op]; ok {
+		return nil
+	}
+	if _, ok := c.operatorHandler(op); ok {
+		return nil
+	}
+	return fmt.Errorf("严格模式下不支持的操作符: %s", op)
+}
+
+// checkTarget 严格模式下校验赋值目标 - 必须以声明的变量前缀或Result开头
+func (c *GRLConverter) checkTarget(target string) error {
+	if !c.config.StrictMode {
+		return nil
+	}
+	if strings.HasPrefix(target, "Result.") || strings.HasPrefix(target, "result.") || c.isVariable(target) {
+		return nil
+	}
+	return fmt.Errorf("严格模式下目标 %s 不在声明的变量前缀内", target)
+}
+
+// checkGenerated 严格模式下校验生成的GRL - 函数调用必须已声明且GRL可解析
+func (c *GRLConverter) checkGenerated(grl string) error {
+	if !c.config.StrictMode {
+		return nil
+	}
+
+	known := c.knownFunctions()
+	code := stringLiteralPattern.ReplaceAllString(grl, `""`)
+	code = lineCommentPattern.ReplaceAllString(code, "")
+	for _, match := range functionCallPattern.FindAllStringSubmatch(code, -1) {
+		name := match[2]
+		if !known[name] {
+			return fmt.Errorf("严格模式下未知函数: %s", name)
+		}
+	}
+
+	ruleBuilder := builder.NewRuleBuilder(ast.NewKnowledgeLibrary())
+	if err := ruleBuilder.BuildRuleFromResource("StrictCheck", "0.0.1", pkg.NewBytesResource([]byte(grl))); err != nil {
+		return fmt.Errorf("生成的GRL解析失败: %w", err)
+	}
+	return nil
+}
+
+// knownFunctions 严格模式允许的函数名 - 函数映射的键和值、函数式操作符及转换器内置函数
+func (c *GRLConverter) knownFunctions() map[string]bool {
+	known := make(map[string]bool)
+	for _, name := range converterFunctions {
+		known[name] = true
+	}
+	// 条件以括号开头时 when (a) && (b) 中的关键字会被识别为函数调用
+	for name := range grlKeywords {
+		known[name] = true
+	}
+	for key, value := range c.config.FunctionMapping {
+		known[key] = true
+		known[strings.TrimSuffix(value, "()")] = true
+	}
+	for key, value := range c.config.OperatorMapping {
+		// 操作符关键字(not/in等)后可直接跟括号
+		known[key] = true
+		known[value] = true
+	}
+	return known
+}
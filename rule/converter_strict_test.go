@@ -0,0 +1,99 @@
+package rule
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// TestConverterStrictMode 测试转换器严格模式
+func TestConverterStrictMode(t *testing.T) {
+	Convey("转换器严格模式", t, func() {
+		strict := NewGRLConverter(ConverterConfig{StrictMode: true})
+		loose := NewGRLConverter()
+
+		newRule := func(op Operator, target string) *StandardRule {
+			rule := NewStandardRule("STRICT_TEST", "严格模式测试")
+			rule.AddSimpleCondition("customer.age", op, 18)
+			rule.AddAction(ActionTypeAssign, target, true)
+			return rule
+		}
+
+		Convey("合法规则正常转换", func() {
+			grl, err := strict.ConvertToGRL(*newRule(OpGreaterThanOrEqual, "Result.adult"))
+			So(err, ShouldBeNil)
+			So(grl, ShouldContainSubstring, "customer.age >= 18")
+		})
+
+		Convey("复合条件以括号开头时正常转换", func() {
+			rule := newRule(OpGreaterThanOrEqual, "Result.adult")
+			rule.AddSimpleCondition("customer.income", OpGreaterThan, 50000)
+			grl, err := strict.ConvertToGRL(*rule)
+			So(err, ShouldBeNil)
+			So(grl, ShouldContainSubstring, "(customer.age >= 18) && (customer.income > 50000)")
+		})
+
+		Convey("未知操作符报错", func() {
+			_, err := strict.ConvertToGRL(*newRule(Operator("approx"), "Result.adult"))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "approx")
+
+			// 非严格模式原样透传
+			grl, err := loose.ConvertToGRL(*newRule(Operator("approx"), "Result.adult"))
+			So(err, ShouldBeNil)
+			So(grl, ShouldContainSubstring, "approx")
+		})
+
+		Convey("已注册的自定义操作符可用", func() {
+			strict.RegisterOperator("atLeast", OperatorFunc(func(left, right string) string {
+				return left + " >= " + right
+			}))
+			_, err := strict.ConvertToGRL(*newRule(Operator("atLeast"), "Result.adult"))
+			So(err, ShouldBeNil)
+		})
+
+		Convey("未声明前缀的目标报错", func() {
+			_, err := strict.ConvertToGRL(*newRule(OpGreaterThanOrEqual, "Unknown.flag"))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "Unknown.flag")
+
+			_, err = strict.ConvertToGRL(SimpleRule{
+				When: "customer.age > 18",
+				Then: map[string]string{"flag": "true"},
+			})
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("未知函数报错", func() {
+			rule := NewStandardRule("FUNC_TEST", "函数测试")
+			rule.Conditions = Condition{
+				Type:       ConditionTypeExpression,
+				Expression: "Mystery(customer.age) > 1",
+			}
+			rule.AddAction(ActionTypeAssign, "Result.ok", true)
+
+			_, err := strict.ConvertToGRL(*rule)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "Mystery")
+
+			// 字符串字面量中的括号不视为函数调用
+			rule.Conditions.Expression = `customer.name == "Mystery(1)"`
+			_, err = strict.ConvertToGRL(*rule)
+			So(err, ShouldBeNil)
+		})
+
+		Convey("生成的GRL无法解析时报错", func() {
+			rule := NewStandardRule("PARSE_TEST", "解析测试")
+			rule.Conditions = Condition{
+				Type:       ConditionTypeExpression,
+				Expression: "customer.age >= >= 18",
+			}
+			rule.AddAction(ActionTypeAssign, "Result.ok", true)
+
+			grl, err := strict.ConvertToGRL(*rule)
+			So(err, ShouldNotBeNil)
+			So(grl, ShouldBeEmpty)
+			So(err.Error(), ShouldContainSubstring, "GRL解析失败")
+		})
+	})
+}
@@ -0,0 +1,157 @@
+package rule
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ============================================================================
+// 规则成本估算 - 发布前静态分析规则集，按条件数、正则与查找调用、事实大小预测单次求值成本
+// ============================================================================
+
+// CostTier 单次求值成本等级
+type CostTier string
+
+const (
+	CostTierLow     CostTier = "low"     // 低于10µs
+	CostTierMedium  CostTier = "medium"  // 10µs ~ 100µs
+	CostTierHigh    CostTier = "high"    // 100µs ~ 1ms
+	CostTierExtreme CostTier = "extreme" // 1ms以上
+)
+
+// 单位成本 - 基于内存缓存命中、Grule解释执行的经验值，仅用于分级和预算比较
+const (
+	costPerRule      = 500 * time.Nanosecond // 每条规则的匹配与调度开销
+	costPerCondition = 100 * time.Nanosecond // 每个比较/逻辑条件
+	costPerFunction  = 200 * time.Nanosecond // 每次内置或自定义函数调用
+	costPerRegex     = 5 * time.Microsecond  // 每次正则匹配（Matches、IsEmail等）
+	costPerLookup    = 20 * time.Microsecond // 每次查找对象调用，如 Blacklist.Contains(...)
+	costPerFact      = 50 * time.Nanosecond  // 每次事实字段访问
+	costPerField     = 100 * time.Nanosecond // 每个输入字段的注入开销
+)
+
+// regexFunctions 内部使用正则匹配的内置函数
+var regexFunctions = map[string]bool{
+	"Matches":       true,
+	"IsEmail":       true,
+	"IsPhoneNumber": true,
+	"IsIDCard":      true,
+}
+
+var (
+	// grlWhenClauseRegex 匹配规则的 when 条件部分
+	grlWhenClauseRegex = regexp.MustCompile(`(?s)\bwhen\b(.*?)\bthen\b`)
+	// grlConditionOpRegex 匹配比较操作符
+	grlConditionOpRegex = regexp.MustCompile(`==|!=|>=|<=|>|<`)
+	// grlFactAccessRegex 匹配事实字段访问，如 Params.age、Params["age"]、Result.score
+	grlFactAccessRegex = regexp.MustCompile(`\b(?:Params|Result|result)\s*(?:\.[A-Za-z_]\w*|\[)`)
+)
+
+// RuleCost 单条规则的成本分析
+type RuleCost struct {
+	Rule       string        `json:"rule"`       // 规则名称
+	Conditions int           `json:"conditions"` // 条件数（比较操作与逻辑连接）
+	Functions  int           `json:"functions"`  // 函数调用次数（不含正则与查找）
+	Regex      int           `json:"regex"`      // 正则匹配次数
+	Lookups    int           `json:"lookups"`    // 查找对象调用次数
+	FactAccess int           `json:"factAccess"` // 事实字段访问次数
+	Estimate   time.Duration `json:"estimate"`   // 预估单次求值耗时
+}
+
+// CostEstimate 规则集的成本估算
+type CostEstimate struct {
+	Rules    []RuleCost    `json:"rules"`    // 各启用规则的成本，按规则顺序
+	FactSize int           `json:"factSize"` // 预期输入字段数
+	Estimate time.Duration `json:"estimate"` // 预估单次执行耗时（所有规则求值与输入注入之和）
+	Tier     CostTier      `json:"tier"`     // 成本等级
+}
+
+// TierFor 按预估耗时获取成本等级
+func TierFor(d time.Duration) CostTier {
+	switch {
+	case d < 10*time.Microsecond:
+		return CostTierLow
+	case d < 100*time.Microsecond:
+		return CostTierMedium
+	case d < time.Millisecond:
+		return CostTierHigh
+	default:
+		return CostTierExtreme
+	}
+}
+
+// EstimateCost 静态估算规则集的单次执行成本 - 用于发布前检查候选规则集
+//
+// 条件、正则与查找调用只统计 when 部分（每次求值都会执行），函数调用与事实访问统计整条规则。
+// 估算基于经验单位成本，适合分级和与延迟预算比较，不代表实际耗时。
+//
+// 参数:
+//
+//	rules    - 候选规则集，禁用的规则不计入
+//	factSize - 预期输入字段数，<=0时按规则引用的不同输入字段数计算
+//
+// 返回值:
+//
+//	CostEstimate - 成本估算
+func EstimateCost(rules []*Rule, factSize int) CostEstimate {
+	estimate := CostEstimate{Rules: []RuleCost{}}
+	var grls []string
+	for _, r := range rules {
+		if r == nil || !r.Enabled {
+			continue
+		}
+		cost := estimateRuleCost(r)
+		estimate.Rules = append(estimate.Rules, cost)
+		estimate.Estimate += cost.Estimate
+		grls = append(grls, r.GRL)
+	}
+
+	if factSize <= 0 {
+		factSize = len(AnalyzeGRLRequirements(grls...).InputFields)
+	}
+	estimate.FactSize = factSize
+	estimate.Estimate += time.Duration(factSize) * costPerField
+	estimate.Tier = TierFor(estimate.Estimate)
+	return estimate
+}
+
+// estimateRuleCost 估算单条规则的成本
+func estimateRuleCost(r *Rule) RuleCost {
+	cost := RuleCost{Rule: r.Name}
+
+	source := grlLineCommentRegex.ReplaceAllString(r.GRL, "")
+	source = grlStringLiteralRegex.ReplaceAllString(source, `""`)
+
+	for _, m := range grlWhenClauseRegex.FindAllStringSubmatch(source, -1) {
+		when := m[1]
+		cost.Conditions += len(grlConditionOpRegex.FindAllString(when, -1))
+		cost.Conditions += strings.Count(when, "&&") + strings.Count(when, "||")
+
+		for _, call := range grlMethodCallRegex.FindAllStringSubmatch(when, -1) {
+			if !inputFactNames[call[2]] {
+				cost.Lookups++
+			}
+		}
+		for _, call := range grlFunctionCallRegex.FindAllStringSubmatch(when, -1) {
+			if regexFunctions[call[2]] {
+				cost.Regex++
+			}
+		}
+	}
+
+	for _, call := range grlFunctionCallRegex.FindAllStringSubmatch(source, -1) {
+		if !grlKeywords[call[2]] && !regexFunctions[call[2]] {
+			cost.Functions++
+		}
+	}
+	cost.FactAccess = len(grlFactAccessRegex.FindAllString(source, -1))
+
+	cost.Estimate = costPerRule +
+		time.Duration(cost.Conditions)*costPerCondition +
+		time.Duration(cost.Functions)*costPerFunction +
+		time.Duration(cost.Regex)*costPerRegex +
+		time.Duration(cost.Lookups)*costPerLookup +
+		time.Duration(cost.FactAccess)*costPerFact
+	return cost
+}
@@ -0,0 +1,69 @@
+package rule
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// TestEstimateCost 测试规则集成本估算
+func TestEstimateCost(t *testing.T) {
+	Convey("规则集成本估算", t, func() {
+		simple := &Rule{Name: "simple", Enabled: true, GRL: `rule Simple "简单" {
+	when Params.amount > 0
+	then
+		Result["ok"] = true;
+		Retract("Simple");
+}`}
+		heavy := &Rule{Name: "heavy", Enabled: true, GRL: `rule Heavy "复杂" {
+	when Matches(Params.email, "^a.*(b)") && IsPhoneNumber(Params.phone) && Blacklist.Contains(Params.userId) && Params.age >= 18
+	then
+		Result["risk"] = Len(Params.email); // Matches(x) 注释不计入
+		Retract("Heavy");
+}`}
+		disabled := &Rule{Name: "disabled", Enabled: false, GRL: `rule Disabled "禁用" { when Matches(Params.x, "y") then Retract("Disabled"); }`}
+
+		Convey("统计条件、正则、查找与函数调用", func() {
+			estimate := EstimateCost([]*Rule{simple, heavy, disabled}, 0)
+			So(estimate.Rules, ShouldHaveLength, 2)
+
+			So(estimate.Rules[0].Rule, ShouldEqual, "simple")
+			So(estimate.Rules[0].Conditions, ShouldEqual, 1)
+			So(estimate.Rules[0].Regex, ShouldEqual, 0)
+			So(estimate.Rules[0].Functions, ShouldEqual, 1)
+
+			cost := estimate.Rules[1]
+			So(cost.Conditions, ShouldEqual, 4)
+			So(cost.Regex, ShouldEqual, 2)
+			So(cost.Lookups, ShouldEqual, 1)
+			So(cost.Functions, ShouldEqual, 2)
+			So(cost.FactAccess, ShouldEqual, 6)
+			So(cost.Estimate, ShouldBeGreaterThan, estimate.Rules[0].Estimate)
+
+			So(estimate.FactSize, ShouldEqual, 5)
+			So(estimate.Estimate, ShouldEqual, estimate.Rules[0].Estimate+cost.Estimate+5*costPerField)
+			So(estimate.Tier, ShouldEqual, CostTierMedium)
+		})
+
+		Convey("指定输入字段数", func() {
+			estimate := EstimateCost([]*Rule{simple}, 100)
+			So(estimate.FactSize, ShouldEqual, 100)
+			So(estimate.Tier, ShouldEqual, CostTierMedium)
+		})
+
+		Convey("空规则集", func() {
+			estimate := EstimateCost(nil, 0)
+			So(estimate.Rules, ShouldBeEmpty)
+			So(estimate.Estimate, ShouldEqual, 0)
+			So(estimate.Tier, ShouldEqual, CostTierLow)
+		})
+
+		Convey("成本分级", func() {
+			So(TierFor(5*time.Microsecond), ShouldEqual, CostTierLow)
+			So(TierFor(50*time.Microsecond), ShouldEqual, CostTierMedium)
+			So(TierFor(500*time.Microsecond), ShouldEqual, CostTierHigh)
+			So(TierFor(2*time.Millisecond), ShouldEqual, CostTierExtreme)
+		})
+	})
+}
@@ -0,0 +1,151 @@
+package rule
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ============================================================================
+// 跨字段校验 - 单字段规则无法表达的字段间约束，如 end_date > start_date、sum(items.amount) == total
+// ============================================================================
+//
+// ValidationRule 设置 Compare 和 Operator 后为跨字段校验，要求 Field Operator Compare 成立。
+// 两侧为字段引用（单个标识符视为输入参数，如 end_date => Params["end_date"]；也可写完整引用 order.Total），
+// 或对集合元素字段的聚合，参数最后一段为元素字段:
+//
+//	sum(items.amount)      => SumBy(Params["items"], "amount")
+//	avg/min/max(集合.字段)  => AvgBy/MinBy/MaxBy(集合, "字段")
+//	count(items)           => CountOf(Params["items"])
+//
+// 约束不成立时与结构体标签生成的规则相同，写入 Result["valid"] = false 和
+// Result["error.<Field>"] = 错误消息，默认消息同时包含两侧字段，如 "end_date 必须大于 start_date"。
+
+// crossFieldAggregateRegex 匹配聚合表达式 函数名(参数)
+var crossFieldAggregateRegex = regexp.MustCompile(`^(sum|avg|min|max|count)\((.+)\)$`)
+
+// crossFieldOperators 跨字段校验支持的操作符及其消息用语
+var crossFieldOperators = map[Operator]string{
+	OpEqual:              "等于",
+	OpNotEqual:           "不等于",
+	OpGreaterThan:        "大于",
+	OpGreaterThanOrEqual: "大于等于",
+	OpLessThan:           "小于",
+	OpLessThanOrEqual:    "小于等于",
+}
+
+// crossFieldAggregates 聚合名对应的引擎函数
+var crossFieldAggregates = map[string]string{
+	"sum":   "SumBy",
+	"avg":   "AvgBy",
+	"min":   "MinBy",
+	"max":   "MaxBy",
+	"count": "CountOf",
+}
+
+// IsCrossField 是否为跨字段校验
+func (v ValidationRule) IsCrossField() bool {
+	return v.Compare != ""
+}
+
+// validateCrossField 校验跨字段约束的定义
+func (v ValidationRule) validateCrossField() error {
+	if strings.TrimSpace(v.Field) == "" {
+		return fmt.Errorf("校验规则的字段不能为空")
+	}
+	if !v.IsCrossField() {
+		return fmt.Errorf("校验规则 %s 没有对比字段，单字段校验请使用结构体标签生成", v.Field)
+	}
+	if _, ok := crossFieldOperators[v.Operator]; !ok {
+		return fmt.Errorf("校验规则 %s 的操作符 %q 不支持，只能为 ==、!=、>、>=、<、<=", v.Field, v.Operator)
+	}
+	if _, err := crossFieldOperand(v.Field); err != nil {
+		return err
+	}
+	_, err := crossFieldOperand(v.Compare)
+	return err
+}
+
+// ConvertValidationRule 转换跨字段校验规则
+//
+// 参数:
+//
+//	rule - 设置了 Compare 和 Operator 的校验规则
+//
+// 返回值:
+//
+//	string - GRL规则，约束不成立时写入 Result["valid"] 和 Result["error.<Field>"]
+//	error  - 不是跨字段校验、操作符不支持或聚合表达式无效
+func (c *GRLConverter) ConvertValidationRule(rule ValidationRule) (string, error) {
+	if err := rule.validateCrossField(); err != nil {
+		return "", err
+	}
+	left, _ := crossFieldOperand(rule.Field)
+	right, _ := crossFieldOperand(rule.Compare)
+
+	field, compare := crossFieldLabel(rule.Field), crossFieldLabel(rule.Compare)
+	message := rule.Message
+	if message == "" {
+		message = fmt.Sprintf("%s 必须%s %s", field, crossFieldOperators[rule.Operator], compare)
+	}
+	ruleName := c.sanitizeRuleName("Validate_" + field + "_" + compare)
+
+	var grl strings.Builder
+	grl.WriteString(c.provenance("validation "+rule.Field, 0, "", rule))
+	grl.WriteString(fmt.Sprintf("rule %s %s salience %d {\n", ruleName, strconv.Quote(message), structRulePriority))
+	grl.WriteString(fmt.Sprintf("    when\n        !(%s %s %s)\n", left, rule.Operator, right))
+	grl.WriteString("    then\n")
+	grl.WriteString(fmt.Sprintf("        Result[%s] = false;\n", strconv.Quote(StructRuleValidKey)))
+	grl.WriteString(fmt.Sprintf("        Result[%s] = %s;\n", strconv.Quote(StructRuleErrorPrefix+field), strconv.Quote(message)))
+	grl.WriteString(fmt.Sprintf("        Retract(\"%s\");\n", ruleName))
+	grl.WriteString("}")
+	return grl.String(), nil
+}
+
+// crossFieldOperand 将字段引用或聚合表达式转换为GRL表达式
+func crossFieldOperand(ref string) (string, error) {
+	ref = strings.TrimSpace(ref)
+	m := crossFieldAggregateRegex.FindStringSubmatch(ref)
+	if m == nil {
+		return crossFieldPath(ref)
+	}
+
+	name, arg := m[1], strings.TrimSpace(m[2])
+	if name == "count" {
+		items, err := crossFieldPath(arg)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s(%s)", crossFieldAggregates[name], items), nil
+	}
+	idx := strings.LastIndex(arg, ".")
+	if idx <= 0 || !referenceNameRegex.MatchString(arg[idx+1:]) {
+		return "", fmt.Errorf("聚合 %s 需要 集合.字段 形式的参数", ref)
+	}
+	items, err := crossFieldPath(arg[:idx])
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s(%s, %s)", crossFieldAggregates[name], items, strconv.Quote(arg[idx+1:])), nil
+}
+
+// crossFieldPath 字段引用，单个标识符视为输入参数 Params["name"]
+func crossFieldPath(ref string) (string, error) {
+	if referenceNameRegex.MatchString(ref) {
+		return fmt.Sprintf("Params[%s]", strconv.Quote(ref)), nil
+	}
+	if !fieldPathPattern.MatchString(ref) {
+		return "", fmt.Errorf("校验字段 %q 不是有效的字段引用", ref)
+	}
+	return ref, nil
+}
+
+// crossFieldLabel 消息和错误键中使用的字段名，去掉 Params. 前缀
+func crossFieldLabel(ref string) string {
+	ref = strings.TrimSpace(ref)
+	if m := crossFieldAggregateRegex.FindStringSubmatch(ref); m != nil {
+		return m[1] + "(" + strings.TrimPrefix(strings.TrimSpace(m[2]), "Params.") + ")"
+	}
+	return strings.TrimPrefix(ref, "Params.")
+}
@@ -0,0 +1,79 @@
+package rule
+
+import (
+	"testing"
+
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/hyperjumptech/grule-rule-engine/builder"
+	"github.com/hyperjumptech/grule-rule-engine/engine"
+	"github.com/hyperjumptech/grule-rule-engine/pkg"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// TestCrossFieldValidation 测试跨字段校验规则
+func TestCrossFieldValidation(t *testing.T) {
+	Convey("跨字段校验规则", t, func() {
+		converter := NewGRLConverter()
+
+		Convey("字段比较生成否定条件和包含两侧字段的消息", func() {
+			grl, err := converter.ConvertToGRL(ValidationRule{Field: "end_date", Operator: OpGreaterThan, Compare: "start_date"})
+			So(err, ShouldBeNil)
+			So(grl, ShouldContainSubstring, `rule Validate_end_date_start_date "end_date 必须大于 start_date" salience 1000`)
+			So(grl, ShouldContainSubstring, `!(Params["end_date"] > Params["start_date"])`)
+			So(grl, ShouldContainSubstring, `Result["valid"] = false;`)
+			So(grl, ShouldContainSubstring, `Result["error.end_date"] = "end_date 必须大于 start_date";`)
+		})
+
+		Convey("聚合转换为引擎集合函数", func() {
+			grl, err := converter.ConvertValidationRule(ValidationRule{Field: "sum(items.amount)", Operator: OpEqual, Compare: "Params.total"})
+			So(err, ShouldBeNil)
+			So(grl, ShouldContainSubstring, `!(SumBy(Params["items"], "amount") == Params.total)`)
+			So(grl, ShouldContainSubstring, `"sum(items.amount) 必须等于 total"`)
+
+			grl, err = converter.ConvertValidationRule(ValidationRule{Field: "count(order.Items)", Operator: OpLessThanOrEqual, Compare: "max_items", Message: "商品数超限"})
+			So(err, ShouldBeNil)
+			So(grl, ShouldContainSubstring, `!(CountOf(order.Items) <= Params["max_items"])`)
+			So(grl, ShouldContainSubstring, `Result["error.count(order.Items)"] = "商品数超限";`)
+		})
+
+		Convey("无效定义返回错误", func() {
+			_, err := converter.ConvertToGRL(ValidationRule{Field: "end_date"})
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "没有对比字段")
+
+			_, err = converter.ConvertToGRL(&ValidationRule{Field: "end_date", Operator: OpContains, Compare: "start_date"})
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "不支持")
+
+			_, err = converter.ConvertToGRL(ValidationRule{Field: "sum(items)", Operator: OpEqual, Compare: "total"})
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "集合.字段")
+
+			So(converter.Validate(ValidationRule{Field: "a b", Operator: OpEqual, Compare: "total"}), ShouldNotBeNil)
+			So(converter.Validate(ValidationRule{Field: "end_date", Operator: OpGreaterThan, Compare: "start_date"}), ShouldBeNil)
+		})
+
+		Convey("规则执行", func() {
+			grl, err := converter.ConvertToGRL(ValidationRule{Field: "end_date", Operator: OpGreaterThan, Compare: "start_date"})
+			So(err, ShouldBeNil)
+
+			lib := ast.NewKnowledgeLibrary()
+			So(builder.NewRuleBuilder(lib).BuildRuleFromResource("cross_biz", "1.0.0", pkg.NewBytesResource([]byte(grl))), ShouldBeNil)
+			exec := func(params map[string]interface{}) map[string]interface{} {
+				kb, err := lib.NewKnowledgeBaseInstance("cross_biz", "1.0.0")
+				So(err, ShouldBeNil)
+				result := map[string]interface{}{}
+				dataCtx := ast.NewDataContext()
+				So(dataCtx.Add("Params", params), ShouldBeNil)
+				So(dataCtx.Add("Result", result), ShouldBeNil)
+				So(engine.NewGruleEngine().Execute(dataCtx, kb), ShouldBeNil)
+				return result
+			}
+
+			So(exec(map[string]interface{}{"start_date": "2024-01-01", "end_date": "2024-02-01"}), ShouldBeEmpty)
+			result := exec(map[string]interface{}{"start_date": "2024-03-01", "end_date": "2024-02-01"})
+			So(result["valid"], ShouldEqual, false)
+			So(result["error.end_date"], ShouldEqual, "end_date 必须大于 start_date")
+		})
+	})
+}
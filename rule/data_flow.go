@@ -0,0 +1,229 @@
+package rule
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ============================================================================
+// 数据流分析 - 根据规则读写的Result字段构建规则间依赖图
+// ============================================================================
+
+// grlResultFieldRegex 匹配Result字段访问，如 Result["score"]、Result.score
+var grlResultFieldRegex = regexp.MustCompile(`\b[Rr]esult(?:\s*\[\s*"((?:[^"\\]|\\.)*)"\s*\]|\.([A-Za-z_]\w*))`)
+
+// grlAccessorRegex 匹配字段访问后的下标或成员访问，判断赋值时跳过
+var grlAccessorRegex = regexp.MustCompile(`^\s*(?:\[[^\]]*\]|\.[A-Za-z_]\w*)`)
+
+// grlAssignOperators GRL赋值操作符
+var grlAssignOperators = []string{"+=", "-=", "*=", "/="}
+
+// ResultAccess 规则对Result顶层字段的读写
+type ResultAccess struct {
+	Reads  []string // 读取的字段
+	Writes []string // 写入的字段，包括对嵌套字段的赋值，如 Result["detail"]["limit"] = 1 记为 detail
+}
+
+// AnalyzeResultAccess 分析GRL读写的Result顶层字段 - 只识别字面量字段名，动态下标不计入
+//
+// 参数:
+//
+//	grl - GRL规则内容
+//
+// 返回值:
+//
+//	ResultAccess - 读写字段（已去重排序）
+func AnalyzeResultAccess(grl string) ResultAccess {
+	reads := make(map[string]bool)
+	writes := make(map[string]bool)
+
+	source := grlLineCommentRegex.ReplaceAllString(grl, "")
+	for _, m := range grlResultFieldRegex.FindAllStringSubmatchIndex(source, -1) {
+		field := ""
+		if m[2] >= 0 {
+			field = source[m[2]:m[3]]
+		} else {
+			field = source[m[4]:m[5]]
+		}
+
+		rest := source[m[1]:]
+		for loc := grlAccessorRegex.FindStringIndex(rest); loc != nil; loc = grlAccessorRegex.FindStringIndex(rest) {
+			rest = rest[loc[1]:]
+		}
+		rest = strings.TrimLeft(rest, " \t\r\n")
+
+		switch {
+		case strings.HasPrefix(rest, "("):
+			// 方法调用，如 Result.score.String()，不视为字段读写
+		case isAssignment(rest):
+			writes[field] = true
+		default:
+			reads[field] = true
+		}
+	}
+
+	return ResultAccess{Reads: sortedKeys(reads), Writes: sortedKeys(writes)}
+}
+
+// isAssignment 判断字段访问后是否紧跟赋值操作符
+func isAssignment(rest string) bool {
+	if strings.HasPrefix(rest, "=") {
+		return !strings.HasPrefix(rest, "==")
+	}
+	for _, op := range grlAssignOperators {
+		if strings.HasPrefix(rest, op) {
+			return true
+		}
+	}
+	return false
+}
+
+// UndeclaredWrites 规则写入但未在Writes中声明的Result字段 - 未声明任何写入字段的规则不检查
+//
+// 参数:
+//
+//	r - 规则
+//
+// 返回值:
+//
+//	[]string - 未声明的字段（已排序），无违规时为空
+func UndeclaredWrites(r *Rule) []string {
+	if r == nil || len(r.Writes) == 0 {
+		return nil
+	}
+	declared := make(map[string]bool, len(r.Writes))
+	for _, field := range r.Writes {
+		declared[field] = true
+	}
+
+	var undeclared []string
+	for _, field := range AnalyzeResultAccess(r.GRL).Writes {
+		if !declared[field] {
+			undeclared = append(undeclared, field)
+		}
+	}
+	return undeclared
+}
+
+// DataFlowGraph 数据流图 - 节点为规则，边表示一条规则写入的字段被另一条规则读取
+type DataFlowGraph struct {
+	Nodes []DataFlowNode `json:"nodes"` // 规则节点
+	Edges []DataFlowEdge `json:"edges"` // 依赖边
+}
+
+// DataFlowNode 数据流图中的规则节点
+type DataFlowNode struct {
+	Rule       string   `json:"rule"`                 // 规则名称
+	Inputs     []string `json:"inputs"`               // 引用的输入字段，如 Params.amount
+	Reads      []string `json:"reads"`                // 读取的Result字段
+	Writes     []string `json:"writes"`               // 写入的Result字段，已声明时为声明的字段，否则为分析得到的字段
+	Declared   bool     `json:"declared"`             // 是否声明了写入字段
+	Undeclared []string `json:"undeclared,omitempty"` // 写入但未声明的字段
+}
+
+// DataFlowEdge 数据流依赖边
+type DataFlowEdge struct {
+	From  string `json:"from"`  // 写入字段的规则
+	To    string `json:"to"`    // 读取字段的规则
+	Field string `json:"field"` // Result字段
+}
+
+// AnalyzeDataFlow 分析规则集的数据流 - 优先使用规则声明的写入字段，未声明时按GRL分析
+//
+// 参数:
+//
+//	rules - 规则列表，禁用的规则不参与分析
+//
+// 返回值:
+//
+//	*DataFlowGraph - 数据流图，节点按规则顺序排列，边按来源、目标、字段排序
+func AnalyzeDataFlow(rules []*Rule) *DataFlowGraph {
+	graph := &DataFlowGraph{Nodes: []DataFlowNode{}, Edges: []DataFlowEdge{}}
+	writers := make(map[string][]string)
+
+	for _, r := range rules {
+		if r == nil || !r.Enabled {
+			continue
+		}
+		access := AnalyzeResultAccess(r.GRL)
+		node := DataFlowNode{
+			Rule:     r.Name,
+			Inputs:   AnalyzeGRLRequirements(r.GRL).InputFields,
+			Reads:    access.Reads,
+			Writes:   access.Writes,
+			Declared: len(r.Writes) > 0,
+		}
+		if node.Declared {
+			node.Writes = sortedKeys(toSet(r.Writes))
+			node.Undeclared = UndeclaredWrites(r)
+		}
+
+		for _, field := range append(append([]string(nil), node.Writes...), node.Undeclared...) {
+			writers[field] = append(writers[field], r.Name)
+		}
+		graph.Nodes = append(graph.Nodes, node)
+	}
+
+	for _, node := range graph.Nodes {
+		for _, field := range node.Reads {
+			for _, writer := range writers[field] {
+				if writer != node.Rule {
+					graph.Edges = append(graph.Edges, DataFlowEdge{From: writer, To: node.Rule, Field: field})
+				}
+			}
+		}
+	}
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		a, b := graph.Edges[i], graph.Edges[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if a.To != b.To {
+			return a.To < b.To
+		}
+		return a.Field < b.Field
+	})
+	return graph
+}
+
+// DOT 以Graphviz DOT格式输出数据流图 - 输入字段为椭圆节点，规则为方框节点
+func (g *DataFlowGraph) DOT() string {
+	var sb strings.Builder
+	sb.WriteString("digraph dataflow {\n")
+	sb.WriteString("  rankdir=LR;\n")
+
+	inputs := make(map[string]bool)
+	for _, node := range g.Nodes {
+		for _, input := range node.Inputs {
+			inputs[input] = true
+		}
+	}
+	for _, input := range sortedKeys(inputs) {
+		sb.WriteString(fmt.Sprintf("  %q [shape=ellipse];\n", input))
+	}
+	for _, node := range g.Nodes {
+		sb.WriteString(fmt.Sprintf("  %q [shape=box];\n", node.Rule))
+	}
+	for _, node := range g.Nodes {
+		for _, input := range node.Inputs {
+			sb.WriteString(fmt.Sprintf("  %q -> %q;\n", input, node.Rule))
+		}
+	}
+	for _, edge := range g.Edges {
+		sb.WriteString(fmt.Sprintf("  %q -> %q [label=%q];\n", edge.From, edge.To, edge.Field))
+	}
+
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// toSet 字符串切片转集合
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
@@ -0,0 +1,75 @@
+package rule
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// TestDataFlow 测试写入声明与数据流分析
+func TestDataFlow(t *testing.T) {
+	Convey("写入声明与数据流分析", t, func() {
+		score := &Rule{Name: "score", Enabled: true, Writes: []string{"score"}, GRL: `rule Score "评分" salience 100 {
+	when Params.amount > 0
+	then
+		Result["score"] = Params.amount * 2;
+		Retract("Score");
+}`}
+		grade := &Rule{Name: "grade", Enabled: true, Writes: []string{"grade"}, GRL: `rule Grade "分级" salience 50 {
+	when Result["score"] >= 100 && Params.vip == true
+	then
+		Result["grade"] = "A";
+		Result["detail"]["reason"] = "score"; // 未声明
+		Retract("Grade");
+}`}
+		decide := &Rule{Name: "decide", Enabled: true, GRL: `rule Decide "决策" {
+	when Result.grade == "A" || Result["score"] != nil
+	then
+		Result.approved = true;
+		Result["count"] += 1;
+		Retract("Decide");
+}`}
+		disabled := &Rule{Name: "disabled", Enabled: false, Writes: []string{"x"}, GRL: `rule Disabled "禁用" { when true then Result["y"] = Result["score"]; }`}
+
+		Convey("分析Result字段读写", func() {
+			access := AnalyzeResultAccess(grade.GRL)
+			So(access.Reads, ShouldResemble, []string{"score"})
+			So(access.Writes, ShouldResemble, []string{"detail", "grade"})
+
+			access = AnalyzeResultAccess(decide.GRL)
+			So(access.Reads, ShouldResemble, []string{"grade", "score"})
+			So(access.Writes, ShouldResemble, []string{"approved", "count"})
+		})
+
+		Convey("检查未声明的写入", func() {
+			So(UndeclaredWrites(score), ShouldBeEmpty)
+			So(UndeclaredWrites(grade), ShouldResemble, []string{"detail"})
+			So(UndeclaredWrites(decide), ShouldBeEmpty)
+		})
+
+		Convey("构建数据流图", func() {
+			graph := AnalyzeDataFlow([]*Rule{score, grade, decide, disabled})
+			So(graph.Nodes, ShouldHaveLength, 3)
+
+			So(graph.Nodes[0].Inputs, ShouldResemble, []string{"Params.amount"})
+			So(graph.Nodes[1].Declared, ShouldBeTrue)
+			So(graph.Nodes[1].Writes, ShouldResemble, []string{"grade"})
+			So(graph.Nodes[1].Undeclared, ShouldResemble, []string{"detail"})
+			So(graph.Nodes[2].Declared, ShouldBeFalse)
+			So(graph.Nodes[2].Writes, ShouldResemble, []string{"approved", "count"})
+
+			So(graph.Edges, ShouldResemble, []DataFlowEdge{
+				{From: "grade", To: "decide", Field: "grade"},
+				{From: "score", To: "decide", Field: "score"},
+				{From: "score", To: "grade", Field: "score"},
+			})
+		})
+
+		Convey("输出DOT格式", func() {
+			dot := AnalyzeDataFlow([]*Rule{score, grade}).DOT()
+			So(dot, ShouldStartWith, "digraph dataflow {")
+			So(dot, ShouldContainSubstring, `"Params.vip" -> "grade";`)
+			So(dot, ShouldContainSubstring, `"score" -> "grade" [label="score"];`)
+		})
+	})
+}
@@ -0,0 +1,470 @@
+package rule
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/hyperjumptech/grule-rule-engine/builder"
+	"github.com/hyperjumptech/grule-rule-engine/pkg"
+)
+
+// ============================================================================
+// 决策表 - 从电子表格导出的CSV导入规则，每个数据行生成一条标准规则
+// ============================================================================
+//
+// CSV格式约定（第一行表头、第二行操作符，之后每行一条规则）:
+//
+//	id,      name,   when:customer.age, when:customer.level, then:Result.discount, then:info
+//	,        ,       between,           in,                  assign,               log
+//	vip,     VIP折扣, 18;65,             gold;platinum,       0.8,                  VIP用户 ${customer.id}
+//	adult,   成人,    >= 18,             -,                   0.95,
+//
+// 表头:
+//
+//	when:字段    条件列，字段路径原样写入条件左操作数
+//	then:目标    动作列，目标原样写入动作Target（如 Result.discount，日志动作为日志级别 then:info）
+//	id/name/priority/description  可选的元数据列
+//
+// 操作符行: 条件列填写操作符（==、!=、>、>=、<、<=、in、notIn、contains、matches、between，默认==），
+// 动作列填写动作类型（assign、calculate、log、alert，默认assign），元数据列留空。
+//
+// 单元格: 空或 "-" 表示该行不限制此条件/不执行此动作；条件单元格可以操作符开头覆盖列操作符（如 ">= 18"）；
+// in/notIn/between 的多个值以分号分隔；数值、true/false 按类型解析，双引号包围的内容按字符串处理。
+// 规则按行序执行（先出现的行优先级高），多行命中时后执行的赋值覆盖先执行的赋值。
+
+// 决策表表头前缀和元数据列
+const (
+	decisionWhenPrefix = "when:"
+	decisionThenPrefix = "then:"
+	decisionListSep    = ";"
+)
+
+// decisionMetaColumns 决策表元数据列
+var decisionMetaColumns = map[string]bool{"id": true, "name": true, "priority": true, "description": true}
+
+// decisionCellOperators 条件单元格可覆盖的操作符，长的在前
+var decisionCellOperators = []Operator{OpGreaterThanOrEqual, OpLessThanOrEqual, OpNotEqual, OpEqual, OpGreaterThan, OpLessThan}
+
+// decisionTableOperators 条件列支持的操作符
+var decisionTableOperators = map[Operator]bool{
+	OpEqual: true, OpNotEqual: true, OpGreaterThan: true, OpGreaterThanOrEqual: true, OpLessThan: true, OpLessThanOrEqual: true,
+	OpIn: true, OpNotIn: true, OpContains: true, OpMatches: true, OpBetween: true,
+}
+
+// decisionTableActions 动作列支持的动作类型
+var decisionTableActions = map[ActionType]bool{ActionTypeAssign: true, ActionTypeCalculate: true, ActionTypeLog: true, ActionTypeAlert: true}
+
+// DecisionTable 决策表
+type DecisionTable struct {
+	ID         string           // 决策表标识，作为生成规则的ID前缀和标签
+	Priority   int              // 第一行规则的优先级，之后每行递减1，默认为行数+50
+	Conditions []DecisionColumn // 条件列
+	Actions    []DecisionColumn // 动作列
+	Rows       []DecisionRow    // 数据行
+}
+
+// DecisionColumn 决策表的条件列或动作列
+type DecisionColumn struct {
+	Index    int        // CSV列号（从1开始）
+	Header   string     // 原始表头
+	Field    string     // 条件字段路径或动作目标
+	Operator Operator   // 条件列的操作符
+	Action   ActionType // 动作列的动作类型
+}
+
+// DecisionRow 决策表数据行
+type DecisionRow struct {
+	Line        int      // CSV行号（从1开始）
+	ID          string   // id列，为空时为 决策表ID_行号
+	Name        string   // name列
+	Description string   // description列
+	Priority    string   // priority列，为空时按行序生成
+	Conditions  []string // 条件单元格，与 DecisionTable.Conditions 对应
+	Actions     []string // 动作单元格，与 DecisionTable.Actions 对应
+}
+
+// DecisionTableReport 决策表导入报告
+type DecisionTableReport struct {
+	Rows    int             // 数据行数
+	Rules   int             // 生成的规则数
+	Skipped []int           // 因单元格问题跳过的行号
+	Issues  []DecisionIssue // 无法解析的单元格
+}
+
+// HasIssues 是否存在无法解析的单元格
+func (r *DecisionTableReport) HasIssues() bool {
+	return len(r.Issues) > 0
+}
+
+// DecisionIssue 决策表单元格问题
+type DecisionIssue struct {
+	Line    int    // CSV行号（从1开始）
+	Column  int    // CSV列号（从1开始）
+	Header  string // 所在列的表头
+	Value   string // 单元格内容
+	Message string // 问题说明
+}
+
+// String 问题描述
+func (i DecisionIssue) String() string {
+	return fmt.Sprintf("第%d行第%d列(%s) %q: %s", i.Line, i.Column, i.Header, i.Value, i.Message)
+}
+
+// ImportDecisionTableCSV 从CSV导入决策表并生成标准规则
+//
+// 参数:
+//
+//	r       - CSV内容
+//	tableID - 决策表标识，作为规则ID前缀和标签
+//
+// 返回值:
+//
+//	[]StandardRule       - 生成的规则，存在问题的行不生成规则
+//	*DecisionTableReport - 导入报告，列出无法解析的单元格
+//	error                - CSV格式或表头、操作符行错误
+func ImportDecisionTableCSV(r io.Reader, tableID string) ([]StandardRule, *DecisionTableReport, error) {
+	table, err := ParseDecisionTableCSV(r, tableID)
+	if err != nil {
+		return nil, nil, err
+	}
+	rules, report := table.StandardRules()
+	return rules, report, nil
+}
+
+// ParseDecisionTableCSV 解析CSV决策表的表头、操作符行和数据行，单元格内容在生成规则时解析
+func ParseDecisionTableCSV(r io.Reader, tableID string) (*DecisionTable, error) {
+	if strings.TrimSpace(tableID) == "" {
+		return nil, fmt.Errorf("决策表标识不能为空")
+	}
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	// 逐行读取以记录原始行号，csv.Reader会跳过空行
+	var records [][]string
+	var lines []int
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("读取CSV失败: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+		records = append(records, record)
+		lines = append(lines, line)
+	}
+	if len(records) < 2 {
+		return nil, fmt.Errorf("决策表至少需要表头行和操作符行")
+	}
+
+	table := &DecisionTable{ID: tableID}
+	header, operators := records[0], records[1]
+	meta := make(map[string]int)
+	var errs []error
+	for i, cell := range header {
+		name := strings.TrimSpace(strings.TrimPrefix(cell, "\ufeff"))
+		op := ""
+		if i < len(operators) {
+			op = strings.TrimSpace(operators[i])
+		}
+		lower := strings.ToLower(name)
+		switch {
+		case strings.HasPrefix(lower, decisionWhenPrefix):
+			column := DecisionColumn{Index: i + 1, Header: name, Field: strings.TrimSpace(name[len(decisionWhenPrefix):]), Operator: OpEqual}
+			if op != "" {
+				column.Operator = Operator(op)
+			}
+			if !decisionTableOperators[column.Operator] {
+				errs = append(errs, fmt.Errorf("第%d列(%s)不支持的操作符: %s", i+1, name, op))
+			}
+			table.Conditions = append(table.Conditions, column)
+		case strings.HasPrefix(lower, decisionThenPrefix):
+			column := DecisionColumn{Index: i + 1, Header: name, Field: strings.TrimSpace(name[len(decisionThenPrefix):]), Action: ActionTypeAssign}
+			if op != "" {
+				column.Action = ActionType(strings.ToLower(op))
+			}
+			if !decisionTableActions[column.Action] {
+				errs = append(errs, fmt.Errorf("第%d列(%s)不支持的动作类型: %s", i+1, name, op))
+			}
+			if _, ok := logFunctions[strings.ToLower(column.Field)]; column.Action == ActionTypeLog && !ok {
+				errs = append(errs, fmt.Errorf("第%d列(%s)日志动作的目标必须是日志级别（debug/info/warn/error）", i+1, name))
+			}
+			table.Actions = append(table.Actions, column)
+		case decisionMetaColumns[lower]:
+			meta[lower] = i
+		case name == "":
+			// 空表头列忽略
+		default:
+			errs = append(errs, fmt.Errorf("第%d列表头 %q 无法识别，条件列以 when: 开头，动作列以 then: 开头", i+1, name))
+		}
+	}
+	if len(table.Actions) == 0 {
+		errs = append(errs, fmt.Errorf("决策表至少需要一个动作列（then:）"))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	cell := func(record []string, i int) string {
+		if i < 0 || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+	metaCell := func(record []string, name string) string {
+		if i, ok := meta[name]; ok {
+			return cell(record, i)
+		}
+		return ""
+	}
+	for n, record := range records[2:] {
+		if isBlankRecord(record) {
+			continue
+		}
+		row := DecisionRow{
+			Line:        lines[n+2],
+			ID:          metaCell(record, "id"),
+			Name:        metaCell(record, "name"),
+			Description: metaCell(record, "description"),
+			Priority:    metaCell(record, "priority"),
+		}
+		for _, column := range table.Conditions {
+			row.Conditions = append(row.Conditions, cell(record, column.Index-1))
+		}
+		for _, column := range table.Actions {
+			row.Actions = append(row.Actions, cell(record, column.Index-1))
+		}
+		table.Rows = append(table.Rows, row)
+	}
+	return table, nil
+}
+
+// StandardRules 将数据行转换为标准规则 - 存在无法解析单元格的行不生成规则并记录在报告中
+func (t *DecisionTable) StandardRules() ([]StandardRule, *DecisionTableReport) {
+	report := &DecisionTableReport{Rows: len(t.Rows)}
+	base := t.Priority
+	if base <= 0 {
+		base = len(t.Rows) + 50
+	}
+
+	var rules []StandardRule
+	for i, row := range t.Rows {
+		issues := len(report.Issues)
+		issue := func(column int, header, value, message string) {
+			report.Issues = append(report.Issues, DecisionIssue{Line: row.Line, Column: column, Header: header, Value: value, Message: message})
+		}
+
+		r := NewStandardRule(row.ID, row.Name)
+		if r.ID == "" {
+			r.ID = fmt.Sprintf("%s_%d", t.ID, row.Line)
+		}
+		if r.Name == "" {
+			r.Name = fmt.Sprintf("%s 第%d行", t.ID, row.Line)
+		}
+		r.Description = row.Description
+		r.Tags = []string{t.ID}
+		r.Priority = base - i
+		if row.Priority != "" {
+			priority, err := strconv.Atoi(row.Priority)
+			if err != nil {
+				issue(0, "priority", row.Priority, "优先级必须是整数")
+			}
+			r.Priority = priority
+		}
+
+		var conditions []Condition
+		for j, column := range t.Conditions {
+			value := row.Conditions[j]
+			if isDecisionWildcard(value) {
+				continue
+			}
+			cond, err := decisionCondition(column, value)
+			if err != nil {
+				issue(column.Index, column.Header, value, err.Error())
+				continue
+			}
+			conditions = append(conditions, cond)
+		}
+		switch len(conditions) {
+		case 0:
+			r.Conditions = Condition{Type: ConditionTypeExpression, Expression: "true"}
+		case 1:
+			r.Conditions = conditions[0]
+		default:
+			r.Conditions = Condition{Type: ConditionTypeComposite, Operator: OpAnd, Children: conditions}
+		}
+
+		for j, column := range t.Actions {
+			value := row.Actions[j]
+			if isDecisionWildcard(value) {
+				continue
+			}
+			action, err := decisionAction(column, value)
+			if err != nil {
+				issue(column.Index, column.Header, value, err.Error())
+				continue
+			}
+			r.Actions = append(r.Actions, action)
+		}
+		if len(r.Actions) == 0 && len(report.Issues) == issues {
+			issue(0, "", "", "该行没有任何动作")
+		}
+
+		if len(report.Issues) > issues {
+			report.Skipped = append(report.Skipped, row.Line)
+			continue
+		}
+		rules = append(rules, *r)
+	}
+	report.Rules = len(rules)
+	return rules, report
+}
+
+// decisionCondition 解析条件单元格
+func decisionCondition(column DecisionColumn, value string) (Condition, error) {
+	op := column.Operator
+	for _, candidate := range decisionCellOperators {
+		if rest, ok := strings.CutPrefix(value, string(candidate)); ok {
+			op, value = candidate, strings.TrimSpace(rest)
+			break
+		}
+	}
+	if value == "" {
+		return Condition{}, fmt.Errorf("操作符 %s 缺少取值", op)
+	}
+
+	simple := func(op Operator, right any) Condition {
+		return Condition{Type: ConditionTypeSimple, Left: column.Field, Operator: op, Right: right}
+	}
+	switch op {
+	case OpIn, OpNotIn:
+		values := splitDecisionList(value)
+		children := make([]Condition, len(values))
+		compare, join := OpEqual, OpOr
+		if op == OpNotIn {
+			compare, join = OpNotEqual, OpAnd
+		}
+		for i, v := range values {
+			children[i] = simple(compare, parseDecisionValue(v))
+		}
+		if len(children) == 1 {
+			return children[0], nil
+		}
+		return Condition{Type: ConditionTypeComposite, Operator: join, Children: children}, nil
+
+	case OpBetween:
+		values := splitDecisionList(value)
+		if len(values) != 2 {
+			return Condition{}, fmt.Errorf("between需要以分号分隔的两个值，如 18;65")
+		}
+		low, lowOK := parseDecisionNumber(values[0])
+		high, highOK := parseDecisionNumber(values[1])
+		if !lowOK || !highOK {
+			return Condition{}, fmt.Errorf("between的取值必须是数值")
+		}
+		if low > high {
+			return Condition{}, fmt.Errorf("between的下限大于上限")
+		}
+		return simple(OpBetween, []any{parseDecisionValue(values[0]), parseDecisionValue(values[1])}), nil
+
+	case OpGreaterThan, OpGreaterThanOrEqual, OpLessThan, OpLessThanOrEqual:
+		if _, ok := parseDecisionNumber(value); !ok {
+			return Condition{}, fmt.Errorf("操作符 %s 需要数值", op)
+		}
+		return simple(op, parseDecisionValue(value)), nil
+
+	case OpContains, OpMatches:
+		return simple(op, unquoteDecisionString(value)), nil
+
+	default:
+		return simple(op, parseDecisionValue(value)), nil
+	}
+}
+
+// decisionAction 解析动作单元格
+func decisionAction(column DecisionColumn, value string) (Action, error) {
+	switch column.Action {
+	case ActionTypeCalculate:
+		if err := checkDecisionExpression(value); err != nil {
+			return Action{}, err
+		}
+		return Action{Type: ActionTypeCalculate, Target: column.Field, Expression: value}, nil
+	case ActionTypeLog, ActionTypeAlert:
+		return Action{Type: column.Action, Target: column.Field, Value: unquoteDecisionString(value)}, nil
+	default:
+		return Action{Type: ActionTypeAssign, Target: column.Field, Value: parseDecisionValue(value)}, nil
+	}
+}
+
+// checkDecisionExpression 校验计算表达式可被Grule解析
+func checkDecisionExpression(expr string) error {
+	grl := fmt.Sprintf("rule DecisionCheck \"\" { when true then Result[\"v\"] = %s; }", expr)
+	ruleBuilder := builder.NewRuleBuilder(ast.NewKnowledgeLibrary())
+	if err := ruleBuilder.BuildRuleFromResource("DecisionCheck", "0.0.1", pkg.NewBytesResource([]byte(grl))); err != nil {
+		return fmt.Errorf("计算表达式无法解析")
+	}
+	return nil
+}
+
+// parseDecisionValue 解析单元格取值 - 整数、浮点数、布尔值按类型解析，双引号包围的内容和其他内容按字符串处理
+func parseDecisionValue(value string) any {
+	if len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) {
+		return value[1 : len(value)-1]
+	}
+	if i, err := strconv.Atoi(value); err == nil {
+		return i
+	}
+	if f, err := strconv.ParseFloat(value, 64); err == nil {
+		return f
+	}
+	if b, err := strconv.ParseBool(value); err == nil && (value == "true" || value == "false") {
+		return b
+	}
+	return value
+}
+
+// parseDecisionNumber 解析数值单元格
+func parseDecisionNumber(value string) (float64, bool) {
+	f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	return f, err == nil
+}
+
+// unquoteDecisionString 去除字符串单元格两端的双引号
+func unquoteDecisionString(value string) string {
+	if s, ok := parseDecisionValue(value).(string); ok {
+		return s
+	}
+	return value
+}
+
+// splitDecisionList 按分号拆分多值单元格
+func splitDecisionList(value string) []string {
+	var values []string
+	for _, v := range strings.Split(value, decisionListSep) {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// isDecisionWildcard 单元格是否表示不限制
+func isDecisionWildcard(value string) bool {
+	return value == "" || value == "-"
+}
+
+// isBlankRecord 是否为空行
+func isBlankRecord(record []string) bool {
+	for _, cell := range record {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
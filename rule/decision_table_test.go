@@ -0,0 +1,131 @@
+package rule
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/hyperjumptech/grule-rule-engine/builder"
+	"github.com/hyperjumptech/grule-rule-engine/pkg"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const discountTableCSV = `id,name,when:customer.age,when:customer.level,then:Result.discount,then:info
+,,between,in,assign,log
+vip,VIP折扣,18;65,gold;platinum,0.8,VIP用户 ${customer.id}
+adult,成人,>= 18,-,0.95,
+
+,兜底,-,-,1,"无折扣"
+`
+
+// TestDecisionTableCSV 测试CSV决策表导入
+func TestDecisionTableCSV(t *testing.T) {
+	Convey("CSV决策表导入", t, func() {
+		Convey("按表头约定生成标准规则", func() {
+			rules, report, err := ImportDecisionTableCSV(strings.NewReader(discountTableCSV), "discount")
+			So(err, ShouldBeNil)
+			So(report.HasIssues(), ShouldBeFalse)
+			So(report.Rows, ShouldEqual, 3)
+			So(report.Rules, ShouldEqual, 3)
+			So(rules, ShouldHaveLength, 3)
+
+			vip := rules[0]
+			So(vip.ID, ShouldEqual, "vip")
+			So(vip.Tags, ShouldResemble, []string{"discount"})
+			So(vip.Conditions.Type, ShouldEqual, ConditionTypeComposite)
+			So(vip.Conditions.Operator, ShouldEqual, OpAnd)
+			So(vip.Conditions.Children[0].Operator, ShouldEqual, OpBetween)
+			So(vip.Conditions.Children[0].Right, ShouldResemble, []any{18, 65})
+			So(vip.Conditions.Children[1].Operator, ShouldEqual, OpOr)
+			So(vip.Conditions.Children[1].Children, ShouldHaveLength, 2)
+			So(vip.Actions, ShouldResemble, []Action{
+				{Type: ActionTypeAssign, Target: "Result.discount", Value: 0.8},
+				{Type: ActionTypeLog, Target: "info", Value: "VIP用户 ${customer.id}"},
+			})
+
+			// 单元格操作符覆盖列操作符
+			adult := rules[1]
+			So(adult.Conditions, ShouldResemble, Condition{Type: ConditionTypeSimple, Left: "customer.age", Operator: OpGreaterThanOrEqual, Right: 18})
+			So(adult.Actions, ShouldHaveLength, 1)
+
+			// 无条件的行始终命中，空行不计入且不影响行号
+			fallback := rules[2]
+			So(fallback.ID, ShouldEqual, "discount_6")
+			So(fallback.Conditions.Expression, ShouldEqual, "true")
+			So(fallback.Actions[1].Value, ShouldEqual, "无折扣")
+
+			// 先出现的行优先级高
+			So(vip.Priority, ShouldBeGreaterThan, adult.Priority)
+			So(adult.Priority, ShouldBeGreaterThan, fallback.Priority)
+		})
+
+		Convey("生成的规则可转换为GRL", func() {
+			rules, _, err := ImportDecisionTableCSV(strings.NewReader(discountTableCSV), "discount")
+			So(err, ShouldBeNil)
+			converter := NewGRLConverter()
+			ruleBuilder := builder.NewRuleBuilder(ast.NewKnowledgeLibrary())
+			for _, r := range rules {
+				grl, err := converter.ConvertRule(r, Definitions{})
+				So(err, ShouldBeNil)
+				So(ruleBuilder.BuildRuleFromResource("discount", "1.0.0", pkg.NewBytesResource([]byte(grl))), ShouldBeNil)
+			}
+		})
+
+		Convey("报告无法解析的单元格并跳过该行", func() {
+			csv := `when:order.amount,when:order.channel,then:Result.fee,priority
+>,notIn,calculate,
+abc,app,order.amount * 0.01,
+100,web;h5,order.amount * ,
+200,-,order.amount * 0.02,high
+300,-,order.amount * 0.03,10
+`
+			rules, report, err := ImportDecisionTableCSV(strings.NewReader(csv), "fee")
+			So(err, ShouldBeNil)
+			So(rules, ShouldHaveLength, 1)
+			So(rules[0].Priority, ShouldEqual, 10)
+			So(rules[0].Conditions.Right, ShouldEqual, 300)
+			So(report.Skipped, ShouldResemble, []int{3, 4, 5})
+
+			So(report.Issues, ShouldHaveLength, 3)
+			So(report.Issues[0].Line, ShouldEqual, 3)
+			So(report.Issues[0].Column, ShouldEqual, 1)
+			So(report.Issues[0].Message, ShouldContainSubstring, "需要数值")
+			So(report.Issues[1].Header, ShouldEqual, "then:Result.fee")
+			So(report.Issues[1].Message, ShouldContainSubstring, "表达式无法解析")
+			So(report.Issues[2].Header, ShouldEqual, "priority")
+			So(fmt.Sprint(report.Issues[2]), ShouldContainSubstring, "第5行")
+		})
+
+		Convey("notIn拆分为不等于条件的与组合", func() {
+			csv := "when:order.channel,then:Result.ok\nnotIn,\n\"\"\"web\"\";h5\",true\n"
+			rules, report, err := ImportDecisionTableCSV(strings.NewReader(csv), "channel")
+			So(err, ShouldBeNil)
+			So(report.HasIssues(), ShouldBeFalse)
+			So(rules[0].Conditions.Operator, ShouldEqual, OpAnd)
+			So(rules[0].Conditions.Children[0], ShouldResemble, Condition{Type: ConditionTypeSimple, Left: "order.channel", Operator: OpNotEqual, Right: "web"})
+			So(rules[0].Actions[0].Value, ShouldEqual, true)
+		})
+
+		Convey("表头和操作符行错误返回错误", func() {
+			_, _, err := ImportDecisionTableCSV(strings.NewReader("when:a,then:b\nlike,\n"), "bad")
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "不支持的操作符")
+
+			_, _, err = ImportDecisionTableCSV(strings.NewReader("when:a,result\n==,\n"), "bad")
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "无法识别")
+			So(err.Error(), ShouldContainSubstring, "至少需要一个动作列")
+
+			_, _, err = ImportDecisionTableCSV(strings.NewReader("then:Result.note\nlog\n"), "bad")
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "日志级别")
+
+			_, _, err = ImportDecisionTableCSV(strings.NewReader("then:a\n"), "bad")
+			So(err, ShouldNotBeNil)
+
+			_, _, err = ImportDecisionTableCSV(strings.NewReader(discountTableCSV), "")
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
@@ -0,0 +1,78 @@
+package rule
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// ============================================================================
+// 紧急停用记录 - 持久化业务码的停用状态，实例重启后在执行前恢复
+// ============================================================================
+
+// BizDisablement 业务码紧急停用记录
+//
+// 表名：runehammer_biz_disablements
+type BizDisablement struct {
+	BizCode    string    `gorm:"primaryKey;size:100" json:"biz_code"` // 业务码
+	Reason     string    `gorm:"size:500;not null" json:"reason"`     // 停用原因
+	DisabledAt time.Time `gorm:"not null" json:"disabled_at"`         // 停用时间
+	UpdatedAt  time.Time `gorm:"autoUpdateTime" json:"updated_at"`    // 最近一次写入时间
+}
+
+// TableName 自定义表名
+func (BizDisablement) TableName() string {
+	return "runehammer_biz_disablements"
+}
+
+// DisablementMapper 紧急停用记录数据访问接口 - 可选扩展，未实现时停用状态只保存在内存中
+type DisablementMapper interface {
+	// FindDisablements 查找全部停用记录
+	//
+	// 返回值:
+	//   []*BizDisablement - 停用记录，停用记录表不存在（未迁移）时为空
+	//   error             - 查询错误
+	FindDisablements(ctx context.Context) ([]*BizDisablement, error)
+
+	// SaveDisablement 写入停用记录，业务码已停用时更新原因和时间
+	SaveDisablement(ctx context.Context, d *BizDisablement) error
+
+	// DeleteDisablement 删除业务码的停用记录，不存在时不报错
+	DeleteDisablement(ctx context.Context, bizCode string) error
+}
+
+// FindDisablements 查找全部停用记录
+func (r *ruleMapperImpl) FindDisablements(ctx context.Context) ([]*BizDisablement, error) {
+	db := r.db.WithContext(ctx)
+	if !db.Migrator().HasTable(&BizDisablement{}) {
+		return nil, nil
+	}
+
+	var list []*BizDisablement
+	if err := db.Order("biz_code ASC").Find(&list).Error; err != nil {
+		return nil, fmt.Errorf("查询停用记录失败: %w", err)
+	}
+	return list, nil
+}
+
+// SaveDisablement 写入停用记录
+func (r *ruleMapperImpl) SaveDisablement(ctx context.Context, d *BizDisablement) error {
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "biz_code"}},
+		DoUpdates: clause.AssignmentColumns([]string{"reason", "disabled_at", "updated_at"}),
+	}).Create(d).Error
+	if err != nil {
+		return fmt.Errorf("写入停用记录失败: %w", err)
+	}
+	return nil
+}
+
+// DeleteDisablement 删除业务码的停用记录
+func (r *ruleMapperImpl) DeleteDisablement(ctx context.Context, bizCode string) error {
+	if err := r.db.WithContext(ctx).Where("biz_code = ?", bizCode).Delete(&BizDisablement{}).Error; err != nil {
+		return fmt.Errorf("删除停用记录失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package rule
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gitee.com/damengde/runehammer/config"
+)
+
+// ============================================================================
+// 缺失字段语义 - 按 ConverterConfig.NullPolicy 为引用字段的简单条件生成存在性判断
+// ============================================================================
+//
+// 字段不存在或值为nil视为缺失，执行时由 Present("路径") 判断（引擎提供）。
+//
+//	NullPolicyDefault  不生成判断，沿用Grule默认行为（条件求值出错时规则不命中）
+//	NullPolicyFalse    比较生成为 Present(...) && (比较)，缺失时为false
+//	NullPolicyError    不生成判断，由引擎在条件求值出错时返回错误
+//	NullPolicyUnknown  三值逻辑：每个条件分别生成"确定为真"和"确定为假"的表达式，
+//	                   and/or 按Kleene逻辑组合，规则条件取"确定为真"
+//
+// 表达式条件和函数条件原样输出，不做缺失判断。
+
+// fieldPathPattern 字段引用，如 customer.age、Params["amount"]、Result.score
+var fieldPathPattern = regexp.MustCompile(`^[A-Za-z_]\w*(?:\.[A-Za-z_]\w*|\["[^"\]]+"\])+$`)
+
+// mapIndexPattern map下标访问，如 ["amount"]
+var mapIndexPattern = regexp.MustCompile(`\["([^"\]]+)"\]`)
+
+// operandPath 将字段引用转换为点分路径，非字段引用返回false
+func operandPath(expr string) (string, bool) {
+	if !fieldPathPattern.MatchString(expr) {
+		return "", false
+	}
+	return mapIndexPattern.ReplaceAllString(expr, ".$1"), true
+}
+
+// presentExpr 条件引用字段的存在性判断，未引用字段时返回空
+func (c *GRLConverter) presentExpr(cond Condition, defs Definitions) string {
+	var checks []string
+	seen := make(map[string]bool)
+	add := func(operand interface{}) {
+		if _, ok := operand.(string); !ok {
+			return
+		}
+		expr, err := c.convertOperand(operand, defs)
+		if err != nil {
+			return
+		}
+		if path, ok := operandPath(expr); ok && !seen[path] {
+			seen[path] = true
+			checks = append(checks, fmt.Sprintf("Present(%s)", strconv.Quote(path)))
+		}
+	}
+	add(cond.Left)
+	add(cond.Right)
+	return strings.Join(checks, " && ")
+}
+
+// guardNull 缺失字段为false语义下为简单条件添加存在性判断
+func (c *GRLConverter) guardNull(cond Condition, defs Definitions, expr string) string {
+	if c.config.NullPolicy != config.NullPolicyFalse {
+		return expr
+	}
+	if present := c.presentExpr(cond, defs); present != "" {
+		return fmt.Sprintf("%s && (%s)", present, expr)
+	}
+	return expr
+}
+
+// convertKleene 按三值逻辑转换条件
+//
+// 返回值:
+//
+//	string - 条件确定为真的表达式
+//	string - 条件确定为假的表达式
+//	error  - 转换错误
+func (c *GRLConverter) convertKleene(cond Condition, defs Definitions) (string, string, error) {
+	switch cond.Type {
+	case ConditionTypeSimple:
+		expr, err := c.convertSimpleCondition(cond, defs)
+		if err != nil {
+			return "", "", err
+		}
+		present := c.presentExpr(cond, defs)
+		if present == "" {
+			return expr, fmt.Sprintf("!(%s)", expr), nil
+		}
+		return fmt.Sprintf("%s && (%s)", present, expr), fmt.Sprintf("%s && !(%s)", present, expr), nil
+
+	case ConditionTypeComposite:
+		if len(cond.Children) == 0 {
+			return "", "", fmt.Errorf("复合条件必须包含子条件")
+		}
+		if err := c.checkOperator(string(cond.Operator)); err != nil {
+			return "", "", err
+		}
+		var trues, falses []string
+		for _, child := range cond.Children {
+			t, f, err := c.convertKleene(child, defs)
+			if err != nil {
+				return "", "", err
+			}
+			trues = append(trues, fmt.Sprintf("(%s)", t))
+			falses = append(falses, fmt.Sprintf("(%s)", f))
+		}
+		switch cond.Operator {
+		case OpAnd:
+			return strings.Join(trues, " && "), strings.Join(falses, " || "), nil
+		case OpOr:
+			return strings.Join(trues, " || "), strings.Join(falses, " && "), nil
+		default:
+			return "", "", fmt.Errorf("三值逻辑不支持的复合操作符: %s", cond.Operator)
+		}
+
+	default:
+		expr, err := c.convertConditionNode(cond, defs)
+		if err != nil {
+			return "", "", err
+		}
+		return expr, fmt.Sprintf("!(%s)", expr), nil
+	}
+}
@@ -0,0 +1,63 @@
+package rule
+
+import (
+	"testing"
+
+	"gitee.com/damengde/runehammer/config"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// TestNullPolicy 测试缺失字段语义的条件转换
+func TestNullPolicy(t *testing.T) {
+	Convey("缺失字段语义", t, func() {
+		cond := AllOf(
+			Field(`Params["age"]`).Gte(18),
+			Field("customer.level").Ne("gold"),
+		)
+		convert := func(policy config.NullPolicy, cond Condition) string {
+			converter := NewGRLConverter(ConverterConfig{NullPolicy: policy})
+			grl, err := converter.convertCondition(cond, Definitions{})
+			So(err, ShouldBeNil)
+			return grl
+		}
+
+		Convey("默认语义不生成存在性判断", func() {
+			So(convert(config.NullPolicyDefault, cond), ShouldEqual, `(Params["age"] >= 18) && (customer.level != "gold")`)
+			So(convert(config.NullPolicyError, cond), ShouldEqual, convert(config.NullPolicyDefault, cond))
+		})
+
+		Convey("false语义为比较添加存在性判断", func() {
+			So(convert(config.NullPolicyFalse, cond), ShouldEqual,
+				`(Present("Params.age") && (Params["age"] >= 18)) && (Present("customer.level") && (customer.level != "gold"))`)
+		})
+
+		Convey("三值逻辑规则条件取确定为真", func() {
+			So(convert(config.NullPolicyUnknown, cond), ShouldEqual,
+				`(Present("Params.age") && (Params["age"] >= 18)) && (Present("customer.level") && (customer.level != "gold"))`)
+
+			or := AnyOf(Field("order.amount").Gt(100), Field("order.vip").Eq(true))
+			So(convert(config.NullPolicyUnknown, or), ShouldEqual,
+				`(Present("order.amount") && (order.amount > 100)) || (Present("order.vip") && (order.vip == true))`)
+		})
+
+		Convey("字段间比较同时判断两侧", func() {
+			grl := convert(config.NullPolicyFalse, Field("order.amount").Lte("customer.limit"))
+			So(grl, ShouldEqual, `Present("order.amount") && Present("customer.limit") && (order.amount <= customer.limit)`)
+		})
+
+		Convey("表达式条件原样输出", func() {
+			expr := Condition{Type: ConditionTypeExpression, Expression: "true"}
+			So(convert(config.NullPolicyFalse, expr), ShouldEqual, "true")
+			So(convert(config.NullPolicyUnknown, expr), ShouldEqual, "true")
+		})
+
+		Convey("严格模式允许生成的存在性判断", func() {
+			converter := NewGRLConverter(ConverterConfig{StrictMode: true, NullPolicy: config.NullPolicyUnknown})
+			r := NewStandardRule("vip", "VIP")
+			r.Conditions = cond
+			r.Actions = []Action{{Type: ActionTypeAssign, Target: "Result.vip", Value: true}}
+			_, err := converter.ConvertRule(*r, Definitions{})
+			So(err, ShouldBeNil)
+		})
+	})
+}
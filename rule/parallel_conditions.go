@@ -0,0 +1,82 @@
+package rule
+
+import (
+	"strings"
+)
+
+// ============================================================================
+// 并行条件转换 - 与/或复合条件中的多个 Func.Call 函数条件合并为 Func.AllOf / Func.AnyOf 并发求值
+// ============================================================================
+//
+// 启用 ConverterConfig.ParallelConditions 后，复合条件中整体为 Func.Call("名称", 参数...) 的子条件
+// 不少于两个时，这些子条件改写为 Func.Bind 并合并为一个并发求值的条件，排在其余子条件之后：
+//
+//	(Params.amount > 0) && (Func.Call("A", Params.id)) && (Func.Call("B", Params.id))
+//	=> (Params.amount > 0) && Func.AllOf(Func.Bind("A", Params.id), Func.Bind("B", Params.id))
+//
+// 其余子条件（通常开销较低）先按原顺序短路求值，被合并的函数条件须相互独立、无副作用依赖。
+
+// functionCallPrefix 通过调用对象调用自定义函数的前缀
+const functionCallPrefix = "Func.Call("
+
+// parallelizeConditions 合并可并发求值的子条件，子条件已加括号；不满足条件时原样返回
+func parallelizeConditions(conditions []string, operator Operator) []string {
+	var combine string
+	switch operator {
+	case OpAnd:
+		combine = "Func.AllOf"
+	case OpOr:
+		combine = "Func.AnyOf"
+	default:
+		return conditions
+	}
+
+	var rest, bound []string
+	for _, cond := range conditions {
+		if args, ok := functionCallArgs(strings.TrimSuffix(strings.TrimPrefix(cond, "("), ")")); ok {
+			bound = append(bound, "Func.Bind("+args+")")
+		} else {
+			rest = append(rest, cond)
+		}
+	}
+	if len(bound) < 2 {
+		return conditions
+	}
+	return append(rest, combine+"("+strings.Join(bound, ", ")+")")
+}
+
+// functionCallArgs 表达式整体为单个 Func.Call(...) 时返回括号内的参数
+func functionCallArgs(expr string) (string, bool) {
+	expr = strings.TrimSpace(expr)
+	if !strings.HasPrefix(expr, functionCallPrefix) {
+		return "", false
+	}
+
+	depth := 0
+	var quote byte
+	for i := len(functionCallPrefix) - 1; i < len(expr); i++ {
+		ch := expr[i]
+		switch {
+		case quote != 0:
+			if ch == '\\' {
+				i++
+			} else if ch == quote {
+				quote = 0
+			}
+		case ch == '"' || ch == '\'' || ch == '`':
+			quote = ch
+		case ch == '(':
+			depth++
+		case ch == ')':
+			depth--
+			if depth == 0 {
+				// 调用的右括号必须是表达式末尾，否则如 Func.Call("A") > 1 不是单个调用
+				if i != len(expr)-1 {
+					return "", false
+				}
+				return expr[len(functionCallPrefix):i], true
+			}
+		}
+	}
+	return "", false
+}
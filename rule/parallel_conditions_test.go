@@ -0,0 +1,55 @@
+package rule
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// TestParallelConditions 测试并行条件转换
+func TestParallelConditions(t *testing.T) {
+	Convey("并行条件转换", t, func() {
+		function := func(expr string) Condition {
+			return Condition{Type: ConditionTypeFunction, Expression: expr}
+		}
+		newRule := func(op Operator, children ...Condition) StandardRule {
+			return StandardRule{
+				ID: "PARALLEL", Name: "并行条件", Enabled: true,
+				Conditions: Condition{Type: ConditionTypeComposite, Operator: op, Children: children},
+				Actions:    []Action{{Type: ActionTypeAssign, Target: "Result.hit", Value: true}},
+			}
+		}
+		converter := NewGRLConverter(ConverterConfig{ParallelConditions: true})
+
+		Convey("多个函数条件合并为并发求值，其余条件排在前面", func() {
+			grl, err := converter.ConvertToGRL(newRule(OpOr,
+				function(`Func.Call("InBlacklist", Params.id)`),
+				Condition{Type: ConditionTypeSimple, Left: "Params.amount", Operator: OpGreaterThan, Right: 100},
+				function(`Func.Call("HitModel", Params.id, "f(x)")`),
+			))
+			So(err, ShouldBeNil)
+			So(grl, ShouldContainSubstring,
+				`(Params.amount > 100) || Func.AnyOf(Func.Bind("InBlacklist", Params.id), Func.Bind("HitModel", Params.id, "f(x)"))`)
+
+			grl, err = converter.ConvertToGRL(newRule(OpAnd, function(`Func.Call("A")`), function(`Func.Call("B")`)))
+			So(err, ShouldBeNil)
+			So(grl, ShouldContainSubstring, `Func.AllOf(Func.Bind("A"), Func.Bind("B"))`)
+		})
+
+		Convey("不是单个调用或少于两个时保持顺序求值", func() {
+			grl, err := converter.ConvertToGRL(newRule(OpAnd,
+				function(`Func.Call("Score", Params.id) > 600`),
+				function(`Func.Call("A") && Func.Call("B")`),
+				function(`Func.Call("C")`),
+			))
+			So(err, ShouldBeNil)
+			So(grl, ShouldNotContainSubstring, "Func.Bind")
+		})
+
+		Convey("未启用时不改写", func() {
+			grl, err := NewGRLConverter().ConvertToGRL(newRule(OpOr, function(`Func.Call("A")`), function(`Func.Call("B")`)))
+			So(err, ShouldBeNil)
+			So(grl, ShouldContainSubstring, `(Func.Call("A")) || (Func.Call("B"))`)
+		})
+	})
+}
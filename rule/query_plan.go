@@ -0,0 +1,171 @@
+package rule
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ============================================================================
+// 查询计划诊断 - 对规则获取查询执行EXPLAIN，发现全表扫描时提示创建索引
+// ============================================================================
+//
+// 同步和执行时按业务码获取启用规则（biz_code = ? AND enabled = ? ORDER BY version DESC），
+// 规则表通过复合索引 idx_runehammer_rules_fetch(biz_code, enabled, version) 支持该查询，
+// AutoMigrate 会为已有表补建该索引。租户以业务码前缀区分，不需要单独的索引列。
+
+// FetchIndexName 规则获取查询使用的复合索引名
+const FetchIndexName = "idx_runehammer_rules_fetch"
+
+// ErrExplainUnsupported 数据库类型不支持查询计划诊断
+var ErrExplainUnsupported = errors.New("数据库不支持查询计划诊断")
+
+// QueryPlan 规则获取查询的执行计划
+type QueryPlan struct {
+	Query    string   // 诊断的SQL（含占位符）
+	Steps    []string // 执行计划各步骤的描述
+	Index    string   // 规则表使用的索引，为空表示未使用索引
+	FullScan bool     // 是否全表扫描规则表
+}
+
+// QueryPlanMapper 查询计划诊断接口 - 可选扩展，内置GORM映射器已实现
+type QueryPlanMapper interface {
+	// ExplainFetch 诊断按业务码获取规则的查询计划
+	//
+	// 返回值:
+	//   *QueryPlan - 执行计划
+	//   error      - 诊断错误，数据库不支持时返回ErrExplainUnsupported
+	ExplainFetch(ctx context.Context, bizCode string) (*QueryPlan, error)
+}
+
+// fetchQuery 按业务码获取启用规则的查询，获取和诊断使用同一查询
+func fetchQuery(db *gorm.DB, bizCode string) *gorm.DB {
+	return db.Model(&Rule{}).
+		Where("biz_code = ? AND enabled = ?", bizCode, true).
+		Order("version DESC")
+}
+
+// ExplainFetch 诊断按业务码获取规则的查询计划
+func (r *ruleMapperImpl) ExplainFetch(ctx context.Context, bizCode string) (*QueryPlan, error) {
+	return ExplainFetchQuery(ctx, r.db, bizCode)
+}
+
+// ExplainFetchQuery 对按业务码获取规则的查询执行EXPLAIN，支持SQLite和MySQL
+//
+// 参数:
+//
+//	ctx     - 上下文
+//	db      - 数据库连接
+//	bizCode - 诊断使用的业务码，不影响计划时可传任意值
+//
+// 返回值:
+//
+//	*QueryPlan - 执行计划，FullScan 为true时应执行迁移创建 FetchIndexName 索引
+//	error      - 诊断错误，数据库不支持时返回ErrExplainUnsupported
+func ExplainFetchQuery(ctx context.Context, db *gorm.DB, bizCode string) (*QueryPlan, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库连接为空")
+	}
+
+	var explain string
+	dialect := db.Dialector.Name()
+	switch dialect {
+	case "sqlite":
+		explain = "EXPLAIN QUERY PLAN "
+	case "mysql":
+		explain = "EXPLAIN "
+	default:
+		return nil, fmt.Errorf("%s: %w", dialect, ErrExplainUnsupported)
+	}
+
+	stmt := fetchQuery(db.Session(&gorm.Session{DryRun: true}), bizCode).Find(&[]*Rule{}).Statement
+	query := stmt.SQL.String()
+
+	rows, err := db.WithContext(ctx).Raw(explain+query, stmt.Vars...).Rows()
+	if err != nil {
+		return nil, fmt.Errorf("执行查询计划诊断失败: %w", err)
+	}
+	defer rows.Close()
+
+	plan := &QueryPlan{Query: query}
+	table := (&Rule{}).TableName()
+	for rows.Next() {
+		row, err := scanPlanRow(rows)
+		if err != nil {
+			return nil, fmt.Errorf("读取查询计划失败: %w", err)
+		}
+		if dialect == "sqlite" {
+			plan.addSQLiteStep(row["detail"], table)
+		} else {
+			plan.addMySQLStep(row, table)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("读取查询计划失败: %w", err)
+	}
+	return plan, nil
+}
+
+// scanPlanRow 读取一行执行计划，按小写列名返回
+func scanPlanRow(rows *sql.Rows) (map[string]string, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	values := make([]sql.NullString, len(columns))
+	dest := make([]any, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return nil, err
+	}
+	row := make(map[string]string, len(columns))
+	for i, column := range columns {
+		row[strings.ToLower(column)] = values[i].String
+	}
+	return row, nil
+}
+
+// sqliteIndexPattern 匹配SQLite执行计划中使用的索引名
+var sqliteIndexPattern = regexp.MustCompile(`USING (?:COVERING )?INDEX (\w+)`)
+
+// addSQLiteStep 解析SQLite执行计划步骤，如 "SEARCH runehammer_rules USING INDEX idx_x (biz_code=? AND enabled=?)"
+func (p *QueryPlan) addSQLiteStep(detail, table string) {
+	p.Steps = append(p.Steps, detail)
+	fields := strings.Fields(detail)
+	if len(fields) < 2 {
+		return
+	}
+	target := fields[1]
+	if target == "TABLE" && len(fields) > 2 {
+		target = fields[2]
+	}
+	if target != table {
+		return
+	}
+	if m := sqliteIndexPattern.FindStringSubmatch(detail); m != nil {
+		p.Index = m[1]
+	}
+	if fields[0] == "SCAN" {
+		p.FullScan = true
+	}
+}
+
+// addMySQLStep 解析MySQL执行计划行，type为ALL（全表）或index（全索引）时视为全表扫描
+func (p *QueryPlan) addMySQLStep(row map[string]string, table string) {
+	p.Steps = append(p.Steps, fmt.Sprintf("table=%s type=%s key=%s rows=%s extra=%s",
+		row["table"], row["type"], row["key"], row["rows"], row["extra"]))
+	if row["table"] != table {
+		return
+	}
+	p.Index = row["key"]
+	if row["type"] == "ALL" || row["type"] == "index" {
+		p.FullScan = true
+	}
+}
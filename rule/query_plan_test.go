@@ -0,0 +1,62 @@
+package rule
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// TestExplainFetchQuery 测试规则获取查询的执行计划诊断
+func TestExplainFetchQuery(t *testing.T) {
+	Convey("查询计划诊断", t, func() {
+		ctx := context.Background()
+		open := func(name string) *gorm.DB {
+			db, err := gorm.Open(sqlite.Open("file:"+name+"?mode=memory"), &gorm.Config{})
+			So(err, ShouldBeNil)
+			return db
+		}
+
+		Convey("迁移后使用复合索引", func() {
+			db := open("plan_indexed")
+			So(db.AutoMigrate(&Rule{}), ShouldBeNil)
+			So(db.Migrator().HasIndex(&Rule{}, FetchIndexName), ShouldBeTrue)
+
+			plan, err := NewRuleMapper(db).(QueryPlanMapper).ExplainFetch(ctx, "loan")
+			So(err, ShouldBeNil)
+			So(plan.FullScan, ShouldBeFalse)
+			So(plan.Index, ShouldEqual, FetchIndexName)
+			So(plan.Query, ShouldContainSubstring, "biz_code = ?")
+			So(plan.Steps, ShouldNotBeEmpty)
+		})
+
+		Convey("缺少索引时报告全表扫描", func() {
+			db := open("plan_scan")
+			So(db.Exec(`CREATE TABLE runehammer_rules (id INTEGER PRIMARY KEY, biz_code TEXT, name TEXT, grl TEXT,
+				version INTEGER, enabled NUMERIC)`).Error, ShouldBeNil)
+
+			plan, err := ExplainFetchQuery(ctx, db, "loan")
+			So(err, ShouldBeNil)
+			So(plan.FullScan, ShouldBeTrue)
+			So(plan.Index, ShouldBeEmpty)
+		})
+
+		Convey("解析MySQL执行计划", func() {
+			plan := &QueryPlan{}
+			plan.addMySQLStep(map[string]string{"table": "runehammer_rules", "type": "ALL", "rows": "120000"}, "runehammer_rules")
+			So(plan.FullScan, ShouldBeTrue)
+
+			plan = &QueryPlan{}
+			plan.addMySQLStep(map[string]string{"table": "runehammer_rules", "type": "ref", "key": FetchIndexName}, "runehammer_rules")
+			So(plan.FullScan, ShouldBeFalse)
+			So(plan.Index, ShouldEqual, FetchIndexName)
+		})
+
+		Convey("数据库连接为空时返回错误", func() {
+			_, err := ExplainFetchQuery(ctx, nil, "loan")
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
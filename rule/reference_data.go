@@ -0,0 +1,227 @@
+package rule
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// ============================================================================
+// 枚举与参考数据 - 规则以符号名引用登记的取值，编译时解析为字面量并校验
+// ============================================================================
+//
+// 登记枚举 OrderStatus(PENDING, PAID) 和参考数据表 Countries(CN: {risk: 1, currency: "CNY"}) 后，规则中的
+//
+//	Params.status == OrderStatus.PAID      => Params.status == "PAID"
+//	Params.country == Countries.CN         => Params.country == "CN"
+//	Params.risk > Countries.CN.risk        => Params.risk > 1
+//
+// 引用不存在的枚举成员、数据行或列时编译失败，而不是在执行时静默不匹配。
+// 字符串和注释中的内容不解析，未登记的名称保持原样。
+
+// grlReferenceRegex 匹配 名称.成员 及可选的 .列（在已屏蔽注释和字符串的源码上使用）
+var grlReferenceRegex = regexp.MustCompile(`(^|[^\w.])([A-Za-z_]\w*)\.([A-Za-z_]\w*)(?:\.([A-Za-z_]\w*))?`)
+
+// referenceNameRegex 枚举名、成员名、数据行和列名的格式
+var referenceNameRegex = regexp.MustCompile(`^[A-Za-z_]\w*$`)
+
+// reservedReferenceNames 引擎注入的事实对象名，不能用作枚举或参考数据表名
+var reservedReferenceNames = map[string]bool{
+	"Params": true, "Result": true, "result": true, "Config": true, "Errors": true,
+	"DEFUNC": true, "Func": true, SharedFactsObject: true,
+}
+
+// ReferenceData 枚举与参考数据表登记 - 并发安全
+type ReferenceData struct {
+	mu     sync.RWMutex
+	enums  map[string]map[string]bool                   // 枚举名 -> 成员
+	tables map[string]map[string]map[string]interface{} // 表名 -> 行 -> 列 -> 值
+}
+
+// NewReferenceData 创建空的枚举与参考数据登记
+func NewReferenceData() *ReferenceData {
+	return &ReferenceData{
+		enums:  make(map[string]map[string]bool),
+		tables: make(map[string]map[string]map[string]interface{}),
+	}
+}
+
+// AddEnum 登记枚举，成员名即其取值，重复登记时替换
+//
+// 参数:
+//
+//	name   - 枚举名，规则中以 name.成员 引用
+//	values - 成员，须为标识符且不重复
+//
+// 返回值:
+//
+//	error - 名称无效、与参考数据表重名或成员无效
+func (d *ReferenceData) AddEnum(name string, values ...string) error {
+	if err := checkReferenceName(name); err != nil {
+		return err
+	}
+	if len(values) == 0 {
+		return fmt.Errorf("枚举 %s 至少需要一个成员", name)
+	}
+	members := make(map[string]bool, len(values))
+	for _, value := range values {
+		if !referenceNameRegex.MatchString(value) {
+			return fmt.Errorf("枚举 %s 的成员 %q 不是有效的标识符", name, value)
+		}
+		if members[value] {
+			return fmt.Errorf("枚举 %s 的成员 %s 重复", name, value)
+		}
+		members[value] = true
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.tables[name]; ok {
+		return fmt.Errorf("名称 %s 已登记为参考数据表", name)
+	}
+	d.enums[name] = members
+	return nil
+}
+
+// AddTable 登记参考数据表，重复登记时替换
+//
+// 参数:
+//
+//	name - 表名，规则中以 name.行 引用行键、以 name.行.列 引用单元格的值
+//	rows - 行键 -> 列名 -> 值，值须为字符串、布尔或数值
+//
+// 返回值:
+//
+//	error - 名称无效、与枚举重名、行列名无效或值类型不支持
+func (d *ReferenceData) AddTable(name string, rows map[string]map[string]interface{}) error {
+	if err := checkReferenceName(name); err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return fmt.Errorf("参考数据表 %s 至少需要一行", name)
+	}
+	table := make(map[string]map[string]interface{}, len(rows))
+	for key, columns := range rows {
+		if !referenceNameRegex.MatchString(key) {
+			return fmt.Errorf("参考数据表 %s 的行 %q 不是有效的标识符", name, key)
+		}
+		row := make(map[string]interface{}, len(columns))
+		for column, value := range columns {
+			if !referenceNameRegex.MatchString(column) {
+				return fmt.Errorf("参考数据表 %s 的列 %q 不是有效的标识符", name, column)
+			}
+			literal, ok := referenceLiteral(value)
+			if !ok {
+				return fmt.Errorf("参考数据表 %s 的 %s.%s 类型 %T 不支持，只能为字符串、布尔或数值", name, key, column, value)
+			}
+			row[column] = literal
+		}
+		table[key] = row
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.enums[name]; ok {
+		return fmt.Errorf("名称 %s 已登记为枚举", name)
+	}
+	d.tables[name] = table
+	return nil
+}
+
+// Empty 是否没有登记任何枚举或参考数据表
+func (d *ReferenceData) Empty() bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return len(d.enums) == 0 && len(d.tables) == 0
+}
+
+// Resolve 将GRL中对枚举和参考数据表的引用解析为字面量
+//
+// 参数:
+//
+//	grl - GRL源码
+//
+// 返回值:
+//
+//	string - 解析后的GRL，没有引用时原样返回
+//	error  - 引用了不存在的枚举成员、数据行或列
+func (d *ReferenceData) Resolve(grl string) (string, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if len(d.enums) == 0 && len(d.tables) == 0 {
+		return grl, nil
+	}
+
+	masked := maskGRL(grl)
+	var sb strings.Builder
+	pos := 0
+	for _, m := range grlReferenceRegex.FindAllStringSubmatchIndex(masked, -1) {
+		name, member := masked[m[4]:m[5]], masked[m[6]:m[7]]
+		end := m[7]
+		var literal string
+
+		if members, ok := d.enums[name]; ok {
+			if !members[member] {
+				return "", fmt.Errorf("枚举 %s 没有成员 %s，可用成员: %s", name, member, strings.Join(sortedKeys(members), ", "))
+			}
+			literal = renderConstant(member)
+		} else if table, ok := d.tables[name]; ok {
+			row, ok := table[member]
+			if !ok {
+				return "", fmt.Errorf("参考数据表 %s 没有行 %s", name, member)
+			}
+			literal = renderConstant(member)
+			// 紧跟括号时为对行键的方法调用，不作为列引用
+			if m[8] >= 0 && !strings.HasPrefix(strings.TrimLeft(masked[m[9]:], " \t"), "(") {
+				column := masked[m[8]:m[9]]
+				value, ok := row[column]
+				if !ok {
+					return "", fmt.Errorf("参考数据表 %s 的行 %s 没有列 %s", name, member, column)
+				}
+				literal, end = renderConstant(value), m[9]
+			}
+		} else {
+			continue
+		}
+
+		sb.WriteString(grl[pos:m[4]])
+		sb.WriteString(literal)
+		pos = end
+	}
+	if pos == 0 {
+		return grl, nil
+	}
+	sb.WriteString(grl[pos:])
+	return sb.String(), nil
+}
+
+// checkReferenceName 校验枚举或参考数据表名
+func checkReferenceName(name string) error {
+	if !referenceNameRegex.MatchString(name) {
+		return fmt.Errorf("名称 %q 不是有效的标识符", name)
+	}
+	if reservedReferenceNames[name] {
+		return fmt.Errorf("名称 %s 与引擎注入的对象重名", name)
+	}
+	return nil
+}
+
+// referenceLiteral 将参考数据的值转换为GRL字面量对应的类型
+func referenceLiteral(value interface{}) (interface{}, bool) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), true
+	case reflect.Bool:
+		return v.Bool(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return int64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return nil, false
+}
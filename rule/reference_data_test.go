@@ -0,0 +1,74 @@
+package rule
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// TestReferenceData 测试枚举与参考数据解析
+func TestReferenceData(t *testing.T) {
+	Convey("枚举与参考数据", t, func() {
+		data := NewReferenceData()
+		So(data.Empty(), ShouldBeTrue)
+		So(data.AddEnum("OrderStatus", "PENDING", "PAID"), ShouldBeNil)
+		So(data.AddTable("Countries", map[string]map[string]interface{}{
+			"CN": {"currency": "CNY", "risk": 1, "rate": float32(0.5), "open": true},
+			"US": {"currency": "USD", "risk": uint8(2)},
+		}), ShouldBeNil)
+		So(data.Empty(), ShouldBeFalse)
+
+		Convey("引用解析为字面量", func() {
+			grl, err := data.Resolve(`rule Paid "已支付 OrderStatus.PAID" {
+				when Params.status == OrderStatus.PAID && Params.country == Countries.CN && Params.risk <= Countries.US.risk
+				then Result["rate"] = Countries.CN.rate; Result["open"] = Countries.CN.open; // OrderStatus.UNKNOWN
+					Result["cur"] = Countries.CN.currency; Retract("Paid"); }`)
+			So(err, ShouldBeNil)
+			So(grl, ShouldContainSubstring, `"已支付 OrderStatus.PAID"`)
+			So(grl, ShouldContainSubstring, `Params.status == "PAID" && Params.country == "CN" && Params.risk <= 2`)
+			So(grl, ShouldContainSubstring, `Result["rate"] = 0.5; Result["open"] = true; // OrderStatus.UNKNOWN`)
+			So(grl, ShouldContainSubstring, `Result["cur"] = "CNY"`)
+		})
+
+		Convey("未登记的名称、字段访问和行键方法调用保持原样", func() {
+			source := `when Params.OrderStatus.PAID == Other.PAID && Countries.CN.Len() > 1 then`
+			grl, err := data.Resolve(source)
+			So(err, ShouldBeNil)
+			So(grl, ShouldEqual, `when Params.OrderStatus.PAID == Other.PAID && "CN".Len() > 1 then`)
+
+			grl, err = NewReferenceData().Resolve(source)
+			So(err, ShouldBeNil)
+			So(grl, ShouldEqual, source)
+		})
+
+		Convey("引用不存在的成员、行或列时报错", func() {
+			_, err := data.Resolve(`when Params.status == OrderStatus.PAYED then`)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "可用成员: PAID, PENDING")
+
+			_, err = data.Resolve(`when Params.country == Countries.JP then`)
+			So(err, ShouldNotBeNil)
+
+			_, err = data.Resolve(`when Params.risk > Countries.CN.score then`)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "没有列 score")
+		})
+
+		Convey("登记时校验名称和取值", func() {
+			So(data.AddEnum("Params", "A"), ShouldNotBeNil)
+			So(data.AddEnum("Bad-Name", "A"), ShouldNotBeNil)
+			So(data.AddEnum("Empty"), ShouldNotBeNil)
+			So(data.AddEnum("Status", "A", "A"), ShouldNotBeNil)
+			So(data.AddEnum("Status", "in progress"), ShouldNotBeNil)
+			So(data.AddEnum("Countries", "CN"), ShouldNotBeNil)
+			So(data.AddTable("OrderStatus", map[string]map[string]interface{}{"A": {}}), ShouldNotBeNil)
+			So(data.AddTable("Tiers", map[string]map[string]interface{}{"GOLD": {"limit": []int{1}}}), ShouldNotBeNil)
+			So(data.AddTable("Tiers", nil), ShouldNotBeNil)
+
+			So(data.AddEnum("OrderStatus", "PAID", "PAYED"), ShouldBeNil)
+			grl, err := data.Resolve(`when Params.status == OrderStatus.PAYED then`)
+			So(err, ShouldBeNil)
+			So(grl, ShouldContainSubstring, `"PAYED"`)
+		})
+	})
+}
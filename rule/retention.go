@@ -0,0 +1,115 @@
+package rule
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ============================================================================
+// 数据保留 - 清理规则历史版本和审计类记录，避免持久化数据无限增长
+// ============================================================================
+
+// RuleRetentionMapper 规则版本清理数据访问接口 - 可选扩展，用于删除超出保留数的规则历史版本
+type RuleRetentionMapper interface {
+	// PurgeRuleVersions 删除一批超出保留数的规则历史版本
+	//
+	// 同一业务码下同名规则按版本号保留最新的keep个版本，只删除禁用的历史版本，启用的规则不会被删除。
+	//
+	// 参数:
+	//   ctx       - 上下文，用于超时控制和取消操作
+	//   keep      - 每条规则保留的版本数
+	//   batchSize - 本批最多删除的记录数
+	//
+	// 返回值:
+	//   int64 - 本批删除的记录数，小于batchSize表示已清理完毕
+	//   error - 删除错误
+	PurgeRuleVersions(ctx context.Context, keep, batchSize int) (int64, error)
+}
+
+// RetentionTarget 按时间清理的数据保留目标 - 如审计日志、执行记录等表
+type RetentionTarget interface {
+	// Name 目标名称，用于统计和日志
+	Name() string
+
+	// PurgeBefore 删除一批早于cutoff的记录
+	//
+	// 返回值:
+	//   int64 - 本批删除的记录数，小于batchSize表示已清理完毕
+	//   error - 删除错误
+	PurgeBefore(ctx context.Context, cutoff time.Time, batchSize int) (int64, error)
+}
+
+// PurgeRuleVersions 删除一批超出保留数的禁用规则历史版本
+func (r *ruleMapperImpl) PurgeRuleVersions(ctx context.Context, keep, batchSize int) (int64, error) {
+	if keep <= 0 || batchSize <= 0 {
+		return 0, fmt.Errorf("保留版本数和批大小必须大于0")
+	}
+
+	// 先查出待删除的ID再按ID删除，兼容不支持在删除语句中引用同表子查询的数据库
+	var ids []uint64
+	err := r.db.WithContext(ctx).Model(&Rule{}).
+		Where("enabled = ?", false).
+		Where("(SELECT COUNT(*) FROM runehammer_rules AS newer WHERE newer.biz_code = runehammer_rules.biz_code AND newer.name = runehammer_rules.name AND newer.version > runehammer_rules.version) >= ?", keep).
+		Order("id ASC").
+		Limit(batchSize).
+		Pluck("id", &ids).Error
+	if err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	result := r.db.WithContext(ctx).Where("id IN ?", ids).Delete(&Rule{})
+	return result.RowsAffected, result.Error
+}
+
+// tableRetention 按时间列清理数据表的保留目标
+type tableRetention struct {
+	db     *gorm.DB
+	table  string
+	column string
+}
+
+// NewTableRetention 创建按时间列清理数据表的保留目标
+//
+// 参数:
+//
+//	db     - GORM数据库连接实例
+//	table  - 表名，需包含主键id列
+//	column - 时间列名，早于保留期限的记录被删除
+//
+// 返回值:
+//
+//	RetentionTarget - 以表名为名称的保留目标
+func NewTableRetention(db *gorm.DB, table, column string) RetentionTarget {
+	return &tableRetention{db: db, table: table, column: column}
+}
+
+// Name 实现RetentionTarget
+func (t *tableRetention) Name() string {
+	return t.table
+}
+
+// PurgeBefore 实现RetentionTarget
+func (t *tableRetention) PurgeBefore(ctx context.Context, cutoff time.Time, batchSize int) (int64, error) {
+	var ids []uint64
+	err := t.db.WithContext(ctx).Table(t.table).
+		Where(clause.Lt{Column: clause.Column{Name: t.column}, Value: cutoff}).
+		Order("id ASC").
+		Limit(batchSize).
+		Pluck("id", &ids).Error
+	if err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	result := t.db.WithContext(ctx).Table(t.table).Where("id IN ?", ids).Delete(map[string]any{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,260 @@
+package rule
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ============================================================================
+// 流式规则构建器 - 以代码方式构建并校验 StandardRule，避免手写JSON出错
+// ============================================================================
+//
+// 示例:
+//
+//	r, err := rule.When(rule.Field("Params.age").Gte(18)).
+//		And(rule.Field("Params.income").Gt(50000)).
+//		Then(rule.Assign("Result.approved", true)).
+//		ID("approve_loan").
+//		Name("贷款审批").
+//		Build()
+
+// FieldRef 字段引用 - 通过比较方法生成简单条件
+type FieldRef struct {
+	name string
+}
+
+// Field 创建字段引用
+func Field(name string) FieldRef {
+	return FieldRef{name: name}
+}
+
+// compare 生成简单条件
+func (f FieldRef) compare(op Operator, value interface{}) Condition {
+	return Condition{
+		Type:     ConditionTypeSimple,
+		Left:     f.name,
+		Operator: op,
+		Right:    value,
+	}
+}
+
+// Eq 等于
+func (f FieldRef) Eq(value interface{}) Condition { return f.compare(OpEqual, value) }
+
+// Ne 不等于
+func (f FieldRef) Ne(value interface{}) Condition { return f.compare(OpNotEqual, value) }
+
+// Gt 大于
+func (f FieldRef) Gt(value interface{}) Condition { return f.compare(OpGreaterThan, value) }
+
+// Gte 大于等于
+func (f FieldRef) Gte(value interface{}) Condition { return f.compare(OpGreaterThanOrEqual, value) }
+
+// Lt 小于
+func (f FieldRef) Lt(value interface{}) Condition { return f.compare(OpLessThan, value) }
+
+// Lte 小于等于
+func (f FieldRef) Lte(value interface{}) Condition { return f.compare(OpLessThanOrEqual, value) }
+
+// In 包含于给定集合
+func (f FieldRef) In(values ...interface{}) Condition { return f.compare(OpIn, values) }
+
+// Contains 字段包含给定值
+func (f FieldRef) Contains(value interface{}) Condition { return f.compare(OpContains, value) }
+
+// Matches 字段匹配正则表达式
+func (f FieldRef) Matches(pattern string) Condition { return f.compare(OpMatches, pattern) }
+
+// ApproxEq 字段与给定值之差不超过容差，epsilon不大于0时金额按最小货币单位的一半、其他按 DefaultEpsilon
+func (f FieldRef) ApproxEq(value interface{}, epsilon float64) Condition {
+	return f.compare(OpApproxEq, Approx(value, epsilon))
+}
+
+// Between 字段位于闭区间 [min, max]
+func (f FieldRef) Between(min, max interface{}) Condition {
+	return f.compare(OpBetween, []interface{}{min, max})
+}
+
+// AllOf 所有子条件同时成立
+func AllOf(conds ...Condition) Condition {
+	return Condition{Type: ConditionTypeComposite, Operator: OpAnd, Children: conds}
+}
+
+// AnyOf 任一子条件成立
+func AnyOf(conds ...Condition) Condition {
+	return Condition{Type: ConditionTypeComposite, Operator: OpOr, Children: conds}
+}
+
+// Expr 表达式条件
+func Expr(expression string) Condition {
+	return Condition{Type: ConditionTypeExpression, Expression: expression}
+}
+
+// Assign 赋值动作: target = value
+func Assign(target string, value interface{}) Action {
+	return Action{Type: ActionTypeAssign, Target: target, Value: value}
+}
+
+// Calculate 计算动作: target = expression
+func Calculate(target, expression string) Action {
+	return Action{Type: ActionTypeCalculate, Target: target, Expression: expression}
+}
+
+// Invoke 调用动作
+func Invoke(target string, params map[string]interface{}) Action {
+	return Action{Type: ActionTypeInvoke, Target: target, Parameters: params}
+}
+
+// RuleBuilder 流式规则构建器
+type RuleBuilder struct {
+	rule *StandardRule
+}
+
+// When 以初始条件开始构建规则
+func When(cond Condition) *RuleBuilder {
+	r := NewStandardRule("", "")
+	r.Conditions = cond
+	return &RuleBuilder{rule: r}
+}
+
+// And 追加与条件 - 已有条件为与组合时直接追加，否则与已有条件整体组合
+func (b *RuleBuilder) And(cond Condition) *RuleBuilder {
+	b.combine(OpAnd, cond)
+	return b
+}
+
+// Or 追加或条件 - 按调用顺序左结合: When(a).And(b).Or(c) 等价于 (a && b) || c
+func (b *RuleBuilder) Or(cond Condition) *RuleBuilder {
+	b.combine(OpOr, cond)
+	return b
+}
+
+// combine 按操作符组合条件
+func (b *RuleBuilder) combine(op Operator, cond Condition) {
+	current := b.rule.Conditions
+	if current.Type == ConditionTypeComposite && current.Operator == op {
+		b.rule.Conditions.Children = append(b.rule.Conditions.Children, cond)
+		return
+	}
+	b.rule.Conditions = Condition{
+		Type:     ConditionTypeComposite,
+		Operator: op,
+		Children: []Condition{current, cond},
+	}
+}
+
+// Then 追加动作
+func (b *RuleBuilder) Then(actions ...Action) *RuleBuilder {
+	b.rule.Actions = append(b.rule.Actions, actions...)
+	return b
+}
+
+// ID 设置规则唯一标识
+func (b *RuleBuilder) ID(id string) *RuleBuilder {
+	b.rule.ID = id
+	return b
+}
+
+// Name 设置规则名称
+func (b *RuleBuilder) Name(name string) *RuleBuilder {
+	b.rule.Name = name
+	return b
+}
+
+// Description 设置规则描述
+func (b *RuleBuilder) Description(description string) *RuleBuilder {
+	b.rule.Description = description
+	return b
+}
+
+// Priority 设置优先级 (salience)
+func (b *RuleBuilder) Priority(priority int) *RuleBuilder {
+	b.rule.Priority = priority
+	return b
+}
+
+// Tags 追加标签
+func (b *RuleBuilder) Tags(tags ...string) *RuleBuilder {
+	b.rule.Tags = append(b.rule.Tags, tags...)
+	return b
+}
+
+// Disabled 标记规则为禁用
+func (b *RuleBuilder) Disabled() *RuleBuilder {
+	b.rule.Enabled = false
+	return b
+}
+
+// Build 校验并返回规则定义
+//
+// 校验内容与 StandardRule.Validate 一致，另外检查字段名非空及 between 的区间值；
+// 校验失败时返回汇总所有问题的错误。每次调用返回独立副本，构建器可继续复用。
+func (b *RuleBuilder) Build() (*StandardRule, error) {
+	errs := b.rule.Validate()
+	errs = append(errs, validateBuiltCondition(b.rule.Conditions)...)
+	if len(errs) > 0 {
+		msgs := make([]string, 0, len(errs))
+		for _, e := range errs {
+			msgs = append(msgs, e.Field+": "+e.Message)
+		}
+		return nil, fmt.Errorf("规则定义校验失败: %s", strings.Join(msgs, "; "))
+	}
+
+	built := *b.rule
+	built.Tags = append([]string{}, b.rule.Tags...)
+	built.Actions = append([]Action{}, b.rule.Actions...)
+	built.Conditions = copyCondition(b.rule.Conditions)
+	return &built, nil
+}
+
+// MustBuild 校验并返回规则定义，校验失败时panic - 适用于静态定义的规则
+func (b *RuleBuilder) MustBuild() *StandardRule {
+	r, err := b.Build()
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// validateBuiltCondition 检查 Validate 未覆盖的简单条件问题
+func validateBuiltCondition(cond Condition) []ValidationError {
+	var errors []ValidationError
+
+	switch cond.Type {
+	case ConditionTypeSimple:
+		if left, ok := cond.Left.(string); ok && left == "" {
+			errors = append(errors, ValidationError{
+				Field:   "conditions.left",
+				Message: "简单条件的字段名不能为空",
+			})
+		}
+		if cond.Operator == OpBetween {
+			if values, ok := cond.Right.([]interface{}); !ok || len(values) != 2 {
+				errors = append(errors, ValidationError{
+					Field:   "conditions.right",
+					Message: "between操作符需要两个值",
+				})
+			}
+		}
+
+	case ConditionTypeComposite:
+		for _, child := range cond.Children {
+			errors = append(errors, validateBuiltCondition(child)...)
+		}
+	}
+
+	return errors
+}
+
+// copyCondition 深拷贝条件树
+func copyCondition(cond Condition) Condition {
+	if cond.Children == nil {
+		return cond
+	}
+	children := make([]Condition, len(cond.Children))
+	for i, child := range cond.Children {
+		children[i] = copyCondition(child)
+	}
+	cond.Children = children
+	return cond
+}
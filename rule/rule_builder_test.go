@@ -0,0 +1,82 @@
+package rule
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// TestRuleBuilder 测试流式规则构建器
+func TestRuleBuilder(t *testing.T) {
+	Convey("流式规则构建器", t, func() {
+
+		Convey("构建与条件并转换为GRL", func() {
+			r, err := When(Field("customer.age").Gte(18)).
+				And(Field("customer.income").Gt(50000)).
+				Then(Assign("Result.approved", true)).
+				ID("approve").
+				Name("贷款审批").
+				Priority(80).
+				Tags("loan").
+				Build()
+			So(err, ShouldBeNil)
+			So(r.Priority, ShouldEqual, 80)
+			So(r.Enabled, ShouldBeTrue)
+			So(r.Tags, ShouldResemble, []string{"loan"})
+			So(r.Conditions.Type, ShouldEqual, ConditionTypeComposite)
+			So(r.Conditions.Operator, ShouldEqual, OpAnd)
+			So(r.Conditions.Children, ShouldHaveLength, 2)
+
+			grl, err := NewGRLConverter(ConverterConfig{StrictMode: true}).ConvertToGRL(*r)
+			So(err, ShouldBeNil)
+			So(grl, ShouldContainSubstring, "(customer.age >= 18) && (customer.income > 50000)")
+			So(grl, ShouldContainSubstring, `Result["approved"] = true`)
+		})
+
+		Convey("与或按调用顺序左结合", func() {
+			r, err := When(Field("order.amount").Gt(100)).
+				And(Field("order.vip").Eq(true)).
+				Or(Field("order.channel").In("internal", "staff")).
+				Then(Calculate("Result.discount", "order.amount * 0.1")).
+				ID("discount").
+				Name("折扣").
+				Build()
+			So(err, ShouldBeNil)
+			So(r.Conditions.Operator, ShouldEqual, OpOr)
+			So(r.Conditions.Children[0].Operator, ShouldEqual, OpAnd)
+			So(r.Conditions.Children[1].Right, ShouldResemble, []interface{}{"internal", "staff"})
+		})
+
+		Convey("组合条件辅助函数", func() {
+			cond := AnyOf(Field("a.x").Between(1, 10), AllOf(Field("a.y").Ne("z"), Expr("a.z > 0")))
+			So(cond.Operator, ShouldEqual, OpOr)
+			So(cond.Children[0].Right, ShouldResemble, []interface{}{1, 10})
+			So(cond.Children[1].Children[1].Type, ShouldEqual, ConditionTypeExpression)
+		})
+
+		Convey("校验失败时汇总错误", func() {
+			_, err := When(Field("").Eq(1)).Build()
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "规则ID不能为空")
+			So(err.Error(), ShouldContainSubstring, "规则名称不能为空")
+			So(err.Error(), ShouldContainSubstring, "规则必须包含至少一个动作")
+			So(err.Error(), ShouldContainSubstring, "简单条件的字段名不能为空")
+
+			So(func() { When(Field("a.b").Eq(1)).MustBuild() }, ShouldPanic)
+		})
+
+		Convey("构建结果与构建器相互独立", func() {
+			b := When(Field("a.b").Eq(1)).Then(Assign("Result.x", 1)).ID("R1").Name("R1")
+			first, err := b.Build()
+			So(err, ShouldBeNil)
+
+			b.And(Field("a.c").Eq(2)).Then(Invoke("Log", nil))
+			second, err := b.Build()
+			So(err, ShouldBeNil)
+			So(first.Conditions.Type, ShouldEqual, ConditionTypeSimple)
+			So(first.Actions, ShouldHaveLength, 1)
+			So(second.Conditions.Children, ShouldHaveLength, 2)
+			So(second.Actions, ShouldHaveLength, 2)
+		})
+	})
+}
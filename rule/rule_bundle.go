@@ -0,0 +1,244 @@
+package rule
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ============================================================================
+// 规则包 - 跨环境迁移规则集，附带依赖清单
+// ============================================================================
+
+// BundleFormatVersion 规则包格式版本
+const BundleFormatVersion = "1.0"
+
+// RuleBundle 规则包 - 导出的规则集合及其依赖清单
+type RuleBundle struct {
+	FormatVersion string         `json:"formatVersion" yaml:"formatVersion"` // 规则包格式版本
+	BizCode       string         `json:"bizCode" yaml:"bizCode"`             // 业务码
+	ExportedAt    time.Time      `json:"exportedAt" yaml:"exportedAt"`       // 导出时间
+	Manifest      BundleManifest `json:"manifest" yaml:"manifest"`           // 依赖清单
+	Rules         []Rule         `json:"rules" yaml:"rules"`                 // 规则列表
+	Tests         []RuleTestCase `json:"tests,omitempty" yaml:"tests"`       // 测试用例，导入前可通过RunBundleTests校验
+}
+
+// BundleManifest 依赖清单 - 描述规则集运行所需的外部能力
+type BundleManifest struct {
+	RequiredFunctions []string `json:"requiredFunctions" yaml:"requiredFunctions"` // 需要的函数
+	RequiredLookups   []string `json:"requiredLookups" yaml:"requiredLookups"`     // 需要的查找对象（自定义对象）
+	InputFields       []string `json:"inputFields" yaml:"inputFields"`             // 引用的输入字段，如 Params.Customer.Age
+}
+
+// BundleRequirementError 依赖不满足错误 - 列出目标引擎缺失的能力
+type BundleRequirementError struct {
+	BizCode          string   // 业务码
+	MissingFunctions []string // 缺失的函数
+	MissingLookups   []string // 缺失的查找对象
+	MissingFields    []string // 输入类型中缺失的输入字段
+}
+
+// Error 实现error接口
+func (e *BundleRequirementError) Error() string {
+	var parts []string
+	if len(e.MissingFunctions) > 0 {
+		parts = append(parts, fmt.Sprintf("缺失函数: %s", strings.Join(e.MissingFunctions, ", ")))
+	}
+	if len(e.MissingLookups) > 0 {
+		parts = append(parts, fmt.Sprintf("缺失查找对象: %s", strings.Join(e.MissingLookups, ", ")))
+	}
+	if len(e.MissingFields) > 0 {
+		parts = append(parts, fmt.Sprintf("缺失输入字段: %s", strings.Join(e.MissingFields, ", ")))
+	}
+	return fmt.Sprintf("规则包 %s 依赖不满足: %s", e.BizCode, strings.Join(parts, "; "))
+}
+
+// NewRuleBundle 创建规则包 - 根据规则GRL自动生成依赖清单
+//
+// 参数:
+//
+//	bizCode - 业务码
+//	rules   - 规则列表
+//
+// 返回值:
+//
+//	*RuleBundle - 规则包
+func NewRuleBundle(bizCode string, rules []*Rule) *RuleBundle {
+	bundle := &RuleBundle{
+		FormatVersion: BundleFormatVersion,
+		BizCode:       bizCode,
+		ExportedAt:    time.Now(),
+		Rules:         make([]Rule, 0, len(rules)),
+	}
+
+	var grls []string
+	for _, r := range rules {
+		if r == nil {
+			continue
+		}
+		bundle.Rules = append(bundle.Rules, *r)
+		grls = append(grls, r.GRL)
+	}
+
+	bundle.Manifest = AnalyzeGRLRequirements(grls...)
+	return bundle
+}
+
+// ToJSON 转换为JSON字符串
+func (b *RuleBundle) ToJSON() (string, error) {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// FromJSON 从JSON字符串解析
+func (b *RuleBundle) FromJSON(data string) error {
+	return json.Unmarshal([]byte(data), b)
+}
+
+// Check 检查依赖是否满足
+//
+// 参数:
+//
+//	hasFunction - 判断目标引擎是否提供指定函数
+//	hasLookup   - 判断目标引擎是否提供指定查找对象
+//
+// 返回值:
+//
+//	error - 依赖不满足时返回*BundleRequirementError
+func (b *RuleBundle) Check(hasFunction, hasLookup func(name string) bool) error {
+	reqErr := &BundleRequirementError{BizCode: b.BizCode}
+
+	for _, fn := range b.Manifest.RequiredFunctions {
+		if hasFunction == nil || !hasFunction(fn) {
+			reqErr.MissingFunctions = append(reqErr.MissingFunctions, fn)
+		}
+	}
+	for _, lookup := range b.Manifest.RequiredLookups {
+		if hasLookup == nil || !hasLookup(lookup) {
+			reqErr.MissingLookups = append(reqErr.MissingLookups, lookup)
+		}
+	}
+
+	if len(reqErr.MissingFunctions) > 0 || len(reqErr.MissingLookups) > 0 {
+		return reqErr
+	}
+	return nil
+}
+
+// CheckInputFields 检查输入是否提供清单中的输入字段
+//
+// 参数:
+//
+//	hasField - 判断输入是否提供指定字段，参数为去掉 Params. 前缀的字段路径（如 Customer.Age）
+//
+// 返回值:
+//
+//	error - 存在缺失字段时返回*BundleRequirementError
+func (b *RuleBundle) CheckInputFields(hasField func(path string) bool) error {
+	reqErr := &BundleRequirementError{BizCode: b.BizCode}
+	for _, field := range b.Manifest.InputFields {
+		path := strings.TrimPrefix(field, "Params.")
+		if hasField == nil || !hasField(path) {
+			reqErr.MissingFields = append(reqErr.MissingFields, field)
+		}
+	}
+
+	if len(reqErr.MissingFields) > 0 {
+		return reqErr
+	}
+	return nil
+}
+
+// ============================================================================
+// GRL依赖分析
+// ============================================================================
+
+var (
+	grlStringLiteralRegex = regexp.MustCompile(`"(?:[^"\\]|\\.)*"`)
+	grlLineCommentRegex   = regexp.MustCompile(`//[^\n]*`)
+	grlFunctionCallRegex  = regexp.MustCompile(`(^|[^\w.])([A-Za-z_]\w*)\s*\(`)
+	grlMethodCallRegex    = regexp.MustCompile(`(^|[^\w.])([A-Za-z_]\w*)\.([A-Za-z_]\w*)\s*\(`)
+	grlInputFieldRegex    = regexp.MustCompile(`\bParams((?:\.[A-Za-z_]\w*)+)`)
+)
+
+// grlKeywords GRL关键字，不作为函数统计
+var grlKeywords = map[string]bool{
+	"rule": true, "when": true, "then": true, "salience": true,
+	"true": true, "false": true, "nil": true, "null": true,
+}
+
+// inputFactNames 输入事实名称，方法调用的接收者为这些名称时不视为查找对象
+var inputFactNames = map[string]bool{
+	"Params": true,
+	"Result": true,
+	"result": true,
+}
+
+// AnalyzeGRLRequirements 分析GRL中引用的函数、查找对象和输入字段
+//
+// 参数:
+//
+//	grls - GRL规则内容
+//
+// 返回值:
+//
+//	BundleManifest - 依赖清单（各项已去重排序）
+func AnalyzeGRLRequirements(grls ...string) BundleManifest {
+	functions := make(map[string]bool)
+	lookups := make(map[string]bool)
+	fields := make(map[string]bool)
+
+	for _, grl := range grls {
+		// 去除注释和字符串字面量，避免误判
+		source := grlLineCommentRegex.ReplaceAllString(grl, "")
+		source = grlStringLiteralRegex.ReplaceAllString(source, `""`)
+
+		for _, m := range grlFunctionCallRegex.FindAllStringSubmatch(source, -1) {
+			if !grlKeywords[m[2]] {
+				functions[m[2]] = true
+			}
+		}
+
+		for _, m := range grlMethodCallRegex.FindAllStringSubmatch(source, -1) {
+			if !inputFactNames[m[2]] {
+				lookups[m[2]] = true
+			}
+		}
+
+		for _, m := range grlInputFieldRegex.FindAllStringSubmatchIndex(source, -1) {
+			path := source[m[0]:m[1]]
+			// 字段后紧跟括号说明是方法调用，只保留接收者路径
+			if rest := strings.TrimLeft(source[m[1]:], " \t"); strings.HasPrefix(rest, "(") {
+				if idx := strings.LastIndex(path, "."); idx > 0 {
+					path = path[:idx]
+				}
+				if path == "Params" {
+					continue
+				}
+			}
+			fields[path] = true
+		}
+	}
+
+	return BundleManifest{
+		RequiredFunctions: sortedKeys(functions),
+		RequiredLookups:   sortedKeys(lookups),
+		InputFields:       sortedKeys(fields),
+	}
+}
+
+// sortedKeys 返回排序后的键列表
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
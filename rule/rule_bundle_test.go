@@ -0,0 +1,91 @@
+package rule
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// TestRuleBundle 测试规则包与依赖清单
+func TestRuleBundle(t *testing.T) {
+	Convey("规则包测试", t, func() {
+		grl := `rule R1 "检查" salience 10 {
+    when
+        Params.Age >= 18 && CreditCheck(Params.Id) && Blacklist.Contains(Params.Name)
+    then
+        Result["msg"] = "Foo(ignored)";
+        Retract("R1");
+}`
+		rules := []*Rule{
+			{BizCode: "biz", Name: "R1", GRL: grl, Enabled: true},
+			nil,
+		}
+
+		Convey("自动生成依赖清单", func() {
+			bundle := NewRuleBundle("biz", rules)
+			So(bundle.FormatVersion, ShouldEqual, BundleFormatVersion)
+			So(bundle.Rules, ShouldHaveLength, 1)
+			So(bundle.Manifest.RequiredFunctions, ShouldResemble, []string{"CreditCheck", "Retract"})
+			So(bundle.Manifest.RequiredLookups, ShouldResemble, []string{"Blacklist"})
+			So(bundle.Manifest.InputFields, ShouldResemble, []string{"Params.Age", "Params.Id", "Params.Name"})
+		})
+
+		Convey("JSON序列化往返", func() {
+			bundle := NewRuleBundle("biz", rules)
+			data, err := bundle.ToJSON()
+			So(err, ShouldBeNil)
+
+			parsed := &RuleBundle{}
+			So(parsed.FromJSON(data), ShouldBeNil)
+			So(parsed.BizCode, ShouldEqual, "biz")
+			So(parsed.Manifest, ShouldResemble, bundle.Manifest)
+		})
+
+		Convey("依赖检查", func() {
+			bundle := NewRuleBundle("biz", rules)
+
+			err := bundle.Check(func(name string) bool { return name == "Retract" }, nil)
+			So(err, ShouldNotBeNil)
+
+			var reqErr *BundleRequirementError
+			So(errors.As(err, &reqErr), ShouldBeTrue)
+			So(reqErr.MissingFunctions, ShouldResemble, []string{"CreditCheck"})
+			So(reqErr.MissingLookups, ShouldResemble, []string{"Blacklist"})
+			So(err.Error(), ShouldContainSubstring, "CreditCheck")
+
+			all := func(string) bool { return true }
+			So(bundle.Check(all, all), ShouldBeNil)
+		})
+
+		Convey("输入字段检查", func() {
+			bundle := NewRuleBundle("biz", rules)
+
+			var checked []string
+			err := bundle.CheckInputFields(func(path string) bool {
+				checked = append(checked, path)
+				return path != "Id"
+			})
+			So(checked, ShouldResemble, []string{"Age", "Id", "Name"})
+
+			var reqErr *BundleRequirementError
+			So(errors.As(err, &reqErr), ShouldBeTrue)
+			So(reqErr.MissingFields, ShouldResemble, []string{"Params.Id"})
+			So(err.Error(), ShouldContainSubstring, "缺失输入字段: Params.Id")
+
+			So(bundle.CheckInputFields(func(string) bool { return true }), ShouldBeNil)
+		})
+
+		Convey("转换器支持规则包", func() {
+			converter := NewGRLConverter()
+			bundle := NewRuleBundle("biz", rules)
+
+			out, err := converter.ConvertToGRL(bundle)
+			So(err, ShouldBeNil)
+			So(out, ShouldEqual, grl)
+
+			_, err = converter.ConvertToGRL(RuleBundle{BizCode: "empty"})
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
@@ -1,11 +1,16 @@
 package rule
 
 import (
+	"encoding/json"
 	"fmt"
 	"reflect"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
+
+	"gitee.com/damengde/runehammer/config"
 )
 
 // ============================================================================
@@ -34,6 +39,46 @@ type RuleConverter interface {
 type GRLConverter struct {
 	config           ConverterConfig
 	expressionParser ExpressionParser
+	operators        map[string]OperatorHandler // 自定义操作符处理器
+	operatorsMu      sync.RWMutex
+}
+
+// OperatorHandler 操作符处理器 - 将简单条件的左右操作数转换为GRL表达式
+type OperatorHandler interface {
+	// Convert 转换操作符，left/right为已转换的GRL操作数
+	Convert(left, right string) string
+}
+
+// OperatorFunc 函数形式的操作符处理器
+type OperatorFunc func(left, right string) string
+
+// Convert 实现OperatorHandler接口
+func (f OperatorFunc) Convert(left, right string) string {
+	return f(left, right)
+}
+
+// OperandHandler 需要原始右操作数的操作符处理器 - 如between需要取出区间的两个端点
+//
+// 注册的处理器实现该接口时，转换器调用 ConvertOperand 而不是 Convert，右操作数不做转换。
+type OperandHandler interface {
+	OperatorHandler
+
+	// ConvertOperand 转换操作符，left为已转换的GRL操作数，right为条件中的原始右操作数
+	ConvertOperand(left string, right interface{}) (string, error)
+}
+
+// OperandFunc 函数形式的原始操作数处理器
+type OperandFunc func(left string, right interface{}) (string, error)
+
+// ConvertOperand 实现OperandHandler接口
+func (f OperandFunc) ConvertOperand(left string, right interface{}) (string, error) {
+	return f(left, right)
+}
+
+// Convert 实现OperatorHandler接口 - 以转换后的右操作数调用，转换失败时返回空串
+func (f OperandFunc) Convert(left, right string) string {
+	expr, _ := f(left, right)
+	return expr
 }
 
 // ConverterConfig 转换器配置
@@ -47,11 +92,26 @@ type ConverterConfig struct {
 	// 操作符映射
 	OperatorMapping map[string]string
 
-	// 是否严格模式
+	// 是否严格模式 - 未知操作符/函数、未声明前缀的目标报错，生成的GRL须可解析
 	StrictMode bool
 
 	// 默认优先级
 	DefaultPriority int
+
+	// 是否在生成的GRL头部输出来源注释（来源规则、版本、作者、生成时间、定义哈希）
+	EmitProvenance bool
+
+	// 缺失字段的比较语义，为空时不生成存在性判断
+	NullPolicy config.NullPolicy
+
+	// 是否将与/或复合条件中的多个 Func.Call 函数条件转换为 Func.AnyOf / Func.AllOf 并发求值
+	ParallelConditions bool
+
+	// 是否在转换时折叠常量子表达式并消除恒真/恒假的子条件
+	FoldConstants bool
+
+	// 转换警告回调（如条件恒为真/假），为nil时忽略警告
+	OnWarning func(warning ConversionWarning)
 }
 
 // NewGRLConverter 创建GRL转换器
@@ -79,7 +139,8 @@ func NewGRLConverter(config ...ConverterConfig) *GRLConverter {
 			"in":       "Contains",
 			"contains": "Contains",
 			"matches":  "Matches",
-			"between":  "BETWEEN", // 特殊处理
+			"between":  "BETWEEN", // 由操作符处理器转换
+			"approxEq": "ApproxEq",
 		},
 		FunctionMapping: map[string]string{
 			"now":         "Now()",
@@ -108,6 +169,11 @@ func NewGRLConverter(config ...ConverterConfig) *GRLConverter {
 			defaultConfig.FunctionMapping = cfg.FunctionMapping
 		}
 		defaultConfig.StrictMode = cfg.StrictMode
+		defaultConfig.EmitProvenance = cfg.EmitProvenance
+		defaultConfig.NullPolicy = cfg.NullPolicy
+		defaultConfig.ParallelConditions = cfg.ParallelConditions
+		defaultConfig.FoldConstants = cfg.FoldConstants
+		defaultConfig.OnWarning = cfg.OnWarning
 		if cfg.DefaultPriority > 0 {
 			defaultConfig.DefaultPriority = cfg.DefaultPriority
 		}
@@ -116,39 +182,117 @@ func NewGRLConverter(config ...ConverterConfig) *GRLConverter {
 	return &GRLConverter{
 		config:           defaultConfig,
 		expressionParser: NewExpressionParser(),
+		operators:        defaultOperators(),
 	}
 }
 
+// defaultOperators 内置的函数式操作符
+func defaultOperators() map[string]OperatorHandler {
+	return map[string]OperatorHandler{
+		// in: 左操作数包含于右操作数
+		string(OpIn): OperatorFunc(func(left, right string) string {
+			return fmt.Sprintf("Contains(%s, %s)", right, left)
+		}),
+		string(OpContains): OperatorFunc(func(left, right string) string {
+			return fmt.Sprintf("Contains(%s, %s)", left, right)
+		}),
+		string(OpMatches): OperatorFunc(func(left, right string) string {
+			return fmt.Sprintf("Matches(%s, %s)", left, right)
+		}),
+		// between: 右操作数为 [下限, 上限] 两个值的数组，包含端点
+		string(OpBetween): OperandFunc(convertBetween),
+	}
+}
+
+// convertBetween 转换between操作符
+func convertBetween(left string, right interface{}) (string, error) {
+	if right != nil && reflect.TypeOf(right).Kind() == reflect.Slice {
+		values := reflect.ValueOf(right)
+		if values.Len() == 2 {
+			return fmt.Sprintf("%s >= %v && %s <= %v",
+				left, values.Index(0).Interface(),
+				left, values.Index(1).Interface()), nil
+		}
+	}
+	return "", fmt.Errorf("between操作符需要两个值的数组")
+}
+
+// RegisterOperator 注册自定义操作符 - 同名操作符覆盖内置实现
+//
+// 使用示例:
+//
+//	converter.RegisterOperator("withinRadius", rule.OperatorFunc(func(left, right string) string {
+//	    return fmt.Sprintf("Geo.WithinRadius(%s, %s)", left, right)
+//	}))
+func (c *GRLConverter) RegisterOperator(name string, handler OperatorHandler) {
+	c.operatorsMu.Lock()
+	defer c.operatorsMu.Unlock()
+
+	c.operators[name] = handler
+}
+
+// operatorHandler 获取操作符处理器
+func (c *GRLConverter) operatorHandler(name string) (OperatorHandler, bool) {
+	c.operatorsMu.RLock()
+	defer c.operatorsMu.RUnlock()
+
+	handler, ok := c.operators[name]
+	return handler, ok
+}
+
 // ConvertToGRL 转换标准格式到GRL
 func (c *GRLConverter) ConvertToGRL(definition interface{}) (string, error) {
 	switch def := definition.(type) {
 	case StandardRule:
-		return c.ConvertRule(def, Definitions{})
+		return c.generated(c.ConvertRule(def, Definitions{}))
 
 	case *StandardRule:
-		return c.ConvertRule(*def, Definitions{})
+		return c.generated(c.ConvertRule(*def, Definitions{}))
 
 	case SimpleRule:
-		return c.ConvertSimpleRule(def)
+		return c.generated(c.ConvertSimpleRule(def))
 
 	case *SimpleRule:
-		return c.ConvertSimpleRule(*def)
+		return c.generated(c.ConvertSimpleRule(*def))
 
 	case MetricRule:
-		return c.ConvertMetricRule(def)
+		return c.generated(c.ConvertMetricRule(def))
 
 	case *MetricRule:
-		return c.ConvertMetricRule(*def)
+		return c.generated(c.ConvertMetricRule(*def))
+
+	case ValidationRule:
+		return c.generated(c.ConvertValidationRule(def))
+
+	case *ValidationRule:
+		return c.generated(c.ConvertValidationRule(*def))
 
 	case RuleDefinitionStandard:
 		// 转换完整的规则定义标准
 		return c.convertStandard(def)
 
+	case RuleBundle:
+		return c.convertBundle(def)
+
+	case *RuleBundle:
+		return c.convertBundle(*def)
+
 	default:
 		return "", fmt.Errorf("不支持的规则定义类型: %T", definition)
 	}
 }
 
+// generated 校验生成的GRL，严格模式下校验失败时不返回GRL
+func (c *GRLConverter) generated(grl string, err error) (string, error) {
+	if err != nil {
+		return "", err
+	}
+	if err := c.checkGenerated(grl); err != nil {
+		return "", err
+	}
+	return grl, nil
+}
+
 // ConvertRule 转换标准规则
 func (c *GRLConverter) ConvertRule(rule StandardRule, defs Definitions) (string, error) {
 	var grl strings.Builder
@@ -159,6 +303,7 @@ func (c *GRLConverter) ConvertRule(rule StandardRule, defs Definitions) (string,
 		priority = c.config.DefaultPriority
 	}
 
+	grl.WriteString(c.provenance("rule "+rule.ID, rule.Version, rule.Author, rule))
 	grl.WriteString(fmt.Sprintf("rule %s \"%s\" salience %d {\n",
 		c.sanitizeRuleName(rule.ID),
 		rule.Description,
@@ -170,7 +315,7 @@ func (c *GRLConverter) ConvertRule(rule StandardRule, defs Definitions) (string,
 	if err != nil {
 		return "", fmt.Errorf("转换条件失败: %w", err)
 	}
-	grl.WriteString(condition)
+	grl.WriteString(c.foldCondition(rule.ID, condition))
 	grl.WriteString("\n")
 
 	// then子句
@@ -197,6 +342,7 @@ func (c *GRLConverter) ConvertSimpleRule(rule SimpleRule) (string, error) {
 	// 生成规则名
 	ruleName := "SimpleRule_" + c.generateRuleID()
 
+	grl.WriteString(c.provenance("simple rule", 0, "", rule))
 	grl.WriteString(fmt.Sprintf("rule %s \"动态生成的简化规则\" salience %d {\n",
 		ruleName, c.config.DefaultPriority))
 
@@ -206,13 +352,16 @@ func (c *GRLConverter) ConvertSimpleRule(rule SimpleRule) (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("解析when条件失败: %w", err)
 	}
-	grl.WriteString(condition)
+	grl.WriteString(c.foldCondition(ruleName, condition))
 	grl.WriteString("\n")
 
 	// then子句 - 解析结果表达式
 	grl.WriteString("    then\n")
 	for key, expr := range rule.Then {
-		action, err := c.expressionParser.ParseAction(key, expr)
+		if err := c.checkTarget(key); err != nil {
+			return "", err
+		}
+		action, err := c.expressionParser.ParseAction(key, c.foldExpression(expr))
 		if err != nil {
 			return "", fmt.Errorf("解析then动作失败 (%s): %w", key, err)
 		}
@@ -233,6 +382,7 @@ func (c *GRLConverter) ConvertMetricRule(rule MetricRule) (string, error) {
 	// 生成规则名
 	ruleName := c.sanitizeRuleName("Metric_" + rule.Name)
 
+	grl.WriteString(c.provenance("metric "+rule.Name, 0, "", rule))
 	grl.WriteString(fmt.Sprintf("rule %s \"%s\" salience %d {\n",
 		ruleName, rule.Description, c.config.DefaultPriority))
 
@@ -247,7 +397,7 @@ func (c *GRLConverter) ConvertMetricRule(rule MetricRule) (string, error) {
 			}
 			conditions = append(conditions, parsed)
 		}
-		grl.WriteString(strings.Join(conditions, " && "))
+		grl.WriteString(c.foldCondition(ruleName, strings.Join(conditions, " && ")))
 	} else {
 		grl.WriteString("true") // 无条件
 	}
@@ -258,7 +408,7 @@ func (c *GRLConverter) ConvertMetricRule(rule MetricRule) (string, error) {
 
 	// 定义变量
 	for varName, expr := range rule.Variables {
-		varDef, err := c.expressionParser.ParseAction(varName, expr)
+		varDef, err := c.expressionParser.ParseAction(varName, c.foldExpression(expr))
 		if err != nil {
 			return "", fmt.Errorf("解析变量定义失败 (%s): %w", varName, err)
 		}
@@ -270,6 +420,14 @@ func (c *GRLConverter) ConvertMetricRule(rule MetricRule) (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("解析指标公式失败: %w", err)
 	}
+	formula = c.foldExpression(formula)
+
+	if rule.Bands != nil {
+		formula, err = c.bandExpression(*rule.Bands, formula)
+		if err != nil {
+			return "", fmt.Errorf("指标 %s 的区间表无效: %w", rule.Name, err)
+		}
+	}
 
 	grl.WriteString(fmt.Sprintf("        Result[\"%s\"] = %s;\n", rule.Name, formula))
 
@@ -280,11 +438,40 @@ func (c *GRLConverter) ConvertMetricRule(rule MetricRule) (string, error) {
 	return grl.String(), nil
 }
 
-// convertCondition 转换条件
+// bandExpression 生成按区间表映射的表达式 - 阈值和标签以JSON数组字符串传给 Band 函数
+func (c *GRLConverter) bandExpression(table BandTable, value string) (string, error) {
+	if err := table.Validate(); err != nil {
+		return "", err
+	}
+	thresholds, err := json.Marshal(table.Thresholds())
+	if err != nil {
+		return "", err
+	}
+	labels, err := json.Marshal(table.Labels())
+	if err != nil {
+		return "", fmt.Errorf("区间值无法序列化: %w", err)
+	}
+	return fmt.Sprintf("Band(%s, %s, %s)", value, strconv.Quote(string(thresholds)), strconv.Quote(string(labels))), nil
+}
+
+// convertCondition 转换条件 - 三值逻辑语义下整棵条件树按Kleene逻辑转换
 func (c *GRLConverter) convertCondition(cond Condition, defs Definitions) (string, error) {
+	if c.config.NullPolicy == config.NullPolicyUnknown {
+		expr, _, err := c.convertKleene(cond, defs)
+		return expr, err
+	}
+	return c.convertConditionNode(cond, defs)
+}
+
+// convertConditionNode 转换单个条件节点
+func (c *GRLConverter) convertConditionNode(cond Condition, defs Definitions) (string, error) {
 	switch cond.Type {
 	case ConditionTypeSimple:
-		return c.convertSimpleCondition(cond, defs)
+		expr, err := c.convertSimpleCondition(cond, defs)
+		if err != nil {
+			return "", err
+		}
+		return c.guardNull(cond, defs, expr), nil
 
 	case ConditionTypeComposite:
 		return c.convertCompositeCondition(cond, defs)
@@ -313,6 +500,29 @@ func (c *GRLConverter) convertSimpleCondition(cond Condition, defs Definitions)
 	if err != nil {
 		return "", fmt.Errorf("转换操作符失败: %w", err)
 	}
+	if err := c.checkOperator(string(cond.Operator)); err != nil {
+		return "", err
+	}
+
+	// 近似相等及金额的等值比较按容差转换
+	if unit, ok := unitOperand(cond.Right); ok {
+		cond.Right = unit
+	}
+	_, money := cond.Right.(MoneyValue)
+	switch {
+	case cond.Operator == OpApproxEq:
+		return c.convertApproxCondition(left, cond.Right, false, defs)
+	case money && cond.Operator == OpEqual:
+		return c.convertApproxCondition(left, cond.Right, false, defs)
+	case money && cond.Operator == OpNotEqual:
+		return c.convertApproxCondition(left, cond.Right, true, defs)
+	}
+
+	// 需要原始右操作数的操作符
+	handler, custom := c.operatorHandler(string(cond.Operator))
+	if operandHandler, ok := handler.(OperandHandler); ok {
+		return operandHandler.ConvertOperand(left, cond.Right)
+	}
 
 	// 右操作数
 	right, err := c.convertOperand(cond.Right, defs)
@@ -320,35 +530,12 @@ func (c *GRLConverter) convertSimpleCondition(cond Condition, defs Definitions)
 		return "", fmt.Errorf("转换右操作数失败: %w", err)
 	}
 
-	// 特殊操作符处理
-	switch cond.Operator {
-	case OpBetween:
-		// 处理BETWEEN操作符
-		if reflect.TypeOf(cond.Right).Kind() == reflect.Slice {
-			values := reflect.ValueOf(cond.Right)
-			if values.Len() == 2 {
-				return fmt.Sprintf("%s >= %v && %s <= %v",
-					left, values.Index(0).Interface(),
-					left, values.Index(1).Interface()), nil
-			}
-		}
-		return "", fmt.Errorf("between操作符需要两个值的数组")
-
-	case "in":
-		// 处理IN操作符 - 转换为Contains函数调用
-		return fmt.Sprintf("Contains(%s, %s)", right, left), nil
-
-	case "contains":
-		// 处理CONTAINS操作符
-		return fmt.Sprintf("Contains(%s, %s)", left, right), nil
-
-	case "matches":
-		// 处理正则匹配
-		return fmt.Sprintf("Matches(%s, %s)", left, right), nil
-
-	default:
-		return fmt.Sprintf("%s %s %s", left, operator, right), nil
+	// 自定义及内置函数式操作符
+	if custom {
+		return handler.Convert(left, right), nil
 	}
+
+	return fmt.Sprintf("%s %s %s", left, operator, right), nil
 }
 
 // convertCompositeCondition 转换复合条件
@@ -367,10 +554,16 @@ func (c *GRLConverter) convertCompositeCondition(cond Condition, defs Definition
 	}
 
 	// 操作符
+	if err := c.checkOperator(string(cond.Operator)); err != nil {
+		return "", err
+	}
 	operator := c.config.OperatorMapping[string(cond.Operator)]
 	if operator == "" {
 		operator = string(cond.Operator)
 	}
+	if c.config.ParallelConditions {
+		conditions = parallelizeConditions(conditions, cond.Operator)
+	}
 
 	return strings.Join(conditions, " "+operator+" "), nil
 }
@@ -383,11 +576,20 @@ func (c *GRLConverter) convertFunctionCondition(cond Condition, defs Definitions
 
 // convertAction 转换动作
 func (c *GRLConverter) convertAction(action Action, defs Definitions) (string, error) {
+	if action.Type == ActionTypeAssign || action.Type == ActionTypeCalculate {
+		if err := c.checkTarget(action.Target); err != nil {
+			return "", err
+		}
+	}
+
 	switch action.Type {
 	case ActionTypeAssign:
 		// 赋值动作: target = value
 		target := c.resolveTarget(action.Target)
-		value := c.convertValue(action.Value)
+		value, err := c.convertAssignValue(action.Value)
+		if err != nil {
+			return "", err
+		}
 		return fmt.Sprintf("%s = %s", target, value), nil
 
 	case ActionTypeCalculate:
@@ -397,7 +599,7 @@ func (c *GRLConverter) convertAction(action Action, defs Definitions) (string, e
 		if err != nil {
 			return "", err
 		}
-		return fmt.Sprintf("%s = %s", target, expr), nil
+		return fmt.Sprintf("%s = %s", target, c.foldExpression(expr)), nil
 
 	case ActionTypeInvoke:
 		// 调用动作: function(params)
@@ -411,12 +613,24 @@ func (c *GRLConverter) convertAction(action Action, defs Definitions) (string, e
 		return fmt.Sprintf("%s()", action.Target), nil
 
 	case ActionTypeLog:
-		// 日志动作
-		return fmt.Sprintf("Log(\"%s\")", action.Value), nil
+		// 日志动作，Target为日志级别（debug/info/warn/error），默认info
+		function, ok := logFunctions[strings.ToLower(action.Target)]
+		if !ok {
+			return "", fmt.Errorf("不支持的日志级别: %s", action.Target)
+		}
+		message, err := c.convertTemplate(action.Value)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s(%s)", function, message), nil
 
 	case ActionTypeAlert:
 		// 告警动作
-		return fmt.Sprintf("Alert(\"%s\")", action.Value), nil
+		message, err := c.convertTemplate(action.Value)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Alert(%s)", message), nil
 
 	default:
 		return "", fmt.Errorf("不支持的动作类型: %s", action.Type)
@@ -427,10 +641,14 @@ func (c *GRLConverter) convertAction(action Action, defs Definitions) (string, e
 
 // convertOperand 转换操作数
 func (c *GRLConverter) convertOperand(operand interface{}, defs Definitions) (string, error) {
+	if unit, ok := unitOperand(operand); ok {
+		return c.convertUnit(unit)
+	}
+
 	switch v := operand.(type) {
 	case string:
-		// 检查是否是字段引用
-		if strings.Contains(v, ".") || c.isVariable(v) {
+		// 检查是否是字段引用（含 Params["amount"] 形式的map访问）
+		if strings.Contains(v, ".") || c.isVariable(v) || fieldPathPattern.MatchString(v) {
 			return v, nil
 		}
 		// 字符串字面量
@@ -529,6 +747,24 @@ func (c *GRLConverter) convertStandard(standard RuleDefinitionStandard) (string,
 	return strings.Join(allRules, "\n\n"), nil
 }
 
+// convertBundle 转换规则包 - 拼接所有启用规则的GRL
+func (c *GRLConverter) convertBundle(bundle RuleBundle) (string, error) {
+	var allRules []string
+
+	for _, rule := range bundle.Rules {
+		if !rule.Enabled || strings.TrimSpace(rule.GRL) == "" {
+			continue
+		}
+		allRules = append(allRules, rule.GRL)
+	}
+
+	if len(allRules) == 0 {
+		return "", fmt.Errorf("规则包 %s 不包含可用规则", bundle.BizCode)
+	}
+
+	return strings.Join(allRules, "\n\n"), nil
+}
+
 // Validate 验证规则定义
 func (c *GRLConverter) Validate(definition interface{}) error {
 	switch def := definition.(type) {
@@ -552,6 +788,12 @@ func (c *GRLConverter) Validate(definition interface{}) error {
 			return fmt.Errorf("简化规则的then动作不能为空")
 		}
 
+	case ValidationRule:
+		return def.validateCrossField()
+
+	case *ValidationRule:
+		return def.validateCrossField()
+
 	case MetricRule:
 		if def.Name == "" {
 			return fmt.Errorf("指标规则的名称不能为空")
@@ -559,6 +801,11 @@ func (c *GRLConverter) Validate(definition interface{}) error {
 		if def.Formula == "" {
 			return fmt.Errorf("指标规则的公式不能为空")
 		}
+		if def.Bands != nil {
+			if err := def.Bands.Validate(); err != nil {
+				return fmt.Errorf("指标规则的区间表无效: %w", err)
+			}
+		}
 	}
 
 	return nil
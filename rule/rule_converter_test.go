@@ -1,6 +1,8 @@
 package rule
 
 import (
+	"errors"
+	"strconv"
 	"strings"
 	"testing"
 
@@ -542,6 +544,79 @@ func TestGRLConverter(t *testing.T) {
 				So(err, ShouldBeNil)
 				So(grl, ShouldContainSubstring, "&&") // AND转换为&&
 			})
+
+			Convey("内置函数式操作符", func() {
+				rule := NewStandardRule("IN_TEST", "包含测试")
+				rule.AddSimpleCondition("level", OpIn, []string{"gold", "vip"})
+				rule.AddAction(ActionTypeAssign, "result", "ok")
+
+				grl, err := converter.ConvertRule(*rule, Definitions{})
+				So(err, ShouldBeNil)
+				So(grl, ShouldContainSubstring, "Contains(")
+			})
+
+			Convey("注册自定义操作符", func() {
+				converter.RegisterOperator("withinRadius", OperatorFunc(func(left, right string) string {
+					return "Geo.WithinRadius(" + left + ", " + right + ")"
+				}))
+
+				rule := NewStandardRule("GEO_TEST", "距离测试")
+				rule.AddSimpleCondition("distance", Operator("withinRadius"), 5)
+				rule.AddAction(ActionTypeAssign, "result", "ok")
+
+				grl, err := converter.ConvertRule(*rule, Definitions{})
+				So(err, ShouldBeNil)
+				So(grl, ShouldContainSubstring, "Geo.WithinRadius(")
+				So(grl, ShouldContainSubstring, ", 5)")
+			})
+
+			Convey("自定义操作符覆盖内置实现", func() {
+				converter.RegisterOperator(string(OpIn), OperatorFunc(func(left, right string) string {
+					return "InList(" + left + ", " + right + ")"
+				}))
+
+				rule := NewStandardRule("OVERRIDE_TEST", "覆盖测试")
+				rule.AddSimpleCondition("level", OpIn, []string{"gold"})
+				rule.AddAction(ActionTypeAssign, "result", "ok")
+
+				grl, err := converter.ConvertRule(*rule, Definitions{})
+				So(err, ShouldBeNil)
+				So(grl, ShouldContainSubstring, "InList(")
+				So(grl, ShouldNotContainSubstring, "Contains(")
+			})
+
+			Convey("between 通过操作符表转换，可被覆盖", func() {
+				converter.RegisterOperator(string(OpBetween), OperandFunc(func(left string, right interface{}) (string, error) {
+					bounds, ok := right.([]int)
+					if !ok || len(bounds) != 2 {
+						return "", errors.New("区间需要两个整数")
+					}
+					return "InRange(" + left + ", " + strconv.Itoa(bounds[0]) + ", " + strconv.Itoa(bounds[1]) + ")", nil
+				}))
+
+				rule := NewStandardRule("RANGE_TEST", "区间测试")
+				rule.AddSimpleCondition("customer.age", OpBetween, []int{18, 65})
+				rule.AddAction(ActionTypeAssign, "result", "ok")
+
+				grl, err := converter.ConvertRule(*rule, Definitions{})
+				So(err, ShouldBeNil)
+				So(grl, ShouldContainSubstring, "InRange(customer.age, 18, 65)")
+				So(grl, ShouldNotContainSubstring, ">=")
+			})
+
+			Convey("原始操作数处理器的错误原样返回", func() {
+				converter.RegisterOperator("oneOf", OperandFunc(func(left string, right interface{}) (string, error) {
+					return "", errors.New("oneOf需要非空数组")
+				}))
+
+				rule := NewStandardRule("ONE_OF_TEST", "枚举测试")
+				rule.AddSimpleCondition("level", Operator("oneOf"), nil)
+				rule.AddAction(ActionTypeAssign, "result", "ok")
+
+				_, err := converter.ConvertRule(*rule, Definitions{})
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "oneOf需要非空数组")
+			})
 		})
 
 		Convey("Validate 验证方法", func() {
@@ -813,3 +888,99 @@ func TestRuleConverterInterface(t *testing.T) {
 		})
 	})
 }
+
+// TestConvertLogActions 测试日志与告警动作转换
+func TestConvertLogActions(t *testing.T) {
+	Convey("日志与告警动作转换", t, func() {
+		converter := NewGRLConverter(ConverterConfig{StrictMode: true})
+		convert := func(actions ...Action) (string, error) {
+			return converter.ConvertRule(StandardRule{
+				ID: "notify", Name: "通知", Enabled: true,
+				Conditions: Condition{Type: ConditionTypeSimple, Left: "Params.amount", Operator: OpGreaterThan, Right: 0},
+				Actions:    actions,
+			}, Definitions{})
+		}
+
+		Convey("按Target选择日志级别并转义消息", func() {
+			grl, err := convert(
+				Action{Type: ActionTypeLog, Value: "用户 ${user.id} 申请"},
+				Action{Type: ActionTypeLog, Target: "WARN", Value: `金额 "过高"`},
+				Action{Type: ActionTypeLog, Target: "debug", Value: "调试"},
+				Action{Type: ActionTypeAlert, Value: "需复核"},
+			)
+			So(err, ShouldBeNil)
+			So(grl, ShouldContainSubstring, `Log("用户 ${user.id} 申请");`)
+			So(grl, ShouldContainSubstring, `LogWarn("金额 \"过高\"");`)
+			So(grl, ShouldContainSubstring, `LogDebug("调试");`)
+			So(grl, ShouldContainSubstring, `Alert("需复核");`)
+		})
+
+		Convey("不支持的日志级别", func() {
+			_, err := convert(Action{Type: ActionTypeLog, Target: "fatal", Value: "x"})
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "不支持的日志级别")
+		})
+
+		Convey("含模板的赋值生成Template调用", func() {
+			grl, err := convert(
+				Action{Type: ActionTypeAssign, Target: "Result.message", Value: "用户 ${user.id} 的 $${literal}"},
+				Action{Type: ActionTypeAssign, Target: "Result.plain", Value: "无模板"},
+			)
+			So(err, ShouldBeNil)
+			So(grl, ShouldContainSubstring, `Result["message"] = Template("用户 ${user.id} 的 $${literal}");`)
+			So(grl, ShouldContainSubstring, `Result["plain"] = "无模板";`)
+		})
+
+		Convey("严格模式下未声明的模板变量报错", func() {
+			_, err := convert(Action{Type: ActionTypeLog, Value: "账户 ${account.id}"})
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "${account.id}")
+
+			_, err = convert(Action{Type: ActionTypeAssign, Target: "Result.message", Value: "${account.id}"})
+			So(err, ShouldNotBeNil)
+
+			// 转义的变量不校验
+			_, err = convert(Action{Type: ActionTypeAlert, Value: "$${account.id} ${Params.amount}"})
+			So(err, ShouldBeNil)
+
+			_, err = NewGRLConverter().ConvertRule(StandardRule{
+				ID: "notify", Name: "通知", Enabled: true,
+				Conditions: Condition{Type: ConditionTypeSimple, Left: "Params.amount", Operator: OpGreaterThan, Right: 0},
+				Actions:    []Action{{Type: ActionTypeLog, Value: "账户 ${account.id}"}},
+			}, Definitions{})
+			So(err, ShouldBeNil)
+		})
+	})
+}
+
+// TestConvertMetricRuleBands 测试指标规则的区间表
+func TestConvertMetricRuleBands(t *testing.T) {
+	Convey("指标规则的区间表", t, func() {
+		converter := NewGRLConverter()
+		metric := MetricRule{
+			Name:    "grade",
+			Formula: "customer.score",
+			Bands: &BandTable{
+				Default: "D",
+				Bands:   []Band{{Min: 600, Value: "C"}, {Min: 700, Value: "B"}, {Min: 800, Value: "A"}},
+			},
+		}
+
+		Convey("公式结果通过 Band 映射", func() {
+			grl, err := converter.ConvertToGRL(metric)
+			So(err, ShouldBeNil)
+			So(grl, ShouldContainSubstring, `Result["grade"] = Band(customer.score, "[600,700,800]", "[\"D\",\"C\",\"B\",\"A\"]");`)
+		})
+
+		Convey("下限必须严格升序", func() {
+			metric.Bands.Bands[2].Min = 700
+			So(converter.Validate(metric), ShouldNotBeNil)
+			_, err := converter.ConvertToGRL(metric)
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("区间表不能为空", func() {
+			So(converter.Validate(MetricRule{Name: "grade", Formula: "1", Bands: &BandTable{}}), ShouldNotBeNil)
+		})
+	})
+}
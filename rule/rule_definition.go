@@ -45,14 +45,18 @@ type FunctionDef struct {
 
 // StandardRule 标准规则定义
 type StandardRule struct {
-	ID          string      `json:"id" yaml:"id"`                   // 规则唯一标识
-	Name        string      `json:"name" yaml:"name"`               // 规则名称
-	Description string      `json:"description" yaml:"description"` // 规则描述
-	Priority    int         `json:"priority" yaml:"priority"`       // 优先级 (salience)
-	Enabled     bool        `json:"enabled" yaml:"enabled"`         // 是否启用
-	Tags        []string    `json:"tags" yaml:"tags"`               // 标签
-	Conditions  Condition   `json:"conditions" yaml:"conditions"`   // 条件定义
-	Actions     []Action    `json:"actions" yaml:"actions"`         // 动作定义
+	ID          string    `json:"id" yaml:"id"`                               // 规则唯一标识
+	Name        string    `json:"name" yaml:"name"`                           // 规则名称
+	Description string    `json:"description" yaml:"description"`             // 规则描述
+	Priority    int       `json:"priority" yaml:"priority"`                   // 优先级 (salience)
+	Enabled     bool      `json:"enabled" yaml:"enabled"`                     // 是否启用
+	Tags        []string  `json:"tags" yaml:"tags"`                           // 标签
+	Conditions  Condition `json:"conditions" yaml:"conditions"`               // 条件定义
+	Actions     []Action  `json:"actions" yaml:"actions"`                     // 动作定义
+	Version     int       `json:"version,omitempty" yaml:"version,omitempty"` // 定义版本，用于来源注释
+	Author      string    `json:"author,omitempty" yaml:"author,omitempty"`   // 作者，用于来源注释
+	Owner       string    `json:"owner,omitempty" yaml:"owner,omitempty"`     // 负责人，转换为规则记录时保留，规则出错时附加到错误中
+	DocURL      string    `json:"docUrl,omitempty" yaml:"docUrl,omitempty"`   // 规则说明文档链接，同负责人
 }
 
 // ============================================================================
@@ -148,6 +152,51 @@ type MetricRule struct {
 	Formula     string            `json:"formula" yaml:"formula"`         // 计算公式
 	Variables   map[string]string `json:"variables" yaml:"variables"`     // 变量定义
 	Conditions  []string          `json:"conditions" yaml:"conditions"`   // 计算条件
+	Bands       *BandTable        `json:"bands,omitempty" yaml:"bands,omitempty"` // 区间表，设置后公式结果按区间映射为等级或值
+}
+
+// BandTable 区间表 - 按区间下限把数值映射为等级或值，区间左闭右开
+type BandTable struct {
+	Bands   []Band      `json:"bands" yaml:"bands"`     // 区间，按下限严格升序
+	Default interface{} `json:"default" yaml:"default"` // 低于最小下限时的值
+}
+
+// Band 区间 - 大于等于Min且小于下一个区间的Min时取Value
+type Band struct {
+	Min   float64     `json:"min" yaml:"min"`     // 区间下限（含）
+	Value interface{} `json:"value" yaml:"value"` // 等级或值
+}
+
+// Validate 检查区间表 - 至少一个区间，下限严格升序
+func (t *BandTable) Validate() error {
+	if len(t.Bands) == 0 {
+		return fmt.Errorf("区间表至少需要一个区间")
+	}
+	for i := 1; i < len(t.Bands); i++ {
+		if t.Bands[i].Min <= t.Bands[i-1].Min {
+			return fmt.Errorf("区间下限必须严格升序: %v 不大于 %v", t.Bands[i].Min, t.Bands[i-1].Min)
+		}
+	}
+	return nil
+}
+
+// Thresholds 各区间下限
+func (t *BandTable) Thresholds() []float64 {
+	thresholds := make([]float64, len(t.Bands))
+	for i, band := range t.Bands {
+		thresholds[i] = band.Min
+	}
+	return thresholds
+}
+
+// Labels 各桶的值，第一个为 Default，与 Band 函数的标签参数一致
+func (t *BandTable) Labels() []interface{} {
+	labels := make([]interface{}, 0, len(t.Bands)+1)
+	labels = append(labels, t.Default)
+	for _, band := range t.Bands {
+		labels = append(labels, band.Value)
+	}
+	return labels
 }
 
 // ValidationRule 验证规则 - 专门用于数据验证
@@ -158,6 +207,10 @@ type ValidationRule struct {
 	Level    string      `json:"level" yaml:"level"`       // 级别: error, warning
 	Required bool        `json:"required" yaml:"required"` // 是否必填
 	Default  interface{} `json:"default" yaml:"default"`   // 默认值
+
+	// 跨字段约束：要求 Field Operator Compare 成立，如 end_date > start_date、sum(items.amount) == total
+	Compare  string   `json:"compare,omitempty" yaml:"compare,omitempty"`   // 对比字段，可为 sum/avg/min/max/count 聚合
+	Operator Operator `json:"operator,omitempty" yaml:"operator,omitempty"` // 比较操作符: ==, !=, >, >=, <, <=
 }
 
 // ============================================================================
@@ -0,0 +1,116 @@
+package rule
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// ============================================================================
+// 文件系统规则 - 从 go:embed 等文件系统加载规则，供类库在二进制中附带默认规则集
+// ============================================================================
+
+// LoadRulesFS 从文件系统加载匹配glob的规则文件
+//
+// 按扩展名解析:
+//   - .grl  每个文件一条规则，业务码为所在目录名，规则名为去掉扩展名的文件名，如 rules/loan/limit.grl
+//   - .json 规则包（RuleBundle，可由 NewRuleBundle 导出），规则的业务码取规则包的业务码
+//
+// 其他扩展名的文件被忽略。.grl 规则的版本为1，规则包中禁用的规则被跳过。
+//
+// 参数:
+//
+//	fsys    - 文件系统，如 embed.FS
+//	pattern - fs.Glob 匹配模式，如 "rules/*/*.grl"
+//
+// 返回值:
+//
+//	[]*Rule - 规则列表，按文件名排序
+//	error   - 模式无效、没有匹配的规则文件、解析失败或同一业务码下规则名重复
+func LoadRulesFS(fsys fs.FS, pattern string) ([]*Rule, error) {
+	matches, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return nil, fmt.Errorf("规则文件匹配模式 %q 无效: %w", pattern, err)
+	}
+
+	var rules []*Rule
+	sources := make(map[string]string)
+	for _, name := range matches {
+		var loaded []*Rule
+		switch strings.ToLower(path.Ext(name)) {
+		case ".grl":
+			loaded, err = loadGRLFile(fsys, name)
+		case ".json":
+			loaded, err = loadBundleFile(fsys, name)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, r := range loaded {
+			key := r.BizCode + "\x00" + r.Name + "\x00" + r.Environment
+			if first, ok := sources[key]; ok {
+				return nil, fmt.Errorf("业务码 %s 的规则 %s 在 %s 和 %s 中重复定义", r.BizCode, r.Name, first, name)
+			}
+			sources[key] = name
+		}
+		rules = append(rules, loaded...)
+	}
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("没有匹配 %q 的规则文件", pattern)
+	}
+	return rules, nil
+}
+
+// loadGRLFile 加载单个GRL文件，业务码取所在目录名
+func loadGRLFile(fsys fs.FS, name string) ([]*Rule, error) {
+	dir := path.Dir(name)
+	if dir == "." {
+		return nil, fmt.Errorf("规则文件 %s 必须放在以业务码命名的目录中", name)
+	}
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, fmt.Errorf("读取规则文件 %s 失败: %w", name, err)
+	}
+	if strings.TrimSpace(string(data)) == "" {
+		return nil, fmt.Errorf("规则文件 %s 为空", name)
+	}
+
+	base := path.Base(name)
+	return []*Rule{{
+		BizCode: path.Base(dir),
+		Name:    strings.TrimSuffix(base, path.Ext(base)),
+		GRL:     string(data),
+		Version: 1,
+		Enabled: true,
+	}}, nil
+}
+
+// loadBundleFile 加载规则包文件
+func loadBundleFile(fsys fs.FS, name string) ([]*Rule, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, fmt.Errorf("读取规则包 %s 失败: %w", name, err)
+	}
+	var bundle RuleBundle
+	if err := bundle.FromJSON(string(data)); err != nil {
+		return nil, fmt.Errorf("解析规则包 %s 失败: %w", name, err)
+	}
+	if bundle.BizCode == "" {
+		return nil, fmt.Errorf("规则包 %s 缺少业务码", name)
+	}
+
+	rules := make([]*Rule, 0, len(bundle.Rules))
+	for i := range bundle.Rules {
+		r := bundle.Rules[i]
+		if !r.Enabled {
+			continue
+		}
+		r.BizCode = bundle.BizCode
+		rules = append(rules, &r)
+	}
+	return rules, nil
+}
@@ -0,0 +1,71 @@
+package rule
+
+import (
+	"testing"
+	"testing/fstest"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// TestLoadRulesFS 测试从文件系统加载规则
+func TestLoadRulesFS(t *testing.T) {
+	Convey("从文件系统加载规则", t, func() {
+		bundle, err := NewRuleBundle("quote", []*Rule{
+			{Name: "base", GRL: `rule Base "基础价" { when true then Result["price"] = 10; Retract("Base"); }`, Version: 2, Enabled: true},
+			{Name: "old", GRL: `rule Old "停用" { when true then Retract("Old"); }`, Enabled: false},
+		}).ToJSON()
+		So(err, ShouldBeNil)
+
+		fsys := fstest.MapFS{
+			"rules/loan/limit.grl": {Data: []byte(`rule Limit "额度" { when true then Result["limit"] = 100; Retract("Limit"); }`)},
+			"rules/loan/score.grl": {Data: []byte(`rule Score "评分" { when true then Result["score"] = 1; Retract("Score"); }`)},
+			"rules/loan/README.md": {Data: []byte("说明")},
+			"rules/quote.json":     {Data: []byte(bundle)},
+			"top.grl":              {Data: []byte(`rule Top "顶层" { when true then Retract("Top"); }`)},
+			"empty/blank.grl":      {Data: []byte("  \n")},
+		}
+
+		Convey("GRL文件以目录名为业务码、文件名为规则名", func() {
+			rules, err := LoadRulesFS(fsys, "rules/*/*")
+			So(err, ShouldBeNil)
+			So(rules, ShouldHaveLength, 2)
+			So(rules[0].BizCode, ShouldEqual, "loan")
+			So(rules[0].Name, ShouldEqual, "limit")
+			So(rules[0].Enabled, ShouldBeTrue)
+			So(rules[0].Version, ShouldEqual, 1)
+			So(rules[1].Name, ShouldEqual, "score")
+		})
+
+		Convey("规则包跳过禁用规则", func() {
+			rules, err := LoadRulesFS(fsys, "rules/*.json")
+			So(err, ShouldBeNil)
+			So(rules, ShouldHaveLength, 1)
+			So(rules[0].BizCode, ShouldEqual, "quote")
+			So(rules[0].Name, ShouldEqual, "base")
+			So(rules[0].Version, ShouldEqual, 2)
+		})
+
+		Convey("无效文件返回错误", func() {
+			_, err := LoadRulesFS(fsys, "*.grl")
+			So(err.Error(), ShouldContainSubstring, "以业务码命名的目录")
+
+			_, err = LoadRulesFS(fsys, "empty/*.grl")
+			So(err.Error(), ShouldContainSubstring, "为空")
+
+			_, err = LoadRulesFS(fsys, "missing/*.grl")
+			So(err.Error(), ShouldContainSubstring, "没有匹配")
+
+			_, err = LoadRulesFS(fsys, "[")
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("同一业务码下规则名重复返回错误", func() {
+			dup := fstest.MapFS{
+				"a/loan/limit.grl": {Data: []byte(`rule A "A" { when true then Retract("A"); }`)},
+				"b/loan/limit.grl": {Data: []byte(`rule B "B" { when true then Retract("B"); }`)},
+			}
+			_, err := LoadRulesFS(dup, "*/loan/*.grl")
+			So(err.Error(), ShouldContainSubstring, "重复定义")
+		})
+	})
+}
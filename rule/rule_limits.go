@@ -0,0 +1,107 @@
+package rule
+
+import (
+	"fmt"
+
+	"gitee.com/damengde/runehammer/config"
+)
+
+// ============================================================================
+// 规则集限制 - 发布前检查规则数、条件嵌套深度和GRL大小，拒绝病态规则集
+// ============================================================================
+
+// 限制项
+const (
+	LimitMaxRules          = "max_rules"           // 业务码的最大规则数
+	LimitMaxConditionDepth = "max_condition_depth" // 单条规则when条件的最大嵌套深度
+	LimitMaxGRLBytes       = "max_grl_bytes"       // 单条规则GRL的最大字节数
+)
+
+// LimitViolation 超出限制的项
+type LimitViolation struct {
+	Limit  string `json:"limit"`          // 限制项
+	Rule   string `json:"rule,omitempty"` // 规则名称，规则数限制时为空
+	Actual int    `json:"actual"`         // 实际值
+	Max    int    `json:"max"`            // 上限
+}
+
+// String 超限描述
+func (v LimitViolation) String() string {
+	switch v.Limit {
+	case LimitMaxRules:
+		return fmt.Sprintf("规则数 %d 超过上限 %d", v.Actual, v.Max)
+	case LimitMaxConditionDepth:
+		return fmt.Sprintf("规则 %s 条件嵌套深度 %d 超过上限 %d", v.Rule, v.Actual, v.Max)
+	default:
+		return fmt.Sprintf("规则 %s GRL大小 %d 字节超过上限 %d", v.Rule, v.Actual, v.Max)
+	}
+}
+
+// CheckLimits 检查规则集是否超出限制 - 用于发布前检查候选规则集
+//
+// 参数:
+//
+//	rules  - 候选规则集，禁用的规则同样计入
+//	limits - 规则集限制，<=0的项不检查
+//
+// 返回值:
+//
+//	[]LimitViolation - 超出限制的项，规则数在前、其余按规则顺序；未超出时为空
+func CheckLimits(rules []*Rule, limits config.RuleLimits) []LimitViolation {
+	var violations []LimitViolation
+	count := 0
+	for _, r := range rules {
+		if r != nil {
+			count++
+		}
+	}
+	if limits.MaxRules > 0 && count > limits.MaxRules {
+		violations = append(violations, LimitViolation{Limit: LimitMaxRules, Actual: count, Max: limits.MaxRules})
+	}
+
+	for _, r := range rules {
+		if r == nil {
+			continue
+		}
+		if limits.MaxGRLBytes > 0 && len(r.GRL) > limits.MaxGRLBytes {
+			violations = append(violations, LimitViolation{Limit: LimitMaxGRLBytes, Rule: r.Name, Actual: len(r.GRL), Max: limits.MaxGRLBytes})
+			// 超大的GRL不再分析嵌套深度
+			continue
+		}
+		if limits.MaxConditionDepth > 0 {
+			if depth := ConditionDepth(r.GRL); depth > limits.MaxConditionDepth {
+				violations = append(violations, LimitViolation{Limit: LimitMaxConditionDepth, Rule: r.Name, Actual: depth, Max: limits.MaxConditionDepth})
+			}
+		}
+	}
+	return violations
+}
+
+// ConditionDepth 计算GRL中when条件的最大括号嵌套深度
+//
+// 不含括号的条件深度为1，每层括号（包括函数调用的参数括号）加1；注释和字符串中的括号不计入，
+// 多条规则取最大值，没有when条件时为0。
+func ConditionDepth(grl string) int {
+	source := grlLineCommentRegex.ReplaceAllString(grl, "")
+	source = grlStringLiteralRegex.ReplaceAllString(source, `""`)
+
+	maxDepth := 0
+	for _, m := range grlWhenClauseRegex.FindAllStringSubmatch(source, -1) {
+		depth, clauseMax := 1, 1
+		for _, c := range m[1] {
+			switch c {
+			case '(':
+				depth++
+				if depth > clauseMax {
+					clauseMax = depth
+				}
+			case ')':
+				depth--
+			}
+		}
+		if clauseMax > maxDepth {
+			maxDepth = clauseMax
+		}
+	}
+	return maxDepth
+}
@@ -0,0 +1,55 @@
+package rule
+
+import (
+	"strings"
+	"testing"
+
+	"gitee.com/damengde/runehammer/config"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// TestCheckLimits 测试规则集限制检查
+func TestCheckLimits(t *testing.T) {
+	Convey("规则集限制检查", t, func() {
+		flat := &Rule{Name: "flat", Enabled: true, GRL: `rule Flat "平铺" {
+	when Params.amount > 0 && Params.age >= 18
+	then
+		Result["ok"] = true;
+		Retract("Flat");
+}`}
+		nested := &Rule{Name: "nested", Enabled: false, GRL: `rule Nested "嵌套" {
+	when (Params.a > 0 && (Params.b > 0 || (Len(Params.c) > 0))) // ((( 注释不计入
+	then
+		Result["note"] = "(((";
+		Retract("Nested");
+}`}
+
+		Convey("计算when条件的嵌套深度", func() {
+			So(ConditionDepth(flat.GRL), ShouldEqual, 1)
+			So(ConditionDepth(nested.GRL), ShouldEqual, 5)
+			So(ConditionDepth(flat.GRL+"\n"+nested.GRL), ShouldEqual, 5)
+			So(ConditionDepth(`// 没有规则`), ShouldEqual, 0)
+		})
+
+		Convey("未超出限制时返回空", func() {
+			So(CheckLimits([]*Rule{flat, nested}, config.RuleLimits{}), ShouldBeEmpty)
+			So(CheckLimits([]*Rule{flat, nested}, config.RuleLimits{MaxRules: 2, MaxConditionDepth: 5, MaxGRLBytes: 1000}), ShouldBeEmpty)
+		})
+
+		Convey("列出全部超限项，禁用规则同样计入", func() {
+			violations := CheckLimits([]*Rule{flat, nested, nil}, config.RuleLimits{MaxRules: 1, MaxConditionDepth: 3})
+			So(violations, ShouldHaveLength, 2)
+			So(violations[0], ShouldResemble, LimitViolation{Limit: LimitMaxRules, Actual: 2, Max: 1})
+			So(violations[1], ShouldResemble, LimitViolation{Limit: LimitMaxConditionDepth, Rule: "nested", Actual: 5, Max: 3})
+			So(violations[1].String(), ShouldContainSubstring, "嵌套深度 5")
+		})
+
+		Convey("GRL超出大小时不再检查嵌套深度", func() {
+			huge := &Rule{Name: "huge", GRL: `rule Huge "超大" { when ((((true)))) then Retract("Huge"); }` + strings.Repeat(" ", 100)}
+			violations := CheckLimits([]*Rule{huge}, config.RuleLimits{MaxConditionDepth: 2, MaxGRLBytes: 64})
+			So(violations, ShouldHaveLength, 1)
+			So(violations[0].Limit, ShouldEqual, LimitMaxGRLBytes)
+			So(violations[0].Actual, ShouldEqual, len(huge.GRL))
+		})
+	})
+}
@@ -4,6 +4,7 @@ package rule
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"gorm.io/gorm"
@@ -19,16 +20,24 @@ import (
 // 主要功能：存储GRL规则定义和元数据
 type Rule struct {
 	// 基础字段
-	ID      uint64 `gorm:"primaryKey;autoIncrement" json:"id"`      // 主键ID
-	BizCode string `gorm:"size:100;not null;index" json:"biz_code"` // 业务码，用于分组规则
-	Name    string `gorm:"size:200;not null" json:"name"`           // 规则名称
+	ID      uint64 `gorm:"primaryKey;autoIncrement" json:"id"`                                                  // 主键ID
+	BizCode string `gorm:"size:100;not null;index;index:idx_runehammer_rules_fetch,priority:1" json:"biz_code"` // 业务码，用于分组规则
+	Name    string `gorm:"size:200;not null" json:"name"`                                                       // 规则名称
 
 	// 规则内容
 	GRL string `gorm:"type:text;not null" json:"grl"` // GRL规则内容
 
 	// 版本和状态
-	Version int  `gorm:"default:1" json:"version"` // 规则版本号
-	Enabled bool `gorm:"not null" json:"enabled"`  // 是否启用
+	Version        int  `gorm:"default:1;index:idx_runehammer_rules_fetch,priority:3" json:"version"` // 规则版本号
+	Enabled        bool `gorm:"not null;index:idx_runehammer_rules_fetch,priority:2" json:"enabled"`  // 是否启用
+	RolloutPercent int  `json:"rollout_percent"`                                                      // 灰度放量百分比，1-99时仅对按放量键分桶命中的执行生效，0或>=100表示全量
+
+	// 环境
+	Environment string `gorm:"size:32;index" json:"environment"` // 规则变体所属环境，如 dev、staging、prod，为空表示默认变体
+
+	// 分类
+	Tags   []string `gorm:"type:text;serializer:json" json:"tags"`   // 规则标签，可用于规则选择器筛选执行的规则
+	Writes []string `gorm:"type:text;serializer:json" json:"writes"` // 声明写入的Result字段，为空表示不声明，用于写入检查和数据流分析
 
 	// 时间戳
 	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"` // 创建时间
@@ -38,6 +47,12 @@ type Rule struct {
 	Description string `gorm:"size:500" json:"description"` // 规则描述
 	CreatedBy   string `gorm:"size:100" json:"created_by"`  // 创建者
 	UpdatedBy   string `gorm:"size:100" json:"updated_by"`  // 更新者
+	SourceID    uint64 `gorm:"index" json:"source_id"`      // 复制来源规则ID，0表示非复制产生，用于追溯版本来源
+	Owner       string `gorm:"size:100" json:"owner"`       // 规则负责人，规则执行出错时附加到错误中
+	DocURL      string `gorm:"size:500" json:"doc_url"`     // 规则说明文档链接，规则执行出错时附加到错误中
+
+	// 运行时字段
+	Entries []string `gorm:"-" json:"entries,omitempty"` // GRL声明的规则条目名称，分页获取时代替GRL保留，GRL为空时使用
 }
 
 // TableName 自定义表名
@@ -45,6 +60,27 @@ func (Rule) TableName() string {
 	return "runehammer_rules"
 }
 
+// RuleTestCase 规则测试用例 - 与规则一同存储的输入/期望输出用例
+//
+// 表名：runehammer_rule_tests
+// Expected 只需列出要校验的结果字段，嵌套map按字段逐层比较，未列出的结果字段不参与比较。
+type RuleTestCase struct {
+	ID          uint64         `gorm:"primaryKey;autoIncrement" json:"id" yaml:"id"`                // 主键ID
+	BizCode     string         `gorm:"size:100;not null;index" json:"biz_code" yaml:"bizCode"`      // 业务码
+	Name        string         `gorm:"size:200;not null" json:"name" yaml:"name"`                   // 用例名称
+	Input       map[string]any `gorm:"type:text;serializer:json" json:"input" yaml:"input"`         // 执行输入
+	Expected    map[string]any `gorm:"type:text;serializer:json" json:"expected" yaml:"expected"`   // 期望的结果字段
+	Description string         `gorm:"size:500" json:"description,omitempty" yaml:"description"`    // 用例描述
+	CreatedBy   string         `gorm:"size:100" json:"created_by,omitempty" yaml:"createdBy"`       // 创建者
+	CreatedAt   time.Time      `gorm:"autoCreateTime" json:"created_at,omitempty" yaml:"createdAt"` // 创建时间
+	UpdatedAt   time.Time      `gorm:"autoUpdateTime" json:"updated_at,omitempty" yaml:"updatedAt"` // 更新时间
+}
+
+// TableName 自定义表名
+func (RuleTestCase) TableName() string {
+	return "runehammer_rule_tests"
+}
+
 // ============================================================================
 // 规则数据访问接口 - 统一的数据访问抽象层
 // ============================================================================
@@ -68,6 +104,59 @@ type RuleMapper interface {
 	FindByBizCode(ctx context.Context, bizCode string) ([]*Rule, error)
 }
 
+// PagedRuleMapper 分页规则数据访问接口 - 可选扩展，规则数量巨大的业务码按页获取，避免一次性加载全部行
+type PagedRuleMapper interface {
+	// FindByBizCodePaged 按页获取业务码的启用规则，顺序与FindByBizCode一致
+	//
+	// 参数:
+	//   ctx      - 上下文，用于超时控制和取消操作
+	//   bizCode  - 业务码
+	//   pageSize - 每页规则数
+	//   fn       - 每页回调一次，返回错误时停止获取并返回该错误
+	//
+	// 返回值:
+	//   error - 查询错误或回调错误
+	FindByBizCodePaged(ctx context.Context, bizCode string, pageSize int, fn func(page []*Rule) error) error
+}
+
+// RuleCloneMapper 规则复制数据访问接口 - 可选扩展，用于按业务码批量复制规则（如新租户初始化）
+type RuleCloneMapper interface {
+	// FindAllByBizCode 查找业务码的全部规则（含禁用规则），按ID升序
+	//
+	// 参数:
+	//   ctx     - 上下文，用于超时控制和取消操作
+	//   bizCode - 业务码
+	//
+	// 返回值:
+	//   []*Rule - 规则列表
+	//   error   - 查询错误
+	FindAllByBizCode(ctx context.Context, bizCode string) ([]*Rule, error)
+
+	// CreateRules 在同一事务中批量创建规则，成功后回填规则ID
+	//
+	// 参数:
+	//   ctx   - 上下文，用于超时控制和取消操作
+	//   rules - 待创建的规则
+	//
+	// 返回值:
+	//   error - 任一规则创建失败时整体回滚并返回错误
+	CreateRules(ctx context.Context, rules []*Rule) error
+}
+
+// RuleTestMapper 规则测试用例数据访问接口 - 可选扩展，用于读取与规则一同存储的测试用例
+type RuleTestMapper interface {
+	// FindTestsByBizCode 查找业务码的测试用例，按ID升序
+	//
+	// 参数:
+	//   ctx     - 上下文，用于超时控制和取消操作
+	//   bizCode - 业务码
+	//
+	// 返回值:
+	//   []*RuleTestCase - 测试用例列表
+	//   error           - 查询错误
+	FindTestsByBizCode(ctx context.Context, bizCode string) ([]*RuleTestCase, error)
+}
+
 // ============================================================================
 // 规则数据访问实现 - GORM实现
 // ============================================================================
@@ -98,14 +187,90 @@ func (r *ruleMapperImpl) FindByBizCode(ctx context.Context, bizCode string) ([]*
 	var rules []*Rule
 
 	// 查询启用的规则，按版本号降序排列
+	err := fetchQuery(r.db.WithContext(ctx), bizCode).Find(&rules).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// FindByBizCodePaged 按页获取业务码的启用规则
+func (r *ruleMapperImpl) FindByBizCodePaged(ctx context.Context, bizCode string, pageSize int, fn func(page []*Rule) error) error {
+	if pageSize <= 0 {
+		return fmt.Errorf("分页大小必须大于0")
+	}
+
+	// 按 (version, id) 游标分页，每页查询代价与页码无关；以id作为次级排序，保证分页结果稳定
+	var (
+		lastVersion int
+		lastID      uint64
+	)
+	for first := true; ; first = false {
+		var page []*Rule
+
+		query := fetchQuery(r.db.WithContext(ctx), bizCode)
+		if !first {
+			query = query.Where("version < ? OR (version = ? AND id < ?)", lastVersion, lastVersion, lastID)
+		}
+		err := query.
+			Order("id DESC").
+			Limit(pageSize).
+			Find(&page).Error
+		if err != nil {
+			return err
+		}
+
+		if len(page) > 0 {
+			lastVersion, lastID = page[len(page)-1].Version, page[len(page)-1].ID
+			if err := fn(page); err != nil {
+				return err
+			}
+		}
+		if len(page) < pageSize {
+			return nil
+		}
+	}
+}
+
+// FindAllByBizCode 查找业务码的全部规则（含禁用规则）
+func (r *ruleMapperImpl) FindAllByBizCode(ctx context.Context, bizCode string) ([]*Rule, error) {
+	var rules []*Rule
+
 	err := r.db.WithContext(ctx).
-		Where("biz_code = ? AND enabled = ?", bizCode, true).
-		Order("version DESC").
+		Where("biz_code = ?", bizCode).
+		Order("id ASC").
 		Find(&rules).Error
-
 	if err != nil {
 		return nil, err
 	}
 
 	return rules, nil
 }
+
+// CreateRules 在同一事务中批量创建规则
+func (r *ruleMapperImpl) CreateRules(ctx context.Context, rules []*Rule) error {
+	if len(rules) == 0 {
+		return nil
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return tx.Create(&rules).Error
+	})
+}
+
+// FindTestsByBizCode 查找业务码的测试用例
+func (r *ruleMapperImpl) FindTestsByBizCode(ctx context.Context, bizCode string) ([]*RuleTestCase, error) {
+	var tests []*RuleTestCase
+
+	err := r.db.WithContext(ctx).
+		Where("biz_code = ?", bizCode).
+		Order("id ASC").
+		Find(&tests).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return tests, nil
+}
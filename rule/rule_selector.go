@@ -0,0 +1,531 @@
+package rule
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ============================================================================
+// 规则选择器 - 以类SQL表达式按规则元数据选择要执行的规则
+// ============================================================================
+//
+// 语法:
+//
+//	expr       := term { OR term }
+//	term       := factor { AND factor }
+//	factor     := NOT factor | "(" expr ")" | comparison
+//	comparison := field op value | field IN "(" value { "," value } ")"
+//	op         := = | != | <> | > | >= | < | <= | CONTAINS
+//	value      := 'string' | number | true | false
+//
+// 字段: name、tags、priority、version、enabled、rollout_percent、description、
+// created_by、updated_by、id、source_id，关键字与字段名不区分大小写。
+// priority 取自GRL中的 salience（未声明时为0）；tags CONTAINS 判断是否包含标签，
+// tags IN 判断是否包含任一标签，字符串字段 CONTAINS 判断是否包含子串。
+//
+// 示例: tags CONTAINS 'fast' AND priority >= 50 AND NOT name IN ('legacy', 'debug')
+
+// saliencePattern 匹配GRL中的salience声明
+var saliencePattern = regexp.MustCompile(`(?i)\bsalience\s+(-?\d+)`)
+
+// RuleSalience 解析GRL的salience，未声明时返回0
+func RuleSalience(grl string) int {
+	m := saliencePattern.FindStringSubmatch(grl)
+	if m == nil {
+		return 0
+	}
+	salience, _ := strconv.Atoi(m[1])
+	return salience
+}
+
+// RuleSelector 编译后的规则选择器，可并发使用
+type RuleSelector struct {
+	source string
+	root   selectorNode
+}
+
+// ParseSelector 编译规则选择器表达式
+//
+// 参数:
+//
+//	selector - 选择器表达式，如 "tags CONTAINS 'fast' AND priority >= 50"
+//
+// 返回值:
+//
+//	*RuleSelector - 编译后的选择器
+//	error         - 语法错误或未知字段
+func ParseSelector(selector string) (*RuleSelector, error) {
+	tokens, err := lexSelector(selector)
+	if err != nil {
+		return nil, fmt.Errorf("规则选择器 %q 无效: %w", selector, err)
+	}
+	p := &selectorParser{tokens: tokens}
+	root, err := p.parseExpr()
+	if err == nil && p.peek().kind != tokenEOF {
+		err = fmt.Errorf("位置 %d 存在多余内容 %q", p.peek().pos, p.peek().text)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("规则选择器 %q 无效: %w", selector, err)
+	}
+	return &RuleSelector{source: selector, root: root}, nil
+}
+
+// String 返回选择器原始表达式
+func (s *RuleSelector) String() string {
+	return s.source
+}
+
+// Match 判断规则是否被选中
+func (s *RuleSelector) Match(r *Rule) bool {
+	if r == nil {
+		return false
+	}
+	return s.root.eval(r)
+}
+
+// Filter 返回被选中的规则，保持原有顺序
+func (s *RuleSelector) Filter(rules []*Rule) []*Rule {
+	selected := make([]*Rule, 0, len(rules))
+	for _, r := range rules {
+		if s.Match(r) {
+			selected = append(selected, r)
+		}
+	}
+	return selected
+}
+
+// ----------------------------------------------------------------------------
+// 词法分析
+// ----------------------------------------------------------------------------
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenOp
+	tokenLParen
+	tokenRParen
+	tokenComma
+)
+
+type selectorToken struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// lexSelector 将表达式切分为词法单元
+func lexSelector(input string) ([]selectorToken, error) {
+	var tokens []selectorToken
+	runes := []rune(input)
+	for i := 0; i < len(runes); {
+		c := runes[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '(':
+			tokens = append(tokens, selectorToken{tokenLParen, "(", i})
+			i++
+		case c == ')':
+			tokens = append(tokens, selectorToken{tokenRParen, ")", i})
+			i++
+		case c == ',':
+			tokens = append(tokens, selectorToken{tokenComma, ",", i})
+			i++
+		case c == '\'' || c == '"':
+			start := i
+			var sb strings.Builder
+			for i++; ; i++ {
+				if i >= len(runes) {
+					return nil, fmt.Errorf("位置 %d 的字符串未闭合", start)
+				}
+				if runes[i] == c {
+					// 连续两个引号表示引号本身
+					if i+1 < len(runes) && runes[i+1] == c {
+						sb.WriteRune(c)
+						i++
+						continue
+					}
+					i++
+					break
+				}
+				sb.WriteRune(runes[i])
+			}
+			tokens = append(tokens, selectorToken{tokenString, sb.String(), start})
+		case strings.ContainsRune("=!<>", c):
+			start := i
+			op := string(c)
+			if i+1 < len(runes) && (runes[i+1] == '=' || (c == '<' && runes[i+1] == '>')) {
+				op += string(runes[i+1])
+			}
+			if op == "!" {
+				return nil, fmt.Errorf("位置 %d 的操作符 ! 无效", start)
+			}
+			i += len(op)
+			tokens = append(tokens, selectorToken{tokenOp, op, start})
+		case unicode.IsDigit(c) || (c == '-' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			for i++; i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.'); i++ {
+			}
+			tokens = append(tokens, selectorToken{tokenNumber, string(runes[start:i]), start})
+		case unicode.IsLetter(c) || c == '_':
+			start := i
+			for i++; i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_'); i++ {
+			}
+			tokens = append(tokens, selectorToken{tokenIdent, string(runes[start:i]), start})
+		default:
+			return nil, fmt.Errorf("位置 %d 存在无效字符 %q", i, c)
+		}
+	}
+	return append(tokens, selectorToken{tokenEOF, "", len(runes)}), nil
+}
+
+// ----------------------------------------------------------------------------
+// 语法分析
+// ----------------------------------------------------------------------------
+
+type selectorParser struct {
+	tokens []selectorToken
+	pos    int
+}
+
+func (p *selectorParser) peek() selectorToken {
+	return p.tokens[p.pos]
+}
+
+func (p *selectorParser) next() selectorToken {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// keyword 当前单元是否为指定关键字（不区分大小写），是则消费
+func (p *selectorParser) keyword(word string) bool {
+	t := p.peek()
+	if t.kind == tokenIdent && strings.EqualFold(t.text, word) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *selectorParser) parseExpr() (selectorNode, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("OR") {
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{and: false, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *selectorParser) parseTerm() (selectorNode, error) {
+	left, err := p.parseFactor()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("AND") {
+		right, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{and: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *selectorParser) parseFactor() (selectorNode, error) {
+	if p.keyword("NOT") {
+		inner, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{inner: inner}, nil
+	}
+	if p.peek().kind == tokenLParen {
+		p.next()
+		inner, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if t := p.next(); t.kind != tokenRParen {
+			return nil, fmt.Errorf("位置 %d 缺少 )", t.pos)
+		}
+		return inner, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *selectorParser) parseComparison() (selectorNode, error) {
+	t := p.next()
+	if t.kind != tokenIdent {
+		return nil, fmt.Errorf("位置 %d 需要字段名，实际为 %q", t.pos, t.text)
+	}
+	field := strings.ToLower(t.text)
+	kind, ok := selectorFields[field]
+	if !ok {
+		return nil, fmt.Errorf("位置 %d 的字段 %s 未知", t.pos, t.text)
+	}
+
+	opToken := p.next()
+	var op string
+	switch {
+	case opToken.kind == tokenOp:
+		op = opToken.text
+		if op == "<>" {
+			op = "!="
+		}
+	case opToken.kind == tokenIdent && strings.EqualFold(opToken.text, "CONTAINS"):
+		op = "CONTAINS"
+	case opToken.kind == tokenIdent && strings.EqualFold(opToken.text, "IN"):
+		op = "IN"
+	default:
+		return nil, fmt.Errorf("位置 %d 需要操作符，实际为 %q", opToken.pos, opToken.text)
+	}
+
+	node := &comparisonNode{field: field, kind: kind, op: op}
+	if op == "IN" {
+		if t := p.next(); t.kind != tokenLParen {
+			return nil, fmt.Errorf("位置 %d 的IN后需要 (", t.pos)
+		}
+		for {
+			v, err := p.parseValue(kind)
+			if err != nil {
+				return nil, err
+			}
+			node.values = append(node.values, v)
+			t := p.next()
+			if t.kind == tokenRParen {
+				break
+			}
+			if t.kind != tokenComma {
+				return nil, fmt.Errorf("位置 %d 需要 , 或 )", t.pos)
+			}
+		}
+		return node, nil
+	}
+
+	if err := checkSelectorOp(field, kind, op, opToken.pos); err != nil {
+		return nil, err
+	}
+	valueKind := kind
+	if kind == fieldList {
+		valueKind = fieldString
+	}
+	v, err := p.parseValue(valueKind)
+	if err != nil {
+		return nil, err
+	}
+	node.values = []selectorValue{v}
+	return node, nil
+}
+
+// parseValue 解析字面量并检查与字段类型是否匹配
+func (p *selectorParser) parseValue(kind fieldKind) (selectorValue, error) {
+	t := p.next()
+	switch {
+	case t.kind == tokenString && (kind == fieldString || kind == fieldList):
+		return selectorValue{str: t.text}, nil
+	case t.kind == tokenNumber && kind == fieldNumber:
+		n, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return selectorValue{}, fmt.Errorf("位置 %d 的数值 %s 无效", t.pos, t.text)
+		}
+		return selectorValue{num: n}, nil
+	case t.kind == tokenIdent && kind == fieldBool && (strings.EqualFold(t.text, "true") || strings.EqualFold(t.text, "false")):
+		return selectorValue{boolean: strings.EqualFold(t.text, "true")}, nil
+	}
+	return selectorValue{}, fmt.Errorf("位置 %d 的值 %q 与字段类型不匹配", t.pos, t.text)
+}
+
+// checkSelectorOp 检查操作符是否适用于字段类型
+func checkSelectorOp(field string, kind fieldKind, op string, pos int) error {
+	valid := false
+	switch kind {
+	case fieldString:
+		valid = op != ">" && op != ">=" && op != "<" && op != "<="
+	case fieldNumber:
+		valid = op != "CONTAINS"
+	case fieldBool:
+		valid = op == "=" || op == "!="
+	case fieldList:
+		valid = op == "CONTAINS"
+	}
+	if !valid {
+		return fmt.Errorf("位置 %d 的操作符 %s 不适用于字段 %s", pos, op, field)
+	}
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+// 求值
+// ----------------------------------------------------------------------------
+
+type fieldKind int
+
+const (
+	fieldString fieldKind = iota
+	fieldNumber
+	fieldBool
+	fieldList
+)
+
+// selectorFields 可用字段及类型
+var selectorFields = map[string]fieldKind{
+	"name":            fieldString,
+	"description":     fieldString,
+	"created_by":      fieldString,
+	"updated_by":      fieldString,
+	"tags":            fieldList,
+	"priority":        fieldNumber,
+	"version":         fieldNumber,
+	"rollout_percent": fieldNumber,
+	"id":              fieldNumber,
+	"source_id":       fieldNumber,
+	"enabled":         fieldBool,
+}
+
+type selectorValue struct {
+	str     string
+	num     float64
+	boolean bool
+}
+
+type selectorNode interface {
+	eval(r *Rule) bool
+}
+
+type logicalNode struct {
+	and         bool
+	left, right selectorNode
+}
+
+func (n *logicalNode) eval(r *Rule) bool {
+	if n.and {
+		return n.left.eval(r) && n.right.eval(r)
+	}
+	return n.left.eval(r) || n.right.eval(r)
+}
+
+type notNode struct {
+	inner selectorNode
+}
+
+func (n *notNode) eval(r *Rule) bool {
+	return !n.inner.eval(r)
+}
+
+type comparisonNode struct {
+	field  string
+	kind   fieldKind
+	op     string
+	values []selectorValue
+}
+
+func (n *comparisonNode) eval(r *Rule) bool {
+	switch n.kind {
+	case fieldList:
+		for _, v := range n.values {
+			for _, tag := range r.Tags {
+				if tag == v.str {
+					return true
+				}
+			}
+		}
+		return false
+	case fieldString:
+		actual := n.stringField(r)
+		if n.op == "IN" {
+			for _, v := range n.values {
+				if actual == v.str {
+					return true
+				}
+			}
+			return false
+		}
+		switch n.op {
+		case "=":
+			return actual == n.values[0].str
+		case "!=":
+			return actual != n.values[0].str
+		case "CONTAINS":
+			return strings.Contains(actual, n.values[0].str)
+		}
+	case fieldNumber:
+		actual := n.numberField(r)
+		if n.op == "IN" {
+			for _, v := range n.values {
+				if actual == v.num {
+					return true
+				}
+			}
+			return false
+		}
+		expected := n.values[0].num
+		switch n.op {
+		case "=":
+			return actual == expected
+		case "!=":
+			return actual != expected
+		case ">":
+			return actual > expected
+		case ">=":
+			return actual >= expected
+		case "<":
+			return actual < expected
+		case "<=":
+			return actual <= expected
+		}
+	case fieldBool:
+		for _, v := range n.values {
+			if r.Enabled == v.boolean {
+				return n.op != "!="
+			}
+		}
+		return n.op == "!="
+	}
+	return false
+}
+
+func (n *comparisonNode) stringField(r *Rule) string {
+	switch n.field {
+	case "name":
+		return r.Name
+	case "description":
+		return r.Description
+	case "created_by":
+		return r.CreatedBy
+	case "updated_by":
+		return r.UpdatedBy
+	}
+	return ""
+}
+
+func (n *comparisonNode) numberField(r *Rule) float64 {
+	switch n.field {
+	case "priority":
+		return float64(RuleSalience(r.GRL))
+	case "version":
+		return float64(r.Version)
+	case "rollout_percent":
+		return float64(r.RolloutPercent)
+	case "id":
+		return float64(r.ID)
+	case "source_id":
+		return float64(r.SourceID)
+	}
+	return 0
+}
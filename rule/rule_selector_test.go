@@ -0,0 +1,82 @@
+package rule
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// TestRuleSelector 测试规则选择器
+func TestRuleSelector(t *testing.T) {
+	Convey("规则选择器", t, func() {
+		fast := &Rule{ID: 1, Name: "fast_check", Version: 3, Enabled: true, Tags: []string{"fast", "risk"},
+			GRL: `rule FastCheck "快速检查" salience 80 { when true then Retract("FastCheck"); }`, CreatedBy: "alice"}
+		slow := &Rule{ID: 2, Name: "deep_scan", Version: 1, Enabled: true, Tags: []string{"slow"}, RolloutPercent: 20,
+			GRL: `rule DeepScan "深度扫描" { when true then Retract("DeepScan"); }`, Description: "调用外部服务"}
+		legacy := &Rule{ID: 3, Name: "legacy", Version: 1, Enabled: false, Tags: []string{"fast"},
+			GRL: `rule Legacy "旧规则" SALIENCE 60 { when true then Retract("Legacy"); }`}
+		rules := []*Rule{fast, slow, legacy}
+
+		names := func(selector string) []string {
+			s, err := ParseSelector(selector)
+			So(err, ShouldBeNil)
+			var out []string
+			for _, r := range s.Filter(rules) {
+				out = append(out, r.Name)
+			}
+			return out
+		}
+
+		Convey("解析salience", func() {
+			So(RuleSalience(fast.GRL), ShouldEqual, 80)
+			So(RuleSalience(slow.GRL), ShouldEqual, 0)
+			So(RuleSalience(legacy.GRL), ShouldEqual, 60)
+			So(RuleSalience(`rule A "a" salience -5 { when true then Retract("A"); }`), ShouldEqual, -5)
+		})
+
+		Convey("标签与优先级组合", func() {
+			So(names("tags CONTAINS 'fast' AND priority >= 50"), ShouldResemble, []string{"fast_check", "legacy"})
+			So(names("tags CONTAINS 'fast' AND priority >= 70"), ShouldResemble, []string{"fast_check"})
+			So(names("tags IN ('slow', 'risk')"), ShouldResemble, []string{"fast_check", "deep_scan"})
+		})
+
+		Convey("逻辑运算与括号", func() {
+			So(names("name = 'deep_scan' OR version > 2"), ShouldResemble, []string{"fast_check", "deep_scan"})
+			So(names("NOT (tags CONTAINS 'fast')"), ShouldResemble, []string{"deep_scan"})
+			So(names("enabled = true AND (priority > 50 OR rollout_percent != 0)"), ShouldResemble, []string{"fast_check", "deep_scan"})
+		})
+
+		Convey("字符串与数值字段", func() {
+			So(names("description CONTAINS '外部'"), ShouldResemble, []string{"deep_scan"})
+			So(names("created_by <> ''"), ShouldResemble, []string{"fast_check"})
+			So(names("id IN (1, 3)"), ShouldResemble, []string{"fast_check", "legacy"})
+			So(names("name IN (\"legacy\")"), ShouldResemble, []string{"legacy"})
+			So(names("name = 'it''s'"), ShouldBeEmpty)
+		})
+
+		Convey("关键字不区分大小写", func() {
+			So(names("Tags contains 'slow' and Priority < 10"), ShouldResemble, []string{"deep_scan"})
+		})
+
+		Convey("语法错误", func() {
+			invalid := []string{
+				"",
+				"unknown = 1",
+				"priority >= 'high'",
+				"tags = 'fast'",
+				"priority CONTAINS 1",
+				"enabled > true",
+				"name = 'unclosed",
+				"(priority > 1",
+				"priority > 1 name = 'x'",
+				"priority ! 1",
+				"id IN (1 2)",
+				"name = 'x' AND",
+			}
+			for _, selector := range invalid {
+				_, err := ParseSelector(selector)
+				So(err, ShouldNotBeNil)
+			}
+		})
+	})
+}
@@ -0,0 +1,200 @@
+package rule
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// ============================================================================
+// 表结构检查 - 启动时核对数据库表与模型定义是否一致（不执行迁移），及早发现生产环境漏跑迁移
+// ============================================================================
+
+// ErrSchemaMismatch 数据库表结构与模型定义不一致
+var ErrSchemaMismatch = errors.New("数据库表结构与模型不一致")
+
+// SchemaMismatchKind 不一致类型
+type SchemaMismatchKind string
+
+// SchemaMismatchKind 不一致类型枚举
+const (
+	SchemaMissingTable  SchemaMismatchKind = "missing_table"  // 表不存在
+	SchemaMissingColumn SchemaMismatchKind = "missing_column" // 缺少字段
+	SchemaTypeMismatch  SchemaMismatchKind = "type_mismatch"  // 字段类型不兼容或长度不足
+	SchemaMissingIndex  SchemaMismatchKind = "missing_index"  // 缺少索引
+)
+
+// SchemaMismatch 表结构不一致项
+type SchemaMismatch struct {
+	Table    string             // 表名
+	Kind     SchemaMismatchKind // 不一致类型
+	Name     string             // 字段名或索引名，表不存在时为空
+	Expected string             // 模型期望的类型，仅类型不一致时填充
+	Actual   string             // 数据库中的实际类型，仅类型不一致时填充
+}
+
+// String 返回可读描述
+func (m SchemaMismatch) String() string {
+	switch m.Kind {
+	case SchemaMissingTable:
+		return fmt.Sprintf("表 %s 不存在", m.Table)
+	case SchemaMissingColumn:
+		return fmt.Sprintf("表 %s 缺少字段 %s", m.Table, m.Name)
+	case SchemaTypeMismatch:
+		return fmt.Sprintf("表 %s 字段 %s 类型为 %s，模型期望 %s", m.Table, m.Name, m.Actual, m.Expected)
+	case SchemaMissingIndex:
+		return fmt.Sprintf("表 %s 缺少索引 %s", m.Table, m.Name)
+	}
+	return fmt.Sprintf("表 %s %s %s", m.Table, m.Kind, m.Name)
+}
+
+// SchemaError 表结构检查错误 - 包含全部不一致项，可通过errors.As获取，errors.Is(err, ErrSchemaMismatch)判断
+type SchemaError struct {
+	Mismatches []SchemaMismatch
+}
+
+// Error 实现error接口，逐条列出不一致项
+func (e *SchemaError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s，共%d处（请执行数据库迁移或启用AutoMigrate）:", ErrSchemaMismatch.Error(), len(e.Mismatches))
+	for i, m := range e.Mismatches {
+		fmt.Fprintf(&b, " %d) %s;", i+1, m)
+	}
+	return b.String()
+}
+
+// Unwrap 支持errors.Is(err, ErrSchemaMismatch)
+func (e *SchemaError) Unwrap() error {
+	return ErrSchemaMismatch
+}
+
+// Models 规则引擎使用的数据表模型，迁移和表结构检查使用同一组模型
+func Models() []any {
+	return []any{&Rule{}, &RuleTestCase{}, &BizDisablement{}}
+}
+
+// CheckSchema 检查数据库表结构是否与模型一致 - 只读取表结构，不执行迁移
+//
+// 检查表是否存在、模型字段对应的列是否存在、列类型是否兼容（按数值、字符串、时间、二进制分类比较，
+// 字符串列长度不得小于模型声明的 size）以及模型声明的索引是否存在。数据库中多出的列和索引不视为不一致。
+//
+// 参数:
+//
+//	db     - 数据库连接
+//	models - 待检查的模型，为空时检查 Models()
+//
+// 返回值:
+//
+//	error - 存在不一致时返回*SchemaError，读取表结构失败时返回对应错误
+func CheckSchema(db *gorm.DB, models ...any) error {
+	if db == nil {
+		return fmt.Errorf("数据库连接为空")
+	}
+	if len(models) == 0 {
+		models = Models()
+	}
+
+	var mismatches []SchemaMismatch
+	for _, model := range models {
+		found, err := checkModelSchema(db, model)
+		if err != nil {
+			return err
+		}
+		mismatches = append(mismatches, found...)
+	}
+	if len(mismatches) > 0 {
+		return &SchemaError{Mismatches: mismatches}
+	}
+	return nil
+}
+
+// checkModelSchema 检查单个模型对应的表
+func checkModelSchema(db *gorm.DB, model any) ([]SchemaMismatch, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, fmt.Errorf("解析模型 %T 失败: %w", model, err)
+	}
+	table := stmt.Schema.Table
+
+	migrator := db.Migrator()
+	if !migrator.HasTable(model) {
+		return []SchemaMismatch{{Table: table, Kind: SchemaMissingTable}}, nil
+	}
+
+	columnTypes, err := migrator.ColumnTypes(model)
+	if err != nil {
+		return nil, fmt.Errorf("读取表 %s 结构失败: %w", table, err)
+	}
+	columns := make(map[string]gorm.ColumnType, len(columnTypes))
+	for _, ct := range columnTypes {
+		columns[strings.ToLower(ct.Name())] = ct
+	}
+
+	var mismatches []SchemaMismatch
+	for _, field := range stmt.Schema.Fields {
+		if field.DBName == "" || field.IgnoreMigration {
+			continue
+		}
+		ct, ok := columns[strings.ToLower(field.DBName)]
+		if !ok {
+			mismatches = append(mismatches, SchemaMismatch{Table: table, Kind: SchemaMissingColumn, Name: field.DBName})
+			continue
+		}
+		if mismatch, ok := checkColumnType(table, field, ct); !ok {
+			mismatches = append(mismatches, mismatch)
+		}
+	}
+
+	for _, index := range stmt.Schema.ParseIndexes() {
+		if !migrator.HasIndex(model, index.Name) {
+			mismatches = append(mismatches, SchemaMismatch{Table: table, Kind: SchemaMissingIndex, Name: index.Name})
+		}
+	}
+	return mismatches, nil
+}
+
+// checkColumnType 检查列类型是否兼容模型字段，无法识别的类型视为兼容
+func checkColumnType(table string, field *schema.Field, ct gorm.ColumnType) (SchemaMismatch, bool) {
+	expected := string(field.DataType)
+	actual := ct.DatabaseTypeName()
+	if full, ok := ct.ColumnType(); ok && full != "" {
+		actual = full
+	}
+	mismatch := SchemaMismatch{Table: table, Kind: SchemaTypeMismatch, Name: field.DBName, Expected: expected, Actual: actual}
+
+	want, got := typeCategory(expected), typeCategory(ct.DatabaseTypeName())
+	if want != "" && got != "" && want != got {
+		return mismatch, false
+	}
+	if want == "string" && field.Size > 0 {
+		if length, ok := ct.Length(); ok && length > 0 && length < int64(field.Size) {
+			mismatch.Expected = fmt.Sprintf("%s(%d)", expected, field.Size)
+			return mismatch, false
+		}
+	}
+	return mismatch, true
+}
+
+// typeCategory 将模型数据类型或数据库列类型归类，无法识别时返回空字符串
+func typeCategory(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if i := strings.IndexAny(name, "( "); i >= 0 {
+		name = name[:i]
+	}
+	switch name {
+	case "bool", "boolean", "int", "uint", "integer", "bigint", "smallint", "tinyint", "mediumint",
+		"float", "double", "real", "numeric", "decimal", "number":
+		return "number"
+	case "string", "char", "varchar", "nchar", "nvarchar", "text", "tinytext", "mediumtext", "longtext",
+		"clob", "json", "character":
+		return "string"
+	case "time", "datetime", "timestamp", "timestamptz", "date":
+		return "time"
+	case "bytes", "blob", "tinyblob", "mediumblob", "longblob", "binary", "varbinary", "bytea":
+		return "bytes"
+	}
+	return ""
+}
@@ -0,0 +1,82 @@
+package rule
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// TestCheckSchema 测试表结构检查
+func TestCheckSchema(t *testing.T) {
+	Convey("表结构检查", t, func() {
+		open := func(name string) *gorm.DB {
+			db, err := gorm.Open(sqlite.Open("file:"+name+"?mode=memory"), &gorm.Config{})
+			So(err, ShouldBeNil)
+			return db
+		}
+
+		Convey("迁移后的表结构一致", func() {
+			db := open("schema_ok")
+			So(db.AutoMigrate(Models()...), ShouldBeNil)
+			So(CheckSchema(db), ShouldBeNil)
+		})
+
+		Convey("未迁移时报告缺少的表", func() {
+			err := CheckSchema(open("schema_empty"))
+			So(errors.Is(err, ErrSchemaMismatch), ShouldBeTrue)
+
+			var schemaErr *SchemaError
+			So(errors.As(err, &schemaErr), ShouldBeTrue)
+			So(schemaErr.Mismatches, ShouldResemble, []SchemaMismatch{
+				{Table: "runehammer_rules", Kind: SchemaMissingTable},
+				{Table: "runehammer_rule_tests", Kind: SchemaMissingTable},
+				{Table: "runehammer_biz_disablements", Kind: SchemaMissingTable},
+			})
+			So(err.Error(), ShouldContainSubstring, "表 runehammer_rules 不存在")
+		})
+
+		Convey("报告缺少的字段、不兼容的类型和缺少的索引", func() {
+			db := open("schema_drift")
+			So(db.Exec(`CREATE TABLE runehammer_rules (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				biz_code VARCHAR(100) NOT NULL,
+				name VARCHAR(50) NOT NULL,
+				grl TEXT NOT NULL,
+				version INTEGER DEFAULT 1,
+				enabled DATETIME NOT NULL,
+				rollout_percent INTEGER,
+				environment VARCHAR(32),
+				tags TEXT,
+				writes TEXT,
+				created_at DATETIME,
+				updated_at DATETIME,
+				description VARCHAR(500),
+				created_by VARCHAR(100),
+				updated_by VARCHAR(100),
+				source_id INTEGER
+			)`).Error, ShouldBeNil)
+
+			var schemaErr *SchemaError
+			So(errors.As(CheckSchema(db, &Rule{}), &schemaErr), ShouldBeTrue)
+
+			kinds := make(map[string]SchemaMismatchKind)
+			for _, m := range schemaErr.Mismatches {
+				kinds[m.Name] = m.Kind
+			}
+			So(kinds["owner"], ShouldEqual, SchemaMissingColumn)
+			So(kinds["doc_url"], ShouldEqual, SchemaMissingColumn)
+			So(kinds["name"], ShouldEqual, SchemaTypeMismatch)
+			So(kinds["enabled"], ShouldEqual, SchemaTypeMismatch)
+			So(kinds["idx_runehammer_rules_biz_code"], ShouldEqual, SchemaMissingIndex)
+			So(kinds, ShouldNotContainKey, "grl")
+			So(kinds, ShouldNotContainKey, "tags")
+		})
+
+		Convey("数据库连接为空时返回错误", func() {
+			So(CheckSchema(nil), ShouldNotBeNil)
+		})
+	})
+}
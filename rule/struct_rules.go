@@ -0,0 +1,337 @@
+package rule
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ============================================================================
+// 结构体标签生成规则 - 由输入模型的 rule 标签生成字段校验规则，避免规则与模型脱节
+// ============================================================================
+//
+// 标签格式: `rule:"required,min=18,max=120"`，支持的选项:
+//
+//	required     字符串非空、指针/切片/映射非nil、数值非零
+//	min=N        数值不小于N，字符串/切片长度不小于N
+//	max=N        数值不大于N，字符串/切片长度不大于N
+//	oneof=a b c  取值必须为空格分隔的候选值之一
+//	pattern=RE   字符串必须匹配正则表达式（表达式中不能包含逗号）
+//	msg=TEXT     自定义错误消息，必须放在最后，可包含逗号
+//
+// 标签为 "-" 的字段跳过；嵌套结构体字段按路径递归展开。
+
+// StructRuleTag 结构体规则标签名
+const StructRuleTag = "rule"
+
+// 生成规则写入结果的键
+const (
+	StructRuleValidKey    = "valid"  // 校验失败时置为false
+	StructRuleErrorPrefix = "error." // 错误消息键前缀，后接字段路径
+)
+
+// structRulePriority 生成规则的优先级，先于业务规则执行
+const structRulePriority = 1000
+
+// StructRuleSet 由结构体标签生成的规则集
+type StructRuleSet struct {
+	BizCode     string           // 业务码
+	Root        string           // 输入变量名（结构体类型名小写，与引擎注入名称一致）
+	Validations []ValidationRule // 字段校验定义，每个带标签的字段一条
+	Rules       []StandardRule   // 标准规则，每个约束一条
+}
+
+// GenerateStructRules 读取结构体字段的 rule 标签生成校验规则
+//
+// 参数:
+//
+//	bizCode - 业务码，生成的规则归属该业务码
+//	sample  - 输入结构体样例（值或指针）
+//
+// 返回值:
+//
+//	*StructRuleSet - 生成的规则集
+//	error          - 非结构体或标签无效
+func GenerateStructRules(bizCode string, sample any) (*StructRuleSet, error) {
+	t := reflect.TypeOf(sample)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("结构体规则生成需要结构体样例，实际为 %T", sample)
+	}
+
+	root := strings.ToLower(t.Name())
+	if root == "" {
+		root = "Params"
+	}
+
+	set := &StructRuleSet{BizCode: bizCode, Root: root}
+	if err := set.collect(t, ""); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+// collect 递归收集字段标签
+func (s *StructRuleSet) collect(t reflect.Type, prefix string) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		path := prefix + field.Name
+		tag := field.Tag.Get(StructRuleTag)
+		if tag == "-" {
+			continue
+		}
+
+		ft := field.Type
+		if ft.Kind() == reflect.Struct && ft != reflect.TypeOf(time.Time{}) {
+			if err := s.collect(ft, path+"."); err != nil {
+				return err
+			}
+		}
+		if tag == "" {
+			continue
+		}
+
+		if err := s.addField(path, ft, tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addField 解析单个字段标签并生成校验定义和规则
+func (s *StructRuleSet) addField(path string, ft reflect.Type, tag string) error {
+	options, message := splitStructRuleTag(tag)
+	validation := ValidationRule{
+		Field:   path,
+		Rules:   options,
+		Message: message,
+		Level:   "error",
+	}
+
+	ref := s.Root + "." + path
+	for _, option := range options {
+		name, arg, _ := strings.Cut(option, "=")
+		violation, defaultMsg, err := structRuleViolation(ref, ft, name, arg)
+		if err != nil {
+			return fmt.Errorf("字段 %s 的规则标签 %q 无效: %w", path, option, err)
+		}
+		if violation == nil {
+			continue
+		}
+		if name == "required" {
+			validation.Required = true
+		}
+
+		msg := message
+		if msg == "" {
+			msg = path + " " + defaultMsg
+		}
+		s.Rules = append(s.Rules, StandardRule{
+			ID:          sanitizeStructRuleID(s.Root + "_" + path + "_" + name),
+			Name:        msg,
+			Description: msg,
+			Priority:    structRulePriority,
+			Enabled:     true,
+			Tags:        []string{"struct-validation"},
+			Conditions:  *violation,
+			Actions: []Action{
+				Assign("Result."+StructRuleValidKey, false),
+				Assign("Result."+StructRuleErrorPrefix+path, msg),
+			},
+		})
+	}
+
+	s.Validations = append(s.Validations, validation)
+	return nil
+}
+
+// splitStructRuleTag 拆分标签选项与自定义消息
+func splitStructRuleTag(tag string) ([]string, string) {
+	message := ""
+	if idx := strings.Index(tag, "msg="); idx >= 0 && (idx == 0 || tag[idx-1] == ',') {
+		message = tag[idx+len("msg="):]
+		tag = strings.TrimSuffix(tag[:idx], ",")
+	}
+
+	var options []string
+	for _, option := range strings.Split(tag, ",") {
+		if option = strings.TrimSpace(option); option != "" {
+			options = append(options, option)
+		}
+	}
+	return options, message
+}
+
+// structRuleViolation 生成约束被违反时成立的条件
+//
+// 返回值:
+//
+//	*Condition - 违反条件，约束对该字段类型无意义时为nil
+//	string     - 默认错误消息
+//	error      - 选项未知或参数无效
+func structRuleViolation(ref string, ft reflect.Type, name, arg string) (*Condition, string, error) {
+	kind := ft.Kind()
+	isNumber := isIntKind(kind) || isFloatKind(kind)
+	hasLen := kind == reflect.String || kind == reflect.Slice || kind == reflect.Map || kind == reflect.Array
+
+	switch name {
+	case "required":
+		switch {
+		case kind == reflect.String:
+			cond := Field(ref).Eq("")
+			return &cond, "不能为空", nil
+		case kind == reflect.Ptr || kind == reflect.Slice || kind == reflect.Map || kind == reflect.Interface:
+			// 转换器将nil输出为null，使用Grule内置函数IsNil判断
+			cond := Field("IsNil(" + ref + ")").Eq(true)
+			return &cond, "不能为空", nil
+		case isNumber:
+			cond := Field(ref).Eq(int64(0))
+			return &cond, "不能为零", nil
+		}
+		return nil, "", nil
+
+	case "min", "max":
+		op, msg := OpLessThan, "不能小于"
+		if name == "max" {
+			op, msg = OpGreaterThan, "不能大于"
+		}
+		switch {
+		case isNumber:
+			bound, err := structRuleNumber(kind, arg)
+			if err != nil {
+				return nil, "", err
+			}
+			cond := Field(ref).compare(op, bound)
+			return &cond, msg + arg, nil
+		case hasLen:
+			bound, err := strconv.ParseInt(arg, 10, 64)
+			if err != nil {
+				return nil, "", err
+			}
+			cond := Field(ref+".Len()").compare(op, bound)
+			return &cond, "长度" + msg + arg, nil
+		}
+		return nil, "", fmt.Errorf("类型 %s 不支持%s", ft, name)
+
+	case "oneof":
+		values := strings.Fields(arg)
+		if len(values) == 0 {
+			return nil, "", fmt.Errorf("oneof需要至少一个候选值")
+		}
+		children := make([]Condition, 0, len(values))
+		for _, value := range values {
+			// 转换器将含点号的字符串视为字段引用，此类候选值预先加引号
+			var right interface{} = value
+			if strings.Contains(value, ".") {
+				right = strconv.Quote(value)
+			}
+			if isNumber {
+				number, err := structRuleNumber(kind, value)
+				if err != nil {
+					return nil, "", err
+				}
+				right = number
+			} else if kind != reflect.String {
+				return nil, "", fmt.Errorf("类型 %s 不支持oneof", ft)
+			}
+			children = append(children, Field(ref).compare(OpNotEqual, right))
+		}
+		cond := AllOf(children...)
+		if len(children) == 1 {
+			cond = children[0]
+		}
+		return &cond, "必须为 " + strings.Join(values, "/") + " 之一", nil
+
+	case "pattern":
+		if kind != reflect.String {
+			return nil, "", fmt.Errorf("类型 %s 不支持pattern", ft)
+		}
+		if arg == "" {
+			return nil, "", fmt.Errorf("pattern不能为空")
+		}
+		cond := Field(ref + ".MatchString(" + strconv.Quote(arg) + ")").Eq(false)
+		return &cond, "格式不正确", nil
+	}
+
+	return nil, "", fmt.Errorf("未知选项 %s", name)
+}
+
+// structRuleNumber 按字段类型解析数值参数
+func structRuleNumber(kind reflect.Kind, arg string) (interface{}, error) {
+	if isIntKind(kind) {
+		return strconv.ParseInt(arg, 10, 64)
+	}
+	return strconv.ParseFloat(arg, 64)
+}
+
+// isIntKind 是否为整数类型
+func isIntKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// isFloatKind 是否为浮点类型
+func isFloatKind(kind reflect.Kind) bool {
+	return kind == reflect.Float32 || kind == reflect.Float64
+}
+
+// sanitizeStructRuleID 规则ID只保留字母、数字和下划线
+func sanitizeStructRuleID(id string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, id)
+}
+
+// ToRules 转换为归属业务码的规则记录，可直接写入规则表
+//
+// 参数:
+//
+//	converter - GRL转换器，为nil时使用默认转换器
+func (s *StructRuleSet) ToRules(converter *GRLConverter) ([]*Rule, error) {
+	if converter == nil {
+		converter = NewGRLConverter()
+	}
+
+	rules := make([]*Rule, 0, len(s.Rules))
+	for _, r := range s.Rules {
+		grl, err := converter.ConvertToGRL(r)
+		if err != nil {
+			return nil, fmt.Errorf("转换结构体规则 %s 失败: %w", r.ID, err)
+		}
+		rules = append(rules, &Rule{
+			BizCode:     s.BizCode,
+			Name:        r.ID,
+			GRL:         grl,
+			Version:     1,
+			Enabled:     true,
+			Description: r.Description,
+			Owner:       r.Owner,
+			DocURL:      r.DocURL,
+		})
+	}
+	return rules, nil
+}
+
+// ToBundle 转换为业务码规则包，便于跨环境迁移或导入动态引擎
+func (s *StructRuleSet) ToBundle(converter *GRLConverter) (*RuleBundle, error) {
+	rules, err := s.ToRules(converter)
+	if err != nil {
+		return nil, err
+	}
+	return NewRuleBundle(s.BizCode, rules), nil
+}
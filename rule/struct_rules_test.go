@@ -0,0 +1,116 @@
+package rule
+
+import (
+	"testing"
+
+	"github.com/hyperjumptech/grule-rule-engine/ast"
+	"github.com/hyperjumptech/grule-rule-engine/builder"
+	"github.com/hyperjumptech/grule-rule-engine/engine"
+	"github.com/hyperjumptech/grule-rule-engine/pkg"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type structRuleAddress struct {
+	City string `rule:"required"`
+}
+
+type StructRuleUser struct {
+	Name    string            `rule:"required,max=8"`
+	Age     int               `rule:"min=18,max=120,msg=年龄需在18到120之间, 含边界"`
+	Level   string            `rule:"oneof=gold silver v1.0"`
+	Email   string            `rule:"pattern=^[a-z]+@example\\.com$"`
+	Tags    []string          `rule:"required"`
+	Address structRuleAddress // 嵌套结构体按路径展开
+	Note    string            `rule:"-"`
+	secret  string            `rule:"required"`
+}
+
+// execStructRules 编译生成的规则并执行，返回结果
+func execStructRules(rules []*Rule, user *StructRuleUser) map[string]interface{} {
+	lib := ast.NewKnowledgeLibrary()
+	rb := builder.NewRuleBuilder(lib)
+	for _, r := range rules {
+		So(rb.BuildRuleFromResource("struct_biz", "1.0.0", pkg.NewBytesResource([]byte(r.GRL))), ShouldBeNil)
+	}
+	kb, err := lib.NewKnowledgeBaseInstance("struct_biz", "1.0.0")
+	So(err, ShouldBeNil)
+
+	result := map[string]interface{}{}
+	dataCtx := ast.NewDataContext()
+	So(dataCtx.Add("structruleuser", user), ShouldBeNil)
+	So(dataCtx.Add("Result", result), ShouldBeNil)
+	So(engine.NewGruleEngine().Execute(dataCtx, kb), ShouldBeNil)
+	return result
+}
+
+// TestGenerateStructRules 测试结构体标签生成规则
+func TestGenerateStructRules(t *testing.T) {
+	Convey("结构体标签生成规则", t, func() {
+		set, err := GenerateStructRules("struct_biz", &StructRuleUser{})
+		So(err, ShouldBeNil)
+		So(set.Root, ShouldEqual, "structruleuser")
+
+		Convey("生成校验定义", func() {
+			fields := make([]string, 0, len(set.Validations))
+			for _, v := range set.Validations {
+				fields = append(fields, v.Field)
+			}
+			So(fields, ShouldResemble, []string{"Name", "Age", "Level", "Email", "Tags", "Address.City"})
+			So(set.Validations[0].Required, ShouldBeTrue)
+			So(set.Validations[1].Rules, ShouldResemble, []string{"min=18", "max=120"})
+			So(set.Validations[1].Message, ShouldEqual, "年龄需在18到120之间, 含边界")
+			So(set.Rules, ShouldHaveLength, 8)
+		})
+
+		rules, err := set.ToRules(nil)
+		So(err, ShouldBeNil)
+		for _, r := range rules {
+			So(r.BizCode, ShouldEqual, "struct_biz")
+		}
+
+		Convey("合法输入不产生错误", func() {
+			result := execStructRules(rules, &StructRuleUser{
+				Name: "alice", Age: 30, Level: "v1.0", Email: "alice@example.com",
+				Tags: []string{"a"}, Address: structRuleAddress{City: "杭州"},
+			})
+			So(result, ShouldBeEmpty)
+		})
+
+		Convey("非法输入写入错误消息", func() {
+			result := execStructRules(rules, &StructRuleUser{
+				Name: "a-very-long-name", Age: 10, Level: "bronze", Email: "bob@test.com",
+			})
+			So(result[StructRuleValidKey], ShouldEqual, false)
+			So(result["error.Name"], ShouldEqual, "Name 长度不能大于8")
+			So(result["error.Age"], ShouldEqual, "年龄需在18到120之间, 含边界")
+			So(result["error.Level"], ShouldEqual, "Level 必须为 gold/silver/v1.0 之一")
+			So(result["error.Email"], ShouldEqual, "Email 格式不正确")
+			So(result["error.Tags"], ShouldEqual, "Tags 不能为空")
+			So(result["error.Address.City"], ShouldEqual, "Address.City 不能为空")
+		})
+
+		Convey("导出业务码规则包", func() {
+			bundle, err := set.ToBundle(nil)
+			So(err, ShouldBeNil)
+			So(bundle.BizCode, ShouldEqual, "struct_biz")
+			So(bundle.Rules, ShouldHaveLength, 8)
+			So(bundle.Manifest.RequiredFunctions, ShouldContain, "IsNil")
+		})
+
+		Convey("无效输入", func() {
+			_, err := GenerateStructRules("biz", map[string]any{})
+			So(err, ShouldNotBeNil)
+
+			_, err = GenerateStructRules("biz", struct {
+				Age int `rule:"unknown"`
+			}{})
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "未知选项 unknown")
+
+			_, err = GenerateStructRules("biz", struct {
+				Age int `rule:"min=abc"`
+			}{})
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
@@ -0,0 +1,77 @@
+package rule
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ============================================================================
+// 动作值模板 - Log/Alert/Assign 的字符串值中的 ${path} 在执行时按数据上下文取值
+// ============================================================================
+//
+// 变量首段为数据上下文中的名称（Params、Result、结构体事实名）时从该对象取值，否则从Params取值，
+// 如 ${user.id}、${Params.amount}、${Result.score}。$${path} 转义为字面量 ${path}。
+// 无法取值的变量保留原文；严格模式下转换时校验变量首段必须是声明的变量前缀、Params或Result。
+
+// TemplatePattern 匹配模板变量 ${path} 及其转义形式 $${path}
+var TemplatePattern = regexp.MustCompile(`\$?\$\{\s*([^}\s]+)\s*\}`)
+
+// IsEscapedTemplate 判断匹配结果是否为转义的模板变量 $${path}
+func IsEscapedTemplate(match string) bool {
+	return strings.HasPrefix(match, "$$")
+}
+
+// TemplateVariables 提取文本中的模板变量路径，按出现顺序去重，不含转义的变量
+func TemplateVariables(text string) []string {
+	var paths []string
+	seen := make(map[string]bool)
+	for _, match := range TemplatePattern.FindAllStringSubmatch(text, -1) {
+		if IsEscapedTemplate(match[0]) || seen[match[1]] {
+			continue
+		}
+		seen[match[1]] = true
+		paths = append(paths, match[1])
+	}
+	return paths
+}
+
+// checkTemplate 严格模式下校验模板变量 - 首段必须是声明的变量前缀、Params或Result
+func (c *GRLConverter) checkTemplate(text string) error {
+	if !c.config.StrictMode {
+		return nil
+	}
+	for _, path := range TemplateVariables(text) {
+		root, _, _ := strings.Cut(path, ".")
+		if _, ok := c.config.VariablePrefix[root]; ok || root == "Params" || root == "Result" {
+			continue
+		}
+		return fmt.Errorf("严格模式下模板变量 ${%s} 不在声明的变量前缀内", path)
+	}
+	return nil
+}
+
+// convertTemplate 转换日志、告警消息 - 转义为GRL字符串，模板由执行时的日志函数插值
+func (c *GRLConverter) convertTemplate(value interface{}) (string, error) {
+	text := fmt.Sprint(value)
+	if err := c.checkTemplate(text); err != nil {
+		return "", err
+	}
+	return strconv.Quote(text), nil
+}
+
+// convertAssignValue 转换赋值的值 - 含模板的字符串生成 Template("...") 调用，执行时插值
+func (c *GRLConverter) convertAssignValue(value interface{}) (string, error) {
+	if unit, ok := unitOperand(value); ok {
+		return c.convertUnit(unit)
+	}
+	text, ok := value.(string)
+	if !ok || !TemplatePattern.MatchString(text) {
+		return c.convertValue(value), nil
+	}
+	if err := c.checkTemplate(text); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Template(%s)", strconv.Quote(text)), nil
+}
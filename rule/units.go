@@ -0,0 +1,208 @@
+package rule
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ============================================================================
+// 数值容差与单位 - 避免金额等浮点比较的精度问题，并在规则定义中显式声明单位
+// ============================================================================
+//
+// approxEq 操作符比较 |left - right| <= epsilon，右操作数可以是:
+//
+//	Approx(10.5, 0.01)  显式容差
+//	Money("USD", 10.5)  金额，容差为该币种最小货币单位的一半
+//	10.5                其他数值，容差为 DefaultEpsilon
+//
+// Money/Percent 可作为任意比较或赋值的操作数，转换为GRL中的 Money("USD", 10.5)、Percent(5) 调用，
+// 执行时分别返回按币种精度舍入的金额和比例值（5% -> 0.05）。右操作数为金额的 ==/!= 条件自动按容差比较。
+// JSON定义中使用对象形式: {"value": 10.5, "epsilon": 0.01}、{"currency": "USD", "amount": 10.5}、{"percent": 5}。
+
+// OpApproxEq 近似相等操作符
+const OpApproxEq Operator = "approxEq"
+
+// DefaultEpsilon approxEq 未指定容差时的默认容差
+const DefaultEpsilon = 1e-9
+
+// Tolerance 带容差的比较值
+type Tolerance struct {
+	Value   interface{} `json:"value" yaml:"value"`     // 比较值
+	Epsilon float64     `json:"epsilon" yaml:"epsilon"` // 容差，不大于0时使用 DefaultEpsilon
+}
+
+// Approx 创建带容差的比较值，用作 approxEq 的右操作数
+func Approx(value interface{}, epsilon float64) Tolerance {
+	return Tolerance{Value: value, Epsilon: epsilon}
+}
+
+// MoneyValue 带币种的金额
+type MoneyValue struct {
+	Currency string  `json:"currency" yaml:"currency"` // ISO 4217 币种代码，如 USD、CNY
+	Amount   float64 `json:"amount" yaml:"amount"`     // 金额，按币种精度舍入
+}
+
+// Money 创建金额值，如 Money("USD", 10.5)
+func Money(currency string, amount float64) MoneyValue {
+	return MoneyValue{Currency: strings.ToUpper(currency), Amount: amount}
+}
+
+// Epsilon 金额比较容差 - 最小货币单位的一半
+func (m MoneyValue) Epsilon() float64 {
+	return math.Pow10(-CurrencyDigits(m.Currency)) / 2
+}
+
+// PercentValue 百分比
+type PercentValue struct {
+	Percent float64 `json:"percent" yaml:"percent"` // 百分数，如 5 表示 5%
+}
+
+// Percent 创建百分比值，如 Percent(5) 表示 5%，执行时为 0.05
+func Percent(percent float64) PercentValue {
+	return PercentValue{Percent: percent}
+}
+
+// Ratio 百分比对应的比例值
+func (p PercentValue) Ratio() float64 {
+	return p.Percent / 100
+}
+
+// currencyDigits 小数位数不为2的币种
+var currencyDigits = map[string]int{
+	"JPY": 0, "KRW": 0, "VND": 0, "CLP": 0, "ISK": 0, "UGX": 0,
+	"BHD": 3, "KWD": 3, "OMR": 3, "JOD": 3, "TND": 3, "LYD": 3, "IQD": 3,
+}
+
+// CurrencyDigits 币种的小数位数，未登记的币种为2位
+func CurrencyDigits(currency string) int {
+	if digits, ok := currencyDigits[strings.ToUpper(currency)]; ok {
+		return digits
+	}
+	return 2
+}
+
+// RoundMoney 按币种精度舍入金额
+func RoundMoney(currency string, amount float64) float64 {
+	scale := math.Pow10(CurrencyDigits(currency))
+	return math.Round(amount*scale) / scale
+}
+
+// validCurrency 币种代码是否为3位字母
+func validCurrency(currency string) bool {
+	if len(currency) != 3 {
+		return false
+	}
+	for _, r := range currency {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// unitOperand 识别单位操作数 - Go值或JSON解码后的对象形式
+func unitOperand(operand interface{}) (interface{}, bool) {
+	switch v := operand.(type) {
+	case Tolerance, MoneyValue, PercentValue:
+		return v, true
+	case *MoneyValue:
+		return *v, v != nil
+	case *PercentValue:
+		return *v, v != nil
+	case map[string]interface{}:
+		if currency, ok := v["currency"].(string); ok {
+			if amount, ok := toFloat(v["amount"]); ok && len(v) == 2 {
+				return Money(currency, amount), true
+			}
+		}
+		if percent, ok := toFloat(v["percent"]); ok && len(v) == 1 {
+			return Percent(percent), true
+		}
+		if value, ok := v["value"]; ok && len(v) <= 2 {
+			epsilon, _ := toFloat(v["epsilon"])
+			if inner, ok := unitOperand(value); ok {
+				value = inner
+			}
+			return Approx(value, epsilon), true
+		}
+	}
+	return nil, false
+}
+
+// convertUnit 转换单位操作数 - 金额和百分比生成执行时函数调用
+func (c *GRLConverter) convertUnit(unit interface{}) (string, error) {
+	switch v := unit.(type) {
+	case MoneyValue:
+		if !validCurrency(v.Currency) {
+			return "", fmt.Errorf("无效的币种代码: %q", v.Currency)
+		}
+		return fmt.Sprintf("Money(%q, %s)", v.Currency, formatFloat(v.Amount)), nil
+	case PercentValue:
+		return fmt.Sprintf("Percent(%s)", formatFloat(v.Percent)), nil
+	default:
+		return "", fmt.Errorf("容差值只能用于approxEq操作符")
+	}
+}
+
+// convertApproxCondition 转换近似相等条件 - 生成 ApproxEq(left, right, epsilon)
+func (c *GRLConverter) convertApproxCondition(left string, right interface{}, negate bool, defs Definitions) (string, error) {
+	epsilon := DefaultEpsilon
+	switch v := right.(type) {
+	case Tolerance:
+		right = v.Value
+		if inner, ok := unitOperand(v.Value); ok {
+			right = inner
+		}
+		if v.Epsilon > 0 {
+			epsilon = v.Epsilon
+		} else if money, ok := right.(MoneyValue); ok {
+			epsilon = money.Epsilon()
+		}
+	case MoneyValue:
+		epsilon = v.Epsilon()
+	}
+	if _, ok := right.(Tolerance); ok {
+		return "", fmt.Errorf("容差值不能嵌套")
+	}
+
+	value, err := c.convertOperand(right, defs)
+	if err != nil {
+		return "", fmt.Errorf("转换右操作数失败: %w", err)
+	}
+	expr := fmt.Sprintf("ApproxEq(%s, %s, %s)", left, value, formatFloat(epsilon))
+	if negate {
+		return "!" + expr, nil
+	}
+	return expr, nil
+}
+
+// formatFloat 以最短形式输出浮点数，整数值补 .0 使GRL中为浮点字面量
+func formatFloat(f float64) string {
+	s := strconv.FormatFloat(f, 'g', -1, 64)
+	if strings.ContainsAny(s, "e") {
+		s = strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	if !strings.Contains(s, ".") {
+		s += ".0"
+	}
+	return s
+}
+
+// toFloat 数值转换为float64
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	}
+	return 0, false
+}
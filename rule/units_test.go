@@ -0,0 +1,97 @@
+package rule
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// TestUnits 测试数值容差与单位转换
+func TestUnits(t *testing.T) {
+	Convey("数值容差与单位", t, func() {
+		converter := NewGRLConverter()
+		convert := func(cond Condition) (string, error) {
+			return converter.convertCondition(cond, Definitions{})
+		}
+
+		Convey("approxEq生成带容差的函数调用", func() {
+			grl, err := convert(Field("order.amount").ApproxEq(10.5, 0.01))
+			So(err, ShouldBeNil)
+			So(grl, ShouldEqual, "ApproxEq(order.amount, 10.5, 0.01)")
+
+			grl, err = convert(Condition{Type: ConditionTypeSimple, Left: "order.amount", Operator: OpApproxEq, Right: 10})
+			So(err, ShouldBeNil)
+			So(grl, ShouldEqual, "ApproxEq(order.amount, 10, 0.000000001)")
+		})
+
+		Convey("金额按币种精度确定容差", func() {
+			grl, err := convert(Field("order.amount").Eq(Money("usd", 10.5)))
+			So(err, ShouldBeNil)
+			So(grl, ShouldEqual, `ApproxEq(order.amount, Money("USD", 10.5), 0.005)`)
+
+			grl, err = convert(Field("order.amount").Ne(Money("JPY", 100)))
+			So(err, ShouldBeNil)
+			So(grl, ShouldEqual, `!ApproxEq(order.amount, Money("JPY", 100.0), 0.5)`)
+
+			grl, err = convert(Field("order.amount").ApproxEq(Money("KWD", 1), 0))
+			So(err, ShouldBeNil)
+			So(grl, ShouldEqual, `ApproxEq(order.amount, Money("KWD", 1.0), 0.0005)`)
+
+			grl, err = convert(Field("order.amount").Gt(Money("CNY", 100)))
+			So(err, ShouldBeNil)
+			So(grl, ShouldEqual, `order.amount > Money("CNY", 100.0)`)
+		})
+
+		Convey("百分比转换为比例函数", func() {
+			grl, err := convert(Field("order.rate").Lte(Percent(5)))
+			So(err, ShouldBeNil)
+			So(grl, ShouldEqual, "order.rate <= Percent(5.0)")
+
+			action, err := converter.convertAction(Action{Type: ActionTypeAssign, Target: "Result.rate", Value: Percent(2.5)}, Definitions{})
+			So(err, ShouldBeNil)
+			So(action, ShouldEqual, `Result["rate"] = Percent(2.5)`)
+			So(Percent(2.5).Ratio(), ShouldEqual, 0.025)
+		})
+
+		Convey("JSON定义使用对象形式", func() {
+			var cond Condition
+			So(json.Unmarshal([]byte(`{"type":"simple","left":"order.amount","operator":"approxEq","right":{"value":{"currency":"USD","amount":10.5},"epsilon":0.02}}`), &cond), ShouldBeNil)
+			grl, err := convert(cond)
+			So(err, ShouldBeNil)
+			So(grl, ShouldEqual, `ApproxEq(order.amount, Money("USD", 10.5), 0.02)`)
+
+			data, err := json.Marshal(Field("order.rate").Gte(Percent(5)))
+			So(err, ShouldBeNil)
+			So(json.Unmarshal(data, &cond), ShouldBeNil)
+			grl, err = convert(cond)
+			So(err, ShouldBeNil)
+			So(grl, ShouldEqual, "order.rate >= Percent(5.0)")
+		})
+
+		Convey("无效的单位值报错", func() {
+			_, err := convert(Field("order.amount").Eq(Money("US", 1)))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "无效的币种代码")
+
+			_, err = convert(Field("order.amount").Gt(Approx(1, 0.1)))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "approxEq")
+		})
+
+		Convey("严格模式允许生成的单位函数", func() {
+			strict := NewGRLConverter(ConverterConfig{StrictMode: true})
+			r := NewStandardRule("price", "价格")
+			r.Conditions = AllOf(Field("order.amount").Eq(Money("USD", 9.99)), Field("order.rate").Gt(Percent(1)))
+			r.Actions = []Action{{Type: ActionTypeAssign, Target: "Result.ok", Value: true}}
+			_, err := strict.ConvertRule(*r, Definitions{})
+			So(err, ShouldBeNil)
+		})
+
+		Convey("按币种精度舍入", func() {
+			So(RoundMoney("USD", 10.555), ShouldEqual, 10.56)
+			So(RoundMoney("JPY", 100.4), ShouldEqual, 100)
+			So(CurrencyDigits("bhd"), ShouldEqual, 3)
+		})
+	})
+}
@@ -3,15 +3,22 @@ package runehammer
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"iter"
 	"reflect"
 	"sync"
 	"time"
 
+	"gitee.com/damengde/runehammer/alert"
 	"gitee.com/damengde/runehammer/cache"
 	"gitee.com/damengde/runehammer/config"
 	"gitee.com/damengde/runehammer/engine"
+	"gitee.com/damengde/runehammer/lock"
 	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/pubsub"
 	"gitee.com/damengde/runehammer/rule"
 	"github.com/hyperjumptech/grule-rule-engine/ast"
 	"github.com/robfig/cron/v3"
@@ -37,22 +44,27 @@ import (
 //   - 支持泛型结果类型
 //   - 自动缓存和同步
 //   - 上下文传递和超时控制
+//
+// 诊断、运维、规则分析等能力不在本接口中，以可选接口提供（见 capabilities.go），
+// New 返回的引擎全部实现，通过类型断言获取，如 engine.(KillSwitch).Disable(ctx, bizCode, reason)。
 type Engine[T any] interface {
 	// Exec 执行规则 - 根据业务码执行对应的规则集
 	//
 	// 参数:
-	//   ctx     - 上下文，用于超时控制和取消操作
+	//   ctx     - 上下文，用于超时控制和取消操作；规则执行期间同样生效，取消后在下一条规则求值前停止
 	//   bizCode - 业务码，用于标识规则集合
 	//   input   - 输入数据，支持map、结构体或其他类型
+	//   opts    - 执行选项，如幂等键
 	//
 	// 返回值:
 	//   T     - 规则执行结果，类型由泛型参数决定
-	//   error - 执行错误
+	//   error - 执行错误，上下文取消或超时时包装 ctx.Err()
 	//
 	// 使用示例:
 	//   engine := New[MyResult]()
 	//   result, err := engine.Exec(ctx, "USER_VALIDATE", userInput)
-	Exec(ctx context.Context, bizCode string, input any) (T, error)
+	//   result, err := engine.Exec(ctx, "USER_VALIDATE", userInput, WithIdempotencyKey(requestID))
+	Exec(ctx context.Context, bizCode string, input any, opts ...ExecOption) (T, error)
 
 	// Close 关闭引擎 - 释放所有资源
 	//
@@ -72,6 +84,9 @@ type Engine[T any] interface {
 //   - 执行任意业务规则
 //   - 返回通用map结果
 //   - 支持运行时类型转换
+//
+// 与 Engine 相同，其他能力以可选接口提供，NewBaseEngine 返回的引擎全部实现，
+// 其中会话、流式结果接口为 SessionExecutor[map[string]interface{}]。
 type BaseEngine interface {
 	// ExecRaw 执行规则并返回原始结果
 	//
@@ -79,11 +94,12 @@ type BaseEngine interface {
 	//   ctx     - 上下文，用于超时控制和取消操作
 	//   bizCode - 业务码，用于标识规则集合
 	//   input   - 输入数据，支持map、结构体或其他类型
+	//   opts    - 执行选项
 	//
 	// 返回值:
 	//   map[string]interface{} - 规则执行的原始结果
 	//   error                  - 执行错误
-	ExecRaw(ctx context.Context, bizCode string, input any) (map[string]interface{}, error)
+	ExecRaw(ctx context.Context, bizCode string, input any, opts ...ExecOption) (map[string]interface{}, error)
 
 	// Close 关闭引擎 - 释放所有资源
 	Close() error
@@ -101,7 +117,8 @@ type BaseEngine interface {
 //	userEngine := &TypedEngine[UserResult]{base: baseEngine}
 //	result, err := userEngine.Exec(ctx, "bizCode", input)
 type TypedEngine[T any] struct {
-	base BaseEngine
+	base   BaseEngine
+	shared bool // 是否为 EngineManager 的视图，视图不关闭共享引擎
 }
 
 // Exec 执行规则并返回强类型结果
@@ -111,16 +128,17 @@ type TypedEngine[T any] struct {
 //	ctx     - 上下文，用于超时控制和取消操作
 //	bizCode - 业务码，用于标识规则集合
 //	input   - 输入数据，支持map、结构体或其他类型
+//	opts    - 执行选项
 //
 // 返回值:
 //
 //	T     - 强类型的规则执行结果
 //	error - 执行错误
-func (te *TypedEngine[T]) Exec(ctx context.Context, bizCode string, input any) (T, error) {
+func (te *TypedEngine[T]) Exec(ctx context.Context, bizCode string, input any, opts ...ExecOption) (T, error) {
 	var zero T
 
 	// 1. 执行原始规则
-	rawResult, err := te.base.ExecRaw(ctx, bizCode, input)
+	rawResult, err := te.base.ExecRaw(ctx, bizCode, input, opts...)
 	if err != nil {
 		return zero, err
 	}
@@ -129,8 +147,59 @@ func (te *TypedEngine[T]) Exec(ctx context.Context, bizCode string, input any) (
 	return convertToType[T](rawResult)
 }
 
-// Close 关闭引擎
+// ExecVersion 按指定规则集版本执行
+func (te *TypedEngine[T]) ExecVersion(ctx context.Context, bizCode string, version int, input any, opts ...ExecOption) (T, error) {
+	return te.Exec(ctx, bizCode, input, append(append([]ExecOption(nil), opts...), WithVersion(version))...)
+}
+
+// ExecWhere 只执行满足选择器的规则
+func (te *TypedEngine[T]) ExecWhere(ctx context.Context, bizCode string, selector string, input any, opts ...ExecOption) (T, error) {
+	return te.Exec(ctx, bizCode, input, append(append([]ExecOption(nil), opts...), WithSelector(selector))...)
+}
+
+// NewSession 创建执行会话，会话中的执行返回强类型结果
+func (te *TypedEngine[T]) NewSession(ctx context.Context, input any) *Session[T] {
+	return engine.NewSession[T](ctx, input, te.Exec)
+}
+
+// StreamResults 以迭代器流式获取规则产出的结果元素，元素不做类型转换
+//
+// 底层引擎未实现 SessionExecutor 时迭代器产出 errors.ErrUnsupported
+func (te *TypedEngine[T]) StreamResults(ctx context.Context, bizCode string, input any, key string, maxResults int, opts ...ExecOption) iter.Seq2[any, error] {
+	streamer, ok := te.base.(SessionExecutor[map[string]interface{}])
+	if !ok {
+		return func(yield func(any, error) bool) {
+			yield(nil, fmt.Errorf("底层引擎不支持流式结果: %w", errors.ErrUnsupported))
+		}
+	}
+	return streamer.StreamResults(ctx, bizCode, input, key, maxResults, opts...)
+}
+
+// Explain 执行规则并解释每个Result字段由哪些规则写入
+//
+// 底层引擎未实现 Explainer 时返回 errors.ErrUnsupported
+func (te *TypedEngine[T]) Explain(ctx context.Context, bizCode string, input any, opts ...ExecOption) (*Explanation, error) {
+	explainer, ok := te.base.(Explainer)
+	if !ok {
+		return nil, fmt.Errorf("底层引擎不支持执行解释: %w", errors.ErrUnsupported)
+	}
+	return explainer.Explain(ctx, bizCode, input, opts...)
+}
+
+// Base 获取底层通用引擎 - 诊断、运维等可选能力通过对其类型断言获取
+//
+// 使用示例:
+//
+//	err := userEngine.Base().(KillSwitch).Disable(ctx, "USER_VALIDATE", "INC-1024")
+func (te *TypedEngine[T]) Base() BaseEngine {
+	return te.base
+}
+
+// Close 关闭引擎 - EngineManager 的视图不关闭共享引擎
 func (te *TypedEngine[T]) Close() error {
+	if te.shared {
+		return nil
+	}
 	return te.base.Close()
 }
 
@@ -257,7 +326,7 @@ func convertMapToStruct[T any](rawResult map[string]interface{}) (T, error) {
 //	)
 func NewBaseEngine(opts ...Option) (BaseEngine, error) {
 	// 使用map[string]interface{}作为内部类型创建引擎
-	engine, err := New[map[string]interface{}](opts...)
+	engine, err := newEngine[map[string]interface{}](opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -268,12 +337,182 @@ func NewBaseEngine(opts ...Option) (BaseEngine, error) {
 
 // baseEngineWrapper BaseEngine接口的实现
 type baseEngineWrapper struct {
-	engine Engine[map[string]interface{}]
+	engine engineCapabilities[map[string]interface{}]
 }
 
 // ExecRaw 实现BaseEngine接口
-func (w *baseEngineWrapper) ExecRaw(ctx context.Context, bizCode string, input any) (map[string]interface{}, error) {
-	return w.engine.Exec(ctx, bizCode, input)
+func (w *baseEngineWrapper) ExecRaw(ctx context.Context, bizCode string, input any, opts ...ExecOption) (map[string]interface{}, error) {
+	return w.engine.Exec(ctx, bizCode, input, opts...)
+}
+
+// NewSession 实现SessionExecutor接口
+func (w *baseEngineWrapper) NewSession(ctx context.Context, input any) *Session[map[string]interface{}] {
+	return w.engine.NewSession(ctx, input)
+}
+
+// StreamResults 实现SessionExecutor接口
+func (w *baseEngineWrapper) StreamResults(ctx context.Context, bizCode string, input any, key string, maxResults int, opts ...ExecOption) iter.Seq2[any, error] {
+	return w.engine.StreamResults(ctx, bizCode, input, key, maxResults, opts...)
+}
+
+// DebugDump 实现Diagnostics接口
+func (w *baseEngineWrapper) DebugDump(out io.Writer) error {
+	return w.engine.DebugDump(out)
+}
+
+// KnowledgeBaseMemory 实现Diagnostics接口
+func (w *baseEngineWrapper) KnowledgeBaseMemory() []CompileInfo {
+	return w.engine.KnowledgeBaseMemory()
+}
+
+// RunRetention 实现Maintenance接口
+func (w *baseEngineWrapper) RunRetention(ctx context.Context) (*RetentionReport, error) {
+	return w.engine.RunRetention(ctx)
+}
+
+// NullPolicy 实现Registry接口
+func (w *baseEngineWrapper) NullPolicy(bizCode string) NullPolicy {
+	return w.engine.NullPolicy(bizCode)
+}
+
+// ExecMode 实现Registry接口
+func (w *baseEngineWrapper) ExecMode(bizCode string) ExecMode {
+	return w.engine.ExecMode(bizCode)
+}
+
+// Use 实现Registry接口
+func (w *baseEngineWrapper) Use(middleware ...ExecMiddleware) {
+	w.engine.Use(middleware...)
+}
+
+// RegisterPrebuiltKnowledgeBase 实现Registry接口
+func (w *baseEngineWrapper) RegisterPrebuiltKnowledgeBase(bizCode, name, version string) error {
+	return w.engine.RegisterPrebuiltKnowledgeBase(bizCode, name, version)
+}
+
+// RegisterEnum 实现Registry接口
+func (w *baseEngineWrapper) RegisterEnum(name string, values ...string) error {
+	return w.engine.RegisterEnum(name, values...)
+}
+
+// RegisterRefTable 实现Registry接口
+func (w *baseEngineWrapper) RegisterRefTable(name string, rows map[string]map[string]any) error {
+	return w.engine.RegisterRefTable(name, rows)
+}
+
+// RecentErrors 实现Diagnostics接口
+func (w *baseEngineWrapper) RecentErrors() []ErrorRecord {
+	return w.engine.RecentErrors()
+}
+
+// Metrics 实现Diagnostics接口
+func (w *baseEngineWrapper) Metrics() ExecMetrics {
+	return w.engine.Metrics()
+}
+
+// ReadOnly 实现Diagnostics接口
+func (w *baseEngineWrapper) ReadOnly() bool {
+	return w.engine.ReadOnly()
+}
+
+// AddOverride 实现RuleLayers接口
+func (w *baseEngineWrapper) AddOverride(ctx context.Context, bizCode string, override *rule.Rule, ttl time.Duration) error {
+	return w.engine.AddOverride(ctx, bizCode, override, ttl)
+}
+
+// RemoveOverride 实现RuleLayers接口
+func (w *baseEngineWrapper) RemoveOverride(ctx context.Context, bizCode, name string) error {
+	return w.engine.RemoveOverride(ctx, bizCode, name)
+}
+
+// Resolve 实现RuleLayers接口
+func (w *baseEngineWrapper) Resolve(ctx context.Context, bizCode string) (*RuleResolution, error) {
+	return w.engine.Resolve(ctx, bizCode)
+}
+
+// Disable 实现KillSwitch接口
+func (w *baseEngineWrapper) Disable(ctx context.Context, bizCode, reason string) error {
+	return w.engine.Disable(ctx, bizCode, reason)
+}
+
+// Enable 实现KillSwitch接口
+func (w *baseEngineWrapper) Enable(ctx context.Context, bizCode string) error {
+	return w.engine.Enable(ctx, bizCode)
+}
+
+// Disabled 实现KillSwitch接口
+func (w *baseEngineWrapper) Disabled() []Disablement {
+	return w.engine.Disabled()
+}
+
+// SLOStatus 实现Diagnostics接口
+func (w *baseEngineWrapper) SLOStatus() []SLOStatus {
+	return w.engine.SLOStatus()
+}
+
+// Invalidate 实现Maintenance接口
+func (w *baseEngineWrapper) Invalidate(ctx context.Context, bizCode, inputKeyPrefix string) error {
+	return w.engine.Invalidate(ctx, bizCode, inputKeyPrefix)
+}
+
+// CheckRuleLimits 实现RuleAnalysis接口
+func (w *baseEngineWrapper) CheckRuleLimits(bizCode string, rules []*rule.Rule) error {
+	return w.engine.CheckRuleLimits(bizCode, rules)
+}
+
+// Completions 实现RuleAnalysis接口
+func (w *baseEngineWrapper) Completions(bizCode string) (*CompletionMetadata, error) {
+	return w.engine.Completions(bizCode)
+}
+
+// RefreshMatching 实现Maintenance接口
+func (w *baseEngineWrapper) RefreshMatching(pattern string) ([]string, error) {
+	return w.engine.RefreshMatching(pattern)
+}
+
+// CloneBizCode 实现Maintenance接口
+func (w *baseEngineWrapper) CloneBizCode(ctx context.Context, fromTenant, toTenant string, bizCodes ...string) (*CloneReport, error) {
+	return w.engine.CloneBizCode(ctx, fromTenant, toTenant, bizCodes...)
+}
+
+// CloneBizCodeDryRun 实现Maintenance接口
+func (w *baseEngineWrapper) CloneBizCodeDryRun(ctx context.Context, fromTenant, toTenant string, bizCodes ...string) (*CloneReport, error) {
+	return w.engine.CloneBizCodeDryRun(ctx, fromTenant, toTenant, bizCodes...)
+}
+
+// RunRuleTests 实现RuleAnalysis接口
+func (w *baseEngineWrapper) RunRuleTests(ctx context.Context, bizCode string) (*RuleTestReport, error) {
+	return w.engine.RunRuleTests(ctx, bizCode)
+}
+
+// RunBundleTests 实现RuleAnalysis接口
+func (w *baseEngineWrapper) RunBundleTests(ctx context.Context, bundle *rule.RuleBundle) (*RuleTestReport, error) {
+	return w.engine.RunBundleTests(ctx, bundle)
+}
+
+// DecisionStats 实现Diagnostics接口
+func (w *baseEngineWrapper) DecisionStats(bizCode string) *DecisionStats {
+	return w.engine.DecisionStats(bizCode)
+}
+
+// DeadRules 实现Diagnostics接口
+func (w *baseEngineWrapper) DeadRules(bizCode string) *DeadRuleReport {
+	return w.engine.DeadRules(bizCode)
+}
+
+// DataFlow 实现RuleAnalysis接口
+func (w *baseEngineWrapper) DataFlow(ctx context.Context, bizCode string) (*rule.DataFlowGraph, error) {
+	return w.engine.DataFlow(ctx, bizCode)
+}
+
+// EstimateCost 实现RuleAnalysis接口
+func (w *baseEngineWrapper) EstimateCost(ctx context.Context, bizCode string) (*rule.CostEstimate, error) {
+	return w.engine.EstimateCost(ctx, bizCode)
+}
+
+// Explain 实现Explainer接口
+func (w *baseEngineWrapper) Explain(ctx context.Context, bizCode string, input any, opts ...ExecOption) (*Explanation, error) {
+	return w.engine.Explain(ctx, bizCode, input, opts...)
 }
 
 // Close 实现BaseEngine接口
@@ -314,6 +553,15 @@ func (w *baseEngineWrapper) Close() error {
 //	    WithCustomCache(cache),
 //	)
 func New[T any](opts ...Option) (Engine[T], error) {
+	eng, err := newEngine[T](opts...)
+	if err != nil {
+		return nil, err
+	}
+	return eng, nil
+}
+
+// newEngine 创建实现全部可选能力的引擎实例，New 和 NewBaseEngine 共用
+func newEngine[T any](opts ...Option) (engineCapabilities[T], error) {
 	cfg := config.DefaultConfig()
 	ctx := newRuntimeContext(cfg)
 
@@ -326,6 +574,10 @@ func New[T any](opts ...Option) (Engine[T], error) {
 		}
 	}
 
+	if err := ctx.checkOptionConflicts(); err != nil {
+		return nil, err
+	}
+
 	if err := ctx.config.Validate(); err != nil {
 		return nil, fmt.Errorf("配置验证失败: %w", err)
 	}
@@ -333,6 +585,7 @@ func New[T any](opts ...Option) (Engine[T], error) {
 	if err := ctx.initialize(); err != nil {
 		return nil, fmt.Errorf("创建运行时上下文失败: %w", err)
 	}
+	ctx.warnOptionConflicts()
 
 	// 创建引擎实例
 	eng := engine.NewEngineImpl[T](
@@ -341,16 +594,64 @@ func New[T any](opts ...Option) (Engine[T], error) {
 		ctx.Cache,
 		cache.CacheKeyBuilder{},
 		ctx.Logger,
-		ast.NewKnowledgeLibrary(),
+		ctx.KnowledgeLibrary,
 		&sync.Map{},
 		cron.New(),
 		false,
 	)
+	eng.SetFallbackProvider(ctx.fallbackProvider())
+	eng.SetDecisionStatsExporter(ctx.DecisionExporter)
+	eng.SetAnomalyAlerter(ctx.AnomalyAlerter)
+	eng.SetDeadRuleReporter(ctx.DeadRuleReporter)
+	eng.SetAlertSink(ctx.AlertSink)
+	eng.SetQuotaProvider(ctx.QuotaProvider)
+	eng.SetUsageReporter(ctx.UsageReporter)
+	eng.SetClock(ctx.Clock)
+	eng.SetRateProvider(ctx.RateProvider)
+	eng.SetCalendarProvider(ctx.Calendar)
+	eng.SetEmbeddedRules(ctx.EmbeddedRules)
+	eng.SetAuditRecorder(ctx.AuditRecorder)
+	eng.SetSLOAlerter(ctx.SLOAlerter)
+	for _, target := range ctx.RetentionTargets {
+		eng.AddRetentionTarget(target)
+	}
+	if ctx.DB != nil {
+		if sqlDB, err := ctx.DB.DB(); err == nil {
+			eng.RegisterDiagnostics("db_pool", func() any { return sqlDB.Stats() })
+		}
+	}
+
+	eng.SetTaskLock(ctx.TaskLock)
 
 	// 启动定时同步任务
+	if err := eng.SetPubSub(ctx.PubSub); err != nil {
+		eng.Close()
+		return nil, fmt.Errorf("设置实例间消息通道失败: %w", err)
+	}
+	if err := eng.SetInvalidationSource(ctx.Invalidation); err != nil {
+		eng.Close()
+		return nil, err
+	}
+	// 先订阅再加载停用记录，加载期间其他实例的变更不会丢失
+	if err := eng.LoadDisablements(context.Background()); err != nil {
+		eng.Close()
+		return nil, err
+	}
 	if err := eng.StartSync(); err != nil {
 		return nil, fmt.Errorf("启动同步任务失败: %w", err)
 	}
+	if err := eng.StartDecisionStatsExport(); err != nil {
+		eng.Close()
+		return nil, fmt.Errorf("启动决策分布导出失败: %w", err)
+	}
+	if err := eng.StartDeadRuleDetection(); err != nil {
+		eng.Close()
+		return nil, fmt.Errorf("启动失效规则检测失败: %w", err)
+	}
+	if err := eng.StartRetention(); err != nil {
+		eng.Close()
+		return nil, fmt.Errorf("启动数据清理任务失败: %w", err)
+	}
 
 	return eng, nil
 }
@@ -366,6 +667,7 @@ type Option func(*RuntimeContext) error
 func WithDSN(dsn string) Option {
 	return func(ctx *RuntimeContext) error {
 		ctx.config.DSN = dsn
+		ctx.claim(SettingDatabase, "WithDSN", claimConfig)
 		return nil
 	}
 }
@@ -378,11 +680,54 @@ func WithAutoMigrate() Option {
 	}
 }
 
+// WithSchemaCheck 启用启动时表结构检查 - 核对规则表的字段、类型和索引是否与模型一致，但不执行迁移
+//
+// 不一致时 New 返回包含全部差异的错误，可通过 errors.As 获取 *SchemaError，
+// 用于在首个请求到来前发现生产环境漏跑迁移的问题。与 WithAutoMigrate 同时使用时先迁移再检查。
+func WithSchemaCheck() Option {
+	return func(ctx *RuntimeContext) error {
+		ctx.config.SchemaCheck = true
+		return nil
+	}
+}
+
+// WithReadOnly 启用只读模式 - 禁止实例上的全部写操作，用于嵌入不受信任或影响面大的服务的只读副本
+//
+// 只读实例只获取、编译和执行规则：CloneBizCode、RunRetention 返回 ErrReadOnly，不启动定时清理；
+// 与 WithAutoMigrate、WithAuditRetention、WithRuleVersionRetention 同时使用时配置验证失败。
+func WithReadOnly() Option {
+	return func(ctx *RuntimeContext) error {
+		ctx.config.ReadOnly = true
+		return nil
+	}
+}
+
+// WithIndexAdvisor 启用启动时索引诊断 - 对按业务码获取规则的查询执行EXPLAIN（支持SQLite和MySQL），
+// 全表扫描时记录警告日志，提示执行迁移创建复合索引 idx_runehammer_rules_fetch
+func WithIndexAdvisor() Option {
+	return func(ctx *RuntimeContext) error {
+		ctx.config.IndexAdvisor = true
+		return nil
+	}
+}
+
+// ExplainRuleQuery 诊断按业务码获取规则的查询计划，可在发布流水线中检查索引是否生效
+func ExplainRuleQuery(ctx context.Context, db *gorm.DB, bizCode string) (*QueryPlan, error) {
+	return rule.ExplainFetchQuery(ctx, db, bizCode)
+}
+
+// CheckSchema 检查数据库表结构是否与规则模型一致，不执行迁移，不一致时返回*SchemaError
+func CheckSchema(db *gorm.DB) error {
+	return rule.CheckSchema(db)
+}
+
 // WithMemoryCache 启用内存缓存
 func WithMemoryCache(maxSize int) Option {
 	return func(ctx *RuntimeContext) error {
 		ctx.config.CacheType = config.CacheTypeMemory
 		ctx.config.MaxCacheSize = maxSize
+		ctx.clearRedisConfig()
+		ctx.claim(SettingCache, "WithMemoryCache", claimConfig)
 		return nil
 	}
 }
@@ -394,6 +739,7 @@ func WithRedisCache(addr, password string, db int) Option {
 		ctx.config.RedisAddr = addr
 		ctx.config.RedisPassword = password
 		ctx.config.RedisDB = db
+		ctx.claim(SettingCache, "WithRedisCache", claimConfig)
 		return nil
 	}
 }
@@ -402,62 +748,1396 @@ func WithRedisCache(addr, password string, db int) Option {
 func WithNoCache() Option {
 	return func(ctx *RuntimeContext) error {
 		ctx.config.CacheType = config.CacheTypeNone
+		ctx.clearRedisConfig()
+		ctx.claim(SettingCache, "WithNoCache", claimConfig)
 		return nil
 	}
 }
 
-// WithCacheTTL 设置缓存生存时间
-func WithCacheTTL(ttl time.Duration) Option {
+// WithIdempotencyWindow 设置幂等结果保留窗口，<=0表示禁用幂等
+func WithIdempotencyWindow(window time.Duration) Option {
 	return func(ctx *RuntimeContext) error {
-		ctx.config.CacheTTL = ttl
+		ctx.config.IdempotencyWindow = window
 		return nil
 	}
 }
 
-// WithMaxCacheSize 设置最大缓存大小
-func WithMaxCacheSize(size int) Option {
+// WithInvalidationSource 设置上游数据变更来源 - 创建引擎时调用 source.Watch 开始监听，
+// 数据变更时通过传入的函数失效幂等结果（同 Invalidate），关闭引擎时停止监听
+//
+// 使用示例:
+//
+//	type customerEvents struct{ consumer *kafka.Reader }
+//
+//	func (c *customerEvents) Watch(ctx context.Context, invalidate InvalidateFunc) (func(), error) {
+//	    ctx, cancel := context.WithCancel(ctx)
+//	    go func() {
+//	        for {
+//	            msg, err := c.consumer.ReadMessage(ctx)
+//	            if err != nil {
+//	                return
+//	            }
+//	            _ = invalidate(ctx, "CREDIT_LIMIT", string(msg.Key)+":")
+//	        }
+//	    }()
+//	    return cancel, nil
+//	}
+//
+//	engine, err := New[Result](WithDSN(dsn), WithInvalidationSource(&customerEvents{consumer: reader}))
+func WithInvalidationSource(source InvalidationSource) Option {
 	return func(ctx *RuntimeContext) error {
-		ctx.config.MaxCacheSize = size
+		ctx.Invalidation = source
 		return nil
 	}
 }
 
-// WithSyncInterval 设置同步间隔
-func WithSyncInterval(interval time.Duration) Option {
+// WithRecentErrorsSize 设置近期错误缓冲容量，<=0表示不记录
+func WithRecentErrorsSize(size int) Option {
 	return func(ctx *RuntimeContext) error {
-		ctx.config.SyncInterval = interval
+		ctx.config.RecentErrorsSize = size
 		return nil
 	}
 }
 
-// ============================================================================
-// 实例注入选项 - 用于注入自定义实例
-// ============================================================================
+// WithInputCoercion 启用输入类型归一化 - 注入前将map输入中的字符串数值/布尔值转换为对应类型
+//
+// schema为字段路径到声明类型（int/float/number/bool/string）的映射，路径不含数组下标；
+// 为nil时按内容推断（排除前导零的整数/小数、true/false）。转换记录写入ExecReport.Coercions。
+//
+// 使用示例:
+//
+//	// 按内容推断："18" -> 18，"true" -> true
+//	engine, err := New[Result](WithDSN(dsn), WithInputCoercion(nil))
+//
+//	// 按声明类型转换，未声明字段保持不变
+//	engine, err := New[Result](WithDSN(dsn), WithInputCoercion(map[string]string{
+//	    "age":        "int",
+//	    "order.paid": "bool",
+//	}))
+func WithInputCoercion(schema map[string]string) Option {
+	return func(ctx *RuntimeContext) error {
+		ctx.config.InputCoercion = true
+		ctx.config.InputSchema = schema
+		return nil
+	}
+}
 
-// WithCustomDB 设置自定义数据库实例
-func WithCustomDB(db *gorm.DB) Option {
+// WithInputCopy 启用输入拷贝 - 注入前深拷贝输入，规则修改Params不会改动调用方的map/结构体
+//
+// 不含map、切片、指针等引用类型的结构体直接按值注入，无额外开销。
+func WithInputCopy() Option {
 	return func(ctx *RuntimeContext) error {
-		ctx.DB = db
-		ctx.config.DSN = "__CUSTOM_DB__"
+		ctx.config.CopyInput = true
 		return nil
 	}
 }
 
-// WithCustomCache 设置自定义缓存实例
-func WithCustomCache(cache cache.Cache) Option {
+// WithFlattenedInput 为map输入追加扁平化路径别名
+//
+// 嵌套字段可直接以完整路径访问，如 Params["customer.address.city"]、Params["items[0].qty"]，
+// 原有键保持不变。需要默认值时可使用内置函数 Get(Params, "customer.address.city", "")。
+func WithFlattenedInput() Option {
 	return func(ctx *RuntimeContext) error {
-		ctx.Cache = cache
-		if cache != nil {
-			ctx.config.CacheType = config.CacheTypeNone
+		ctx.config.FlattenInput = true
+		return nil
+	}
+}
+
+// WithInputType 注册业务码的输入类型 - 用于Completions生成自动补全元数据
+//
+// sample支持结构体（或指针）、map样例值，以及字段路径到类型的声明 map[string]string。
+//
+// 使用示例:
+//
+//	engine, err := New[Result](WithDSN(dsn), WithInputType("RISK_CHECK", RiskInput{}))
+//	meta, err := engine.Completions("RISK_CHECK")
+func WithInputType(bizCode string, sample any) Option {
+	return func(ctx *RuntimeContext) error {
+		if ctx.config.InputTypes == nil {
+			ctx.config.InputTypes = make(map[string]any)
 		}
+		ctx.config.InputTypes[bizCode] = sample
 		return nil
 	}
 }
 
-// WithCustomLogger 设置自定义日志实例
-func WithCustomLogger(logger logger.Logger) Option {
+// WithFieldErrors 启用字段错误累积 - 统一的字段级错误结果结构
+//
+// 规则通过 Errors.AddError(field, code, message) 记录错误，执行结束后汇总为 []FieldError：
+// map结果写入 Result["errors"]（未记录错误时为空列表），结构体结果通过 `json:"errors"` 字段接收，
+// 同时填充 ExecReport.FieldErrors。
+//
+// 使用示例:
+//
+//	engine, err := New[map[string]any](WithDSN(dsn), WithFieldErrors())
+//	// GRL: then Errors.AddError("age", "AGE_TOO_LOW", "年龄不能小于18");
+//	result, err := engine.Exec(ctx, "APPLY_CHECK", input)
+//	errs := result["errors"].([]FieldError)
+func WithFieldErrors() Option {
 	return func(ctx *RuntimeContext) error {
-		ctx.Logger = logger
+		ctx.config.FieldErrors = true
+		return nil
+	}
+}
+
+// WithRolloutKey 设置规则灰度放量的分桶键字段
+//
+// Rule.RolloutPercent 为1-99的规则只对部分执行生效：以规则名和该字段的值计算分桶，
+// 同一键值结果稳定，放量比例从1%调至100%时已命中的键保持命中。未设置或输入缺少该字段时部分放量的规则不执行。
+//
+// 使用示例:
+//
+//	engine, err := New[Result](WithDSN(dsn), WithRolloutKey("customer.id"))
+func WithRolloutKey(field string) Option {
+	return func(ctx *RuntimeContext) error {
+		ctx.config.RolloutKeyField = field
+		return nil
+	}
+}
+
+// WithDecisionStats 启用决策分布统计 - 用于规则变更后的漂移检测
+//
+// 按业务码在内存中累计结果字段的取值分布：布尔、字符串等取值按值计数，数值取值按buckets上界分桶
+// 并记录计数、总和与极值。规则集版本变化时开始新的分布，上一版本的分布保留在 Previous 中用于对比。
+// 通过 engine.DecisionStats(bizCode) 获取快照。
+//
+// 使用示例:
+//
+//	engine, err := New[map[string]any](WithDSN(dsn),
+//	    WithDecisionStats([]string{"approved", "score"}, []float64{300, 600, 800}))
+//	stats := engine.DecisionStats("LOAN_APPROVAL")
+//	approved := stats.Fields["approved"].Values["true"]
+func WithDecisionStats(fields []string, buckets []float64) Option {
+	return func(ctx *RuntimeContext) error {
+		ctx.config.DecisionStatsFields = fields
+		ctx.config.DecisionStatsBuckets = buckets
+		return nil
+	}
+}
+
+// WithDecisionStatsSampling 设置决策分布采样率 (0,1)，高吞吐场景下降低统计开销
+func WithDecisionStatsSampling(rate float64) Option {
+	return func(ctx *RuntimeContext) error {
+		ctx.config.DecisionStatsSampleRate = rate
+		return nil
+	}
+}
+
+// WithDecisionStatsExport 定期导出决策分布 - exporter为nil时以Info级别输出到日志
+func WithDecisionStatsExport(interval time.Duration, exporter DecisionStatsExporter) Option {
+	return func(ctx *RuntimeContext) error {
+		ctx.config.DecisionStatsExportInterval = interval
+		ctx.DecisionExporter = exporter
+		return nil
+	}
+}
+
+// WithAnomalyAlerts 启用决策分布异常告警 - 及早发现错误的规则发布
+//
+// 以上一规则集版本的决策分布为基线，当前版本与基线的样本数均达到minSamples（<=0时取100）后，
+// 规则命中率或 WithDecisionStats 统计字段的取值占比变化超过threshold（绝对值，如0.2表示20个百分点）时
+// 调用alerter，同一指标在同一版本内只告警一次。alerter为nil时输出警告日志。
+// 未配置 WithDecisionStats 时只检测规则命中率。
+//
+// 使用示例:
+//
+//	engine, err := New[map[string]any](WithDSN(dsn),
+//	    WithDecisionStats([]string{"approved"}, nil),
+//	    WithAnomalyAlerts(0.2, 500, AnomalyAlertFunc(func(ctx context.Context, alert AnomalyAlert) {
+//	        pager.Notify(alert.String())
+//	    })))
+func WithAnomalyAlerts(threshold float64, minSamples int64, alerter AnomalyAlerter) Option {
+	return func(ctx *RuntimeContext) error {
+		ctx.config.AnomalyThreshold = threshold
+		ctx.config.AnomalyMinSamples = minSamples
+		ctx.AnomalyAlerter = alerter
+		return nil
+	}
+}
+
+// WithDeadRuleDetection 启用失效规则检测 - 帮助清理长期不命中的过时规则
+//
+// 引擎按规则记录命中情况，每隔interval（<=0时取1小时）检测一次，将观察满window且window内从未命中的规则
+// 按业务码汇总后调用reporter，reporter为nil时输出警告日志。也可通过 engine.DeadRules(bizCode) 随时查询，
+// 或在 DebugDump 的 dead_rules 诊断项中查看。
+//
+// 使用示例:
+//
+//	engine, err := New[map[string]any](WithDSN(dsn),
+//	    WithDeadRuleDetection(30*24*time.Hour, 24*time.Hour, DeadRuleReporterFunc(func(ctx context.Context, reports []DeadRuleReport) error {
+//	        return ticket.Create(ctx, reports)
+//	    })))
+func WithDeadRuleDetection(window, interval time.Duration, reporter DeadRuleReporter) Option {
+	return func(ctx *RuntimeContext) error {
+		ctx.config.DeadRuleWindow = window
+		ctx.config.DeadRuleCheckInterval = interval
+		ctx.DeadRuleReporter = reporter
+		return nil
+	}
+}
+
+// WithKnowledgeLibrary 使用已有的Grule知识库 - 可通过 RegisterPrebuiltKnowledgeBase 执行其中预编译的知识库
+//
+// 引擎编译业务码规则时同样写入该知识库，知识库名为业务码、版本为1.0.0，请避免与已有知识库重名。
+func WithKnowledgeLibrary(lib *ast.KnowledgeLibrary) Option {
+	return func(ctx *RuntimeContext) error {
+		ctx.KnowledgeLibrary = lib
+		return nil
+	}
+}
+
+// WithAlertSink 设置规则告警通道 - 规则动作中的 Alert 调用在输出警告日志的同时发送到该通道
+//
+// alert 包提供Slack（NewSlackSink）、通用HTTP（NewWebhookSink）和邮件（NewSMTPSink）通道，
+// Multi 组合多个通道，Throttle 按窗口限流并对相同告警去重。告警在规则执行中同步发送，
+// 发送失败只记录错误日志，不影响规则执行。
+//
+// 使用示例:
+//
+//	sink := alert.Throttle(alert.Multi(
+//	    alert.NewSlackSink(slackWebhookURL),
+//	    alert.NewSMTPSink(alert.SMTPConfig{Addr: "smtp.example.com:587", From: "risk@example.com", To: []string{"oncall@example.com"}}),
+//	), alert.ThrottleConfig{DedupWindow: 10 * time.Minute, Limit: 20})
+//	engine, err := New[map[string]any](WithDSN(dsn), WithAlertSink(sink))
+func WithAlertSink(sink alert.Sink) Option {
+	return func(ctx *RuntimeContext) error {
+		ctx.AlertSink = sink
+		return nil
+	}
+}
+
+// WithSimilarityMaxLength 设置相似度函数（Levenshtein、JaroWinkler）的最大输入长度（字符数），<=0时取1000
+//
+// 两个函数的计算量与输入长度之积成正比，输入超过该长度时执行返回 ErrStringTooLong，
+// 避免外部传入的超长字符串拖慢规则执行。
+func WithSimilarityMaxLength(n int) Option {
+	return func(ctx *RuntimeContext) error {
+		ctx.config.SimilarityMaxLength = n
+		return nil
+	}
+}
+
+// WithClock 设置规则时间函数使用的时钟 - 规则中的 Now()、Today()、NowMillis() 按该时钟取当前时间
+//
+// 未设置时使用系统时间。测试时可使用 runehammertest.NewClock 创建可冻结、可拨动的时钟，
+// 使依赖当前时间的规则得到确定的结果。
+//
+// 使用示例:
+//
+//	clock := runehammertest.NewClock(time.Date(2024, 12, 31, 23, 59, 0, 0, time.UTC))
+//	engine, err := New[map[string]any](WithDSN(dsn), WithClock(clock))
+//	clock.Advance(2 * time.Minute) // 跨年
+func WithClock(clock Clock) Option {
+	return func(ctx *RuntimeContext) error {
+		ctx.Clock = clock
+		return nil
+	}
+}
+
+// WithRateProvider 设置货币换算的汇率提供者 - 规则通过 ConvertCurrency(amount, from, to) 换算金额
+//
+// 汇率按货币对缓存（WithRateCacheTTL，默认1分钟），获取失败时继续使用仍在时效内的缓存汇率；
+// 设置 WithRateMaxAge 后，汇率时间早于该时长时执行返回 ErrStaleRate。
+//
+// 使用示例:
+//
+//	engine, err := New[map[string]any](WithDSN(dsn),
+//	    WithRateProvider(RateFunc(func(ctx context.Context, from, to string) (Rate, error) {
+//	        return fx.Latest(ctx, from, to)
+//	    })), WithRateMaxAge(24*time.Hour))
+func WithRateProvider(provider RateProvider) Option {
+	return func(ctx *RuntimeContext) error {
+		ctx.RateProvider = provider
+		return nil
+	}
+}
+
+// WithRateCacheTTL 设置汇率的缓存时长，<=0时取1分钟
+func WithRateCacheTTL(ttl time.Duration) Option {
+	return func(ctx *RuntimeContext) error {
+		ctx.config.RateCacheTTL = ttl
+		return nil
+	}
+}
+
+// WithRateMaxAge 设置汇率的最大时效，<=0表示不检查
+func WithRateMaxAge(maxAge time.Duration) Option {
+	return func(ctx *RuntimeContext) error {
+		ctx.config.RateMaxAge = maxAge
+		return nil
+	}
+}
+
+// WithCalendar 设置节假日日历 - 规则通过 IsHoliday、IsBusinessDay、NextBusinessDay 按地区判断日期
+//
+// 可使用 LoadCalendar 从YAML文件加载内置日历，或实现 CalendarProvider 对接外部日历服务。
+//
+// 使用示例:
+//
+//	calendar, err := LoadCalendar("calendars/holidays.yaml")
+//	engine, err := New[map[string]any](WithDSN(dsn), WithCalendar(calendar))
+func WithCalendar(calendar CalendarProvider) Option {
+	return func(ctx *RuntimeContext) error {
+		ctx.Calendar = calendar
+		return nil
+	}
+}
+
+// LoadCalendar 从YAML文件加载节假日日历，格式见 ParseCalendar
+func LoadCalendar(path string) (*Calendar, error) {
+	return engine.LoadCalendar(path)
+}
+
+// ParseCalendar 解析YAML格式的节假日日历 - 顶层为地区，每个地区包含 holidays、workdays（调休上班日）和可选的 weekend
+//
+//	CN:
+//	  holidays: [2024-10-01, 2024-10-02]
+//	  workdays: [2024-10-12]
+func ParseCalendar(data []byte) (*Calendar, error) {
+	return engine.ParseCalendar(data)
+}
+
+// WithEmbeddedRules 加载随二进制附带的默认规则 - 类库可通过 go:embed 打包开箱即用的规则集
+//
+// 按扩展名解析匹配glob的文件：.grl 文件每个一条规则，业务码为所在目录名、规则名为文件名（如 rules/loan/limit.grl）；
+// .json 文件为规则包（RuleBundle）。运行时数据库规则优先：与数据库规则同名的内置规则被覆盖，
+// 其余内置规则与数据库规则一同编译执行。可多次调用加载多组规则，加载失败时 New 返回错误。
+//
+// 使用示例:
+//
+//	//go:embed rules
+//	var defaultRules embed.FS
+//
+//	engine, err := New[map[string]any](WithDSN(dsn), WithEmbeddedRules(defaultRules, "rules/*/*.grl"))
+func WithEmbeddedRules(fsys fs.FS, glob string) Option {
+	return func(ctx *RuntimeContext) error {
+		rules, err := rule.LoadRulesFS(fsys, glob)
+		if err != nil {
+			return fmt.Errorf("加载内置规则失败: %w", err)
+		}
+		ctx.EmbeddedRules = append(ctx.EmbeddedRules, rules...)
+		return nil
+	}
+}
+
+// WithDistributedLock 设置分布式锁 - 多实例部署时规则同步和数据清理每个周期只由一个实例执行，
+// 自动迁移（WithAutoMigrate）在各实例间依次执行
+//
+// lock 包提供Redis（NewRedis）、数据库锁表（NewDB，也可使用 WithDBLock）和进程内（NewMemory）三种实现。
+// 持有锁的实例停止后，锁在有效期（任务执行间隔）到期时由其他实例接管；锁统计见诊断快照的 distributed_lock。
+// 与 WithDBLock 同时使用时后应用的选项生效。
+//
+// 使用示例:
+//
+//	client := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+//	engine, err := New[map[string]any](WithDSN(dsn), WithDistributedLock(lock.NewRedis(client)))
+func WithDistributedLock(locker lock.Locker) Option {
+	return func(ctx *RuntimeContext) error {
+		ctx.Locker = locker
+		ctx.dbLock = false
+		return nil
+	}
+}
+
+// WithDBLock 使用引擎数据库中的锁表（runehammer_locks）作为分布式锁，初始化时创建锁表
+//
+// 锁记录持久化，不依赖Redis，接管过期锁的次数计入锁统计。与 WithDistributedLock 同时使用时后应用的选项生效。
+func WithDBLock() Option {
+	return func(ctx *RuntimeContext) error {
+		ctx.Locker = nil
+		ctx.dbLock = true
+		return nil
+	}
+}
+
+// WithPubSub 设置实例间消息通道 - 运行时覆盖（AddOverride、RemoveOverride）、紧急停用（Disable、Enable）
+// 和幂等结果失效（Invalidate）通过该通道广播给其他实例
+//
+// pubsub 包提供Redis发布订阅（NewRedis）和进程内（NewMemory）两种实现。消息不持久化，
+// 广播之后启动的实例不会收到之前的操作。
+//
+// 使用示例:
+//
+//	client := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+//	engine, err := New[map[string]any](WithDSN(dsn), WithPubSub(pubsub.NewRedis(client)))
+func WithPubSub(bus pubsub.PubSub) Option {
+	return func(ctx *RuntimeContext) error {
+		ctx.PubSub = bus
+		return nil
+	}
+}
+
+// WithAuditRecorder 设置审计记录 - 紧急停用（Disable、Enable）在发起操作的实例上写入审计记录
+//
+// 审计记录同步写入，失败时操作已生效，错误返回给调用方。
+//
+// 使用示例:
+//
+//	recorder := AuditFunc(func(ctx context.Context, event AuditEvent) error {
+//	    return db.Table("ops_audit").Create(map[string]any{
+//	        "action": event.Action, "biz_code": event.BizCode, "reason": event.Reason, "created_at": event.Time,
+//	    }).Error
+//	})
+//	engine, err := New[map[string]any](WithDSN(dsn), WithAuditRecorder(recorder))
+func WithAuditRecorder(recorder AuditRecorder) Option {
+	return func(ctx *RuntimeContext) error {
+		ctx.AuditRecorder = recorder
+		return nil
+	}
+}
+
+// WithLocale 设置规则格式化函数的默认区域
+//
+// 规则中的 FormatNumber、FormatCurrency、ParseLocalizedNumber 的locale参数为空字符串时使用该区域，
+// 未设置时为 en-US。区域按 zh-CN、de_DE 等形式匹配，未收录的区域按语言匹配。
+// 该区域不影响错误信息的语言，错误信息区域由 WithErrorLocale 设置。
+//
+// 使用示例:
+//
+//	engine, err := New[map[string]any](WithDSN(dsn), WithLocale("de-DE"))
+//	// 规则中: Result["text"] = FormatCurrency(Params["amount"], "EUR", "");  // "1.234,50 €"
+func WithLocale(locale string) Option {
+	return func(ctx *RuntimeContext) error {
+		ctx.config.Locale = locale
+		return nil
+	}
+}
+
+// WithErrorLocale 设置 Exec 返回错误的信息区域
+//
+// 未设置或中文区域输出原有的中文信息，其他区域输出英文说明、错误码和业务码，并附上原始的中文信息。
+// 错误码（ErrorCode）不随区域变化；与 WithLocale 设置的格式化区域相互独立。
+//
+// 使用示例:
+//
+//	engine, err := New[map[string]any](WithDSN(dsn), WithLocale("de-DE"), WithErrorLocale("en-US"))
+func WithErrorLocale(locale string) Option {
+	return func(ctx *RuntimeContext) error {
+		ctx.config.ErrorLocale = locale
+		return nil
+	}
+}
+
+// WithQuota 设置按租户的配额检查和用量上报 - 用于SaaS平台按客户计量规则执行
+//
+// 每次执行在参数验证后调用 provider.CheckQuota(ctx, tenant, bizCode)，返回错误时拒绝执行，
+// 超出配额应返回 ErrQuotaExceeded（或包装它的错误）；其他错误包装为配额检查失败。
+// 通过检查的执行结束后调用 reporter 上报用量（规则求值次数、动作执行次数、耗时和错误）。
+// 租户由执行选项 WithTenant 指定。provider、reporter 均可为nil。
+//
+// 使用示例:
+//
+//	engine, err := New[map[string]any](WithDSN(dsn),
+//	    WithQuota(QuotaFunc(func(ctx context.Context, tenant, bizCode string) error {
+//	        if !limiter.Allow(tenant) {
+//	            return ErrQuotaExceeded
+//	        }
+//	        return nil
+//	    }), UsageReportFunc(func(ctx context.Context, usage Usage) {
+//	        billing.Record(usage.Tenant, usage.BizCode, usage.Evaluations)
+//	    })))
+//	result, err := engine.Exec(ctx, "RISK_CHECK", input, WithTenant("acme"))
+func WithQuota(provider QuotaProvider, reporter UsageReporter) Option {
+	return func(ctx *RuntimeContext) error {
+		ctx.QuotaProvider = provider
+		ctx.UsageReporter = reporter
+		return nil
+	}
+}
+
+// WithVersionRetention 设置每个业务码保留的已编译规则集版本数（默认3），<=0表示不保留历史版本
+//
+// 保留的版本可通过 ExecVersion 或 WithRuleSetHash 固定执行；超出数量时淘汰最久未使用的版本。
+// 版本只保留在内存中，引擎重启后丢失；不保留历史版本时固定执行只能使用最新规则集。
+func WithVersionRetention(n int) Option {
+	return func(ctx *RuntimeContext) error {
+		ctx.config.VersionRetention = n
+		return nil
+	}
+}
+
+// WithAuditRetention 设置审计类记录的保留时长 - 清理任务按间隔分批删除保留目标中早于保留期限的记录
+//
+// 保留目标可用 rule.NewTableRetention 按表的时间列创建，也可自行实现 RetentionTarget。
+// 清理间隔和批大小通过 WithRetentionSchedule 设置，默认每小时、每批500条。
+//
+// 使用示例:
+//
+//	engine, err := New[map[string]any](WithDSN(dsn),
+//	    WithAuditRetention(90*24*time.Hour, rule.NewTableRetention(db, "decision_audit", "created_at")))
+func WithAuditRetention(retention time.Duration, targets ...RetentionTarget) Option {
+	return func(ctx *RuntimeContext) error {
+		ctx.config.AuditRetention = retention
+		ctx.RetentionTargets = append(ctx.RetentionTargets, targets...)
+		return nil
+	}
+}
+
+// WithRuleVersionRetention 设置每条规则在数据库中保留的版本数 - 清理任务删除超出的禁用历史版本
+//
+// 同一业务码下同名规则按版本号保留最新的n个版本，启用的规则不会被删除；需要规则映射器实现
+// rule.RuleRetentionMapper（内置映射器已实现）。与 WithVersionRetention（内存中保留的已编译版本数）相互独立。
+func WithRuleVersionRetention(n int) Option {
+	return func(ctx *RuntimeContext) error {
+		ctx.config.RuleVersionRetention = n
+		return nil
+	}
+}
+
+// WithRetentionSchedule 设置数据清理任务的执行间隔和每批删除数，<=0时分别取1小时和500
+func WithRetentionSchedule(interval time.Duration, batchSize int) Option {
+	return func(ctx *RuntimeContext) error {
+		ctx.config.RetentionInterval = interval
+		ctx.config.RetentionBatchSize = batchSize
+		return nil
+	}
+}
+
+// WithWriteCheck 设置规则写入声明检查模式
+//
+// 声明了写入字段（Rule.Writes）的规则写入其他Result字段时，WriteCheckWarn记录警告日志，
+// WriteCheckError使编译失败并返回ErrUndeclaredWrite；未声明写入字段的规则不检查。
+//
+// 使用示例:
+//
+//	engine, err := New[Result](WithDSN(dsn), WithWriteCheck(WriteCheckError))
+//	graph, err := engine.DataFlow(ctx, "RISK_CHECK")
+func WithWriteCheck(mode WriteCheckMode) Option {
+	return func(ctx *RuntimeContext) error {
+		ctx.config.WriteCheck = mode
+		return nil
+	}
+}
+
+// WithCostCheck 设置规则集成本预算检查模式
+//
+// 编译前静态估算规则集的单次执行成本（条件数、正则与查找调用、输入字段数），超出业务码的延迟预算时，
+// CostCheckWarn记录警告日志，CostCheckError使编译失败并返回ErrCostBudgetExceeded；未配置预算时不检查。
+//
+// 使用示例:
+//
+//	engine, err := New[Result](WithDSN(dsn), WithCostCheck(CostCheckError), WithLatencyBudget(200*time.Microsecond))
+//	estimate, err := engine.EstimateCost(ctx, "RISK_CHECK")
+func WithCostCheck(mode CostCheckMode) Option {
+	return func(ctx *RuntimeContext) error {
+		ctx.config.CostCheck = mode
+		return nil
+	}
+}
+
+// WithLatencyBudget 设置单次执行的延迟预算，供 WithCostCheck 比较，<=0表示不限制
+func WithLatencyBudget(budget time.Duration) Option {
+	return func(ctx *RuntimeContext) error {
+		ctx.config.LatencyBudget = budget
+		return nil
+	}
+}
+
+// WithBizLatencyBudget 设置业务码的延迟预算，覆盖 WithLatencyBudget
+func WithBizLatencyBudget(bizCode string, budget time.Duration) Option {
+	return func(ctx *RuntimeContext) error {
+		if ctx.config.BizLatencyBudgets == nil {
+			ctx.config.BizLatencyBudgets = make(map[string]time.Duration)
+		}
+		ctx.config.BizLatencyBudgets[bizCode] = budget
+		return nil
+	}
+}
+
+// WithLatencySLO 设置业务码的延迟SLO - 统计窗口内的p95/p99执行延迟，超出目标时告警并标记为不健康
+//
+// 延迟包含获取规则、编译和执行，失败的执行同样计入。启用层级继承时子业务码使用父业务码的SLO。
+// 状态变化（违反、恢复）时调用 WithSLOAlerter 设置的回调，告警附带规则负责人（Rule.Owner）；
+// 当前状态通过 SLOStatus 和诊断快照查看。
+//
+// 使用示例:
+//
+//	engine, err := New[Result](WithDSN(dsn),
+//	    WithLatencySLO("RISK_CHECK", LatencySLO{P95: 2 * time.Millisecond, P99: 5 * time.Millisecond}),
+//	    WithSLOAlerter(SLOAlertFunc(func(ctx context.Context, alert SLOAlert) {
+//	        pager.Notify(alert.Owners, alert.String())
+//	    })),
+//	)
+func WithLatencySLO(bizCode string, slo LatencySLO) Option {
+	return func(ctx *RuntimeContext) error {
+		if ctx.config.LatencySLOs == nil {
+			ctx.config.LatencySLOs = make(map[string]config.LatencySLO)
+		}
+		ctx.config.LatencySLOs[bizCode] = slo
+		return nil
+	}
+}
+
+// WithLatencySLOWindow 设置延迟SLO的统计窗口和最小样本数，<=0时分别使用5分钟和100
+func WithLatencySLOWindow(window time.Duration, minSamples int) Option {
+	return func(ctx *RuntimeContext) error {
+		ctx.config.LatencySLOWindow = window
+		ctx.config.LatencySLOMinSamples = minSamples
+		return nil
+	}
+}
+
+// WithSLOAlerter 设置延迟SLO告警回调，未设置时输出警告日志
+func WithSLOAlerter(alerter SLOAlerter) Option {
+	return func(ctx *RuntimeContext) error {
+		ctx.SLOAlerter = alerter
+		return nil
+	}
+}
+
+// WithRuleLimits 设置规则集的默认规模与复杂度限制 - 复制租户规则集、添加运行时覆盖时检查，
+// 超出时返回 *RuleLimitError；应用的发布流程可通过 CheckRuleLimits 使用同一限制
+//
+// MaxRules 限制业务码的规则数（含禁用规则），MaxConditionDepth 限制单条规则when条件的括号嵌套深度，
+// MaxGRLBytes 限制单条规则GRL的字节数，<=0的项不限制。
+//
+// 使用示例:
+//
+//	engine, err := New[Result](WithDSN(dsn),
+//	    WithRuleLimits(RuleLimits{MaxRules: 500, MaxConditionDepth: 8, MaxGRLBytes: 64 << 10}),
+//	    WithTenantRuleLimits("trial", RuleLimits{MaxRules: 50, MaxConditionDepth: 4, MaxGRLBytes: 8 << 10}),
+//	)
+func WithRuleLimits(limits RuleLimits) Option {
+	return func(ctx *RuntimeContext) error {
+		ctx.config.RuleLimits = limits
+		return nil
+	}
+}
+
+// WithTenantRuleLimits 设置租户（业务码前缀）的规则集限制，覆盖 WithRuleLimits；
+// 业务码按层级查找最近的配置，如 acme.payments 依次查找 acme.payments、acme
+func WithTenantRuleLimits(tenant string, limits RuleLimits) Option {
+	return func(ctx *RuntimeContext) error {
+		if ctx.config.TenantRuleLimits == nil {
+			ctx.config.TenantRuleLimits = make(map[string]config.RuleLimits)
+		}
+		ctx.config.TenantRuleLimits[tenant] = limits
+		return nil
+	}
+}
+
+// WithNonFinitePolicy 设置Result中出现非有限数值（NaN、±Inf）时的处理方式
+//
+// 除以零、对负数取对数等运算的结果不会使Grule报错。NonFiniteError 在执行结束后检查Result
+// （含嵌套map和切片），出现非有限数值时返回 ErrNonFiniteResult；NonFiniteSubstitute 将其替换为
+// WithNonFiniteDefault 设置的值（默认0）。规则中可改用 SafeDiv、SafeLog、SafeSqrt 按调用给出默认值。
+//
+// 使用示例:
+//
+//	engine, err := New[Result](WithDSN(dsn), WithNonFinitePolicy(NonFiniteError))
+func WithNonFinitePolicy(policy NonFinitePolicy) Option {
+	return func(ctx *RuntimeContext) error {
+		ctx.config.NonFinitePolicy = policy
+		return nil
+	}
+}
+
+// WithNonFiniteDefault 以指定值替换Result中的非有限数值，等同 WithNonFinitePolicy(NonFiniteSubstitute) 并设置替换值
+func WithNonFiniteDefault(value float64) Option {
+	return func(ctx *RuntimeContext) error {
+		ctx.config.NonFinitePolicy = NonFiniteSubstitute
+		ctx.config.NonFiniteDefault = value
+		return nil
+	}
+}
+
+// WithNullPolicy 设置缺失字段（字段不存在或值为nil）的比较语义
+//
+// NullPolicyError 使条件求值出错（如访问不存在的map键）时执行返回错误；NullPolicyFalse/NullPolicyUnknown
+// 由转换器为规则定义生成存在性判断，转换规则时使用 engine.NullPolicy(bizCode) 对应的语义。
+//
+// 使用示例:
+//
+//	engine, err := New[Result](WithDSN(dsn), WithNullPolicy(NullPolicyUnknown))
+func WithNullPolicy(policy NullPolicy) Option {
+	return func(ctx *RuntimeContext) error {
+		ctx.config.NullPolicy = policy
+		return nil
+	}
+}
+
+// WithBizNullPolicy 设置业务码的缺失字段比较语义，覆盖 WithNullPolicy
+func WithBizNullPolicy(bizCode string, policy NullPolicy) Option {
+	return func(ctx *RuntimeContext) error {
+		if ctx.config.BizNullPolicies == nil {
+			ctx.config.BizNullPolicies = make(map[string]NullPolicy)
+		}
+		ctx.config.BizNullPolicies[bizCode] = policy
+		return nil
+	}
+}
+
+// WithExecMode 设置规则执行模式 - 由引擎控制一次执行命中的规则数，无需在规则中手写Retract编排
+//
+// ExecModeFirstMatch 按规则顺序（获取顺序，启用继承时子级在前）只执行第一条条件成立的规则，忽略salience；
+// ExecModeBestMatch 只执行条件成立的规则中salience最高的一条。命中规则的动作完整执行后结束本次执行。
+//
+// 使用示例:
+//
+//	engine, err := New[Result](WithDSN(dsn), WithExecMode(ExecModeFirstMatch))
+func WithExecMode(mode ExecMode) Option {
+	return func(ctx *RuntimeContext) error {
+		ctx.config.ExecMode = mode
+		return nil
+	}
+}
+
+// WithBizExecMode 设置业务码的规则执行模式，覆盖 WithExecMode
+func WithBizExecMode(bizCode string, mode ExecMode) Option {
+	return func(ctx *RuntimeContext) error {
+		if ctx.config.BizExecModes == nil {
+			ctx.config.BizExecModes = make(map[string]ExecMode)
+		}
+		ctx.config.BizExecModes[bizCode] = mode
+		return nil
+	}
+}
+
+// WithCacheTTL 设置缓存生存时间
+func WithCacheTTL(ttl time.Duration) Option {
+	return func(ctx *RuntimeContext) error {
+		ctx.config.CacheTTL = ttl
+		return nil
+	}
+}
+
+// WithMaxCacheSize 设置最大缓存大小
+func WithMaxCacheSize(size int) Option {
+	return func(ctx *RuntimeContext) error {
+		ctx.config.MaxCacheSize = size
+		return nil
+	}
+}
+
+// WithSyncInterval 设置同步间隔
+func WithSyncInterval(interval time.Duration) Option {
+	return func(ctx *RuntimeContext) error {
+		ctx.config.SyncInterval = interval
+		return nil
+	}
+}
+
+// ============================================================================
+// 执行选项 - 作用于单次Exec调用
+// ============================================================================
+
+// ExecOption 执行选项
+type ExecOption = engine.ExecOption
+
+// ExecFunc 规则执行函数 - 执行中间件包裹的对象
+type ExecFunc = engine.ExecFunc
+
+// ExecMiddleware 执行中间件 - func(next ExecFunc) ExecFunc
+type ExecMiddleware = engine.ExecMiddleware
+
+// WithIdempotencyKey 设置幂等键 - 窗口期内相同 (bizCode, 规则版本, key) 直接返回已存储的结果
+//
+// 注意: 结果通过JSON序列化存储，命中时map结果中的数值类型为float64
+func WithIdempotencyKey(key string) ExecOption {
+	return engine.WithIdempotencyKey(key)
+}
+
+// WithVersion 固定执行的规则集版本 - 效果同 ExecVersion，可用于 BaseEngine.ExecRaw
+func WithVersion(version int) ExecOption {
+	return engine.WithVersion(version)
+}
+
+// WithRuleSetHash 按内容哈希固定执行的规则集 - 哈希从 ExecReport.RuleSetHash 获取，
+// 规则最大版本号不变而内容变化时也能区分，与 WithVersion 同时设置时以哈希为准
+func WithRuleSetHash(hash string) ExecOption {
+	return engine.WithRuleSetHash(hash)
+}
+
+// WithSelector 设置规则选择器 - 效果同 ExecWhere，可用于 BaseEngine.ExecRaw
+func WithSelector(selector string) ExecOption {
+	return engine.WithSelector(selector)
+}
+
+// ExecReport 执行报告 - 执行结束后填充降级等信息
+type ExecReport = engine.ExecReport
+
+// WithExecReport 设置执行报告
+//
+// 使用示例:
+//
+//	var report ExecReport
+//	result, err := engine.Exec(ctx, "RISK_CHECK", input, WithExecReport(&report))
+//	if report.Degraded {
+//	    // 返回的是降级结果
+//	}
+func WithExecReport(report *ExecReport) ExecOption {
+	return engine.WithExecReport(report)
+}
+
+// WithProfiling 启用执行剖析 - 执行结束后将周期、事实断言和条件求值耗时写入 ExecReport.Profile
+//
+// 使用示例:
+//
+//	var report ExecReport
+//	_, err := engine.Exec(ctx, "RISK_CHECK", input, WithExecReport(&report), WithProfiling())
+//	for _, r := range report.Profile.Rules {
+//	    fmt.Println(r.Rule, r.Evaluations, r.EvalTime)
+//	}
+func WithProfiling() ExecOption {
+	return engine.WithProfiling()
+}
+
+// WithResultJournal 启用结果变更日志 - 执行结束后将每条规则对Result的写入记录到 ExecReport.Journal
+//
+// 使用示例:
+//
+//	var report ExecReport
+//	_, err := engine.Exec(ctx, "RISK_CHECK", input, WithExecReport(&report), WithResultJournal())
+//	for _, c := range report.Journal {
+//	    fmt.Printf("%s 将 %s 从 %v 改为 %v\n", c.Rule, c.Key, c.Old, c.New)
+//	}
+func WithResultJournal() ExecOption {
+	return engine.WithResultJournal()
+}
+
+// WithResultStream 设置结果流式输出 - 规则以 Emit(key, 元素) 产出的元素依次交给yield，不写入结果
+//
+// 元素数达到maxResults（<=0不限制）后丢弃之后产出的元素，ExecReport.Streamed 和 ExecReport.Truncated 记录输出情况；
+// yield 返回错误时中止执行并返回该错误。未配置流式输出的键，Emit 与 AppendTo 相同追加到结果列表。
+//
+// 使用示例:
+//
+//	result, err := engine.Exec(ctx, "OFFERS", input, WithResultStream("offers", 5000, func(offer any) error {
+//	    return encoder.Encode(offer)
+//	}))
+func WithResultStream(key string, maxResults int, yield func(element any) error) ExecOption {
+	return engine.WithResultStream(key, maxResults, yield)
+}
+
+// WithTenant 设置执行所属的租户 - 用于 WithQuota 的配额检查和用量上报
+func WithTenant(tenant string) ExecOption {
+	return engine.WithTenant(tenant)
+}
+
+// WithParams 设置执行参数 - 与业务输入分离，以 Config 对象注入，便于按环境调整阈值而无需修改规则
+//
+// 使用示例:
+//
+//	result, err := engine.Exec(ctx, "RISK_CHECK", input, WithParams(map[string]any{"threshold_override": 0.8}))
+//	// 规则中: when Params["score"] >= Config["threshold_override"] then ...
+func WithParams(params map[string]any) ExecOption {
+	return engine.WithParams(params)
+}
+
+// ExecutionProfile 执行剖析结果
+type ExecutionProfile = engine.ExecutionProfile
+
+// CycleProfile 单个执行周期的统计
+type CycleProfile = engine.CycleProfile
+
+// RuleProfile 单条规则的剖析统计
+type RuleProfile = engine.RuleProfile
+
+// ResultChange Result字段的一次变更
+type ResultChange = engine.ResultChange
+
+// Explanation 决策解释 - Explain 的返回值
+type Explanation = engine.Explanation
+
+// FieldExplanation Result字段的解释
+type FieldExplanation = engine.FieldExplanation
+
+// ExecMetrics 执行指标
+type ExecMetrics = engine.ExecMetrics
+
+// RetentionReport 单次数据清理报告
+type RetentionReport = engine.RetentionReport
+
+// RetentionStats 数据清理累计统计
+type RetentionStats = engine.RetentionStats
+
+// RetentionTarget 按时间清理的数据保留目标
+type RetentionTarget = rule.RetentionTarget
+
+// QueryPlan 规则获取查询的执行计划
+type QueryPlan = rule.QueryPlan
+
+// CompileInfo 知识库编译信息 - 包含规则数、AST节点数和估算的内存占用
+type CompileInfo = engine.CompileInfo
+
+// CoercionRecord 输入类型转换记录
+type CoercionRecord = engine.CoercionRecord
+
+// FieldError 字段错误 - 规则通过 Errors.AddError 记录
+type FieldError = engine.FieldError
+
+// ReasonCode 决策原因 - 规则通过 AddReason 记录，按影响排序后写入 Result["reasons"]
+type ReasonCode = engine.ReasonCode
+
+// CloneReport 租户规则复制报告
+type CloneReport = engine.CloneReport
+
+// ClonedRule 复制的规则
+type ClonedRule = engine.ClonedRule
+
+// CloneCollision 复制冲突 - 目标业务码已存在同名规则
+type CloneCollision = engine.CloneCollision
+
+// RuleTestReport 规则测试报告
+type RuleTestReport = engine.RuleTestReport
+
+// RuleTestResult 单个用例的测试结果
+type RuleTestResult = engine.RuleTestResult
+
+// RuleTestMismatch 结果字段不一致
+type RuleTestMismatch = engine.RuleTestMismatch
+
+// DecisionStats 业务码决策分布
+type DecisionStats = engine.DecisionStats
+
+// FieldDistribution 结果字段取值分布
+type FieldDistribution = engine.FieldDistribution
+
+// BucketCount 数值分桶计数
+type BucketCount = engine.BucketCount
+
+// DecisionStatsExporter 决策分布导出器
+type DecisionStatsExporter = engine.DecisionStatsExporter
+
+// DecisionStatsExporterFunc 函数形式的决策分布导出器
+type DecisionStatsExporterFunc = engine.DecisionStatsExporterFunc
+
+// AnomalyAlert 决策分布异常告警
+type AnomalyAlert = engine.AnomalyAlert
+
+// AnomalyKind 异常类型
+type AnomalyKind = engine.AnomalyKind
+
+// AnomalyAlerter 异常告警回调
+type AnomalyAlerter = engine.AnomalyAlerter
+
+// AnomalyAlertFunc 函数形式的异常告警回调
+type AnomalyAlertFunc = engine.AnomalyAlertFunc
+
+// DeadRule 检测窗口内未命中的规则
+type DeadRule = engine.DeadRule
+
+// DeadRuleReport 业务码失效规则报告
+type DeadRuleReport = engine.DeadRuleReport
+
+// DeadRuleReporter 失效规则报告回调
+type DeadRuleReporter = engine.DeadRuleReporter
+
+// DeadRuleReporterFunc 函数形式的失效规则报告回调
+type DeadRuleReporterFunc = engine.DeadRuleReporterFunc
+
+// AlertSink 规则告警通道
+type AlertSink = alert.Sink
+
+// QuotaProvider 配额提供者
+type QuotaProvider = engine.QuotaProvider
+
+// RateProvider 汇率提供者
+type RateProvider = engine.RateProvider
+
+// RateFunc 函数形式的汇率提供者
+type RateFunc = engine.RateFunc
+
+// Rate 汇率 - 1单位源货币可兑换 Value 单位目标货币
+type Rate = engine.Rate
+
+// CalendarProvider 节假日日历提供者
+type CalendarProvider = engine.CalendarProvider
+
+// Calendar 从YAML加载的节假日日历
+type Calendar = engine.Calendar
+
+// Clock 规则时间函数使用的时钟
+type Clock = engine.Clock
+
+// ClockFunc 函数形式的时钟
+type ClockFunc = engine.ClockFunc
+
+// QuotaFunc 函数形式的配额提供者
+type QuotaFunc = engine.QuotaFunc
+
+// Usage 单次执行的用量
+type Usage = engine.Usage
+
+// UsageReporter 用量上报
+type UsageReporter = engine.UsageReporter
+
+// UsageReportFunc 函数形式的用量上报
+type UsageReportFunc = engine.UsageReportFunc
+
+// RuleLayer 规则来源层
+type RuleLayer = engine.RuleLayer
+
+// 规则来源层，优先级由低到高
+const (
+	LayerEmbedded = engine.LayerEmbedded // 内置默认规则（WithEmbeddedRules）
+	LayerDatabase = engine.LayerDatabase // 数据库规则
+	LayerOverride = engine.LayerOverride // 运行时覆盖（AddOverride）
+)
+
+// ResolvedRule 生效规则及其来源层
+type ResolvedRule = engine.ResolvedRule
+
+// RuleResolution 业务码的规则分层解析结果
+type RuleResolution = engine.RuleResolution
+
+// Disablement 业务码紧急停用记录
+type Disablement = engine.Disablement
+
+// AuditAction 审计操作类型
+type AuditAction = engine.AuditAction
+
+// 审计操作类型
+const (
+	AuditDisable = engine.AuditDisable // 紧急停用业务码
+	AuditEnable  = engine.AuditEnable  // 恢复业务码
+)
+
+// AuditEvent 运维操作审计记录
+type AuditEvent = engine.AuditEvent
+
+// AuditRecorder 审计记录
+type AuditRecorder = engine.AuditRecorder
+
+// AuditFunc 函数形式的审计记录
+type AuditFunc = engine.AuditFunc
+
+// LatencySLO 业务码延迟SLO目标
+type LatencySLO = config.LatencySLO
+
+// SLOAlert 延迟SLO告警
+type SLOAlert = engine.SLOAlert
+
+// SLOAlerter 延迟SLO告警回调
+type SLOAlerter = engine.SLOAlerter
+
+// SLOAlertFunc 函数形式的延迟SLO告警回调
+type SLOAlertFunc = engine.SLOAlertFunc
+
+// SLOStatus 业务码的延迟SLO状态
+type SLOStatus = engine.SLOStatus
+
+// InvalidationSource 上游数据变更来源
+type InvalidationSource = engine.InvalidationSource
+
+// InvalidateFunc 失效幂等结果的函数
+type InvalidateFunc = engine.InvalidateFunc
+
+// RuleLimits 规则集的规模与复杂度限制
+type RuleLimits = config.RuleLimits
+
+// RuleLimitError 规则集超限错误
+type RuleLimitError = engine.RuleLimitError
+
+// LimitViolation 超出限制的项
+type LimitViolation = rule.LimitViolation
+
+// 规则集限制项
+const (
+	LimitMaxRules          = rule.LimitMaxRules          // 业务码的最大规则数
+	LimitMaxConditionDepth = rule.LimitMaxConditionDepth // 单条规则when条件的最大嵌套深度
+	LimitMaxGRLBytes       = rule.LimitMaxGRLBytes       // 单条规则GRL的最大字节数
+)
+
+// Session 执行会话 - 以同一输入依次执行多个业务码
+type Session[T any] = engine.Session[T]
+
+// ResultStream 结果流式输出配置
+type ResultStream = engine.ResultStream
+
+// 异常类型
+const (
+	AnomalyRuleFireRate = engine.AnomalyRuleFireRate
+	AnomalyOutcomeShift = engine.AnomalyOutcomeShift
+)
+
+// WriteCheckMode 规则写入声明检查模式
+type WriteCheckMode = config.WriteCheckMode
+
+// 写入声明检查模式
+const (
+	WriteCheckOff   = config.WriteCheckOff   // 不检查
+	WriteCheckWarn  = config.WriteCheckWarn  // 记录警告日志
+	WriteCheckError = config.WriteCheckError // 编译失败
+)
+
+// CostCheckMode 规则集成本预算检查模式
+type CostCheckMode = config.CostCheckMode
+
+// 成本预算检查模式
+const (
+	CostCheckOff   = config.CostCheckOff   // 不检查
+	CostCheckWarn  = config.CostCheckWarn  // 记录警告日志
+	CostCheckError = config.CostCheckError // 编译失败
+)
+
+// CostEstimate 规则集的成本估算
+type CostEstimate = rule.CostEstimate
+
+// NonFinitePolicy Result中出现NaN、±Inf时的处理方式
+type NonFinitePolicy = config.NonFinitePolicy
+
+// 非有限数值处理方式
+const (
+	NonFiniteAllow      = config.NonFiniteAllow      // 原样返回
+	NonFiniteError      = config.NonFiniteError      // 执行返回错误
+	NonFiniteSubstitute = config.NonFiniteSubstitute // 替换为 WithNonFiniteDefault 设置的值
+)
+
+// CostTier 单次求值成本等级
+type CostTier = rule.CostTier
+
+// 成本等级
+const (
+	CostTierLow     = rule.CostTierLow     // 低于10µs
+	CostTierMedium  = rule.CostTierMedium  // 10µs ~ 100µs
+	CostTierHigh    = rule.CostTierHigh    // 100µs ~ 1ms
+	CostTierExtreme = rule.CostTierExtreme // 1ms以上
+)
+
+// NullPolicy 缺失字段的比较语义
+type NullPolicy = config.NullPolicy
+
+// 缺失字段比较语义
+const (
+	NullPolicyDefault = config.NullPolicyDefault // Grule默认：条件求值出错时规则不命中
+	NullPolicyFalse   = config.NullPolicyFalse   // 涉及缺失字段的比较为false
+	NullPolicyError   = config.NullPolicyError   // 条件引用缺失字段时执行返回错误
+	NullPolicyUnknown = config.NullPolicyUnknown // 三值逻辑
+)
+
+// ExecMode 规则执行模式
+type ExecMode = config.ExecMode
+
+// 规则执行模式
+const (
+	ExecModeAllMatching = config.ExecModeAllMatching // 执行所有条件成立的规则
+	ExecModeFirstMatch  = config.ExecModeFirstMatch  // 按规则顺序只执行第一条命中的规则
+	ExecModeBestMatch   = config.ExecModeBestMatch   // 只执行salience最高的命中规则
+)
+
+// ConfigError 单个配置问题 - 包含错误代码、字段和处理建议
+type ConfigError = config.ConfigError
+
+// ConfigErrors 配置验证错误集合 - New 配置验证失败时可通过 errors.As 获取全部问题
+type ConfigErrors = config.ValidationErrors
+
+// 配置错误代码
+const (
+	CodeMissingDSN              = config.CodeMissingDSN              // 未配置数据库DSN
+	CodeInvalidCacheType        = config.CodeInvalidCacheType        // 未知的缓存类型
+	CodeMissingRedisAddr        = config.CodeMissingRedisAddr        // 使用Redis缓存但未配置地址
+	CodeInvalidRedisDB          = config.CodeInvalidRedisDB          // Redis数据库编号为负数
+	CodeInvalidCacheSize        = config.CodeInvalidCacheSize        // 内存缓存大小不大于0
+	CodeInvalidCacheTTL         = config.CodeInvalidCacheTTL         // 启用缓存但缓存生存时间不大于0
+	CodeConflictingCacheOptions = config.CodeConflictingCacheOptions // 配置了Redis参数但未使用Redis缓存
+	CodeInvalidSyncInterval     = config.CodeInvalidSyncInterval     // 规则同步间隔为负数
+	CodeInvalidWriteCheck       = config.CodeInvalidWriteCheck       // 未知的写入声明检查模式
+	CodeInvalidAnomalyThreshold = config.CodeInvalidAnomalyThreshold // 异常告警阈值超出范围
+	CodeInvalidRetention        = config.CodeInvalidRetention        // 数据保留参数为负数
+	CodeInvalidNullPolicy       = config.CodeInvalidNullPolicy       // 未知的缺失字段比较语义
+	CodeInvalidDeadRuleWindow   = config.CodeInvalidDeadRuleWindow   // 失效规则检测参数为负数
+	CodeInvalidExecMode         = config.CodeInvalidExecMode         // 未知的规则执行模式
+	CodeInvalidCostCheck        = config.CodeInvalidCostCheck        // 未知的成本预算检查模式或延迟预算为负数
+	CodeInvalidNonFinitePolicy  = config.CodeInvalidNonFinitePolicy  // 未知的非有限数值处理方式或替换值不是有限数值
+	CodeInvalidRateCache        = config.CodeInvalidRateCache        // 汇率缓存时长或最大时效为负数
+	CodeReadOnlyConflict        = config.CodeReadOnlyConflict        // 只读模式下启用了自动迁移或数据清理
+	CodeInvalidLatencySLO       = config.CodeInvalidLatencySLO       // 延迟SLO目标、统计窗口或最小样本数为负数
+	CodeInvalidRuleLimits       = config.CodeInvalidRuleLimits       // 规则集限制为负数
+)
+
+// CompletionMetadata 自动补全元数据
+type CompletionMetadata = engine.CompletionMetadata
+
+// FieldInfo 自动补全字段信息
+type FieldInfo = engine.FieldInfo
+
+// FunctionInfo 自动补全函数信息
+type FunctionInfo = engine.FunctionInfo
+
+// ============================================================================
+// 降级选项 - 规则缺失或编译失败时的兜底结果
+// ============================================================================
+
+// FallbackProvider 降级结果提供者
+type FallbackProvider = engine.FallbackProvider
+
+// FallbackFunc 函数形式的降级结果提供者
+type FallbackFunc = engine.FallbackFunc
+
+// WithFallbackResult 设置业务码的降级结果
+//
+// 业务码无可用规则或规则编译失败时返回value且不返回错误，
+// 调用方可通过 WithExecReport 获取 Degraded 标记，value类型与T不一致时通过JSON转换。
+// 每次执行返回value的深拷贝，调用方修改降级结果不影响其他执行。
+func WithFallbackResult(bizCode string, value any) Option {
+	return func(ctx *RuntimeContext) error {
+		if ctx.fallbackResults == nil {
+			ctx.fallbackResults = make(map[string]any)
+		}
+		ctx.fallbackResults[bizCode] = value
+		return nil
+	}
+}
+
+// WithFallbackProvider 设置降级结果提供者，未通过 WithFallbackResult 配置的业务码会询问该提供者
+func WithFallbackProvider(provider FallbackProvider) Option {
+	return func(ctx *RuntimeContext) error {
+		ctx.FallbackProvider = provider
+		return nil
+	}
+}
+
+// ============================================================================
+// 实例注入选项 - 用于注入自定义实例
+// ============================================================================
+
+// WithDBPool 设置数据库连接池参数 - 同样作用于WithCustomDB传入的连接
+//
+// 参数:
+//
+//	maxOpen     - 最大打开连接数，<=0保持驱动默认值
+//	maxIdle     - 最大空闲连接数，<=0保持驱动默认值
+//	maxLifetime - 连接最大存活时间，<=0保持驱动默认值
+func WithDBPool(maxOpen, maxIdle int, maxLifetime time.Duration) Option {
+	return func(ctx *RuntimeContext) error {
+		ctx.config.DBMaxOpenConns = maxOpen
+		ctx.config.DBMaxIdleConns = maxIdle
+		ctx.config.DBConnMaxLifetime = maxLifetime
+		return nil
+	}
+}
+
+// WithSlowQueryThreshold 设置慢查询阈值 - 从数据库获取规则耗时超过阈值时记录警告日志
+func WithSlowQueryThreshold(threshold time.Duration) Option {
+	return func(ctx *RuntimeContext) error {
+		ctx.config.SlowQueryThreshold = threshold
+		return nil
+	}
+}
+
+// WithRulePaging 分页获取规则 - 每次查询pageSize条并按页拼接GRL编译，适用于规则数量巨大的业务码
+//
+// 需要规则映射器实现rule.PagedRuleMapper（内置GORM映射器已实现，按版本和ID游标分页），否则仍一次性获取。
+// 获取和缓存的规则只保留元数据与规则条目名称（Rule.Entries），编译时再按页读取GRL，
+// 业务码的全部GRL不会同时驻留内存。公共调用提取（WithHoistCommonCalls）和成本预算检查需要同时分析全部规则，
+// 启用时编译前读取全部GRL完整编译，只保留分页获取。
+func WithRulePaging(pageSize int) Option {
+	return func(ctx *RuntimeContext) error {
+		ctx.config.RulePageSize = pageSize
+		return nil
+	}
+}
+
+// WithIncrementalCompile 启用增量编译 - 规则集变更后只编译新增或修改的规则，复用其余规则的编译结果
+//
+// 规则按GRL内容哈希判断是否变更，500条规则的业务码修改一条规则时只需解析一条GRL。
+// 每个业务码额外保留一份知识库蓝本；配置 WithRulePaging 时不生效。编译信息中的 ReusedRules 为复用的规则数。
+func WithIncrementalCompile() Option {
+	return func(ctx *RuntimeContext) error {
+		ctx.config.IncrementalCompile = true
+		return nil
+	}
+}
+
+// WithHoistCommonCalls 启用公共调用提取 - 多条规则条件中相同的函数调用每次执行只求值一次
+//
+// 编译时将至少两条规则when条件中相同的调用改写为共享事实对象 Shared 的调用，参数均为字符串、数值或布尔值时按参数值缓存结果，
+// 参数为指针、结构体等可变事实时每次重新求值。被提取的函数在一次执行内应当是确定的；编译信息中的 SharedCalls 为提取的调用。
+func WithHoistCommonCalls() Option {
+	return func(ctx *RuntimeContext) error {
+		ctx.config.HoistCommonCalls = true
+		return nil
+	}
+}
+
+// WithRuleCountWarning 设置业务码规则数量告警阈值 - 获取的规则数超过阈值时记录警告日志
+func WithRuleCountWarning(threshold int) Option {
+	return func(ctx *RuntimeContext) error {
+		ctx.config.RuleCountWarnThreshold = threshold
+		return nil
+	}
+}
+
+// WithBizCodeInheritance 启用业务码层级继承
+//
+// 以"."分隔的业务码执行时同时包含各级父业务码的规则，如 payments.cards.fraud 包含
+// payments.cards 和 payments 的规则；子级与父级存在同名规则（Rule.Name）时子级覆盖父级。
+func WithBizCodeInheritance() Option {
+	return func(ctx *RuntimeContext) error {
+		ctx.config.BizCodeInheritance = true
+		return nil
+	}
+}
+
+// WithEnvironment 设置引擎运行环境 - 同一数据库可保存各环境的规则变体
+//
+// 规则通过 Rule.Environment 标记所属环境（如 dev、staging、prod），为空的是默认变体。
+// 同名规则存在当前环境的变体时使用该变体，否则使用默认变体；其他环境的变体不参与执行。
+// 未设置运行环境时只执行默认变体。
+//
+// 使用示例:
+//
+//	engine, err := New[Result](WithDSN(dsn), WithEnvironment(os.Getenv("APP_ENV")))
+func WithEnvironment(env string) Option {
+	return func(ctx *RuntimeContext) error {
+		ctx.config.Environment = env
+		return nil
+	}
+}
+
+// WithCustomDB 设置自定义数据库实例
+func WithCustomDB(db *gorm.DB) Option {
+	return func(ctx *RuntimeContext) error {
+		ctx.DB = db
+		ctx.config.DSN = "__CUSTOM_DB__"
+		if db != nil {
+			ctx.claim(SettingDatabase, "WithCustomDB", claimInstance)
+		} else {
+			ctx.claim(SettingDatabase, "WithCustomDB(nil)", claimIgnored)
+		}
+		return nil
+	}
+}
+
+// WithCustomCache 设置自定义缓存实例
+func WithCustomCache(cache cache.Cache) Option {
+	return func(ctx *RuntimeContext) error {
+		ctx.Cache = cache
+		if cache == nil {
+			ctx.claim(SettingCache, "WithCustomCache(nil)", claimIgnored)
+			return nil
+		}
+		ctx.config.CacheType = config.CacheTypeNone
+		ctx.clearRedisConfig()
+		ctx.claim(SettingCache, "WithCustomCache", claimInstance)
+		return nil
+	}
+}
+
+// WithCustomLogger 设置自定义日志实例
+func WithCustomLogger(logger logger.Logger) Option {
+	return func(ctx *RuntimeContext) error {
+		ctx.Logger = logger
+		return nil
+	}
+}
+
+// WithRequestLogger 设置请求级日志记录器 - 执行期间的日志使用fn按Exec的ctx返回的日志记录器
+//
+// fn返回nil时使用引擎日志记录器（WithCustomLogger）；编译、定时同步等不属于单次执行的日志同样使用引擎日志记录器。
+//
+// 使用示例:
+//
+//	WithRequestLogger(func(ctx context.Context) logger.Logger {
+//	    if traceID, ok := ctx.Value(traceKey{}).(string); ok {
+//	        return baseLogger.With("trace_id", traceID)
+//	    }
+//	    return nil
+//	})
+func WithRequestLogger(fn func(ctx context.Context) logger.Logger) Option {
+	return func(ctx *RuntimeContext) error {
+		ctx.RequestLogger = fn
 		return nil
 	}
 }
@@ -2,16 +2,23 @@ package runehammer
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
+	"testing/fstest"
 	"time"
 
+	"gitee.com/damengde/runehammer/alert"
 	"gitee.com/damengde/runehammer/cache"
 	"gitee.com/damengde/runehammer/config"
 	"gitee.com/damengde/runehammer/engine"
+	"gitee.com/damengde/runehammer/lock"
 	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/pubsub"
 	"gitee.com/damengde/runehammer/rule"
+	"gitee.com/damengde/runehammer/runehammertest"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
 	. "github.com/smartystreets/goconvey/convey"
 	"go.uber.org/mock/gomock"
 	"gorm.io/driver/sqlite"
@@ -55,6 +62,13 @@ func (s *stubCache) Set(ctx context.Context, key string, value []byte, ttl time.
 func (s *stubCache) Del(ctx context.Context, key string) error { return nil }
 func (s *stubCache) Close() error                              { return s.closeErr }
 
+// invalidationSourceFunc 函数形式的数据变更来源
+type invalidationSourceFunc func(ctx context.Context, invalidate InvalidateFunc) (func(), error)
+
+func (f invalidationSourceFunc) Watch(ctx context.Context, invalidate InvalidateFunc) (func(), error) {
+	return f(ctx, invalidate)
+}
+
 // --- 额外覆盖率测试 ---
 
 func TestConvertToTypeAndOptions(t *testing.T) {
@@ -128,6 +142,605 @@ func TestConvertToTypeAndOptions(t *testing.T) {
 			So(ctx.config.SyncInterval, ShouldEqual, 3*time.Minute)
 		})
 
+		Convey("WithDBPool 和 WithSlowQueryThreshold", func() {
+			So(WithDBPool(20, 5, time.Hour)(ctx), ShouldBeNil)
+			So(ctx.config.DBMaxOpenConns, ShouldEqual, 20)
+			So(ctx.config.DBMaxIdleConns, ShouldEqual, 5)
+			So(ctx.config.DBConnMaxLifetime, ShouldEqual, time.Hour)
+			So(WithSlowQueryThreshold(200*time.Millisecond)(ctx), ShouldBeNil)
+			So(ctx.config.SlowQueryThreshold, ShouldEqual, 200*time.Millisecond)
+		})
+
+		Convey("WithBizCodeInheritance 启用层级继承", func() {
+			So(WithBizCodeInheritance()(ctx), ShouldBeNil)
+			So(ctx.config.BizCodeInheritance, ShouldBeTrue)
+		})
+
+		Convey("WithEnvironment 设置运行环境", func() {
+			So(WithEnvironment("prod")(ctx), ShouldBeNil)
+			So(ctx.config.Environment, ShouldEqual, "prod")
+		})
+
+		Convey("WithFieldErrors 启用字段错误累积", func() {
+			So(WithFieldErrors()(ctx), ShouldBeNil)
+			So(ctx.config.FieldErrors, ShouldBeTrue)
+		})
+
+		Convey("WithRolloutKey 设置放量分桶键", func() {
+			So(WithRolloutKey("customer.id")(ctx), ShouldBeNil)
+			So(ctx.config.RolloutKeyField, ShouldEqual, "customer.id")
+		})
+
+		Convey("WithDecisionStats 启用决策分布统计", func() {
+			So(WithDecisionStats([]string{"approved", "score"}, []float64{300, 600})(ctx), ShouldBeNil)
+			So(ctx.config.DecisionStatsFields, ShouldResemble, []string{"approved", "score"})
+			So(ctx.config.DecisionStatsBuckets, ShouldResemble, []float64{300, 600})
+			So(WithDecisionStatsSampling(0.1)(ctx), ShouldBeNil)
+			So(ctx.config.DecisionStatsSampleRate, ShouldEqual, 0.1)
+
+			exporter := DecisionStatsExporterFunc(func(context.Context, []DecisionStats) error { return nil })
+			So(WithDecisionStatsExport(time.Minute, exporter)(ctx), ShouldBeNil)
+			So(ctx.config.DecisionStatsExportInterval, ShouldEqual, time.Minute)
+			So(ctx.DecisionExporter, ShouldNotBeNil)
+		})
+
+		Convey("WithAnomalyAlerts 启用异常告警", func() {
+			alerter := AnomalyAlertFunc(func(context.Context, AnomalyAlert) {})
+			So(WithAnomalyAlerts(0.2, 500, alerter)(ctx), ShouldBeNil)
+			So(ctx.config.AnomalyThreshold, ShouldEqual, 0.2)
+			So(ctx.config.AnomalyMinSamples, ShouldEqual, 500)
+			So(ctx.AnomalyAlerter, ShouldNotBeNil)
+		})
+
+		Convey("WithDeadRuleDetection 启用失效规则检测", func() {
+			reporter := DeadRuleReporterFunc(func(context.Context, []DeadRuleReport) error { return nil })
+			So(WithDeadRuleDetection(30*24*time.Hour, time.Hour, reporter)(ctx), ShouldBeNil)
+			So(ctx.config.DeadRuleWindow, ShouldEqual, 30*24*time.Hour)
+			So(ctx.config.DeadRuleCheckInterval, ShouldEqual, time.Hour)
+			So(ctx.DeadRuleReporter, ShouldNotBeNil)
+
+			So(WithDeadRuleDetection(-time.Hour, 0, nil)(ctx), ShouldBeNil)
+			So(ctx.config.Validate().(ConfigErrors).Has(CodeInvalidDeadRuleWindow), ShouldBeTrue)
+		})
+
+		Convey("WithAlertSink 设置规则告警通道", func() {
+			So(WithAlertSink(alert.NewWebhookSink("http://localhost/alert"))(ctx), ShouldBeNil)
+			So(ctx.AlertSink, ShouldNotBeNil)
+		})
+
+		Convey("WithQuota 设置配额检查和用量上报", func() {
+			provider := QuotaFunc(func(context.Context, string, string) error { return ErrQuotaExceeded })
+			reporter := UsageReportFunc(func(context.Context, Usage) {})
+			So(WithQuota(provider, reporter)(ctx), ShouldBeNil)
+			So(ctx.QuotaProvider, ShouldNotBeNil)
+			So(ctx.UsageReporter, ShouldNotBeNil)
+		})
+
+		Convey("数据保留选项", func() {
+			target := rule.NewTableRetention(nil, "decision_audit", "created_at")
+			So(WithAuditRetention(90*24*time.Hour, target)(ctx), ShouldBeNil)
+			So(WithRuleVersionRetention(10)(ctx), ShouldBeNil)
+			So(WithRetentionSchedule(30*time.Minute, 1000)(ctx), ShouldBeNil)
+			So(ctx.config.AuditRetention, ShouldEqual, 90*24*time.Hour)
+			So(ctx.RetentionTargets, ShouldResemble, []RetentionTarget{target})
+			So(ctx.config.RuleVersionRetention, ShouldEqual, 10)
+			So(ctx.config.RetentionInterval, ShouldEqual, 30*time.Minute)
+			So(ctx.config.RetentionBatchSize, ShouldEqual, 1000)
+
+			ctx.config.RuleVersionRetention = -1
+			So(ctx.config.Validate().(ConfigErrors).Has(CodeInvalidRetention), ShouldBeTrue)
+		})
+
+		Convey("缺失字段语义选项", func() {
+			So(WithNullPolicy(NullPolicyFalse)(ctx), ShouldBeNil)
+			So(WithBizNullPolicy("RISK", NullPolicyError)(ctx), ShouldBeNil)
+			So(ctx.config.NullPolicy, ShouldEqual, NullPolicyFalse)
+			So(ctx.config.BizNullPolicies, ShouldResemble, map[string]NullPolicy{"RISK": NullPolicyError})
+
+			So(WithBizNullPolicy("PRICE", "null")(ctx), ShouldBeNil)
+			So(ctx.config.Validate().(ConfigErrors).Has(CodeInvalidNullPolicy), ShouldBeTrue)
+		})
+
+		Convey("WithKnowledgeLibrary 使用已有知识库", func() {
+			lib := ast.NewKnowledgeLibrary()
+			So(WithKnowledgeLibrary(lib)(ctx), ShouldBeNil)
+			So(ctx.KnowledgeLibrary, ShouldEqual, lib)
+		})
+
+		Convey("执行模式选项", func() {
+			So(WithExecMode(ExecModeBestMatch)(ctx), ShouldBeNil)
+			So(WithBizExecMode("PRICE", ExecModeFirstMatch)(ctx), ShouldBeNil)
+			So(ctx.config.ExecMode, ShouldEqual, ExecModeBestMatch)
+			So(ctx.config.BizExecModes, ShouldResemble, map[string]ExecMode{"PRICE": ExecModeFirstMatch})
+
+			So(WithBizExecMode("RISK", "last")(ctx), ShouldBeNil)
+			So(ctx.config.Validate().(ConfigErrors).Has(CodeInvalidExecMode), ShouldBeTrue)
+		})
+
+		Convey("WithSimilarityMaxLength 设置相似度函数最大输入长度", func() {
+			So(WithSimilarityMaxLength(200)(ctx), ShouldBeNil)
+			So(ctx.config.SimilarityMaxLength, ShouldEqual, 200)
+		})
+
+		Convey("WithLocale 设置默认区域", func() {
+			So(WithLocale("zh-CN")(ctx), ShouldBeNil)
+			So(ctx.config.Locale, ShouldEqual, "zh-CN")
+		})
+
+		Convey("WithErrorLocale 设置错误信息区域", func() {
+			So(WithErrorLocale("en-US")(ctx), ShouldBeNil)
+			So(ctx.config.ErrorLocale, ShouldEqual, "en-US")
+			So(ctx.config.Locale, ShouldBeEmpty)
+		})
+
+		Convey("WithClock 设置时钟", func() {
+			clock := runehammertest.NewClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+			So(WithClock(clock)(ctx), ShouldBeNil)
+			So(ctx.Clock, ShouldEqual, clock)
+		})
+
+		Convey("成本预算选项", func() {
+			So(WithCostCheck(CostCheckError)(ctx), ShouldBeNil)
+			So(WithLatencyBudget(200*time.Microsecond)(ctx), ShouldBeNil)
+			So(WithBizLatencyBudget("RISK", 50*time.Microsecond)(ctx), ShouldBeNil)
+			So(ctx.config.CostCheck, ShouldEqual, CostCheckError)
+			So(ctx.config.LatencyBudget, ShouldEqual, 200*time.Microsecond)
+			So(ctx.config.BizLatencyBudgets, ShouldResemble, map[string]time.Duration{"RISK": 50 * time.Microsecond})
+
+			So(WithBizLatencyBudget("PRICE", -time.Second)(ctx), ShouldBeNil)
+			So(ctx.config.Validate().(ConfigErrors).Has(CodeInvalidCostCheck), ShouldBeTrue)
+		})
+
+		Convey("非有限数值选项", func() {
+			So(WithNonFinitePolicy(NonFiniteError)(ctx), ShouldBeNil)
+			So(ctx.config.NonFinitePolicy, ShouldEqual, NonFiniteError)
+
+			So(WithNonFiniteDefault(-1)(ctx), ShouldBeNil)
+			So(ctx.config.NonFinitePolicy, ShouldEqual, NonFiniteSubstitute)
+			So(ctx.config.NonFiniteDefault, ShouldEqual, -1)
+
+			So(WithNonFinitePolicy("ignore")(ctx), ShouldBeNil)
+			So(ctx.config.Validate().(ConfigErrors).Has(CodeInvalidNonFinitePolicy), ShouldBeTrue)
+		})
+
+		Convey("汇率选项", func() {
+			provider := RateFunc(func(ctx context.Context, from, to string) (Rate, error) { return Rate{Value: 7.2}, nil })
+			So(WithRateProvider(provider)(ctx), ShouldBeNil)
+			So(WithRateCacheTTL(5*time.Minute)(ctx), ShouldBeNil)
+			So(WithRateMaxAge(24*time.Hour)(ctx), ShouldBeNil)
+			So(ctx.RateProvider, ShouldNotBeNil)
+			So(ctx.config.RateCacheTTL, ShouldEqual, 5*time.Minute)
+			So(ctx.config.RateMaxAge, ShouldEqual, 24*time.Hour)
+
+			So(WithRateMaxAge(-time.Hour)(ctx), ShouldBeNil)
+			So(ctx.config.Validate().(ConfigErrors).Has(CodeInvalidRateCache), ShouldBeTrue)
+		})
+
+		Convey("WithCalendar 设置节假日日历", func() {
+			calendar, err := ParseCalendar([]byte("CN:\n  holidays: [2024-10-01]\n"))
+			So(err, ShouldBeNil)
+			So(WithCalendar(calendar)(ctx), ShouldBeNil)
+			So(ctx.Calendar, ShouldEqual, calendar)
+		})
+
+		Convey("WithSchemaCheck 启动时检查表结构", func() {
+			So(WithSchemaCheck()(ctx), ShouldBeNil)
+			So(ctx.config.SchemaCheck, ShouldBeTrue)
+
+			_, err := New[map[string]any](WithDSN("sqlite:file:schema_check?mode=memory"), WithSchemaCheck())
+			So(errors.Is(err, ErrSchemaMismatch), ShouldBeTrue)
+
+			eng, err := New[map[string]any](WithDSN("sqlite:file:schema_check_migrated?mode=memory"), WithAutoMigrate(), WithSchemaCheck())
+			So(err, ShouldBeNil)
+			So(eng.Close(), ShouldBeNil)
+		})
+
+		Convey("WithIndexAdvisor 启动时诊断规则查询", func() {
+			So(WithIndexAdvisor()(ctx), ShouldBeNil)
+			So(ctx.config.IndexAdvisor, ShouldBeTrue)
+
+			eng, err := New[map[string]any](WithDSN("sqlite:file:index_advisor?mode=memory"), WithAutoMigrate(), WithIndexAdvisor())
+			So(err, ShouldBeNil)
+			So(eng.Close(), ShouldBeNil)
+		})
+
+		Convey("WithReadOnly 启用只读模式", func() {
+			ctx.config.DSN = "sqlite:file::memory:"
+			So(WithReadOnly()(ctx), ShouldBeNil)
+			So(ctx.config.ReadOnly, ShouldBeTrue)
+			So(ctx.config.Validate(), ShouldBeNil)
+
+			So(WithAutoMigrate()(ctx), ShouldBeNil)
+			So(WithRuleVersionRetention(3)(ctx), ShouldBeNil)
+			var errs ConfigErrors
+			So(errors.As(ctx.config.Validate(), &errs), ShouldBeTrue)
+			So(errs.Codes(), ShouldResemble, []string{CodeReadOnlyConflict, CodeReadOnlyConflict})
+		})
+
+		Convey("WithEmbeddedRules 加载内置默认规则", func() {
+			fsys := fstest.MapFS{
+				"rules/loan/limit.grl": {Data: []byte(`rule Limit "额度" { when true then Result["limit"] = 100; Retract("Limit"); }`)},
+			}
+			So(WithEmbeddedRules(fsys, "rules/*/*.grl")(ctx), ShouldBeNil)
+			So(ctx.EmbeddedRules, ShouldHaveLength, 1)
+			So(ctx.EmbeddedRules[0].BizCode, ShouldEqual, "loan")
+
+			So(WithEmbeddedRules(fsys, "missing/*.grl")(ctx), ShouldNotBeNil)
+		})
+
+		Convey("WithPubSub 设置实例间消息通道", func() {
+			bus := pubsub.NewMemory()
+			So(WithPubSub(bus)(ctx), ShouldBeNil)
+			So(ctx.PubSub, ShouldEqual, bus)
+		})
+
+		Convey("WithAuditRecorder 设置审计记录并在紧急停用时写入", func() {
+			var audits []AuditEvent
+			So(WithAuditRecorder(AuditFunc(func(_ context.Context, event AuditEvent) error {
+				audits = append(audits, event)
+				return nil
+			}))(ctx), ShouldBeNil)
+			So(ctx.AuditRecorder, ShouldNotBeNil)
+
+			eng, err := New[map[string]any](WithDSN("sqlite:file:kill_switch?mode=memory"), WithAutoMigrate(),
+				WithAuditRecorder(ctx.AuditRecorder))
+			So(err, ShouldBeNil)
+			defer eng.Close()
+
+			bg := context.Background()
+			So(eng.(KillSwitch).Disable(bg, "loan", "下游故障"), ShouldBeNil)
+			_, err = eng.Exec(bg, "loan", map[string]any{})
+			So(errors.Is(err, ErrBizDisabled), ShouldBeTrue)
+			So(eng.(KillSwitch).Disabled(), ShouldHaveLength, 1)
+			So(eng.(KillSwitch).Enable(bg, "loan"), ShouldBeNil)
+			So(audits, ShouldHaveLength, 2)
+			So(audits[0].Action, ShouldEqual, AuditDisable)
+		})
+
+		Convey("New 在返回前加载持久化的停用状态", func() {
+			dsn := "sqlite:file:kill_switch_restart?mode=memory&cache=shared"
+			first, err := New[map[string]any](WithDSN(dsn), WithAutoMigrate())
+			So(err, ShouldBeNil)
+			defer first.Close()
+
+			bg := context.Background()
+			So(first.(KillSwitch).Disable(bg, "loan", "下游故障"), ShouldBeNil)
+			defer first.(KillSwitch).Enable(bg, "loan")
+
+			restarted, err := New[map[string]any](WithDSN(dsn))
+			So(err, ShouldBeNil)
+			defer restarted.Close()
+			_, err = restarted.Exec(bg, "loan", map[string]any{})
+			So(errors.Is(err, ErrBizDisabled), ShouldBeTrue)
+		})
+
+		Convey("WithLatencySLO 设置延迟SLO并统计执行延迟", func() {
+			alerter := SLOAlertFunc(func(context.Context, SLOAlert) {})
+			So(WithLatencySLO("loan", LatencySLO{P95: time.Millisecond, P99: 5 * time.Millisecond})(ctx), ShouldBeNil)
+			So(WithLatencySLOWindow(time.Minute, 20)(ctx), ShouldBeNil)
+			So(WithSLOAlerter(alerter)(ctx), ShouldBeNil)
+			So(ctx.config.LatencySLOs["loan"].P99, ShouldEqual, 5*time.Millisecond)
+			So(ctx.config.LatencySLOWindow, ShouldEqual, time.Minute)
+			So(ctx.config.LatencySLOMinSamples, ShouldEqual, 20)
+			So(ctx.SLOAlerter, ShouldNotBeNil)
+
+			So(WithLatencySLO("risk", LatencySLO{P95: -time.Millisecond})(ctx), ShouldBeNil)
+			So(ctx.config.Validate().(ConfigErrors).Has(CodeInvalidLatencySLO), ShouldBeTrue)
+
+			eng, err := New[map[string]any](WithDSN("sqlite:file:latency_slo?mode=memory"), WithAutoMigrate(),
+				WithLatencySLO("loan", LatencySLO{P99: time.Second}), WithSLOAlerter(alerter))
+			So(err, ShouldBeNil)
+			defer eng.Close()
+
+			// 未找到规则的执行同样计入延迟
+			_, err = eng.Exec(context.Background(), "loan", map[string]any{})
+			So(err, ShouldNotBeNil)
+			statuses := eng.(Diagnostics).SLOStatus()
+			So(statuses, ShouldHaveLength, 1)
+			So(statuses[0].Samples, ShouldEqual, 1)
+			So(statuses[0].Healthy, ShouldBeTrue)
+		})
+
+		Convey("WithErrorLocale 切换执行错误的信息语言", func() {
+			eng, err := New[map[string]any](WithDSN("sqlite:file:error_locale?mode=memory"), WithAutoMigrate(), WithErrorLocale("en-US"))
+			So(err, ShouldBeNil)
+			defer eng.Close()
+
+			So(eng.(KillSwitch).Disable(context.Background(), "loan", "下游故障"), ShouldBeNil)
+			_, err = eng.Exec(context.Background(), "loan", map[string]any{})
+			So(errors.Is(err, ErrBizDisabled), ShouldBeTrue)
+			So(ErrorCode(err), ShouldEqual, ErrCodeBizDisabled)
+			So(err.Error(), ShouldContainSubstring, "code=biz_disabled")
+			So(err.Error(), ShouldContainSubstring, "下游故障")
+
+			var localized *LocalizedError
+			So(errors.As(LocalizeError(err, "zh-CN"), &localized), ShouldBeTrue)
+			So(localized.Error(), ShouldContainSubstring, "业务码已被紧急停用")
+			So(ErrorMessage(ErrCodeBizDisabled, LocaleEnUS), ShouldEqual, "business code is disabled by kill switch")
+		})
+
+		Convey("WithLocale 不改变执行错误的信息语言", func() {
+			eng, err := New[map[string]any](WithDSN("sqlite:file:format_locale?mode=memory"), WithAutoMigrate(), WithLocale("de-DE"))
+			So(err, ShouldBeNil)
+			defer eng.Close()
+
+			So(eng.(KillSwitch).Disable(context.Background(), "loan", "下游故障"), ShouldBeNil)
+			_, err = eng.Exec(context.Background(), "loan", map[string]any{})
+			So(ErrorCode(err), ShouldEqual, ErrCodeBizDisabled)
+			So(err.Error(), ShouldContainSubstring, "业务码已被紧急停用")
+			So(err.Error(), ShouldNotContainSubstring, "code=")
+		})
+
+		Convey("WithInvalidationSource 接入数据变更失效幂等结果", func() {
+			var invalidate InvalidateFunc
+			stopped := false
+			source := invalidationSourceFunc(func(_ context.Context, fn InvalidateFunc) (func(), error) {
+				invalidate = fn
+				return func() { stopped = true }, nil
+			})
+			So(WithInvalidationSource(source)(ctx), ShouldBeNil)
+			So(ctx.Invalidation, ShouldNotBeNil)
+
+			eng, err := New[map[string]any](WithDSN("sqlite:file:invalidation?mode=memory"), WithAutoMigrate(),
+				WithInvalidationSource(source))
+			So(err, ShouldBeNil)
+			So(invalidate, ShouldNotBeNil)
+			So(invalidate(context.Background(), "loan", "cust-42:"), ShouldBeNil)
+			So(eng.(Maintenance).Invalidate(context.Background(), "loan", ""), ShouldBeNil)
+			So(eng.Close(), ShouldBeNil)
+			So(stopped, ShouldBeTrue)
+		})
+
+		Convey("WithRuleLimits 和 WithTenantRuleLimits 限制规则集规模", func() {
+			So(WithRuleLimits(RuleLimits{MaxRules: 100, MaxConditionDepth: 8})(ctx), ShouldBeNil)
+			So(WithTenantRuleLimits("trial", RuleLimits{MaxRules: 1})(ctx), ShouldBeNil)
+			So(ctx.config.RuleLimits.MaxConditionDepth, ShouldEqual, 8)
+			So(ctx.config.TenantRuleLimits["trial"].MaxRules, ShouldEqual, 1)
+
+			So(WithTenantRuleLimits("acme", RuleLimits{MaxGRLBytes: -1})(ctx), ShouldBeNil)
+			So(ctx.config.Validate().(ConfigErrors).Has(CodeInvalidRuleLimits), ShouldBeTrue)
+
+			eng, err := New[map[string]any](WithDSN("sqlite:file:rule_limits?mode=memory"), WithAutoMigrate(),
+				WithTenantRuleLimits("trial", RuleLimits{MaxRules: 1, MaxConditionDepth: 2}))
+			So(err, ShouldBeNil)
+			defer eng.Close()
+
+			candidate := []*rule.Rule{
+				{Name: "a", GRL: `rule A "A" { when true then Retract("A"); }`},
+				{Name: "b", GRL: `rule B "B" { when ((true)) then Retract("B"); }`},
+			}
+			err = eng.(RuleAnalysis).CheckRuleLimits("trial.loan", candidate)
+			var limitErr *RuleLimitError
+			So(errors.As(err, &limitErr), ShouldBeTrue)
+			So(limitErr.Violations, ShouldHaveLength, 2)
+			So(limitErr.Violations[0].Limit, ShouldEqual, LimitMaxRules)
+			So(errors.Is(err, ErrRuleLimitExceeded), ShouldBeTrue)
+			So(eng.(RuleAnalysis).CheckRuleLimits("acme.loan", candidate), ShouldBeNil)
+
+			err = eng.(RuleLayers).AddOverride(context.Background(), "trial.loan", candidate[1], 0)
+			So(errors.Is(err, ErrRuleLimitExceeded), ShouldBeTrue)
+		})
+
+		Convey("WithIncrementalCompile 启用增量编译", func() {
+			So(WithIncrementalCompile()(ctx), ShouldBeNil)
+			So(ctx.config.IncrementalCompile, ShouldBeTrue)
+		})
+
+		Convey("WithHoistCommonCalls 启用公共调用提取", func() {
+			So(WithHoistCommonCalls()(ctx), ShouldBeNil)
+			So(ctx.config.HoistCommonCalls, ShouldBeTrue)
+		})
+
+		Convey("NewSession 以同一输入执行多个业务码", func() {
+			fsys := fstest.MapFS{
+				"rules/kyc/adult.grl":  {Data: []byte(`rule Adult "成年" { when Params["age"] >= 18 then Result["adult"] = true; Retract("Adult"); }`)},
+				"rules/risk/score.grl": {Data: []byte(`rule Score "评分" { when true then Result["score"] = Params["age"] * 2; Retract("Score"); }`)},
+			}
+			base, err := NewBaseEngine(WithDSN("sqlite:file:exec_session?mode=memory"), WithAutoMigrate(),
+				WithEmbeddedRules(fsys, "rules/*/*.grl"))
+			So(err, ShouldBeNil)
+			defer base.Close()
+
+			bg := context.Background()
+			session := base.(SessionExecutor[map[string]interface{}]).NewSession(bg, map[string]any{"age": 20})
+			kyc, err := session.Exec("kyc")
+			So(err, ShouldBeNil)
+			So(kyc, ShouldResemble, map[string]interface{}{"adult": true})
+			risk, err := session.Exec("risk")
+			So(err, ShouldBeNil)
+			So(risk, ShouldResemble, map[string]interface{}{"score": int64(40)})
+
+			type riskResult struct {
+				Score int `json:"score"`
+			}
+			typed, err := NewTypedEngine[riskResult](base).NewSession(bg, map[string]any{"age": 30}).Exec("risk")
+			So(err, ShouldBeNil)
+			So(typed.Score, ShouldEqual, 60)
+		})
+
+		Convey("StreamResults 流式获取规则产出的元素", func() {
+			fsys := fstest.MapFS{
+				"rules/offers/all.grl": {Data: []byte(`rule All "全部" { when true then Emit("offers", "a"); Emit("offers", "b"); Emit("offers", "c"); Retract("All"); }`)},
+			}
+			base, err := NewBaseEngine(WithDSN("sqlite:file:result_stream?mode=memory"), WithAutoMigrate(),
+				WithEmbeddedRules(fsys, "rules/*/*.grl"))
+			So(err, ShouldBeNil)
+			defer base.Close()
+
+			var offers []any
+			for offer, err := range NewTypedEngine[TestResult](base).StreamResults(context.Background(), "offers", map[string]any{}, "offers", 2) {
+				So(err, ShouldBeNil)
+				offers = append(offers, offer)
+			}
+			So(offers, ShouldResemble, []any{"a", "b"})
+
+			var report ExecReport
+			_, err = base.ExecRaw(context.Background(), "offers", map[string]any{}, WithExecReport(&report),
+				WithResultStream("offers", 0, func(any) error { return nil }))
+			So(err, ShouldBeNil)
+			So(report.Streamed, ShouldEqual, 3)
+		})
+
+		Convey("RegisterEnum 和 RegisterRefTable 供规则以符号名引用", func() {
+			fsys := fstest.MapFS{
+				"rules/orders/paid.grl": {Data: []byte(`rule Paid "已支付" { when Params["status"] == OrderStatus.PAID then Result["currency"] = Countries.CN.currency; Retract("Paid"); }`)},
+			}
+			base, err := NewBaseEngine(WithDSN("sqlite:file:reference_data?mode=memory"), WithAutoMigrate(),
+				WithEmbeddedRules(fsys, "rules/*/*.grl"))
+			So(err, ShouldBeNil)
+			defer base.Close()
+
+			typed := NewTypedEngine[map[string]any](base)
+			So(typed.Base().(Registry).RegisterEnum("OrderStatus", "PENDING", "PAID"), ShouldBeNil)
+			So(typed.Base().(Registry).RegisterRefTable("Countries", map[string]map[string]any{"CN": {"currency": "CNY"}}), ShouldBeNil)
+			result, err := typed.Exec(context.Background(), "orders", map[string]any{"status": "PAID"})
+			So(err, ShouldBeNil)
+			So(result["currency"], ShouldEqual, "CNY")
+		})
+
+		Convey("WithDBLock 和 WithDistributedLock 设置多实例间的任务锁", func() {
+			dsn := "sqlite:file:task_lock?mode=memory&cache=shared"
+			first, err := NewBaseEngine(WithDSN(dsn), WithAutoMigrate(), WithDBLock())
+			So(err, ShouldBeNil)
+			defer first.Close()
+			second, err := NewBaseEngine(WithDSN(dsn), WithAutoMigrate(), WithDBLock())
+			So(err, ShouldBeNil)
+			defer second.Close()
+
+			// 迁移完成后释放迁移锁
+			db, err := gorm.Open(sqlite.Open("file:task_lock?mode=memory&cache=shared"), &gorm.Config{})
+			So(err, ShouldBeNil)
+			var locks int64
+			So(db.Model(&lock.Record{}).Count(&locks).Error, ShouldBeNil)
+			So(locks, ShouldEqual, 0)
+
+			var buf strings.Builder
+			So(second.(Diagnostics).DebugDump(&buf), ShouldBeNil)
+			So(buf.String(), ShouldContainSubstring, "distributed_lock")
+
+			base, err := NewBaseEngine(WithDSN("sqlite:file:memory_lock?mode=memory"), WithAutoMigrate(),
+				WithDBLock(), WithDistributedLock(lock.NewMemory()))
+			So(err, ShouldBeNil)
+			defer base.Close()
+			buf.Reset()
+			So(base.(Diagnostics).DebugDump(&buf), ShouldBeNil)
+			So(buf.String(), ShouldContainSubstring, "distributed_lock")
+		})
+
+		Convey("Resolve 报告内置规则和运行时覆盖的来源层", func() {
+			fsys := fstest.MapFS{
+				"rules/loan/limit.grl": {Data: []byte(`rule Limit "额度" { when true then Result["limit"] = 100; Retract("Limit"); }`)},
+			}
+			eng, err := New[map[string]any](WithDSN("sqlite:file:rule_layers?mode=memory"), WithAutoMigrate(),
+				WithEmbeddedRules(fsys, "rules/*/*.grl"))
+			So(err, ShouldBeNil)
+			defer eng.Close()
+
+			bg := context.Background()
+			So(eng.(RuleLayers).AddOverride(bg, "loan", &rule.Rule{Name: "limit",
+				GRL: `rule Block "临时拦截" { when true then Result["limit"] = 0; Retract("Block"); }`}, 0), ShouldBeNil)
+			result, err := eng.Exec(bg, "loan", map[string]any{})
+			So(err, ShouldBeNil)
+			So(result["limit"], ShouldEqual, 0)
+
+			resolution, err := eng.(RuleLayers).Resolve(bg, "loan")
+			So(err, ShouldBeNil)
+			limit, ok := resolution.Find("limit")
+			So(ok, ShouldBeTrue)
+			So(limit.Layer, ShouldEqual, LayerOverride)
+			So(limit.Shadowed, ShouldResemble, []RuleLayer{LayerEmbedded})
+
+			So(eng.(RuleLayers).RemoveOverride(bg, "loan", "limit"), ShouldBeNil)
+			So(errors.Is(eng.(RuleLayers).RemoveOverride(bg, "loan", "limit"), ErrOverrideNotFound), ShouldBeTrue)
+		})
+
+		Convey("WithVersionRetention 设置保留版本数", func() {
+			So(ctx.config.VersionRetention, ShouldEqual, 3)
+			So(WithVersionRetention(5)(ctx), ShouldBeNil)
+			So(ctx.config.VersionRetention, ShouldEqual, 5)
+		})
+
+		Convey("WithWriteCheck 设置写入声明检查模式", func() {
+			ctx.config.DSN = "sqlite:file::memory:"
+			So(WithWriteCheck(WriteCheckError)(ctx), ShouldBeNil)
+			So(ctx.config.WriteCheck, ShouldEqual, WriteCheckError)
+			So(ctx.config.Validate(), ShouldBeNil)
+
+			So(WithWriteCheck("strict")(ctx), ShouldBeNil)
+			So(ctx.config.Validate(), ShouldNotBeNil)
+		})
+
+		Convey("配置验证汇总全部问题", func() {
+			So(WithMemoryCache(0)(ctx), ShouldBeNil)
+			So(WithCacheTTL(0)(ctx), ShouldBeNil)
+			// 直接修改配置，模拟通过 NewRuntimeContext 传入的配置
+			ctx.config.RedisAddr = "localhost:6379"
+			ctx.config.RedisDB = -1
+
+			err := ctx.config.Validate()
+			var errs ConfigErrors
+			So(errors.As(err, &errs), ShouldBeTrue)
+			So(errs.Codes(), ShouldResemble, []string{
+				CodeMissingDSN, CodeInvalidRedisDB, CodeConflictingCacheOptions, CodeInvalidCacheSize, CodeInvalidCacheTTL,
+			})
+			So(errs.Has(CodeMissingRedisAddr), ShouldBeFalse)
+			So(err.Error(), ShouldStartWith, "共5个配置问题:")
+			So(err.Error(), ShouldContainSubstring, "[missing_dsn]")
+
+			var first *ConfigError
+			So(errors.As(err, &first), ShouldBeTrue)
+			So(first.Field, ShouldEqual, "DSN")
+
+			ctx.config.DSN = "sqlite:file::memory:"
+			So(WithRedisCache("", "", 0)(ctx), ShouldBeNil)
+			So(WithCacheTTL(time.Minute)(ctx), ShouldBeNil)
+			err = ctx.config.Validate()
+			So(errors.As(err, &errs), ShouldBeTrue)
+			So(errs.Codes(), ShouldResemble, []string{CodeMissingRedisAddr})
+			So(err.Error(), ShouldStartWith, "[missing_redis_addr]")
+		})
+
+		Convey("WithRequestLogger 使用请求级日志记录器", func() {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			type traceKey struct{}
+			requestLog := logger.NewMockLogger(ctrl)
+			requestLog.EXPECT().Warnf(gomock.Any(), "未找到有效规则", "bizCode", "MISSING").Times(1)
+			engineLog := logger.NewMockLogger(ctrl)
+			engineLog.EXPECT().Warnf(gomock.Any(), "未找到有效规则", "bizCode", "MISSING").Times(1)
+			engineLog.EXPECT().Infof(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+			engineLog.EXPECT().Debugf(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+
+			eng, err := New[map[string]any](
+				WithDSN("sqlite:file:request_logger.db?mode=memory&cache=shared&_fk=1"),
+				WithAutoMigrate(),
+				WithNoCache(),
+				WithCustomLogger(engineLog),
+				WithRequestLogger(func(ctx context.Context) logger.Logger {
+					if ctx.Value(traceKey{}) != nil {
+						return requestLog
+					}
+					return nil
+				}),
+			)
+			So(err, ShouldBeNil)
+			defer eng.Close()
+
+			// 携带追踪信息的请求使用请求级日志，其余使用引擎日志
+			_, err = eng.Exec(context.WithValue(context.Background(), traceKey{}, "trace-1"), "MISSING", map[string]any{})
+			So(err, ShouldNotBeNil)
+			_, err = eng.Exec(context.Background(), "MISSING", map[string]any{})
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("WithRulePaging 和 WithRuleCountWarning", func() {
+			So(WithRulePaging(500)(ctx), ShouldBeNil)
+			So(ctx.config.RulePageSize, ShouldEqual, 500)
+			So(WithRuleCountWarning(10000)(ctx), ShouldBeNil)
+			So(ctx.config.RuleCountWarnThreshold, ShouldEqual, 10000)
+		})
+
 		Convey("WithCustomDB 注入数据库实例", func() {
 			db, err := gorm.Open(sqlite.Open("file:custom_db_test.db?mode=memory&cache=shared"), &gorm.Config{})
 			So(err, ShouldBeNil)
@@ -176,6 +789,41 @@ func TestRuntimeContextInitialize(t *testing.T) {
 		So(sqlDB.Close(), ShouldBeNil)
 	})
 
+	Convey("连接池配置应用到sql.DB", t, func() {
+		cfg := config.DefaultConfig()
+		cfg.DSN = "sqlite:file:runtime_pool.db?mode=memory&cache=shared&_fk=1"
+		cfg.DBMaxOpenConns = 7
+		ctx := newRuntimeContext(cfg)
+
+		So(ctx.initialize(), ShouldBeNil)
+		sqlDB, err := ctx.DB.DB()
+		So(err, ShouldBeNil)
+		So(sqlDB.Stats().MaxOpenConnections, ShouldEqual, 7)
+		So(sqlDB.Close(), ShouldBeNil)
+	})
+
+	Convey("索引诊断发现全表扫描时记录警告", t, func() {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		db, err := gorm.Open(sqlite.Open("file:index_scan.db?mode=memory"), &gorm.Config{})
+		So(err, ShouldBeNil)
+		So(db.Exec("CREATE TABLE runehammer_rules (id INTEGER PRIMARY KEY, biz_code TEXT, version INTEGER, enabled NUMERIC)").Error, ShouldBeNil)
+
+		mockLogger := logger.NewMockLogger(ctrl)
+		mockLogger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, msg string, _ ...any) {
+				So(msg, ShouldContainSubstring, "全表扫描")
+			}).Times(1)
+
+		cfg := config.DefaultConfig()
+		cfg.IndexAdvisor = true
+		ctx := newRuntimeContext(cfg)
+		ctx.DB = db
+		ctx.Logger = mockLogger
+		So(ctx.initialize(), ShouldBeNil)
+	})
+
 	Convey("setupCache 分支覆盖", t, func() {
 		cfg := config.DefaultConfig()
 		ctx := newRuntimeContext(cfg)
@@ -407,6 +1055,7 @@ func TestRunehammer(t *testing.T) {
 				So(err, ShouldNotBeNil)
 				So(eie, ShouldBeNil)
 				So(err.Error(), ShouldContainSubstring, "配置验证失败")
+				So(err.Error(), ShouldContainSubstring, "[missing_dsn]")
 			})
 
 			Convey("数据库连接失败", func() {
@@ -1645,3 +2294,38 @@ func TestHighLevelAPI(t *testing.T) {
 		})
 	})
 }
+
+func TestFallbackOptions(t *testing.T) {
+	Convey("降级选项", t, func() {
+		eng, err := New[map[string]interface{}](
+			WithDSN("sqlite:file:fallback_test.db?mode=memory&cache=shared&_fk=1"),
+			WithAutoMigrate(),
+			WithFallbackResult("missing_biz", map[string]interface{}{"decision": "deny"}),
+		)
+		So(err, ShouldBeNil)
+		defer eng.Close()
+
+		var report ExecReport
+		result, err := eng.Exec(context.Background(), "missing_biz", map[string]any{"a": 1}, WithExecReport(&report))
+		So(err, ShouldBeNil)
+		So(result["decision"], ShouldEqual, "deny")
+		So(report.Degraded, ShouldBeTrue)
+
+		_, err = eng.Exec(context.Background(), "other_biz", map[string]any{"a": 1})
+		So(err, ShouldNotBeNil)
+	})
+}
+
+func TestDebugDump(t *testing.T) {
+	Convey("诊断快照输出", t, func() {
+		eng, err := NewBaseEngine(WithDSN("sqlite:file:debug_dump.db?mode=memory&cache=shared&_fk=1"))
+		So(err, ShouldBeNil)
+		defer eng.Close()
+
+		typed := NewTypedEngine[TestResult](eng)
+		var buf strings.Builder
+		So(typed.Base().(Diagnostics).DebugDump(&buf), ShouldBeNil)
+		So(buf.String(), ShouldContainSubstring, "db_pool")
+		So(buf.String(), ShouldContainSubstring, "knowledge_bases")
+	})
+}
@@ -0,0 +1,87 @@
+package runehammertest
+
+import (
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// 测试时钟 - 配合 WithClock 使用，冻结或拨动规则中 Now()/Today()/NowMillis() 看到的时间
+// ============================================================================
+//
+// 新建的时钟冻结在起始时间，Advance/Set 拨动时间；Unfreeze 后从当前时间起随系统时间流逝，
+// 再次 Freeze 停在当时的时间。所有方法并发安全。
+
+// Clock 可控的测试时钟，实现 runehammer.Clock
+type Clock struct {
+	mu     sync.Mutex
+	now    time.Time // 冻结时的时间，或解冻时的起点
+	since  time.Time // 解冻时的系统时间，冻结时为零值
+	frozen bool
+}
+
+// NewClock 创建冻结在指定时间的测试时钟
+func NewClock(start time.Time) *Clock {
+	return &Clock{now: start, frozen: true}
+}
+
+// Now 实现Clock接口 - 返回当前时间
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.current()
+}
+
+// Advance 将时钟拨快d，d为负数时拨慢
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// Set 将时钟拨到指定时间，保持冻结或流逝状态
+func (c *Clock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = t
+	if !c.frozen {
+		c.since = time.Now()
+	}
+}
+
+// Freeze 冻结时钟，停在当前时间
+func (c *Clock) Freeze() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.frozen {
+		return
+	}
+	c.now = c.current()
+	c.frozen = true
+}
+
+// Unfreeze 解冻时钟，从当前时间起随系统时间流逝
+func (c *Clock) Unfreeze() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.frozen {
+		return
+	}
+	c.since = time.Now()
+	c.frozen = false
+}
+
+// Frozen 时钟是否冻结
+func (c *Clock) Frozen() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.frozen
+}
+
+// current 计算当前时间，调用方持有锁
+func (c *Clock) current() time.Time {
+	if c.frozen {
+		return c.now
+	}
+	return c.now.Add(time.Since(c.since))
+}
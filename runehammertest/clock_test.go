@@ -0,0 +1,50 @@
+package runehammertest
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// TestClock 测试可控测试时钟
+func TestClock(t *testing.T) {
+	Convey("测试时钟", t, func() {
+		start := time.Date(2024, 12, 31, 23, 59, 0, 0, time.UTC)
+		clock := NewClock(start)
+
+		Convey("新建时冻结在起始时间", func() {
+			So(clock.Frozen(), ShouldBeTrue)
+			So(clock.Now(), ShouldEqual, start)
+			time.Sleep(2 * time.Millisecond)
+			So(clock.Now(), ShouldEqual, start)
+		})
+
+		Convey("拨动时间", func() {
+			clock.Advance(2 * time.Minute)
+			So(clock.Now(), ShouldEqual, start.Add(2*time.Minute))
+			clock.Advance(-time.Hour)
+			So(clock.Now(), ShouldEqual, start.Add(-58*time.Minute))
+
+			target := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+			clock.Set(target)
+			So(clock.Now(), ShouldEqual, target)
+		})
+
+		Convey("解冻后随系统时间流逝，再次冻结停在当时", func() {
+			clock.Unfreeze()
+			So(clock.Frozen(), ShouldBeFalse)
+			time.Sleep(5 * time.Millisecond)
+			elapsed := clock.Now().Sub(start)
+			So(elapsed, ShouldBeGreaterThanOrEqualTo, 5*time.Millisecond)
+
+			clock.Advance(time.Hour)
+			So(clock.Now().Sub(start), ShouldBeGreaterThan, time.Hour)
+
+			clock.Freeze()
+			frozen := clock.Now()
+			time.Sleep(2 * time.Millisecond)
+			So(clock.Now(), ShouldEqual, frozen)
+		})
+	})
+}
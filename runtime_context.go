@@ -2,14 +2,20 @@ package runehammer
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
+	"gitee.com/damengde/runehammer/alert"
 	"gitee.com/damengde/runehammer/cache"
 	"gitee.com/damengde/runehammer/config"
+	"gitee.com/damengde/runehammer/engine"
+	"gitee.com/damengde/runehammer/lock"
 	logger "gitee.com/damengde/runehammer/logger"
+	"gitee.com/damengde/runehammer/pubsub"
 	"gitee.com/damengde/runehammer/rule"
+	"github.com/hyperjumptech/grule-rule-engine/ast"
 	"github.com/redis/go-redis/v9"
 	"gorm.io/driver/mysql"
 	"gorm.io/driver/sqlite"
@@ -23,15 +29,43 @@ import (
 // RuntimeContext 运行时上下文 - 持有所有运行时实例对象
 type RuntimeContext struct {
 	// 实例对象
-	DB     *gorm.DB      // 数据库连接实例
-	Cache  cache.Cache   // 缓存实例
-	Logger logger.Logger // 日志实例
+	DB               *gorm.DB              // 数据库连接实例
+	Cache            cache.Cache           // 缓存实例
+	Logger           logger.Logger         // 日志实例
+	KnowledgeLibrary *ast.KnowledgeLibrary // Grule知识库，为nil时新建
 
 	// 组件对象
 	RuleMapper rule.RuleMapper // 规则映射器
 
+	// 扩展对象
+	FallbackProvider engine.FallbackProvider             // 降级结果提供者
+	fallbackResults  map[string]any                      // 按业务码配置的静态降级结果
+	DecisionExporter engine.DecisionStatsExporter        // 决策分布导出器，为nil时导出到日志
+	AnomalyAlerter   engine.AnomalyAlerter               // 决策分布异常告警回调，为nil时输出警告日志
+	DeadRuleReporter engine.DeadRuleReporter             // 失效规则报告回调，为nil时输出警告日志
+	AlertSink        alert.Sink                          // 规则告警通道，为nil时告警只输出日志
+	QuotaProvider    engine.QuotaProvider                // 配额提供者，为nil时不检查配额
+	UsageReporter    engine.UsageReporter                // 用量上报，为nil时不统计用量
+	RetentionTargets []rule.RetentionTarget              // 按审计记录保留时长清理的目标
+	RequestLogger    func(context.Context) logger.Logger // 请求级日志记录器，返回nil时使用Logger
+	Clock            engine.Clock                        // 规则时间函数使用的时钟，为nil时使用系统时间
+	RateProvider     engine.RateProvider                 // 货币换算的汇率提供者，为nil时只能换算相同货币
+	Calendar         engine.CalendarProvider             // 规则日历函数使用的节假日日历
+	EmbeddedRules    []*rule.Rule                        // 内置默认规则，数据库中的同名规则优先
+	PubSub           pubsub.PubSub                       // 实例间消息通道，用于同步运行时覆盖、紧急停用和幂等结果失效
+	AuditRecorder    engine.AuditRecorder                // 紧急停用等运维操作的审计记录
+	SLOAlerter       engine.SLOAlerter                   // 延迟SLO告警回调，为nil时输出警告日志
+	Invalidation     engine.InvalidationSource           // 上游数据变更来源，用于失效幂等结果
+	Locker           lock.Locker                         // 分布式锁，用于多实例间互斥执行同步、清理和迁移任务，为nil时不加锁
+	TaskLock         *lock.Guard                         // 基于Locker的任务锁，初始化时创建
+	dbLock           bool                                // 是否使用数据库锁表作为分布式锁
+
 	// 配置
 	config *config.Config
+
+	// 选项冲突检测
+	claims        map[string][]optionClaim // 配置项 -> 设置该配置项的选项，按应用顺序
+	strictOptions bool                     // 存在冲突选项时是否返回错误
 }
 
 // NewRuntimeContext 创建运行时上下文
@@ -47,6 +81,13 @@ func newRuntimeContext(cfg *config.Config) *RuntimeContext {
 	return &RuntimeContext{config: cfg}
 }
 
+// clearRedisConfig 清除Redis配置 - 切换到其他缓存来源时，之前设置的Redis参数不再生效
+func (ctx *RuntimeContext) clearRedisConfig() {
+	ctx.config.RedisAddr = ""
+	ctx.config.RedisPassword = ""
+	ctx.config.RedisDB = 0
+}
+
 func (ctx *RuntimeContext) initialize() error {
 	// 初始化数据库
 	if ctx.DB == nil {
@@ -55,6 +96,11 @@ func (ctx *RuntimeContext) initialize() error {
 		}
 	}
 
+	// 应用连接池配置
+	if err := ctx.setupDBPool(); err != nil {
+		return fmt.Errorf("数据库连接池配置失败: %w", err)
+	}
+
 	// 初始化缓存
 	if ctx.Cache == nil {
 		if err := ctx.setupCache(); err != nil {
@@ -66,22 +112,80 @@ func (ctx *RuntimeContext) initialize() error {
 	if ctx.Logger == nil {
 		ctx.Logger = logger.NewNoopLogger()
 	}
+	if ctx.RequestLogger != nil {
+		ctx.Logger = logger.NewContextLogger(ctx.Logger, ctx.RequestLogger)
+	}
 
 	// 初始化规则映射器
 	if ctx.RuleMapper == nil {
 		ctx.RuleMapper = rule.NewRuleMapper(ctx.DB)
 	}
 
-	// 执行自动迁移
+	// 初始化分布式任务锁
+	if ctx.dbLock {
+		locker := lock.NewDB(ctx.DB)
+		if err := locker.Migrate(); err != nil {
+			return fmt.Errorf("分布式锁初始化失败: %w", err)
+		}
+		ctx.Locker = locker
+	}
+	ctx.TaskLock = lock.NewGuard(ctx.Locker, "")
+
+	// 执行自动迁移，多实例同时启动时依次执行
 	if ctx.config.AutoMigrate {
-		if err := ctx.DB.AutoMigrate(&rule.Rule{}); err != nil {
+		if err := ctx.migrate(); err != nil {
 			return fmt.Errorf("数据库迁移失败: %w", err)
 		}
 	}
 
+	// 检查表结构，在首次执行前发现未执行的迁移
+	if ctx.config.SchemaCheck {
+		if err := rule.CheckSchema(ctx.DB); err != nil {
+			return fmt.Errorf("数据库表结构检查失败: %w", err)
+		}
+	}
+
+	// 诊断规则获取查询，全表扫描时提示创建索引
+	if ctx.config.IndexAdvisor {
+		ctx.adviseIndexes()
+	}
+
 	return nil
 }
 
+// migrateLockTTL 迁移锁的有效期，持有锁的实例在此期间未完成迁移时锁可被其他实例接管
+const migrateLockTTL = 10 * time.Minute
+
+// migrate 在迁移锁内执行数据库迁移
+func (ctx *RuntimeContext) migrate() error {
+	return ctx.TaskLock.Run(context.Background(), "migrate", migrateLockTTL, func(c context.Context) error {
+		return ctx.DB.WithContext(c).AutoMigrate(rule.Models()...)
+	})
+}
+
+// adviseIndexes 对规则获取查询执行EXPLAIN，全表扫描时记录警告日志，规则映射器不支持诊断时跳过
+func (ctx *RuntimeContext) adviseIndexes() {
+	mapper, ok := ctx.RuleMapper.(rule.QueryPlanMapper)
+	if !ok {
+		return
+	}
+
+	bg := context.Background()
+	plan, err := mapper.ExplainFetch(bg, "")
+	if err != nil {
+		if errors.Is(err, rule.ErrExplainUnsupported) {
+			ctx.Logger.Debugf(bg, "跳过规则查询计划诊断", "error", err)
+		} else {
+			ctx.Logger.Warnf(bg, "规则查询计划诊断失败", "error", err)
+		}
+		return
+	}
+	if plan.FullScan {
+		ctx.Logger.Warnf(bg, "规则查询将全表扫描，请执行数据库迁移（WithAutoMigrate）创建索引",
+			"index", rule.FetchIndexName, "query", plan.Query, "plan", strings.Join(plan.Steps, "; "))
+	}
+}
+
 // setupDatabase 初始化数据库连接
 func (ctx *RuntimeContext) setupDatabase() error {
 	config := ctx.config
@@ -108,6 +212,30 @@ func (ctx *RuntimeContext) setupDatabase() error {
 	return nil
 }
 
+// setupDBPool 应用连接池配置到底层sql.DB，未配置的参数保持驱动默认值
+func (ctx *RuntimeContext) setupDBPool() error {
+	cf := ctx.config
+	if cf.DBMaxOpenConns <= 0 && cf.DBMaxIdleConns <= 0 && cf.DBConnMaxLifetime <= 0 {
+		return nil
+	}
+
+	sqlDB, err := ctx.DB.DB()
+	if err != nil {
+		return err
+	}
+
+	if cf.DBMaxOpenConns > 0 {
+		sqlDB.SetMaxOpenConns(cf.DBMaxOpenConns)
+	}
+	if cf.DBMaxIdleConns > 0 {
+		sqlDB.SetMaxIdleConns(cf.DBMaxIdleConns)
+	}
+	if cf.DBConnMaxLifetime > 0 {
+		sqlDB.SetConnMaxLifetime(cf.DBConnMaxLifetime)
+	}
+	return nil
+}
+
 // setupCache 初始化缓存系统
 func (ctx *RuntimeContext) setupCache() error {
 	cf := ctx.config
@@ -175,6 +303,14 @@ func (ctx *RuntimeContext) Close() error {
 	return nil
 }
 
+// fallbackProvider 组合静态降级结果与自定义降级提供者
+func (ctx *RuntimeContext) fallbackProvider() engine.FallbackProvider {
+	if len(ctx.fallbackResults) == 0 {
+		return ctx.FallbackProvider
+	}
+	return engine.NewStaticFallbackProvider(ctx.fallbackResults, ctx.FallbackProvider)
+}
+
 // GetConfig 获取配置（只读）
 func (ctx *RuntimeContext) GetConfig() *config.Config {
 	return ctx.config